@@ -0,0 +1,65 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeAddresses collapses repeated punctuation and whitespace in addresses and queries of
+// them, so "123 Main St,,  Apt 4" and "123 Main St; Apt 4" are scored the same.
+// main sets it from ADDRESS_NORMALIZATION.
+var normalizeAddresses = false
+
+// precomputeAddress is precompute of an address field (a street line or city, state and postal
+// code), with its separators standardized first when normalizeAddresses is set
+func precomputeAddress(s string) string {
+	if normalizeAddresses {
+		s = normalizeAddress(s)
+	}
+	return precompute(s)
+}
+
+// normalizeAddress replaces each run of punctuation and whitespace between the words of s
+// with one separator. Runs with a newline or a separating mark (like ";" or "/") become ", " and
+// others a space. A single hyphen, apostrophe, period or ampersand inside a word is kept, as in
+// "10-2" or "P.O. Box".
+func normalizeAddress(s string) string {
+	var out strings.Builder
+	var run []rune
+	flush := func() {
+		switch {
+		case out.Len() == 0:
+			// leading punctuation is dropped
+		case len(run) == 1 && strings.ContainsRune("-'.&", run[0]):
+			out.WriteRune(run[0])
+		case strings.IndexFunc(string(run), isAddressSeparator) >= 0:
+			out.WriteString(", ")
+		default:
+			out.WriteByte(' ')
+		}
+		run = run[:0]
+	}
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) {
+			if len(run) > 0 {
+				flush()
+			}
+			out.WriteRune(r)
+			continue
+		}
+		run = append(run, r)
+	}
+	return out.String() // trailing punctuation is dropped
+}
+
+func isAddressSeparator(r rune) bool {
+	switch r {
+	case ',', ';', '|', '/', '\\', ':', '\n', '\r', '\u2028':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+func TestAddressNormalize__normalizeAddress(t *testing.T) {
+	cases := map[string]string{
+		"123 Main St,,  Apt 4":           "123 Main St, Apt 4",
+		"123 Main St\nApt 4":             "123 Main St, Apt 4",
+		"123 Main St\r\n\r\nApt 4":       "123 Main St, Apt 4",
+		"123 Main St.; ; Apt #4":         "123 Main St, Apt 4",
+		"123 Main St -- Apt 4 ":          "123 Main St Apt 4",
+		"  ,Calle 13 No. 551 || Vedado,": "Calle 13 No 551, Vedado",
+		"Dai-Ichi Bldg. 6th Floor, 10-2": "Dai-Ichi Bldg 6th Floor, 10-2",
+		"P.O. Box 1020":                  "P.O Box 1020",
+		"O'Connell St\t\tDublin":         "O'Connell St Dublin",
+		"Москва,  ул. Тверская":          "Москва, ул Тверская",
+		"":                               "",
+		"---":                            "",
+	}
+	for in, expected := range cases {
+		if out := normalizeAddress(in); out != expected {
+			t.Errorf("%q: got %q, expected %q", in, out, expected)
+		}
+	}
+}
+
+func TestAddressNormalize__scoring(t *testing.T) {
+	defer func() { normalizeAddresses = false }()
+
+	records := []*ofac.Address{
+		{EntityID: "1", AddressID: "1", Address: "123 Main St,,  Apt 4\nBldg; 7", CityStateProvincePostalCode: "Springfield;; IL 62701", Country: "United States"},
+	}
+	req := addressSearchRequest{Address: "123 Main St Apt 4 Bldg 7", City: "Springfield", State: "IL", Country: "United States"}
+
+	score := func() float64 {
+		addrs := precomputeAddresses(records)
+		return weightedAddressCompare(req, addressScoreWeights, searchOptions{})(addrs[0]).weight
+	}
+	messy := score()
+
+	normalizeAddresses = true
+	addrs := precomputeAddresses(records)
+	if addrs[0].address != "123 main st apt 4 bldg 7" || addrs[0].city != "springfield" || addrs[0].state != "il" || addrs[0].postal != "62701" {
+		t.Errorf("address=%q city=%q state=%q postal=%q", addrs[0].address, addrs[0].city, addrs[0].state, addrs[0].postal)
+	}
+	if normalized := score(); normalized <= messy || normalized < 0.99 {
+		t.Errorf("normalized=%.3f messy=%.3f", normalized, messy)
+	}
+
+	// queries are normalized the same way
+	req.Address = "123 Main St //  Apt 4,, Bldg 7"
+	if normalized := score(); normalized < 0.99 {
+		t.Errorf("messy query scored %.3f", normalized)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-kit/kit/log"
+)
+
+// addressWeights are the relative weights of each address component when combining them
+// into one address score. Street lines on sanctions lists are often vague or transliterated
+// while the city and country are reliable, so those count for more by default.
+type addressWeights struct {
+	street  float64
+	city    float64
+	state   float64
+	postal  float64
+	country float64
+}
+
+var (
+	defaultAddressWeights = addressWeights{
+		street:  1.0,
+		city:    2.0,
+		state:   1.0,
+		postal:  1.0,
+		country: 2.0,
+	}
+
+	// addressScoreWeights are used by address searches, main sets them from ADDRESS_SCORE_WEIGHTS
+	addressScoreWeights = defaultAddressWeights
+)
+
+// getAddressWeights reads weights formatted as "street=1,city=2,country=2". Components
+// not listed keep their default weight.
+//
+// env is the value from an environmental variable
+func getAddressWeights(logger log.Logger, env string) addressWeights {
+	if env == "" {
+		return defaultAddressWeights
+	}
+	weights, err := readAddressWeights(env)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid ADDRESS_SCORE_WEIGHTS=%q, using defaults: %v", env, err))
+		return defaultAddressWeights
+	}
+	logger.Log("main", fmt.Sprintf("Setting address score weights to %q", env))
+	return weights
+}
+
+func readAddressWeights(str string) (addressWeights, error) {
+	weights := defaultAddressWeights
+	for _, part := range strings.Split(str, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return weights, fmt.Errorf("expected component=weight, got %q", part)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil || math.IsNaN(w) || math.IsInf(w, 0) || w < 0 {
+			return weights, fmt.Errorf("invalid weight %q for %s", kv[1], kv[0])
+		}
+		switch strings.ToLower(strings.TrimSpace(kv[0])) {
+		case "street":
+			weights.street = w
+		case "city":
+			weights.city = w
+		case "state":
+			weights.state = w
+		case "postal":
+			weights.postal = w
+		case "country":
+			weights.country = w
+		default:
+			return weights, fmt.Errorf("unknown address component %q", kv[0])
+		}
+	}
+	// scores are divided by the sum of the weights
+	if weights.street+weights.city+weights.state+weights.postal+weights.country == 0 {
+		return weights, errors.New("every address component has a weight of zero")
+	}
+	return weights, nil
+}
+
+// splitCityStateProvincePostalCode breaks OFAC's combined field (e.g. "London EC3N 1DY" or
+// "Mexico, D.F. 11570") into its parts. The city comes before the first comma, anything after
+// it is the state or province and trailing tokens containing digits are the postal code.
+func splitCityStateProvincePostalCode(raw string) (city, state, postal string) {
+	parts := strings.Split(raw, ",")
+	var postals []string
+	for i := range parts {
+		fields := strings.Fields(parts[i])
+		// only keep peeling off postal codes when they're at the end of a part
+		for len(fields) > 1 && strings.IndexFunc(fields[len(fields)-1], unicode.IsDigit) >= 0 {
+			postals = append([]string{fields[len(fields)-1]}, postals...)
+			fields = fields[:len(fields)-1]
+		}
+		if len(fields) == 1 && i > 0 && strings.IndexFunc(fields[0], unicode.IsDigit) >= 0 {
+			postals = append(postals, fields[0])
+			fields = nil
+		}
+		parts[i] = strings.Join(fields, " ")
+	}
+	city = parts[0]
+	if len(parts) > 1 {
+		var states []string
+		for _, p := range parts[1:] {
+			if p != "" {
+				states = append(states, p)
+			}
+		}
+		state = strings.Join(states, " ")
+	}
+	return city, state, strings.Join(postals, " ")
+}
+
+var (
+	// ukPostalCode and caPostalCode match postal codes once spaces are removed, like "sw1a1aa"
+	// (SW1A 1AA) and "k1a0b1" (K1A 0B1)
+	ukPostalCode = regexp.MustCompile(`^[a-z]{1,2}[0-9][a-z0-9]?[0-9][a-z]{2}$`)
+	caPostalCode = regexp.MustCompile(`^[a-z][0-9][a-z][0-9][a-z][0-9]$`)
+
+	// prefixedPostalCode matches codes with a country prefix, like "CH-8022"
+	prefixedPostalCode = regexp.MustCompile(`^[a-z]{1,2}-([0-9]+)$`)
+)
+
+// normalizePostalCode formats a postal code the same way however it was written, so "SW1A1AA"
+// and "sw1a 1aa" are equal. UK and Canadian codes are spaced as their post offices write them,
+// unless country is another country. Other codes have their spaces and punctuation removed.
+func normalizePostalCode(code, country string) string {
+	if m := prefixedPostalCode.FindStringSubmatch(strings.ToLower(strings.TrimSpace(code))); m != nil {
+		code = m[1]
+	}
+	code = fold(code)
+	compact := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, code)
+
+	switch country = fold(country); {
+	case ukPostalCode.MatchString(compact) && anyCountry(country, "united kingdom", "uk", "gb", "great britain"):
+		return compact[:len(compact)-3] + " " + compact[len(compact)-3:]
+	case caPostalCode.MatchString(compact) && anyCountry(country, "canada", "ca"):
+		return compact[:3] + " " + compact[3:]
+	}
+	return compact
+}
+
+// anyCountry returns true when country is unknown or one of names
+func anyCountry(country string, names ...string) bool {
+	if country == "" {
+		return true
+	}
+	for i := range names {
+		if country == names[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// tokens counts the tokens of every component of req
+func (req addressSearchRequest) tokens() int {
+	var n int
+	for _, v := range []string{req.Address, req.City, req.State, req.Providence, req.Zip, req.Country} {
+		n += len(strings.Fields(v))
+	}
+	return n
+}
+
+// forAddress returns opts for scoring the addresses of req. Short queries (like "NY") score
+// well against many addresses, so ones with fewer tokens than minAddressTokens only match
+// exactly.
+func (opts searchOptions) forAddress(req addressSearchRequest) searchOptions {
+	if req.tokens() < opts.minAddressTokens {
+		opts.strictAddresses = true
+	}
+	return opts
+}
+
+// weightedAddressCompare scores each component of req which was given against the matching
+// component of an Address and returns their weighted average. Addresses whose component
+// couldn't be parsed are compared against the entire city/state/postal field instead.
+// Strict searches require each component to match exactly.
+func weightedAddressCompare(req addressSearchRequest, weights addressWeights, opts searchOptions) func(*Address) *item {
+	type component struct {
+		needle   string
+		weight   float64
+		field    func(*Address) string
+		fallback string // compared against a.citystate when field is empty
+	}
+	var components []component
+	add := func(needle string, weight float64, fallback bool, field func(*Address) string) {
+		if needle = precomputeAddress(needle); needle != "" {
+			c := component{needle: needle, weight: weight, field: field}
+			if fallback {
+				c.fallback = needle
+			}
+			components = append(components, c)
+		}
+	}
+	add(req.Address, weights.street, false, func(a *Address) string { return a.address })
+	add(req.City, weights.city, true, func(a *Address) string { return a.city })
+	add(req.State, weights.state, true, func(a *Address) string { return a.state })
+	add(req.Providence, weights.state, true, func(a *Address) string { return a.state })
+	add(req.Country, weights.country, false, func(a *Address) string { return a.country })
+	if zip := normalizePostalCode(req.Zip, req.Country); zip != "" {
+		// the entire field isn't normalized, so it's compared against the zip as written
+		components = append(components, component{zip, weights.postal, func(a *Address) string { return a.postal }, precompute(req.Zip)})
+	}
+
+	return func(a *Address) *item {
+		var score, total float64
+		for _, c := range components {
+			field, needle := c.field(a), c.needle
+			if field == "" && c.fallback != "" {
+				field, needle = a.citystate, c.fallback
+			}
+			if opts.strictAddresses {
+				score += c.weight * exactMatch(field, needle)
+			} else {
+				score += c.weight * searchScorers.address.score(field, needle)
+			}
+			total += c.weight
+		}
+		if total == 0 {
+			return &item{value: a, weight: 0.0}
+		}
+		return &item{value: a, weight: score / total}
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestAddressScoring__readAddressWeights(t *testing.T) {
+	weights, err := readAddressWeights("street=0.5, COUNTRY=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weights.street != 0.5 || weights.country != 3 {
+		t.Errorf("unexpected weights: %#v", weights)
+	}
+	if weights.city != defaultAddressWeights.city {
+		t.Errorf("city weight should keep default: %#v", weights)
+	}
+
+	for _, str := range []string{"street", "street=abc", "city=-1", "city=NaN", "city=Inf", "planet=1", "street=0,city=0,state=0,postal=0,country=0"} {
+		if _, err := readAddressWeights(str); err == nil {
+			t.Errorf("expected error for %q", str)
+		}
+	}
+
+	if w := getAddressWeights(log.NewNopLogger(), "planet=1"); w != defaultAddressWeights {
+		t.Errorf("expected defaults: %#v", w)
+	}
+	if w := getAddressWeights(log.NewNopLogger(), ""); w != defaultAddressWeights {
+		t.Errorf("expected defaults: %#v", w)
+	}
+}
+
+func TestAddressScoring__splitCityStateProvincePostalCode(t *testing.T) {
+	cases := []struct {
+		input               string
+		city, state, postal string
+	}{
+		{"Havana", "Havana", "", ""},
+		{"London EC3N 1DY", "London", "", "EC3N 1DY"},
+		{"Zurich CH-8022", "Zurich", "", "CH-8022"},
+		{"Tokyo 103", "Tokyo", "", "103"},
+		{"Mexico, D.F. 11570", "Mexico", "D.F.", "11570"},
+		{"San Pedro Sula, Cortes", "San Pedro Sula", "Cortes", ""},
+		{"Panama City, 0816", "Panama City", "", "0816"},
+		{"", "", "", ""},
+	}
+	for _, tc := range cases {
+		city, state, postal := splitCityStateProvincePostalCode(tc.input)
+		if city != tc.city || state != tc.state || postal != tc.postal {
+			t.Errorf("%q: got city=%q state=%q postal=%q", tc.input, city, state, postal)
+		}
+	}
+}
+
+func TestAddressScoring__divergentStreet(t *testing.T) {
+	addrs := precomputeAddresses([]*ofac.Address{
+		{
+			EntityID:                    "306",
+			Address:                     "Zweierstrasse 35",
+			CityStateProvincePostalCode: "Zurich CH-8022",
+			Country:                     "Switzerland",
+		},
+		{
+			EntityID:                    "552",
+			Address:                     "Bahnhofstrasse 12",
+			CityStateProvincePostalCode: "Madrid 28016",
+			Country:                     "Spain",
+		},
+	})
+	req := addressSearchRequest{
+		Address: "bahnhofstrasse 12",
+		City:    "zurich",
+		Country: "switzerland",
+	}
+
+	weighted := weightedAddressCompare(req, defaultAddressWeights, searchOptions{})
+	sameCity := weighted(addrs[0]).weight
+	sameStreet := weighted(addrs[1]).weight
+
+	// the street is different, but the city and country match
+	if sameCity < 0.85 {
+		t.Errorf("city and country match scored %.3f", sameCity)
+	}
+	if sameCity <= sameStreet {
+		t.Errorf("city and country match (%.3f) should outrank a street match (%.3f)", sameCity, sameStreet)
+	}
+
+	// equal weights score the same street match higher
+	equal := weightedAddressCompare(req, addressWeights{street: 1, city: 1, state: 1, postal: 1, country: 1}, searchOptions{})
+	if w := equal(addrs[0]).weight; w >= sameCity {
+		t.Errorf("equal weights scored %.3f, weighted %.3f", w, sameCity)
+	}
+
+	// components which couldn't be parsed are compared against the entire field
+	zip := weightedAddressCompare(addressSearchRequest{Zip: "ch-8022"}, defaultAddressWeights, searchOptions{})
+	eql(t, "postal", zip(addrs[0]).weight, 1.0)
+	if w := zip(&Address{citystate: precompute("ch-8022")}).weight; w != 1.0 {
+		t.Errorf("fallback scored %.3f", w)
+	}
+
+	// no components
+	if w := weightedAddressCompare(addressSearchRequest{}, defaultAddressWeights, searchOptions{})(addrs[0]).weight; w != 0 {
+		t.Errorf("empty request scored %.3f", w)
+	}
+}
+
+func TestAddressScoring__normalizePostalCode(t *testing.T) {
+	cases := []struct {
+		code, country, expected string
+	}{
+		// UK
+		{"SW1A 1AA", "", "sw1a 1aa"},
+		{"SW1A1AA", "United Kingdom", "sw1a 1aa"},
+		{"sw1a  1aa", "UK", "sw1a 1aa"},
+		{"EC3N 1DY", "", "ec3n 1dy"},
+		{"M1 1AE", "", "m1 1ae"},
+		{"B338TH", "GB", "b33 8th"},
+		// Canada
+		{"K1A 0B1", "", "k1a 0b1"},
+		{"k1a0b1", "Canada", "k1a 0b1"},
+		{"M5V-3L9", "CA", "m5v 3l9"},
+		// other countries
+		{"SW1A 1AA", "Spain", "sw1a1aa"},
+		{"1012 AB", "Netherlands", "1012ab"},
+		{"90210-1234", "", "902101234"},
+		{"CH-8022", "Switzerland", "8022"},
+		{"", "", ""},
+	}
+	for _, tc := range cases {
+		if got := normalizePostalCode(tc.code, tc.country); got != tc.expected {
+			t.Errorf("%q (%s): got %q", tc.code, tc.country, got)
+		}
+	}
+}
+
+func TestAddressScoring__postalCodeVariants(t *testing.T) {
+	addrs := precomputeAddresses([]*ofac.Address{
+		{
+			EntityID:                    "306",
+			Address:                     "10 Downing Street",
+			CityStateProvincePostalCode: "London SW1A 2AA",
+			Country:                     "United Kingdom",
+		},
+		{
+			EntityID:                    "552",
+			Address:                     "111 Wellington Street",
+			CityStateProvincePostalCode: "Ottawa, Ontario K1A0A9",
+			Country:                     "Canada",
+		},
+	})
+	if addrs[0].postal != "sw1a 2aa" || addrs[1].postal != "k1a 0a9" {
+		t.Fatalf("postal codes: %q and %q", addrs[0].postal, addrs[1].postal)
+	}
+
+	for _, tc := range []struct {
+		addr *Address
+		zip  string
+	}{
+		{addrs[0], "SW1A2AA"},
+		{addrs[0], "sw1a 2aa"},
+		{addrs[1], "K1A 0A9"},
+		{addrs[1], "k1a0a9"},
+	} {
+		for _, opts := range []searchOptions{{}, {strictAddresses: true}} {
+			weighted := weightedAddressCompare(addressSearchRequest{Zip: tc.zip}, defaultAddressWeights, opts)
+			if w := weighted(tc.addr).weight; w != 1.0 {
+				t.Errorf("%q (strict=%v) scored %.3f against %q", tc.zip, opts.strictAddresses, w, tc.addr.postal)
+			}
+		}
+	}
+
+	// a different postal code of the same area doesn't match exactly
+	strict := weightedAddressCompare(addressSearchRequest{Zip: "SW1A 1AA"}, defaultAddressWeights, searchOptions{strictAddresses: true})
+	if w := strict(addrs[0]).weight; w != 0 {
+		t.Errorf("different postal code scored %.3f", w)
+	}
+}
+
+func TestAddressScoring__minAddressTokens(t *testing.T) {
+	logger := log.NewNopLogger()
+	for env, expected := range map[string]int{"": 1, "3": 3, "0": 1, "two": 1} {
+		if n := getMinAddressTokens(logger, env); n != expected {
+			t.Errorf("%q: got %d", env, n)
+		}
+	}
+
+	s := &searcher{
+		Addresses: precomputeAddresses([]*ofac.Address{
+			{EntityID: "1", Address: "1 Main St", CityStateProvincePostalCode: "New York, NY 10001", Country: "United States"},
+			{EntityID: "2", CityStateProvincePostalCode: "Nyala", Country: "Sudan"},
+			{EntityID: "3", CityStateProvincePostalCode: "Nyon", Country: "Switzerland"},
+			{EntityID: "4", CityStateProvincePostalCode: "Nyeri", Country: "Kenya"},
+		}),
+		pipe: noLogPipeliner,
+	}
+	search := func(query string) []string {
+		t.Helper()
+
+		router := mux.NewRouter()
+		addSearchRoutes(log.NewNopLogger(), router, s)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?minMatch=0.8&"+query, nil))
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: bogus status code: %d", query, w.Code)
+		}
+		var resp struct {
+			Addresses []*ofac.Address `json:"addresses"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for i := range resp.Addresses {
+			ids = append(ids, resp.Addresses[i].EntityID)
+		}
+		return ids
+	}
+
+	// a one token address matches every city starting with "ny"
+	if ids := search("state=NY"); len(ids) != 4 {
+		t.Errorf("addresses: %v", ids)
+	}
+
+	defer func() { minAddressTokens = defaultMinAddressTokens }()
+	minAddressTokens = 2
+
+	if ids := search("state=NY"); len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("addresses: %v", ids)
+	}
+	if ids := search("state=NJ"); len(ids) != 0 {
+		t.Errorf("addresses: %v", ids)
+	}
+
+	// full addresses are still scored fuzzily
+	if ids := search("address=1+main+street&city=new+york&country=united+states"); len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("addresses: %v", ids)
+	}
+}
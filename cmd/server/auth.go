@@ -0,0 +1,227 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// authNone lets every request through
+	authNone = "none"
+
+	// authToken requires one of authTokens as "Authorization: Bearer <token>"
+	authToken = "token"
+
+	// authMTLS requires a client certificate signed by HTTPS_CLIENT_CA_FILE
+	authMTLS = "mtls"
+)
+
+const (
+	// authGroupSearch is every read-only endpoint of the HTTP server, like /search and /ofac/sdn
+	authGroupSearch = "search"
+
+	// authGroupWebhooks is the endpoints managing watches, their webhook deliveries and the
+	// statuses of companies and customers
+	authGroupWebhooks = "webhooks"
+
+	// authGroupAdmin is the endpoints Watchman adds to the admin server, its probes and
+	// metrics are always open
+	authGroupAdmin = "admin"
+)
+
+var (
+	// authRequirements is how requests of each endpoint group are authenticated, main sets it
+	// from AUTH_REQUIREMENTS. By default only search is open.
+	authRequirements = defaultAuthRequirements()
+
+	// authTokens are the bearer tokens accepted by groups requiring authToken, main sets them
+	// from AUTH_TOKENS
+	authTokens []string
+
+	errUnauthorized = errors.New("unauthorized")
+)
+
+func defaultAuthRequirements() map[string]string {
+	return map[string]string{
+		authGroupSearch:   authNone,
+		authGroupWebhooks: authToken,
+		authGroupAdmin:    authToken,
+	}
+}
+
+// getAuthRequirements reads GROUP=requirement pairs (e.g. "search=none,admin=token"), groups
+// which aren't listed keep their default. Invalid values use the defaults for every group.
+//
+// env is the value from an environmental variable
+func getAuthRequirements(logger log.Logger, env string) map[string]string {
+	if strings.TrimSpace(env) == "" {
+		return defaultAuthRequirements()
+	}
+	requirements, err := parseAuthRequirements(env)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid AUTH_REQUIREMENTS=%q, using defaults: %v", env, err))
+		return defaultAuthRequirements()
+	}
+	var groups []string
+	for group, requirement := range requirements {
+		groups = append(groups, fmt.Sprintf("%s=%s", group, requirement))
+	}
+	sort.Strings(groups)
+	logger.Log("main", fmt.Sprintf("Setting auth requirements to %s", strings.Join(groups, ", ")))
+	return requirements
+}
+
+func parseAuthRequirements(raw string) (map[string]string, error) {
+	out := defaultAuthRequirements()
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q isn't GROUP=requirement", pair)
+		}
+		group := strings.ToLower(strings.TrimSpace(parts[0]))
+		if _, exists := out[group]; !exists {
+			return nil, fmt.Errorf("unknown group %q, expected one of %s, %s or %s", parts[0], authGroupSearch, authGroupWebhooks, authGroupAdmin)
+		}
+		if seen[group] {
+			return nil, fmt.Errorf("%s is listed more than once", group)
+		}
+		seen[group] = true
+
+		switch requirement := strings.ToLower(strings.TrimSpace(parts[1])); requirement {
+		case authNone, authToken:
+			out[group] = requirement
+		case authMTLS:
+			if group == authGroupAdmin {
+				return nil, fmt.Errorf("%s can't require %s, the admin server doesn't use TLS", group, authMTLS)
+			}
+			out[group] = requirement
+		default:
+			return nil, fmt.Errorf("invalid requirement %q for %s, expected %s, %s or %s", parts[1], group, authNone, authToken, authMTLS)
+		}
+	}
+	return out, nil
+}
+
+// getAuthTokens reads a comma separated list of bearer tokens
+//
+// env is the value from an environmental variable
+func getAuthTokens(logger log.Logger, env string) []string {
+	var out []string
+	for _, token := range strings.Split(env, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			out = append(out, token)
+		}
+	}
+	if len(out) == 0 {
+		for _, group := range []string{authGroupSearch, authGroupWebhooks, authGroupAdmin} {
+			if authRequirements[group] == authToken {
+				logger.Log("main", fmt.Sprintf("WARN: no AUTH_TOKENS, every request to %s endpoints is rejected", group))
+			}
+		}
+	}
+	return out
+}
+
+// setClientCAs makes serve ask for client certificates signed by a CA of the PEM file at path,
+// which groups requiring authMTLS check. Clients without one can still reach other groups.
+func setClientCAs(serve *http.Server, path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("no certificates found in %s", path)
+	}
+	serve.TLSConfig.ClientCAs = pool
+	serve.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	return nil
+}
+
+// authEndpointGroup returns which group the request of an HTTP server route is in
+func authEndpointGroup(r *http.Request) string {
+	for _, segment := range strings.Split(r.URL.Path, "/") {
+		if segment == "watch" || segment == "watches" {
+			return authGroupWebhooks
+		}
+	}
+	if r.Method == "PUT" {
+		return authGroupWebhooks // company and customer status updates
+	}
+	return authGroupSearch
+}
+
+// authenticated returns true when r satisfies requirement
+func authenticated(requirement string, r *http.Request) bool {
+	switch requirement {
+	case authNone:
+		return true
+	case authToken:
+		header := r.Header.Get("Authorization")
+		if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+			return false
+		}
+		token := []byte(strings.TrimSpace(header[7:]))
+		for i := range authTokens {
+			if subtle.ConstantTimeCompare(token, []byte(authTokens[i])) == 1 {
+				return true
+			}
+		}
+		return false
+	case authMTLS:
+		return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+	}
+	return false
+}
+
+// requireAuth rejects requests to next which don't satisfy the requirement of group, it wraps
+// the handlers added to the admin server
+func requireAuth(logger log.Logger, group string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requirement := authRequirements[group]
+		if r.Method == "OPTIONS" || authenticated(requirement, r) {
+			next(w, r)
+			return
+		}
+		logger.Log("auth", fmt.Sprintf("rejected %s %s without %s", r.Method, r.URL.Path, requirement), "requestID", moovhttp.GetRequestID(r))
+		if requirement == authToken {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": errUnauthorized.Error(),
+		})
+	}
+}
+
+// authMiddleware applies requireAuth to each route of the HTTP server by its group (see
+// authEndpointGroup). CORS preflight requests aren't authenticated.
+func authMiddleware(logger log.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requireAuth(logger, authEndpointGroup(r), next.ServeHTTP)(w, r)
+		})
+	}
+}
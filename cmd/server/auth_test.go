@@ -0,0 +1,194 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/base/admin"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestAuth__getRequirements(t *testing.T) {
+	logger := log.NewNopLogger()
+
+	if v := getAuthRequirements(logger, ""); !reflect.DeepEqual(v, defaultAuthRequirements()) {
+		t.Errorf("got %v", v)
+	}
+	v := getAuthRequirements(logger, " Search = TOKEN, webhooks=mtls")
+	expected := map[string]string{authGroupSearch: authToken, authGroupWebhooks: authMTLS, authGroupAdmin: authToken}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("got %v", v)
+	}
+
+	// invalid values don't open any group
+	for _, env := range []string{"admin=none,other=token", "admin=mtls", "search=basic", "search", "admin=none,admin=token"} {
+		if _, err := parseAuthRequirements(env); err == nil {
+			t.Errorf("%q: expected error", env)
+		}
+		if v := getAuthRequirements(logger, env); !reflect.DeepEqual(v, defaultAuthRequirements()) {
+			t.Errorf("%q: got %v", env, v)
+		}
+	}
+}
+
+func TestAuth__getTokens(t *testing.T) {
+	if v := getAuthTokens(log.NewNopLogger(), " abc ,,def"); !reflect.DeepEqual(v, []string{"abc", "def"}) {
+		t.Errorf("got %v", v)
+	}
+	if v := getAuthTokens(log.NewNopLogger(), ""); len(v) != 0 {
+		t.Errorf("got %v", v)
+	}
+}
+
+func TestAuth__endpointGroup(t *testing.T) {
+	cases := map[string]string{
+		"GET /search":                             authGroupSearch,
+		"POST /search/batch":                      authGroupSearch,
+		"POST /screen":                            authGroupSearch,
+		"GET /ofac/sdn/22790":                     authGroupSearch,
+		"GET /ofac/companies/1234":                authGroupSearch,
+		"PUT /ofac/companies/1234":                authGroupWebhooks,
+		"POST /ofac/customers/1234/watch":         authGroupWebhooks,
+		"DELETE /ofac/companies/watch/abc":        authGroupWebhooks,
+		"GET /ofac/watches":                       authGroupWebhooks,
+		"GET /ofac/watches/abc/deliveries":        authGroupWebhooks,
+		"GET /base/ofac/watches/abc/deliveries":   authGroupWebhooks,
+		"GET /ofac/sdn?name=watch":                authGroupSearch,
+		"GET /ui/values/sdnType":                  authGroupSearch,
+		"GET /search?name=watches+of+switzerland": authGroupSearch,
+	}
+	for req, expected := range cases {
+		parts := strings.SplitN(req, " ", 2)
+		if group := authEndpointGroup(httptest.NewRequest(parts[0], parts[1], nil)); group != expected {
+			t.Errorf("%s: got %s", req, group)
+		}
+	}
+}
+
+func TestAuth__authenticated(t *testing.T) {
+	defer func() { authTokens = nil }()
+	authTokens = []string{"secret", "other"}
+
+	req := httptest.NewRequest("GET", "/search", nil)
+	if !authenticated(authNone, req) || authenticated(authToken, req) || authenticated(authMTLS, req) {
+		t.Error("unauthenticated request")
+	}
+	for header, expected := range map[string]bool{
+		"Bearer secret": true, "bearer other": true, "Bearer  secret ": true,
+		"Bearer secre": false, "Basic secret": false, "secret": false, "Bearer": false,
+	} {
+		req.Header.Set("Authorization", header)
+		if v := authenticated(authToken, req); v != expected {
+			t.Errorf("%q: got %v", header, v)
+		}
+	}
+
+	req.TLS = &tls.ConnectionState{}
+	if authenticated(authMTLS, req) {
+		t.Error("TLS without a client certificate")
+	}
+	req.TLS.VerifiedChains = [][]*x509.Certificate{{&x509.Certificate{}}}
+	if !authenticated(authMTLS, req) {
+		t.Error("verified client certificate")
+	}
+	if authenticated("other", req) {
+		t.Error("unknown requirement")
+	}
+}
+
+func TestAuth__endpoints(t *testing.T) {
+	defer func() {
+		authRequirements = defaultAuthRequirements()
+		authTokens = nil
+	}()
+	logger := log.NewNopLogger()
+	authRequirements = getAuthRequirements(logger, "")
+	authTokens = getAuthTokens(logger, "secret")
+
+	router := mux.NewRouter()
+	router.Use(authMiddleware(logger))
+	addSearchRoutes(logger, router, idSearcher)
+	addWatchRoutes(logger, router, &sqliteWatchRepository{})
+
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+	svc.AddHandler(parseReportPath, requireAuth(logger, authGroupAdmin, parseReportHandler(logger, idSearcher)))
+
+	do := func(t *testing.T, req *http.Request, token string) int {
+		t.Helper()
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if strings.HasPrefix(req.URL.String(), "http://") {
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("WWW-Authenticate: %q", resp.Header.Get("WWW-Authenticate"))
+			}
+			return resp.StatusCode
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		w.Flush()
+		return w.Code
+	}
+	adminURL := "http://" + svc.BindAddr() + parseReportPath
+
+	// by default search is open while admin and webhook endpoints need a token
+	if code := do(t, httptest.NewRequest("GET", "/search?name=nicolas+maduro", nil), ""); code != http.StatusOK {
+		t.Errorf("search: got %d", code)
+	}
+	req, _ := http.NewRequest("GET", adminURL, nil)
+	if code := do(t, req, ""); code != http.StatusUnauthorized {
+		t.Errorf("admin: got %d", code)
+	}
+	req, _ = http.NewRequest("GET", adminURL, nil)
+	if code := do(t, req, "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("admin with the wrong token: got %d", code)
+	}
+	req, _ = http.NewRequest("GET", adminURL, nil)
+	if code := do(t, req, "secret"); code != http.StatusOK {
+		t.Errorf("admin with a token: got %d", code)
+	}
+	if code := do(t, httptest.NewRequest("DELETE", "/ofac/watches", strings.NewReader(`{}`)), ""); code != http.StatusUnauthorized {
+		t.Errorf("watches: got %d", code)
+	}
+
+	// admin probes and metrics stay open
+	resp, err := http.Get("http://" + svc.BindAddr() + "/live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("/live: got %s", resp.Status)
+	}
+
+	// search can be locked down too
+	authRequirements = getAuthRequirements(logger, "search=token")
+	if code := do(t, httptest.NewRequest("GET", "/search?name=nicolas+maduro", nil), ""); code != http.StatusUnauthorized {
+		t.Errorf("search: got %d", code)
+	}
+	if code := do(t, httptest.NewRequest("GET", "/search?name=nicolas+maduro", nil), "secret"); code != http.StatusOK {
+		t.Errorf("search with a token: got %d", code)
+	}
+	authRequirements = getAuthRequirements(logger, "search=mtls")
+	if code := do(t, httptest.NewRequest("GET", "/search?name=nicolas+maduro", nil), "secret"); code != http.StatusUnauthorized {
+		t.Errorf("search without a client certificate: got %d", code)
+	}
+}
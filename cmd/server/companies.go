@@ -36,6 +36,8 @@ type Company struct {
 	// Metadata
 	Status *CompanyStatus `json:"status"`
 	Match  float64        `json:"match,omitempty"`
+	// Changes are how the SDN changed in the last refresh, only sent to webhooks
+	Changes *sdnChange `json:"changes,omitempty"`
 }
 
 // CompanyBlockStatus can be either CompanyUnsafe or CompanyException
@@ -267,13 +269,20 @@ func addCompanyWatch(logger log.Logger, searcher *searcher, repo watchRepository
 			moovhttp.Problem(w, err)
 			return
 		}
+		hooks, err := validateWatchWebhooks(req.Webhooks)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
 		req.Webhook = webhook
 
 		companyID := getCompanyID(w, r)
 		if companyID == "" {
 			return
 		}
-		watchID, err := repo.addCompanyWatch(companyID, req)
+		watchID, err := addWatchAndWebhooks(repo, hooks, func() (string, error) {
+			return repo.addCompanyWatch(companyID, req)
+		})
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
@@ -332,7 +341,14 @@ func addCompanyNameWatch(logger log.Logger, searcher *searcher, repo watchReposi
 			moovhttp.Problem(w, err)
 			return
 		}
-		watchID, err := repo.addCompanyNameWatch(name, webhook, req.AuthToken)
+		hooks, err := validateWatchWebhooks(req.Webhooks)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		watchID, err := addWatchAndWebhooks(repo, hooks, func() (string, error) {
+			return repo.addCompanyNameWatch(name, webhook, req.AuthToken)
+		})
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
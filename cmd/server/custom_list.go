@@ -0,0 +1,116 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/moov-io/watchman/pkg/custom"
+
+	"github.com/go-kit/kit/log"
+)
+
+const defaultCustomListReloadInterval = 30 * time.Second
+
+var (
+	// customListFile is the path of a CSV or JSON custom list searched as the CUSTOM source,
+	// main sets it from CUSTOM_LIST_FILE. Nothing is indexed for CUSTOM when it's empty.
+	customListFile string
+
+	// customListReloadInterval is how often customListFile is checked for changes, which are
+	// reindexed without a full refresh. main sets it from CUSTOM_LIST_RELOAD_INTERVAL.
+	customListReloadInterval = defaultCustomListReloadInterval
+)
+
+// getCustomListReloadInterval reads a non-negative duration (e.g. 1m), zero disables reloads
+//
+// env is the value from an environmental variable
+func getCustomListReloadInterval(logger log.Logger, env string) time.Duration {
+	if env == "" {
+		return defaultCustomListReloadInterval
+	}
+	dur, err := time.ParseDuration(env)
+	if err != nil || dur < 0 {
+		logger.Log("main", fmt.Sprintf("invalid CUSTOM_LIST_RELOAD_INTERVAL=%q, using default of %v", env, defaultCustomListReloadInterval))
+		return defaultCustomListReloadInterval
+	}
+	return dur
+}
+
+// customListRecords reads the custom list at path, there are no records when path is empty
+func customListRecords(path string) ([]*custom.Entry, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return custom.Read(path)
+}
+
+// customListWatcher reindexes the CUSTOM source when its file is changed
+type customListWatcher struct {
+	searcher     *searcher
+	downloadRepo downloadRepository
+	path         string
+
+	// modTime and size are from the last check of path
+	modTime time.Time
+	size    int64
+}
+
+func newCustomListWatcher(s *searcher, downloadRepo downloadRepository, path string) *customListWatcher {
+	w := &customListWatcher{searcher: s, downloadRepo: downloadRepo, path: path}
+	w.changed() // the file was read by the initial refresh
+	return w
+}
+
+// changed returns true when the file's modification time or size differ from the last check
+func (w *customListWatcher) changed() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false // a missing file keeps the indexed records until it's back
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return false
+	}
+	w.modTime, w.size = info.ModTime(), info.Size()
+	return true
+}
+
+// reload reindexes the custom list when its file changed since the last check
+func (w *customListWatcher) reload() (*downloadStats, error) {
+	if !w.changed() {
+		return nil, nil
+	}
+	stats, err := w.searcher.debouncedReindex(sourceCustom)
+	if err != nil {
+		return nil, err
+	}
+	if w.downloadRepo != nil {
+		if err := w.downloadRepo.recordStats(stats); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// watch checks for changes to the custom list every interval
+func (w *customListWatcher) watch(interval time.Duration) {
+	logger := w.searcher.logger
+	if interval <= 0 {
+		logger.Log("download", fmt.Sprintf("not reloading custom list %s on changes", w.path))
+		return
+	}
+	for {
+		time.Sleep(interval)
+		stats, err := w.reload()
+		switch {
+		case err != nil:
+			logger.Log("main", fmt.Sprintf("ERROR: reloading custom list %s: %v", w.path, err))
+		case stats != nil:
+			logger.Log("main", fmt.Sprintf("reloaded custom list %s", w.path), "CUSTOM", stats.CustomEntries)
+		}
+	}
+}
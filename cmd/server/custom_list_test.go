@@ -0,0 +1,165 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/watchman/internal/database"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestCustomList__getReloadInterval(t *testing.T) {
+	logger := log.NewNopLogger()
+	cases := map[string]time.Duration{
+		"":    defaultCustomListReloadInterval,
+		"1m":  time.Minute,
+		"0":   0,
+		"-1s": defaultCustomListReloadInterval,
+		"x":   defaultCustomListReloadInterval,
+	}
+	for env, expected := range cases {
+		if dur := getCustomListReloadInterval(logger, env); dur != expected {
+			t.Errorf("%q: got %v expected %v", env, dur, expected)
+		}
+	}
+}
+
+func TestCustomList__refresh(t *testing.T) {
+	defer func(path string) { customListFile = path }(customListFile)
+
+	dir := copyTestData(t)
+	defer os.RemoveAll(dir)
+
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+
+	// nothing is indexed without a custom list
+	customListFile = ""
+	stats, err := s.refreshData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.CustomEntries) != 0 || stats.CustomEntries != 0 || len(s.staleSourceNames()) != 0 {
+		t.Fatalf("CustomEntries=%d stale=%v", len(s.CustomEntries), s.staleSourceNames())
+	}
+	if _, err := s.reindexSource(dir, sourceCustom); err == nil {
+		t.Error("expected error")
+	}
+
+	customListFile = filepath.Join(dir, "custom-list.csv")
+	stats, err = s.refreshData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.CustomEntries) != 3 || stats.CustomEntries != 3 {
+		t.Fatalf("CustomEntries=%d stats.CustomEntries=%d", len(s.CustomEntries), stats.CustomEntries)
+	}
+
+	// custom entries are searched with the other lists and by themselves
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+	for _, path := range []string{"/search?name=ivan+petrov&limit=1", "/search?name=ivan+petrov&limit=1&sources=custom"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), `"customEntries":[{"id":"BL-001"`) {
+			t.Errorf("%s: missing custom entry: %s", path, w.Body.String())
+		}
+	}
+
+	// reindexing only reads the custom list
+	s.CustomEntries = nil
+	if stats, err = s.reindexSource(dir, sourceCustom); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.CustomEntries) != 3 || stats.CustomEntries != 3 || stats.UnitedNationsSanctions != 5 {
+		t.Errorf("unexpected stats: %#v", stats)
+	}
+
+	// a broken custom list keeps its last-good records
+	if err := ioutil.WriteFile(customListFile, []byte("name\nAcme\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if stale := s.staleSourceNames(); len(s.CustomEntries) != 3 || len(stale) != 1 || stale[0] != sourceCustom {
+		t.Errorf("CustomEntries=%d stale=%v", len(s.CustomEntries), stale)
+	}
+}
+
+func TestCustomList__reload(t *testing.T) {
+	defer func(path string, debounce time.Duration) {
+		customListFile, reindexDebounce = path, debounce
+	}(customListFile, reindexDebounce)
+	reindexDebounce = 0
+
+	dir, err := ioutil.TempDir("", "custom-list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	customListFile = filepath.Join(dir, "blocklist.json")
+	if err := ioutil.WriteFile(customListFile, []byte(`[{"id": "1", "name": "Karina Volkova", "type": "individual"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	if _, err := s.reindexSource("", sourceCustom); err != nil {
+		t.Fatal(err)
+	}
+
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	repo := &sqliteDownloadRepository{db.DB, log.NewNopLogger()}
+
+	w := newCustomListWatcher(s, repo, customListFile)
+	if stats, err := w.reload(); stats != nil || err != nil {
+		t.Fatalf("unchanged file was reloaded: %#v %v", stats, err)
+	}
+
+	// an added record is searchable after the next check
+	contents := `[{"id": "1", "name": "Karina Volkova", "type": "individual"}, {"id": "2", "name": "Apex Crypto Exchange"}]`
+	if err := ioutil.WriteFile(customListFile, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := w.reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats == nil || stats.CustomEntries != 2 {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+	if entries := s.TopCustomEntries(1, "apex crypto exchange", searchOptions{}); len(entries) != 1 || entries[0].Entry.ID != "2" {
+		t.Errorf("%#v", entries)
+	}
+	if downloads, err := repo.latestDownloads(1); err != nil || len(downloads) != 1 || downloads[0].CustomEntries != 2 {
+		t.Errorf("downloads=%#v err=%v", downloads, err)
+	}
+
+	// a removed file keeps the records
+	os.Remove(customListFile)
+	if stats, err := w.reload(); stats != nil || err != nil || len(s.CustomEntries) != 2 {
+		t.Errorf("stats=%#v err=%v CustomEntries=%d", stats, err, len(s.CustomEntries))
+	}
+}
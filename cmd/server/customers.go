@@ -39,6 +39,8 @@ type Customer struct {
 	// Metadata
 	Status *CustomerStatus `json:"status"`
 	Match  float64         `json:"match,omitempty"`
+	// Changes are how the SDN changed in the last refresh, only sent to webhooks
+	Changes *sdnChange `json:"changes,omitempty"`
 }
 
 // CustomerBlockStatus can be either CustomerUnsafe or CustomerException
@@ -229,7 +231,14 @@ func addCustomerNameWatch(logger log.Logger, searcher *searcher, repo *sqliteWat
 			moovhttp.Problem(w, err)
 			return
 		}
-		watchID, err := repo.addCustomerNameWatch(name, webhook, req.AuthToken)
+		hooks, err := validateWatchWebhooks(req.Webhooks)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		watchID, err := addWatchAndWebhooks(repo, hooks, func() (string, error) {
+			return repo.addCustomerNameWatch(name, webhook, req.AuthToken)
+		})
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
@@ -267,10 +276,17 @@ func addCustomerWatch(logger log.Logger, searcher *searcher, repo *sqliteWatchRe
 			moovhttp.Problem(w, err)
 			return
 		}
+		hooks, err := validateWatchWebhooks(req.Webhooks)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
 		req.Webhook = webhook
 
 		customerID := getCustomerID(w, r)
-		watchID, err := repo.addCustomerWatch(customerID, req)
+		watchID, err := addWatchAndWebhooks(repo, hooks, func() (string, error) {
+			return repo.addCustomerWatch(customerID, req)
+		})
 		if err != nil {
 			moovhttp.Problem(w, err)
 			return
@@ -11,12 +11,16 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	moovhttp "github.com/moov-io/base/http"
+	"github.com/moov-io/watchman/pkg/au"
+	"github.com/moov-io/watchman/pkg/ca"
 	"github.com/moov-io/watchman/pkg/csl"
 	"github.com/moov-io/watchman/pkg/dpl"
 	"github.com/moov-io/watchman/pkg/ofac"
+	"github.com/moov-io/watchman/pkg/un"
 
 	"github.com/go-kit/kit/log"
 	"github.com/gorilla/mux"
@@ -54,6 +58,24 @@ type Download struct {
 	// US Bureau of Industry and Security (BIS)
 	DeniedPersons int `json:"deniedPersons"`
 	BISEntities   int `json:"bisEntities"`
+
+	// US State Department
+	NonproliferationSanctions int `json:"nonproliferationSanctions"`
+
+	// Global Affairs Canada
+	CanadianSanctions int `json:"canadianSanctions"`
+
+	// Australian Department of Foreign Affairs and Trade
+	AustralianSanctions int `json:"australianSanctions"`
+
+	// United Nations Security Council
+	UnitedNationsSanctions int `json:"unitedNationsSanctions"`
+
+	// Custom list (CUSTOM_LIST_FILE)
+	CustomEntries int `json:"customEntries"`
+
+	// Sources has the size and record count of each source's files
+	Sources map[string]sourceDownload `json:"sources,omitempty"`
 }
 
 type downloadStats struct {
@@ -67,7 +89,28 @@ type downloadStats struct {
 	DeniedPersons int `json:"deniedPersons"`
 	BISEntities   int `json:"bisEntities"`
 
+	// US State Department
+	NonproliferationSanctions int `json:"nonproliferationSanctions"`
+
+	// Global Affairs Canada
+	CanadianSanctions int `json:"canadianSanctions"`
+
+	// Australian Department of Foreign Affairs and Trade
+	AustralianSanctions int `json:"australianSanctions"`
+
+	// United Nations Security Council
+	UnitedNationsSanctions int `json:"unitedNationsSanctions"`
+
+	// Custom list (CUSTOM_LIST_FILE)
+	CustomEntries int `json:"customEntries"`
+
+	Sources map[string]sourceDownload `json:"sources,omitempty"`
+
 	RefreshedAt time.Time `json:"timestamp"`
+
+	// sdnChanges are the SDNs whose details changed in the refresh or reindex, by EntityID. They're
+	// sent along with the stats so each re-search of watches notifies its own changes.
+	sdnChanges map[string]*sdnChange
 }
 
 // periodicDataRefresh will forever block for interval's duration and then download and reparse the data.
@@ -79,7 +122,7 @@ func (s *searcher) periodicDataRefresh(interval time.Duration, downloadRepo down
 	}
 	for {
 		time.Sleep(interval)
-		stats, err := s.refreshData("")
+		stats, err := s.debouncedRefresh()
 		if err != nil {
 			if s.logger != nil {
 				s.logger.Log("main", fmt.Sprintf("ERROR: refreshing data: %v", err))
@@ -90,7 +133,7 @@ func (s *searcher) periodicDataRefresh(interval time.Duration, downloadRepo down
 				s.logger.Log(
 					"main", fmt.Sprintf("data refreshed %v ago", time.Since(stats.RefreshedAt)),
 					"SDNs", stats.SDNs, "AltNames", stats.Alts, "Addresses", stats.Addresses, "SSI", stats.SectoralSanctions,
-					"DPL", stats.DeniedPersons, "BISEntities", stats.BISEntities,
+					"DPL", stats.DeniedPersons, "BISEntities", stats.BISEntities, "ISN", stats.NonproliferationSanctions, "CA", stats.CanadianSanctions, "AU", stats.AustralianSanctions, "UN", stats.UnitedNationsSanctions, "CUSTOM", stats.CustomEntries,
 				)
 			}
 			updates <- stats // send stats for re-search and watch notifications
@@ -98,13 +141,14 @@ func (s *searcher) periodicDataRefresh(interval time.Duration, downloadRepo down
 	}
 }
 
-func ofacRecords(logger log.Logger, initialDir string) (*ofac.Results, error) {
+// ofacRecords reads every OFAC file, the combined size of them is also returned
+func ofacRecords(logger log.Logger, initialDir string) (*ofac.Results, int64, error) {
 	files, err := ofac.Download(logger, initialDir)
 	if err != nil {
-		return nil, fmt.Errorf("download: %v", err)
+		return nil, 0, fmt.Errorf("download: %v", err)
 	}
 	if len(files) == 0 {
-		return nil, errors.New("no OFAC Results")
+		return nil, 0, errors.New("no OFAC Results")
 	}
 
 	var res *ofac.Results
@@ -113,42 +157,67 @@ func ofacRecords(logger log.Logger, initialDir string) (*ofac.Results, error) {
 		if i == 0 {
 			res, err = ofac.Read(files[i])
 			if err != nil {
-				return nil, fmt.Errorf("read: %v", err)
+				return nil, 0, fmt.Errorf("read: %v", err)
 			}
 		} else {
 			rr, err := ofac.Read(files[i])
 			if err != nil {
-				return nil, fmt.Errorf("read and replace: %v", err)
+				return nil, 0, fmt.Errorf("read and replace: %v", err)
 			}
 
 			res.Addresses = append(res.Addresses, rr.Addresses...)
 			res.AlternateIdentities = append(res.AlternateIdentities, rr.AlternateIdentities...)
 			res.SDNs = append(res.SDNs, rr.SDNs...)
 			res.SDNComments = append(res.SDNComments, rr.SDNComments...)
+			res.Warnings = append(res.Warnings, rr.Warnings...)
 		}
 	}
-	return res, err
+	return res, fileSize(files...), err
 }
 
-func dplRecords(logger log.Logger, initialDir string) ([]*dpl.DPL, error) {
+func dplRecords(logger log.Logger, initialDir string) ([]*dpl.DPL, int64, error) {
 	file, err := dpl.Download(logger, initialDir)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return dpl.Read(file)
+	records, err := dpl.Read(file)
+	return records, fileSize(file), err
 }
 
-func cslRecords(logger log.Logger, initialDir string) (*csl.CSL, error) {
+func cslRecords(logger log.Logger, initialDir string) (*csl.CSL, int64, error) {
 	file, err := csl.Download(logger, initialDir)
 	if err != nil {
-		logger.Log("download", "WARN: skipping CSL download", "description", err)
-		return &csl.CSL{}, nil
+		return nil, 0, fmt.Errorf("download: %v", err)
 	}
-	cslRecords, err := csl.Read(file)
+	records, err := csl.Read(file)
+	return records, fileSize(file), err
+}
+
+func canadianSanctionRecords(logger log.Logger, initialDir string) ([]*ca.Entry, int64, error) {
+	file, err := ca.Download(logger, initialDir)
 	if err != nil {
-		return nil, err
+		return nil, 0, fmt.Errorf("download: %v", err)
 	}
-	return cslRecords, err
+	records, err := ca.Read(file)
+	return records, fileSize(file), err
+}
+
+func australianSanctionRecords(logger log.Logger, initialDir string) ([]*au.Entry, int64, error) {
+	file, err := au.Download(logger, initialDir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("download: %v", err)
+	}
+	records, err := au.Read(file)
+	return records, fileSize(file), err
+}
+
+func unitedNationsSanctionRecords(logger log.Logger, initialDir string) ([]*un.Entry, int64, error) {
+	file, err := un.Download(logger, initialDir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("download: %v", err)
+	}
+	records, err := un.Read(file)
+	return records, fileSize(file), err
 }
 
 // refreshData reaches out to the various websites to download the latest
@@ -162,27 +231,140 @@ func (s *searcher) refreshData(initialDir string) (*downloadStats, error) {
 		}
 	}
 
-	results, err := ofacRecords(s.logger, initialDir)
+	// current records are kept for sources which fail, see sourceFailurePolicy
+	s.RLock()
+	sdns, adds, alts, ssis := s.SDNs, s.Addresses, s.Alts, s.SSIs
+	dps, els, isns := s.DPs, s.BISEntities, s.ISNs
+	cas, aus, uns := s.CanadianSanctions, s.AustralianSanctions, s.UnitedNationsSanctions
+	customs := s.CustomEntries
+	sequence := s.ofacSequence
+	s.RUnlock()
+	prevSDNs, prevAdds, prevAlts := sdns, adds, alts
+
+	report := newParseReport()
+	stale := make(map[string]bool)
+	sizes := make(map[string]int64)
+	var deltaReport *ofacDeltaReport
+	var changedSDNs map[string]bool // compared for watch notifications, nil compares every SDN
+
+	// OFAC deltas are applied onto the current records, every file is downloaded without one
+	delta, err := ofacDeltaRecords(s.logger, initialDir)
+	if err != nil && s.logger != nil {
+		s.logger.Log("download", fmt.Sprintf("ERROR: OFAC delta, downloading every file: %v", err))
+	}
+	switch {
+	case delta != nil && sequence > 0 && delta.Sequence == sequence:
+		if s.logger != nil {
+			s.logger.Log("download", fmt.Sprintf("OFAC records are current with delta %d", sequence))
+		}
+		changedSDNs = make(map[string]bool)
+
+	case ofacDeltaApplies(delta, sequence):
+		deltaReport = newOFACDeltaReport(delta, sdns, time.Now())
+		changedSDNs = delta.Changed()
+		sdns, adds, alts = applyOFACDelta(delta, sdns, adds, alts, s.pipe)
+		report.checkSDNs(sdns, nil)
+		if s.logger != nil {
+			s.logger.Log("download", fmt.Sprintf("applied OFAC delta %d onto %d", delta.Sequence, sequence))
+		}
+		sequence = delta.Sequence
+
+	default:
+		if delta != nil && sequence > 0 && s.logger != nil {
+			s.logger.Log("download", fmt.Sprintf("OFAC delta %d changes %d but records are of %d, downloading every file", delta.Sequence, delta.PreviousSequence, sequence))
+		}
+		results, size, err := ofacRecords(s.logger, initialDir)
+		if err != nil {
+			if err := s.sourceFailed(fmt.Errorf("OFAC records: %v", err), len(sdns) > 0, true, stale, sourceSDN); err != nil {
+				return nil, err
+			}
+		} else {
+			sdns = precomputeSDNs(results.SDNs, results.Addresses, s.pipe)
+			adds = precomputeAddresses(results.Addresses)
+			alts = precomputeAlts(results.AlternateIdentities)
+			report.checkSDNs(sdns, results.Warnings)
+			if !indexRawNames {
+				compactOFACRecords(sdns, adds, alts)
+			}
+			sizes[sourceSDN] = size
+			// the full files include the latest delta's changes
+			sequence = 0
+			if delta != nil {
+				sequence = delta.Sequence
+			}
+		}
+	}
+
+	deniedPersons, size, err := dplRecords(s.logger, initialDir)
+	if err != nil {
+		if err := s.sourceFailed(fmt.Errorf("DPL records: %v", err), len(dps) > 0, true, stale, sourceDPL); err != nil {
+			return nil, err
+		}
+	} else {
+		dps = precomputeDPs(deniedPersons, s.pipe)
+		report.checkDPs(dps)
+		sizes[sourceDPL] = size
+	}
+
+	consolidatedLists, size, err := cslRecords(s.logger, initialDir)
+	if err != nil {
+		kept := len(ssis) > 0 || len(els) > 0 || len(isns) > 0
+		if err := s.sourceFailed(fmt.Errorf("CSL records: %v", err), kept, false, stale, sourceSSI, sourceEL, sourceISN); err != nil {
+			return nil, err
+		}
+	} else {
+		ssis = precomputeSSIs(consolidatedLists.SSIs, s.pipe)
+		els = precomputeBISEntities(consolidatedLists.ELs, s.pipe)
+		isns = precomputeISNs(consolidatedLists.ISNs, s.pipe)
+		report.checkSSIs(ssis)
+		report.checkBISEntities(els)
+		report.checkISNs(isns)
+		sizes[sourceSSI], sizes[sourceEL], sizes[sourceISN] = size, size, size
+	}
+
+	canadianSanctions, size, err := canadianSanctionRecords(s.logger, initialDir)
 	if err != nil {
-		return nil, fmt.Errorf("OFAC records: %v", err)
+		if err := s.sourceFailed(fmt.Errorf("CA records: %v", err), len(cas) > 0, false, stale, sourceCA); err != nil {
+			return nil, err
+		}
+	} else {
+		cas = precomputeCanadianSanctions(canadianSanctions, s.pipe)
+		report.checkCanadianSanctions(cas)
+		sizes[sourceCA] = size
 	}
 
-	sdns := precomputeSDNs(results.SDNs, results.Addresses, s.pipe)
-	adds := precomputeAddresses(results.Addresses)
-	alts := precomputeAlts(results.AlternateIdentities)
+	australianSanctions, size, err := australianSanctionRecords(s.logger, initialDir)
+	if err != nil {
+		if err := s.sourceFailed(fmt.Errorf("AU records: %v", err), len(aus) > 0, false, stale, sourceAU); err != nil {
+			return nil, err
+		}
+	} else {
+		aus = precomputeAustralianSanctions(australianSanctions, s.pipe)
+		report.checkAustralianSanctions(aus)
+		sizes[sourceAU] = size
+	}
 
-	deniedPersons, err := dplRecords(s.logger, initialDir)
+	unitedNationsSanctions, size, err := unitedNationsSanctionRecords(s.logger, initialDir)
 	if err != nil {
-		return nil, fmt.Errorf("DPL records: %v", err)
+		if err := s.sourceFailed(fmt.Errorf("UN records: %v", err), len(uns) > 0, false, stale, sourceUN); err != nil {
+			return nil, err
+		}
+	} else {
+		uns = precomputeUnitedNationsSanctions(unitedNationsSanctions, s.pipe)
+		report.checkUnitedNationsSanctions(uns)
+		sizes[sourceUN] = size
 	}
-	dps := precomputeDPs(deniedPersons, s.pipe)
 
-	consolidatedLists, err := cslRecords(s.logger, initialDir)
+	customEntries, err := customListRecords(customListFile)
 	if err != nil {
-		return nil, fmt.Errorf("CSL records: %v", err)
+		if err := s.sourceFailed(fmt.Errorf("CUSTOM records: %v", err), len(customs) > 0, false, stale, sourceCustom); err != nil {
+			return nil, err
+		}
+	} else {
+		customs = precomputeCustomEntries(customEntries, s.pipe)
+		report.checkCustomEntries(customs)
+		sizes[sourceCustom] = fileSize(customListFile)
 	}
-	ssis := precomputeSSIs(consolidatedLists.SSIs, s.pipe)
-	els := precomputeBISEntities(consolidatedLists.ELs, s.pipe)
 
 	stats := &downloadStats{
 		// OFAC
@@ -193,6 +375,16 @@ func (s *searcher) refreshData(initialDir string) (*downloadStats, error) {
 		// BIS
 		BISEntities:   len(els),
 		DeniedPersons: len(dps),
+		// State Department
+		NonproliferationSanctions: len(isns),
+		// Global Affairs Canada
+		CanadianSanctions: len(cas),
+		// Australian DFAT
+		AustralianSanctions: len(aus),
+		// UN Security Council
+		UnitedNationsSanctions: len(uns),
+		// Custom list
+		CustomEntries: len(customs),
 	}
 	stats.RefreshedAt = lastRefresh(initialDir)
 
@@ -201,24 +393,65 @@ func (s *searcher) refreshData(initialDir string) (*downloadStats, error) {
 	lastDataRefreshCount.WithLabelValues("SSIs").Set(float64(len(ssis)))
 	lastDataRefreshCount.WithLabelValues("BISEntities").Set(float64(len(els)))
 	lastDataRefreshCount.WithLabelValues("DPs").Set(float64(len(dps)))
+	lastDataRefreshCount.WithLabelValues("ISNs").Set(float64(len(isns)))
+	lastDataRefreshCount.WithLabelValues("CanadianSanctions").Set(float64(len(cas)))
+	lastDataRefreshCount.WithLabelValues("AustralianSanctions").Set(float64(len(aus)))
+	lastDataRefreshCount.WithLabelValues("UnitedNationsSanctions").Set(float64(len(uns)))
+	lastDataRefreshCount.WithLabelValues("CustomEntries").Set(float64(len(customs)))
+
+	var sdnIDs *sdnIDIndex
+	if indexSDNIDs {
+		sdnIDs = newSDNIDIndex(sdns)
+	}
+	sdnNames := newSDNNameIndex(sdns, alts)
+	individuals := individualEntityIDs(sdns)
+	sdnChanges := diffSDNRecords(prevSDNs, prevAdds, prevAlts, sdns, adds, alts, changedSDNs)
 
 	// Set new records after precomputation (to minimize lock contention)
 	s.Lock()
 	// OFAC
 	s.SDNs = sdns
+	s.sdnIDs = sdnIDs
+	s.sdnNames = sdnNames
+	s.individuals = individuals
 	s.Addresses = adds
 	s.Alts = alts
 	s.SSIs = ssis
 	// BIS
 	s.DPs = dps
 	s.BISEntities = els
+	// State Department
+	s.ISNs = isns
+	// Global Affairs Canada
+	s.CanadianSanctions = cas
+	// Australian DFAT
+	s.AustralianSanctions = aus
+	// UN Security Council
+	s.UnitedNationsSanctions = uns
+	// Custom list
+	s.CustomEntries = customs
 	// metadata
+	s.ofacSequence = sequence
+	if deltaReport != nil {
+		s.lastOFACDelta = deltaReport
+	}
+	stats.sdnChanges = sdnChanges
+	stats.Sources = s.updateSourceDownloads(sizes)
+	s.nextGeneration()
 	s.lastRefreshedAt = stats.RefreshedAt
+	s.refreshSucceededAt = time.Now()
+	s.lastParseReport = s.lastParseReport.replaceSources(report) // kept sources keep their report
+	s.markStale(stale)
+	s.markRefreshed(stats.RefreshedAt, freshSources(stale)...)
+	warmup := s.warmup
 	s.Unlock()
 
 	if s.logger != nil {
 		s.logger.Log("download", "Finished refresh of data")
 	}
+	if warmup != nil {
+		warmup.run()
+	}
 
 	// record successful data refresh
 	lastDataRefreshSuccess.WithLabelValues().Set(float64(time.Now().Unix()))
@@ -226,6 +459,192 @@ func (s *searcher) refreshData(initialDir string) (*downloadStats, error) {
 	return stats, nil
 }
 
+// reindexSource downloads and reparses one list and swaps it into the index, other lists are
+// left untouched. A failed download is always an error here so the source keeps its current
+// records, regardless of sourceFailurePolicy.
+func (s *searcher) reindexSource(initialDir string, source string) (*downloadStats, error) {
+	if s.logger != nil {
+		s.logger.Log("download", fmt.Sprintf("Starting reindex of %s", source))
+	}
+
+	var swap func()
+	var size int64
+	var sdnChanges map[string]*sdnChange
+	report := newParseReport()
+	switch source {
+	case sourceSDN:
+		results, ofacSize, err := ofacRecords(s.logger, initialDir)
+		if err != nil {
+			return nil, fmt.Errorf("OFAC records: %v", err)
+		}
+		sdns := precomputeSDNs(results.SDNs, results.Addresses, s.pipe)
+		adds := precomputeAddresses(results.Addresses)
+		alts := precomputeAlts(results.AlternateIdentities)
+		report.checkSDNs(sdns, results.Warnings)
+		if !indexRawNames {
+			compactOFACRecords(sdns, adds, alts)
+		}
+		lastDataRefreshCount.WithLabelValues("SDNs").Set(float64(len(sdns)))
+		size = ofacSize
+		var sdnIDs *sdnIDIndex
+		if indexSDNIDs {
+			sdnIDs = newSDNIDIndex(sdns)
+		}
+		sdnNames := newSDNNameIndex(sdns, alts)
+		individuals := individualEntityIDs(sdns)
+		s.RLock()
+		sdnChanges = diffSDNRecords(s.SDNs, s.Addresses, s.Alts, sdns, adds, alts, nil)
+		s.RUnlock()
+		swap = func() {
+			s.SDNs = sdns
+			s.sdnIDs = sdnIDs
+			s.sdnNames = sdnNames
+			s.individuals = individuals
+			s.Addresses = adds
+			s.Alts = alts
+			s.ofacSequence = 0 // unknown, so the next refresh downloads every file
+		}
+
+	case sourceDPL:
+		deniedPersons, dplSize, err := dplRecords(s.logger, initialDir)
+		if err != nil {
+			return nil, fmt.Errorf("DPL records: %v", err)
+		}
+		size = dplSize
+		dps := precomputeDPs(deniedPersons, s.pipe)
+		report.checkDPs(dps)
+		lastDataRefreshCount.WithLabelValues("DPs").Set(float64(len(dps)))
+		swap = func() { s.DPs = dps }
+
+	case sourceSSI, sourceEL, sourceISN:
+		file, err := csl.Download(s.logger, initialDir)
+		if err != nil {
+			return nil, fmt.Errorf("CSL download: %v", err)
+		}
+		size = fileSize(file)
+		consolidatedLists, err := csl.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("CSL records: %v", err)
+		}
+		switch source {
+		case sourceSSI:
+			ssis := precomputeSSIs(consolidatedLists.SSIs, s.pipe)
+			report.checkSSIs(ssis)
+			lastDataRefreshCount.WithLabelValues("SSIs").Set(float64(len(ssis)))
+			swap = func() { s.SSIs = ssis }
+		case sourceEL:
+			els := precomputeBISEntities(consolidatedLists.ELs, s.pipe)
+			report.checkBISEntities(els)
+			lastDataRefreshCount.WithLabelValues("BISEntities").Set(float64(len(els)))
+			swap = func() { s.BISEntities = els }
+		case sourceISN:
+			isns := precomputeISNs(consolidatedLists.ISNs, s.pipe)
+			report.checkISNs(isns)
+			lastDataRefreshCount.WithLabelValues("ISNs").Set(float64(len(isns)))
+			swap = func() { s.ISNs = isns }
+		}
+
+	case sourceCA:
+		file, err := ca.Download(s.logger, initialDir)
+		if err != nil {
+			return nil, fmt.Errorf("CA download: %v", err)
+		}
+		size = fileSize(file)
+		entries, err := ca.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("CA records: %v", err)
+		}
+		cas := precomputeCanadianSanctions(entries, s.pipe)
+		report.checkCanadianSanctions(cas)
+		lastDataRefreshCount.WithLabelValues("CanadianSanctions").Set(float64(len(cas)))
+		swap = func() { s.CanadianSanctions = cas }
+
+	case sourceAU:
+		file, err := au.Download(s.logger, initialDir)
+		if err != nil {
+			return nil, fmt.Errorf("AU download: %v", err)
+		}
+		size = fileSize(file)
+		entries, err := au.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("AU records: %v", err)
+		}
+		aus := precomputeAustralianSanctions(entries, s.pipe)
+		report.checkAustralianSanctions(aus)
+		lastDataRefreshCount.WithLabelValues("AustralianSanctions").Set(float64(len(aus)))
+		swap = func() { s.AustralianSanctions = aus }
+
+	case sourceUN:
+		file, err := un.Download(s.logger, initialDir)
+		if err != nil {
+			return nil, fmt.Errorf("UN download: %v", err)
+		}
+		size = fileSize(file)
+		entries, err := un.Read(file)
+		if err != nil {
+			return nil, fmt.Errorf("UN records: %v", err)
+		}
+		uns := precomputeUnitedNationsSanctions(entries, s.pipe)
+		report.checkUnitedNationsSanctions(uns)
+		lastDataRefreshCount.WithLabelValues("UnitedNationsSanctions").Set(float64(len(uns)))
+		swap = func() { s.UnitedNationsSanctions = uns }
+
+	case sourceCustom:
+		if customListFile == "" {
+			return nil, errors.New("CUSTOM_LIST_FILE isn't set")
+		}
+		entries, err := customListRecords(customListFile)
+		if err != nil {
+			return nil, fmt.Errorf("CUSTOM records: %v", err)
+		}
+		size = fileSize(customListFile)
+		customs := precomputeCustomEntries(entries, s.pipe)
+		report.checkCustomEntries(customs)
+		lastDataRefreshCount.WithLabelValues("CustomEntries").Set(float64(len(customs)))
+		swap = func() { s.CustomEntries = customs }
+
+	default:
+		return nil, fmt.Errorf("unknown source %q, expected one of %s", source, strings.Join(knownSources, ", "))
+	}
+
+	s.Lock()
+	swap()
+	s.nextGeneration()
+	if _, exists := s.staleSources[source]; exists {
+		delete(s.staleSources, source)
+		dataSourceStale.WithLabelValues(source).Set(0)
+	}
+	s.lastParseReport = s.lastParseReport.replaceSources(report)
+	s.lastRefreshedAt = lastRefresh(initialDir)
+	s.markRefreshed(s.lastRefreshedAt, source)
+	stats := &downloadStats{
+		SDNs:                      len(s.SDNs),
+		Alts:                      len(s.Alts),
+		Addresses:                 len(s.Addresses),
+		SectoralSanctions:         len(s.SSIs),
+		BISEntities:               len(s.BISEntities),
+		DeniedPersons:             len(s.DPs),
+		NonproliferationSanctions: len(s.ISNs),
+		CanadianSanctions:         len(s.CanadianSanctions),
+		AustralianSanctions:       len(s.AustralianSanctions),
+		UnitedNationsSanctions:    len(s.UnitedNationsSanctions),
+		CustomEntries:             len(s.CustomEntries),
+		Sources:                   s.updateSourceDownloads(map[string]int64{source: size}),
+		RefreshedAt:               s.lastRefreshedAt,
+		sdnChanges:                sdnChanges,
+	}
+	warmup := s.warmup
+	s.Unlock()
+
+	if s.logger != nil {
+		s.logger.Log("download", fmt.Sprintf("Finished reindex of %s", source))
+	}
+	if warmup != nil {
+		warmup.run()
+	}
+	return stats, nil
+}
+
 // lastRefresh returns a time.Time for the oldest file in dir or the current time if empty.
 func lastRefresh(dir string) time.Time {
 	if dir == "" {
@@ -294,19 +713,41 @@ func (r *sqliteDownloadRepository) recordStats(stats *downloadStats) error {
 		return errors.New("recordStats: nil downloadStats")
 	}
 
-	query := `insert into download_stats (downloaded_at, sdns, alt_names, addresses, sectoral_sanctions, denied_persons, bis_entities) values (?, ?, ?, ?, ?, ?, ?);`
+	query := `insert into download_stats (downloaded_at, sdns, alt_names, addresses, sectoral_sanctions, denied_persons, bis_entities, nonproliferation_sanctions, canadian_sanctions, australian_sanctions, united_nations_sanctions, custom_entries) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);`
 	stmt, err := r.db.Prepare(query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(stats.RefreshedAt, stats.SDNs, stats.Alts, stats.Addresses, stats.SectoralSanctions, stats.DeniedPersons, stats.BISEntities)
-	return err
+	_, err = stmt.Exec(stats.RefreshedAt, stats.SDNs, stats.Alts, stats.Addresses, stats.SectoralSanctions, stats.DeniedPersons, stats.BISEntities, stats.NonproliferationSanctions, stats.CanadianSanctions, stats.AustralianSanctions, stats.UnitedNationsSanctions, stats.CustomEntries)
+	if err != nil {
+		return err
+	}
+	return r.recordSourceStats(stats)
+}
+
+func (r *sqliteDownloadRepository) recordSourceStats(stats *downloadStats) error {
+	if len(stats.Sources) == 0 {
+		return nil
+	}
+	query := `insert into download_source_stats (downloaded_at, source, size_bytes, record_count) values (?, ?, ?, ?);`
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for source, dl := range stats.Sources {
+		if _, err := stmt.Exec(stats.RefreshedAt, source, dl.SizeBytes, dl.RecordCount); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *sqliteDownloadRepository) latestDownloads(limit int) ([]Download, error) {
-	query := `select downloaded_at, sdns, alt_names, addresses, sectoral_sanctions, denied_persons, bis_entities from download_stats order by downloaded_at desc limit ?;`
+	query := `select downloaded_at, sdns, alt_names, addresses, sectoral_sanctions, denied_persons, bis_entities, nonproliferation_sanctions, canadian_sanctions, australian_sanctions, united_nations_sanctions, custom_entries from download_stats order by downloaded_at desc limit ?;`
 	stmt, err := r.db.Prepare(query)
 	if err != nil {
 		return nil, err
@@ -322,9 +763,50 @@ func (r *sqliteDownloadRepository) latestDownloads(limit int) ([]Download, error
 	var downloads []Download
 	for rows.Next() {
 		var dl Download
-		if err := rows.Scan(&dl.Timestamp, &dl.SDNs, &dl.Alts, &dl.Addresses, &dl.SectoralSanctions, &dl.DeniedPersons, &dl.BISEntities); err == nil {
+		if err := rows.Scan(&dl.Timestamp, &dl.SDNs, &dl.Alts, &dl.Addresses, &dl.SectoralSanctions, &dl.DeniedPersons, &dl.BISEntities, &dl.NonproliferationSanctions, &dl.CanadianSanctions, &dl.AustralianSanctions, &dl.UnitedNationsSanctions, &dl.CustomEntries); err == nil {
 			downloads = append(downloads, dl)
 		}
 	}
-	return downloads, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range downloads {
+		sources, err := r.sourceStats(downloads[i].Timestamp)
+		if err != nil {
+			return nil, err
+		}
+		downloads[i].Sources = sources
+	}
+	return downloads, nil
+}
+
+// sourceStats returns the size and record count of each source downloaded at downloadedAt,
+// downloads recorded before they were kept have none
+func (r *sqliteDownloadRepository) sourceStats(downloadedAt time.Time) (map[string]sourceDownload, error) {
+	query := `select source, size_bytes, record_count from download_source_stats where downloaded_at = ?;`
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(downloadedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources map[string]sourceDownload
+	for rows.Next() {
+		var source string
+		var dl sourceDownload
+		if err := rows.Scan(&source, &dl.SizeBytes, &dl.RecordCount); err != nil {
+			return nil, err
+		}
+		if sources == nil {
+			sources = make(map[string]sourceDownload)
+		}
+		sources[source] = dl
+	}
+	return sources, rows.Err()
 }
@@ -0,0 +1,109 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+const defaultReindexDebounce = time.Second
+
+// reindexDebounce is how long a refresh or reindex waits for more triggers of the same rebuild,
+// which share its result instead of queuing their own. Zero runs every trigger on its own.
+// main sets it from REINDEX_DEBOUNCE.
+var reindexDebounce = defaultReindexDebounce
+
+// getReindexDebounce reads a non-negative duration (e.g. 500ms)
+//
+// env is the value from an environmental variable
+func getReindexDebounce(logger log.Logger, env string) time.Duration {
+	if env == "" {
+		return defaultReindexDebounce
+	}
+	dur, err := time.ParseDuration(env)
+	if err != nil || dur < 0 {
+		logger.Log("main", fmt.Sprintf("invalid REINDEX_DEBOUNCE=%q, using default of %v", env, defaultReindexDebounce))
+		return defaultReindexDebounce
+	}
+	if dur == 0 {
+		logger.Log("main", "REINDEX_DEBOUNCE=0, every refresh and reindex runs on its own")
+	} else {
+		logger.Log("main", fmt.Sprintf("Coalescing refreshes and reindexes triggered within %v", dur))
+	}
+	return dur
+}
+
+// rebuildCoalescer merges a burst of triggers for the same rebuild (a full refresh or the reindex
+// of one source) into a single run. The first trigger waits for the debounce window, triggers
+// during the window join it and every caller receives the stats of that run. Triggers after the
+// rebuild started wait for the next one, so they always see the latest data.
+//
+// Rebuilds of different keys run one at a time, otherwise a reindex could swap in records
+// parsed before a concurrent refresh and undo it.
+type rebuildCoalescer struct {
+	mu      sync.Mutex
+	pending map[string]*pendingRebuild
+
+	running sync.Mutex // held by the rebuild in progress
+}
+
+type pendingRebuild struct {
+	done  chan struct{}
+	stats *downloadStats
+	err   error
+}
+
+// do runs rebuild once per burst of calls with the same key and window
+func (c *rebuildCoalescer) do(key string, window time.Duration, rebuild func() (*downloadStats, error)) (*downloadStats, error) {
+	if window <= 0 {
+		return c.run(rebuild)
+	}
+
+	c.mu.Lock()
+	if c.pending == nil {
+		c.pending = make(map[string]*pendingRebuild)
+	}
+	p, exists := c.pending[key]
+	if !exists {
+		p = &pendingRebuild{done: make(chan struct{})}
+		c.pending[key] = p
+		time.AfterFunc(window, func() {
+			c.mu.Lock()
+			delete(c.pending, key)
+			c.mu.Unlock()
+
+			p.stats, p.err = c.run(rebuild)
+			close(p.done)
+		})
+	}
+	c.mu.Unlock()
+
+	<-p.done
+	return p.stats, p.err
+}
+
+func (c *rebuildCoalescer) run(rebuild func() (*downloadStats, error)) (*downloadStats, error) {
+	c.running.Lock()
+	defer c.running.Unlock()
+	return rebuild()
+}
+
+// debouncedRefresh is refreshData coalesced with other refreshes triggered within reindexDebounce
+func (s *searcher) debouncedRefresh() (*downloadStats, error) {
+	return s.rebuilds.do("", reindexDebounce, func() (*downloadStats, error) {
+		return s.refreshData("")
+	})
+}
+
+// debouncedReindex is reindexSource coalesced with reindexes of source triggered within reindexDebounce
+func (s *searcher) debouncedReindex(source string) (*downloadStats, error) {
+	return s.rebuilds.do(source, reindexDebounce, func() (*downloadStats, error) {
+		return s.reindexSource("", source)
+	})
+}
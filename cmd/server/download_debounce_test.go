@@ -0,0 +1,137 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestReindexDebounce__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	cases := map[string]time.Duration{
+		"":      defaultReindexDebounce,
+		"0":     0,
+		"250ms": 250 * time.Millisecond,
+		"-1s":   defaultReindexDebounce,
+		"soon":  defaultReindexDebounce,
+	}
+	for env, expected := range cases {
+		if dur := getReindexDebounce(logger, env); dur != expected {
+			t.Errorf("%q: got %v expected %v", env, dur, expected)
+		}
+	}
+}
+
+func TestReindexDebounce__coalesce(t *testing.T) {
+	var c rebuildCoalescer
+	var rebuilds int32
+	rebuild := func() (*downloadStats, error) {
+		n := atomic.AddInt32(&rebuilds, 1)
+		return &downloadStats{SDNs: int(n)}, nil
+	}
+
+	// a burst of triggers shares one rebuild
+	var wg sync.WaitGroup
+	results := make([]*downloadStats, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stats, err := c.do(sourceSDN, 50*time.Millisecond, rebuild)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = stats
+		}(i)
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&rebuilds); n != 1 {
+		t.Fatalf("got %d rebuilds", n)
+	}
+	for i := range results {
+		if results[i] != results[0] {
+			t.Errorf("#%d: got %#v expected %#v", i, results[i], results[0])
+		}
+	}
+
+	// later triggers rebuild again against the latest data
+	if stats, _ := c.do(sourceSDN, 10*time.Millisecond, rebuild); stats.SDNs != 2 {
+		t.Errorf("SDNs=%d", stats.SDNs)
+	}
+
+	// other sources aren't merged
+	sdn := make(chan *downloadStats)
+	go func() {
+		stats, _ := c.do(sourceSDN, 50*time.Millisecond, rebuild)
+		sdn <- stats
+	}()
+	ca, _ := c.do(sourceCA, 50*time.Millisecond, rebuild)
+	if other := <-sdn; other == ca || atomic.LoadInt32(&rebuilds) != 4 {
+		t.Errorf("rebuilds=%d", atomic.LoadInt32(&rebuilds))
+	}
+
+	// every caller gets the error
+	failed := errors.New("download failed")
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.do("", 20*time.Millisecond, func() (*downloadStats, error) { return nil, failed }); err != failed {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestReindexDebounce__serialized(t *testing.T) {
+	var c rebuildCoalescer
+	var running, overlapped int32
+	rebuild := func() (*downloadStats, error) {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return &downloadStats{}, nil
+	}
+
+	// a refresh and reindexes of several sources triggered at once, with and without a window
+	var wg sync.WaitGroup
+	for _, key := range []string{"", sourceSDN, sourceEL, sourceCustom} {
+		for _, window := range []time.Duration{0, time.Millisecond} {
+			wg.Add(1)
+			go func(key string, window time.Duration) {
+				defer wg.Done()
+				c.do(key, window, rebuild)
+			}(key, window)
+		}
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&overlapped) != 0 {
+		t.Error("rebuilds of different keys ran at the same time")
+	}
+}
+
+func TestReindexDebounce__disabled(t *testing.T) {
+	var c rebuildCoalescer
+	var rebuilds int32
+	for i := 0; i < 3; i++ {
+		c.do(sourceSDN, 0, func() (*downloadStats, error) {
+			atomic.AddInt32(&rebuilds, 1)
+			return &downloadStats{}, nil
+		})
+	}
+	if n := atomic.LoadInt32(&rebuilds); n != 3 {
+		t.Errorf("got %d rebuilds", n)
+	}
+}
@@ -8,21 +8,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
 	moovhttp "github.com/moov-io/base/http"
 )
 
 const (
 	manualRefreshPath = "/data/refresh"
+
+	sourceReindexPath = "/admin/sources/{source}/reindex"
 )
 
-// manualRefreshHandler will register an endpoint on the admin server data refresh endpoint
-func manualRefreshHandler(logger log.Logger, searcher *searcher, downloadRepo downloadRepository) http.HandlerFunc {
+// manualRefreshHandler will register an endpoint on the admin server data refresh endpoint.
+// Watches are re-searched after the refresh, as they are after a periodic one.
+func manualRefreshHandler(logger log.Logger, searcher *searcher, downloadRepo downloadRepository, updates chan *downloadStats) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		logger.Log("main", "admin: refreshing data")
-		if stats, err := searcher.refreshData(""); err != nil {
+		if stats, err := searcher.debouncedRefresh(); err != nil {
 			logger.Log("main", fmt.Sprintf("ERROR: admin: problem refreshing data: %v", err))
 			w.WriteHeader(http.StatusInternalServerError)
 		} else {
@@ -33,10 +38,56 @@ func manualRefreshHandler(logger log.Logger, searcher *searcher, downloadRepo do
 			logger.Log(
 				"main", fmt.Sprintf("admin: finished data refreshed %v ago", time.Since(stats.RefreshedAt)),
 				"SDNs", stats.SDNs, "AltNames", stats.Alts, "Addresses", stats.Addresses, "SSI", stats.SectoralSanctions,
-				"DPL", stats.DeniedPersons, "BISEntities", stats.BISEntities,
+				"DPL", stats.DeniedPersons, "BISEntities", stats.BISEntities, "ISN", stats.NonproliferationSanctions, "CA", stats.CanadianSanctions, "AU", stats.AustralianSanctions, "UN", stats.UnitedNationsSanctions, "CUSTOM", stats.CustomEntries,
 			)
+			researchWatches(updates, stats)
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(stats)
 		}
 	}
 }
+
+// sourceReindexHandler re-downloads and reindexes a single source (e.g. SDN or CA) on the admin server.
+// Watches are re-searched after the SDN source is reindexed, as they are after a refresh.
+func sourceReindexHandler(logger log.Logger, searcher *searcher, downloadRepo downloadRepository, updates chan *downloadStats) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		source := strings.ToUpper(strings.TrimSpace(mux.Vars(r)["source"]))
+		if !isKnownSource(source) {
+			moovhttp.Problem(w, fmt.Errorf("unknown source %q, expected one of %s", source, strings.Join(knownSources, ", ")))
+			return
+		}
+
+		logger.Log("main", fmt.Sprintf("admin: reindexing %s", source))
+		stats, err := searcher.debouncedReindex(source)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("ERROR: admin: problem reindexing %s: %v", source, err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if err := downloadRepo.recordStats(stats); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		logger.Log("main", fmt.Sprintf("admin: finished reindexing %s", source), "timestamp", stats.RefreshedAt.Format(time.RFC3339))
+		if source == sourceSDN {
+			researchWatches(updates, stats) // watches only match SDNs
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(stats)
+	}
+}
+
+// researchWatches sends stats for re-search and watch notifications without waiting on a
+// re-search which is still running. The stats carry their own SDN changes, so a later refresh
+// doesn't replace what's notified.
+func researchWatches(updates chan *downloadStats, stats *downloadStats) {
+	if updates != nil {
+		go func() { updates <- stats }()
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/moov-io/base"
+	"github.com/moov-io/base/admin"
 	"github.com/moov-io/watchman/internal/database"
 
 	"github.com/go-kit/kit/log"
@@ -33,7 +34,7 @@ func TestDownload__manualRefreshPath(t *testing.T) {
 		req := httptest.NewRequest("GET", "/data/refresh", nil)
 		req.Header.Set("x-request-id", base.ID())
 
-		manualRefreshHandler(log.NewNopLogger(), searcher, repo)(w, req)
+		manualRefreshHandler(log.NewNopLogger(), searcher, repo, nil)(w, req)
 		w.Flush()
 
 		if w.Code != http.StatusOK {
@@ -58,3 +59,46 @@ func TestDownload__manualRefreshPath(t *testing.T) {
 	defer mysqlDB.Close()
 	check(t, &sqliteDownloadRepository{mysqlDB.DB, log.NewNopLogger()})
 }
+
+func TestDownload__sourceReindexPath(t *testing.T) {
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	repo := &sqliteDownloadRepository{sqliteDB.DB, log.NewNopLogger()}
+
+	svc := admin.NewServer(":0")
+	go svc.Listen()
+	defer svc.Shutdown()
+
+	searcher := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	svc.AddHandler(sourceReindexPath, sourceReindexHandler(log.NewNopLogger(), searcher, repo, nil))
+
+	reindex := func(method, source string) *http.Response {
+		req, _ := http.NewRequest(method, "http://"+svc.BindAddr()+"/admin/sources/"+source+"/reindex", nil)
+		req.Header.Set("x-request-id", base.ID())
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+	if resp := reindex("POST", "other"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("bogus status code: %s", resp.Status)
+	}
+	if resp := reindex("GET", "sdn"); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("bogus status code: %s", resp.Status)
+	}
+
+	if testing.Short() {
+		return
+	}
+	if resp := reindex("POST", "sdn"); resp.StatusCode != http.StatusOK {
+		t.Errorf("bogus status code: %s", resp.Status)
+	}
+	if len(searcher.SDNs) == 0 || len(searcher.DPs) != 0 {
+		t.Errorf("SDNs=%d DPs=%d", len(searcher.SDNs), len(searcher.DPs))
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/moov-io/watchman/pkg/download"
+
+	"github.com/go-kit/kit/log"
+)
+
+// getDownloadMaxAttempts reads how many requests are made for each file of a source before it
+// fails, which is a positive number
+//
+// env is the value from an environmental variable
+func getDownloadMaxAttempts(logger log.Logger, env string) int {
+	if env == "" {
+		return download.MaxAttempts
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil || n < 1 {
+		logger.Log("main", fmt.Sprintf("invalid DOWNLOAD_MAX_ATTEMPTS=%q, using default of %d", env, download.MaxAttempts))
+		return download.MaxAttempts
+	}
+	logger.Log("main", fmt.Sprintf("Making up to %d attempts to download each file", n))
+	return n
+}
+
+// getDownloadRetryBackoff reads a non-negative duration (e.g. 2s) to wait after the first
+// failed attempt of a file, it doubles after each further one
+//
+// env is the value from an environmental variable
+func getDownloadRetryBackoff(logger log.Logger, env string) time.Duration {
+	if env == "" {
+		return download.RetryBackoff
+	}
+	dur, err := time.ParseDuration(env)
+	if err != nil || dur < 0 {
+		logger.Log("main", fmt.Sprintf("invalid DOWNLOAD_RETRY_BACKOFF=%q, using default of %v", env, download.RetryBackoff))
+		return download.RetryBackoff
+	}
+	logger.Log("main", fmt.Sprintf("Retrying failed downloads after %v, doubling after each attempt", dur))
+	return dur
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moov-io/watchman/pkg/download"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestDownloadRetry__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	for env, expected := range map[string]int{"": download.MaxAttempts, "5": 5, "0": download.MaxAttempts, "five": download.MaxAttempts} {
+		if n := getDownloadMaxAttempts(logger, env); n != expected {
+			t.Errorf("%q: got %d", env, n)
+		}
+	}
+	for env, expected := range map[string]time.Duration{"": download.RetryBackoff, "2s": 2 * time.Second, "0s": 0, "-1s": download.RetryBackoff, "asdf": download.RetryBackoff} {
+		if dur := getDownloadRetryBackoff(logger, env); dur != expected {
+			t.Errorf("%q: got %v", env, dur)
+		}
+	}
+}
+
+func TestDownloadRetry__source(t *testing.T) {
+	defer func(v string) { ofacDeltaURL = v }(ofacDeltaURL)
+	defer func(attempts int, backoff time.Duration) {
+		download.MaxAttempts, download.RetryBackoff = attempts, backoff
+	}(download.MaxAttempts, download.RetryBackoff)
+
+	delta, err := ioutil.ReadFile(filepath.Join("..", "..", "test", "testdata", "sdn_delta.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the delta's server fails twice before responding
+	var mu sync.Mutex
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+		if n <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write(delta)
+	}))
+	defer server.Close()
+	ofacDeltaURL = server.URL + "/sdn_delta.json"
+
+	download.MaxAttempts, download.RetryBackoff = 3, time.Millisecond
+	loaded, err := ofacDeltaRecords(log.NewNopLogger(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Sequence != 2 || len(loaded.Removed) != 1 || requests != 3 {
+		t.Errorf("sequence=%d removed=%v after %d requests", loaded.Sequence, loaded.Removed, requests)
+	}
+
+	// without retries the source fails
+	requests = 0
+	download.MaxAttempts = 1
+	if _, err := ofacDeltaRecords(log.NewNopLogger(), ""); err == nil {
+		t.Error("expected error")
+	}
+	if requests != 1 {
+		t.Errorf("%d requests", requests)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+)
+
+// sourceDownload is the size of a source's files and how many records were parsed from them,
+// used to monitor how each list grows
+type sourceDownload struct {
+	SizeBytes   int64 `json:"sizeBytes"`
+	RecordCount int   `json:"recordCount"`
+}
+
+// fileSize returns the combined size of paths, files which can't be read count as empty
+func fileSize(paths ...string) int64 {
+	var size int64
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// updateSourceDownloads records the size of each source in sizes along with the number of
+// records of every source, the caller must hold the write lock. Sources which weren't
+// downloaded (because they failed, or an OFAC delta was applied) keep their last size.
+//
+// SSI, EL and ISN are parsed from the same file, so they each report its size.
+func (s *searcher) updateSourceDownloads(sizes map[string]int64) map[string]sourceDownload {
+	counts := map[string]int{
+		sourceSDN:    len(s.SDNs),
+		sourceSSI:    len(s.SSIs),
+		sourceDPL:    len(s.DPs),
+		sourceEL:     len(s.BISEntities),
+		sourceISN:    len(s.ISNs),
+		sourceCA:     len(s.CanadianSanctions),
+		sourceAU:     len(s.AustralianSanctions),
+		sourceUN:     len(s.UnitedNationsSanctions),
+		sourceCustom: len(s.CustomEntries),
+	}
+	out := make(map[string]sourceDownload)
+	for _, source := range knownSources {
+		if source == sourceCustom && customListFile == "" {
+			continue
+		}
+		size, exists := sizes[source]
+		if !exists {
+			size = s.sourceDownloads[source].SizeBytes
+		}
+		out[source] = sourceDownload{SizeBytes: size, RecordCount: counts[source]}
+	}
+	s.sourceDownloads = out
+	return out
+}
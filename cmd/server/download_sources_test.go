@@ -0,0 +1,78 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moov-io/watchman/internal/database"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestDownload__sources(t *testing.T) {
+	dir := filepath.Join("..", "..", "test", "testdata")
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	stats, err := s.refreshData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]sourceDownload{
+		sourceSDN: {
+			SizeBytes:   fileSize(filepath.Join(dir, "sdn.csv"), filepath.Join(dir, "add.csv"), filepath.Join(dir, "alt.csv"), filepath.Join(dir, "sdn_comments.csv")),
+			RecordCount: len(s.SDNs),
+		},
+		sourceDPL: {SizeBytes: fileSize(filepath.Join(dir, "dpl.txt")), RecordCount: len(s.DPs)},
+		sourceSSI: {SizeBytes: fileSize(filepath.Join(dir, "csl.csv")), RecordCount: len(s.SSIs)},
+		sourceEL:  {SizeBytes: fileSize(filepath.Join(dir, "csl.csv")), RecordCount: len(s.BISEntities)},
+		sourceISN: {SizeBytes: fileSize(filepath.Join(dir, "csl.csv")), RecordCount: len(s.ISNs)},
+		sourceCA:  {SizeBytes: fileSize(filepath.Join(dir, "sema-lmes.xml")), RecordCount: 4},
+		sourceAU:  {SizeBytes: fileSize(filepath.Join(dir, "regulation8_consolidated.xlsx")), RecordCount: 3},
+		sourceUN:  {SizeBytes: fileSize(filepath.Join(dir, "consolidated.xml")), RecordCount: 5},
+	}
+	for source, dl := range expected {
+		if dl.SizeBytes == 0 || dl.RecordCount == 0 {
+			t.Fatalf("%s: empty fixture %#v", source, dl)
+		}
+	}
+	if !reflect.DeepEqual(stats.Sources, expected) {
+		t.Errorf("got %#v", stats.Sources)
+	}
+
+	// reindexing a source keeps the size of the others
+	s.CanadianSanctions = nil
+	stats, err = s.reindexSource(dir, sourceCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stats.Sources, expected) {
+		t.Errorf("got %#v", stats.Sources)
+	}
+
+	// and they're kept with the download
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	repo := &sqliteDownloadRepository{db.DB, log.NewNopLogger()}
+	if err := repo.recordStats(stats); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.recordStats(&downloadStats{SDNs: 1, RefreshedAt: stats.RefreshedAt.Add(-time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	downloads, err := repo.latestDownloads(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(downloads) != 2 || !reflect.DeepEqual(downloads[0].Sources, expected) || downloads[1].Sources != nil {
+		t.Errorf("got %#v", downloads)
+	}
+}
@@ -72,13 +72,91 @@ func TestSearcher__refreshData(t *testing.T) {
 	}
 }
 
+func TestSearcher__refreshData_initialDir(t *testing.T) {
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	stats, err := s.refreshData(filepath.Join("..", "..", "test", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.CanadianSanctions) != 4 || stats.CanadianSanctions != 4 {
+		t.Errorf("CanadianSanctions=%d stats.CanadianSanctions=%d", len(s.CanadianSanctions), stats.CanadianSanctions)
+	}
+	if len(s.AustralianSanctions) != 3 || stats.AustralianSanctions != 3 {
+		t.Errorf("AustralianSanctions=%d stats.AustralianSanctions=%d", len(s.AustralianSanctions), stats.AustralianSanctions)
+	}
+	if len(s.UnitedNationsSanctions) != 5 || stats.UnitedNationsSanctions != 5 {
+		t.Errorf("UnitedNationsSanctions=%d stats.UnitedNationsSanctions=%d", len(s.UnitedNationsSanctions), stats.UnitedNationsSanctions)
+	}
+}
+
+func TestSearcher__reindexSource(t *testing.T) {
+	dir := filepath.Join("..", "..", "test", "testdata")
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	sdns, dps := s.SDNs, s.DPs
+	if len(sdns) == 0 || len(dps) == 0 {
+		t.Fatalf("SDNs=%d DPs=%d", len(sdns), len(dps))
+	}
+
+	// pretend the Canadian list was corrupted and rebuild only it
+	s.CanadianSanctions = nil
+	stats, err := s.reindexSource(dir, sourceCA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.CanadianSanctions) != 4 || stats.CanadianSanctions != 4 {
+		t.Errorf("CanadianSanctions=%d stats.CanadianSanctions=%d", len(s.CanadianSanctions), stats.CanadianSanctions)
+	}
+	if stats.SDNs != len(sdns) || stats.DeniedPersons != len(dps) {
+		t.Errorf("unexpected stats: %#v", stats)
+	}
+	if &s.SDNs[0] != &sdns[0] || &s.DPs[0] != &dps[0] {
+		t.Error("other sources were reindexed")
+	}
+	if !stats.RefreshedAt.Equal(s.lastRefreshedAt) {
+		t.Errorf("RefreshedAt=%v lastRefreshedAt=%v", stats.RefreshedAt, s.lastRefreshedAt)
+	}
+	if !s.sourceRefreshedAt[sourceCA].Equal(stats.RefreshedAt) || s.sourceRefreshedAt[sourceSDN].IsZero() {
+		t.Errorf("sourceRefreshedAt=%v", s.sourceRefreshedAt)
+	}
+
+	s.AustralianSanctions = nil
+	if stats, err = s.reindexSource(dir, sourceAU); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.AustralianSanctions) != 3 || stats.AustralianSanctions != 3 || stats.CanadianSanctions != 4 {
+		t.Errorf("unexpected stats: %#v", stats)
+	}
+
+	s.UnitedNationsSanctions = nil
+	if stats, err = s.reindexSource(dir, sourceUN); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.UnitedNationsSanctions) != 5 || stats.UnitedNationsSanctions != 5 || stats.AustralianSanctions != 3 {
+		t.Errorf("unexpected stats: %#v", stats)
+	}
+
+	if _, err := s.reindexSource(dir, "OTHER"); err == nil {
+		t.Error("expected error")
+	}
+}
+
 func TestDownload_record(t *testing.T) {
 	t.Parallel()
 
 	check := func(t *testing.T, repo *sqliteDownloadRepository) {
 		stats := &downloadStats{
 			SDNs: 1, Alts: 12, Addresses: 42, SectoralSanctions: 39,
-			DeniedPersons: 13, BISEntities: 32,
+			DeniedPersons: 13, BISEntities: 32, CanadianSanctions: 7, AustralianSanctions: 5,
+			UnitedNationsSanctions: 3, CustomEntries: 2,
 		}
 		if err := repo.recordStats(stats); err != nil {
 			t.Fatal(err)
@@ -110,6 +188,18 @@ func TestDownload_record(t *testing.T) {
 		if dl.BISEntities != stats.BISEntities {
 			t.Errorf("dl.BISEntities=%d stats.BISEntities=%d", dl.BISEntities, stats.BISEntities)
 		}
+		if dl.CanadianSanctions != stats.CanadianSanctions {
+			t.Errorf("dl.CanadianSanctions=%d stats.CanadianSanctions=%d", dl.CanadianSanctions, stats.CanadianSanctions)
+		}
+		if dl.AustralianSanctions != stats.AustralianSanctions {
+			t.Errorf("dl.AustralianSanctions=%d stats.AustralianSanctions=%d", dl.AustralianSanctions, stats.AustralianSanctions)
+		}
+		if dl.UnitedNationsSanctions != stats.UnitedNationsSanctions {
+			t.Errorf("dl.UnitedNationsSanctions=%d stats.UnitedNationsSanctions=%d", dl.UnitedNationsSanctions, stats.UnitedNationsSanctions)
+		}
+		if dl.CustomEntries != stats.CustomEntries {
+			t.Errorf("dl.CustomEntries=%d stats.CustomEntries=%d", dl.CustomEntries, stats.CustomEntries)
+		}
 	}
 
 	// SQLite tests
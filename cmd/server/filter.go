@@ -12,6 +12,9 @@ import (
 type filterRequest struct {
 	sdnType     string
 	ofacProgram string
+
+	// sources limits which lists are searched, it's not applied in filterSDNs
+	sources sourceSet
 }
 
 func (req filterRequest) empty() bool {
@@ -19,9 +22,11 @@ func (req filterRequest) empty() bool {
 }
 
 func buildFilterRequest(u *url.URL) filterRequest {
+	sources, _ := readSources(u) // invalid values are rejected in search()
 	return filterRequest{
 		sdnType:     u.Query().Get("sdnType"),
 		ofacProgram: u.Query().Get("ofacProgram"),
+		sources:     sources,
 	}
 }
 
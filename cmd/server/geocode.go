@@ -0,0 +1,252 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+const geocoderGazetteer = "gazetteer"
+
+// addressGeocoder sets approximate coordinates on addresses as they're precomputed, nil leaves
+// them out. main sets it from GEOCODER.
+var addressGeocoder geocoder
+
+// geocoder returns the approximate coordinates of an address, ok is false when it's unknown
+type geocoder interface {
+	geocode(addr *ofac.Address) (lat, lon float64, ok bool)
+}
+
+// getGeocoder reads which geocoder to use, an empty value (or "none") disables geocoding
+//
+// env is the value from an environmental variable
+func getGeocoder(logger log.Logger, env string) geocoder {
+	switch v := strings.ToLower(strings.TrimSpace(env)); v {
+	case "", "none":
+		return nil
+	case geocoderGazetteer:
+		logger.Log("main", "Geocoding addresses with the embedded gazetteer")
+		return gazetteer{}
+	default:
+		logger.Log("main", fmt.Sprintf("unknown GEOCODER=%q, addresses won't be geocoded", env))
+		return nil
+	}
+}
+
+// coordinates are included on address results when a geocoder found them
+type coordinates struct {
+	Lat *float64 `json:"lat,omitempty"`
+	Lon *float64 `json:"lon,omitempty"`
+}
+
+func geocodeAddress(g geocoder, addr *ofac.Address) coordinates {
+	if g == nil || addr == nil {
+		return coordinates{}
+	}
+	lat, lon, ok := g.geocode(addr)
+	if !ok {
+		return coordinates{}
+	}
+	return coordinates{Lat: &lat, Lon: &lon}
+}
+
+// gazetteer geocodes addresses to the centroid of their city, when it's one of gazetteerCities,
+// or otherwise their country. It needs no network access but is only accurate to a city.
+type gazetteer struct{}
+
+func (gazetteer) geocode(addr *ofac.Address) (float64, float64, bool) {
+	country := precompute(addr.Country)
+	if country == "" {
+		return 0, 0, false
+	}
+	city, _, _ := splitCityStateProvincePostalCode(addr.CityStateProvincePostalCode)
+	if p, exists := gazetteerCities[precompute(city)+"|"+country]; exists {
+		return p[0], p[1], true
+	}
+	if p, exists := gazetteerCountries[country]; exists {
+		return p[0], p[1], true
+	}
+	return 0, 0, false
+}
+
+// gazetteerCities are the centroids of cities which often appear in sanctioned addresses,
+// keyed by the precomputed city and country.
+var gazetteerCities = map[string][2]float64{
+	"abu dhabi|united arab emirates": {24.4539, 54.3773},
+	"almaty|kazakhstan":              {43.2220, 76.8512},
+	"baghdad|iraq":                   {33.3152, 44.3661},
+	"beijing|china":                  {39.9042, 116.4074},
+	"beirut|lebanon":                 {33.8938, 35.5018},
+	"bogota|colombia":                {4.7110, -74.0721},
+	"cali|colombia":                  {3.4516, -76.5320},
+	"caracas|venezuela":              {10.4806, -66.9036},
+	"damascus|syria":                 {33.5138, 36.2765},
+	"dubai|united arab emirates":     {25.2048, 55.2708},
+	"guadalajara|mexico":             {20.6597, -103.3496},
+	"havana|cuba":                    {23.1136, -82.3666},
+	"hong kong|china":                {22.3193, 114.1694},
+	"istanbul|turkey":                {41.0082, 28.9784},
+	"kabul|afghanistan":              {34.5553, 69.2075},
+	"karachi|pakistan":               {24.8607, 67.0011},
+	"london|united kingdom":          {51.5074, -0.1278},
+	"medellin|colombia":              {6.2442, -75.5812},
+	"mexico city|mexico":             {19.4326, -99.1332},
+	"minsk|belarus":                  {53.9006, 27.5590},
+	"moscow|russia":                  {55.7558, 37.6173},
+	"panama city|panama":             {8.9824, -79.5199},
+	"pyongyang|korea north":          {39.0392, 125.7625},
+	"pyongyang|north korea":          {39.0392, 125.7625},
+	"sanaa|yemen":                    {15.3694, 44.1910},
+	"shanghai|china":                 {31.2304, 121.4737},
+	"st petersburg|russia":           {59.9311, 30.3609},
+	"tehran|iran":                    {35.6892, 51.3890},
+	"tripoli|libya":                  {32.8872, 13.1913},
+	"zurich|switzerland":             {47.3769, 8.5417},
+}
+
+// gazetteerCountries are the approximate centroids of countries, keyed by their precomputed
+// name as the lists spell it.
+var gazetteerCountries = map[string][2]float64{
+	"afghanistan":                      {33.9391, 67.7100},
+	"albania":                          {41.1533, 20.1683},
+	"algeria":                          {28.0339, 1.6596},
+	"angola":                           {-11.2027, 17.8739},
+	"argentina":                        {-38.4161, -63.6167},
+	"armenia":                          {40.0691, 45.0382},
+	"australia":                        {-25.2744, 133.7751},
+	"austria":                          {47.5162, 14.5501},
+	"azerbaijan":                       {40.1431, 47.5769},
+	"bahamas":                          {25.0343, -77.3963},
+	"bahrain":                          {25.9304, 50.6378},
+	"bangladesh":                       {23.6850, 90.3563},
+	"belarus":                          {53.7098, 27.9534},
+	"belgium":                          {50.5039, 4.4699},
+	"belize":                           {17.1899, -88.4976},
+	"bolivia":                          {-16.2902, -63.5887},
+	"bosnia and herzegovina":           {43.9159, 17.6791},
+	"brazil":                           {-14.2350, -51.9253},
+	"bulgaria":                         {42.7339, 25.4858},
+	"burma":                            {21.9162, 95.9560},
+	"burundi":                          {-3.3731, 29.9189},
+	"cambodia":                         {12.5657, 104.9910},
+	"canada":                           {56.1304, -106.3468},
+	"central african republic":         {6.6111, 20.9394},
+	"chile":                            {-35.6751, -71.5430},
+	"china":                            {35.8617, 104.1954},
+	"colombia":                         {4.5709, -74.2973},
+	"costa rica":                       {9.7489, -83.7534},
+	"croatia":                          {45.1000, 15.2000},
+	"cuba":                             {21.5218, -77.7812},
+	"cyprus":                           {35.1264, 33.4299},
+	"czech republic":                   {49.8175, 15.4730},
+	"democratic republic of the congo": {-4.0383, 21.7587},
+	"denmark":                          {56.2639, 9.5018},
+	"dominican republic":               {18.7357, -70.1627},
+	"ecuador":                          {-1.8312, -78.1834},
+	"egypt":                            {26.8206, 30.8025},
+	"el salvador":                      {13.7942, -88.8965},
+	"eritrea":                          {15.1794, 39.7823},
+	"ethiopia":                         {9.1450, 40.4897},
+	"france":                           {46.2276, 2.2137},
+	"georgia":                          {42.3154, 43.3569},
+	"germany":                          {51.1657, 10.4515},
+	"ghana":                            {7.9465, -1.0232},
+	"greece":                           {39.0742, 21.8243},
+	"guatemala":                        {15.7835, -90.2308},
+	"guinea":                           {9.9456, -9.6966},
+	"haiti":                            {18.9712, -72.2852},
+	"honduras":                         {15.2000, -86.2419},
+	"hong kong":                        {22.3193, 114.1694},
+	"hungary":                          {47.1625, 19.5033},
+	"india":                            {20.5937, 78.9629},
+	"indonesia":                        {-0.7893, 113.9213},
+	"iran":                             {32.4279, 53.6880},
+	"iraq":                             {33.2232, 43.6793},
+	"ireland":                          {53.4129, -8.2439},
+	"israel":                           {31.0461, 34.8516},
+	"italy":                            {41.8719, 12.5674},
+	"japan":                            {36.2048, 138.2529},
+	"jordan":                           {30.5852, 36.2384},
+	"kazakhstan":                       {48.0196, 66.9237},
+	"kenya":                            {-0.0236, 37.9062},
+	"korea north":                      {40.3399, 127.5101},
+	"north korea":                      {40.3399, 127.5101},
+	"kosovo":                           {42.6026, 20.9030},
+	"kuwait":                           {29.3117, 47.4818},
+	"kyrgyzstan":                       {41.2044, 74.7661},
+	"laos":                             {19.8563, 102.4955},
+	"latvia":                           {56.8796, 24.6032},
+	"lebanon":                          {33.8547, 35.8623},
+	"liberia":                          {6.4281, -9.4295},
+	"libya":                            {26.3351, 17.2283},
+	"liechtenstein":                    {47.1660, 9.5554},
+	"lithuania":                        {55.1694, 23.8813},
+	"luxembourg":                       {49.8153, 6.1296},
+	"malaysia":                         {4.2105, 101.9758},
+	"mali":                             {17.5707, -3.9962},
+	"malta":                            {35.9375, 14.3754},
+	"mexico":                           {23.6345, -102.5528},
+	"moldova":                          {47.4116, 28.3699},
+	"montenegro":                       {42.7087, 19.3744},
+	"morocco":                          {31.7917, -7.0926},
+	"netherlands":                      {52.1326, 5.2913},
+	"nicaragua":                        {12.8654, -85.2072},
+	"niger":                            {17.6078, 8.0817},
+	"nigeria":                          {9.0820, 8.6753},
+	"north macedonia":                  {41.6086, 21.7453},
+	"norway":                           {60.4720, 8.4689},
+	"oman":                             {21.4735, 55.9754},
+	"pakistan":                         {30.3753, 69.3451},
+	"panama":                           {8.5380, -80.7821},
+	"paraguay":                         {-23.4425, -58.4438},
+	"peru":                             {-9.1900, -75.0152},
+	"philippines":                      {12.8797, 121.7740},
+	"poland":                           {51.9194, 19.1451},
+	"portugal":                         {39.3999, -8.2245},
+	"qatar":                            {25.3548, 51.1839},
+	"romania":                          {45.9432, 24.9668},
+	"russia":                           {61.5240, 105.3188},
+	"rwanda":                           {-1.9403, 29.8739},
+	"saudi arabia":                     {23.8859, 45.0792},
+	"serbia":                           {44.0165, 21.0059},
+	"sierra leone":                     {8.4606, -11.7799},
+	"singapore":                        {1.3521, 103.8198},
+	"slovakia":                         {48.6690, 19.6990},
+	"slovenia":                         {46.1512, 14.9955},
+	"somalia":                          {5.1521, 46.1996},
+	"south africa":                     {-30.5595, 22.9375},
+	"south korea":                      {35.9078, 127.7669},
+	"south sudan":                      {6.8770, 31.3070},
+	"spain":                            {40.4637, -3.7492},
+	"sri lanka":                        {7.8731, 80.7718},
+	"sudan":                            {12.8628, 30.2176},
+	"sweden":                           {60.1282, 18.6435},
+	"switzerland":                      {46.8182, 8.2275},
+	"syria":                            {34.8021, 38.9968},
+	"taiwan":                           {23.6978, 120.9605},
+	"tajikistan":                       {38.8610, 71.2761},
+	"tanzania":                         {-6.3690, 34.8888},
+	"thailand":                         {15.8700, 100.9925},
+	"tunisia":                          {33.8869, 9.5375},
+	"turkey":                           {38.9637, 35.2433},
+	"turkmenistan":                     {38.9697, 59.5563},
+	"uganda":                           {1.3733, 32.2903},
+	"ukraine":                          {48.3794, 31.1656},
+	"united arab emirates":             {23.4241, 53.8478},
+	"united kingdom":                   {55.3781, -3.4360},
+	"united states":                    {37.0902, -95.7129},
+	"uruguay":                          {-32.5228, -55.7658},
+	"uzbekistan":                       {41.3775, 64.5853},
+	"venezuela":                        {6.4238, -66.5897},
+	"vietnam":                          {14.0583, 108.2772},
+	"west bank":                        {31.9466, 35.3027},
+	"yemen":                            {15.5527, 48.5164},
+	"zimbabwe":                         {-19.0154, 29.1549},
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+// stubGeocoder returns fixed coordinates for addresses in a country
+type stubGeocoder map[string][2]float64
+
+func (g stubGeocoder) geocode(addr *ofac.Address) (float64, float64, bool) {
+	p, exists := g[addr.Country]
+	return p[0], p[1], exists
+}
+
+func TestGeocoder__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	for _, env := range []string{"", "none", "other"} {
+		if g := getGeocoder(logger, env); g != nil {
+			t.Errorf("%q: got %#v", env, g)
+		}
+	}
+	if _, ok := getGeocoder(logger, "Gazetteer").(gazetteer); !ok {
+		t.Error("expected gazetteer")
+	}
+}
+
+func TestGeocoder__precomputeAddresses(t *testing.T) {
+	defer func(g geocoder) { addressGeocoder = g }(addressGeocoder)
+
+	adds := []*ofac.Address{
+		{EntityID: "1", AddressID: "1", CityStateProvincePostalCode: "Havana", Country: "Cuba"},
+		{EntityID: "2", AddressID: "2", CityStateProvincePostalCode: "Zurich CH-8022", Country: "Switzerland"},
+	}
+	encode := func(add *Address) map[string]interface{} {
+		t.Helper()
+		bs, err := json.Marshal(add)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out map[string]interface{}
+		if err := json.Unmarshal(bs, &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// disabled by default
+	addressGeocoder = nil
+	for _, add := range precomputeAddresses(adds) {
+		if out := encode(add); out["lat"] != nil || out["lon"] != nil {
+			t.Errorf("unexpected coordinates: %v", out)
+		}
+	}
+
+	addressGeocoder = stubGeocoder{"Cuba": {23.1, -82.3}}
+	precomputed := precomputeAddresses(adds)
+	if out := encode(precomputed[0]); out["lat"] != 23.1 || out["lon"] != -82.3 {
+		t.Errorf("unexpected coordinates: %v", out)
+	}
+	// addresses the geocoder doesn't know are left out, zero isn't a valid default
+	if out := encode(precomputed[1]); out["lat"] != nil || out["lon"] != nil {
+		t.Errorf("unexpected coordinates: %v", out)
+	}
+}
+
+func TestGeocoder__gazetteer(t *testing.T) {
+	g := gazetteer{}
+	cases := []struct {
+		add      ofac.Address
+		lat, lon float64
+		ok       bool
+	}{
+		{ofac.Address{CityStateProvincePostalCode: "Havana", Country: "Cuba"}, 23.1136, -82.3666, true},
+		{ofac.Address{CityStateProvincePostalCode: "Pyongyang", Country: "Korea, North"}, 39.0392, 125.7625, true},
+		{ofac.Address{CityStateProvincePostalCode: "Zurich CH-8022", Country: "Switzerland"}, 47.3769, 8.5417, true},
+		// unknown cities fall back to their country
+		{ofac.Address{CityStateProvincePostalCode: "Harare", Country: "Zimbabwe"}, -19.0154, 29.1549, true},
+		{ofac.Address{Country: "CUBA"}, 21.5218, -77.7812, true},
+		{ofac.Address{CityStateProvincePostalCode: "Havana"}, 0, 0, false},
+		{ofac.Address{Country: "Atlantis"}, 0, 0, false},
+	}
+	for i := range cases {
+		lat, lon, ok := g.geocode(&cases[i].add)
+		if lat != cases[i].lat || lon != cases[i].lon || ok != cases[i].ok {
+			t.Errorf("#%d: got %v, %v (%v)", i, lat, lon, ok)
+		}
+	}
+
+	// keys must be precomputed to ever match
+	for key := range gazetteerCities {
+		parts := strings.Split(key, "|")
+		if len(parts) != 2 || precompute(parts[0]) != parts[0] || precompute(parts[1]) != parts[1] {
+			t.Errorf("city %q isn't precomputed", key)
+		}
+	}
+	for key, p := range gazetteerCountries {
+		if precompute(key) != key {
+			t.Errorf("country %q isn't precomputed", key)
+		}
+		if p[0] < -90 || p[0] > 90 || p[1] < -180 || p[1] > 180 {
+			t.Errorf("country %q has invalid coordinates %v", key, p)
+		}
+	}
+}
@@ -5,11 +5,13 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	moovhttp "github.com/moov-io/base/http"
 
@@ -26,6 +28,7 @@ var (
 )
 
 func wrapResponseWriter(logger log.Logger, w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+	setPrivacyHeaders(w)
 	route := fmt.Sprintf("%s-%s", strings.ToLower(r.Method), cleanMetricsPath(r.URL.Path))
 	return moovhttp.Wrap(logger, routeHistogram.With("route", route), w, r)
 }
@@ -50,3 +53,81 @@ func cleanMetricsPath(path string) string {
 	}
 	return strings.Join(out, "-")
 }
+
+var (
+	defaultReadTimeout       = 30 * time.Second
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultIdleTimeout       = 60 * time.Second
+
+	// defaultWriteTimeout is longer than defaultSearchTimeout so a search which times out
+	// still has time to write its 504
+	defaultWriteTimeout = defaultSearchTimeout + 30*time.Second
+
+	// streamWriteTimeout is how long each write of a streamed response has, main sets it to
+	// HTTP_WRITE_TIMEOUT. Zero doesn't limit writes.
+	streamWriteTimeout = defaultWriteTimeout
+)
+
+// serverTimeouts holds each timeout applied to the business HTTP server
+type serverTimeouts struct {
+	Read       time.Duration
+	ReadHeader time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// readServerTimeouts returns the HTTP server timeouts from environmental variables
+// and falls back to the defaults otherwise.
+func readServerTimeouts(logger log.Logger, getenv func(string) string) serverTimeouts {
+	return serverTimeouts{
+		Read:       getHTTPTimeout(logger, "HTTP_READ_TIMEOUT", getenv("HTTP_READ_TIMEOUT"), defaultReadTimeout),
+		ReadHeader: getHTTPTimeout(logger, "HTTP_READ_HEADER_TIMEOUT", getenv("HTTP_READ_HEADER_TIMEOUT"), defaultReadHeaderTimeout),
+		Write:      getHTTPTimeout(logger, "HTTP_WRITE_TIMEOUT", getenv("HTTP_WRITE_TIMEOUT"), defaultWriteTimeout),
+		Idle:       getHTTPTimeout(logger, "HTTP_IDLE_TIMEOUT", getenv("HTTP_IDLE_TIMEOUT"), defaultIdleTimeout),
+	}
+}
+
+// getHTTPTimeout parses env as a time.Duration. A value of 'off' disables the timeout,
+// which is useful for long-lived streaming responses, but opens the server up to slow clients.
+func getHTTPTimeout(logger log.Logger, name, env string, def time.Duration) time.Duration {
+	if env == "" {
+		return def
+	}
+	if strings.EqualFold(env, "off") {
+		logger.Log("main", fmt.Sprintf("WARN: disabling %s", name))
+		return 0 * time.Second
+	}
+	if dur, err := time.ParseDuration(env); err == nil && dur > 0 {
+		logger.Log("main", fmt.Sprintf("Setting %s to %v", name, dur))
+		return dur
+	}
+	logger.Log("main", fmt.Sprintf("invalid %s=%q, using default of %v", name, env, def))
+	return def
+}
+
+// extendWriteDeadline gives a streamed response another streamWriteTimeout for its next write,
+// the server's WriteTimeout would otherwise cut off streams which run longer than it.
+func extendWriteDeadline(controller *http.ResponseController) {
+	var deadline time.Time
+	if streamWriteTimeout > 0 {
+		deadline = time.Now().Add(streamWriteTimeout)
+	}
+	controller.SetWriteDeadline(deadline)
+}
+
+// newHTTPServer returns an *http.Server for our business routes with the given timeouts applied.
+func newHTTPServer(addr string, handler http.Handler, timeouts serverTimeouts) *http.Server {
+	return &http.Server{
+		Addr:    addr,
+		Handler: handler,
+		TLSConfig: &tls.Config{
+			InsecureSkipVerify:       false,
+			PreferServerCipherSuites: true,
+			MinVersion:               tls.VersionTLS12,
+		},
+		ReadTimeout:       timeouts.Read,
+		ReadHeaderTimeout: timeouts.ReadHeader,
+		WriteTimeout:      timeouts.Write,
+		IdleTimeout:       timeouts.Idle,
+	}
+}
@@ -5,7 +5,14 @@
 package main
 
 import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
 )
 
 func TestHTTP__cleanMetricsPath(t *testing.T) {
@@ -23,3 +30,72 @@ func TestHTTP__cleanMetricsPath(t *testing.T) {
 		t.Errorf("got %q", v)
 	}
 }
+
+func TestHTTP__serverTimeouts(t *testing.T) {
+	logger := log.NewNopLogger()
+
+	// defaults
+	timeouts := readServerTimeouts(logger, func(string) string { return "" })
+	serve := newHTTPServer(":0", http.NotFoundHandler(), timeouts)
+	if serve.ReadTimeout != defaultReadTimeout || serve.ReadHeaderTimeout != defaultReadHeaderTimeout {
+		t.Errorf("read=%v readHeader=%v", serve.ReadTimeout, serve.ReadHeaderTimeout)
+	}
+	if serve.WriteTimeout != defaultWriteTimeout || serve.IdleTimeout != defaultIdleTimeout {
+		t.Errorf("write=%v idle=%v", serve.WriteTimeout, serve.IdleTimeout)
+	}
+
+	// configured values
+	env := map[string]string{
+		"HTTP_READ_TIMEOUT":        "5s",
+		"HTTP_READ_HEADER_TIMEOUT": "2s",
+		"HTTP_WRITE_TIMEOUT":       "off",
+		"HTTP_IDLE_TIMEOUT":        "bogus",
+	}
+	timeouts = readServerTimeouts(logger, func(key string) string { return env[key] })
+	serve = newHTTPServer(":0", http.NotFoundHandler(), timeouts)
+	if serve.ReadTimeout != 5*time.Second {
+		t.Errorf("ReadTimeout=%v", serve.ReadTimeout)
+	}
+	if serve.ReadHeaderTimeout != 2*time.Second {
+		t.Errorf("ReadHeaderTimeout=%v", serve.ReadHeaderTimeout)
+	}
+	if serve.WriteTimeout != 0 {
+		t.Errorf("WriteTimeout=%v", serve.WriteTimeout)
+	}
+	if serve.IdleTimeout != defaultIdleTimeout {
+		t.Errorf("IdleTimeout=%v", serve.IdleTimeout)
+	}
+}
+
+func TestHTTP__extendWriteDeadline(t *testing.T) {
+	defer func(d time.Duration) { streamWriteTimeout = d }(streamWriteTimeout)
+	streamWriteTimeout = 200 * time.Millisecond
+
+	// the stream runs longer than the server's WriteTimeout, but each write is within it
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		controller := http.NewResponseController(w)
+		for i := 0; i < 4; i++ {
+			extendWriteDeadline(controller)
+			fmt.Fprintf(w, "line %d\n", i)
+			controller.Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var lines int
+	for scanner := bufio.NewScanner(resp.Body); scanner.Scan(); {
+		lines++
+	}
+	if lines != 4 {
+		t.Errorf("got %d lines", lines)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+// indexRawNames keeps the raw names and addresses of OFAC records in the index alongside their
+// normalized forms. main sets it from INDEX_RAW_NAMES, when false only the normalized forms
+// are kept (see compactOFACRecords) to lower memory usage.
+var indexRawNames = true
+
+// compactOFACRecords replaces the raw names and address fields of each SDN, address and alt name
+// with their normalized forms, so a string of each is kept instead of two. Responses then include
+// the normalized form. The raw records are still in the downloaded files.
+//
+// Every record is copied instead of modified, so the parsed records can be garbage collected.
+func compactOFACRecords(sdns []*SDN, adds []*Address, alts []*Alt) {
+	compacted := make(map[*ofac.Address]*ofac.Address, len(adds))
+	for i := range adds {
+		if adds[i] == nil || adds[i].Address == nil {
+			continue
+		}
+		addr := *adds[i].Address
+		addr.Address = adds[i].address
+		addr.CityStateProvincePostalCode = adds[i].citystate
+		addr.Country = adds[i].country
+		compacted[adds[i].Address] = &addr
+		adds[i].Address = &addr
+	}
+	for i := range sdns {
+		if sdns[i] == nil || sdns[i].SDN == nil {
+			continue
+		}
+		sdn := *sdns[i].SDN
+		sdn.SDNName = sdns[i].name
+		sdns[i].SDN = &sdn
+
+		addresses := make([]*ofac.Address, len(sdns[i].addresses))
+		for j, addr := range sdns[i].addresses {
+			if c, exists := compacted[addr]; exists {
+				addr = c
+			}
+			addresses[j] = addr
+		}
+		sdns[i].addresses = addresses
+	}
+	for i := range alts {
+		if alts[i] == nil || alts[i].AlternateIdentity == nil {
+			continue
+		}
+		alt := *alts[i].AlternateIdentity
+		alt.AlternateName = alts[i].name
+		alts[i].AlternateIdentity = &alt
+	}
+}
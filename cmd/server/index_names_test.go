@@ -0,0 +1,79 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestIndexNames__compact(t *testing.T) {
+	defer func(v bool) { indexRawNames = v }(indexRawNames)
+
+	dir := copyTestData(t)
+	defer os.RemoveAll(dir)
+
+	refresh := func(t *testing.T, raw bool) *searcher {
+		t.Helper()
+		indexRawNames = raw
+		s := &searcher{
+			logger: log.NewNopLogger(),
+			pipe:   noLogPipeliner,
+		}
+		if _, err := s.refreshData(dir); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	// raw names are kept by default
+	s := refresh(t, true)
+	sdns := s.TopSDNs(1, "anglo caribbean", searchOptions{})
+	if len(sdns) != 1 || sdns[0].SDNName != "ANGLO-CARIBBEAN CO., LTD." {
+		t.Fatalf("%#v", sdns)
+	}
+	rawMatch := sdns[0].match
+
+	// only normalized names are kept, searches score the same
+	s = refresh(t, false)
+	sdns = s.TopSDNs(1, "anglo caribbean", searchOptions{})
+	if len(sdns) != 1 || sdns[0].EntityID != "173" || sdns[0].SDNName != sdns[0].name {
+		t.Fatalf("%#v", sdns)
+	}
+	eql(t, "match", sdns[0].match, rawMatch)
+
+	addrs := s.TopAddresses(1, "ibex house the minories", searchOptions{})
+	if len(addrs) != 1 || addrs[0].Address.Address != "ibex house the minories" || addrs[0].Address.Country != "united kingdom" {
+		t.Errorf("%#v", addrs)
+	}
+	alts := s.TopAltNames(1, "avia import", searchOptions{})
+	if len(alts) != 1 || alts[0].AlternateIdentity.AlternateName != "avia import" {
+		t.Errorf("%#v", alts)
+	}
+
+	// an SDN's addresses are the compacted ones
+	if sdn := s.debugSDN("173"); sdn == nil || len(sdn.addresses) != 1 || sdn.addresses[0] != addrs[0].Address {
+		t.Errorf("SDN addresses weren't compacted: %#v", sdn)
+	}
+
+	// responses include the normalized forms, which leave out what normalization removed
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=anglo+caribbean&limit=1", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"sdnName":"anglo caribbean"`) {
+		t.Errorf("%s", body)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xrash/smetrics"
+)
+
+// initialTokenScore is the score of an initial (e.g. "j") against a token it abbreviates (e.g.
+// "john"). It's below an exact token so the full name still ranks first.
+const initialTokenScore = 0.9
+
+// initialsMatch scores an indexed name against a query with matchMode=initials, where either
+// may abbreviate tokens to their initial. This is common in wire transfers, so "j smith" and
+// "john s" both match "john smith". Other tokens are scored like jaroWinkler.
+func initialsMatch(indexed, query string) float64 {
+	return averageTokenScores(indexed, query, initialTokenMatch)
+}
+
+// initialTokenMatch scores two tokens, a single letter token only matches tokens starting with it
+func initialTokenMatch(a, b string) float64 {
+	if utf8.RuneCountInString(a) == 1 || utf8.RuneCountInString(b) == 1 {
+		switch {
+		case a == b:
+			return 1.0
+		case strings.HasPrefix(a, b) || strings.HasPrefix(b, a):
+			return initialTokenScore
+		}
+		return 0.0
+	}
+	return smetrics.JaroWinkler(a, b, 0.7, 4)
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestInitials__match(t *testing.T) {
+	eql(t, "initial and surname", initialsMatch("john smith", "j smith"), 0.95)
+	eql(t, "given name and initial", initialsMatch("john smith", "john s"), 0.95)
+	eql(t, "abbreviated index", initialsMatch("j smith", "john smith"), 0.95)
+	eql(t, "full name", initialsMatch("john smith", "john smith"), 1.0)
+	eql(t, "equal initials", initialsMatch("j smith", "j smith"), 1.0)
+	eql(t, "wrong initial", initialsMatch("john smith", "k smith"), 0.5)
+	eql(t, "empty", initialsMatch("", "j smith"), 0.0)
+}
+
+func TestInitials__search(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "SMITH, John", SDNType: "individual"},
+			{EntityID: "2", SDNName: "SMYTHE, Karen", SDNType: "individual"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	for _, query := range []string{"J SMITH", "JOHN S", "S JOHN"} {
+		fuzzy := s.TopSDNs(1, precompute(query), searchOptions{})
+		initials := s.TopSDNs(1, precompute(query), searchOptions{matchMode: matchModeInitials})
+		if len(initials) != 1 || initials[0].EntityID != "1" {
+			t.Fatalf("%s: %#v", query, initials)
+		}
+		if initials[0].match < 0.95 || initials[0].match <= fuzzy[0].match {
+			t.Errorf("%s: initials=%.3f fuzzy=%.3f", query, initials[0].match, fuzzy[0].match)
+		}
+	}
+
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=j+smith&matchMode=initials&limit=2", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		SDNs []struct {
+			EntityID string  `json:"entityID"`
+			Match    float64 `json:"match"`
+		} `json:"SDNs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.SDNs) != 2 || resp.SDNs[0].EntityID != "1" || resp.SDNs[1].Match >= resp.SDNs[0].Match {
+		t.Errorf("unexpected results: %#v", resp.SDNs)
+	}
+
+	// initials are opt-in and can't be strict
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=j+smith&matchMode=initials&strict=true", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
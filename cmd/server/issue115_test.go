@@ -30,12 +30,12 @@ func TestIssue115__TopSDNs(t *testing.T) {
 
 	// was 89.6% match
 	s.SDNs = precomputeSDNs([]*ofac.SDN{{EntityID: "2680", SDNName: "HABBASH, George", SDNType: "INDIVIDUAL"}}, nil, pipe)
-	out := s.TopSDNs(1, "george bush")
+	out := s.TopSDNs(1, "george bush", searchOptions{})
 	eql(t, "issue115: top SDN 2680", out[0].match, 0.732)
 
 	// was 88.3% match
 	s.SDNs = precomputeSDNs([]*ofac.SDN{{EntityID: "9432", SDNName: "CHIWESHE, George", SDNType: "INDIVIDUAL"}}, nil, pipe)
-	out = s.TopSDNs(1, "george bush")
+	out = s.TopSDNs(1, "george bush", searchOptions{})
 	eql(t, "issue115: top SDN 18996", out[0].match, 0.764)
 
 	// another example
@@ -44,9 +44,9 @@ func TestIssue115__TopSDNs(t *testing.T) {
 		t.Errorf("s.SDNs[0].name=%s", s.SDNs[0].name)
 	}
 
-	out = s.TopSDNs(1, "george bush")
+	out = s.TopSDNs(1, "george bush", searchOptions{})
 	eql(t, "issue115: top SDN 0", out[0].match, 1.0)
 
-	out = s.TopSDNs(1, "george w bush")
+	out = s.TopSDNs(1, "george w bush", searchOptions{})
 	eql(t, "issue115: top SDN 0", out[0].match, 1.0)
 }
@@ -4,10 +4,25 @@
 
 package main
 
+import (
+	"sync/atomic"
+)
+
 // item represents an arbitrary value with an associated weight
 type item struct {
 	value  interface{}
 	weight float64
+
+	alias     string // set when an alias scored higher than the primary name
+	aliasType string // type of the alias, like aka or fka
+
+	// boost ranks the item higher than its weight without changing it (see inProgramBoost)
+	boost float64
+}
+
+// rank is what items are ordered by
+func (it *item) rank() float64 {
+	return it.weight + it.boost
 }
 
 // newLargest returns a `largest` instance which can be used to track items with the highest weights
@@ -23,15 +38,32 @@ func newLargest(capacity int) *largest {
 type largest struct {
 	items    []*item
 	capacity int
+
+	// minWeight drops items weighing less, counted is incremented for every other item
+	// weighing more than zero (it's optional and shared across lists)
+	minWeight float64
+	counted   *int64
+
+	// skip drops items which would be kept when it returns true (see selfMatches)
+	skip func(*item) bool
 }
 
 func (xs *largest) add(it *item) {
+	if it.weight < xs.minWeight {
+		return
+	}
+	if xs.counted != nil && it.weight > 0 {
+		atomic.AddInt64(xs.counted, 1)
+	}
+	if xs.skip != nil && xs.fits(it) && xs.skip(it) {
+		return
+	}
 	for i := range xs.items {
 		if xs.items[i] == nil {
 			xs.items[i] = it // insert if we found empty slot
 			break
 		}
-		if xs.items[i].weight < it.weight {
+		if xs.items[i].rank() < it.rank() {
 			// insert at i, slide other items over
 			xs.items = append(xs.items, nil)
 			copy(xs.items[i+1:], xs.items[i:])
@@ -43,3 +75,13 @@ func (xs *largest) add(it *item) {
 		xs.items = xs.items[:xs.capacity]
 	}
 }
+
+// fits returns true when it would be kept
+func (xs *largest) fits(it *item) bool {
+	for i := range xs.items {
+		if xs.items[i] == nil || xs.items[i].rank() < it.rank() {
+			return true
+		}
+	}
+	return false
+}
@@ -6,7 +6,6 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
 	"flag"
 	"fmt"
 	"net/http"
@@ -23,9 +22,11 @@ import (
 	"github.com/moov-io/base/http/bind"
 	"github.com/moov-io/watchman"
 	"github.com/moov-io/watchman/internal/database"
+	"github.com/moov-io/watchman/pkg/download"
 
 	"github.com/go-kit/kit/log"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -83,29 +84,29 @@ func main() {
 	}
 	router := mux.NewRouter().PathPrefix(*flagBasePath).Subrouter()
 	moovhttp.AddCORSHandler(router)
+	authRequirements = getAuthRequirements(logger, os.Getenv("AUTH_REQUIREMENTS"))
+	authTokens = getAuthTokens(logger, os.Getenv("AUTH_TOKENS"))
+	router.Use(authMiddleware(logger))
 	addPingRoute(router)
-
-	// Start business HTTP server
-	readTimeout, _ := time.ParseDuration("30s")
-	writTimeout, _ := time.ParseDuration("30s")
-	idleTimeout, _ := time.ParseDuration("60s")
+	addOpenAPIRoute(logger, router)
 
 	// Check to see if our -http.addr flag has been overridden
 	if v := os.Getenv("HTTP_BIND_ADDRESS"); v != "" {
 		*httpAddr = v
 	}
 
-	serve := &http.Server{
-		Addr:    *httpAddr,
-		Handler: router,
-		TLSConfig: &tls.Config{
-			InsecureSkipVerify:       false,
-			PreferServerCipherSuites: true,
-			MinVersion:               tls.VersionTLS12,
-		},
-		ReadTimeout:  readTimeout,
-		WriteTimeout: writTimeout,
-		IdleTimeout:  idleTimeout,
+	// Start business HTTP server
+	timeouts := readServerTimeouts(logger, os.Getenv)
+	streamWriteTimeout = timeouts.Write
+	serve := newHTTPServer(*httpAddr, router, timeouts)
+	if path := os.Getenv("HTTPS_CLIENT_CA_FILE"); path != "" {
+		if err := setClientCAs(serve, path); err != nil {
+			logger.Log("main", fmt.Sprintf("ERROR: reading HTTPS_CLIENT_CA_FILE: %v", err))
+			os.Exit(1)
+		}
+		if os.Getenv("HTTPS_CERT_FILE") == "" {
+			logger.Log("main", "WARN: HTTPS_CLIENT_CA_FILE requires HTTPS_CERT_FILE, client certificates aren't verified")
+		}
 	}
 	shutdownServer := func() {
 		if err := serve.Shutdown(context.TODO()); err != nil {
@@ -144,11 +145,63 @@ func main() {
 		searcher.pipe = newPipeliner(log.NewNopLogger())
 	}
 
-	// Add manual data refresh endpoint
-	adminServer.AddHandler(manualRefreshPath, manualRefreshHandler(logger, searcher, downloadRepo))
+	privacyMode = getPrivacyMode(logger, os.Getenv("PRIVACY_MODE"))
+	addressGeocoder = getGeocoder(logger, os.Getenv("GEOCODER"))
+	programRisks = getProgramRiskTiers(logger, os.Getenv("PROGRAM_RISK_TIERS"), os.Getenv("PROGRAM_RISK_DEFAULT_TIER"))
+	sourceFailurePolicy = getSourceFailurePolicy(logger, os.Getenv("SOURCE_FAILURE_POLICY"))
+	sourceScoreMultipliers = getSourceScoreMultipliers(logger, os.Getenv("SOURCE_SCORE_MULTIPLIERS"))
+	scoreCalibrations = getScoreCalibrations(logger, os.Getenv("SCORE_CALIBRATION"))
+	namePunctuation = getNamePunctuation(logger, os.Getenv("NAME_PUNCTUATION"))
+	numericNameTokens = getNumericNameTokens(logger, os.Getenv("NUMERIC_NAME_TOKENS"))
+	keepNameDigits(numericNameTokens)
+	reindexDebounce = getReindexDebounce(logger, os.Getenv("REINDEX_DEBOUNCE"))
+	if customListFile = os.Getenv("CUSTOM_LIST_FILE"); customListFile != "" {
+		logger.Log("main", fmt.Sprintf("Searching custom list %s", customListFile))
+	}
+	if dedupe, err := strconv.ParseBool(os.Getenv("DEDUPE_SDN_ADDRESSES")); err == nil && !dedupe {
+		dedupeSDNAddresses = false
+	}
+	if include, err := strconv.ParseBool(os.Getenv("SEARCH_NAMELESS_RECORDS")); err == nil && include {
+		searchNamelessRecords = true
+	}
+	if ofacDeltaURL = os.Getenv("OFAC_DELTA_URL"); ofacDeltaURL != "" {
+		logger.Log("main", fmt.Sprintf("Applying OFAC deltas from %s", ofacDeltaURL))
+	}
+	if tombstones, err := strconv.ParseBool(os.Getenv("OFAC_DELTA_TOMBSTONES")); err == nil && tombstones {
+		logger.Log("main", "Reporting removed SDNs of OFAC deltas with their last known name")
+		ofacDeltaTombstones = true
+	}
+	if index, err := strconv.ParseBool(os.Getenv("SDN_ID_INDEX")); err == nil && !index {
+		logger.Log("main", "Searching SDN IDs without an index")
+		indexSDNIDs = false
+	}
+	if index, err := strconv.ParseBool(os.Getenv("INDEX_ALT_NAMES")); err == nil && index {
+		logger.Log("main", "Indexing alt names as SDN records")
+		indexAltNames = true
+	}
+	if normalize, err := strconv.ParseBool(os.Getenv("ADDRESS_NORMALIZATION")); err == nil && normalize {
+		logger.Log("main", "Normalizing punctuation and separators of addresses")
+		normalizeAddresses = true
+	}
+	nameParentheticals = getNameParentheticals(logger, os.Getenv("NAME_PARENTHETICALS"))
+	if keep, err := strconv.ParseBool(os.Getenv("INDEX_RAW_NAMES")); err == nil && !keep {
+		logger.Log("main", "Only indexing normalized OFAC names and addresses")
+		indexRawNames = false
+	}
+	download.MaxAttempts = getDownloadMaxAttempts(logger, os.Getenv("DOWNLOAD_MAX_ATTEMPTS"))
+	download.RetryBackoff = getDownloadRetryBackoff(logger, os.Getenv("DOWNLOAD_RETRY_BACKOFF"))
+
+	// Add manual data refresh endpoint, refreshes and reindexes send stats for re-search like periodic refreshes
+	updates := make(chan *downloadStats)
+	adminServer.AddHandler(manualRefreshPath, requireAuth(logger, authGroupAdmin, manualRefreshHandler(logger, searcher, downloadRepo, updates)))
+	adminServer.AddHandler(sourceReindexPath, requireAuth(logger, authGroupAdmin, sourceReindexHandler(logger, searcher, downloadRepo, updates)))
+	adminServer.AddHandler(parseReportPath, requireAuth(logger, authGroupAdmin, parseReportHandler(logger, searcher)))
+	adminServer.AddHandler(ofacDeltaReportPath, requireAuth(logger, authGroupAdmin, ofacDeltaReportHandler(logger, searcher)))
+	adminServer.AddHandler(ofacExportPath, requireAuth(logger, authGroupAdmin, ofacExportHandler(logger, searcher)))
+	adminServer.AddHandler(searchPreviewPath, requireAuth(logger, authGroupAdmin, searchPreviewHandler(logger, searcher)))
 
 	// Add debug routes
-	adminServer.AddHandler(debugSDNPath, debugSDNHandler(logger, searcher))
+	adminServer.AddHandler(debugSDNPath, requireAuth(logger, authGroupAdmin, debugSDNHandler(logger, searcher)))
 
 	// Initial download of data
 	if stats, err := searcher.refreshData(os.Getenv("INITIAL_DATA_DIRECTORY")); err != nil {
@@ -162,7 +215,7 @@ func main() {
 		logger.Log(
 			"main", fmt.Sprintf("data refreshed %v ago", time.Since(stats.RefreshedAt)),
 			"SDNs", stats.SDNs, "AltNames", stats.Alts, "Addresses", stats.Addresses, "SSI", stats.SectoralSanctions,
-			"DPL", stats.DeniedPersons, "BISEntities", stats.BISEntities,
+			"DPL", stats.DeniedPersons, "BISEntities", stats.BISEntities, "ISN", stats.NonproliferationSanctions, "CA", stats.CanadianSanctions, "AU", stats.AustralianSanctions, "UN", stats.UnitedNationsSanctions, "CUSTOM", stats.CustomEntries,
 		)
 	}
 
@@ -171,6 +224,8 @@ func main() {
 	defer watchRepo.close()
 	webhookRepo := &sqliteWebhookRepository{db}
 	defer webhookRepo.close()
+	webhookWorkers = newWebhookPool(getWebhookMaxConcurrency(logger, os.Getenv("WEBHOOK_MAX_CONCURRENCY")))
+	webhookNotifications = getWebhookNotifications(logger, os.Getenv("WEBHOOK_NOTIFICATIONS"))
 
 	// Setup company / customer repositories
 	companyRepo := &sqliteCompanyRepository{db, logger}
@@ -179,16 +234,80 @@ func main() {
 	defer custRepo.close()
 
 	// Setup periodic download and re-search
-	updates := make(chan *downloadStats)
 	dataRefreshInterval = getDataRefreshInterval(logger, os.Getenv("DATA_REFRESH_INTERVAL"))
 	go searcher.periodicDataRefresh(dataRefreshInterval, downloadRepo, updates)
+	if customListFile != "" {
+		customListReloadInterval = getCustomListReloadInterval(logger, os.Getenv("CUSTOM_LIST_RELOAD_INTERVAL"))
+		go newCustomListWatcher(searcher, downloadRepo, customListFile).watch(customListReloadInterval)
+	}
+
+	// Report stale data once a refresh is overdue by more than the grace period
+	staleness := newStalenessChecker(searcher, dataRefreshInterval, getStalenessGracePeriod(logger, os.Getenv("DATA_STALENESS_GRACE_PERIOD")))
+	readiness := &readinessChecks{}
+	readiness.add(adminServer, "data-staleness", staleness.check)
+	adminServer.AddHandler(readyBriefPath, readyBriefHandler(searcher, readiness))
+	prometheus.MustRegister(staleness.gauge())
+	addressScoreWeights = getAddressWeights(logger, os.Getenv("ADDRESS_SCORE_WEIGHTS"))
+	searchScorers = getFieldScorers(logger, os.Getenv("FIELD_SCORERS"))
+	searchBandThresholds = getBandThresholds(logger, os.Getenv("SEARCH_BAND_THRESHOLDS"))
+	searchDecisionThresholds = getDecisionThresholds(logger, os.Getenv("SEARCH_DECISION_THRESHOLDS"))
+	minMatchingNameTokens = getMinMatchingNameTokens(logger, os.Getenv("MIN_MATCHING_NAME_TOKENS"))
+	minAddressTokens = getMinAddressTokens(logger, os.Getenv("MIN_ADDRESS_TOKENS"))
+	searchScoreFloor = getScoreFloor(logger, os.Getenv("SEARCH_SCORE_FLOOR"))
+	programBoost = getProgramBoost(logger, os.Getenv("PROGRAM_BOOST"))
+	defaultNameScoring = getNameScoring(logger, os.Getenv("NAME_SCORING"))
+	fuzzyNameMatching = getFuzzyMatching(logger, "FUZZY_NAME_MATCHING", os.Getenv("FUZZY_NAME_MATCHING"))
+	fuzzyAddressMatching = getFuzzyMatching(logger, "FUZZY_ADDRESS_MATCHING", os.Getenv("FUZZY_ADDRESS_MATCHING"))
+	searchTimeout = getSearchTimeout(logger, os.Getenv("SEARCH_TIMEOUT"))
+	searchConcurrency = newSearchLimiter(
+		getSearchLimit(logger, "SEARCH_MAX_CONCURRENCY", os.Getenv("SEARCH_MAX_CONCURRENCY")),
+		getSearchLimit(logger, "SEARCH_MAX_QUEUED", os.Getenv("SEARCH_MAX_QUEUED")),
+	)
+	if timeouts.Write > 0 && (searchTimeout <= 0 || searchTimeout >= timeouts.Write) {
+		logger.Log("main", fmt.Sprintf("WARN: HTTP_WRITE_TIMEOUT=%v isn't longer than SEARCH_TIMEOUT=%v, searches which time out may not write their 504", timeouts.Write, searchTimeout))
+	}
+	searchMaxDataAge = getSearchMaxDataAge(logger, os.Getenv("SEARCH_MAX_DATA_AGE"))
+	searchRequiredFields = getSearchRequiredFields(logger, os.Getenv("SEARCH_REQUIRED_FIELDS"))
+	searchEmptyFields = getSearchEmptyFields(logger, os.Getenv("SEARCH_EMPTY_FIELDS"))
+	searchMaxBodyBytes = getSearchMaxBodyBytes(logger, os.Getenv("SEARCH_MAX_BODY_BYTES"))
+	searchBatchWorkers = getBatchWorkers(logger, os.Getenv("SEARCH_BATCH_WORKERS"))
+	if enabled, err := strconv.ParseBool(os.Getenv("SEARCH_EXPLAIN")); err == nil && enabled {
+		logger.Log("main", "WARN: enabling GET /search/explain, it's meant for development")
+		searchExplain = true
+	}
+
+	// Check the index serves expected results before it's ready, once search options are read
+	if path := os.Getenv("WARMUP_QUERIES_FILE"); path != "" {
+		queries, err := readWarmupQueries(path)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("ERROR: reading WARMUP_QUERIES_FILE: %v", err))
+			os.Exit(1)
+		}
+		warmup := newWarmupChecker(searcher, queries)
+		readiness.add(adminServer, "warmup-queries", warmup.check)
+		if err := searcher.setWarmup(warmup); err == nil {
+			logger.Log("main", fmt.Sprintf("%d warm-up queries passed", len(queries)))
+		}
+	}
+	if path := os.Getenv("INTERNAL_ENTITIES_FILE"); path != "" {
+		entities, err := readInternalEntities(path)
+		if err != nil {
+			logger.Log("main", fmt.Sprintf("ERROR: reading INTERNAL_ENTITIES_FILE: %v", err))
+			os.Exit(1)
+		}
+		internalEntities = entities
+		logger.Log("main", fmt.Sprintf("Suppressing self-matches of %d internal entities", len(entities)))
+	}
 	go searcher.spawnResearching(logger, companyRepo, custRepo, watchRepo, webhookRepo, updates)
 
 	// Add searcher for HTTP routes
 	addCompanyRoutes(logger, router, searcher, companyRepo, watchRepo)
 	addCustomerRoutes(logger, router, searcher, custRepo, watchRepo)
+	addWatchRoutes(logger, router, watchRepo)
+	addWebhookDeliveryRoutes(logger, router, webhookRepo)
 	addSDNRoutes(logger, router, searcher)
 	addSearchRoutes(logger, router, searcher)
+	addScreenRoutes(logger, router, searcher)
 	addDownloadRoutes(logger, router, downloadRepo)
 	addValuesRoutes(logger, router, searcher)
 
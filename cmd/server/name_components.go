@@ -0,0 +1,116 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+// nameComponents are the (precomputed) parts of an individual's name. OFAC lists individuals
+// as "FAMILY NAME(S), Given Middle" so the family name isn't lost in cultures with several of
+// them, and middle is everything after the given name, like a patronymic.
+type nameComponents struct {
+	given, middle, family string
+}
+
+// nameComponentWeights are how much each component counts towards the score, family names
+// identify an individual best while middle names and patronymics are often left out
+var nameComponentWeights = struct {
+	given, middle, family float64
+}{
+	given:  1.0,
+	middle: 0.5,
+	family: 2.0,
+}
+
+func (c nameComponents) empty() bool {
+	return c.given == "" && c.middle == "" && c.family == ""
+}
+
+// String joins every component as a single name, which is searched on lists without components
+func (c nameComponents) String() string {
+	return strings.Join(strings.Fields(strings.Join([]string{c.given, c.middle, c.family}, " ")), " ")
+}
+
+// sdnNameComponents splits the name of an individual SDN, it's nil for entities and names
+// without a comma
+func sdnNameComponents(sdn *ofac.SDN) *nameComponents {
+	if sdn == nil || !strings.EqualFold(sdn.SDNType, "individual") {
+		return nil
+	}
+	idx := strings.Index(sdn.SDNName, ",")
+	if idx < 0 {
+		return nil
+	}
+	family := precompute(sdn.SDNName[:idx])
+	rest := strings.Fields(precompute(sdn.SDNName[idx+1:]))
+	if family == "" || len(rest) == 0 {
+		return nil
+	}
+	return &nameComponents{
+		given:  rest[0],
+		middle: strings.Join(rest[1:], " "),
+		family: family,
+	}
+}
+
+// readNameComponents reads ?givenName=, ?middleName= and ?familyName=, it's nil when a search
+// has none of them
+func readNameComponents(u *url.URL) *nameComponents {
+	q := u.Query()
+	c := nameComponents{
+		given:  precompute(strings.TrimSpace(q.Get("givenName"))),
+		middle: precompute(strings.TrimSpace(q.Get("middleName"))),
+		family: precompute(strings.TrimSpace(q.Get("familyName"))),
+	}
+	if c.empty() {
+		return nil
+	}
+	return &c
+}
+
+// componentScore compares each component of query against the same component of indexed and
+// returns their weighted average. A component either of them doesn't have isn't compared, so a
+// query without a patronymic isn't penalized. It's false when no components can be compared.
+func componentScore(indexed, query *nameComponents) (float64, bool) {
+	var score, total float64
+	compare := func(indexed, query string, weight float64) {
+		if indexed != "" && query != "" {
+			score += weight * searchScorers.name.score(indexed, query)
+			total += weight
+		}
+	}
+	compare(indexed.given, query.given, nameComponentWeights.given)
+	compare(indexed.middle, query.middle, nameComponentWeights.middle)
+	compare(indexed.family, query.family, nameComponentWeights.family)
+	if total == 0 {
+		return 0.0, false
+	}
+	return score / total, true
+}
+
+// scoreComponents scores the components of an SDN instead of its whole name, when both the
+// search and SDN have them and names are scored fuzzily. Otherwise ok is false and the whole
+// name is scored. With nicknames the given name is also compared with its nicknames swapped.
+func (opts searchOptions) scoreComponents(sdn *SDN) (float64, bool) {
+	if opts.nameComponents == nil || sdn.components == nil || opts.strict || opts.matchMode != "" {
+		return 0.0, false
+	}
+	score, ok := componentScore(sdn.components, opts.nameComponents)
+	if !ok || !opts.nicknames {
+		return score, ok
+	}
+	for _, given := range nicknames.expand(opts.nameComponents.given) {
+		query := *opts.nameComponents
+		query.given = given
+		if swapped, _ := componentScore(sdn.components, &query); nicknameWeight*swapped > score {
+			score = nicknameWeight * swapped
+		}
+	}
+	return score, true
+}
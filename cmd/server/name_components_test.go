@@ -0,0 +1,143 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestNameComponents__sdn(t *testing.T) {
+	cases := []struct {
+		sdn      *ofac.SDN
+		expected *nameComponents
+	}{
+		{&ofac.SDN{SDNName: "SIDOROV, Ivan Petrovich", SDNType: "individual"}, &nameComponents{given: "ivan", middle: "petrovich", family: "sidorov"}},
+		{&ofac.SDN{SDNName: "MADURO MOROS, Nicolas", SDNType: "INDIVIDUAL"}, &nameComponents{given: "nicolas", family: "maduro moros"}},
+		{&ofac.SDN{SDNName: "AL-ZAWAHIRI, Ayman Muhammad Rabi", SDNType: "individual"}, &nameComponents{given: "ayman", middle: "muhammad rabi", family: "al zawahiri"}},
+		{&ofac.SDN{SDNName: "ANGLO-CARIBBEAN CO., LTD.", SDNType: ""}, nil},
+		{&ofac.SDN{SDNName: "Nicolas Maduro", SDNType: "individual"}, nil},
+		{&ofac.SDN{SDNName: "MADURO, ", SDNType: "individual"}, nil},
+	}
+	for _, tc := range cases {
+		got := sdnNameComponents(tc.sdn)
+		if (got == nil) != (tc.expected == nil) || (got != nil && *got != *tc.expected) {
+			t.Errorf("%q: got %#v", tc.sdn.SDNName, got)
+		}
+	}
+
+	u, _ := url.Parse("/search?givenName=Iván&familyName=SIDOROV")
+	if c := readNameComponents(u); c == nil || *c != (nameComponents{given: "ivan", family: "sidorov"}) || c.String() != "ivan sidorov" {
+		t.Errorf("%#v", c)
+	}
+	if c := readNameComponents(&url.URL{RawQuery: "name=ivan"}); c != nil {
+		t.Errorf("%#v", c)
+	}
+}
+
+func TestNameComponents__patronymic(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "SIDOROV, Ivan Petrovich", SDNType: "individual"},
+			{EntityID: "2", SDNName: "PETROV, Ivan Sidorovich", SDNType: "individual"},
+			{EntityID: "3", SDNName: "SIDOROV, Ivan", SDNType: "individual"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	scores := func(name string, opts searchOptions) map[string]float64 {
+		out := make(map[string]float64)
+		for _, sdn := range s.TopSDNs(3, name, opts) {
+			out[sdn.EntityID] = sdn.match
+		}
+		return out
+	}
+
+	// the whole names have the same tokens, so a patronymic and family name swapped still scores well
+	whole := scores("ivan petrovitch sidorov", searchOptions{})
+	if whole["2"] < 0.9 {
+		t.Fatalf("whole name scores: %v", whole)
+	}
+
+	components := &nameComponents{given: "ivan", middle: "petrovitch", family: "sidorov"}
+	scored := scores(components.String(), searchOptions{nameComponents: components})
+	if scored["1"] < 0.99 || scored["1"] < whole["1"] {
+		t.Errorf("patronymic scored %.3f (whole name %.3f)", scored["1"], whole["1"])
+	}
+	if scored["2"] > 0.8 {
+		t.Errorf("swapped components scored %.3f (whole name %.3f)", scored["2"], whole["2"])
+	}
+
+	// an SDN without a patronymic isn't penalized for it, nor is a query without one
+	eql(t, "SDN without middle name", scored["3"], 1.0)
+	if score := scores("ivan sidorov", searchOptions{nameComponents: &nameComponents{given: "ivan", family: "sidorov"}})["1"]; score != 1.0 {
+		t.Errorf("query without middle name scored %.3f", score)
+	}
+
+	// and it's explained
+	explained := explainSDN(s.SDNs[1], components.String(), searchOptions{nameComponents: components})
+	if explained.Method != "components" || explained.Match != scored["2"] {
+		t.Errorf("%#v", explained)
+	}
+
+	// strict searches score whole names
+	if score, ok := (searchOptions{strict: true, nameComponents: components}).scoreComponents(s.SDNs[0]); ok {
+		t.Errorf("strict search scored components: %.3f", score)
+	}
+}
+
+func TestNameComponents__search(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "SIDOROV, Ivan Petrovich", SDNType: "individual"},
+			{EntityID: "2", SDNName: "PETROV, Ivan Sidorovich", SDNType: "individual"},
+			{EntityID: "4", SDNName: "SIDOROV SHIPPING", SDNType: ""},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?givenName=ivan&middleName=petrovich&familyName=sidorov&limit=3", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		SDNs []struct {
+			EntityID string  `json:"entityID"`
+			Match    float64 `json:"match"`
+		} `json:"SDNs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.SDNs) != 3 || resp.SDNs[0].EntityID != "1" || resp.SDNs[0].Match != 1.0 {
+		t.Fatalf("%#v", resp.SDNs)
+	}
+
+	// the entity is scored by its whole name
+	opts, _ := readSearchOptions(&url.URL{})
+	var entity float64
+	for _, sdn := range s.TopSDNs(3, "ivan petrovich sidorov", opts) {
+		if sdn.EntityID == "4" {
+			entity = sdn.match
+		}
+	}
+	for _, sdn := range resp.SDNs {
+		if sdn.EntityID == "4" && sdn.Match != entity {
+			t.Errorf("entity scored %.3f, expected %.3f", sdn.Match, entity)
+		}
+	}
+}
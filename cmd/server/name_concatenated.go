@@ -0,0 +1,30 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/xrash/smetrics"
+)
+
+// concatenatedNameWeight discounts matches of a query against a name without its spaces, so
+// an exact "johnsmith" scores below "john smith", and remains in the medium confidence band.
+const concatenatedNameWeight = 0.9
+
+// concatenatedNameScore compares a single token query (e.g. "johnsmith" from a system which
+// doesn't store spaces) against the indexed name without spaces, as a fallback to comparing
+// tokens. It's zero for queries of several tokens, single token names and names which can't
+// score floor.
+func concatenatedNameScore(indexed, query string, floor float64) float64 {
+	if query == "" || strings.Contains(query, " ") || !strings.Contains(indexed, " ") {
+		return 0.0
+	}
+	joined := strings.Replace(indexed, " ", "", -1)
+	if floor > 0 && concatenatedNameWeight*tokenUpperBound(joined, query)+1e-9 < floor {
+		return 0.0
+	}
+	return concatenatedNameWeight * smetrics.JaroWinkler(joined, query, 0.7, 4)
+}
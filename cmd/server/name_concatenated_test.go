@@ -0,0 +1,57 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestConcatenatedNameScore(t *testing.T) {
+	eql(t, "exact", concatenatedNameScore("john smith", "johnsmith", 0), concatenatedNameWeight)
+	if score := concatenatedNameScore("john smith", "jonsmith", 0); score >= concatenatedNameWeight || score < 0.8 {
+		t.Errorf("typo scored %.3f", score)
+	}
+
+	// only single token queries against names of several tokens
+	eql(t, "tokens", concatenatedNameScore("john smith", "john smith", 0), 0.0)
+	eql(t, "single token name", concatenatedNameScore("johnsmith", "johnsmith", 0), 0.0)
+	eql(t, "empty", concatenatedNameScore("john smith", "", 0), 0.0)
+
+	// names which can't reach the floor aren't scored
+	eql(t, "floor", concatenatedNameScore("john smith", "johnsmith", 0.95), 0.0)
+	eql(t, "below floor", concatenatedNameScore("john smith", "johnsmith", 0.85), concatenatedNameWeight)
+}
+
+func TestConcatenatedNameScore__search(t *testing.T) {
+	opts, err := readSearchOptions(&url.URL{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// "Nicolas Maduro Moros" without spaces
+	query := precompute("NicolasMaduroMoros")
+	tokens := opts.fuzzyScore("nicolas maduro moros", query, false)
+
+	sdns := idSearcher.TopSDNs(1, query, opts)
+	if len(sdns) != 1 || sdns[0].EntityID != "22790" {
+		t.Fatalf("%#v", sdns)
+	}
+	eql(t, "match", sdns[0].match, concatenatedNameWeight)
+	if tokens >= sdns[0].match {
+		t.Errorf("tokens scored %.3f", tokens)
+	}
+
+	// the concatenated match is discounted, so it's below an exact name
+	if exact := idSearcher.TopSDNs(1, precompute("Nicolas Maduro Moros"), opts); exact[0].match <= sdns[0].match {
+		t.Errorf("exact name scored %.3f, concatenated %.3f", exact[0].match, sdns[0].match)
+	}
+
+	// and it's explained
+	explained := explainSDN(idSearcher.debugSDN("22790"), "NicolasMaduroMoros", opts)
+	if explained.Method != "concatenated" || explained.Match != sdns[0].match {
+		t.Errorf("%#v", explained)
+	}
+}
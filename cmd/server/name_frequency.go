@@ -0,0 +1,69 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	// maxNameFrequencyPenalty is how much lower a query made only of the most common names
+	// scores with ?nameFrequency=true, rare names aren't lowered at all
+	maxNameFrequencyPenalty = 0.15
+
+	// names with fewer bearers than rareNameBearers aren't lowered, names with commonNameBearers
+	// or more get the full penalty. Between them the penalty grows with the log of the bearers.
+	rareNameBearers   = 1e5
+	commonNameBearers = 1e8
+)
+
+// nameBearers holds rounded estimates of how many people (in millions) have each common surname
+// or given name worldwide. They're compiled from public surname and given name rankings (such
+// as Forebears and the US Census Bureau's 2010 surname table) and only need to be good enough to
+// tell very common names from rare ones. Keys are precomputed.
+var nameBearers = map[string]float64{
+	// East Asia
+	"wang": 100, "li": 100, "zhang": 95, "liu": 70, "chen": 70, "yang": 45, "huang": 30,
+	"zhao": 28, "wu": 27, "zhou": 25, "xu": 20, "sun": 18, "ma": 17, "zhu": 16, "hu": 15,
+	"kim": 20, "lee": 15, "park": 10, "choi": 5, "jung": 5,
+	"nguyen": 40, "tran": 10, "le": 10, "pham": 8, "hoang": 5,
+
+	// South Asia and the Middle East
+	"singh": 35, "kumar": 20, "sharma": 10, "patel": 6, "devi": 70,
+	"muhammad": 150, "ahmed": 30, "ahmad": 30, // spellings of muhammad are canonical (see nameVariants)
+	"ali": 25, "khan": 25, "hassan": 10, "hussein": 8, "abdul": 15, "abdullah": 8,
+
+	// Europe and the Americas
+	"garcia": 10, "rodriguez": 7, "martinez": 6, "hernandez": 6, "lopez": 6, "gonzalez": 6,
+	"perez": 5, "sanchez": 5, "silva": 10, "santos": 6, "ivanov": 1, "smith": 4, "johnson": 2.5,
+	"williams": 2, "brown": 2, "jones": 2, "miller": 1.5, "davis": 1.5,
+	"maria": 60, "jose": 20, "juan": 10, "john": 15, "david": 15,
+}
+
+// nameCommonness returns how common a precomputed name is, between 0 (every token is a rare
+// name) and 1 (every token is one of the most common names). It's the average of its tokens.
+func nameCommonness(name string) float64 {
+	tokens := strings.Fields(name)
+	if len(tokens) == 0 {
+		return 0
+	}
+	var total float64
+	for _, t := range tokens {
+		bearers := nameBearers[t] * 1e6
+		if bearers <= rareNameBearers {
+			continue
+		}
+		total += math.Min(1, math.Log10(bearers/rareNameBearers)/math.Log10(commonNameBearers/rareNameBearers))
+	}
+	return total / float64(len(tokens))
+}
+
+// nameFrequencyWeight is the multiplier of fuzzy scores for query with ?nameFrequency=true,
+// so matches on common names (like "kim" or "garcia") score lower than equally similar
+// matches on rare names.
+func nameFrequencyWeight(query string) float64 {
+	return 1 - maxNameFrequencyPenalty*nameCommonness(query)
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"net/url"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+func TestNameFrequency__commonness(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected float64
+	}{
+		{"muhammad", 1},
+		{"wang", 1},
+		{"kim", 0.7676},
+		{"ivanov", 0.3333},
+		{"zubrowski", 0},
+		{"kim zubrowski", 0.3838},
+		{"", 0},
+	}
+	for i := range cases {
+		if v := nameCommonness(cases[i].name); math.Abs(v-cases[i].expected) > 0.001 {
+			t.Errorf("%q: got %.4f", cases[i].name, v)
+		}
+	}
+
+	// keys must be precomputed to ever match
+	for name, bearers := range nameBearers {
+		if precompute(name) != name {
+			t.Errorf("%q isn't precomputed", name)
+		}
+		if bearers <= 0 {
+			t.Errorf("%q has %v bearers", name, bearers)
+		}
+	}
+}
+
+func TestNameFrequency__options(t *testing.T) {
+	u, _ := url.Parse("/search?name=kim&nameFrequency=true")
+	opts, err := readSearchOptions(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.nameFrequency {
+		t.Error("expected nameFrequency")
+	}
+	u, _ = url.Parse("/search?name=kim&nameFrequency=maybe")
+	if _, err := readSearchOptions(u); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestNameFrequency__score(t *testing.T) {
+	// the same string similarity, a typo in the last letter of each name
+	rare := searchOptions{}.score("jon tarcio", "jon tarcia")
+	common := searchOptions{}.score("jon garcio", "jon garcia")
+	if rare != common || rare == 1.0 {
+		t.Fatalf("rare=%.4f common=%.4f", rare, common)
+	}
+
+	opts := searchOptions{nameFrequency: true}
+	weightedRare, weightedCommon := opts.score("jon tarcio", "jon tarcia"), opts.score("jon garcio", "jon garcia")
+	if weightedRare <= weightedCommon {
+		t.Errorf("rare=%.4f common=%.4f", weightedRare, weightedCommon)
+	}
+	if weightedRare != rare || weightedCommon >= common {
+		t.Errorf("rare=%.4f common=%.4f", weightedRare, weightedCommon)
+	}
+
+	// exact matches are kept, so strict searches aren't changed
+	if v := (searchOptions{strict: true, nameFrequency: true}).score("kim", "kim"); v != 1.0 {
+		t.Errorf("got %.4f", v)
+	}
+}
+
+func TestNameFrequency__search(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "KIM, Jong", SDNType: "individual"},
+			{EntityID: "2", SDNName: "ZUBROWSKI, Jong", SDNType: "individual"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	score := func(opts searchOptions, query string) float64 {
+		t.Helper()
+		sdns := s.TopSDNs(1, query, opts)
+		if len(sdns) != 1 {
+			t.Fatalf("%s: got %d SDNs", query, len(sdns))
+		}
+		return sdns[0].match
+	}
+
+	// both are exact matches by default
+	if kim, zubrowski := score(searchOptions{}, "jong kim"), score(searchOptions{}, "jong zubrowski"); kim != zubrowski {
+		t.Errorf("kim=%.4f zubrowski=%.4f", kim, zubrowski)
+	}
+	opts := searchOptions{nameFrequency: true}
+	if kim, zubrowski := score(opts, "jong kim"), score(opts, "jong zubrowski"); kim >= zubrowski || zubrowski != 1.0 {
+		t.Errorf("kim=%.4f zubrowski=%.4f", kim, zubrowski)
+	}
+}
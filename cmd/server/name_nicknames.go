@@ -0,0 +1,171 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	// nicknameWeight is the multiplier of a name's score against a query with a nickname (or
+	// formal name) swapped, so names matching the query as given still rank first
+	nicknameWeight = 0.95
+
+	// maxNicknameQueries bounds how many queries with swapped names a query expands to, a query
+	// of many common names (e.g. "alexander william robert") would otherwise be scored many times
+	maxNicknameQueries = 8
+)
+
+var (
+	// defaultNicknames maps formal given names to their common nicknames and diminutives, which
+	// fuzzy matching doesn't score closely (e.g. "bill" and "william")
+	defaultNicknames = map[string][]string{
+		// English
+		"william":     {"bill", "billy", "will", "willy", "liam"},
+		"robert":      {"bob", "bobby", "rob", "robby", "bert"},
+		"richard":     {"dick", "rick", "ricky", "rich"},
+		"james":       {"jim", "jimmy", "jamie"},
+		"john":        {"jack", "johnny"},
+		"joseph":      {"joe", "joey"},
+		"michael":     {"mike", "mikey", "mick"},
+		"thomas":      {"tom", "tommy"},
+		"charles":     {"charlie", "chuck"},
+		"edward":      {"ed", "eddie", "ted", "ned"},
+		"christopher": {"chris", "kit"},
+		"daniel":      {"dan", "danny"},
+		"anthony":     {"tony"},
+		"nicholas":    {"nick", "nicky"},
+		"alexander":   {"alex", "sasha", "sandy"},
+		"elizabeth":   {"liz", "beth", "betty", "lizzy", "eliza"},
+		"margaret":    {"maggie", "peggy", "meg"},
+		"katherine":   {"kate", "kathy", "katie"},
+		"patricia":    {"pat", "patty", "trish"},
+
+		// Russian and other Slavic names, as romanized on the SDN list
+		"aleksandr":  {"sasha", "sanya", "shura"},
+		"aleksandra": {"sasha", "shura"},
+		"aleksey":    {"alyosha", "lyosha"},
+		"vladimir":   {"volodya", "vova"},
+		"dmitriy":    {"dima", "mitya"},
+		"dmitry":     {"dima", "mitya"},
+		"mikhail":    {"misha"},
+		"nikolay":    {"kolya"},
+		"ivan":       {"vanya"},
+		"yevgeniy":   {"zhenya"},
+		"sergey":     {"seryozha"},
+		"yekaterina": {"katya"},
+		"anastasiya": {"nastya"},
+		"natalya":    {"natasha"},
+		"mariya":     {"masha"},
+
+		// Spanish
+		"francisco": {"paco", "pancho", "curro"},
+		"jose":      {"pepe", "chepe"},
+		"ignacio":   {"nacho"},
+		"guillermo": {"memo"},
+		"roberto":   {"beto"},
+		"alberto":   {"beto"},
+		"manuel":    {"manolo"},
+		"enrique":   {"kike"},
+		"eduardo":   {"lalo"},
+		"jesus":     {"chucho", "chuy"},
+	}
+
+	// nicknames are swapped for their formal names (and the reverse) in queries with
+	// ?nicknames=true, NICKNAMES adds to the default table.
+	nicknames = newNicknames(os.Getenv("NICKNAMES"))
+)
+
+// nicknameTable maps each (folded) given name to the formal names it's a nickname of and the
+// nicknames it has
+type nicknameTable map[string][]string
+
+// newNicknames reads a comma separated list of nickname=formal pairs (e.g. sasha=oleksandr)
+// which are added to defaultNicknames.
+func newNicknames(list string) nicknameTable {
+	out := make(nicknameTable)
+	add := func(nickname, formal string) {
+		// names are folded and made canonical the same as indexed names (see nameVariants)
+		nickname, formal = nameVariants.canonicalize(fold(nickname)), nameVariants.canonicalize(fold(formal))
+		if nickname == "" || formal == "" || nickname == formal || strings.ContainsAny(nickname+formal, " ") {
+			return // only single tokens are swapped
+		}
+		out.link(nickname, formal)
+		out.link(formal, nickname)
+	}
+	for formal, names := range defaultNicknames {
+		for _, nickname := range names {
+			add(nickname, formal)
+		}
+	}
+	for _, pair := range strings.Split(list, ",") {
+		if parts := strings.SplitN(pair, "=", 2); len(parts) == 2 {
+			add(parts[0], parts[1])
+		}
+	}
+	return out
+}
+
+func (t nicknameTable) link(from, to string) {
+	for _, name := range t[from] {
+		if name == to {
+			return
+		}
+	}
+	t[from] = append(t[from], to)
+}
+
+// expand returns the query with each of its nicknames swapped for a formal name, or formal
+// names for a nickname, in up to maxNicknameQueries combinations. The query itself isn't
+// included, it's nil when the query has no names in the table.
+func (t nicknameTable) expand(query string) []string {
+	if len(t) == 0 {
+		return nil
+	}
+	tokens := strings.Fields(query)
+	queries := [][]string{tokens}
+	for i := range tokens {
+		names := t[tokens[i]]
+		if len(names) == 0 {
+			continue
+		}
+		for _, q := range queries {
+			for _, name := range names {
+				if len(queries) > maxNicknameQueries {
+					break
+				}
+				swapped := make([]string, len(q))
+				copy(swapped, q)
+				swapped[i] = name
+				queries = append(queries, swapped)
+			}
+		}
+	}
+	if len(queries) == 1 {
+		return nil
+	}
+	out := make([]string, 0, len(queries)-1)
+	for _, q := range queries[1:] {
+		out = append(out, strings.Join(q, " "))
+	}
+	return out
+}
+
+// nicknameScore is the best score of an indexed name against the query with nicknames swapped
+// (see expand), lowered by nicknameWeight
+func (opts searchOptions) nicknameScore(individual bool, indexed, query string) (float64, string) {
+	if opts.scoreFloor > 0 {
+		opts.scoreFloor /= nicknameWeight
+	}
+	var best float64
+	var bestQuery string
+	for _, q := range nicknames.expand(query) {
+		if score := nicknameWeight * opts.nameScore(individual, indexed, q); score > best {
+			best, bestQuery = score, q
+		}
+	}
+	return best, bestQuery
+}
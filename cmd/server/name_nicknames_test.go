@@ -0,0 +1,162 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+func TestNicknames__table(t *testing.T) {
+	table := newNicknames("Oleksandr=SASHA, bad, x=, two words=bill")
+	for name, expected := range map[string][]string{
+		"bill":      {"william"},
+		"william":   {"bill", "billy", "will", "willy", "liam"},
+		"oleksandr": {"sasha"},
+	} {
+		if v := table[name]; !reflect.DeepEqual(v, expected) {
+			t.Errorf("%s: got %v", name, v)
+		}
+	}
+	// a nickname of several formal names swaps for each of them
+	sasha := map[string]bool{}
+	for _, name := range table["sasha"] {
+		sasha[name] = true
+	}
+	for _, name := range []string{"alexander", "aleksandr", "aleksandra", "oleksandr"} {
+		if !sasha[name] {
+			t.Errorf("sasha isn't a nickname of %s: %v", name, table["sasha"])
+		}
+	}
+
+	// names must be precomputed to ever match
+	for formal, names := range defaultNicknames {
+		for _, name := range append(names, formal) {
+			if precompute(name) != name {
+				t.Errorf("%q isn't precomputed", name)
+			}
+		}
+	}
+}
+
+func TestNicknames__expand(t *testing.T) {
+	table := newNicknames("")
+	if v := table.expand("bob mugabe"); !reflect.DeepEqual(v, []string{"robert mugabe"}) {
+		t.Errorf("got %v", v)
+	}
+	if v := table.expand("william herrera"); len(v) != 5 || v[0] != "bill herrera" {
+		t.Errorf("got %v", v)
+	}
+	if v := table.expand("nicolas maduro"); v != nil {
+		t.Errorf("got %v", v)
+	}
+	if v := table.expand("william robert james"); len(v) != maxNicknameQueries {
+		t.Errorf("got %d queries: %v", len(v), v)
+	}
+	if v := (nicknameTable{}).expand("bob"); v != nil {
+		t.Errorf("got %v", v)
+	}
+}
+
+func TestNicknames__options(t *testing.T) {
+	u, _ := url.Parse("/search?name=bob+mugabe&nicknames=true")
+	opts, err := readSearchOptions(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.nicknames {
+		t.Error("expected nicknames")
+	}
+	u, _ = url.Parse("/search?name=bob+mugabe&nicknames=maybe")
+	if _, err := readSearchOptions(u); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestNicknames__search(t *testing.T) {
+	s := testdataSDNs(t)
+	opts := searchOptions{nameScoring: nameScoringTokens, nicknames: true}
+
+	cases := []struct {
+		query, entityID string
+	}{
+		{"bob mugabe", "7480"},              // MUGABE, Robert Gabriel
+		{"bill herrera buitrago", "4529"},   // HERRERA BUITRAGO, William
+		{"sasha radkov", "10139"},           // RADKOV, Aleksandr Mikhailovich
+		{"volodya lazarevic", "8818"},       // LAZAREVIC, Vladimir
+		{"nacho coronel villareal", "9335"}, // CORONEL VILLAREAL, Ignacio
+		{"dick ndlovu", "9469"},             // NDLOVU, Richard
+	}
+	for _, tc := range cases {
+		query := precompute(tc.query)
+		without := s.TopSDNs(1, query, searchOptions{nameScoring: nameScoringTokens})
+		with := s.TopSDNs(1, query, opts)
+		if len(with) != 1 || with[0].EntityID != tc.entityID {
+			t.Errorf("%s: got %#v", tc.query, with)
+			continue
+		}
+		if len(without) == 1 && without[0].EntityID == tc.entityID && without[0].match >= with[0].match {
+			t.Errorf("%s: nicknames didn't raise the score (%.4f >= %.4f)", tc.query, without[0].match, with[0].match)
+		}
+	}
+
+	// formal names are discounted below the exact name
+	exact := s.TopSDNs(1, precompute("robert mugabe"), opts)
+	nickname := s.TopSDNs(1, precompute("bob mugabe"), opts)
+	if exact[0].match <= nickname[0].match {
+		t.Errorf("exact=%.4f nickname=%.4f", exact[0].match, nickname[0].match)
+	}
+}
+
+func TestNicknames__reverse(t *testing.T) {
+	// SDNs listed with a nickname match queries of the formal name
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "PETROV, Sasha", SDNType: "individual"},
+			{EntityID: "2", SDNName: "PETROVA, Natalia", SDNType: "individual"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	query := precompute("aleksandr petrov")
+	without := s.TopSDNs(1, query, searchOptions{})
+	with := s.TopSDNs(1, query, searchOptions{nicknames: true})
+	if len(with) != 1 || with[0].EntityID != "1" || with[0].match <= without[0].match {
+		t.Fatalf("without=%#v with=%#v", without, with)
+	}
+	if math.Abs(with[0].match-nicknameWeight) > 0.001 {
+		t.Errorf("got %.4f", with[0].match)
+	}
+
+	// name components swap given names
+	opts := searchOptions{nicknames: true, nameComponents: &nameComponents{given: "aleksandr", family: "petrov"}}
+	score, ok := opts.scoreComponents(s.SDNs[0])
+	if !ok || math.Abs(score-nicknameWeight) > 0.001 {
+		t.Errorf("ok=%v score=%.4f", ok, score)
+	}
+	opts.nicknames = false
+	if unswapped, _ := opts.scoreComponents(s.SDNs[0]); unswapped >= score {
+		t.Errorf("unswapped=%.4f", unswapped)
+	}
+
+	// strict searches aren't changed
+	if v := (searchOptions{strict: true, nicknames: true}).score("sasha petrov", query); v != 0 {
+		t.Errorf("got %.4f", v)
+	}
+}
+
+func TestNicknames__explain(t *testing.T) {
+	sdn := precomputeSDNs([]*ofac.SDN{{EntityID: "7480", SDNName: "MUGABE, Robert Gabriel", SDNType: "individual"}}, nil, noLogPipeliner)[0]
+	explained := explainSDN(sdn, "bob mugabe", searchOptions{nicknames: true})
+	if explained.Method != "nickname" || explained.NicknameQuery != "robert mugabe" {
+		t.Errorf("method=%s nicknameQuery=%q", explained.Method, explained.NicknameQuery)
+	}
+	if math.Abs(explained.NameScore-explained.Match) > 0.0001 {
+		t.Errorf("nameScore=%.4f match=%.4f", explained.NameScore, explained.Match)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/bbalet/stopwords"
+	"github.com/go-kit/kit/log"
+	"github.com/xrash/smetrics"
+)
+
+const (
+	// numericTokensEntities compares numbers in the names of entities exactly (the default)
+	numericTokensEntities = "entities"
+
+	// numericTokensAll compares numbers in every name exactly, including individuals
+	numericTokensAll = "all"
+
+	// numericTokensFuzzy scores numbers like any other word
+	numericTokensFuzzy = "fuzzy"
+)
+
+// numericNameTokens is which names have their numeric tokens (like the 7 of "Group 7") compared
+// exactly instead of fuzzy matched, "Bank 123" and "Bank 124" are different banks.
+// main sets it from NUMERIC_NAME_TOKENS.
+var numericNameTokens = numericTokensEntities
+
+func init() {
+	keepNameDigits(numericNameTokens)
+}
+
+// keepNameDigits sets if stopword removal keeps the numbers of entity names, which it otherwise
+// drops like punctuation. They're kept unless numeric tokens are fuzzy matched.
+func keepNameDigits(policy string) {
+	if policy == numericTokensFuzzy {
+		stopwords.OverwriteWordSegmenter(`[\pL\p{Mc}\p{Mn}-_']+`)
+	} else {
+		stopwords.DontStripDigits()
+	}
+}
+
+// getNumericNameTokens reads entities, all or fuzzy
+//
+// env is the value from an environmental variable
+func getNumericNameTokens(logger log.Logger, env string) string {
+	switch v := strings.ToLower(strings.TrimSpace(env)); v {
+	case "":
+		return numericTokensEntities
+	case numericTokensEntities, numericTokensAll, numericTokensFuzzy:
+		logger.Log("main", fmt.Sprintf("Setting numeric name tokens to %s", v))
+		return v
+	}
+	logger.Log("main", fmt.Sprintf("invalid NUMERIC_NAME_TOKENS=%q, using %s", env, numericTokensEntities))
+	return numericTokensEntities
+}
+
+// exactNumbers returns true when numeric tokens of an individual's (or entity's) name are
+// compared exactly
+func exactNumbers(individual bool) bool {
+	switch numericNameTokens {
+	case numericTokensAll:
+		return true
+	case numericTokensEntities:
+		return !individual
+	}
+	return false
+}
+
+// isNumericToken returns true for tokens made of only digits
+func isNumericToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	for _, r := range token {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// numericTokenMatch scores two tokens like jaroWinkler unless either is a number, then the
+// tokens must be equal. A number scores zero against a word.
+func numericTokenMatch(a, b string) float64 {
+	if isNumericToken(a) || isNumericToken(b) {
+		if a == b {
+			return 1.0
+		}
+		return 0.0
+	}
+	return smetrics.JaroWinkler(a, b, 0.7, 4)
+}
+
+// numericJaroWinkler is jaroWinkler with numeric tokens compared exactly, it's never above
+// jaroWinkler so jaroWinklerUpperBound still bounds it
+func numericJaroWinkler(s1, s2 string) float64 {
+	return averageTokenScores(s1, s2, numericTokenMatch)
+}
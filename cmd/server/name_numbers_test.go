@@ -0,0 +1,88 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestNameNumbers__getNumericNameTokens(t *testing.T) {
+	logger := log.NewNopLogger()
+	cases := map[string]string{
+		"":          numericTokensEntities,
+		"entities":  numericTokensEntities,
+		" ALL ":     numericTokensAll,
+		"fuzzy":     numericTokensFuzzy,
+		"off":       numericTokensEntities,
+		"entities,": numericTokensEntities,
+	}
+	for env, expected := range cases {
+		if v := getNumericNameTokens(logger, env); v != expected {
+			t.Errorf("%q: got %q expected %q", env, v, expected)
+		}
+	}
+}
+
+func TestNameNumbers__tokenMatch(t *testing.T) {
+	eql(t, "equal numbers", numericTokenMatch("123", "123"), 1.0)
+	eql(t, "different numbers", numericTokenMatch("123", "124"), 0.0)
+	eql(t, "number and word", numericTokenMatch("123", "bank"), 0.0)
+	eql(t, "words", numericTokenMatch("bank", "bank"), 1.0)
+
+	if isNumericToken("") || isNumericToken("7th") || !isNumericToken("0042") {
+		t.Error("isNumericToken")
+	}
+}
+
+func TestNameNumbers__score(t *testing.T) {
+	defer func(v string) { numericNameTokens = v }(numericNameTokens)
+
+	opts := searchOptions{}
+	fuzzy := jaroWinkler("bank 123", "bank 124")
+
+	numericNameTokens = numericTokensEntities
+	entity := opts.scoreRecord(false, "bank 123", "bank 124")
+	if entity >= fuzzy || entity > 0.5+1e-9 {
+		t.Errorf("entity=%.3f fuzzy=%.3f", entity, fuzzy)
+	}
+	eql(t, "same number", opts.scoreRecord(false, "bank 123", "bank 123"), 1.0)
+	eql(t, "typo in a word", opts.scoreRecord(false, "bank 123", "banc 123"), jaroWinkler("bank 123", "banc 123"))
+	eql(t, "individuals are fuzzy", opts.scoreRecord(true, "bank 123", "bank 124"), fuzzy)
+
+	numericNameTokens = numericTokensAll
+	eql(t, "all individuals", opts.scoreRecord(true, "bank 123", "bank 124"), entity)
+
+	numericNameTokens = numericTokensFuzzy
+	eql(t, "fuzzy entities", opts.scoreRecord(false, "bank 123", "bank 124"), fuzzy)
+
+	// full name scoring compares the whole names
+	numericNameTokens = numericTokensEntities
+	full := searchOptions{nameScoring: nameScoringFull}
+	eql(t, "full", full.scoreRecord(false, "bank 123", "bank 124"), fullJaroWinkler("bank 123", "bank 124"))
+}
+
+func TestNameNumbers__search(t *testing.T) {
+	defer func(v string) { numericNameTokens = v }(numericNameTokens)
+	numericNameTokens = numericTokensEntities
+
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "BANK 123"},
+			{EntityID: "2", SDNName: "BANK 124"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	sdns := s.TopSDNs(2, precompute("bank 124"), searchOptions{})
+	if len(sdns) != 2 || sdns[0].EntityID != "2" || sdns[0].match != 1.0 {
+		t.Fatalf("%#v", sdns)
+	}
+	if sdns[1].match > 0.5+1e-9 {
+		t.Errorf("BANK 123 scored %.3f", sdns[1].match)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+// cjkScripts are the nameScripts of Chinese, Japanese and Korean names, which are written with
+// the family name first ("KIM Jong Un", "金正恩") unlike Western names
+var cjkScripts = map[string]bool{
+	"Han":      true,
+	"Hangul":   true,
+	"Hiragana": true,
+	"Katakana": true,
+}
+
+// cjkFamilyFirst returns the name of an individual SDN in its native order (family name
+// first) when the SDN has a native name in a CJK script. It's empty for every other SDN, whose
+// names are only compared in the order reorderSDNName indexes them.
+func cjkFamilyFirst(sdn *ofac.SDN, scriptNames []scriptName, components *nameComponents) string {
+	if components == nil || !strings.EqualFold(sdn.SDNType, "individual") {
+		return ""
+	}
+	cjk := cjkScripts[nameScript(sdn.SDNName)]
+	for i := range scriptNames {
+		cjk = cjk || cjkScripts[scriptNames[i].script]
+	}
+	if !cjk {
+		return ""
+	}
+	return strings.Join(strings.Fields(strings.Join([]string{components.family, components.given, components.middle}, " ")), " ")
+}
+
+// reverseTokens returns the words of s in the opposite order
+func reverseTokens(s string) string {
+	tokens := strings.Fields(s)
+	for i, j := 0, len(tokens)-1; i < j; i, j = i+1, j-1 {
+		tokens[i], tokens[j] = tokens[j], tokens[i]
+	}
+	return strings.Join(tokens, " ")
+}
+
+// scoreCJKOrder scores the other order of a CJK name, so a query with the family name first or
+// last matches either way. Latin queries are compared with the SDN's family first name, queries
+// in a CJK script are reversed and compared with the native name. It's false for other names.
+func (opts searchOptions) scoreCJKOrder(sdn *SDN, individual bool, script, indexed, query string) (float64, bool) {
+	if !opts.cjkNameOrder || !individual {
+		return 0.0, false
+	}
+	switch {
+	case script == "" && sdn.familyFirst != "":
+		return opts.scoreRecord(individual, sdn.familyFirst, query), true
+	case cjkScripts[script] && strings.Contains(query, " "):
+		return opts.scoreRecord(individual, indexed, reverseTokens(query)), true
+	}
+	return 0.0, false
+}
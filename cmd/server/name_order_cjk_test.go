@@ -0,0 +1,89 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+func TestNameOrderCJK__familyFirst(t *testing.T) {
+	sdns := precomputeSDNs([]*ofac.SDN{
+		{EntityID: "1", SDNName: "KIM, Jong Un", SDNType: "individual", Remarks: "DOB 08 Jan 1984; Korean name: 김 정은; nationality Korea, North."},
+		{EntityID: "2", SDNName: "SMITH, John Paul", SDNType: "individual"},
+		{EntityID: "3", SDNName: "KOREA KWANGSON BANKING CORP", SDNType: "", Remarks: "Korean name: 조선광선은행."},
+		{EntityID: "4", SDNName: "WANG, Xiaoming", SDNType: "individual", Remarks: "Chinese Simplified name: 王小明."},
+	}, nil, noLogPipeliner)
+
+	expected := []string{"kim jong un", "", "", "wang xiaoming"}
+	for i := range sdns {
+		if sdns[i].familyFirst != expected[i] {
+			t.Errorf("%s: familyFirst=%q", sdns[i].SDNName, sdns[i].familyFirst)
+		}
+	}
+	if sdns[0].name != "jong un kim" {
+		t.Errorf("Western order changed: %q", sdns[0].name)
+	}
+}
+
+func TestNameOrderCJK__search(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "KIM, Jong Un", SDNType: "individual", Remarks: "DOB 08 Jan 1984; Korean name: 김 정은; nationality Korea, North."},
+			{EntityID: "2", SDNName: "SMITH, John Paul", SDNType: "individual"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	score := func(query, params string) map[string]float64 {
+		t.Helper()
+		opts, err := readSearchOptions(&url.URL{RawQuery: params})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := make(map[string]float64)
+		for _, sdn := range s.TopSDNs(2, query, opts) {
+			out[sdn.EntityID] = sdn.match
+		}
+		return out
+	}
+
+	// the CJK name matches in either order, when word order matters
+	for _, query := range []string{"Kim Jong Un", "Jong Un Kim"} {
+		if got := score(query, "nameScoring=full")["1"]; got != 1.0 {
+			t.Errorf("%q scored %.3f", query, got)
+		}
+		if got := score(query, "strict=true")["1"]; got != 1.0 {
+			t.Errorf("strict %q scored %.3f", query, got)
+		}
+	}
+	if got := score("Kim Jong Un", "nameScoring=full&cjkNameOrder=false")["1"]; got >= 1.0 {
+		t.Errorf("cjkNameOrder=false scored %.3f", got)
+	}
+
+	// and so does the native name
+	for _, query := range []string{"김 정은", "정은 김"} {
+		if got := score(query, "nameScoring=full")["1"]; got != 1.0 {
+			t.Errorf("%q scored %.3f", query, got)
+		}
+	}
+
+	// Western names keep their order
+	full := score("Smith John Paul", "nameScoring=full")["2"]
+	if full >= 1.0 || full != score("Smith John Paul", "nameScoring=full&cjkNameOrder=false")["2"] {
+		t.Errorf("Western name scored %.3f", full)
+	}
+	if got := score("Smith John Paul", "strict=true")["2"]; got != 0.0 {
+		t.Errorf("strict Western name scored %.3f", got)
+	}
+
+	// and it's explained
+	opts, _ := readSearchOptions(&url.URL{RawQuery: "nameScoring=full"})
+	explained := explainSDN(s.SDNs[0], "Kim Jong Un", opts)
+	if explained.Method != "familyFirst" || explained.Match != 1.0 || explained.Indexed.FamilyFirst != "kim jong un" {
+		t.Errorf("%#v", explained)
+	}
+}
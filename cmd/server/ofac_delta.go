@@ -0,0 +1,180 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	moovhttp "github.com/moov-io/base/http"
+)
+
+const ofacDeltaReportPath = "/admin/ofac-delta"
+
+var (
+	// ofacDeltaURL is where the latest OFAC delta is published, refreshes apply it onto the current
+	// records instead of downloading every OFAC file. main sets it from OFAC_DELTA_URL.
+	ofacDeltaURL string
+
+	// ofacDeltaTombstones includes the last known name of each removed SDN in delta reports, so
+	// alerts on delisted SDNs can be cleared. main sets it from OFAC_DELTA_TOMBSTONES.
+	ofacDeltaTombstones bool
+)
+
+// ofacDeltaRecords returns the latest OFAC delta, there's none when ofacDeltaURL isn't set
+func ofacDeltaRecords(logger log.Logger, initialDir string) (*ofac.Delta, error) {
+	if ofacDeltaURL == "" {
+		return nil, nil
+	}
+	path, err := ofac.DownloadDelta(logger, initialDir, ofacDeltaURL)
+	if err != nil {
+		return nil, fmt.Errorf("download: %v", err)
+	}
+	defer os.RemoveAll(filepath.Dir(path))
+
+	return ofac.ReadDelta(path)
+}
+
+// ofacDeltaApplies returns true when delta changes the OFAC records of sequence. Sequence is
+// zero when it's unknown (like before the first refresh) which no delta applies to.
+func ofacDeltaApplies(delta *ofac.Delta, sequence int64) bool {
+	return delta != nil && sequence > 0 && delta.PreviousSequence == sequence
+}
+
+// applyOFACDelta returns the SDNs, addresses and alt names after the changes of delta. Records
+// of every added, updated or removed SDN are dropped and the delta's records are appended.
+func applyOFACDelta(delta *ofac.Delta, sdns []*SDN, adds []*Address, alts []*Alt, pipe *pipeliner) ([]*SDN, []*Address, []*Alt) {
+	changed := delta.Changed()
+
+	newSDNs := precomputeSDNs(delta.SDNs, delta.Addresses, pipe)
+	newAdds := precomputeAddresses(delta.Addresses)
+	newAlts := precomputeAlts(delta.AlternateIdentities)
+	if !indexRawNames {
+		compactOFACRecords(newSDNs, newAdds, newAlts)
+	}
+
+	outSDNs := make([]*SDN, 0, len(sdns)+len(newSDNs))
+	for _, sdn := range sdns {
+		if sdn != nil && !changed[sdn.EntityID] {
+			outSDNs = append(outSDNs, sdn)
+		}
+	}
+	for _, sdn := range newSDNs {
+		if sdn != nil {
+			outSDNs = append(outSDNs, sdn)
+		}
+	}
+	outAdds := make([]*Address, 0, len(adds)+len(newAdds))
+	for _, addr := range adds {
+		if addr != nil && !changed[addr.Address.EntityID] {
+			outAdds = append(outAdds, addr)
+		}
+	}
+	outAlts := make([]*Alt, 0, len(alts)+len(newAlts))
+	for _, alt := range alts {
+		if alt != nil && !changed[alt.AlternateIdentity.EntityID] {
+			outAlts = append(outAlts, alt)
+		}
+	}
+	return outSDNs, append(outAdds, newAdds...), append(outAlts, newAlts...)
+}
+
+// ofacDeltaReport lists the SDNs changed by the last OFAC delta applied onto the records
+type ofacDeltaReport struct {
+	Sequence         int64     `json:"sequence"`
+	PreviousSequence int64     `json:"previousSequence"`
+	AppliedAt        time.Time `json:"appliedAt"`
+
+	Added   []string `json:"added"`
+	Updated []string `json:"updated"`
+	Removed []string `json:"removed"`
+
+	// Tombstones are the removed SDNs as they were last listed, with OFAC_DELTA_TOMBSTONES=true
+	Tombstones []ofacTombstone `json:"tombstones,omitempty"`
+}
+
+type ofacTombstone struct {
+	EntityID string   `json:"entityID"`
+	SDNName  string   `json:"sdnName"`
+	SDNType  string   `json:"sdnType"`
+	Programs []string `json:"program"`
+}
+
+// newOFACDeltaReport describes the changes of delta onto sdns, the records before it's applied.
+// SDNs of the delta which aren't in sdns were added. Removed SDNs which aren't in sdns have no
+// tombstone.
+func newOFACDeltaReport(delta *ofac.Delta, sdns []*SDN, appliedAt time.Time) *ofacDeltaReport {
+	current := make(map[string]*SDN, len(sdns))
+	for _, sdn := range sdns {
+		if sdn != nil && sdn.SDN != nil {
+			current[sdn.EntityID] = sdn
+		}
+	}
+	report := &ofacDeltaReport{
+		Sequence:         delta.Sequence,
+		PreviousSequence: delta.PreviousSequence,
+		AppliedAt:        appliedAt,
+		Added:            []string{},
+		Updated:          []string{},
+		Removed:          []string{},
+	}
+	for _, sdn := range delta.SDNs {
+		if _, exists := current[sdn.EntityID]; exists {
+			report.Updated = append(report.Updated, sdn.EntityID)
+		} else {
+			report.Added = append(report.Added, sdn.EntityID)
+		}
+	}
+	for _, id := range delta.Removed {
+		report.Removed = append(report.Removed, id)
+		if sdn, exists := current[id]; exists && ofacDeltaTombstones {
+			report.Tombstones = append(report.Tombstones, ofacTombstone{
+				EntityID: id,
+				SDNName:  sdn.SDNName,
+				SDNType:  sdn.SDNType,
+				Programs: sdn.Programs,
+			})
+		}
+	}
+	return report
+}
+
+// getOFACDeltaReport returns the report of the last OFAC delta applied, or nil when the records
+// were only ever read from every file
+func (s *searcher) getOFACDeltaReport() *ofacDeltaReport {
+	s.RLock()
+	defer s.RUnlock()
+	return s.lastOFACDelta
+}
+
+// ofacDeltaReportHandler returns the changes of the last OFAC delta on the admin server
+func ofacDeltaReportHandler(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if requestID := moovhttp.GetRequestID(r); requestID != "" {
+			logger.Log("admin", "OFAC delta report", "requestID", requestID)
+		}
+
+		report := searcher.getOFACDeltaReport()
+		if report == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(report)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestOFACDelta__apply(t *testing.T) {
+	defer func(v string) { ofacDeltaURL = v }(ofacDeltaURL)
+	ofacDeltaURL = ""
+
+	dir := copyTestData(t)
+	defer os.RemoveAll(dir)
+
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	base := s.debugSDN("173")
+	sdns, adds, alts := len(s.SDNs), len(s.Addresses), len(s.Alts)
+
+	delta, err := ofac.ReadDelta(filepath.Join(dir, "sdn_delta.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SDNs, s.Addresses, s.Alts = applyOFACDelta(delta, s.SDNs, s.Addresses, s.Alts, s.pipe)
+
+	// 36 was removed and 99001 added, 173 gained an address and lost its alt name
+	if len(s.SDNs) != sdns || len(s.Addresses) != adds+1 || len(s.Alts) != alts-1 {
+		t.Errorf("SDNs=%d (was %d) addresses=%d (was %d) alts=%d (was %d)", len(s.SDNs), sdns, len(s.Addresses), adds, len(s.Alts), alts)
+	}
+	if sdn := s.debugSDN("36"); sdn != nil {
+		t.Errorf("36 wasn't removed: %#v", sdn)
+	}
+	for _, addr := range s.Addresses {
+		if addr.Address.EntityID == "36" {
+			t.Errorf("address of 36 wasn't removed: %#v", addr.Address)
+		}
+	}
+	for _, alt := range s.Alts {
+		if alt.AlternateIdentity.EntityID == "36" || alt.AlternateIdentity.EntityID == "173" {
+			t.Errorf("alt of %s wasn't removed: %#v", alt.AlternateIdentity.EntityID, alt.AlternateIdentity)
+		}
+	}
+
+	updated := s.debugSDN("173")
+	if updated == nil || updated == base || updated.SDNName != "ANGLO-CARIBBEAN TRADING CO., LTD." || len(updated.addresses) != 2 {
+		t.Fatalf("173 wasn't updated: %#v", updated)
+	}
+
+	// the added SDN is searchable by its name, alt name and address
+	if found := s.TopSDNs(1, "northern star shipping", searchOptions{}); len(found) != 1 || found[0].EntityID != "99001" {
+		t.Errorf("%#v", found)
+	}
+	if found := s.TopAltNames(1, "northern star marine", searchOptions{}); len(found) != 1 || found[0].AlternateIdentity.EntityID != "99001" {
+		t.Errorf("%#v", found)
+	}
+	req := addressSearchRequest{Address: "4 Marina Street", Country: "Malta"}
+	if found := s.TopAddressesFn(1, searchOptions{}, weightedAddressCompare(req, addressScoreWeights, searchOptions{})); len(found) != 1 || found[0].Address.EntityID != "99001" {
+		t.Errorf("%#v", found)
+	}
+}
+
+func TestOFACDelta__refresh(t *testing.T) {
+	defer func(v string) { ofacDeltaURL = v }(ofacDeltaURL)
+	ofacDeltaURL = "http://localhost:0/sdn_delta.json" // read from the initial directory
+
+	dir := copyTestData(t)
+	defer os.RemoveAll(dir)
+
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+
+	// every file is read on the first refresh, they include the latest delta
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if s.ofacSequence != 2 || s.debugSDN("36") == nil {
+		t.Fatalf("ofacSequence=%d", s.ofacSequence)
+	}
+	sdns := len(s.SDNs)
+
+	// the delta is applied onto the records it changes
+	s.ofacSequence = 1
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if s.ofacSequence != 2 || s.debugSDN("36") != nil || s.debugSDN("99001") == nil {
+		t.Errorf("delta wasn't applied: ofacSequence=%d", s.ofacSequence)
+	}
+
+	// records which include the delta are kept
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if s.ofacSequence != 2 || s.debugSDN("36") != nil || len(s.SDNs) != sdns {
+		t.Errorf("records weren't kept: ofacSequence=%d SDNs=%d", s.ofacSequence, len(s.SDNs))
+	}
+
+	// a gap in the sequence reads every file again
+	s.ofacSequence = 5
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if s.ofacSequence != 2 || s.debugSDN("36") == nil || s.debugSDN("99001") != nil {
+		t.Errorf("records weren't reloaded: ofacSequence=%d", s.ofacSequence)
+	}
+
+	// reindexing reads every file, the next refresh does too
+	if _, err := s.reindexSource(dir, sourceSDN); err != nil {
+		t.Fatal(err)
+	}
+	if s.ofacSequence != 0 {
+		t.Errorf("ofacSequence=%d", s.ofacSequence)
+	}
+
+	// a broken delta reads every file
+	if err := ioutil.WriteFile(filepath.Join(dir, "sdn_delta.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s.ofacSequence = 1
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if s.ofacSequence != 0 || s.debugSDN("36") == nil {
+		t.Errorf("ofacSequence=%d", s.ofacSequence)
+	}
+}
+
+func TestOFACDelta__report(t *testing.T) {
+	defer func(v string, tombstones bool) { ofacDeltaURL, ofacDeltaTombstones = v, tombstones }(ofacDeltaURL, ofacDeltaTombstones)
+	ofacDeltaURL = "http://localhost:0/sdn_delta.json" // read from the initial directory
+
+	dir := copyTestData(t)
+	defer os.RemoveAll(dir)
+
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	report := func() (int, *ofacDeltaReport) {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		ofacDeltaReportHandler(log.NewNopLogger(), s)(w, httptest.NewRequest("GET", ofacDeltaReportPath, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+		var out ofacDeltaReport
+		if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, &out
+	}
+
+	// reading every file doesn't apply a delta
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := report(); code != http.StatusNotFound {
+		t.Errorf("bogus status code: %d", code)
+	}
+
+	// removed SDNs are only listed by their ID by default
+	s.ofacSequence = 1
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	code, r := report()
+	if code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", code)
+	}
+	if r.Sequence != 2 || r.PreviousSequence != 1 || r.AppliedAt.IsZero() {
+		t.Errorf("%#v", r)
+	}
+	if len(r.Added) != 1 || r.Added[0] != "99001" || len(r.Updated) != 1 || r.Updated[0] != "173" {
+		t.Errorf("added=%v updated=%v", r.Added, r.Updated)
+	}
+	if len(r.Removed) != 1 || r.Removed[0] != "36" || len(r.Tombstones) != 0 {
+		t.Errorf("removed=%v tombstones=%#v", r.Removed, r.Tombstones)
+	}
+
+	// the report is kept until another delta is applied
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, kept := report(); kept == nil || kept.Sequence != 2 || !kept.AppliedAt.Equal(r.AppliedAt) {
+		t.Errorf("%#v", kept)
+	}
+
+	// tombstones have the last known name of removed SDNs
+	ofacDeltaTombstones = true
+	s.ofacSequence = 5
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	s.ofacSequence = 1
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	_, r = report()
+	if len(r.Tombstones) != 1 {
+		t.Fatalf("tombstones=%#v", r.Tombstones)
+	}
+	if ts := r.Tombstones[0]; ts.EntityID != "36" || ts.SDNName != "AEROCARIBBEAN AIRLINES" || len(ts.Programs) != 1 || ts.Programs[0] != "CUBA" {
+		t.Errorf("%#v", ts)
+	}
+}
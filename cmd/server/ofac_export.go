@@ -0,0 +1,77 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	moovhttp "github.com/moov-io/base/http"
+)
+
+const ofacExportPath = "/admin/ofac-export"
+
+// ofacExport returns the indexed OFAC records, which are those of the last refresh after any
+// delta. They're normalized when INDEX_RAW_NAMES=false (see compactOFACRecords) and extended
+// comments aren't kept, so SDNComments is empty.
+func (s *searcher) ofacExport() *ofac.Results {
+	s.RLock()
+	defer s.RUnlock()
+
+	res := &ofac.Results{
+		SDNs:                make([]*ofac.SDN, 0, len(s.SDNs)),
+		Addresses:           make([]*ofac.Address, 0, len(s.Addresses)),
+		AlternateIdentities: make([]*ofac.AlternateIdentity, 0, len(s.Alts)),
+	}
+	for _, sdn := range s.SDNs {
+		if sdn != nil && sdn.SDN != nil {
+			res.SDNs = append(res.SDNs, sdn.SDN)
+		}
+	}
+	for _, addr := range s.Addresses {
+		if addr != nil && addr.Address != nil {
+			res.Addresses = append(res.Addresses, addr.Address)
+		}
+	}
+	for _, alt := range s.Alts {
+		if alt != nil && alt.AlternateIdentity != nil {
+			res.AlternateIdentities = append(res.AlternateIdentities, alt.AlternateIdentity)
+		}
+	}
+	return res
+}
+
+// ofacExportHandler responds with a zip archive of the indexed OFAC records as sdn.csv, add.csv,
+// alt.csv and sdn_comments.csv, which another instance can download as OFAC_DOWNLOAD_BUNDLE.
+func ofacExportHandler(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if requestID := moovhttp.GetRequestID(r); requestID != "" {
+			logger.Log("admin", "OFAC export", "requestID", requestID)
+		}
+
+		// the archive is written before responding so an error is still a 500
+		var buf bytes.Buffer
+		if err := ofac.WriteBundle(&buf, searcher.ofacExport()); err != nil {
+			logger.Log("admin", fmt.Sprintf("ERROR: problem exporting OFAC records: %v", err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="ofac.zip"`)
+		w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	}
+}
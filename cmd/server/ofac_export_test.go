@@ -0,0 +1,112 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func readOFACFiles(t *testing.T, dir string) *ofac.Results {
+	t.Helper()
+	out := &ofac.Results{}
+	for _, name := range []string{"add.csv", "alt.csv", "sdn.csv"} {
+		res, err := ofac.Read(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out.Addresses = append(out.Addresses, res.Addresses...)
+		out.AlternateIdentities = append(out.AlternateIdentities, res.AlternateIdentities...)
+		out.SDNs = append(out.SDNs, res.SDNs...)
+	}
+	return out
+}
+
+func TestOFACExport(t *testing.T) {
+	expected := readOFACFiles(t, filepath.Join("..", "..", "test", "testdata"))
+	s := &searcher{
+		SDNs:      precomputeSDNs(expected.SDNs, nil, noLogPipeliner),
+		Addresses: precomputeAddresses(expected.Addresses),
+		Alts:      precomputeAlts(expected.AlternateIdentities),
+		pipe:      noLogPipeliner,
+	}
+
+	w := httptest.NewRecorder()
+	ofacExportHandler(log.NewNopLogger(), s)(w, httptest.NewRequest("GET", ofacExportPath, nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	if v := w.Header().Get("Content-Type"); v != "application/zip" {
+		t.Errorf("Content-Type: %s", v)
+	}
+
+	// extract the archive and read it back as OFAC files
+	dir, err := ioutil.TempDir("", "ofac-export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	body := w.Body.Bytes()
+	r, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.File) != 4 {
+		t.Errorf("got %d files", len(r.File))
+	}
+	for _, f := range r.File {
+		in, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(in)
+		in.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, f.Name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := readOFACFiles(t, dir)
+	if len(got.SDNs) != len(expected.SDNs) || len(got.Addresses) != len(expected.Addresses) || len(got.AlternateIdentities) != len(expected.AlternateIdentities) {
+		t.Fatalf("got %d SDNs, %d addresses and %d alts", len(got.SDNs), len(got.Addresses), len(got.AlternateIdentities))
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Error("exported records were read back differently")
+	}
+
+	// re-importing the export finds the same results
+	reimported := &searcher{
+		SDNs: precomputeSDNs(got.SDNs, nil, noLogPipeliner),
+		Alts: precomputeAlts(got.AlternateIdentities),
+		pipe: noLogPipeliner,
+	}
+	for _, name := range []string{"nicolas maduro", "banco nacional de cuba", "aeroflot"} {
+		before, after := s.TopSDNs(1, precompute(name), searchOptions{}), reimported.TopSDNs(1, precompute(name), searchOptions{})
+		if len(before) != 1 || len(after) != 1 || before[0].EntityID != after[0].EntityID || before[0].match != after[0].match {
+			t.Errorf("%s: before=%#v after=%#v", name, before, after)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	ofacExportHandler(log.NewNopLogger(), s)(w, httptest.NewRequest("POST", ofacExportPath, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
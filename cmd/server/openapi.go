@@ -0,0 +1,367 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/moov-io/watchman"
+	"github.com/moov-io/watchman/pkg/au"
+	"github.com/moov-io/watchman/pkg/ca"
+	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/custom"
+	"github.com/moov-io/watchman/pkg/dpl"
+	"github.com/moov-io/watchman/pkg/ofac"
+	"github.com/moov-io/watchman/pkg/un"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+// queryParam describes a query parameter accepted by GET /search
+type queryParam struct {
+	Name        string
+	Type        string // OpenAPI type: string, integer, number or boolean
+	Example     interface{}
+	Description string
+}
+
+// searchParams are the query parameters read by the /search handlers. This is the source for
+// the served OpenAPI document, so add new parameters here when a handler reads them.
+var searchParams = []queryParam{
+	{"q", "string", "John Doe", "Search across Name, Alt Names, and SDN Address fields for all available sanctions lists."},
+	{"name", "string", "Jane Smith", "Name which could correspond to an entry on any indexed list. Alt names are also searched. Repeat name to search a primary name together with known aliases, SDNs matched by several of them are boosted."},
+	{"givenName", "string", "Ivan", "Optional given name of an individual. Individual SDNs listed as FAMILY, Given Middle are scored by each component instead of their whole name, the components are searched as a name on the other lists when name isn't set."},
+	{"middleName", "string", "Petrovich", "Optional middle name or patronymic of an individual, see givenName. It's left out of the score for SDNs without one."},
+	{"familyName", "string", "Sidorov", "Optional family name(s) of an individual, see givenName. It counts for more than the other components."},
+	{"address", "string", "123 83rd Ave", "Physical address which could correspond to a human on the SDN list. Only Address results will be returned."},
+	{"city", "string", "Caracas", "City name as designated by SDN guidelines. Only Address results will be returned."},
+	{"state", "string", "CA", "State name as designated by SDN guidelines. Only Address results will be returned."},
+	{"providence", "string", "Harare", "Providence name as designated by SDN guidelines. Only Address results will be returned."},
+	{"zip", "string", "90210", "Zip code as designated by SDN guidelines. Spaces and formatting are normalized, so SW1A1AA matches SW1A 1AA. Only Address results will be returned."},
+	{"country", "string", "Venezuela", "Country name as designated by SDN guidelines. Only Address results will be returned."},
+	{"altName", "string", "Jane Smith", "Alternate name which could correspond to a human on the SDN list. Only Alt name results will be returned."},
+	{"id", "string", "10517860", "ID value often found in remarks property of an SDN."},
+	{"limit", "integer", 25, "Maximum results returned by a search. Results are sorted by their match percentage in decending order."},
+	{"sdnType", "string", "individual", "Optional filter to only return SDNs whose type case-insensitively matches."},
+	{"ofacProgram", "string", "SDGT", "Optional filter to only return SDNs whose program case-insensitively matches."},
+	{"program", "string", "SDGT", "Optional comma separated programs whose SDN, SSI and ISN results rank above results outside of them scoring up to PROGRAM_BOOST higher. Unlike ofacProgram no results are filtered and their match isn't changed."},
+	{"highlight", "boolean", true, "Optional flag to include the matched token alignment (query token to result token) on each name result."},
+	{"matchMode", "string", "wildcard", "Optional name scoring mode. Use wildcard to match '*' and '?' patterns against whole name tokens or initials to match initials (e.g. J SMITH) against the tokens they abbreviate, fuzzy matching is the default."},
+	{"nameScoring", "string", "full", "Optional name scoring. Use full to score whole names (word order matters) or tokens to score each word against its best match (word order doesn't matter). NAME_SCORING sets the default."},
+	{"nameFrequency", "boolean", true, "Optional flag to lower fuzzy scores of queries made of common names (like Kim or Garcia) by up to 15%, so matches on rare names rank above equally similar matches on common ones."},
+	{"nicknames", "boolean", true, "Optional flag to also match nicknames against formal names (like Bill and William or Sasha and Aleksandr) and the reverse, scoring 5% below the name as given."},
+	{"entityBias", "boolean", false, "Optional flag, false turns off lowering scores of individuals by 10% for queries with a business suffix (like LLC, Ltd or Inc), which otherwise rank entities above same-named individuals. Defaults to true."},
+	{"cjkNameOrder", "boolean", false, "Optional flag, false turns off also comparing Chinese, Japanese and Korean names with the family name first, which lets a query in either order match them. Defaults to true."},
+	{"minMatch", "number", 0.85, "Optional minimum score (0 to 1), results below it are dropped before the limit is applied."},
+	{"excludeIds", "string", "22790,1234", "Optional comma separated list of SDN entity IDs whose SDN, alt name and address results are left out before the limit is applied."},
+	{"suppressSelfMatches", "boolean", false, "Optional flag, false keeps results which are the internal entity (INTERNAL_ENTITIES_FILE) the search is for. They're suppressed by default."},
+	{"totalMatches", "boolean", true, "Optional flag to include totalMatches, how many results across every searched list scored above zero and minMatch before the limit was applied."},
+	{"topDelta", "number", 0.05, "Optional score delta, only results within this amount of the best match (across all lists) are returned."},
+	{"strict", "boolean", true, "Optional flag to only return exact matches (after normalization) on names and addresses. Fuzzy matches are removed. Shorthand for fuzzyName=false and fuzzyAddress=false."},
+	{"fuzzyName", "boolean", false, "Optional flag to fuzzy match names (the default, set by FUZZY_NAME_MATCHING). Use false to only return exact (normalized) name matches."},
+	{"fuzzyAddress", "boolean", false, "Optional flag to fuzzy match addresses (the default, set by FUZZY_ADDRESS_MATCHING). Use false to only return addresses whose every given field matches exactly."},
+	{"matchedName", "boolean", true, "Optional flag to include the alias (matchedName) and its type (matchedNameType, e.g. aka or fka) on results whose match came from an alias instead of the primary name."},
+	{"dedupeEntities", "boolean", true, "Optional flag to group results from different lists which refer to the same party (same name and date of birth or ID) into entities."},
+	{"sort", "string", "recent", "Optional ordering of results. score (the default) orders by match, recent orders results in the same confidence band by their listing date, the most recently listed first."},
+	{"group", "string", "bands", "Optional grouping of results. Use bands to return every result in high, medium and low confidence bands (set by SEARCH_BAND_THRESHOLDS) instead of in each list."},
+	{"timeout", "string", "5s", "Optional duration (e.g. 500ms or 5s) after which the search is cancelled with a 504. It can only lower the limit set by SEARCH_TIMEOUT."},
+	{"combine", "string", "avg", "Optional strategy (max, avg or weighted) which scores SDNs of searches with a name and address from both matches. The default keeps the name's score."},
+	{"nameWeight", "number", 0.7, "Optional weight (0 to 1) of the name's score with combine=weighted, the default is 1."},
+	{"addressWeight", "number", 0.3, "Optional weight (0 to 1) of the address's score with combine=weighted, the default is 0."},
+	{"includeAddresses", "boolean", true, "Optional flag to include every address of an SDN on its result. When the search included an address the best matching address of each SDN is marked as matched."},
+	{"includeDetails", "boolean", true, "Optional flag to include the dates of birth, nationalities and ID parsed from the remarks of SDN results under details."},
+	{"includeRemarks", "boolean", true, "Optional flag to include the raw remarks on SDN results, they're left out to keep responses small. GET /ofac/sdn/{sdnId} always includes them."},
+	{"sources", "string", "SDN,ISN", "Optional comma separated list of sources to search (" + strings.Join(knownSources, ", ") + "). All sources are searched when empty."},
+}
+
+// resultModels maps search result wrappers (which have custom JSON encoding) to the
+// list model they embed. Each result also includes match and optionally highlights.
+var resultModels = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(SDN{}):                   reflect.TypeOf(ofac.SDN{}),
+	reflect.TypeOf(Alt{}):                   reflect.TypeOf(ofac.AlternateIdentity{}),
+	reflect.TypeOf(Address{}):               reflect.TypeOf(ofac.Address{}),
+	reflect.TypeOf(SSI{}):                   reflect.TypeOf(csl.SSI{}),
+	reflect.TypeOf(DP{}):                    reflect.TypeOf(dpl.DPL{}),
+	reflect.TypeOf(BISEntity{}):             reflect.TypeOf(csl.EL{}),
+	reflect.TypeOf(ISN{}):                   reflect.TypeOf(csl.ISN{}),
+	reflect.TypeOf(CanadianSanction{}):      reflect.TypeOf(ca.Entry{}),
+	reflect.TypeOf(AustralianSanction{}):    reflect.TypeOf(au.Entry{}),
+	reflect.TypeOf(UnitedNationsSanction{}): reflect.TypeOf(un.Entry{}),
+	reflect.TypeOf(CustomEntry{}):           reflect.TypeOf(custom.Entry{}),
+}
+
+func addOpenAPIRoute(logger log.Logger, r *mux.Router) {
+	doc := buildOpenAPI()
+	r.Methods("GET").Path("/openapi.json").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		moovhttp.SetAccessControlAllowHeaders(w, r.Header.Get("Origin"))
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(doc); err != nil {
+			logger.Log("openapi", err)
+		}
+	})
+}
+
+type object = map[string]interface{}
+
+// buildOpenAPI derives an OpenAPI 3 document for the /search endpoints from searchParams and searchResponse
+func buildOpenAPI() object {
+	var params []object
+	bodyProps := make(object)
+	for _, p := range searchParams {
+		schema := object{
+			"type":    p.Type,
+			"example": p.Example,
+		}
+		body := object{
+			"type":        p.Type,
+			"example":     p.Example,
+			"description": p.Description,
+		}
+		if values, exists := searchParamValues[p.Name]; exists && !listParams[p.Name] {
+			schema["enum"], body["enum"] = values, values
+		}
+		params = append(params, object{
+			"name":        p.Name,
+			"in":          "query",
+			"description": p.Description,
+			"schema":      schema,
+		})
+		bodyProps[p.Name] = body
+	}
+	searchBody := object{"type": "object", "properties": bodyProps}
+	results := jsonSchema(reflect.TypeOf(searchResponse{}))
+
+	return object{
+		"openapi": "3.0.2",
+		"info": object{
+			"title":   "Watchman API",
+			"version": watchman.Version,
+		},
+		"paths": object{
+			"/search": object{
+				"get": object{
+					"summary":    "Search",
+					"parameters": params,
+					"responses": object{
+						"200": object{
+							"description": "Results from each list searched",
+							"headers": object{
+								dataVersionHeader: object{
+									"description": "Timestamp (RFC 3339 with nanoseconds, UTC) of the data download this search ran against, also listed in GET /downloads",
+									"schema":      object{"type": "string", "format": "date-time"},
+								},
+								staleSourcesHeader: object{
+									"description": "Comma separated sources whose records were kept from an earlier refresh because their latest download failed",
+									"schema":      object{"type": "string"},
+								},
+							},
+							"content": jsonContent(results),
+						},
+						"400": errorResponse("Invalid search parameter(s)"),
+						"503": errorResponse("Too many in-flight searches, retry after the Retry-After header. Also returned when the oldest source is older than SEARCH_MAX_DATA_AGE"),
+					},
+				},
+				"post": object{
+					"summary":     "Search with the query parameters as a JSON object",
+					"requestBody": object{"required": true, "content": jsonContent(searchBody)},
+					"responses": object{
+						"200": object{
+							"description": "Results from each list searched",
+							"content":     jsonContent(results),
+						},
+						"400": errorResponse("Invalid search parameter(s)"),
+						"413": errorResponse("Request body is larger than SEARCH_MAX_BODY_BYTES"),
+						"503": errorResponse("Too many in-flight searches, retry after the Retry-After header. Also returned when the oldest source is older than SEARCH_MAX_DATA_AGE"),
+					},
+				},
+			},
+			"/search/batch": object{
+				"post": object{
+					"summary": "Perform several searches",
+					"requestBody": object{"required": true, "content": jsonContent(object{
+						"type": "object",
+						"properties": object{
+							"searches": object{"type": "array", "items": searchBody},
+						},
+					})},
+					"responses": object{
+						"200": object{
+							"description": "Results in the same order as the searches",
+							"content": jsonContent(object{
+								"type": "object",
+								"properties": object{
+									"results": object{"type": "array", "items": object{
+										"type": "object",
+										"properties": object{
+											"status": object{"type": "integer"},
+											"result": results,
+											"error":  object{"type": "string"},
+										},
+									}},
+								},
+							}),
+						},
+						"400": errorResponse("Invalid batch"),
+						"413": errorResponse("Request body is larger than SEARCH_MAX_BODY_BYTES"),
+						"503": errorResponse("Too many in-flight searches, retry after the Retry-After header. Also returned when the oldest source is older than SEARCH_MAX_DATA_AGE"),
+					},
+				},
+			},
+			"/search/schema": object{
+				"get": object{
+					"summary": "Parameters of /search and their accepted values",
+					"responses": object{
+						"200": object{
+							"description": "Every search parameter",
+							"content":     jsonContent(jsonSchema(reflect.TypeOf(searchSchema{}))),
+						},
+					},
+				},
+			},
+			"/search/replay": object{
+				"post": object{
+					"summary": "Re-run a prior search against the current data",
+					"requestBody": object{"required": true, "content": jsonContent(object{
+						"type": "object",
+						"properties": object{
+							"search":      searchBody,
+							"fingerprint": object{"type": "string"},
+							"prior":       results,
+						},
+					})},
+					"responses": object{
+						"200": object{
+							"description": "Current results and how they differ from the prior results",
+							"content":     jsonContent(replaySchema(results)),
+						},
+						"400": errorResponse("Invalid replay or search parameter(s)"),
+						"413": errorResponse("Request body is larger than SEARCH_MAX_BODY_BYTES"),
+						"503": errorResponse("Too many in-flight searches, retry after the Retry-After header. Also returned when the oldest source is older than SEARCH_MAX_DATA_AGE"),
+					},
+				},
+			},
+		},
+	}
+}
+
+// replaySchema is the schema of replayResponse, whose result is the raw search response
+func replaySchema(results object) object {
+	schema := jsonSchema(reflect.TypeOf(replayResponse{}))
+	schema["properties"].(object)["result"] = results
+	return schema
+}
+
+func jsonContent(schema object) object {
+	return object{
+		"application/json": object{
+			"schema": schema,
+		},
+	}
+}
+
+func errorResponse(desc string) object {
+	return object{
+		"description": desc,
+		"content": jsonContent(jsonSchema(reflect.TypeOf(struct {
+			Error string `json:"error"`
+		}{}))),
+	}
+}
+
+// jsonSchema returns the OpenAPI schema of how t is encoded with encoding/json
+func jsonSchema(t reflect.Type) object {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return object{"type": "string", "format": "date-time"}
+	}
+	if model, ok := resultModels[t]; ok {
+		schema := jsonSchema(model)
+		props := schema["properties"].(object)
+		props["match"] = object{"type": "number"}
+		if _, ok := t.FieldByName("highlights"); ok {
+			props["highlights"] = jsonSchema(reflect.TypeOf([]tokenMatch{}))
+		}
+		if _, ok := t.FieldByName("titles"); ok {
+			props["titles"] = jsonSchema(reflect.TypeOf([]string{}))
+		}
+		if _, ok := t.FieldByName("alias"); ok {
+			addStructProperties(props, reflect.TypeOf(matchedAlias{}))
+		}
+		if _, ok := t.FieldByName("coordinates"); ok {
+			addStructProperties(props, reflect.TypeOf(coordinates{}))
+		}
+		if _, ok := t.FieldByName("risk"); ok {
+			addStructProperties(props, reflect.TypeOf(programRisk{}))
+		}
+		if _, ok := t.FieldByName("adjustment"); ok {
+			addStructProperties(props, reflect.TypeOf(sourceAdjustment{}))
+		}
+		if _, ok := t.FieldByName("showAddresses"); ok {
+			props["addresses"] = jsonSchema(reflect.TypeOf([]sdnAddress{}))
+		}
+		if _, ok := t.FieldByName("showDetails"); ok {
+			props["details"] = jsonSchema(reflect.TypeOf(sdnDetails{}))
+		}
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return object{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return object{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return object{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Struct:
+		props := make(object)
+		addStructProperties(props, t)
+		return object{"type": "object", "properties": props}
+	}
+	return object{}
+}
+
+func addStructProperties(props object, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			// embedded structs have their fields promoted
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructProperties(props, ft)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = jsonSchema(f.Type)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestOpenAPI__search(t *testing.T) {
+	router := mux.NewRouter()
+	addOpenAPIRoute(log.NewNopLogger(), router)
+	addSearchRoutes(log.NewNopLogger(), router, &searcher{
+		SDNs: sdnSearcher.SDNs,
+		Alts: altSearcher.Alts,
+		SSIs: ssiSearcher.SSIs,
+		DPs:  dplSearcher.DPs,
+		ISNs: isnSearcher.ISNs,
+
+		CanadianSanctions:      caSearcher.CanadianSanctions,
+		AustralianSanctions:    auSearcher.AustralianSanctions,
+		UnitedNationsSanctions: unSearcher.UnitedNationsSanctions,
+		CustomEntries:          customSearcher.CustomEntries,
+
+		pipe: noLogPipeliner,
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	var doc struct {
+		Paths map[string]struct {
+			Get struct {
+				Parameters []struct {
+					Name   string                 `json:"name"`
+					Schema map[string]interface{} `json:"schema"`
+				} `json:"parameters"`
+				Responses map[string]struct {
+					Content map[string]struct {
+						Schema map[string]interface{} `json:"schema"`
+					} `json:"content"`
+				} `json:"responses"`
+			} `json:"get"`
+		} `json:"paths"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	op := doc.Paths["/search"].Get
+
+	// every query param of our sample request must be documented
+	req := httptest.NewRequest("GET", "/search?q=Dr+AL+ZAWAHIRI&limit=2&sdnType=individual&ofacProgram=SDGT&highlight=true&sources=SDN,SSI,DPL,ISN,CA,AU,UN,CUSTOM", nil)
+	for name := range req.URL.Query() {
+		found := false
+		for _, p := range op.Parameters {
+			if p.Name == name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing %s query parameter", name)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	var body interface{}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	schema := op.Responses["200"].Content["application/json"].Schema
+	if err := validateSchema("body", schema, body); err != nil {
+		t.Error(err)
+	}
+}
+
+// validateSchema checks a decoded JSON value against the subset of OpenAPI schemas buildOpenAPI generates
+func validateSchema(path string, schema map[string]interface{}, v interface{}) error {
+	if v == nil {
+		return nil // optional fields and null slices
+	}
+	switch schema["type"] {
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %T", path, v)
+		}
+		props, _ := schema["properties"].(map[string]interface{})
+		for k, vv := range obj {
+			prop, ok := props[k].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s: undocumented property %q", path, k)
+			}
+			if err := validateSchema(path+"."+k, prop, vv); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %T", path, v)
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		for i := range arr {
+			if err := validateSchema(fmt.Sprintf("%s[%d]", path, i), items, arr[i]); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %T", path, v)
+		}
+	case "number", "integer":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %T", path, v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %T", path, v)
+		}
+	}
+	return nil
+}
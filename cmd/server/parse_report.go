@@ -0,0 +1,207 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	parseReportPath = "/admin/parse-report"
+
+	// maxParseReportSamples is how many warning messages are kept for each source, every
+	// warning is still counted
+	maxParseReportSamples = 10
+)
+
+// parseReport collects the warnings about malformed records found while reading and
+// precomputing each source's records
+type parseReport struct {
+	GeneratedAt time.Time                     `json:"generatedAt"`
+	Sources     map[string]*sourceParseReport `json:"sources"`
+}
+
+type sourceParseReport struct {
+	Warnings int      `json:"warnings"`
+	Samples  []string `json:"samples"`
+}
+
+func newParseReport() *parseReport {
+	return &parseReport{
+		GeneratedAt: time.Now(),
+		Sources:     make(map[string]*sourceParseReport),
+	}
+}
+
+func (r *parseReport) source(source string) *sourceParseReport {
+	src, exists := r.Sources[source]
+	if !exists {
+		src = &sourceParseReport{Samples: []string{}}
+		r.Sources[source] = src
+	}
+	return src
+}
+
+func (r *parseReport) warn(source string, msg string) {
+	src := r.source(source)
+	src.Warnings++
+	if len(src.Samples) < maxParseReportSamples {
+		src.Samples = append(src.Samples, msg)
+	}
+}
+
+// checkNames warns about records which failed to be normalized (nil) or whose name was
+// empty once precomputed, these records can never match a search.
+//
+// record returns the ID and precomputed name of each record, ok is false for nil records.
+func (r *parseReport) checkNames(source string, count int, record func(i int) (id, name string, ok bool)) {
+	r.source(source)
+	for i := 0; i < count; i++ {
+		id, name, ok := record(i)
+		switch {
+		case !ok:
+			r.warn(source, fmt.Sprintf("record %d couldn't be normalized", i+1))
+		case name == "" && id != "":
+			r.warn(source, fmt.Sprintf("entity %s has an empty name", id))
+		case name == "":
+			r.warn(source, fmt.Sprintf("record %d has an empty name", i+1))
+		}
+	}
+}
+
+func (r *parseReport) checkSDNs(sdns []*SDN, warnings []string) {
+	for i := range warnings {
+		r.warn(sourceSDN, warnings[i])
+	}
+	r.checkNames(sourceSDN, len(sdns), func(i int) (string, string, bool) {
+		if sdns[i] == nil {
+			return "", "", false
+		}
+		return sdns[i].EntityID, sdns[i].name, true
+	})
+}
+
+func (r *parseReport) checkDPs(dps []*DP) {
+	r.checkNames(sourceDPL, len(dps), func(i int) (string, string, bool) {
+		if dps[i] == nil {
+			return "", "", false
+		}
+		return "", dps[i].name, true
+	})
+}
+
+func (r *parseReport) checkSSIs(ssis []*SSI) {
+	r.checkNames(sourceSSI, len(ssis), func(i int) (string, string, bool) {
+		if ssis[i] == nil {
+			return "", "", false
+		}
+		return ssis[i].SectoralSanction.EntityID, ssis[i].name, true
+	})
+}
+
+func (r *parseReport) checkBISEntities(els []*BISEntity) {
+	r.checkNames(sourceEL, len(els), func(i int) (string, string, bool) {
+		if els[i] == nil {
+			return "", "", false
+		}
+		return "", els[i].name, true
+	})
+}
+
+func (r *parseReport) checkISNs(isns []*ISN) {
+	r.checkNames(sourceISN, len(isns), func(i int) (string, string, bool) {
+		if isns[i] == nil {
+			return "", "", false
+		}
+		return isns[i].Sanction.EntityID, isns[i].name, true
+	})
+}
+
+func (r *parseReport) checkCanadianSanctions(cas []*CanadianSanction) {
+	r.checkNames(sourceCA, len(cas), func(i int) (string, string, bool) {
+		if cas[i] == nil {
+			return "", "", false
+		}
+		return "", cas[i].name, true
+	})
+}
+
+func (r *parseReport) checkAustralianSanctions(aus []*AustralianSanction) {
+	r.checkNames(sourceAU, len(aus), func(i int) (string, string, bool) {
+		if aus[i] == nil {
+			return "", "", false
+		}
+		return "", aus[i].name, true
+	})
+}
+
+func (r *parseReport) checkUnitedNationsSanctions(uns []*UnitedNationsSanction) {
+	r.checkNames(sourceUN, len(uns), func(i int) (string, string, bool) {
+		if uns[i] == nil {
+			return "", "", false
+		}
+		return uns[i].Entry.DataID, uns[i].name, true
+	})
+}
+
+func (r *parseReport) checkCustomEntries(customs []*CustomEntry) {
+	r.checkNames(sourceCustom, len(customs), func(i int) (string, string, bool) {
+		if customs[i] == nil {
+			return "", "", false
+		}
+		return customs[i].Entry.ID, customs[i].name, true
+	})
+}
+
+// replaceSources returns a copy of r with the sources of other, used after reindexing one source
+func (r *parseReport) replaceSources(other *parseReport) *parseReport {
+	out := newParseReport()
+	if r != nil {
+		for source, src := range r.Sources {
+			out.Sources[source] = src
+		}
+	}
+	for source, src := range other.Sources {
+		out.Sources[source] = src
+	}
+	return out
+}
+
+// getParseReport returns the parse report of the last refresh (or reindex) of records
+func (s *searcher) getParseReport() *parseReport {
+	s.RLock()
+	defer s.RUnlock()
+	return s.lastParseReport
+}
+
+// parseReportHandler returns the warnings of the last data refresh on the admin server
+func parseReportHandler(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if requestID := moovhttp.GetRequestID(r); requestID != "" {
+			logger.Log("admin", "parse report", "requestID", requestID)
+		}
+
+		report := searcher.getParseReport()
+		if report == nil {
+			// data hasn't been refreshed yet
+			report = &parseReport{Sources: make(map[string]*sourceParseReport)}
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(report)
+	}
+}
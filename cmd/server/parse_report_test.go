@@ -0,0 +1,152 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+// copyTestData copies the files of test/testdata into a temp directory so they can be changed
+func copyTestData(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "watchman-testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join("..", "..", "test", "testdata")
+	infos, err := ioutil.ReadDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+		bs, err := ioutil.ReadFile(filepath.Join(src, info.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, info.Name()), bs, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func getParseReport(t *testing.T, s *searcher) *parseReport {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", parseReportPath, nil)
+	parseReportHandler(log.NewNopLogger(), s)(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	var report parseReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	return &report
+}
+
+func TestParseReport__refresh(t *testing.T) {
+	dir := copyTestData(t)
+	defer os.RemoveAll(dir)
+
+	// a row missing most of its fields
+	f, err := os.OpenFile(filepath.Join(dir, "sdn.csv"), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("\n99999,\"BROKEN, Row\",\"individual\"\n"); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+
+	// nothing is reported before the first refresh
+	if report := getParseReport(t, s); len(report.Sources) != 0 || !report.GeneratedAt.IsZero() {
+		t.Errorf("unexpected report: %#v", report)
+	}
+
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	report := getParseReport(t, s)
+	if report.GeneratedAt.IsZero() {
+		t.Error("missing generatedAt")
+	}
+	for _, source := range knownSources {
+		if report.Sources[source] == nil {
+			t.Errorf("missing %s", source)
+		}
+	}
+	sdns := report.Sources[sourceSDN]
+	if sdns == nil || sdns.Warnings != 1 || len(sdns.Samples) != 1 {
+		t.Fatalf("unexpected SDN report: %#v", sdns)
+	}
+	if s := sdns.Samples[0]; !strings.HasPrefix(s, "sdn.csv line ") || !strings.HasSuffix(s, ": expected 12 fields, found 3") {
+		t.Errorf("got %q", s)
+	}
+	// the vessel "7-28" keeps its numbers (see numericNameTokens)
+	for i := range s.SDNs {
+		if s.SDNs[i].EntityID == "23156" && s.SDNs[i].name != "7 28" {
+			t.Errorf("entity 23156 is indexed as %q", s.SDNs[i].name)
+		}
+	}
+	if ca := report.Sources[sourceCA]; ca.Warnings != 0 || len(ca.Samples) != 0 {
+		t.Errorf("unexpected CA report: %#v", ca)
+	}
+
+	// reindexing one source keeps the others
+	if err := ioutil.WriteFile(filepath.Join(dir, "sdn.csv"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.reindexSource(dir, sourceCA); err != nil {
+		t.Fatal(err)
+	}
+	if after := getParseReport(t, s); after.Sources[sourceSDN].Warnings != 1 || after.Sources[sourceCA] == nil {
+		t.Errorf("unexpected report: %#v", after.Sources)
+	}
+}
+
+func TestParseReport__samples(t *testing.T) {
+	report := newParseReport()
+	for i := 0; i < maxParseReportSamples+5; i++ {
+		report.warn(sourceDPL, fmt.Sprintf("warning %d", i))
+	}
+	if dpl := report.Sources[sourceDPL]; dpl.Warnings != maxParseReportSamples+5 || len(dpl.Samples) != maxParseReportSamples {
+		t.Errorf("unexpected report: %#v", dpl)
+	}
+
+	sdns := []*SDN{nil, {name: "nicolas maduro moros"}}
+	sdns = append(sdns, &SDN{SDN: sdns[1].SDN})
+	report.checkNames(sourceSDN, len(sdns), func(i int) (string, string, bool) {
+		if sdns[i] == nil {
+			return "", "", false
+		}
+		return "", sdns[i].name, true
+	})
+	if src := report.Sources[sourceSDN]; src.Warnings != 2 || src.Samples[0] != "record 1 couldn't be normalized" || src.Samples[1] != "record 3 has an empty name" {
+		t.Errorf("unexpected report: %#v", src)
+	}
+}
@@ -8,9 +8,13 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/moov-io/watchman/pkg/au"
+	"github.com/moov-io/watchman/pkg/ca"
 	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/custom"
 	"github.com/moov-io/watchman/pkg/dpl"
 	"github.com/moov-io/watchman/pkg/ofac"
+	"github.com/moov-io/watchman/pkg/un"
 
 	"github.com/go-kit/kit/log"
 )
@@ -29,7 +33,20 @@ type Name struct {
 	ssi   *csl.SSI
 	dp    *dpl.DPL
 	el    *csl.EL
+	isn   *csl.ISN
+	ca    *ca.Entry
+	au    *au.Entry
+	un    *un.Entry
+	cust  *custom.Entry
 	addrs []*ofac.Address
+
+	// qualifiers are parentheticals stripped from the name and aliases are the names some of
+	// them refer to, see parentheticalsStep
+	qualifiers nameQualifiers
+	aliases    []string
+
+	// listed is the name once it's reordered, before later steps remove any of its words
+	listed string
 }
 
 func sdnName(sdn *ofac.SDN, addrs []*ofac.Address) *Name {
@@ -65,6 +82,55 @@ func bisEntityName(el *csl.EL) *Name {
 	}
 }
 
+func isnName(isn *csl.ISN) *Name {
+	return &Name{
+		Original:  isn.Name,
+		Processed: isn.Name,
+		isn:       isn,
+	}
+}
+
+func canadianSanctionName(entry *ca.Entry) *Name {
+	return &Name{
+		Original:  entry.Name(),
+		Processed: entry.Name(),
+		ca:        entry,
+	}
+}
+
+func australianSanctionName(entry *au.Entry) *Name {
+	return &Name{
+		Original:  entry.Name,
+		Processed: entry.Name,
+		au:        entry,
+	}
+}
+
+func unitedNationsSanctionName(entry *un.Entry) *Name {
+	return &Name{
+		Original:  entry.Name(),
+		Processed: entry.Name(),
+		un:        entry,
+	}
+}
+
+func customEntryName(entry *custom.Entry) *Name {
+	return &Name{
+		Original:  entry.Name,
+		Processed: entry.Name,
+		cust:      entry,
+	}
+}
+
+// exactName is the precomputed name as it's listed (but reordered), which strict searches match
+// along with the processed name. It's empty when the pipeline didn't remove any words.
+func exactName(name *Name) string {
+	if exact := precompute(name.listed); name.listed != "" && exact != name.Processed {
+		return exact
+	}
+	return ""
+}
+
 type step interface {
 	apply(*Name) error
 }
@@ -89,10 +155,13 @@ func newPipeliner(logger log.Logger) *pipeliner {
 	return &pipeliner{
 		logger: logger,
 		steps: []step{
+			&debugStep{logger: logger, step: &parentheticalsStep{}},
 			&debugStep{logger: logger, step: &reorderSDNStep{}},
 			&debugStep{logger: logger, step: &companyNameCleanupStep{}},
 			&debugStep{logger: logger, step: &stopwordsStep{}},
 			&debugStep{logger: logger, step: &normalizeStep{}},
+			&debugStep{logger: logger, step: &honorificsStep{}},
+			&debugStep{logger: logger, step: &articlesStep{}},
 		},
 	}
 }
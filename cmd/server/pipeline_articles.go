@@ -0,0 +1,69 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/moov-io/watchman/pkg/au"
+	"github.com/moov-io/watchman/pkg/ca"
+	"github.com/moov-io/watchman/pkg/custom"
+	"github.com/moov-io/watchman/pkg/un"
+)
+
+var (
+	// defaultArticles are articles which are dropped from the start of an entity's name, "al-"
+	// and "el-" are folded into "al" and "el" like names are
+	defaultArticles = []string{"the", "al-", "el-"}
+
+	// nameArticles are stripped from entity names, ENTITY_ARTICLES replaces the default list
+	// and "none" keeps every article.
+	nameArticles = newArticles(os.Getenv("ENTITY_ARTICLES"))
+)
+
+// articles is a set of precomputed leading articles
+type articles map[string]bool
+
+// newArticles reads a comma separated list of articles. An empty list strips defaultArticles.
+func newArticles(list string) articles {
+	words := defaultArticles
+	switch strings.ToLower(strings.TrimSpace(list)) {
+	case "":
+	case "none":
+		words = nil
+	default:
+		words = strings.Split(list, ",")
+	}
+	out := make(articles)
+	for _, w := range words {
+		if w = precompute(w); w != "" {
+			out[w] = true
+		}
+	}
+	return out
+}
+
+// strip drops leading articles from a precomputed name, so "the iran co" becomes "iran co".
+// The last token is always kept, even when it's an article.
+func (a articles) strip(name string) string {
+	return honorifics(a).strip(name)
+}
+
+type articlesStep struct {
+}
+
+func (s *articlesStep) apply(in *Name) error {
+	switch {
+	case in.sdn != nil && !strings.EqualFold(in.sdn.SDNType, "individual"),
+		in.ssi != nil && !strings.EqualFold(in.ssi.Type, "individual"),
+		in.ca != nil && in.ca.Type != ca.TypeIndividual,
+		in.au != nil && in.au.Type != au.TypeIndividual,
+		in.un != nil && in.un.Type != un.TypeIndividual,
+		in.cust != nil && in.cust.Type != custom.TypeIndividual:
+		in.Processed = nameArticles.strip(in.Processed)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ca"
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+func TestArticles__strip(t *testing.T) {
+	a := newArticles("")
+	cases := []struct {
+		input, expected string
+	}{
+		{"the iran co", "iran co"},
+		{precompute("Al-Qadr Trading"), "qadr trading"},
+		{precompute("EL-NASR Export"), "nasr export"},
+		{"the al noor co", "noor co"}, // several articles
+
+		// Controls
+		{"iran co", "iran co"},
+		{"bank of the east", "bank of the east"}, // only leading articles
+		{"the", "the"},                           // never strips every token
+		{"", ""},
+	}
+	for i := range cases {
+		if ans := a.strip(cases[i].input); ans != cases[i].expected {
+			t.Errorf("#%d input=%q expected=%q got=%q", i, cases[i].input, cases[i].expected, ans)
+		}
+	}
+}
+
+func TestArticles__configured(t *testing.T) {
+	// the list is replaced and normalized like names are
+	a := newArticles(" The, LA ")
+	if v := a.strip("la societe"); v != "societe" {
+		t.Errorf("got %q", v)
+	}
+	if v := a.strip("al qadr trading"); v != "al qadr trading" {
+		t.Errorf("got %q", v)
+	}
+
+	a = newArticles("none")
+	if len(a) != 0 {
+		t.Errorf("got %#v", a)
+	}
+	if v := a.strip("the iran co"); v != "the iran co" {
+		t.Errorf("got %q", v)
+	}
+}
+
+func TestPipeline__articlesStep(t *testing.T) {
+	step := &articlesStep{}
+
+	nn := &Name{Processed: "al qadr trading", ca: &ca.Entry{Type: ca.TypeEntity}}
+	if err := step.apply(nn); err != nil {
+		t.Fatal(err)
+	}
+	if nn.Processed != "qadr trading" {
+		t.Errorf("nn.Processed=%s", nn.Processed)
+	}
+
+	// individuals keep their names
+	nn = &Name{Processed: "el sayed", sdn: &ofac.SDN{SDNType: "individual"}}
+	if err := step.apply(nn); err != nil {
+		t.Fatal(err)
+	}
+	if nn.Processed != "el sayed" {
+		t.Errorf("nn.Processed=%s", nn.Processed)
+	}
+}
+
+func TestArticles__search(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "IRAN COMPANY", SDNType: ""},
+			{EntityID: "2", SDNName: "EL SAYED, Ahmed", SDNType: "individual"},
+		}, nil, noLogPipeliner),
+		CanadianSanctions: precomputeCanadianSanctions([]*ca.Entry{
+			{Type: ca.TypeEntity, EntityOrShip: "The Al-Qadr Trading Company", Item: "1"},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	for _, q := range []string{"The Iran Company", "Iran Company"} {
+		sdns := s.TopSDNs(1, q, searchOptions{})
+		if len(sdns) != 1 || sdns[0].EntityID != "1" || math.Abs(1.0-sdns[0].match) > 0.001 {
+			t.Errorf("%s: %#v", q, sdns)
+		}
+	}
+	for _, q := range []string{"Al-Qadr Trading Company", "Qadr Trading Company", "The Qadr Trading Company"} {
+		entries := s.TopCanadianSanctions(1, q, searchOptions{})
+		if len(entries) != 1 || math.Abs(1.0-entries[0].match) > 0.001 {
+			t.Errorf("%s: %#v", q, entries)
+		}
+	}
+
+	// individuals are indexed with their articles
+	if s.SDNs[1].name != "ahmed el sayed" {
+		t.Errorf("individual: %q", s.SDNs[1].name)
+	}
+
+	// the displayed name is kept
+	entries := s.TopCanadianSanctions(1, "Qadr Trading Company", searchOptions{})
+	bs, err := json.Marshal(entries[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bs), `"entityOrShip":"The Al-Qadr Trading Company"`) {
+		t.Errorf("got %s", bs)
+	}
+	if entries[0].name != "qadr trading co" {
+		t.Errorf("indexed name=%q", entries[0].name)
+	}
+}
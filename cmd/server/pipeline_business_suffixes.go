@@ -0,0 +1,130 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+var (
+	// defaultBusinessSuffixes maps the spellings of business suffixes to their canonical form
+	defaultBusinessSuffixes = map[string]string{
+		"incorporated":                          "inc",
+		"corporation":                           "corp",
+		"company":                               "co",
+		"limited":                               "ltd",
+		"limited liability company":             "llc",
+		"limited partnership":                   "lp",
+		"limited liability partnership":         "llp",
+		"public limited company":                "plc",
+		"joint stock company":                   "jsc",
+		"open joint stock company":              "ojsc",
+		"closed joint stock company":            "cjsc",
+		"public joint stock company":            "pjsc",
+		"gesellschaft mit beschrankter haftung": "gmbh",
+		"aktiengesellschaft":                    "ag",
+		"societe anonyme":                       "sa",
+		"sociedad anonima":                      "sa",
+		"besloten vennootschap":                 "bv",
+	}
+
+	// nameSuffixes are made canonical in every precomputed name, BUSINESS_SUFFIXES replaces
+	// the default map.
+	nameSuffixes = newBusinessSuffixes(os.Getenv("BUSINESS_SUFFIXES"))
+)
+
+// businessSuffixes maps the (folded) spellings of business suffixes to a canonical token
+type businessSuffixes struct {
+	variants map[string]string
+
+	// canonical are the tokens variants are replaced with
+	canonical map[string]bool
+
+	// longest is the most tokens in a variant
+	longest int
+}
+
+// newBusinessSuffixes reads a comma separated list of variant=canonical pairs
+// (e.g. corporation=corp,incorporated=inc). An empty list uses defaultBusinessSuffixes.
+func newBusinessSuffixes(list string) *businessSuffixes {
+	pairs := defaultBusinessSuffixes
+	if list != "" {
+		pairs = make(map[string]string)
+		for _, pair := range strings.Split(list, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) == 2 {
+				pairs[parts[0]] = parts[1]
+			}
+		}
+	}
+	out := &businessSuffixes{variants: make(map[string]string), canonical: make(map[string]bool)}
+	for variant, canonical := range pairs {
+		// fold is used rather than precompute, which depends on nameSuffixes
+		variant, canonical = fold(variant), fold(canonical)
+		if variant == "" || canonical == "" {
+			continue
+		}
+		out.variants[variant] = canonical
+		out.canonical[canonical] = true
+		if n := len(strings.Fields(variant)); n > out.longest {
+			out.longest = n
+		}
+	}
+	return out
+}
+
+// canonicalize replaces business suffixes in a folded name with their canonical form, so
+// "acme corporation" becomes "acme corp". The first token is never replaced, which keeps
+// names like "limited brands" whole.
+func (s *businessSuffixes) canonicalize(name string) string {
+	if s == nil || len(s.variants) == 0 {
+		return name
+	}
+	tokens := strings.Fields(name)
+	if len(tokens) < 2 {
+		return name
+	}
+	out := []string{tokens[0]}
+	for i := 1; i < len(tokens); {
+		n := s.longest
+		if rest := len(tokens) - i; n > rest {
+			n = rest
+		}
+		for ; n > 0; n-- {
+			if canonical, exists := s.variants[strings.Join(tokens[i:i+n], " ")]; exists {
+				out = append(out, canonical)
+				break
+			}
+		}
+		if n == 0 {
+			out = append(out, tokens[i])
+			n = 1
+		}
+		i += n
+	}
+	return strings.Join(out, " ")
+}
+
+// suffixed returns true when a token of a precomputed name, other than its first, is the
+// canonical form of a business suffix (like "acme llc")
+func (s *businessSuffixes) suffixed(name string) bool {
+	if s == nil || len(s.canonical) == 0 {
+		return false
+	}
+	first := true
+	for name != "" {
+		idx := strings.IndexByte(name, ' ')
+		if idx < 0 {
+			idx = len(name)
+		}
+		if !first && s.canonical[name[:idx]] {
+			return true
+		}
+		first = false
+		name = strings.TrimPrefix(name[idx:], " ")
+	}
+	return false
+}
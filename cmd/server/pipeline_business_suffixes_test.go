@@ -0,0 +1,144 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+func TestBusinessSuffixes__canonicalize(t *testing.T) {
+	suffixes := newBusinessSuffixes("")
+	cases := []struct {
+		input, expected string
+	}{
+		{"acme corporation", "acme corp"},
+		{"acme corp", "acme corp"},
+		{"acme incorporated", "acme inc"},
+		{"acme trading company limited", "acme trading co ltd"},
+		{"acme public limited company", "acme plc"}, // the longest suffix wins
+		{"rosneft open joint stock company", "rosneft ojsc"},
+		{"acme gesellschaft mit beschrankter haftung", "acme gmbh"},
+		{"acme corporation of america", "acme corp of america"},
+
+		// Controls
+		{"limited brands", "limited brands"}, // the first token is kept
+		{"company", "company"},
+		{"qassem soleimani", "qassem soleimani"},
+		{"", ""},
+	}
+	for i := range cases {
+		if ans := suffixes.canonicalize(cases[i].input); ans != cases[i].expected {
+			t.Errorf("#%d input=%q expected=%q got=%q", i, cases[i].input, cases[i].expected, ans)
+		}
+	}
+}
+
+func TestBusinessSuffixes__configured(t *testing.T) {
+	// the map is replaced and normalized like names are
+	suffixes := newBusinessSuffixes("Sociedad Anónima=S.A.,invalid,corporation=")
+	if v := suffixes.canonicalize("banco sociedad anonima"); v != "banco sa" {
+		t.Errorf("got %q", v)
+	}
+	if v := suffixes.canonicalize("acme corporation"); v != "acme corporation" {
+		t.Errorf("got %q", v)
+	}
+}
+
+func TestBusinessSuffixes__precompute(t *testing.T) {
+	if v := precompute("ACME Corporation"); v != "acme corp" {
+		t.Errorf("got %q", v)
+	}
+	if v := precompute("Acme, Inc."); v != "acme inc" {
+		t.Errorf("got %q", v)
+	}
+}
+
+func TestBusinessSuffixes__search(t *testing.T) {
+	corporation := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{{EntityID: "1", SDNName: "ACME CORPORATION", SDNType: ""}}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	corp := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{{EntityID: "2", SDNName: "ACME CORP", SDNType: ""}}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	for _, q := range []string{"Acme Corp", "Acme Corporation"} {
+		a, b := corporation.TopSDNs(1, q, searchOptions{}), corp.TopSDNs(1, q, searchOptions{})
+		if len(a) != 1 || len(b) != 1 {
+			t.Fatalf("%s: %#v %#v", q, a, b)
+		}
+		if a[0].match != b[0].match || a[0].match != 1.0 {
+			t.Errorf("%s: ACME CORPORATION=%.4f ACME CORP=%.4f", q, a[0].match, b[0].match)
+		}
+	}
+}
+
+func TestBusinessSuffixes__suffixed(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"john smith llc":  true,
+		"acme inc":        true,
+		"acme corp ltd":   true,
+		"john smith":      false,
+		"ltd brands":      false, // the first token is never a suffix
+		"johnsmithllc":    false,
+		"llc":             false,
+		"":                false,
+		"acme  co":        true,
+		"john llcs smith": false,
+	} {
+		if got := nameSuffixes.suffixed(name); got != expected {
+			t.Errorf("%q: got %v", name, got)
+		}
+	}
+}
+
+func TestBusinessSuffixes__entityBias(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "SMITH, John", SDNType: "individual"},
+			{EntityID: "2", SDNName: "JOHN SMITH LLC", SDNType: ""},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	search := func(query string) []SDN {
+		t.Helper()
+		u := &url.URL{RawQuery: query}
+		opts, err := readSearchOptions(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.TopSDNs(2, u.Query().Get("name"), opts)
+	}
+
+	// without a business suffix the individual is the best match
+	if sdns := search("name=john+smith"); sdns[0].EntityID != "1" || sdns[0].match != 1.0 {
+		t.Errorf("%s=%.3f %s=%.3f", sdns[0].EntityID, sdns[0].match, sdns[1].EntityID, sdns[1].match)
+	}
+
+	// adding LLC moves the entity above the individual
+	sdns := search("name=john+smith+llc")
+	if sdns[0].EntityID != "2" || sdns[0].match != 1.0 || sdns[1].match != businessQueryIndividualWeight {
+		t.Errorf("%s=%.3f %s=%.3f", sdns[0].EntityID, sdns[0].match, sdns[1].EntityID, sdns[1].match)
+	}
+
+	// unless the heuristic is disabled, both names score the same
+	sdns = search("name=john+smith+llc&entityBias=false")
+	if sdns[0].match != 1.0 || sdns[1].match != 1.0 {
+		t.Errorf("%s=%.3f %s=%.3f", sdns[0].EntityID, sdns[0].match, sdns[1].EntityID, sdns[1].match)
+	}
+	if _, err := readSearchOptions(&url.URL{RawQuery: "entityBias=maybe"}); err == nil {
+		t.Error("expected error")
+	}
+
+	// and it's explained
+	opts, _ := readSearchOptions(&url.URL{})
+	explained := explainSDN(s.SDNs[0], "john smith llc", opts)
+	if len(explained.Penalties) != 1 || explained.Penalties[0].Name != "entityBias" || explained.Match != businessQueryIndividualWeight {
+		t.Errorf("%#v", explained)
+	}
+}
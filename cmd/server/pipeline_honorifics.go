@@ -0,0 +1,81 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/moov-io/watchman/pkg/au"
+	"github.com/moov-io/watchman/pkg/ca"
+	"github.com/moov-io/watchman/pkg/custom"
+	"github.com/moov-io/watchman/pkg/un"
+)
+
+var (
+	// defaultHonorifics are titles which are dropped from the start of an individual's name
+	defaultHonorifics = []string{
+		"mr", "mrs", "ms", "miss", "mx", "dr", "prof", "professor", "sir", "dame", "lord", "lady",
+		"sheikh", "shaikh", "sheik", "haji", "hajji", "mullah", "maulana", "mawlawi", "imam", "ayatollah",
+		"general", "gen", "colonel", "col", "major", "maj", "captain", "capt", "lieutenant", "lt",
+		"admiral", "brigadier", "sergeant", "sgt", "rev", "reverend",
+	}
+
+	// nameHonorifics are stripped from individual names, HONORIFICS replaces the default list
+	// and KEEP_HONORIFICS lists titles which are part of names and never stripped.
+	nameHonorifics = newHonorifics(os.Getenv("HONORIFICS"), os.Getenv("KEEP_HONORIFICS"))
+)
+
+// honorifics is a set of precomputed titles
+type honorifics map[string]bool
+
+// newHonorifics reads comma separated lists of titles to strip and to keep. An empty list
+// strips defaultHonorifics.
+func newHonorifics(list, keep string) honorifics {
+	titles := defaultHonorifics
+	if list != "" {
+		titles = strings.Split(list, ",")
+	}
+	out := make(honorifics)
+	for _, t := range titles {
+		if t = precompute(t); t != "" {
+			out[t] = true
+		}
+	}
+	for _, t := range strings.Split(keep, ",") {
+		delete(out, precompute(t))
+	}
+	return out
+}
+
+// strip drops leading titles from a precomputed name, so "general qassem" becomes "qassem".
+// The last token is always kept, even when it's a title.
+func (h honorifics) strip(name string) string {
+	tokens := strings.Fields(name)
+	var i int
+	for i < len(tokens)-1 && h[tokens[i]] {
+		i++
+	}
+	if i == 0 {
+		return name
+	}
+	return strings.Join(tokens[i:], " ")
+}
+
+type honorificsStep struct {
+}
+
+func (s *honorificsStep) apply(in *Name) error {
+	switch {
+	case in.sdn != nil && strings.EqualFold(in.sdn.SDNType, "individual"),
+		in.ssi != nil && strings.EqualFold(in.ssi.Type, "individual"),
+		in.ca != nil && in.ca.Type == ca.TypeIndividual,
+		in.au != nil && in.au.Type == au.TypeIndividual,
+		in.un != nil && in.un.Type == un.TypeIndividual,
+		in.cust != nil && in.cust.Type == custom.TypeIndividual:
+		in.Processed = nameHonorifics.strip(in.Processed)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+func TestHonorifics__strip(t *testing.T) {
+	h := newHonorifics("", "")
+	cases := []struct {
+		input, expected string
+	}{
+		{"general qassem", "qassem"},
+		{"dr ayman al zawahiri", "ayman al zawahiri"},
+		{"sheikh mohammed", "mohammed"},
+		{"mr john smith", "john smith"},
+		{"dr sheikh ahmed", "ahmed"}, // several titles
+
+		// Controls
+		{"qassem soleimani", "qassem soleimani"},
+		{"ahmed dr", "ahmed dr"}, // only leading titles
+		{"general", "general"},   // never strips every token
+		{"", ""},
+	}
+	for i := range cases {
+		if ans := h.strip(cases[i].input); ans != cases[i].expected {
+			t.Errorf("#%d input=%q expected=%q got=%q", i, cases[i].input, cases[i].expected, ans)
+		}
+	}
+}
+
+func TestHonorifics__configured(t *testing.T) {
+	// titles which are part of a legal name are kept
+	h := newHonorifics("", " Sheikh,Shaikh ")
+	if v := h.strip("sheikh mohammed"); v != "sheikh mohammed" {
+		t.Errorf("got %q", v)
+	}
+	if v := h.strip("dr sheikh mohammed"); v != "sheikh mohammed" {
+		t.Errorf("got %q", v)
+	}
+
+	// the list is replaced and normalized like names are
+	h = newHonorifics("Emir, Dr.", "")
+	if v := h.strip("emir dr abdullah"); v != "abdullah" {
+		t.Errorf("got %q", v)
+	}
+	if v := h.strip("general qassem"); v != "general qassem" {
+		t.Errorf("got %q", v)
+	}
+}
+
+func TestPipeline__honorificsStep(t *testing.T) {
+	step := &honorificsStep{}
+
+	nn := &Name{Processed: "general qassem soleimani", sdn: &ofac.SDN{SDNType: "individual"}}
+	if err := step.apply(nn); err != nil {
+		t.Fatal(err)
+	}
+	if nn.Processed != "qassem soleimani" {
+		t.Errorf("nn.Processed=%s", nn.Processed)
+	}
+
+	// entities keep their names
+	nn = &Name{Processed: "general electric", sdn: &ofac.SDN{SDNType: ""}}
+	if err := step.apply(nn); err != nil {
+		t.Fatal(err)
+	}
+	if nn.Processed != "general electric" {
+		t.Errorf("nn.Processed=%s", nn.Processed)
+	}
+}
+
+func TestHonorifics__search(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "SOLEIMANI, Qassem", SDNType: "individual"},
+			{EntityID: "2", SDNName: "GENERAL MINING COMPANY", SDNType: ""},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	for _, q := range []string{"General Qassem Soleimani", "Dr. Qassem Soleimani", "Qassem Soleimani"} {
+		sdns := s.TopSDNs(1, q, searchOptions{})
+		if len(sdns) != 1 || sdns[0].EntityID != "1" || math.Abs(1.0-sdns[0].match) > 0.001 {
+			t.Errorf("%s: %#v", q, sdns)
+		}
+	}
+	sdns := s.TopSDNs(1, "General Mining Company", searchOptions{})
+	if len(sdns) != 1 || sdns[0].EntityID != "2" || math.Abs(1.0-sdns[0].match) > 0.001 {
+		t.Errorf("entity: %#v", sdns)
+	}
+}
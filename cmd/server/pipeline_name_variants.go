@@ -0,0 +1,74 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+var (
+	// defaultNameVariants maps romanizations of names to one canonical spelling, they're tokens
+	// that phonetic and fuzzy matching don't score closely enough (e.g. "qaddafi" and "kadafi")
+	defaultNameVariants = map[string][]string{
+		"qadhafi":  {"qaddafi", "gaddafi", "gadhafi", "gadafi", "kadafi", "kaddafi", "kadhafi", "khadafi", "qadafi", "qathafi", "gathafi"},
+		"muhammad": {"mohammed", "mohammad", "mohamed", "mohamad", "muhammed", "muhamad", "muhamed"},
+		"hussein":  {"husain", "hussain", "husayn", "hussien", "hosein", "hossein"},
+		"yusuf":    {"yousef", "youssef", "yusef", "yousuf", "yossef"},
+		"osama":    {"usama", "ussama"},
+	}
+
+	// nameVariants are made canonical in every precomputed name, NAME_VARIANTS adds to the
+	// default table.
+	nameVariants = newNameVariants(os.Getenv("NAME_VARIANTS"))
+)
+
+// romanizations maps each (folded) variant token of a name to its canonical token
+type romanizations map[string]string
+
+// newNameVariants reads a comma separated list of variant=canonical pairs
+// (e.g. kaddafi=qadhafi,ghadafi=qadhafi) which are added to defaultNameVariants.
+// Pairs of the list replace default variants of the same token.
+func newNameVariants(list string) romanizations {
+	out := make(romanizations)
+	add := func(variant, canonical string) {
+		// fold is used rather than precompute, which depends on nameVariants
+		variant, canonical = fold(variant), fold(canonical)
+		if variant == "" || canonical == "" || strings.ContainsAny(variant+canonical, " ") {
+			return // only single tokens are replaced
+		}
+		out[variant] = canonical
+	}
+	for canonical, variants := range defaultNameVariants {
+		for _, variant := range variants {
+			add(variant, canonical)
+		}
+	}
+	for _, pair := range strings.Split(list, ",") {
+		if parts := strings.SplitN(pair, "=", 2); len(parts) == 2 {
+			add(parts[0], parts[1])
+		}
+	}
+	return out
+}
+
+// canonicalize replaces each token of a folded name which is a known variant with its
+// canonical spelling, so "muammar gaddafi" becomes "muammar qadhafi"
+func (r romanizations) canonicalize(name string) string {
+	if len(r) == 0 {
+		return name
+	}
+	tokens := strings.Fields(name)
+	var replaced bool
+	for i := range tokens {
+		if canonical, exists := r[tokens[i]]; exists {
+			tokens[i], replaced = canonical, true
+		}
+	}
+	if !replaced {
+		return name
+	}
+	return strings.Join(tokens, " ")
+}
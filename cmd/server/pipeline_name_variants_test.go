@@ -0,0 +1,83 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+func TestNameVariants__canonicalize(t *testing.T) {
+	variants := newNameVariants("")
+	cases := []struct {
+		input, expected string
+	}{
+		{"muammar gaddafi", "muammar qadhafi"},
+		{"kadafi", "qadhafi"},
+		{"mohammed bin salman", "muhammad bin salman"},
+		{"usama bin laden", "osama bin laden"},
+		{"saddam hussain", "saddam hussein"},
+
+		// Controls
+		{"muammar qadhafi", "muammar qadhafi"},
+		{"gaddafiya trading", "gaddafiya trading"}, // only whole tokens are replaced
+		{"nicolas maduro", "nicolas maduro"},
+		{"", ""},
+	}
+	for i := range cases {
+		if ans := variants.canonicalize(cases[i].input); ans != cases[i].expected {
+			t.Errorf("#%d input=%q expected=%q got=%q", i, cases[i].input, cases[i].expected, ans)
+		}
+	}
+
+	// canonical tokens must be precomputed like names
+	for canonical := range defaultNameVariants {
+		if precompute(canonical) != canonical {
+			t.Errorf("%q isn't precomputed", canonical)
+		}
+	}
+}
+
+func TestNameVariants__configured(t *testing.T) {
+	// pairs are added to the default table and normalized like names are
+	variants := newNameVariants("Ghadafi=QADHAFI,invalid,two words=one,kadafi=kadafy,empty=")
+	cases := map[string]string{
+		"ghadafi":       "qadhafi",
+		"gaddafi":       "qadhafi", // default
+		"kadafi":        "kadafy",  // replaced
+		"two words":     "two words",
+		"empty":         "empty",
+		"muammar mohd":  "muammar mohd",
+		"mohamed morsi": "muhammad morsi",
+	}
+	for input, expected := range cases {
+		if ans := variants.canonicalize(input); ans != expected {
+			t.Errorf("input=%q expected=%q got=%q", input, expected, ans)
+		}
+	}
+}
+
+func TestNameVariants__qadhafi(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "6987", SDNName: "QADHAFI, Muammar", SDNType: "individual"},
+			{EntityID: "173", SDNName: "ANGLO-CARIBBEAN CO., LTD."},
+		}, nil, noLogPipeliner),
+	}
+	opts := searchOptions{nameScoring: nameScoringTokens}
+	for _, variant := range []string{"Qaddafi", "Gaddafi", "Gadhafi", "Kadafi", "Kaddafi", "Khadafi", "Qadhafi"} {
+		sdns := s.TopSDNs(1, "Muammar "+variant, opts)
+		if len(sdns) != 1 || sdns[0].EntityID != "6987" {
+			t.Fatalf("%s: %#v", variant, sdns)
+		}
+		eql(t, variant, sdns[0].match, 1.0)
+	}
+
+	// fuzzy matching alone doesn't score the variants as the same name
+	if score := jaroWinkler("kadafi", "qadhafi"); score >= 0.9 {
+		t.Errorf("kadafi scored %v against qadhafi", score)
+	}
+}
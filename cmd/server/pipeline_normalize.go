@@ -8,13 +8,14 @@ import (
 	"strings"
 	"unicode"
 
+	"golang.org/x/text/cases"
 	"golang.org/x/text/runes"
 	"golang.org/x/text/transform"
 	"golang.org/x/text/unicode/norm"
 )
 
 var (
-	punctuationReplacer = strings.NewReplacer(".", "", ",", "", "-", " ", "  ", " ")
+	punctuationReplacer = strings.NewReplacer(".", "", ",", "")
 )
 
 type normalizeStep struct {
@@ -25,17 +26,41 @@ func (s *normalizeStep) apply(in *Name) error {
 	return nil
 }
 
-// precompute will lowercase each substring and remove punctuation
+// precompute will case fold each substring, remove punctuation, normalize hyphens and
+// apostrophes (see namePunctuation), collapse whitespace and make business suffixes
+// canonical (e.g. "corporation" into "corp") along with romanizations of names (see nameVariants)
 //
 // This function is called on every record from the flat files and all
-// search requests (i.e. HTTP and searcher.TopNNNs methods).
+// search requests (i.e. HTTP and searcher.TopNNNs methods). Any normalization of
+// names should happen here so indexed values and queries are always compared the same way.
 // See: https://godoc.org/golang.org/x/text/unicode/norm#Form
 // See: https://withblue.ink/2019/03/11/why-you-need-to-normalize-unicode-strings.html
 func precompute(s string) string {
-	trimmed := strings.TrimSpace(strings.ToLower(punctuationReplacer.Replace(s)))
+	return nameSuffixes.canonicalize(nameVariants.canonicalize(fold(s)))
+}
 
+// fold is the unicode normalization and case folding of precompute
+func fold(s string) string {
 	// UTF-8 normalization
-	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC) // Mn: nonspacing marks
-	result, _, _ := transform.String(t, trimmed)
-	return result
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), runes.Remove(runes.Predicate(isZeroWidth)), norm.NFC) // Mn: nonspacing marks
+	result, _, _ := transform.String(t, punctuationReplacer.Replace(s))
+	result = replaceJoiners(result)
+
+	// Unicode case folding handles more than strings.ToLower (e.g. "ß" and "ss" are equal)
+	return normalizeSpace(cases.Fold().String(result))
+}
+
+// normalizeSpace replaces each run of whitespace (tabs, newlines, non-breaking and other
+// unicode spaces) with a single space and trims the ends.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// isZeroWidth returns true for invisible characters which are sometimes found between words or letters
+func isZeroWidth(r rune) bool {
+	switch r {
+	case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff':
+		return true
+	}
+	return false
 }
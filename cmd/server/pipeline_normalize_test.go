@@ -31,6 +31,16 @@ func TestPrecompute(t *testing.T) {
 		{"Delcy Rodríguez", "delcy rodriguez"},
 		{"Raúl Castro", "raul castro"},
 		{"ANGLO-CARIBBEAN ", "anglo caribbean"},
+		// whitespace
+		{"nicolas\u00a0maduro", "nicolas maduro"},
+		{"\tnicolas \t maduro\n", "nicolas maduro"},
+		{"nicolas   maduro", "nicolas maduro"},
+		{"nicolas\u3000maduro\u2009moros", "nicolas maduro moros"},
+		{"nico\u200blas maduro", "nicolas maduro"},
+		// case folding
+		{"NiCoLaS MADURO", "nicolas maduro"},
+		{"STRASSE Straße", "strasse strasse"},
+		{"ΣΊΣΥΦΟΣ", "σισυφοσ"},
 	}
 	for i := range cases {
 		guess := precompute(cases[i].input)
@@ -39,3 +49,14 @@ func TestPrecompute(t *testing.T) {
 		}
 	}
 }
+
+func TestPrecompute__search(t *testing.T) {
+	// The same normalization applies to queries, so messy input still matches
+	for _, name := range []string{"NICOLAS\u00a0MADURO\tmoros", "  nicolas  maduro  MOROS "} {
+		sdns := idSearcher.TopSDNs(1, name, searchOptions{})
+		if len(sdns) == 0 {
+			t.Fatalf("no results for %q", name)
+		}
+		eql(t, name, sdns[0].match, 1.0)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	// parentheticalsKeep indexes names as they're published
+	parentheticalsKeep = "keep"
+
+	// parentheticalsStrip removes parentheticals from indexed names (e.g. "John Smith (deceased)"
+	// is scored as "John Smith") and includes them on results as nameQualifiers
+	parentheticalsStrip = "strip"
+
+	// parentheticalsAliases strips parentheticals like parentheticalsStrip and when one names
+	// an alias or former name (e.g. "Acme (formerly Beta)") adds it as an alias of the record
+	parentheticalsAliases = "aliases"
+)
+
+var (
+	// nameParentheticals is how parentheticals in indexed names are handled, main sets it from
+	// NAME_PARENTHETICALS
+	nameParentheticals = parentheticalsKeep
+
+	// aliasQualifiers start a parenthetical which names an alias, the longest are listed first
+	aliasQualifiers = []string{
+		"formerly known as", "also known as", "formerly", "previously", "f/k/a", "f.k.a.", "fka", "a.k.a.", "aka",
+	}
+)
+
+// getNameParentheticals reads either "keep", "strip" or "aliases"
+//
+// env is the value from an environmental variable
+func getNameParentheticals(logger log.Logger, env string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(env)); mode {
+	case "", parentheticalsKeep:
+		return parentheticalsKeep
+	case parentheticalsStrip, parentheticalsAliases:
+		logger.Log("main", fmt.Sprintf("Setting parentheticals in names to %s", mode))
+		return mode
+	default:
+		logger.Log("main", fmt.Sprintf("invalid NAME_PARENTHETICALS=%q, keeping parentheticals in names", env))
+		return parentheticalsKeep
+	}
+}
+
+// nameQualifiers are the parentheticals stripped from a record's name
+type nameQualifiers struct {
+	Qualifiers []string `json:"nameQualifiers,omitempty"`
+}
+
+// splitParentheticals returns name without its parenthetical groups and the text of each group.
+// Nested parentheses stay within their group while unbalanced ones are kept in the name. A name
+// which would be empty without its parentheticals (e.g. "(UNKNOWN)") is returned as is.
+func splitParentheticals(name string) (string, []string) {
+	if !strings.ContainsRune(name, '(') {
+		return name, nil
+	}
+	var cleaned, group strings.Builder
+	var qualifiers []string
+	depth := 0
+	for _, r := range name {
+		switch {
+		case r == '(':
+			if depth > 0 {
+				group.WriteRune(r)
+			} else {
+				cleaned.WriteRune(' ')
+			}
+			depth++
+		case r == ')' && depth > 0:
+			if depth--; depth > 0 {
+				group.WriteRune(r)
+				continue
+			}
+			if q := normalizeSpace(group.String()); q != "" {
+				qualifiers = append(qualifiers, q)
+			}
+			group.Reset()
+			cleaned.WriteRune(' ')
+		case depth > 0:
+			group.WriteRune(r)
+		default:
+			cleaned.WriteRune(r)
+		}
+	}
+	if depth > 0 {
+		// nothing closed the last group, so it's part of the name
+		cleaned.WriteString("(" + group.String())
+	}
+	out := strings.Replace(normalizeSpace(cleaned.String()), " ,", ",", -1)
+	if emptyQuery(out) {
+		return name, nil
+	}
+	return out, qualifiers
+}
+
+// qualifiedAlias returns the alias named by a parenthetical (e.g. "Beta" of "formerly Beta"),
+// which is empty when it doesn't start with one of aliasQualifiers
+func qualifiedAlias(qualifier string) string {
+	lower := strings.ToLower(qualifier)
+	for _, prefix := range aliasQualifiers {
+		if !strings.HasPrefix(lower, prefix) {
+			continue
+		}
+		// the qualifier is a whole word, "akamai" doesn't name an alias
+		rest := qualifier[len(prefix):]
+		if rest != "" && !strings.ContainsAny(rest[:1], " :") {
+			continue
+		}
+		return strings.TrimSpace(strings.TrimLeft(rest, " :"))
+	}
+	return ""
+}
+
+// cleanParentheticals strips parentheticals from name according to nameParentheticals
+func cleanParentheticals(name string) (string, []string) {
+	if nameParentheticals == parentheticalsKeep {
+		return name, nil
+	}
+	return splitParentheticals(name)
+}
+
+type parentheticalsStep struct {
+}
+
+func (s *parentheticalsStep) apply(in *Name) error {
+	var qualifiers []string
+	in.Processed, qualifiers = cleanParentheticals(in.Processed)
+	in.qualifiers = nameQualifiers{qualifiers}
+	if nameParentheticals == parentheticalsAliases {
+		for _, q := range qualifiers {
+			if alias := qualifiedAlias(q); alias != "" {
+				in.aliases = append(in.aliases, alias)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestParentheticals__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	for env, expected := range map[string]string{"": parentheticalsKeep, "keep": parentheticalsKeep, " Strip ": parentheticalsStrip, "aliases": parentheticalsAliases, "other": parentheticalsKeep} {
+		if v := getNameParentheticals(logger, env); v != expected {
+			t.Errorf("%q: got %q", env, v)
+		}
+	}
+}
+
+func TestParentheticals__split(t *testing.T) {
+	cases := []struct {
+		input, expected string
+		qualifiers      []string
+	}{
+		{"John Smith (deceased)", "John Smith", []string{"deceased"}},
+		{"Acme (formerly Beta)", "Acme", []string{"formerly Beta"}},
+		{"ANGULO OROBIO (SEGUNDO), Jose Francisco", "ANGULO OROBIO, Jose Francisco", []string{"SEGUNDO"}},
+		{"BRECO (U.K.) LTD", "BRECO LTD", []string{"U.K."}},
+		{"ISLAMIC REVOLUTIONARY GUARD CORPS (IRGC)-QODS FORCE", "ISLAMIC REVOLUTIONARY GUARD CORPS -QODS FORCE", []string{"IRGC"}},
+		{"Acme (formerly Beta (Cyprus)) Ltd (in liquidation)", "Acme Ltd", []string{"formerly Beta (Cyprus)", "in liquidation"}},
+		{"John  (  deceased  ) Smith", "John Smith", []string{"deceased"}},
+		{"John Smith ()", "John Smith", nil},
+
+		// Controls
+		{"John Smith", "John Smith", nil},
+		{"Acme (Cyprus", "Acme (Cyprus", nil},       // never closed
+		{"Acme) Ltd", "Acme) Ltd", nil},             // never opened
+		{"(UNKNOWN)", "(UNKNOWN)", nil},             // nothing would be left
+		{"Smith (deceased", "Smith (deceased", nil}, // unbalanced
+		{"A (B) (C", "A (C", []string{"B"}},         // the last group isn't closed
+		{"", "", nil},
+	}
+	for i, tc := range cases {
+		name, qualifiers := splitParentheticals(tc.input)
+		if name != tc.expected || strings.Join(qualifiers, "|") != strings.Join(tc.qualifiers, "|") {
+			t.Errorf("#%d %q: got %q %q, expected %q %q", i, tc.input, name, qualifiers, tc.expected, tc.qualifiers)
+		}
+	}
+}
+
+func TestParentheticals__qualifiedAlias(t *testing.T) {
+	cases := map[string]string{
+		"formerly Beta":                "Beta",
+		"Formerly: Beta Trading":       "Beta Trading",
+		"formerly known as Beta":       "Beta",
+		"f/k/a Beta":                   "Beta",
+		"F.K.A. Beta":                  "Beta",
+		"a.k.a. The Chess Player":      "The Chess Player",
+		"also known as Beta":           "Beta",
+		"previously Beta Holdings Ltd": "Beta Holdings Ltd",
+
+		// Controls
+		"deceased":       "",
+		"in liquidation": "",
+		"akamai":         "",
+		"formerly":       "",
+		"Cyprus":         "",
+	}
+	for qualifier, expected := range cases {
+		if v := qualifiedAlias(qualifier); v != expected {
+			t.Errorf("%q: got %q, expected %q", qualifier, v, expected)
+		}
+	}
+}
+
+func TestParentheticals__step(t *testing.T) {
+	defer func() { nameParentheticals = parentheticalsKeep }()
+
+	apply := func(name string) *Name {
+		t.Helper()
+		in := &Name{Processed: name}
+		if err := (&parentheticalsStep{}).apply(in); err != nil {
+			t.Fatal(err)
+		}
+		return in
+	}
+
+	// names are indexed as they're published by default
+	if in := apply("Acme (formerly Beta)"); in.Processed != "Acme (formerly Beta)" || in.qualifiers.Qualifiers != nil || in.aliases != nil {
+		t.Errorf("keep: %#v", in)
+	}
+
+	nameParentheticals = parentheticalsStrip
+	if in := apply("Acme (formerly Beta)"); in.Processed != "Acme" || len(in.qualifiers.Qualifiers) != 1 || in.aliases != nil {
+		t.Errorf("strip: %#v", in)
+	}
+
+	nameParentheticals = parentheticalsAliases
+	in := apply("Acme (formerly Beta) (in liquidation) (f/k/a Gamma Ltd)")
+	if in.Processed != "Acme" || len(in.qualifiers.Qualifiers) != 3 || strings.Join(in.aliases, "|") != "Beta|Gamma Ltd" {
+		t.Errorf("aliases: %#v", in)
+	}
+}
+
+func TestParentheticals__search(t *testing.T) {
+	defer func() { nameParentheticals = parentheticalsKeep }()
+
+	index := func() *searcher {
+		return &searcher{
+			SDNs: precomputeSDNs([]*ofac.SDN{
+				{EntityID: "1", SDNName: "SMITH, John (deceased)", SDNType: "individual"},
+			}, nil, noLogPipeliner),
+			Alts: precomputeAlts([]*ofac.AlternateIdentity{
+				{EntityID: "1", AlternateID: "11", AlternateType: "aka", AlternateName: "SMITH, Johnny (deceased)"},
+			}),
+			SSIs: precomputeSSIs([]*csl.SSI{
+				{EntityID: "2", Name: "Acme Trading (formerly Beta Shipping)", AlternateNames: []string{"Acme Trade"}},
+			}, noLogPipeliner),
+			pipe: noLogPipeliner,
+		}
+	}
+
+	s := index()
+	full := searchOptions{nameScoring: nameScoringFull} // tokens scoring already ignores extra words
+	kept := s.TopSDNs(1, "john smith", full)[0].match
+	if ssi := s.TopSSIs(1, "beta shipping", searchOptions{})[0]; ssi.match > 0.9 || len(ssi.SectoralSanction.AlternateNames) != 1 {
+		t.Errorf("keep: %.2f %v", ssi.match, ssi.SectoralSanction.AlternateNames)
+	}
+
+	// the cleaned name is scored and the parentheticals are a separate field
+	nameParentheticals = parentheticalsStrip
+	s = index()
+	sdn := s.TopSDNs(1, "john smith", full)[0]
+	if sdn.match != 1.0 || sdn.match <= kept {
+		t.Errorf("SDN match=%.2f, %.2f with parentheticals", sdn.match, kept)
+	}
+	bs, err := json.Marshal(sdn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(bs), `"nameQualifiers":["deceased"]`) || !strings.Contains(string(bs), `"sdnName":"SMITH, John (deceased)"`) {
+		t.Errorf("unexpected JSON: %s", bs)
+	}
+	if alt := s.TopAltNames(1, "johnny smith", searchOptions{})[0]; alt.match != 1.0 || alt.qualifiers.Qualifiers[0] != "deceased" {
+		t.Errorf("alt: %#v", alt)
+	}
+	if ssi := s.TopSSIs(1, "beta shipping", searchOptions{})[0]; len(ssi.SectoralSanction.AlternateNames) != 1 {
+		t.Errorf("strip: %v", ssi.SectoralSanction.AlternateNames)
+	}
+
+	// former names are aliases of the record
+	nameParentheticals = parentheticalsAliases
+	s = index()
+	ssi := s.TopSSIs(1, "beta shipping", searchOptions{matchedName: true})[0]
+	if ssi.match != 1.0 || ssi.alias.MatchedName != "beta shipping" || len(ssi.SectoralSanction.AlternateNames) != 2 {
+		t.Errorf("aliases: %.2f %#v %v", ssi.match, ssi.alias, ssi.SectoralSanction.AlternateNames)
+	}
+	if ssi := s.TopSSIs(1, "acme trading", searchOptions{})[0]; ssi.match != 1.0 || ssi.qualifiers.Qualifiers[0] != "formerly Beta Shipping" {
+		t.Errorf("aliases: %#v", ssi)
+	}
+}
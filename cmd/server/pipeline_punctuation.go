@@ -0,0 +1,81 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	// namePunctuationSpace replaces hyphens and apostrophes with a space, so "Al-Masri" and
+	// "O'Brien" are indexed as "al masri" and "o brien"
+	namePunctuationSpace = "space"
+
+	// namePunctuationRemove removes hyphens and apostrophes between letters, so "Al-Masri" and
+	// "O'Brien" are indexed as "almasri" and "obrien". Others (e.g. "SMITH - JONES" or quotes
+	// around 'BNC') are still replaced with a space so separate tokens aren't merged.
+	namePunctuationRemove = "remove"
+)
+
+var (
+	// namePunctuation is how precompute normalizes hyphens and apostrophes in names and
+	// queries, main sets it from NAME_PUNCTUATION
+	namePunctuation = namePunctuationSpace
+)
+
+// getNamePunctuation reads either "space" or "remove"
+//
+// env is the value from an environmental variable
+func getNamePunctuation(logger log.Logger, env string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(env)); mode {
+	case "", namePunctuationSpace:
+		return namePunctuationSpace
+	case namePunctuationRemove:
+		logger.Log("main", "Removing hyphens and apostrophes within names")
+		return mode
+	default:
+		logger.Log("main", fmt.Sprintf("invalid NAME_PUNCTUATION=%q, replacing hyphens and apostrophes with spaces", env))
+		return namePunctuationSpace
+	}
+}
+
+// isJoiner returns true for hyphens and apostrophes, which join the parts of names like
+// Al-Masri and O'Brien. Dashes (e.g. "–") aren't joiners.
+func isJoiner(r rune) bool {
+	switch r {
+	case '-', '‐', '‑', // hyphens
+		'\'', '`', '‘', '’', 'ʼ': // apostrophes
+		return true
+	}
+	return false
+}
+
+// replaceJoiners normalizes the hyphens and apostrophes of s according to namePunctuation
+func replaceJoiners(s string) string {
+	if strings.IndexFunc(s, isJoiner) < 0 {
+		return s
+	}
+	rs := []rune(s)
+	var buf strings.Builder
+	for i, r := range rs {
+		if !isJoiner(r) {
+			buf.WriteRune(r)
+			continue
+		}
+		if namePunctuation == namePunctuationRemove && i > 0 && i < len(rs)-1 && isWordRune(rs[i-1]) && isWordRune(rs[i+1]) {
+			continue
+		}
+		buf.WriteRune(' ')
+	}
+	return buf.String()
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
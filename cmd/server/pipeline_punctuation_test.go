@@ -0,0 +1,106 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestNamePunctuation__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	cases := map[string]string{
+		"":         namePunctuationSpace,
+		"space":    namePunctuationSpace,
+		" REMOVE ": namePunctuationRemove,
+		"strip":    namePunctuationSpace,
+	}
+	for env, expected := range cases {
+		if mode := getNamePunctuation(logger, env); mode != expected {
+			t.Errorf("%q: got %q expected %q", env, mode, expected)
+		}
+	}
+}
+
+func TestNamePunctuation__precompute(t *testing.T) {
+	defer func() { namePunctuation = namePunctuationSpace }()
+
+	cases := []struct {
+		input, space, remove string
+	}{
+		{"AL-MASRI", "al masri", "almasri"},
+		{"Al‐Masri", "al masri", "almasri"},
+		{"O'BRIEN", "o brien", "obrien"},
+		{"O’Brien", "o brien", "obrien"},
+		{"JAMAAT-UD-DAWAH", "jamaat ud dawah", "jamaatuddawah"},
+		{"KURDISTAN WORKERS' PARTY", "kurdistan workers party", "kurdistan workers party"},
+		// separate tokens aren't merged
+		{"SMITH - JONES", "smith jones", "smith jones"},
+		{"SMITH -JONES", "smith jones", "smith jones"},
+		{"a.k.a. 'BNC'", "aka bnc", "aka bnc"},
+		{"-MASRI-", "masri", "masri"},
+		// dashes aren't joiners
+		{"SMITH–JONES", "smith–jones", "smith–jones"},
+	}
+	for _, tc := range cases {
+		namePunctuation = namePunctuationSpace
+		if got := precompute(tc.input); got != tc.space {
+			t.Errorf("space: precompute(%q)=%q expected %q", tc.input, got, tc.space)
+		}
+		namePunctuation = namePunctuationRemove
+		if got := precompute(tc.input); got != tc.remove {
+			t.Errorf("remove: precompute(%q)=%q expected %q", tc.input, got, tc.remove)
+		}
+	}
+}
+
+func TestNamePunctuation__search(t *testing.T) {
+	defer func() { namePunctuation = namePunctuationSpace }()
+
+	sdns := []*ofac.SDN{
+		{EntityID: "6366", SDNName: "AL QA'IDA", SDNType: "entity"},
+		{EntityID: "6367", SDNName: "AL-MASRI, Abu Hafs", SDNType: "individual"},
+	}
+	search := func(query string) SDN {
+		s := &searcher{
+			SDNs: precomputeSDNs(sdns, nil, noLogPipeliner),
+			pipe: noLogPipeliner,
+		}
+		results := s.TopSDNs(1, query, searchOptions{})
+		if len(results) == 0 {
+			t.Fatalf("no results for %q", query)
+		}
+		return results[0]
+	}
+
+	// spaced hyphens and apostrophes match their spaced and other variants
+	namePunctuation = namePunctuationSpace
+	for _, query := range []string{"Abu Hafs Al Masri", "abu hafs al‐masri", "ABU HAFS AL-MASRI"} {
+		if sdn := search(query); sdn.EntityID != "6367" || sdn.match != 1.0 {
+			t.Errorf("space: %s: %s matched %.3f", query, sdn.EntityID, sdn.match)
+		}
+	}
+	for _, query := range []string{"al qa ida", "AL QA’IDA", "al qa`ida"} {
+		if sdn := search(query); sdn.EntityID != "6366" || sdn.match != 1.0 {
+			t.Errorf("space: %s: %s matched %.3f", query, sdn.EntityID, sdn.match)
+		}
+	}
+
+	// removed hyphens and apostrophes match their joined variants
+	namePunctuation = namePunctuationRemove
+	for _, query := range []string{"Abu Hafs AlMasri", "abu hafs al-masri", "ABU HAFS AL’MASRI"} {
+		if sdn := search(query); sdn.EntityID != "6367" || sdn.match != 1.0 {
+			t.Errorf("remove: %s: %s matched %.3f", query, sdn.EntityID, sdn.match)
+		}
+	}
+	for _, query := range []string{"al qaida", "AL QA’IDA"} {
+		if sdn := search(query); sdn.EntityID != "6366" || sdn.match != 1.0 {
+			t.Errorf("remove: %s: %s matched %.3f", query, sdn.EntityID, sdn.match)
+		}
+	}
+}
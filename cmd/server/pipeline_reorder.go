@@ -20,7 +20,11 @@ func (s *reorderSDNStep) apply(in *Name) error {
 
 	case in.ssi != nil:
 		in.Processed = reorderSDNName(in.Processed, in.ssi.Type)
+
+	case in.cust != nil:
+		in.Processed = reorderSDNName(in.Processed, in.cust.Type)
 	}
+	in.listed = in.Processed
 	return nil
 }
 
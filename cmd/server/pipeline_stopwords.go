@@ -48,7 +48,7 @@ func removeStopwords(in string, lang whatlanggo.Lang) string {
 	if keepStopwords {
 		return in
 	}
-	return strings.TrimSpace(stopwords.CleanString(strings.ToLower(in), lang.Iso6391(), false))
+	return strings.TrimSpace(stopwords.CleanString(strings.ToLower(normalizeSpace(in)), lang.Iso6391(), false))
 }
 
 // detectLanguage will return a guess as to the appropriate language a given SDN's name
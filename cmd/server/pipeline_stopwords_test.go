@@ -57,6 +57,7 @@ func TestStopwords__clean(t *testing.T) {
 		expected string
 	}{
 		{"Trees and Trucks", whatlanggo.Eng, "trees trucks"},
+		{"Trees\u00a0and\tTrucks", whatlanggo.Eng, "trees trucks"},
 		{"COLOMBIANA DE CERDOS LTDA.", whatlanggo.Spa, "colombiana cerdos ltda"},
 		{"INVERSIONES LA QUINTA Y CIA. LTDA.", whatlanggo.Spa, "inversiones quinta y cia ltda"},
 		{"COMITE' DE BIENFAISANCE ET DE SECOURS AUX PALESTINIENS", whatlanggo.Fra, "comite' bienfaisance secours palestiniens"},
@@ -0,0 +1,55 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-kit/kit/log"
+)
+
+// redactedQuery replaces query text in logs when privacyMode is enabled
+const redactedQuery = "[redacted]"
+
+// privacyMode guarantees query text isn't persisted: logs don't include it and responses can't
+// be cached. Metrics are only labeled by the type of search, so they never include it.
+// main sets it from PRIVACY_MODE.
+var privacyMode bool
+
+// getPrivacyMode reads a boolean which defaults to false
+//
+// env is the value from an environmental variable
+func getPrivacyMode(logger log.Logger, env string) bool {
+	if env == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(env)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid PRIVACY_MODE=%q, query text can be logged", env))
+		return false
+	}
+	if enabled {
+		logger.Log("main", "Privacy mode enabled, query text won't be logged or cached")
+	}
+	return enabled
+}
+
+// redact returns v for logging, or redactedQuery in privacy mode
+func redact(v interface{}) interface{} {
+	if privacyMode {
+		return redactedQuery
+	}
+	return v
+}
+
+// setPrivacyHeaders forbids clients and proxies from storing responses in privacy mode
+func setPrivacyHeaders(w http.ResponseWriter) {
+	if privacyMode {
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Pragma", "no-cache")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestPrivacyMode(t *testing.T) {
+	defer func(v bool) { privacyMode = v }(privacyMode)
+	defer func(v bool) { searchExplain = v }(searchExplain)
+	privacyMode, searchExplain = true, true
+
+	var logs bytes.Buffer
+	logger := log.NewLogfmtLogger(&logs)
+
+	router := mux.NewRouter()
+	addSearchRoutes(logger, router, idSearcher)
+
+	requests := []*http.Request{
+		httptest.NewRequest("GET", "/search?q=zyqxnicolas", nil),
+		httptest.NewRequest("GET", "/search?id=zyqx12345", nil),
+		httptest.NewRequest("GET", "/search?name=zyqxmaduro", nil),
+		httptest.NewRequest("GET", "/search?name=zyqxmaduro&address=zyqxstreet", nil),
+		httptest.NewRequest("GET", "/search?altName=zyqxalias", nil),
+		httptest.NewRequest("GET", "/search?address=zyqxstreet&city=zyqxcity", nil),
+		httptest.NewRequest("GET", "/search/explain?name=zyqxmaduro&sdnId=22790", nil),
+		httptest.NewRequest("POST", "/search", strings.NewReader(`{"name": "zyqxmaduro"}`)),
+		httptest.NewRequest("POST", "/search/batch", strings.NewReader(`[{"name": "zyqxmaduro"}, {"q": "zyqxnicolas"}]`)),
+		httptest.NewRequest("POST", "/search/batch/stream", strings.NewReader(`[{"name": "zyqxmaduro"}]`)),
+	}
+	for _, req := range requests {
+		req.Header.Set("X-Request-ID", "privacy")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		w.Flush()
+
+		if v := w.Header().Get("Cache-Control"); v != "no-store" {
+			t.Errorf("%s %s: Cache-Control=%q", req.Method, req.URL, v)
+		}
+	}
+
+	// name watches found by async searches
+	companyRepo := createTestCompanyRepository(t)
+	defer companyRepo.close()
+	customerRepo := createTestCustomerRepository(t)
+	defer customerRepo.close()
+
+	defer func(l log.Logger) { idSearcher.logger = l }(idSearcher.logger)
+	idSearcher.logger = logger
+	for _, w := range []watch{{id: "customer", customerName: "zyqxcustomer"}, {id: "company", companyName: "zyqxcompany"}} {
+		idSearcher.renderBody(w, nil, companyRepo, customerRepo)
+	}
+
+	var metrics bytes.Buffer
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range families {
+		metrics.WriteString(mf.GetName())
+		for _, m := range mf.GetMetric() {
+			for _, label := range m.GetLabel() {
+				metrics.WriteString(" " + label.GetName() + "=" + label.GetValue())
+			}
+		}
+		metrics.WriteString("\n")
+	}
+
+	if logs.Len() == 0 || !strings.Contains(logs.String(), redactedQuery) {
+		t.Fatalf("no redacted logs: %s", logs.String())
+	}
+	for _, output := range []string{logs.String(), metrics.String()} {
+		if strings.Contains(output, "zyqx") {
+			t.Errorf("query text in output:\n%s", output)
+		}
+	}
+}
+
+func TestPrivacyMode__disabled(t *testing.T) {
+	defer func(v bool) { privacyMode = v }(privacyMode)
+	privacyMode = false
+
+	if v := redact("maduro"); v != "maduro" {
+		t.Errorf("redact=%v", v)
+	}
+	w := httptest.NewRecorder()
+	setPrivacyHeaders(w)
+	if v := w.Header().Get("Cache-Control"); v != "" {
+		t.Errorf("Cache-Control=%q", v)
+	}
+}
+
+func TestPrivacyMode__getPrivacyMode(t *testing.T) {
+	logger := log.NewNopLogger()
+	if getPrivacyMode(logger, "") || getPrivacyMode(logger, "other") || getPrivacyMode(logger, "false") {
+		t.Error("expected privacy mode to be disabled")
+	}
+	if !getPrivacyMode(logger, "true") {
+		t.Error("expected privacy mode")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+const defaultProgramRiskTier = "unknown"
+
+// programRisks resolves the risk tier of SDN, SSI and ISN results as they're precomputed, nil
+// leaves the tier off of results. main sets it from PROGRAM_RISK_TIERS.
+var programRisks *programRiskTiers
+
+// programRiskTiers maps sanctions program codes to a risk tier
+type programRiskTiers struct {
+	tiers map[string]string // program code (uppercase) to tier
+
+	// rank orders tiers by their first appearance in the config, lower ranks win
+	rank map[string]int
+
+	// fallback is the tier of programs which aren't mapped
+	fallback string
+}
+
+// getProgramRiskTiers reads a comma separated list of PROGRAM=tier pairs (e.g. SDGT=high,CUBA=medium).
+// Programs which aren't listed get the fallback tier, or "unknown" when it's empty. Nil is
+// returned when env is empty or invalid.
+//
+// env and fallback are the values from environmental variables
+func getProgramRiskTiers(logger log.Logger, env, fallback string) *programRiskTiers {
+	if strings.TrimSpace(env) == "" {
+		return nil
+	}
+	risks, err := parseProgramRiskTiers(env, fallback)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid PROGRAM_RISK_TIERS=%q, risk tiers won't be included: %v", env, err))
+		return nil
+	}
+	logger.Log("main", fmt.Sprintf("Including risk tiers of %d programs on results", len(risks.tiers)))
+	return risks
+}
+
+func parseProgramRiskTiers(raw, fallback string) (*programRiskTiers, error) {
+	out := &programRiskTiers{
+		tiers:    make(map[string]string),
+		rank:     make(map[string]int),
+		fallback: strings.TrimSpace(fallback),
+	}
+	if out.fallback == "" {
+		out.fallback = defaultProgramRiskTier
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q isn't PROGRAM=tier", pair)
+		}
+		program, tier := strings.ToUpper(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		if program == "" || tier == "" {
+			return nil, fmt.Errorf("%q isn't PROGRAM=tier", pair)
+		}
+		if _, exists := out.tiers[program]; exists {
+			return nil, fmt.Errorf("%s is listed more than once", program)
+		}
+		out.tiers[program] = tier
+		if _, exists := out.rank[tier]; !exists {
+			out.rank[tier] = len(out.rank)
+		}
+	}
+	if len(out.tiers) == 0 {
+		return nil, fmt.Errorf("no programs")
+	}
+	return out, nil
+}
+
+// tier returns the riskiest tier of programs, which is the one listed first in the config.
+// Programs are matched by their code (e.g. SDGT) and codes with a suffix (VENEZUELA-EO13850)
+// fall back to their prefix (VENEZUELA). Empty is returned when r is nil.
+func (r *programRiskTiers) tier(programs []string) string {
+	if r == nil {
+		return ""
+	}
+	best := ""
+	for _, program := range programs {
+		tier, exists := r.lookup(program)
+		if !exists {
+			continue
+		}
+		if best == "" || r.rank[tier] < r.rank[best] {
+			best = tier
+		}
+	}
+	if best == "" {
+		return r.fallback
+	}
+	return best
+}
+
+func (r *programRiskTiers) lookup(program string) (string, bool) {
+	code := strings.ToUpper(strings.TrimSpace(program))
+	if tier, exists := r.tiers[code]; exists {
+		return tier, true
+	}
+	if idx := strings.Index(code, "-"); idx > 0 {
+		tier, exists := r.tiers[code[:idx]]
+		return tier, exists
+	}
+	return "", false
+}
+
+// programRisk is included on results with programs when PROGRAM_RISK_TIERS is set
+type programRisk struct {
+	RiskTier string `json:"riskTier,omitempty"`
+}
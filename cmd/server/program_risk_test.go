@@ -0,0 +1,113 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestProgramRisk__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	for _, env := range []string{"", " ", "SDGT", "SDGT=", "=high", "SDGT=high,sdgt=low", ","} {
+		if risks := getProgramRiskTiers(logger, env, ""); risks != nil {
+			t.Errorf("%q: got %#v", env, risks)
+		}
+	}
+
+	risks := getProgramRiskTiers(logger, "SDGT=high, cuba = medium,IRAN=high,", "low")
+	if risks == nil {
+		t.Fatal("expected risk tiers")
+	}
+	if len(risks.tiers) != 3 || risks.tiers["CUBA"] != "medium" || risks.fallback != "low" {
+		t.Errorf("unexpected risk tiers: %#v", risks)
+	}
+	if r := getProgramRiskTiers(logger, "SDGT=high", ""); r.fallback != defaultProgramRiskTier {
+		t.Errorf("fallback=%q", r.fallback)
+	}
+}
+
+func TestProgramRisk__tier(t *testing.T) {
+	risks, err := parseProgramRiskTiers("SDGT=high,IRAN=high,VENEZUELA=medium,CUBA=low", "standard")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		programs []string
+		expected string
+	}{
+		{[]string{"SDGT"}, "high"},
+		{[]string{"cuba"}, "low"},
+		// the riskiest mapped program wins, regardless of order
+		{[]string{"CUBA", "VENEZUELA"}, "medium"},
+		{[]string{"CUBA", "IRAN", "VENEZUELA"}, "high"},
+		// program codes with a suffix fall back to their prefix
+		{[]string{"VENEZUELA-EO13850"}, "medium"},
+		{[]string{"IRAN-EO13846", "CUBA"}, "high"},
+		// unmapped programs get the default tier
+		{[]string{"SDNTK"}, "standard"},
+		{[]string{"SDNTK", "CUBA"}, "low"},
+		{nil, "standard"},
+	}
+	for i := range cases {
+		if tier := risks.tier(cases[i].programs); tier != cases[i].expected {
+			t.Errorf("%v: got %q expected %q", cases[i].programs, tier, cases[i].expected)
+		}
+	}
+
+	// no tier without a mapping
+	var none *programRiskTiers
+	if tier := none.tier([]string{"SDGT"}); tier != "" {
+		t.Errorf("got %q", tier)
+	}
+}
+
+func TestProgramRisk__results(t *testing.T) {
+	defer func(r *programRiskTiers) { programRisks = r }(programRisks)
+
+	encode := func(v interface{}) string {
+		t.Helper()
+		bs, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(bs)
+	}
+	sdns := []*ofac.SDN{
+		{EntityID: "1", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual", Programs: []string{"VENEZUELA-EO13850"}},
+		{EntityID: "2", SDNName: "BANCO NACIONAL DE CUBA", Programs: []string{"CUBA"}},
+	}
+	ssis := []*csl.SSI{{EntityID: "3", Name: "PJSC ROSNEFT OIL COMPANY", Programs: []string{"UKRAINE-EO13662", "RUSSIA-EO14024"}}}
+
+	// results don't have a tier by default
+	programRisks = nil
+	for _, sdn := range precomputeSDNs(sdns, nil, noLogPipeliner) {
+		if out := encode(sdn); strings.Contains(out, "riskTier") {
+			t.Errorf("unexpected tier: %s", out)
+		}
+	}
+
+	programRisks, _ = parseProgramRiskTiers("RUSSIA=high,VENEZUELA=medium", "")
+	precomputed := precomputeSDNs(sdns, nil, noLogPipeliner)
+	if out := encode(precomputed[0]); !strings.Contains(out, `"riskTier":"medium"`) {
+		t.Errorf("expected medium tier: %s", out)
+	}
+	if out := encode(precomputed[1]); !strings.Contains(out, `"riskTier":"unknown"`) {
+		t.Errorf("expected default tier: %s", out)
+	}
+	if out := encode(precomputeSSIs(ssis, noLogPipeliner)[0]); !strings.Contains(out, `"riskTier":"high"`) {
+		t.Errorf("expected high tier: %s", out)
+	}
+	isns := precomputeISNs([]*csl.ISN{{EntityID: "4", Name: "ABU AHMAD", Programs: []string{"NS-ISA"}}}, noLogPipeliner)
+	if out := encode(isns[0]); !strings.Contains(out, `"riskTier":"unknown"`) {
+		t.Errorf("expected default tier: %s", out)
+	}
+}
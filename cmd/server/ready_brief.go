@@ -0,0 +1,84 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/moov-io/base/admin"
+)
+
+const (
+	readyBriefPath = "/ready/brief"
+
+	// indexGenerationHeader counts how many times records were swapped into the index, it's
+	// zero before the initial download
+	indexGenerationHeader = "X-Watchman-Index-Generation"
+
+	// indexAgeHeader is how many seconds ago the current generation was built
+	indexAgeHeader = "X-Watchman-Index-Age"
+)
+
+// readinessChecks are the readiness checks of the admin server, which GET /ready runs but
+// doesn't expose, so GET /ready/brief can run them as well
+type readinessChecks struct {
+	checks []func() error
+}
+
+// add registers check with svc and keeps it for GET /ready/brief
+func (c *readinessChecks) add(svc *admin.Server, name string, check func() error) {
+	svc.AddReadinessCheck(name, check)
+	c.checks = append(c.checks, check)
+}
+
+// check returns the error of the first failing check
+func (c *readinessChecks) check() error {
+	for _, check := range c.checks {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextGeneration records that records were swapped into the index, the caller must hold the
+// write lock
+func (s *searcher) nextGeneration() {
+	s.generation++
+	s.generatedAt = time.Now()
+}
+
+// indexGeneration returns the generation of the index and when it was built
+func (s *searcher) indexGeneration() (int64, time.Time) {
+	s.RLock()
+	defer s.RUnlock()
+	return s.generation, s.generatedAt
+}
+
+// readyBriefHandler responds to GET or HEAD with 200 when the index is built and every
+// readiness check passes, or 503 otherwise, without a body. The generation and age of the
+// index are in headers, which is cheaper for orchestration to poll than GET /ready.
+func readyBriefHandler(searcher *searcher, checks *readinessChecks) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "HEAD" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		generation, generatedAt := searcher.indexGeneration()
+		w.Header().Set(indexGenerationHeader, strconv.FormatInt(generation, 10))
+		if !generatedAt.IsZero() {
+			w.Header().Set(indexAgeHeader, strconv.FormatInt(int64(time.Since(generatedAt).Seconds()), 10))
+		}
+		w.Header().Set("Cache-Control", "no-cache")
+
+		if generation == 0 || checks.check() != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
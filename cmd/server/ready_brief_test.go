@@ -0,0 +1,86 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestReadyBrief(t *testing.T) {
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	var checkErr error
+	checks := &readinessChecks{checks: []func() error{
+		func() error { return checkErr },
+	}}
+	handler := readyBriefHandler(s, checks)
+
+	ready := func(method string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(method, readyBriefPath, nil))
+		if w.Body.Len() != 0 {
+			t.Errorf("unexpected body: %q", w.Body.String())
+		}
+		return w
+	}
+
+	// the index isn't built yet
+	for _, method := range []string{"GET", "HEAD"} {
+		w := ready(method)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: bogus HTTP status: %d", method, w.Code)
+		}
+		if v := w.Header().Get(indexGenerationHeader); v != "0" {
+			t.Errorf("%s: generation=%q", method, v)
+		}
+		if v := w.Header().Get(indexAgeHeader); v != "" {
+			t.Errorf("%s: age=%q", method, v)
+		}
+	}
+
+	if _, err := s.refreshData(filepath.Join("..", "..", "test", "testdata")); err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{"GET", "HEAD"} {
+		w := ready(method)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: bogus HTTP status: %d", method, w.Code)
+		}
+		if v := w.Header().Get(indexGenerationHeader); v != "1" {
+			t.Errorf("%s: generation=%q", method, v)
+		}
+		if v := w.Header().Get(indexAgeHeader); v != "0" {
+			t.Errorf("%s: age=%q", method, v)
+		}
+	}
+
+	// a failing readiness check
+	checkErr = errors.New("stale")
+	if w := ready("HEAD"); w.Code != http.StatusServiceUnavailable || w.Header().Get(indexGenerationHeader) != "1" {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+	checkErr = nil
+
+	// reindexing bumps the generation
+	if _, err := s.reindexSource(filepath.Join("..", "..", "test", "testdata"), sourceCA); err != nil {
+		t.Fatal(err)
+	}
+	if v := ready("GET").Header().Get(indexGenerationHeader); v != "2" {
+		t.Errorf("generation=%q", v)
+	}
+
+	if w := ready("POST"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("bogus HTTP status: %d", w.Code)
+	}
+}
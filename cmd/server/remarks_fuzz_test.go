@@ -0,0 +1,93 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Remark parsers run over every record during precompute, so a panic on one malformed
+// remark stops Watchman from starting. Run these with 'go test -fuzz FuzzX ./cmd/server/'
+// and commit inputs which found a bug under testdata/fuzz/.
+
+var remarkSeeds = []string{
+	"",
+	"No.",
+	"Passport No.",
+	"Cedula No. 10517860 (Venezuela);",
+	"Trade License No. C 37422 (Malta).",
+	"Tax ID No. AABA 670850 Y.",
+	"DOB 23 Nov 1962; POB Caracas, Venezuela; citizen Venezuela; Gender Male; Cedula No. 5892464 (Venezuela).",
+	"DOB 10 Dec 1948; alt. DOB 1950; POB Egypt",
+	"DOB circa 1960",
+	"DOB 31 Feb 1962",
+}
+
+func FuzzExtractIDFromRemark(f *testing.F) {
+	for _, seed := range remarkSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, remarks string) {
+		id := extractIDFromRemark(remarks)
+		if utf8.ValidString(remarks) && id != "" && !strings.Contains(remarks, strings.Fields(id)[0]) {
+			t.Errorf("id %q isn't from remarks %q", id, remarks)
+		}
+	})
+}
+
+func FuzzRemarkDOBs(f *testing.F) {
+	for _, seed := range remarkSeeds {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, remarks string) {
+		for _, dob := range remarkDOBs(remarks) {
+			if len(dob) != len("2006") && len(dob) != len("2006-01-02") {
+				t.Errorf("unexpected DOB %q from %q", dob, remarks)
+			}
+		}
+	})
+}
+
+func FuzzNormalizeDOB(f *testing.F) {
+	for _, seed := range []string{"1962-11-23", "23 Nov 1962", "3 Jul 1956", "2008", "", "0000-00-00", "99 Abc 12345"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		if dob := normalizeDOB(raw); dob != "" && len(dob) != len("2006") && len(dob) != len("2006-01-02") {
+			t.Errorf("unexpected DOB %q from %q", dob, raw)
+		}
+	})
+}
+
+func FuzzRemarkScriptNames(f *testing.F) {
+	for _, seed := range append(remarkSeeds, "Arabic name: صدام حسين;", "(金正恩) Ким Чен Ын", "ሰላም.ሰላም") {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, remarks string) {
+		for _, name := range remarkScriptNames(remarks) {
+			if name.name == "" || name.script == "" || nameScript(name.name) != name.script {
+				t.Errorf("unexpected name %#v from %q", name, remarks)
+			}
+		}
+	})
+}
+
+func FuzzRemarkNationalities(f *testing.F) {
+	for _, seed := range append(remarkSeeds, "nationality Korea, North; citizen Russia.", "Nationality ;citizen .;", "citizenship Iran") {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, remarks string) {
+		for _, nationality := range remarkNationalities(remarks) {
+			if nationality == "" || strings.Contains(nationality, ";") || !strings.Contains(remarks, nationality) {
+				t.Errorf("unexpected nationality %q from %q", nationality, remarks)
+			}
+		}
+	})
+}
@@ -0,0 +1,159 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+// calibratedModes are the name scoring modes which can be calibrated, strict searches only
+// score 0 or 1 so they never are
+var calibratedModes = []string{nameScoringTokens, nameScoringFull, matchModeInitials, matchModeWildcard}
+
+// scoreCalibrations map the raw scores of each name scoring mode onto a shared scale, so a
+// minMatch means about the same confidence whichever mode a search uses. Modes which aren't
+// listed keep their scores. main sets it from SCORE_CALIBRATION.
+var scoreCalibrations map[string]calibrationCurve
+
+// calibrationPoint says a raw score of a mode is as confident as calibrated
+type calibrationPoint struct {
+	raw, calibrated float64
+}
+
+// calibrationCurve is a piecewise linear mapping of raw scores through its points, which are
+// in increasing order. It always maps 0 to 0 and 1 to 1, so exact matches still score 1.
+type calibrationCurve []calibrationPoint
+
+// getScoreCalibrations reads a comma separated list of MODE=raw:calibrated pairs, where
+// several points of a mode are separated by semicolons (e.g. full=0.8:0.9;0.9:0.95,initials=0.95:0.9).
+// Nil is returned when env is empty or invalid.
+//
+// env is the value from an environmental variable
+func getScoreCalibrations(logger log.Logger, env string) map[string]calibrationCurve {
+	if strings.TrimSpace(env) == "" {
+		return nil
+	}
+	curves, err := parseScoreCalibrations(env)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid SCORE_CALIBRATION=%q, scores won't be calibrated: %v", env, err))
+		return nil
+	}
+	logger.Log("main", fmt.Sprintf("Calibrating scores of %d name scoring modes", len(curves)))
+	return curves
+}
+
+func parseScoreCalibrations(raw string) (map[string]calibrationCurve, error) {
+	out := make(map[string]calibrationCurve)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q isn't MODE=raw:calibrated", pair)
+		}
+		mode := strings.ToLower(strings.TrimSpace(parts[0]))
+		if !isCalibratedMode(mode) {
+			return nil, fmt.Errorf("unknown mode %q, expected one of %s", parts[0], strings.Join(calibratedModes, ", "))
+		}
+		if _, exists := out[mode]; exists {
+			return nil, fmt.Errorf("%s is listed more than once", mode)
+		}
+		curve, err := parseCalibrationCurve(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", mode, err)
+		}
+		out[mode] = curve
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no modes")
+	}
+	return out, nil
+}
+
+func parseCalibrationCurve(raw string) (calibrationCurve, error) {
+	var out calibrationCurve
+	last := calibrationPoint{}
+	for _, point := range strings.Split(raw, ";") {
+		parts := strings.SplitN(point, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q isn't raw:calibrated", point)
+		}
+		var p calibrationPoint
+		var err error
+		if p.raw, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err != nil || p.raw <= 0 || p.raw >= 1 {
+			return nil, fmt.Errorf("invalid raw score %q, must be between 0 and 1", parts[0])
+		}
+		if p.calibrated, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err != nil || p.calibrated <= 0 || p.calibrated >= 1 {
+			return nil, fmt.Errorf("invalid calibrated score %q, must be between 0 and 1", parts[1])
+		}
+		if p.raw <= last.raw || p.calibrated <= last.calibrated {
+			return nil, fmt.Errorf("%q must score higher than the point before it", point)
+		}
+		out = append(out, p)
+		last = p
+	}
+	return out, nil
+}
+
+func isCalibratedMode(mode string) bool {
+	for i := range calibratedModes {
+		if calibratedModes[i] == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// apply maps a raw score onto the calibrated scale
+func (c calibrationCurve) apply(score float64) float64 {
+	return c.interpolate(score, func(p calibrationPoint) (float64, float64) { return p.raw, p.calibrated })
+}
+
+// raw is the inverse of apply, it's the raw score which calibrates to score
+func (c calibrationCurve) raw(score float64) float64 {
+	return c.interpolate(score, func(p calibrationPoint) (float64, float64) { return p.calibrated, p.raw })
+}
+
+func (c calibrationCurve) interpolate(score float64, xy func(calibrationPoint) (float64, float64)) float64 {
+	if score <= 0 || score >= 1 {
+		return score
+	}
+	x0, y0 := 0.0, 0.0
+	for i := range c {
+		x1, y1 := xy(c[i])
+		if score <= x1 {
+			return y0 + (score-x0)*(y1-y0)/(x1-x0)
+		}
+		x0, y0 = x1, y1
+	}
+	return y0 + (score-x0)*(1-y0)/(1-x0)
+}
+
+// calibrationMode is the name scoring mode of fuzzy searches, whose curve in scoreCalibrations
+// calibrates their scores
+func (opts searchOptions) calibrationMode() string {
+	if opts.matchMode != "" {
+		return opts.matchMode
+	}
+	if opts.nameScoring == nameScoringFull {
+		return nameScoringFull
+	}
+	return nameScoringTokens
+}
+
+// calibration returns the curve of the search's mode, ok is false for strict searches and
+// modes without one
+func (opts searchOptions) calibration() (calibrationCurve, bool) {
+	if opts.strict {
+		return nil, false
+	}
+	curve, ok := scoreCalibrations[opts.calibrationMode()]
+	return curve, ok
+}
@@ -0,0 +1,130 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestScoreCalibration__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	for _, env := range []string{"", " ", "full", "full=", "full=0.8", "strict=0.8:0.9", "other=0.8:0.9", "full=0:0.5", "full=0.8:1",
+		"full=0.9:0.95;0.8:0.9", "full=0.8:0.9;0.9:0.85", "full=high:0.9", "full=0.8:0.9,FULL=0.7:0.8", ","} {
+		if curves := getScoreCalibrations(logger, env); curves != nil {
+			t.Errorf("%q: got %#v", env, curves)
+		}
+	}
+
+	curves := getScoreCalibrations(logger, "FULL = 0.8:0.9; 0.9:0.95,initials=0.95:0.9,")
+	if len(curves) != 2 || len(curves[nameScoringFull]) != 2 || len(curves[matchModeInitials]) != 1 {
+		t.Fatalf("unexpected curves: %#v", curves)
+	}
+	if p := curves[nameScoringFull][1]; p.raw != 0.9 || p.calibrated != 0.95 {
+		t.Errorf("unexpected point: %#v", p)
+	}
+}
+
+func TestScoreCalibration__curve(t *testing.T) {
+	curve := calibrationCurve{{raw: 0.8, calibrated: 0.9}, {raw: 0.9, calibrated: 0.95}}
+
+	cases := []struct {
+		raw, calibrated float64
+	}{
+		{0.0, 0.0},
+		{0.4, 0.45},
+		{0.8, 0.9},
+		{0.85, 0.925},
+		{0.9, 0.95},
+		{0.95, 0.975},
+		{1.0, 1.0},
+	}
+	for _, tc := range cases {
+		eql(t, "apply", curve.apply(tc.raw), tc.calibrated)
+		eql(t, "raw", curve.raw(tc.calibrated), tc.raw)
+	}
+}
+
+func TestScoreCalibration__thresholds(t *testing.T) {
+	defer func(c map[string]calibrationCurve) { scoreCalibrations = c }(scoreCalibrations)
+
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	modes := []searchOptions{
+		{nameScoring: nameScoringTokens},
+		{nameScoring: nameScoringFull},
+		{matchMode: matchModeInitials},
+	}
+	find := func(query string, opts searchOptions) bool {
+		opts.minMatch = 0.9
+		return len(s.TopSDNs(1, query, opts)) == 1
+	}
+
+	// an abbreviated given name is as close a match whichever mode scores it, but full names
+	// penalize the shortened token and initials reward it
+	const abbreviated, unrelated = "N Maduro Moros", "John Smith"
+	got := make([]bool, len(modes))
+	for i := range modes {
+		got[i] = find(abbreviated, modes[i])
+	}
+	if !got[0] || got[1] || !got[2] {
+		t.Fatalf("uncalibrated: %v", got)
+	}
+
+	// calibrating full names and initials onto the scale of tokens puts the abbreviation over
+	// the same minMatch in every mode, while an unrelated name stays below it
+	scoreCalibrations = getScoreCalibrations(log.NewNopLogger(), "full=0.78:0.91,initials=0.96:0.91")
+	for i := range modes {
+		if !find(abbreviated, modes[i]) {
+			t.Errorf("%#v: %s wasn't found", modes[i], abbreviated)
+		}
+		if find(unrelated, modes[i]) {
+			t.Errorf("%#v: %s was found", modes[i], unrelated)
+		}
+	}
+
+	// strict searches are never calibrated
+	if opts := (searchOptions{strict: true}); opts.score("n maduro moros", "nicolas maduro moros") != 0.0 {
+		t.Error("strict search was calibrated")
+	}
+}
+
+func TestScoreCalibration__scoreFloor(t *testing.T) {
+	defer func(c map[string]calibrationCurve) { scoreCalibrations = c }(scoreCalibrations)
+
+	// names whose calibrated score reaches the floor aren't skipped by their raw score
+	opts := searchOptions{nameScoring: nameScoringFull, scoreFloor: 0.95}
+	if score := opts.score("nicolas maduro moros", "n maduro moros"); score != 0.0 {
+		t.Fatalf("uncalibrated score=%v", score)
+	}
+	scoreCalibrations = map[string]calibrationCurve{nameScoringFull: {{raw: 0.78, calibrated: 0.96}}}
+	if score := opts.score("nicolas maduro moros", "n maduro moros"); score < 0.95 {
+		t.Errorf("calibrated score=%v", score)
+	}
+}
+
+func TestScoreCalibration__explain(t *testing.T) {
+	defer func(c map[string]calibrationCurve) { scoreCalibrations = c }(scoreCalibrations)
+
+	sdn := idSearcher.SDNs[0] // 22790 "MADURO MOROS, Nicolas"
+	opts := searchOptions{nameScoring: nameScoringFull}
+	if explained := explainSDN(sdn, "N Maduro Moros", opts); explained.CalibratedScore != 0.0 {
+		t.Errorf("calibratedScore=%v", explained.CalibratedScore)
+	}
+
+	scoreCalibrations = map[string]calibrationCurve{nameScoringFull: {{raw: 0.78, calibrated: 0.91}}}
+	explained := explainSDN(sdn, "N Maduro Moros", opts)
+	eql(t, "calibrated", explained.CalibratedScore, scoreCalibrations[nameScoringFull].apply(explained.NameScore))
+	if explained.CalibratedScore != explained.Match {
+		t.Errorf("calibratedScore=%v match=%v", explained.CalibratedScore, explained.Match)
+	}
+}
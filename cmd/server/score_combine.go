@@ -0,0 +1,103 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Strategies for ?combine= which score an SDN from its name and address matches when a search
+// includes both
+const (
+	combineMax      = "max"
+	combineAvg      = "avg"
+	combineWeighted = "weighted"
+)
+
+// scoreCombination is how name+address searches compute an SDN's match. The default is a
+// weighted sum with only the name counted, which keeps the name's score like before ?combine=
+type scoreCombination struct {
+	strategy      string
+	nameWeight    float64
+	addressWeight float64
+}
+
+var defaultScoreCombination = scoreCombination{
+	strategy:      combineWeighted,
+	nameWeight:    1.0,
+	addressWeight: 0.0,
+}
+
+// readScoreCombination parses ?combine=, ?nameWeight= and ?addressWeight=
+func readScoreCombination(u *url.URL) (scoreCombination, error) {
+	out := defaultScoreCombination
+
+	strategy, err := readEnumParam(u, "combine")
+	if err != nil {
+		return out, err
+	}
+	if strategy != "" {
+		out.strategy = strategy
+	}
+
+	for param, weight := range map[string]*float64{"nameWeight": &out.nameWeight, "addressWeight": &out.addressWeight} {
+		if v := strings.TrimSpace(u.Query().Get(param)); v != "" {
+			w, err := strconv.ParseFloat(v, 64)
+			if err != nil || w < 0 || w > 1 {
+				return out, fmt.Errorf("invalid %s %q, must be between 0 and 1", param, v)
+			}
+			*weight = w
+		}
+	}
+	if out.nameWeight+out.addressWeight == 0 {
+		return out, fmt.Errorf("nameWeight and addressWeight can't both be zero")
+	}
+	return out, nil
+}
+
+// combine returns the match of an SDN whose name scored name and address scored address
+func (c scoreCombination) combine(name, address float64) float64 {
+	switch c.strategy {
+	case combineMax:
+		if address > name {
+			return address
+		}
+		return name
+	case combineAvg:
+		return (name + address) / 2
+	default:
+		if c.nameWeight+c.addressWeight == 0 {
+			return name
+		}
+		// the weights are normalized so the match stays between 0 and 1
+		return (c.nameWeight*name + c.addressWeight*address) / (c.nameWeight + c.addressWeight)
+	}
+}
+
+// combineAddressMatches sets the match of each SDN result to its combination with the address
+// result at the same index, and re-ranks the pairs by it
+func (resp *searchResponse) combineAddressMatches(c scoreCombination) {
+	if len(resp.SDNs) != len(resp.Addresses) {
+		return
+	}
+	for i := range resp.SDNs {
+		resp.SDNs[i].match = c.combine(resp.SDNs[i].match, resp.Addresses[i].match)
+	}
+	order := make([]int, len(resp.SDNs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return resp.SDNs[order[i]].match > resp.SDNs[order[j]].match })
+
+	sdns, addresses := make([]SDN, len(order)), make([]Address, len(order))
+	for i, idx := range order {
+		sdns[i], addresses[i] = resp.SDNs[idx], resp.Addresses[idx]
+	}
+	resp.SDNs, resp.Addresses = sdns, addresses
+}
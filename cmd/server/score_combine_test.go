@@ -0,0 +1,128 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestScoreCombination__combine(t *testing.T) {
+	name, address := 0.8, 0.4
+	cases := []struct {
+		query    string
+		expected float64
+	}{
+		{"", 0.8}, // only the name is counted by default
+		{"combine=max", 0.8},
+		{"combine=avg", 0.6},
+		{"combine=weighted", 0.8},
+		{"combine=weighted&addressWeight=1", 0.6},
+		{"combine=weighted&nameWeight=0.75&addressWeight=0.25", 0.7},
+		{"combine=WEIGHTED&nameWeight=0&addressWeight=0.5", 0.4},
+	}
+	for _, tc := range cases {
+		u, _ := url.Parse("/search?" + tc.query)
+		c, err := readScoreCombination(u)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.query, err)
+		}
+		eql(t, tc.query, c.combine(name, address), tc.expected)
+	}
+
+	// max picks whichever score is higher
+	eql(t, "max", scoreCombination{strategy: combineMax}.combine(0.3, 0.9), 0.9)
+}
+
+func TestScoreCombination__invalid(t *testing.T) {
+	for _, query := range []string{"combine=sum", "nameWeight=2", "addressWeight=-0.1", "nameWeight=x", "nameWeight=0&addressWeight=0"} {
+		u, _ := url.Parse("/search?" + query)
+		if _, err := readScoreCombination(u); err == nil {
+			t.Errorf("%s: expected error", query)
+		}
+		if _, err := readSearchOptions(u); err == nil {
+			t.Errorf("%s: expected error from readSearchOptions", query)
+		}
+	}
+}
+
+func TestScoreCombination__search(t *testing.T) {
+	addrs := []*ofac.Address{
+		{EntityID: "22790", AddressID: "1", Address: "Palacio de Miraflores", CityStateProvincePostalCode: "Caracas", Country: "Venezuela"},
+		{EntityID: "173", AddressID: "129", Address: "Miraflores Palace", CityStateProvincePostalCode: "Caracas", Country: "Venezuela"},
+	}
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"},
+			{EntityID: "173", SDNName: "MADURO TRADING CO"},
+		}, addrs, noLogPipeliner),
+		Addresses: precomputeAddresses(addrs),
+		pipe:      noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	type result struct {
+		EntityID string  `json:"entityID"`
+		Match    float64 `json:"match"`
+	}
+	search := func(t *testing.T, query string) ([]result, []result) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro&address=palacio+de+miraflores&"+query, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			SDNs      []result `json:"SDNs"`
+			Addresses []result `json:"addresses"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.SDNs) != 2 || len(resp.SDNs) != len(resp.Addresses) {
+			t.Fatalf("unexpected results: %#v", resp)
+		}
+		return resp.SDNs, resp.Addresses
+	}
+
+	// SDNs keep their name's score by default
+	names, addresses := search(t, "")
+	if names[0].EntityID != "22790" {
+		t.Errorf("unexpected ranking: %#v", names)
+	}
+	nameScores := map[string]float64{names[0].EntityID: names[0].Match, names[1].EntityID: names[1].Match}
+	addressScores := map[string]float64{addresses[0].EntityID: addresses[0].Match, addresses[1].EntityID: addresses[1].Match}
+
+	for _, tc := range []struct {
+		query string
+		c     scoreCombination
+	}{
+		{"combine=max", scoreCombination{strategy: combineMax}},
+		{"combine=avg", scoreCombination{strategy: combineAvg}},
+		{"combine=weighted&nameWeight=0.2&addressWeight=0.8", scoreCombination{strategy: combineWeighted, nameWeight: 0.2, addressWeight: 0.8}},
+	} {
+		sdns, addrs := search(t, tc.query)
+		for i := range sdns {
+			if sdns[i].EntityID != addrs[i].EntityID {
+				t.Errorf("%s: SDN %s paired with address of %s", tc.query, sdns[i].EntityID, addrs[i].EntityID)
+			}
+			id := sdns[i].EntityID
+			eql(t, tc.query+" "+id, sdns[i].Match, tc.c.combine(nameScores[id], addressScores[id]))
+		}
+		if sdns[0].Match < sdns[1].Match {
+			t.Errorf("%s: results aren't ranked by their combined match: %#v", tc.query, sdns)
+		}
+	}
+}
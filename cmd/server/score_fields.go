@@ -0,0 +1,146 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+// scorer compares an indexed value of a field against a query of it, both normalized, and
+// returns their similarity between 0 and 1
+type scorer interface {
+	score(indexed, query string) float64
+}
+
+// Similarity functions which FIELD_SCORERS assigns to fields
+const (
+	scorerJaroWinkler = "jaroWinkler"
+	scorerTokenSet    = "tokenSet"
+	scorerExact       = "exact"
+)
+
+var scorers = map[string]scorer{
+	strings.ToLower(scorerJaroWinkler): jaroWinklerScorer{},
+	strings.ToLower(scorerTokenSet):    tokenSetScorer{},
+	strings.ToLower(scorerExact):       exactScorer{},
+}
+
+// jaroWinklerScorer is jaroWinkler, which names and addresses are scored with by default.
+// Names scored with it keep every other option of a search (nameScoring, numbers, concatenated
+// names and the score floor).
+type jaroWinklerScorer struct{}
+
+func (jaroWinklerScorer) score(indexed, query string) float64 {
+	return jaroWinkler(indexed, query)
+}
+
+// tokenSetScorer is the share of distinct tokens in both values (their Dice coefficient), so
+// the order and repeats of tokens don't matter but each one has to match exactly
+type tokenSetScorer struct{}
+
+func (tokenSetScorer) score(indexed, query string) float64 {
+	a, b := tokenSet(indexed), tokenSet(query)
+	if len(a) == 0 || len(b) == 0 {
+		return 0.0
+	}
+	var shared int
+	for token := range a {
+		if b[token] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+func tokenSet(s string) map[string]bool {
+	out := make(map[string]bool)
+	for _, token := range strings.Fields(s) {
+		out[token] = true
+	}
+	return out
+}
+
+// exactScorer is exactMatch, which IDs are matched with by default
+type exactScorer struct{}
+
+func (exactScorer) score(indexed, query string) float64 {
+	return exactMatch(indexed, query)
+}
+
+// scorerName returns what FIELD_SCORERS calls s, it's empty for other scorers
+func scorerName(s scorer) string {
+	switch s.(type) {
+	case jaroWinklerScorer:
+		return scorerJaroWinkler
+	case tokenSetScorer:
+		return scorerTokenSet
+	case exactScorer:
+		return scorerExact
+	}
+	return ""
+}
+
+// fieldScorers are the similarity functions of each field type
+type fieldScorers struct {
+	name    scorer
+	address scorer
+	id      scorer
+}
+
+var (
+	defaultFieldScorers = fieldScorers{
+		name:    jaroWinklerScorer{},
+		address: jaroWinklerScorer{},
+		id:      exactScorer{},
+	}
+
+	// searchScorers score each field of searches, main sets them from FIELD_SCORERS
+	searchScorers = defaultFieldScorers
+)
+
+// getFieldScorers reads similarity functions formatted as "address=tokenSet,id=exact".
+// Fields not listed keep their default scorer.
+//
+// env is the value from an environmental variable
+func getFieldScorers(logger log.Logger, env string) fieldScorers {
+	if strings.TrimSpace(env) == "" {
+		return defaultFieldScorers
+	}
+	out, err := readFieldScorers(env)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid FIELD_SCORERS=%q, using defaults: %v", env, err))
+		return defaultFieldScorers
+	}
+	logger.Log("main", fmt.Sprintf("Setting field scorers to %q", env))
+	return out
+}
+
+func readFieldScorers(str string) (fieldScorers, error) {
+	out := defaultFieldScorers
+	for _, part := range strings.Split(str, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return out, fmt.Errorf("expected field=scorer, got %q", part)
+		}
+		s, exists := scorers[strings.ToLower(strings.TrimSpace(kv[1]))]
+		if !exists {
+			return out, fmt.Errorf("unknown scorer %q for %s, expected %s, %s or %s", kv[1], kv[0], scorerJaroWinkler, scorerTokenSet, scorerExact)
+		}
+		switch strings.ToLower(strings.TrimSpace(kv[0])) {
+		case "name":
+			out.name = s
+		case "address":
+			out.address = s
+		case "id":
+			out.id = s
+		default:
+			return out, fmt.Errorf("unknown field %q", kv[0])
+		}
+	}
+	return out, nil
+}
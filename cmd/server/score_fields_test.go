@@ -0,0 +1,124 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestFieldScorers__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	if s := getFieldScorers(logger, ""); s != defaultFieldScorers {
+		t.Errorf("got %#v", s)
+	}
+
+	s := getFieldScorers(logger, "address=tokenSet, id=JAROWINKLER")
+	if _, ok := s.name.(jaroWinklerScorer); !ok {
+		t.Errorf("name=%T", s.name)
+	}
+	if _, ok := s.address.(tokenSetScorer); !ok {
+		t.Errorf("address=%T", s.address)
+	}
+	if _, ok := s.id.(jaroWinklerScorer); !ok {
+		t.Errorf("id=%T", s.id)
+	}
+
+	for _, env := range []string{"address", "address=levenshtein", "phone=exact", "name=exact,,"} {
+		if _, err := readFieldScorers(env); err == nil {
+			t.Errorf("%q: expected error", env)
+		}
+		if s := getFieldScorers(logger, env); s != defaultFieldScorers {
+			t.Errorf("%q: got %#v", env, s)
+		}
+	}
+}
+
+func TestFieldScorers__tokenSet(t *testing.T) {
+	cases := []struct {
+		indexed, query string
+		expected       float64
+	}{
+		{"ibex house the minories", "the minories ibex house", 1.0},
+		{"ibex house the minories", "ibex house", 2 * 2.0 / 6},
+		{"piarco airport", "port au prince", 0.0},
+		{"main main st", "main st", 1.0},
+		{"", "main st", 0.0},
+	}
+	for _, tc := range cases {
+		if score := (tokenSetScorer{}).score(tc.indexed, tc.query); math.Abs(score-tc.expected) > 0.001 {
+			t.Errorf("%q vs %q: got %.3f, expected %.3f", tc.indexed, tc.query, score, tc.expected)
+		}
+	}
+}
+
+// recordingScorer returns weight for every comparison and records the queries it scored
+type recordingScorer struct {
+	weight float64
+
+	mu      sync.Mutex
+	queries map[string]bool
+}
+
+func (s *recordingScorer) score(indexed, query string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries[query] = true
+	return s.weight
+}
+
+func TestFieldScorers__fields(t *testing.T) {
+	defer func() { searchScorers = defaultFieldScorers }()
+
+	name := &recordingScorer{weight: 0.51, queries: make(map[string]bool)}
+	address := &recordingScorer{weight: 0.52, queries: make(map[string]bool)}
+	id := &recordingScorer{weight: 0.53, queries: make(map[string]bool)}
+	searchScorers = fieldScorers{name: name, address: address, id: id}
+
+	sdns := idSearcher.TopSDNs(1, "Nicolas Maduro", searchOptions{})
+	if len(sdns) != 1 || math.Abs(sdns[0].match-0.51) > 0.001 {
+		t.Errorf("SDNs=%#v", sdns)
+	}
+	addrs := addressSearcher.TopAddresses(1, "Ibex House", searchOptions{})
+	if len(addrs) != 1 || math.Abs(addrs[0].match-0.52) > 0.001 {
+		t.Errorf("addresses=%#v", addrs)
+	}
+	sdns = idSearcher.FindSDNsByRemarksID(1, "5892464")
+	if len(sdns) != 1 || math.Abs(sdns[0].match-0.53) > 0.001 {
+		t.Errorf("IDs=%#v", sdns)
+	}
+
+	// each field was only scored by its own scorer
+	if len(name.queries) != 1 || !name.queries["nicolas maduro"] {
+		t.Errorf("name scored %v", name.queries)
+	}
+	if len(address.queries) != 1 || !address.queries["ibex house"] {
+		t.Errorf("address scored %v", address.queries)
+	}
+	if len(id.queries) != 1 || !id.queries["5892464"] {
+		t.Errorf("id scored %v", id.queries)
+	}
+}
+
+func TestFieldScorers__defaults(t *testing.T) {
+	// the default scorers score like before FIELD_SCORERS
+	sdns := idSearcher.TopSDNs(1, "Nicolas Maduro", searchOptions{})
+	if len(sdns) != 1 || math.Abs(sdns[0].match-jaroWinkler(sdns[0].name, "nicolas maduro")) > 0.001 {
+		t.Errorf("SDNs=%#v", sdns)
+	}
+	addrs := addressSearcher.TopAddresses(1, "Ibex House", searchOptions{})
+	if len(addrs) != 1 || math.Abs(addrs[0].match-jaroWinkler("ibex house the minories", "ibex house")) > 0.001 {
+		t.Errorf("addresses=%#v", addrs)
+	}
+	if sdns := idSearcher.FindSDNsByRemarksID(1, "5892464"); len(sdns) != 1 || sdns[0].match != 1.0 {
+		t.Errorf("IDs=%#v", sdns)
+	}
+	if sdns := idSearcher.FindSDNsByRemarksID(1, "5892465"); len(sdns) != 0 {
+		t.Errorf("IDs=%#v", sdns)
+	}
+}
@@ -0,0 +1,434 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// screenCandidates is how many SDNs with the closest names are screened by every field
+	screenCandidates = 10
+
+	screenFieldName        = "name"
+	screenFieldDateOfBirth = "dateOfBirth"
+	screenFieldNationality = "nationality"
+	screenFieldID          = "id"
+	screenFieldAddress     = "address"
+)
+
+var (
+	// screenWeights are the relative weights of each field of a party when blending their scores
+	// into one match. Identification numbers and the name identify someone best, while many
+	// people share a date of birth, nationality or city.
+	screenWeights = map[string]float64{
+		screenFieldName:        3.0,
+		screenFieldID:          3.0,
+		screenFieldDateOfBirth: 2.0,
+		screenFieldAddress:     1.0,
+		screenFieldNationality: 1.0,
+	}
+
+	errNoScreenName = errors.New("no name to screen")
+)
+
+// screenRequest is a structured party, every field but the name is optional
+type screenRequest struct {
+	Name        string               `json:"name"`
+	DateOfBirth string               `json:"dateOfBirth"`
+	Nationality string               `json:"nationality"`
+	ID          string               `json:"id"`
+	Address     addressSearchRequest `json:"address"`
+}
+
+// screenField is the score of one field of a party against an SDN
+type screenField struct {
+	Score float64 `json:"score"`
+
+	// Weight is the field's share of the match, the match is the sum of each score times its weight
+	Weight float64 `json:"weight"`
+
+	// Indexed is the SDN's value which scored best
+	Indexed string `json:"indexed,omitempty"`
+}
+
+// screenMatch is the record which matches a party best, with how each field of the party scored
+type screenMatch struct {
+	Source   string                 `json:"source"`
+	EntityID string                 `json:"entityID"`
+	Name     string                 `json:"name"`
+	Match    float64                `json:"match"`
+	Fields   map[string]screenField `json:"fields"`
+
+	// NotCompared are fields of the party which the SDN doesn't have (e.g. no date of birth is
+	// listed), they don't count towards the match
+	NotCompared []string `json:"notCompared,omitempty"`
+
+	// Listings are the same party on other lists, grouped like dedupeEntities groups results
+	Listings []entityEntry `json:"listings,omitempty"`
+
+	// SDN is set when the match is an SDN, Result is the record of other lists
+	SDN    *SDN        `json:"sdn,omitempty"`
+	Result interface{} `json:"result,omitempty"`
+
+	party *party
+}
+
+type screenResponse struct {
+	// Match is null when no record has a similar name or the same ID
+	Match *screenMatch `json:"match"`
+
+	// Decision of the match (clear, review or block), see SEARCH_DECISION_THRESHOLDS
+	Decision    string    `json:"decision"`
+	RefreshedAt time.Time `json:"refreshedAt"`
+}
+
+func addScreenRoutes(logger log.Logger, r *mux.Router, searcher *searcher) {
+	maxAge := newDataAgeGuard(searcher, searchMaxDataAge)
+	r.Methods("POST").Path("/screen").HandlerFunc(searchConcurrency.wrap(maxAge.wrap(screenParty(logger, searcher))))
+}
+
+// screenParty screens a structured party by every field it has and returns the single record
+// which matches best
+func screenParty(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+		requestID, userID := moovhttp.GetRequestID(r), moovhttp.GetUserID(r)
+
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		var req screenRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			moovhttp.Problem(w, fmt.Errorf("invalid party: %v", err))
+			return
+		}
+		if err := req.validate(); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		opts, err := readSearchOptions(r.URL)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		r, cancel := withSearchTimeout(r, opts)
+		defer cancel()
+		opts.ctx = r.Context()
+
+		logger.Log("search", fmt.Sprintf("screening party %s", redact(req.Name)), "requestID", requestID, "userID", userID)
+		match := searcher.screen(req, opts)
+		if searchTimedOut(w, r) {
+			return
+		}
+		var top float64
+		if match != nil {
+			top = match.Match
+		}
+		matchHist.With("type", "screen").Observe(top)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(screenResponse{
+			Match:       match,
+			Decision:    searchDecisionThresholds.decide(top),
+			RefreshedAt: searcher.lastRefreshedAt,
+		})
+	}
+}
+
+func (req *screenRequest) validate() error {
+	req.Name = strings.TrimSpace(req.Name)
+	if emptyQuery(req.Name) {
+		return errNoScreenName
+	}
+	if raw := strings.TrimSpace(req.DateOfBirth); raw != "" {
+		if req.DateOfBirth = normalizeDOB(raw); req.DateOfBirth == "" {
+			return fmt.Errorf("invalid dateOfBirth %q, expected YYYY-MM-DD", raw)
+		}
+	}
+	req.Nationality = strings.TrimSpace(req.Nationality)
+	req.ID = strings.TrimSpace(req.ID)
+	return nil
+}
+
+// screen scores the SDNs with the closest names, and those with the party's ID, by every field
+// of the party. The records of other lists with the closest names are scored by the fields those
+// lists publish. The best match is returned along with the party's listings on other lists, nil is
+// returned when no record was found.
+func (s *searcher) screen(req screenRequest, opts searchOptions) *screenMatch {
+	resp := &searchResponse{
+		SDNs:                      s.TopSDNs(screenCandidates, req.Name, opts),
+		SectoralSanctions:         s.TopSSIs(screenCandidates, req.Name, opts),
+		CanadianSanctions:         s.TopCanadianSanctions(screenCandidates, req.Name, opts),
+		AustralianSanctions:       s.TopAustralianSanctions(screenCandidates, req.Name, opts),
+		UnitedNationsSanctions:    s.TopUnitedNationsSanctions(screenCandidates, req.Name, opts),
+		CustomEntries:             s.TopCustomEntries(screenCandidates, req.Name, opts),
+		DeniedPersons:             s.TopDPs(screenCandidates, req.Name, opts),
+		BISEntities:               s.TopBISEntities(screenCandidates, req.Name, opts),
+		NonproliferationSanctions: s.TopISNs(screenCandidates, req.Name, opts),
+	}
+
+	// SDNs with the party's ID are screened even when their name isn't close
+	sameID := make(map[string]bool)
+	for _, sdn := range s.findSDNsByRemarksID(screenCandidates, req.ID, opts) {
+		sameID[sdn.EntityID] = true
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if len(sameID) > 0 {
+		for i := range s.SDNs {
+			if !sameID[s.SDNs[i].EntityID] || containsSDN(resp.SDNs, s.SDNs[i].EntityID) {
+				continue
+			}
+			sdn := *s.SDNs[i]
+			sdn.match = explainSDN(s.SDNs[i], req.Name, opts).Match
+			resp.SDNs = append(resp.SDNs, sdn)
+		}
+	}
+
+	addresses := make(map[string][]*Address)
+	if !req.Address.empty() {
+		for i := range s.Addresses {
+			if id := s.Addresses[i].Address.EntityID; containsSDN(resp.SDNs, id) {
+				addresses[id] = append(addresses[id], s.Addresses[i])
+			}
+		}
+	}
+
+	parties := resp.screenParties()
+	if len(parties) == 0 {
+		return nil
+	}
+	matches := make([]*screenMatch, 0, len(parties))
+	for _, p := range parties {
+		if sdn, ok := p.result.(SDN); ok {
+			matches = append(matches, req.score(sdn, p, sameID[sdn.EntityID], addresses[sdn.EntityID], opts))
+		} else {
+			matches = append(matches, req.scoreParty(p))
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Match > matches[j].Match })
+
+	best := matches[0]
+	for _, p := range parties {
+		if best.party.agrees(p) {
+			best.Listings = append(best.Listings, p.entry)
+		}
+	}
+	return best
+}
+
+// screenParties are the parties of every result in resp, parties is extended with the lists
+// which can't be grouped since they don't publish dates of birth or IDs
+func (resp *searchResponse) screenParties() []*party {
+	out := resp.parties()
+	for i := range resp.DeniedPersons {
+		if dp := resp.DeniedPersons[i]; dp.DeniedPerson != nil {
+			out = append(out, &party{entry: entityEntry{sourceDPL, "", dp.DeniedPerson.Name, dp.match}, result: dp})
+		}
+	}
+	for i := range resp.BISEntities {
+		if el := resp.BISEntities[i]; el.Entity != nil {
+			out = append(out, &party{entry: entityEntry{sourceEL, "", el.Entity.Name, el.match}, result: el})
+		}
+	}
+	for i := range resp.NonproliferationSanctions {
+		if isn := resp.NonproliferationSanctions[i]; isn.Sanction != nil {
+			out = append(out, &party{entry: entityEntry{sourceISN, isn.Sanction.EntityID, isn.Sanction.Name, isn.match}, result: isn})
+		}
+	}
+	return out
+}
+
+func containsSDN(sdns []SDN, entityID string) bool {
+	for i := range sdns {
+		if sdns[i].EntityID == entityID {
+			return true
+		}
+	}
+	return false
+}
+
+// score blends the score of each field the party and SDN both have, p is the SDN's party
+func (req screenRequest) score(sdn SDN, p *party, sameID bool, addresses []*Address, opts searchOptions) *screenMatch {
+	out := &screenMatch{
+		Source:   sourceSDN,
+		EntityID: sdn.EntityID,
+		Name:     sdn.SDNName,
+		Fields:   make(map[string]screenField),
+		party:    p,
+	}
+	add := out.add
+
+	add(screenFieldName, true, sdn.match, sdn.name)
+	if req.DateOfBirth != "" {
+		score, indexed := scoreDOB(req.DateOfBirth, remarkDOBs(sdn.Remarks))
+		add(screenFieldDateOfBirth, indexed != "", score, indexed)
+	}
+	if req.Nationality != "" {
+		score, indexed := scoreNationality(req.Nationality, remarkNationalities(sdn.Remarks))
+		add(screenFieldNationality, indexed != "", score, indexed)
+	}
+	if req.ID != "" {
+		score := 0.0
+		if sameID {
+			score = 1.0
+		}
+		add(screenFieldID, sdn.id != "", score, sdn.id)
+	}
+	if !req.Address.empty() {
+		compare := weightedAddressCompare(req.Address, addressScoreWeights, opts.forAddress(req.Address))
+		var best float64
+		var indexed string
+		for _, addr := range addresses {
+			if score := compare(addr).weight; score > best || indexed == "" {
+				best = score
+				indexed = strings.TrimSpace(strings.Join([]string{addr.Address.Address, addr.Address.CityStateProvincePostalCode, addr.Address.Country}, " "))
+			}
+		}
+		add(screenFieldAddress, len(addresses) > 0, best, indexed)
+	}
+	out.blend()
+
+	sdn.hideRemarks = !opts.includeRemarks
+	sdn.showDetails = opts.includeDetails
+	out.SDN = &sdn
+	return out
+}
+
+// scoreParty blends the score of each field the party and a record of a list other than the
+// SDN list both have. Those lists publish names, some of them dates of birth and IDs, but
+// nationalities and addresses aren't compared.
+func (req screenRequest) scoreParty(p *party) *screenMatch {
+	out := &screenMatch{
+		Source:   p.entry.Source,
+		EntityID: p.entry.EntityID,
+		Name:     p.entry.Name,
+		Fields:   make(map[string]screenField),
+		Result:   p.result,
+		party:    p,
+	}
+	out.add(screenFieldName, true, p.entry.Match, precompute(p.entry.Name))
+	if req.DateOfBirth != "" {
+		score, indexed := scoreDOB(req.DateOfBirth, p.dobs)
+		out.add(screenFieldDateOfBirth, indexed != "", score, indexed)
+	}
+	if req.Nationality != "" {
+		out.add(screenFieldNationality, false, 0, "")
+	}
+	if req.ID != "" {
+		var score float64
+		var indexed string
+		for _, id := range p.ids {
+			if id == "" {
+				continue
+			}
+			if id == normalizeID(req.ID) {
+				score, indexed = 1.0, id
+				break
+			}
+			if indexed == "" {
+				indexed = id
+			}
+		}
+		out.add(screenFieldID, indexed != "", score, indexed)
+	}
+	if !req.Address.empty() {
+		out.add(screenFieldAddress, false, 0, "")
+	}
+	out.blend()
+	return out
+}
+
+// add records the score of a field, or that it wasn't compared
+func (m *screenMatch) add(field string, compared bool, score float64, indexed string) {
+	if !compared {
+		m.NotCompared = append(m.NotCompared, field)
+		return
+	}
+	m.Fields[field] = screenField{Score: score, Weight: screenWeights[field], Indexed: indexed}
+}
+
+// blend sums the score of the compared fields into the match, their weights are normalized over
+// the compared fields so the match stays between 0 and 1
+func (m *screenMatch) blend() {
+	var total float64
+	for field := range m.Fields {
+		total += screenWeights[field]
+	}
+	for field, f := range m.Fields {
+		f.Weight = f.Weight / total
+		m.Match += f.Score * f.Weight
+		m.Fields[field] = f
+	}
+}
+
+// scoreDOB returns 1.0 when a date of birth is the same as dob, or 0.5 when only their year is
+// the same, along with the best date. Dates with only a year match any date in that year.
+func scoreDOB(dob string, dobs []string) (float64, string) {
+	var best float64
+	var indexed string
+	for _, other := range dobs {
+		var score float64
+		switch {
+		case other == dob:
+			score = 1.0
+		case len(other) == 4 || len(dob) == 4:
+			if other[:4] == dob[:4] {
+				score = 1.0
+			}
+		case other[:4] == dob[:4]:
+			score = 0.5
+		}
+		if score > best || indexed == "" {
+			best, indexed = score, other
+		}
+	}
+	return best, indexed
+}
+
+// scoreNationality returns 1.0 when a nationality is the same as nationality once both are normalized
+func scoreNationality(nationality string, nationalities []string) (float64, string) {
+	var indexed string
+	for _, other := range nationalities {
+		if precompute(other) == precompute(nationality) {
+			return 1.0, other
+		}
+		if indexed == "" {
+			indexed = other
+		}
+	}
+	return 0.0, indexed
+}
+
+var remarkNationalityRegex = regexp.MustCompile(`(?i)\b(?:nationality|citizen) ([^;]+)`)
+
+// remarkNationalities finds each nationality and citizenship (e.g. "nationality Korea, North")
+// in SDN remarks
+func remarkNationalities(remarks string) []string {
+	var out []string
+	for _, m := range remarkNationalityRegex.FindAllStringSubmatch(remarks, -1) {
+		if nationality := strings.TrimRight(strings.TrimSpace(m[1]), "."); nationality != "" {
+			out = append(out, nationality)
+		}
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/watchman/pkg/ca"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func screenTestSearcher() *searcher {
+	sdns := []*ofac.SDN{
+		{EntityID: "1", SDNName: "SMITH, John", SDNType: "individual", Remarks: "DOB 01 Jan 1970; nationality United Kingdom; Passport No. A1111 (United Kingdom)."},
+		{EntityID: "2", SDNName: "SMITH, John", SDNType: "individual", Remarks: "DOB 05 May 1980; nationality Canada; Passport No. B2222 (Canada)."},
+		{EntityID: "3", SDNName: "SMYTHE, Jon", SDNType: "individual", Remarks: "Passport No. X12345 (Ireland)."},
+	}
+	addrs := []*ofac.Address{
+		{EntityID: "1", AddressID: "11", Address: "10 Downing St", CityStateProvincePostalCode: "London", Country: "United Kingdom"},
+		{EntityID: "2", AddressID: "21", Address: "100 Queen St W", CityStateProvincePostalCode: "Toronto, Ontario", Country: "Canada"},
+	}
+	return &searcher{
+		SDNs:            precomputeSDNs(sdns, addrs, noLogPipeliner),
+		Addresses:       precomputeAddresses(addrs),
+		lastRefreshedAt: time.Now(),
+		pipe:            noLogPipeliner,
+		logger:          log.NewNopLogger(),
+	}
+}
+
+func screenTest(t *testing.T, s *searcher, body string) (int, screenResponse) {
+	t.Helper()
+
+	router := mux.NewRouter()
+	addScreenRoutes(log.NewNopLogger(), router, s)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/screen", strings.NewReader(body)))
+	var resp screenResponse
+	if w.Code == http.StatusOK {
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w.Code, resp
+}
+
+func TestScreen(t *testing.T) {
+	s := screenTestSearcher()
+
+	cases := []struct {
+		body     string
+		entityID string
+		deciding string
+	}{
+		{`{"name": "John Smith", "dateOfBirth": "1980-05-05"}`, "2", screenFieldDateOfBirth},
+		{`{"name": "John Smith", "dateOfBirth": "1970-01-01"}`, "1", screenFieldDateOfBirth},
+		{`{"name": "John Smith", "nationality": "united kingdom"}`, "1", screenFieldNationality},
+		{`{"name": "John Smith", "id": "X12345"}`, "3", screenFieldID},
+		{`{"name": "John Smith", "address": {"city": "Toronto", "country": "Canada"}}`, "2", screenFieldAddress},
+	}
+	for i, tc := range cases {
+		code, resp := screenTest(t, s, tc.body)
+		if code != http.StatusOK || resp.Match == nil {
+			t.Fatalf("#%d: bogus HTTP status %d: %#v", i, code, resp)
+		}
+		m := resp.Match
+		if m.EntityID != tc.entityID || m.Source != sourceSDN {
+			t.Errorf("#%d: matched %s: %#v", i, m.EntityID, m)
+			continue
+		}
+		if f, ok := m.Fields[tc.deciding]; !ok || f.Score != 1.0 {
+			t.Errorf("#%d: %s: %#v", i, tc.deciding, m.Fields)
+		}
+
+		// the match is the weighted sum of the fields
+		var sum, weights float64
+		for _, f := range m.Fields {
+			sum += f.Score * f.Weight
+			weights += f.Weight
+		}
+		if math.Abs(sum-m.Match) > 0.001 || math.Abs(weights-1.0) > 0.001 {
+			t.Errorf("#%d: match=%.3f fields=%#v", i, m.Match, m.Fields)
+		}
+	}
+
+	// fields the SDN doesn't have aren't compared
+	_, resp := screenTest(t, s, `{"name": "Jon Smythe", "dateOfBirth": "1980-05-05", "id": "X12345"}`)
+	if m := resp.Match; m == nil || m.EntityID != "3" || len(m.NotCompared) != 1 || m.NotCompared[0] != screenFieldDateOfBirth {
+		t.Errorf("%#v", m)
+	}
+	if m := resp.Match; m != nil && (m.Fields[screenFieldID].Indexed != "X12345" || m.SDN.SDN == nil || m.SDN.Remarks != "") {
+		t.Errorf("%#v", m)
+	}
+
+	// a different date of birth counts against a match
+	_, resp = screenTest(t, s, `{"name": "John Smith", "dateOfBirth": "1990-01-01"}`)
+	if m := resp.Match; m == nil || m.Fields[screenFieldDateOfBirth].Score != 0.0 || m.Match >= 1.0 {
+		t.Errorf("%#v", m)
+	}
+}
+
+func TestScreen__otherLists(t *testing.T) {
+	s := screenTestSearcher()
+	s.CanadianSanctions = precomputeCanadianSanctions([]*ca.Entry{
+		{Type: ca.TypeIndividual, Country: "Russia", LastName: "Petrov", GivenName: "Ivan", DateOfBirthOrShipBuildDate: "1975-03-03", Schedule: "1", Item: "7"},
+		{Type: ca.TypeIndividual, Country: "Canada", LastName: "Smith", GivenName: "John", DateOfBirthOrShipBuildDate: "1980-05-05", Schedule: "1", Item: "8"},
+	}, noLogPipeliner)
+
+	// the party is only on the Canadian list
+	_, resp := screenTest(t, s, `{"name": "Ivan Petrov", "dateOfBirth": "1975-03-03", "nationality": "Russia"}`)
+	m := resp.Match
+	if m == nil || m.Source != sourceCA || m.SDN != nil || m.Result == nil {
+		t.Fatalf("%#v", m)
+	}
+	if m.Fields[screenFieldDateOfBirth].Score != 1.0 || len(m.NotCompared) != 1 || m.NotCompared[0] != screenFieldNationality {
+		t.Errorf("%#v", m)
+	}
+
+	// the party's listing on the Canadian list agrees with the SDN
+	_, resp = screenTest(t, s, `{"name": "John Smith", "dateOfBirth": "1980-05-05"}`)
+	if m := resp.Match; m == nil || m.EntityID != "2" || len(m.Listings) != 1 || m.Listings[0].Source != sourceCA {
+		t.Errorf("%#v", m)
+	}
+}
+
+func TestScreen__errors(t *testing.T) {
+	s := screenTestSearcher()
+	for _, body := range []string{`{"dateOfBirth": "1980-05-05"}`, `{"name": "John Smith", "dateOfBirth": "yesterday"}`, `{"name": " -- "}`, `[]`} {
+		if code, _ := screenTest(t, s, body); code != http.StatusBadRequest {
+			t.Errorf("%s: bogus HTTP status: %d", body, code)
+		}
+	}
+
+	// nothing to match against
+	code, resp := screenTest(t, &searcher{pipe: noLogPipeliner, logger: log.NewNopLogger()}, `{"name": "John Smith"}`)
+	if code != http.StatusOK || resp.Match != nil {
+		t.Errorf("bogus response %d: %#v", code, resp)
+	}
+}
+
+func TestScreen__scoreDOB(t *testing.T) {
+	cases := []struct {
+		dob      string
+		dobs     []string
+		expected float64
+	}{
+		{"1980-05-05", []string{"1970-01-01", "1980-05-05"}, 1.0},
+		{"1980-05-05", []string{"1980"}, 1.0},
+		{"1980", []string{"1980-05-05"}, 1.0},
+		{"1980-05-05", []string{"1980-06-05"}, 0.5},
+		{"1980-05-05", []string{"1981-05-05"}, 0.0},
+	}
+	for i := range cases {
+		if score, _ := scoreDOB(cases[i].dob, cases[i].dobs); score != cases[i].expected {
+			t.Errorf("#%d: score=%.2f", i, score)
+		}
+	}
+	if remarks := remarkNationalities("DOB 1984; nationality Korea, North; citizen Korea, North."); len(remarks) != 2 || remarks[0] != "Korea, North" || remarks[1] != "Korea, North" {
+		t.Errorf("%#v", remarks)
+	}
+}
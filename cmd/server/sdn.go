@@ -9,6 +9,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/moov-io/watchman/pkg/ofac"
 
 	moovhttp "github.com/moov-io/base/http"
 
@@ -17,13 +21,21 @@ import (
 )
 
 var (
-	errNoSDNId = errors.New("no SDN Id provided")
+	errNoSDNId     = errors.New("no SDN Id provided")
+	errNoAltId     = errors.New("no alt Id provided")
+	errNoAddressId = errors.New("no address Id provided")
+
+	// defaultSDNRangeLimit and maxSDNRangeLimit cap how many SDNs GET /ofac/sdn returns
+	defaultSDNRangeLimit, maxSDNRangeLimit = 100, 1000
 )
 
 func addSDNRoutes(logger log.Logger, r *mux.Router, searcher *searcher) {
+	r.Methods("GET").Path("/ofac/sdn").HandlerFunc(getSDNsByIDRange(logger, searcher))
 	r.Methods("GET").Path("/ofac/sdn/{sdnId}/addresses").HandlerFunc(getSDNAddresses(logger, searcher))
 	r.Methods("GET").Path("/ofac/sdn/{sdnId}/alts").HandlerFunc(getSDNAltNames(logger, searcher))
 	r.Methods("GET").Path("/ofac/sdn/{sdnId}").HandlerFunc(getSDN(logger, searcher))
+	r.Methods("GET").Path("/ofac/alt/{altId}").HandlerFunc(getAlt(logger, searcher))
+	r.Methods("GET").Path("/ofac/address/{addressId}").HandlerFunc(getAddress(logger, searcher))
 }
 
 func getSDNId(w http.ResponseWriter, r *http.Request) string {
@@ -100,7 +112,192 @@ func getSDN(logger log.Logger, searcher *searcher) http.HandlerFunc {
 
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(sdn); err != nil {
+		if err := json.NewEncoder(w).Encode(sdnDetail{sdn, sdnTitles(sdn)}); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+	}
+}
+
+// altResponse is a single alternate identity along with the SDN it belongs to
+type altResponse struct {
+	SDNID string `json:"sdnID"`
+	*ofac.AlternateIdentity
+}
+
+func getAlt(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		id, ok := mux.Vars(r)["altId"]
+		if !ok || id == "" {
+			moovhttp.Problem(w, errNoAltId)
+			return
+		}
+		alt := searcher.FindAlt(id)
+		if alt == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		requestID, userID := moovhttp.GetRequestID(r), moovhttp.GetUserID(r)
+		logger.Log("sdn", fmt.Sprintf("get alt=%s", id), "requestID", requestID, "userID", userID)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(altResponse{SDNID: alt.EntityID, AlternateIdentity: alt}); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+	}
+}
+
+// addressResponse is a single address along with the SDN it belongs to
+type addressResponse struct {
+	SDNID string `json:"sdnID"`
+	*ofac.Address
+}
+
+func getAddress(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		id, ok := mux.Vars(r)["addressId"]
+		if !ok || id == "" {
+			moovhttp.Problem(w, errNoAddressId)
+			return
+		}
+		addr := searcher.FindAddress(id)
+		if addr == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		requestID, userID := moovhttp.GetRequestID(r), moovhttp.GetUserID(r)
+		logger.Log("sdn", fmt.Sprintf("get address=%s", id), "requestID", requestID, "userID", userID)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(addressResponse{SDNID: addr.EntityID, Address: addr}); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+	}
+}
+
+// sdnSummary is the identifying fields of an SDN, returned when listing SDNs
+type sdnSummary struct {
+	EntityID string   `json:"entityID"`
+	SDNName  string   `json:"sdnName"`
+	SDNType  string   `json:"sdnType"`
+	Programs []string `json:"programs"`
+}
+
+type sdnRangeResponse struct {
+	SDNs []sdnSummary `json:"SDNs"`
+
+	// Truncated is true when more SDNs are in the range than limit
+	Truncated bool `json:"truncated"`
+}
+
+// readSDNRange parses ?minId and ?maxId, which are both required
+func readSDNRange(r *http.Request) (int, int, error) {
+	read := func(key string) (int, error) {
+		v := r.URL.Query().Get(key)
+		if v == "" {
+			return 0, fmt.Errorf("missing %s", key)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid %s %q", key, v)
+		}
+		return n, nil
+	}
+	min, err := read("minId")
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := read("maxId")
+	if err != nil {
+		return 0, 0, err
+	}
+	if min > max {
+		return 0, 0, fmt.Errorf("minId %d is above maxId %d", min, max)
+	}
+	return min, max, nil
+}
+
+// SDNsByIDRange returns SDNs whose (numeric) entity ID is between min and max, inclusive, in
+// order of their ID. At most limit SDNs are returned and the second value is true when more
+// were in the range.
+func (s *searcher) SDNsByIDRange(min, max, limit int) ([]*ofac.SDN, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	type indexed struct {
+		id  int
+		sdn *ofac.SDN
+	}
+	var matches []indexed
+	for i := range s.SDNs {
+		id, err := strconv.Atoi(s.SDNs[i].EntityID)
+		if err != nil || id < min || id > max {
+			continue
+		}
+		matches = append(matches, indexed{id, s.SDNs[i].SDN})
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].id < matches[j].id })
+
+	truncated := len(matches) > limit
+	if truncated {
+		matches = matches[:limit]
+	}
+	out := make([]*ofac.SDN, 0, len(matches))
+	for i := range matches {
+		out = append(out, matches[i].sdn)
+	}
+	return out, truncated
+}
+
+func getSDNsByIDRange(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		min, max, err := readSDNRange(r)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		limit := defaultSDNRangeLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				limit = n
+			}
+		}
+		if limit > maxSDNRangeLimit {
+			limit = maxSDNRangeLimit
+		}
+
+		requestID, userID := moovhttp.GetRequestID(r), moovhttp.GetUserID(r)
+		logger.Log("sdn", fmt.Sprintf("get sdns from %d to %d", min, max), "requestID", requestID, "userID", userID)
+
+		sdns, truncated := searcher.SDNsByIDRange(min, max, limit)
+		resp := sdnRangeResponse{
+			SDNs:      make([]sdnSummary, 0, len(sdns)),
+			Truncated: truncated,
+		}
+		for _, sdn := range sdns {
+			resp.SDNs = append(resp.SDNs, sdnSummary{
+				EntityID: sdn.EntityID,
+				SDNName:  sdn.SDNName,
+				SDNType:  sdn.SDNType,
+				Programs: sdn.Programs,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
 			moovhttp.Problem(w, err)
 			return
 		}
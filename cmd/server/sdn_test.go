@@ -103,6 +103,76 @@ func TestSDN__AltNames(t *testing.T) {
 	}
 }
 
+func TestSDN__getAlt(t *testing.T) {
+	s := &searcher{
+		Alts: precomputeAlts([]*ofac.AlternateIdentity{
+			{EntityID: "559", AlternateID: "481", AlternateType: "aka", AlternateName: "CIMEX"},
+		}),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSDNRoutes(log.NewNopLogger(), router, s)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ofac/alt/481", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	var alt struct {
+		SDNID string `json:"sdnID"`
+		ofac.AlternateIdentity
+	}
+	if err := json.NewDecoder(w.Body).Decode(&alt); err != nil {
+		t.Fatal(err)
+	}
+	if alt.SDNID != "559" || alt.AlternateID != "481" || alt.AlternateName != "CIMEX" {
+		t.Errorf("got %#v", alt)
+	}
+
+	// the alt is gone after a refresh
+	s.Lock()
+	s.Alts = precomputeAlts([]*ofac.AlternateIdentity{
+		{EntityID: "559", AlternateID: "482", AlternateType: "aka", AlternateName: "CIMEX"},
+	})
+	s.Unlock()
+	for _, path := range []string{"/ofac/alt/481", "/ofac/alt/559"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: bogus status code: %d", path, w.Code)
+		}
+	}
+}
+
+func TestSDN__getAddress(t *testing.T) {
+	router := mux.NewRouter()
+	addSDNRoutes(log.NewNopLogger(), router, addressSearcher)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ofac/address/447", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	var addr struct {
+		SDNID string `json:"sdnID"`
+		ofac.Address
+	}
+	if err := json.NewDecoder(w.Body).Decode(&addr); err != nil {
+		t.Fatal(err)
+	}
+	if addr.SDNID != "735" || addr.AddressID != "447" || addr.EntityID != "735" {
+		t.Errorf("got %#v", addr)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ofac/address/735", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
+
 func TestSDN__Get(t *testing.T) {
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest("GET", "/ofac/sdn/2681", nil)
@@ -125,3 +195,129 @@ func TestSDN__Get(t *testing.T) {
 		t.Errorf("got %#v", sdn)
 	}
 }
+
+func TestSDN__remarks(t *testing.T) {
+	remarks := "DOB 23 Nov 1962; POB Caracas, Venezuela; Cedula No. 5892464 (Venezuela);  \"Nicolas\" <President>."
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual", Remarks: remarks},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSDNRoutes(log.NewNopLogger(), router, s)
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	get := func(path string) map[string]json.RawMessage {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: bogus status code: %d", path, w.Code)
+		}
+		var out map[string]json.RawMessage
+		if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	firstSDN := func(resp map[string]json.RawMessage) map[string]json.RawMessage {
+		t.Helper()
+
+		var sdns []map[string]json.RawMessage
+		if err := json.Unmarshal(resp["SDNs"], &sdns); err != nil || len(sdns) == 0 {
+			t.Fatalf("SDNs: %s (err=%v)", resp["SDNs"], err)
+		}
+		return sdns[0]
+	}
+	roundTrips := func(desc string, raw json.RawMessage) {
+		t.Helper()
+
+		var got string
+		if err := json.Unmarshal(raw, &got); err != nil {
+			t.Fatalf("%s: %v", desc, err)
+		}
+		if got != remarks {
+			t.Errorf("%s: remarks=%q", desc, got)
+		}
+	}
+
+	// the detail endpoint always has remarks
+	roundTrips("detail", get("/ofac/sdn/22790")["remarks"])
+
+	// searches only include them when asked
+	for _, query := range []string{"name=nicolas+maduro", "id=5892464", "q=nicolas+maduro"} {
+		if sdn := firstSDN(get("/search?" + query)); sdn["remarks"] != nil {
+			t.Errorf("%s: unexpected remarks: %s", query, sdn["remarks"])
+		}
+		roundTrips(query, firstSDN(get("/search?includeRemarks=true&" + query))["remarks"])
+	}
+}
+
+func TestSDN__idRange(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1101", SDNName: "AFTER"},
+			{EntityID: "1050", SDNName: "MIDDLE"},
+			{EntityID: "999", SDNName: "BEFORE"},
+			{EntityID: "1000", SDNName: "FIRST", SDNType: "individual", Programs: []string{"SDGT"}},
+			{EntityID: "1100", SDNName: "LAST"},
+			{EntityID: "abc", SDNName: "NOT NUMERIC"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSDNRoutes(log.NewNopLogger(), router, s)
+
+	get := func(query string) (int, sdnRangeResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/ofac/sdn?"+query, nil))
+		w.Flush()
+
+		var resp sdnRangeResponse
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w.Code, resp
+	}
+
+	code, resp := get("minId=1000&maxId=1100")
+	if code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", code)
+	}
+	if len(resp.SDNs) != 3 || resp.Truncated {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+	for i, id := range []string{"1000", "1050", "1100"} {
+		if resp.SDNs[i].EntityID != id {
+			t.Errorf("SDNs[%d]: got %s expected %s", i, resp.SDNs[i].EntityID, id)
+		}
+	}
+	if sdn := resp.SDNs[0]; sdn.SDNName != "FIRST" || sdn.SDNType != "individual" || len(sdn.Programs) != 1 {
+		t.Errorf("unexpected summary: %#v", sdn)
+	}
+
+	// the count is capped
+	code, resp = get("minId=0&maxId=5000&limit=2")
+	if code != http.StatusOK || len(resp.SDNs) != 2 || !resp.Truncated || resp.SDNs[0].EntityID != "999" {
+		t.Errorf("unexpected response: %d %#v", code, resp)
+	}
+
+	// empty range
+	code, resp = get("minId=2000&maxId=3000")
+	if code != http.StatusOK || resp.SDNs == nil || len(resp.SDNs) != 0 || resp.Truncated {
+		t.Errorf("unexpected response: %d %#v", code, resp)
+	}
+
+	for _, query := range []string{"", "minId=1000", "maxId=1000", "minId=a&maxId=10", "minId=-5&maxId=10", "minId=20&maxId=10"} {
+		if code, _ := get(query); code != http.StatusBadRequest {
+			t.Errorf("%q: bogus status code: %d", query, code)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+// titlePrefixes start a title or position of an individual, either in the title column
+// ("Position: Owner; Alt. Position: General Manager") or in remarks ("Title Basij Deputy
+// Commander.", "Alternate Title, Brigadier General."). Longer prefixes are listed first.
+var titlePrefixes = []string{
+	"Alt. Position:",
+	"Alt. Title:",
+	"Alternate Position:",
+	"Alternate Title:",
+	"Alternate Title,",
+	"Position:",
+	"Title:",
+	"Title ",
+}
+
+// sdnTitles returns each title or position of an SDN, from its title column and remarks. A
+// title is kept whole when it has commas ("Director, Banco Nacional de Cuba") so only
+// semicolons, which OFAC separates fields with, split them.
+func sdnTitles(sdn *ofac.SDN) []string {
+	if sdn == nil {
+		return nil
+	}
+	var out []string
+	seen := make(map[string]bool)
+	add := func(title string) {
+		title = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(title), "."))
+		if title == "" || title == "-0-" || seen[strings.ToLower(title)] {
+			return
+		}
+		seen[strings.ToLower(title)] = true
+		out = append(out, title)
+	}
+	for _, part := range strings.Split(sdn.Title, ";") {
+		if title, ok := trimTitlePrefix(part); ok {
+			add(title)
+		} else {
+			add(part)
+		}
+	}
+	for _, part := range strings.Split(sdn.Remarks, ";") {
+		title, ok := trimTitlePrefix(part)
+		if !ok {
+			continue
+		}
+		// "Alternate Title, Police Chief, Alternate Title, Commander" lists two of them
+		for _, t := range strings.Split(title, ", Alternate Title,") {
+			add(t)
+		}
+	}
+	return out
+}
+
+// trimTitlePrefix removes the prefix of a title, it's false when s doesn't start with one
+func trimTitlePrefix(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	for _, prefix := range titlePrefixes {
+		if len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+			return strings.TrimSpace(s[len(prefix):]), true
+		}
+	}
+	return s, false
+}
+
+// sdnDetail is an SDN returned by GET /ofac/sdn/{sdnId} along with its parsed titles
+type sdnDetail struct {
+	*ofac.SDN
+	Titles []string `json:"titles,omitempty"`
+}
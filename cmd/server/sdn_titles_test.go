@@ -0,0 +1,105 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSDNTitles(t *testing.T) {
+	cases := []struct {
+		sdn      *ofac.SDN
+		expected []string
+	}{
+		{
+			&ofac.SDN{Title: "Director, Banco Nacional de Cuba"},
+			[]string{"Director, Banco Nacional de Cuba"},
+		},
+		{
+			&ofac.SDN{Title: "Position: Owner; Alt. Position: General Manager; Alt. Position: Chief Executive Officer"},
+			[]string{"Owner", "General Manager", "Chief Executive Officer"},
+		},
+		{
+			&ofac.SDN{Title: "-0-", Remarks: "DOB 1961; POB Tehran, Iran; Title Basij Deputy Commander."},
+			[]string{"Basij Deputy Commander"},
+		},
+		{
+			&ofac.SDN{Remarks: "DOB 1953; nationality Syria; Position: Director, General Intelligence Directorate."},
+			[]string{"Director, General Intelligence Directorate"},
+		},
+		{
+			&ofac.SDN{
+				Title:   "Chief of Iran's Law Enforcement Forces",
+				Remarks: "Additional Sanctions Information - Subject to Secondary Sanctions; Alternate Title, Police Chief, Alternate Title, Commander of Iran's Law Enforcement Force; Alternate Title:  Colonel.",
+			},
+			[]string{"Chief of Iran's Law Enforcement Forces", "Police Chief", "Commander of Iran's Law Enforcement Force", "Colonel"},
+		},
+		{
+			// the same title in the column and remarks is listed once
+			&ofac.SDN{Title: "Position: Owner", Remarks: "Position: owner; Position also referred to as Minister of Internal Affairs."},
+			[]string{"Owner"},
+		},
+		{&ofac.SDN{Title: "-0-", Remarks: "-0-"}, nil},
+	}
+	for _, tc := range cases {
+		if got := sdnTitles(tc.sdn); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%q %q: got %q", tc.sdn.Title, tc.sdn.Remarks, got)
+		}
+	}
+}
+
+func TestSDNTitles__routes(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "ACOSTA, Rafael", SDNType: "individual", Title: "Position: Owner; Alt. Position: General Manager"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSDNRoutes(log.NewNopLogger(), router, s)
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	get := func(path string, v interface{}) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: bogus status code: %d", path, w.Code)
+		}
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []string{"Owner", "General Manager"}
+
+	var detail struct {
+		Title  string   `json:"title"`
+		Titles []string `json:"titles"`
+	}
+	get("/ofac/sdn/1", &detail)
+	if detail.Title != "Position: Owner; Alt. Position: General Manager" || !reflect.DeepEqual(detail.Titles, expected) {
+		t.Errorf("detail: %#v", detail)
+	}
+
+	var search struct {
+		SDNs []struct {
+			Titles []string `json:"titles"`
+		} `json:"SDNs"`
+	}
+	get("/search?name=rafael+acosta", &search)
+	if len(search.SDNs) != 1 || !reflect.DeepEqual(search.SDNs[0].Titles, expected) {
+		t.Errorf("search: %#v", search.SDNs)
+	}
+}
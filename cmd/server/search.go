@@ -16,9 +16,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/moov-io/watchman/pkg/au"
+	"github.com/moov-io/watchman/pkg/ca"
 	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/custom"
 	"github.com/moov-io/watchman/pkg/dpl"
 	"github.com/moov-io/watchman/pkg/ofac"
+	"github.com/moov-io/watchman/pkg/un"
 
 	"github.com/go-kit/kit/log"
 	"github.com/xrash/smetrics"
@@ -36,16 +40,50 @@ type searcher struct {
 	// OFAC
 	SDNs      []*SDN
 	Addresses []*Address
+	sdnIDs    *sdnIDIndex   // nil without SDN_ID_INDEX, it's swapped in with SDNs
+	sdnNames  *sdnNameIndex // every name of each SDN, it's swapped in with SDNs and Alts
 	Alts      []*Alt
 	SSIs      []*SSI
 
+	// individuals has the EntityID of every individual SDN, it's swapped in with SDNs
+	individuals map[string]bool
+
 	// BIS
 	DPs         []*DP
 	BISEntities []*BISEntity
 
+	// State Department
+	ISNs []*ISN
+
+	// Global Affairs Canada
+	CanadianSanctions []*CanadianSanction
+
+	// Australian Department of Foreign Affairs and Trade
+	AustralianSanctions []*AustralianSanction
+
+	// United Nations Security Council
+	UnitedNationsSanctions []*UnitedNationsSanction
+
+	// Custom list (CUSTOM_LIST_FILE)
+	CustomEntries []*CustomEntry
+
 	// metadata
-	lastRefreshedAt time.Time
-	sync.RWMutex    // protects all above fields
+	lastRefreshedAt    time.Time
+	refreshSucceededAt time.Time                 // when refreshData last completed, used for staleness
+	lastParseReport    *parseReport              // warnings about malformed records from the last refresh
+	staleSources       map[string]time.Time      // sources kept after failed downloads, with when they first failed
+	sourceRefreshedAt  map[string]time.Time      // when each source was last loaded, used by searchMaxDataAge
+	ofacSequence       int64                     // of the last OFAC delta the records include, zero when unknown
+	lastOFACDelta      *ofacDeltaReport          // changes of the last OFAC delta applied onto the records
+	sourceDownloads    map[string]sourceDownload // size and record count of each source's last download
+	generation         int64                     // incremented each time records are swapped in (see nextGeneration)
+	generatedAt        time.Time                 // when the current generation was swapped in
+	sync.RWMutex                                 // protects all above fields
+
+	rebuilds rebuildCoalescer // merges bursts of refreshes and reindexes
+
+	// warmup checks the index after each refresh and reindex, it's nil without WARMUP_QUERIES_FILE
+	warmup *warmupChecker
 
 	pipe *pipeliner
 
@@ -68,8 +106,21 @@ func (s *searcher) FindAddresses(limit int, id string) []*ofac.Address {
 	return out
 }
 
-func (s *searcher) TopAddresses(limit int, reqAddress string) []Address {
-	return s.TopAddressesFn(limit, topAddressesAddress(reqAddress))
+// FindAddress returns the address whose AddressID is id, or nil when it isn't indexed
+func (s *searcher) FindAddress(id string) *ofac.Address {
+	s.RLock()
+	defer s.RUnlock()
+
+	for i := range s.Addresses {
+		if s.Addresses[i].Address.AddressID == id {
+			return s.Addresses[i].Address
+		}
+	}
+	return nil
+}
+
+func (s *searcher) TopAddresses(limit int, reqAddress string, opts searchOptions) []Address {
+	return s.TopAddressesFn(limit, opts, topAddressesAddress(reqAddress))
 }
 
 var (
@@ -78,7 +129,7 @@ var (
 		return func(add *Address) *item {
 			return &item{
 				value:  add,
-				weight: jaroWinkler(add.address, precompute(needleAddr)),
+				weight: searchScorers.address.score(add.address, precomputeAddress(needleAddr)),
 			}
 		}
 	}
@@ -90,7 +141,7 @@ var (
 		return func(add *Address) *item {
 			return &item{
 				value:  add,
-				weight: jaroWinkler(add.citystate, precompute(needleCityState)),
+				weight: searchScorers.address.score(add.citystate, precomputeAddress(needleCityState)),
 			}
 		}
 	}
@@ -100,7 +151,7 @@ var (
 		return func(add *Address) *item {
 			return &item{
 				value:  add,
-				weight: jaroWinkler(add.country, precompute(needleCountry)),
+				weight: searchScorers.address.score(add.country, precompute(needleCountry)),
 			}
 		}
 	}
@@ -127,16 +178,22 @@ var (
 // compare takes an Address (from s.Addresses) and is expected to extract some property to be compared
 // against a captured parameter (in a closure calling compare) to return an *item for final sorting.
 // See searchByAddress in search_handlers.go for an example
-func (s *searcher) TopAddressesFn(limit int, compare func(*Address) *item) []Address {
+func (s *searcher) TopAddressesFn(limit int, opts searchOptions, compare func(*Address) *item) []Address {
 	s.RLock()
 	defer s.RUnlock()
 
 	if len(s.Addresses) == 0 {
 		return nil
 	}
-	xs := newLargest(limit)
+	xs := opts.largest(limit, sourceSDN)
 
 	for i := range s.Addresses {
+		if opts.cancelled(i) {
+			break
+		}
+		if s.Addresses[i].duplicate || opts.excluded(s.Addresses[i].Address.EntityID) {
+			continue
+		}
 		xs.add(compare(s.Addresses[i]))
 	}
 	return largestToAddresses(xs)
@@ -151,7 +208,7 @@ func largestToAddresses(xs *largest) []Address {
 				continue
 			}
 			address := *aa
-			address.match = v.weight
+			address.match, address.adjustment = adjustSourceScore(sourceSDN, v.weight)
 			out = append(out, address)
 		}
 	}
@@ -174,7 +231,20 @@ func (s *searcher) FindAlts(limit int, id string) []*ofac.AlternateIdentity {
 	return out
 }
 
-func (s *searcher) TopAltNames(limit int, alt string) []Alt {
+// FindAlt returns the alternate identity whose AlternateID is id, or nil when it isn't indexed
+func (s *searcher) FindAlt(id string) *ofac.AlternateIdentity {
+	s.RLock()
+	defer s.RUnlock()
+
+	for i := range s.Alts {
+		if s.Alts[i].AlternateIdentity.AlternateID == id {
+			return s.Alts[i].AlternateIdentity
+		}
+	}
+	return nil
+}
+
+func (s *searcher) TopAltNames(limit int, alt string, opts searchOptions) []Alt {
 	alt = precompute(alt)
 
 	s.RLock()
@@ -183,12 +253,23 @@ func (s *searcher) TopAltNames(limit int, alt string) []Alt {
 	if len(s.Alts) == 0 {
 		return nil
 	}
-	xs := newLargest(limit)
+	xs := opts.largest(limit, sourceSDN)
 
 	for i := range s.Alts {
+		if opts.cancelled(i) {
+			break
+		}
+		entityID := s.Alts[i].AlternateIdentity.EntityID
+		if nameless(s.Alts[i].name, nil) || opts.excluded(entityID) {
+			continue
+		}
+		// alt names of individuals need as many matching tokens as their primary names
+		if !opts.enoughNameTokens(s.individuals[entityID], s.Alts[i].name, alt) {
+			continue
+		}
 		xs.add(&item{
 			value:  s.Alts[i],
-			weight: jaroWinkler(s.Alts[i].name, alt),
+			weight: opts.score(s.Alts[i].name, alt),
 		})
 	}
 
@@ -200,13 +281,27 @@ func (s *searcher) TopAltNames(limit int, alt string) []Alt {
 				continue
 			}
 			alt := *aa
-			alt.match = v.weight
+			alt.match, alt.adjustment = adjustSourceScore(sourceSDN, v.weight)
+			if opts.matchedName {
+				alt.alias = matchedAlias{aa.AlternateIdentity.AlternateName, strings.ToLower(aa.AlternateIdentity.AlternateType)}
+			}
 			out = append(out, alt)
 		}
 	}
 	return out
 }
 
+// individualEntityIDs returns the EntityID of every individual SDN
+func individualEntityIDs(sdns []*SDN) map[string]bool {
+	out := make(map[string]bool)
+	for i := range sdns {
+		if strings.EqualFold(sdns[i].SDNType, "individual") {
+			out[sdns[i].EntityID] = true
+		}
+	}
+	return out
+}
+
 func (s *searcher) FindSDN(entityID string) *ofac.SDN {
 	if sdn := s.debugSDN(entityID); sdn != nil {
 		return sdn.SDN
@@ -230,47 +325,43 @@ func (s *searcher) debugSDN(entityID string) *SDN {
 // what is provided to this function. It's typically used with values assigned by a local
 // government. (National ID, Drivers License, etc)
 func (s *searcher) FindSDNsByRemarksID(limit int, id string) []SDN {
+	return s.findSDNsByRemarksID(limit, id, searchOptions{})
+}
+
+// findSDNsByRemarksID is FindSDNsByRemarksID without the SDNs excluded by opts. IDs are
+// looked up in sdnIDs unless SDN_ID_INDEX=false, which compares every SDN's ID instead.
+func (s *searcher) findSDNsByRemarksID(limit int, id string, opts searchOptions) []SDN {
 	if id == "" {
 		return nil
 	}
 
-	var out []SDN
-	for i := range s.SDNs {
-		// If the SDN's remarks ID contains a space then we need to ensure "all the numeric
-		// parts have to exactly match" between our query and the parsed ID.
-		if strings.Contains(s.SDNs[i].id, " ") {
-			qParts := strings.Fields(id)
-			sdnParts := strings.Fields(s.SDNs[i].id)
-
-			matched, expected := 0, 0
-			for j := range sdnParts {
-				if n, _ := strconv.ParseInt(sdnParts[j], 10, 64); n > 0 {
-					// This part of the SDN's remarks is a number so it must exactly
-					// match to a query's part
-					expected += 1
-
-					for k := range qParts {
-						if sdnParts[j] == qParts[k] {
-							matched += 1
-						}
-					}
-				}
-			}
+	s.RLock()
+	defer s.RUnlock()
 
-			// If all the numeric parts match between query and SDN return the match
-			if matched == expected {
-				sdn := *s.SDNs[i]
-				sdn.match = 1.0
-				out = append(out, sdn)
-			}
-		} else {
-			// The query and remarks ID must exactly match
-			if s.SDNs[i].id == id {
-				sdn := *s.SDNs[i]
-				sdn.match = 1.0
-				out = append(out, sdn)
+	if _, exact := searchScorers.id.(exactScorer); !exact {
+		return s.scoreSDNsByRemarksID(limit, id, opts)
+	}
+
+	sdns := s.SDNs
+	var positions []int
+	if s.sdnIDs != nil {
+		sdns, positions = s.sdnIDs.sdns, s.sdnIDs.lookup(id)
+	} else {
+		for i := range sdns {
+			if remarksIDMatches(sdns[i].id, id) {
+				positions = append(positions, i)
 			}
 		}
+	}
+
+	var out []SDN
+	for _, i := range positions {
+		if opts.excluded(sdns[i].EntityID) {
+			continue
+		}
+		sdn := *sdns[i]
+		sdn.match = 1.0
+		out = append(out, sdn)
 
 		// quit if we're at our max result size
 		if len(out) >= limit {
@@ -280,7 +371,31 @@ func (s *searcher) FindSDNsByRemarksID(limit int, id string) []SDN {
 	return out
 }
 
-func (s *searcher) TopSDNs(limit int, name string) []SDN {
+// scoreSDNsByRemarksID returns the SDNs whose ID scores best against id with the ID scorer of
+// FIELD_SCORERS, SDNs whose ID doesn't score at all aren't returned. s must be read locked.
+func (s *searcher) scoreSDNsByRemarksID(limit int, id string, opts searchOptions) []SDN {
+	id = precompute(id)
+	xs := opts.largest(limit, sourceSDN)
+	for i := range s.SDNs {
+		if s.SDNs[i].id == "" || opts.excluded(s.SDNs[i].EntityID) {
+			continue
+		}
+		if weight := searchScorers.id.score(precompute(s.SDNs[i].id), id); weight > 0 {
+			xs.add(&item{value: s.SDNs[i], weight: weight})
+		}
+	}
+	var out []SDN
+	for i := range xs.items {
+		if v := xs.items[i]; v != nil {
+			sdn := *(v.value.(*SDN))
+			sdn.match = v.weight
+			out = append(out, sdn)
+		}
+	}
+	return out
+}
+
+func (s *searcher) TopSDNs(limit int, name string, opts searchOptions) []SDN {
 	name = precompute(name)
 
 	s.RLock()
@@ -289,15 +404,30 @@ func (s *searcher) TopSDNs(limit int, name string) []SDN {
 	if len(s.SDNs) == 0 {
 		return nil
 	}
-	xs := newLargest(limit)
-
-	for i := range s.SDNs {
-		xs.add(&item{
-			value:  s.SDNs[i],
-			weight: jaroWinkler(s.SDNs[i].name, name),
-		})
+	xs := opts.largest(limit, sourceSDN)
+	q := newSDNQuery(name)
+
+	if indexAltNames && s.sdnNames != nil {
+		s.sdnNames.top(xs, q, opts)
+	} else {
+		for i := range s.SDNs {
+			if opts.cancelled(i) {
+				break
+			}
+			if weight, ok := opts.scoreSDN(s.SDNs[i], q); ok {
+				xs.add(&item{
+					value:  s.SDNs[i],
+					weight: weight,
+					boost:  opts.inProgramBoost(s.SDNs[i].Programs),
+				})
+			}
+		}
 	}
+	return sdnResults(xs, opts)
+}
 
+// sdnResults returns the SDNs ranked in xs with their source adjusted match
+func sdnResults(xs *largest, opts searchOptions) []SDN {
 	out := make([]SDN, 0)
 	for i := range xs.items {
 		if v := xs.items[i]; v != nil {
@@ -306,14 +436,59 @@ func (s *searcher) TopSDNs(limit int, name string) []SDN {
 				continue
 			}
 			sdn := *ss // deref for a copy
-			sdn.match = v.weight
+			sdn.match, sdn.adjustment = adjustSourceScore(sourceSDN, v.weight)
+			if opts.matchedName && v.alias != "" {
+				sdn.alias = matchedAlias{v.alias, v.aliasType}
+			}
 			out = append(out, sdn)
 		}
 	}
 	return out
 }
 
-func (s *searcher) TopDPs(limit int, name string) []DP {
+// sdnQuery is a (precomputed) query in the forms SDN names are compared with
+type sdnQuery struct {
+	person string // individuals are indexed without titles
+	entity string // entities are indexed without leading articles
+	script string // non-Latin queries are compared with names in their script
+}
+
+func newSDNQuery(name string) sdnQuery {
+	return sdnQuery{
+		person: nameHonorifics.strip(name),
+		entity: nameArticles.strip(name),
+		script: nameScript(name),
+	}
+}
+
+func (q sdnQuery) forSDN(sdn *SDN) (string, bool) {
+	if strings.EqualFold(sdn.SDNType, "individual") {
+		return q.person, true
+	}
+	return q.entity, false
+}
+
+// scoreSDN scores the primary name of sdn, ok is false when it isn't a result
+func (opts searchOptions) scoreSDN(sdn *SDN, q sdnQuery) (float64, bool) {
+	if nameless(sdn.name, nil) || opts.excluded(sdn.EntityID) {
+		return 0.0, false
+	}
+	query, individual := q.forSDN(sdn)
+	indexed := sdn.scriptIndexedName(q.script, query, opts)
+	if !opts.enoughNameTokens(individual, indexed, query) {
+		return 0.0, false
+	}
+	weight, ok := opts.scoreComponents(sdn)
+	if !ok {
+		weight = opts.scoreName(individual, indexed, sdn.exact, query)
+		if other, ok := opts.scoreCJKOrder(sdn, individual, q.script, indexed, query); ok && other > weight {
+			weight = other
+		}
+	}
+	return weight, true
+}
+
+func (s *searcher) TopDPs(limit int, name string, opts searchOptions) []DP {
 	name = precompute(name)
 
 	s.RLock()
@@ -322,12 +497,18 @@ func (s *searcher) TopDPs(limit int, name string) []DP {
 	if len(s.DPs) == 0 {
 		return nil
 	}
-	xs := newLargest(limit)
+	xs := opts.largest(limit, sourceDPL)
 
-	for _, dp := range s.DPs {
+	for i, dp := range s.DPs {
+		if opts.cancelled(i) {
+			break
+		}
+		if nameless(dp.name, nil) {
+			continue
+		}
 		xs.add(&item{
 			value:  dp,
-			weight: jaroWinkler(dp.name, name),
+			weight: opts.scoreName(false, dp.name, dp.exact, name),
 		})
 	}
 
@@ -339,7 +520,7 @@ func (s *searcher) TopDPs(limit int, name string) []DP {
 				continue
 			}
 			dp := *ss
-			dp.match = v.weight
+			dp.match, dp.adjustment = adjustSourceScore(sourceDPL, v.weight)
 			out = append(out, dp)
 		}
 	}
@@ -347,7 +528,7 @@ func (s *searcher) TopDPs(limit int, name string) []DP {
 }
 
 // TopSSIs searches Sectoral Sanctions records by Name and Alias
-func (s *searcher) TopSSIs(limit int, name string) []SSI {
+func (s *searcher) TopSSIs(limit int, name string, opts searchOptions) []SSI {
 	name = precompute(name)
 
 	s.RLock()
@@ -356,20 +537,35 @@ func (s *searcher) TopSSIs(limit int, name string) []SSI {
 	if len(s.SSIs) == 0 {
 		return nil
 	}
-	xs := newLargest(limit)
+	xs := opts.largest(limit, sourceSSI)
+	person := nameHonorifics.strip(name) // individuals are indexed without titles
+	entity := nameArticles.strip(name)   // entities are indexed without leading articles
 
-	for _, ssi := range s.SSIs {
+	for i, ssi := range s.SSIs {
+		if opts.cancelled(i) {
+			break
+		}
+		if nameless(ssi.name, ssi.SectoralSanction.AlternateNames) {
+			continue
+		}
+		query := entity
+		individual := strings.EqualFold(ssi.SectoralSanction.Type, "individual")
+		if individual {
+			query = person
+		}
 		it := &item{
 			value:  ssi,
-			weight: jaroWinkler(ssi.name, name),
+			weight: opts.scoreName(individual, ssi.name, ssi.exact, query),
+			boost:  opts.inProgramBoost(ssi.SectoralSanction.Programs),
 		}
 		for _, alt := range ssi.SectoralSanction.AlternateNames {
 			if alt == "" {
 				continue
 			}
-			currWeight := jaroWinkler(alt, name)
+			currWeight := opts.scoreRecord(individual, alt, query)
 			if currWeight > it.weight {
 				it.weight = currWeight
+				it.alias = alt
 			}
 		}
 		xs.add(it)
@@ -383,7 +579,10 @@ func (s *searcher) TopSSIs(limit int, name string) []SSI {
 				continue
 			}
 			ssi := *ss
-			ssi.match = v.weight
+			ssi.match, ssi.adjustment = adjustSourceScore(sourceSSI, v.weight)
+			if opts.matchedName && v.alias != "" {
+				ssi.alias = matchedAlias{v.alias, aliasTypeAKA}
+			}
 			out = append(out, ssi)
 		}
 	}
@@ -391,7 +590,7 @@ func (s *searcher) TopSSIs(limit int, name string) []SSI {
 }
 
 // TopBISEntities searches BIS Entity List records by name and alias
-func (s *searcher) TopBISEntities(limit int, name string) []BISEntity {
+func (s *searcher) TopBISEntities(limit int, name string, opts searchOptions) []BISEntity {
 	name = precompute(name)
 
 	s.RLock()
@@ -401,20 +600,27 @@ func (s *searcher) TopBISEntities(limit int, name string) []BISEntity {
 		return nil
 	}
 
-	xs := newLargest(limit)
+	xs := opts.largest(limit, sourceEL)
 
-	for _, el := range s.BISEntities {
+	for i, el := range s.BISEntities {
+		if opts.cancelled(i) {
+			break
+		}
+		if nameless(el.name, el.Entity.AlternateNames) {
+			continue
+		}
 		it := &item{
 			value:  el,
-			weight: jaroWinkler(el.name, name),
+			weight: opts.scoreName(false, el.name, el.exact, name),
 		}
 		for _, alt := range el.Entity.AlternateNames {
 			if alt == "" {
 				continue
 			}
-			currWeight := jaroWinkler(alt, name)
+			currWeight := opts.score(alt, name)
 			if currWeight > it.weight {
 				it.weight = currWeight
+				it.alias = alt
 			}
 		}
 		xs.add(it)
@@ -428,13 +634,348 @@ func (s *searcher) TopBISEntities(limit int, name string) []BISEntity {
 				continue
 			}
 			el := *ss
-			el.match = v.weight
+			el.match, el.adjustment = adjustSourceScore(sourceEL, v.weight)
+			if opts.matchedName && v.alias != "" {
+				el.alias = matchedAlias{v.alias, aliasTypeAKA}
+			}
 			out = append(out, el)
 		}
 	}
 	return out
 }
 
+// TopISNs searches State Department Nonproliferation Sanctions records by name and alias
+func (s *searcher) TopISNs(limit int, name string, opts searchOptions) []ISN {
+	name = precompute(name)
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if len(s.ISNs) == 0 {
+		return nil
+	}
+
+	xs := opts.largest(limit, sourceISN)
+
+	for i, isn := range s.ISNs {
+		if opts.cancelled(i) {
+			break
+		}
+		if nameless(isn.name, isn.Sanction.AlternateNames) {
+			continue
+		}
+		it := &item{
+			value:  isn,
+			weight: opts.scoreName(false, isn.name, isn.exact, name),
+			boost:  opts.inProgramBoost(isn.Sanction.Programs),
+		}
+		for _, alt := range isn.Sanction.AlternateNames {
+			if alt == "" {
+				continue
+			}
+			currWeight := opts.score(alt, name)
+			if currWeight > it.weight {
+				it.weight = currWeight
+				it.alias = alt
+			}
+		}
+		xs.add(it)
+	}
+
+	out := make([]ISN, 0)
+	for _, thisItem := range xs.items {
+		if v := thisItem; v != nil {
+			ss, ok := v.value.(*ISN)
+			if !ok {
+				continue
+			}
+			isn := *ss
+			isn.match, isn.adjustment = adjustSourceScore(sourceISN, v.weight)
+			if opts.matchedName && v.alias != "" {
+				isn.alias = matchedAlias{v.alias, aliasTypeAKA}
+			}
+			out = append(out, isn)
+		}
+	}
+	return out
+}
+
+// TopCanadianSanctions searches Canada's Consolidated Autonomous Sanctions List by name and alias
+func (s *searcher) TopCanadianSanctions(limit int, name string, opts searchOptions) []CanadianSanction {
+	name = precompute(name)
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if len(s.CanadianSanctions) == 0 {
+		return nil
+	}
+
+	xs := opts.largest(limit, sourceCA)
+	person := nameHonorifics.strip(name) // individuals are indexed without titles
+	entity := nameArticles.strip(name)   // entities are indexed without leading articles
+
+	for i, entry := range s.CanadianSanctions {
+		if opts.cancelled(i) {
+			break
+		}
+		if nameless(entry.name, entry.Entry.Aliases) {
+			continue
+		}
+		query := entity
+		individual := entry.Entry.Type == ca.TypeIndividual
+		if individual {
+			query = person
+		}
+		it := &item{
+			value:  entry,
+			weight: opts.scoreName(individual, entry.name, entry.exact, query),
+		}
+		for _, alt := range entry.Entry.Aliases {
+			if alt == "" {
+				continue
+			}
+			currWeight := opts.scoreRecord(individual, alt, query)
+			if currWeight > it.weight {
+				it.weight = currWeight
+				it.alias = alt
+			}
+		}
+		matched := entry.name
+		if it.alias != "" {
+			matched = it.alias
+		}
+		if !opts.enoughNameTokens(individual, matched, query) {
+			continue
+		}
+		xs.add(it)
+	}
+
+	out := make([]CanadianSanction, 0)
+	for _, thisItem := range xs.items {
+		if v := thisItem; v != nil {
+			ss, ok := v.value.(*CanadianSanction)
+			if !ok {
+				continue
+			}
+			entry := *ss
+			entry.match, entry.adjustment = adjustSourceScore(sourceCA, v.weight)
+			if opts.matchedName && v.alias != "" {
+				entry.alias = matchedAlias{v.alias, aliasTypeAKA}
+			}
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// TopAustralianSanctions searches DFAT's Consolidated List by name and alias
+func (s *searcher) TopAustralianSanctions(limit int, name string, opts searchOptions) []AustralianSanction {
+	name = precompute(name)
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if len(s.AustralianSanctions) == 0 {
+		return nil
+	}
+
+	xs := opts.largest(limit, sourceAU)
+	person := nameHonorifics.strip(name) // individuals are indexed without titles
+	entity := nameArticles.strip(name)   // entities are indexed without leading articles
+
+	for i, entry := range s.AustralianSanctions {
+		if opts.cancelled(i) {
+			break
+		}
+		if nameless(entry.name, entry.Entry.Aliases) {
+			continue
+		}
+		query := entity
+		individual := entry.Entry.Type == au.TypeIndividual
+		if individual {
+			query = person
+		}
+		it := &item{
+			value:  entry,
+			weight: opts.scoreName(individual, entry.name, entry.exact, query),
+		}
+		for _, alt := range entry.Entry.Aliases {
+			if alt == "" {
+				continue
+			}
+			currWeight := opts.scoreRecord(individual, alt, query)
+			if currWeight > it.weight {
+				it.weight = currWeight
+				it.alias = alt
+			}
+		}
+		matched := entry.name
+		if it.alias != "" {
+			matched = it.alias
+		}
+		if !opts.enoughNameTokens(individual, matched, query) {
+			continue
+		}
+		xs.add(it)
+	}
+
+	out := make([]AustralianSanction, 0)
+	for _, thisItem := range xs.items {
+		if v := thisItem; v != nil {
+			ss, ok := v.value.(*AustralianSanction)
+			if !ok {
+				continue
+			}
+			entry := *ss
+			entry.match, entry.adjustment = adjustSourceScore(sourceAU, v.weight)
+			if opts.matchedName && v.alias != "" {
+				entry.alias = matchedAlias{v.alias, aliasTypeAKA}
+			}
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// TopUnitedNationsSanctions searches the UN Security Council Consolidated List by name and alias
+func (s *searcher) TopUnitedNationsSanctions(limit int, name string, opts searchOptions) []UnitedNationsSanction {
+	name = precompute(name)
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if len(s.UnitedNationsSanctions) == 0 {
+		return nil
+	}
+
+	xs := opts.largest(limit, sourceUN)
+	person := nameHonorifics.strip(name) // individuals are indexed without titles
+	entity := nameArticles.strip(name)   // entities are indexed without leading articles
+
+	for i, entry := range s.UnitedNationsSanctions {
+		if opts.cancelled(i) {
+			break
+		}
+		if nameless(entry.name, entry.Entry.Aliases) {
+			continue
+		}
+		query := entity
+		individual := entry.Entry.Type == un.TypeIndividual
+		if individual {
+			query = person
+		}
+		it := &item{
+			value:  entry,
+			weight: opts.scoreName(individual, entry.name, entry.exact, query),
+		}
+		for _, alt := range entry.Entry.Aliases {
+			if alt == "" {
+				continue
+			}
+			currWeight := opts.scoreRecord(individual, alt, query)
+			if currWeight > it.weight {
+				it.weight = currWeight
+				it.alias = alt
+			}
+		}
+		matched := entry.name
+		if it.alias != "" {
+			matched = it.alias
+		}
+		if !opts.enoughNameTokens(individual, matched, query) {
+			continue
+		}
+		xs.add(it)
+	}
+
+	out := make([]UnitedNationsSanction, 0)
+	for _, thisItem := range xs.items {
+		if v := thisItem; v != nil {
+			ss, ok := v.value.(*UnitedNationsSanction)
+			if !ok {
+				continue
+			}
+			entry := *ss
+			entry.match, entry.adjustment = adjustSourceScore(sourceUN, v.weight)
+			if opts.matchedName && v.alias != "" {
+				entry.alias = matchedAlias{v.alias, aliasTypeAKA}
+			}
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// TopCustomEntries searches the custom list by name and alias
+func (s *searcher) TopCustomEntries(limit int, name string, opts searchOptions) []CustomEntry {
+	name = precompute(name)
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if len(s.CustomEntries) == 0 {
+		return nil
+	}
+
+	xs := opts.largest(limit, sourceCustom)
+	person := nameHonorifics.strip(name) // individuals are indexed without titles
+	entity := nameArticles.strip(name)   // entities are indexed without leading articles
+
+	for i, entry := range s.CustomEntries {
+		if opts.cancelled(i) {
+			break
+		}
+		if nameless(entry.name, entry.Entry.Aliases) {
+			continue
+		}
+		query := entity
+		individual := entry.Entry.Type == custom.TypeIndividual
+		if individual {
+			query = person
+		}
+		it := &item{
+			value:  entry,
+			weight: opts.scoreName(individual, entry.name, entry.exact, query),
+		}
+		for _, alt := range entry.Entry.Aliases {
+			if alt == "" {
+				continue
+			}
+			currWeight := opts.scoreRecord(individual, alt, query)
+			if currWeight > it.weight {
+				it.weight = currWeight
+				it.alias = alt
+			}
+		}
+		matched := entry.name
+		if it.alias != "" {
+			matched = it.alias
+		}
+		if !opts.enoughNameTokens(individual, matched, query) {
+			continue
+		}
+		xs.add(it)
+	}
+
+	out := make([]CustomEntry, 0)
+	for _, thisItem := range xs.items {
+		if v := thisItem; v != nil {
+			ss, ok := v.value.(*CustomEntry)
+			if !ok {
+				continue
+			}
+			entry := *ss
+			entry.match, entry.adjustment = adjustSourceScore(sourceCustom, v.weight)
+			if opts.matchedName && v.alias != "" {
+				entry.alias = matchedAlias{v.alias, aliasTypeAKA}
+			}
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
 // SDN is ofac.SDN wrapped with precomputed search metadata
 type SDN struct {
 	*ofac.SDN
@@ -442,25 +983,90 @@ type SDN struct {
 	// match holds the match ratio for an SDN in search results
 	match float64
 
+	// highlights are optionally computed token alignments against the query
+	highlights []tokenMatch
+
 	// name is precomputed for speed
 	name string
 
+	// exact is the name before the rest of the pipeline (see exactName), it's empty when it's
+	// the same as name
+	exact string
+
+	// scriptNames are native script forms of the name found in remarks (see remarkScriptNames)
+	scriptNames []scriptName
+
+	// components of an individual's name, nil when they're unknown (see sdnNameComponents)
+	components *nameComponents
+
+	// familyFirst is the name of a CJK individual in its native order (see cjkFamilyFirst)
+	familyFirst string
+
+	// titles are the individual's titles and positions (see sdnTitles)
+	titles []string
+
 	// id is the parseed ID value from an SDN's remarks field. Often this
 	// is a National ID, Drivers License, or similar government value
 	// ueed to uniquely identify an entiy.
 	//
 	// Typically the form of this is 'No. NNNNN' where NNNNN is alphanumeric.
 	id string
+
+	// hideRemarks leaves the raw remarks out of search results unless ?includeRemarks=true
+	hideRemarks bool
+
+	// risk is the tier of the SDN's programs, when they're mapped
+	risk programRisk
+
+	// adjustment is set when SOURCE_SCORE_MULTIPLIERS changed match
+	adjustment sourceAdjustment
+
+	// addresses of the SDN, they're only included in results with ?includeAddresses=true along
+	// with which one matchedAddressID matched
+	addresses        []*ofac.Address
+	showAddresses    bool
+	matchedAddressID string
+
+	// showDetails includes the fields parsed from remarks, with ?includeDetails=true
+	showDetails bool
+
+	// alias is the alt name which produced the match with INDEX_ALT_NAMES and ?matchedName=true
+	alias matchedAlias
+
+	// qualifiers are the parentheticals stripped from the name (see NAME_PARENTHETICALS)
+	qualifiers nameQualifiers
 }
 
 // MarshalJSON is a custom method for marshaling a SDN search result
 func (s SDN) MarshalJSON() ([]byte, error) {
+	var remarks *string
+	if s.SDN != nil && !s.hideRemarks {
+		remarks = &s.SDN.Remarks
+	}
 	return json.Marshal(struct {
 		*ofac.SDN
-		Match float64 `json:"match"`
+		Remarks    *string      `json:"remarks,omitempty"` // replaces the embedded field
+		Match      float64      `json:"match"`
+		Titles     []string     `json:"titles,omitempty"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		Addresses  []sdnAddress `json:"addresses,omitempty"`
+		Details    *sdnDetails  `json:"details,omitempty"`
+		matchedAlias
+		programRisk
+		sourceAdjustment
+		nameQualifiers
 	}{
 		s.SDN,
+		remarks,
 		s.match,
+		s.titles,
+		s.highlights,
+		s.sdnAddresses(),
+		s.sdnDetails(),
+		s.alias,
+		s.risk,
+		s.adjustment,
+		s.qualifiers,
 	})
 }
 
@@ -477,17 +1083,28 @@ func findAddresses(entityID string, addrs []*ofac.Address) []*ofac.Address {
 func precomputeSDNs(sdns []*ofac.SDN, addrs []*ofac.Address, pipe *pipeliner) []*SDN {
 	out := make([]*SDN, len(sdns))
 	for i := range sdns {
-		nn := sdnName(sdns[i], findAddresses(sdns[i].EntityID, addrs))
+		addresses := findAddresses(sdns[i].EntityID, addrs)
+		nn := sdnName(sdns[i], addresses)
 
 		if err := pipe.Do(nn); err != nil {
 			pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining SDN: %v", err))
 			continue
 		}
 
+		scriptNames := remarkScriptNames(sdns[i].Remarks)
+		components := sdnNameComponents(sdns[i])
 		out[i] = &SDN{
-			SDN:  sdns[i],
-			name: nn.Processed,
-			id:   extractIDFromRemark(strings.TrimSpace(sdns[i].Remarks)),
+			SDN:         sdns[i],
+			name:        nn.Processed,
+			exact:       exactName(nn),
+			qualifiers:  nn.qualifiers,
+			scriptNames: scriptNames,
+			components:  components,
+			familyFirst: cjkFamilyFirst(sdns[i], scriptNames, components),
+			titles:      sdnTitles(sdns[i]),
+			id:          extractIDFromRemark(strings.TrimSpace(sdns[i].Remarks)),
+			risk:        programRisk{programRisks.tier(sdns[i].Programs)},
+			addresses:   addresses,
 		}
 	}
 	return out
@@ -499,8 +1116,20 @@ type Address struct {
 
 	match float64 // match %
 
+	// coordinates are only set when addresses are geocoded
+	coordinates coordinates
+
+	adjustment sourceAdjustment
+
+	// duplicate is set when an earlier address of the same SDN is the same place, duplicates
+	// aren't returned by address searches
+	duplicate bool
+
 	// precomputed fields for speed
 	address, citystate, country string
+
+	// components of CityStateProvincePostalCode
+	city, state, postal string
 }
 
 // MarshalJSON is a custom method for marshaling a SDN Address search result
@@ -508,30 +1137,91 @@ func (a Address) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		*ofac.Address
 		Match float64 `json:"match"`
+		coordinates
+		sourceAdjustment
 	}{
 		a.Address,
 		a.match,
+		a.coordinates,
+		a.adjustment,
 	})
 }
 
+// searchNamelessRecords includes records whose name (and every alias) is empty after
+// normalization in name searches. By default they're skipped and only found by ID or address,
+// main sets it from SEARCH_NAMELESS_RECORDS.
+var searchNamelessRecords = false
+
+// nameless returns true when a record is skipped by name searches as it has no name to compare
+func nameless(name string, aliases []string) bool {
+	if searchNamelessRecords || name != "" {
+		return false
+	}
+	for i := range aliases {
+		if aliases[i] != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// dedupeSDNAddresses marks addresses which are the same place as another address of their SDN
+// (once normalized) as duplicates, main disables it with DEDUPE_SDN_ADDRESSES=false
+var dedupeSDNAddresses = true
+
 func precomputeAddresses(adds []*ofac.Address) []*Address {
 	out := make([]*Address, len(adds))
+	seen := make(map[string]bool)
 	for i := range adds {
+		street, citystate := adds[i].Address, adds[i].CityStateProvincePostalCode
+		if normalizeAddresses {
+			street, citystate = normalizeAddress(street), normalizeAddress(citystate)
+		}
+		city, state, postal := splitCityStateProvincePostalCode(citystate)
 		out[i] = &Address{
-			Address:   adds[i],
-			address:   precompute(adds[i].Address),
-			citystate: precompute(adds[i].CityStateProvincePostalCode),
-			country:   precompute(adds[i].Country),
+			Address:     adds[i],
+			coordinates: geocodeAddress(addressGeocoder, adds[i]),
+			address:     precompute(street),
+			citystate:   precompute(citystate),
+			country:     precompute(adds[i].Country),
+			city:        precompute(city),
+			state:       precompute(state),
+			postal:      normalizePostalCode(postal, adds[i].Country),
+		}
+		if dedupeSDNAddresses {
+			key := out[i].Address.EntityID + "|" + out[i].placeKey()
+			out[i].duplicate = seen[key]
+			seen[key] = true
 		}
 	}
 	return out
 }
 
+// placeKey is the same for addresses which only differ by case, punctuation, accents or spacing,
+// including when a part of the address is split into a different field
+func (a *Address) placeKey() string {
+	return strings.Replace(a.address+a.citystate+a.country, " ", "", -1)
+}
+
+// aliasTypeAKA is the alias type of lists which don't classify their aliases
+const aliasTypeAKA = "aka"
+
+// matchedAlias is set on results whose best comparison was against an alias instead of the
+// primary name, when the search asked for matchedName.
+type matchedAlias struct {
+	MatchedName     string `json:"matchedName,omitempty"`
+	MatchedNameType string `json:"matchedNameType,omitempty"`
+}
+
 // Alt is an ofac.AlternateIdentity wrapped with precomputed search metadata
 type Alt struct {
 	AlternateIdentity *ofac.AlternateIdentity
 
-	match float64 // match %
+	match      float64 // match %
+	highlights []tokenMatch
+	alias      matchedAlias
+	adjustment sourceAdjustment
+	qualifiers nameQualifiers
 
 	// name is precomputed for speed
 	name string
@@ -541,19 +1231,29 @@ type Alt struct {
 func (a Alt) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		*ofac.AlternateIdentity
-		Match float64 `json:"match"`
+		Match      float64      `json:"match"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		matchedAlias
+		sourceAdjustment
+		nameQualifiers
 	}{
 		a.AlternateIdentity,
 		a.match,
+		a.highlights,
+		a.alias,
+		a.adjustment,
+		a.qualifiers,
 	})
 }
 
 func precomputeAlts(alts []*ofac.AlternateIdentity) []*Alt {
 	out := make([]*Alt, len(alts))
 	for i := range alts {
+		name, qualifiers := cleanParentheticals(alts[i].AlternateName)
 		out[i] = &Alt{
 			AlternateIdentity: alts[i],
-			name:              precompute(alts[i].AlternateName),
+			name:              precompute(name),
+			qualifiers:        nameQualifiers{qualifiers},
 		}
 	}
 	return out
@@ -563,17 +1263,27 @@ func precomputeAlts(alts []*ofac.AlternateIdentity) []*Alt {
 type DP struct {
 	DeniedPerson *dpl.DPL
 	match        float64
+	highlights   []tokenMatch
+	adjustment   sourceAdjustment
+	qualifiers   nameQualifiers
 	name         string
+	exact        string
 }
 
 // MarshalJSON is a custom method for marshaling a BIS Denied Person (DP)
 func (d DP) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		*dpl.DPL
-		Match float64 `json:"match"`
+		Match      float64      `json:"match"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		sourceAdjustment
+		nameQualifiers
 	}{
 		d.DeniedPerson,
 		d.match,
+		d.highlights,
+		d.adjustment,
+		d.qualifiers,
 	})
 }
 
@@ -588,6 +1298,8 @@ func precomputeDPs(persons []*dpl.DPL, pipe *pipeliner) []*DP {
 		out[i] = &DP{
 			DeniedPerson: persons[i],
 			name:         nn.Processed,
+			exact:        exactName(nn),
+			qualifiers:   nn.qualifiers,
 		}
 	}
 	return out
@@ -596,16 +1308,32 @@ func precomputeDPs(persons []*dpl.DPL, pipe *pipeliner) []*DP {
 type SSI struct {
 	SectoralSanction *csl.SSI
 	match            float64
+	highlights       []tokenMatch
+	alias            matchedAlias
+	risk             programRisk
+	adjustment       sourceAdjustment
+	qualifiers       nameQualifiers
 	name             string
+	exact            string
 }
 
 func (s SSI) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		*csl.SSI
-		Match float64 `json:"match"`
+		Match      float64      `json:"match"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		matchedAlias
+		programRisk
+		sourceAdjustment
+		nameQualifiers
 	}{
 		s.SectoralSanction,
 		s.match,
+		s.highlights,
+		s.alias,
+		s.risk,
+		s.adjustment,
+		s.qualifiers,
 	})
 }
 
@@ -618,6 +1346,7 @@ func precomputeSSIs(ssis []*csl.SSI, pipe *pipeliner) []*SSI {
 			continue
 		}
 
+		ssi.AlternateNames = append(ssi.AlternateNames, nn.aliases...)
 		var altNames []string
 		for i := range ssi.AlternateNames {
 			altNN := &Name{Processed: ssi.AlternateNames[i]}
@@ -631,25 +1360,41 @@ func precomputeSSIs(ssis []*csl.SSI, pipe *pipeliner) []*SSI {
 
 		out[i] = &SSI{
 			SectoralSanction: ssi,
+			risk:             programRisk{programRisks.tier(ssi.Programs)},
 			name:             nn.Processed,
+			exact:            exactName(nn),
+			qualifiers:       nn.qualifiers,
 		}
 	}
 	return out
 }
 
 type BISEntity struct {
-	Entity *csl.EL
-	match  float64
-	name   string
+	Entity     *csl.EL
+	match      float64
+	highlights []tokenMatch
+	alias      matchedAlias
+	adjustment sourceAdjustment
+	qualifiers nameQualifiers
+	name       string
+	exact      string
 }
 
 func (e BISEntity) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
 		*csl.EL
-		Match float64 `json:"match"`
+		Match      float64      `json:"match"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		matchedAlias
+		sourceAdjustment
+		nameQualifiers
 	}{
 		e.Entity,
 		e.match,
+		e.highlights,
+		e.alias,
+		e.adjustment,
+		e.qualifiers,
 	})
 }
 
@@ -662,6 +1407,7 @@ func precomputeBISEntities(els []*csl.EL, pipe *pipeliner) []*BISEntity {
 			continue
 		}
 
+		el.AlternateNames = append(el.AlternateNames, nn.aliases...)
 		var altNames []string
 		for i := range el.AlternateNames {
 			altNN := &Name{Processed: el.AlternateNames[i]}
@@ -674,8 +1420,10 @@ func precomputeBISEntities(els []*csl.EL, pipe *pipeliner) []*BISEntity {
 		el.AlternateNames = altNames
 
 		out[i] = &BISEntity{
-			Entity: el,
-			name:   nn.Processed,
+			Entity:     el,
+			name:       nn.Processed,
+			exact:      exactName(nn),
+			qualifiers: nn.qualifiers,
 		}
 	}
 	return out
@@ -700,13 +1448,36 @@ func extractSearchLimit(r *http.Request) int {
 //
 // For more details see https://en.wikipedia.org/wiki/Jaro%E2%80%93Winkler_distance
 func jaroWinkler(s1, s2 string) float64 {
+	return averageTokenScores(s1, s2, func(a, b string) float64 {
+		return smetrics.JaroWinkler(a, b, 0.7, 4)
+	})
+}
+
+// fullJaroWinkler scores s1 and s2 as whole strings, so unlike jaroWinkler reordered tokens
+// score lower. It's a single comparison per name instead of one for every pair of tokens.
+func fullJaroWinkler(s1, s2 string) float64 {
+	if s1 == "" || s2 == "" {
+		return 0.0
+	}
+	return smetrics.JaroWinkler(s1, s2, 0.7, 4)
+}
+
+// jaroWinklerUpperBound is never less than jaroWinkler(s1, s2) but only compares the lengths,
+// characters and prefixes of each token, so it's much cheaper to compute.
+func jaroWinklerUpperBound(s1, s2 string) float64 {
+	return averageTokenScores(s1, s2, tokenUpperBound)
+}
+
+// averageTokenScores scores each token of s1 against its best match in s2 and averages the
+// highest scores, which is how jaroWinkler combines scores of each word
+func averageTokenScores(s1, s2 string, score func(a, b string) float64) float64 {
 	maxMatch := func(word string, parts []string) float64 {
 		if len(parts) == 0 {
 			return 0.0
 		}
-		max := smetrics.JaroWinkler(word, parts[0], 0.7, 4)
+		max := score(word, parts[0])
 		for i := 1; i < len(parts); i++ {
-			if score := smetrics.JaroWinkler(word, parts[i], 0.7, 4); score > max {
+			if score := score(word, parts[i]); score > max {
 				max = score
 			}
 		}
@@ -736,6 +1507,72 @@ func jaroWinkler(s1, s2 string) float64 {
 	return sum / float64(len(scores))
 }
 
+// tokenUpperBound is the highest smetrics.JaroWinkler(a, b, 0.7, 4) could return. Jaro can't
+// match more characters than the tokens have in common and the Winkler boost only applies to
+// a common prefix, so tokens starting with different characters are never boosted.
+func tokenUpperBound(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0.0
+	}
+	var counts [256]int
+	for i := 0; i < len(a); i++ {
+		counts[a[i]]++
+	}
+	var common float64
+	for i := 0; i < len(b); i++ {
+		if counts[b[i]] > 0 {
+			counts[b[i]]--
+			common++
+		}
+	}
+	if common == 0 {
+		return 0.0
+	}
+	// assume every common character matches without transpositions
+	j := (common/float64(len(a)) + common/float64(len(b)) + 1.0) / 3.0
+	if j <= 0.7 {
+		return j
+	}
+	var prefix float64
+	for i := 0; i < 4 && i < len(a) && i < len(b) && a[i] == b[i]; i++ {
+		prefix++
+	}
+	return j + 0.1*prefix*(1.0-j)
+}
+
+// tokenMatch pairs a token from the user's query with the best matching token
+// from an indexed name. It's used to highlight matched portions of results.
+type tokenMatch struct {
+	Query     string  `json:"query"`
+	Candidate string  `json:"candidate"`
+	Score     float64 `json:"score"`
+}
+
+// alignTokens returns the best matching token (and score) from indexed for
+// each token in query. Both strings are expected to be precomputed.
+//
+// Tokens are compared the same way jaroWinkler does, so the alignment reflects
+// how the result was scored regardless of word order.
+func alignTokens(indexed, query string) []tokenMatch {
+	indexedParts, queryParts := strings.Fields(indexed), strings.Fields(query)
+	if len(indexedParts) == 0 || len(queryParts) == 0 {
+		return nil
+	}
+
+	out := make([]tokenMatch, 0, len(queryParts))
+	for i := range queryParts {
+		best := tokenMatch{Query: queryParts[i]}
+		for j := range indexedParts {
+			if score := smetrics.JaroWinkler(indexedParts[j], queryParts[i], 0.7, 4); score > best.Score {
+				best.Candidate = indexedParts[j]
+				best.Score = score
+			}
+		}
+		out = append(out, best)
+	}
+	return out
+}
+
 // extractIDFromRemark attempts to parse out a National ID or similar governmental ID value
 // from an SDN's remarks property.
 //
@@ -748,7 +1585,7 @@ func extractIDFromRemark(remarks string) string {
 	var out bytes.Buffer
 	parts := strings.Fields(remarks)
 	for i := range parts {
-		if parts[i] == "No." {
+		if parts[i] == "No." && i+1 < len(parts) {
 			trimmed := strings.TrimSuffix(strings.TrimSuffix(parts[i+1], "."), ";")
 
 			// Always take the next part
@@ -766,7 +1603,332 @@ func extractIDFromRemark(remarks string) string {
 					out.WriteString(" " + parts[j])
 				}
 			}
+			// the rest of remarks were read, so don't rescan them from the next "No."
+			return out.String()
 		}
 	}
 	return out.String()
 }
+
+type ISN struct {
+	Sanction   *csl.ISN
+	match      float64
+	highlights []tokenMatch
+	alias      matchedAlias
+	risk       programRisk
+	adjustment sourceAdjustment
+	qualifiers nameQualifiers
+	name       string
+	exact      string
+}
+
+func (i ISN) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*csl.ISN
+		Match      float64      `json:"match"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		matchedAlias
+		programRisk
+		sourceAdjustment
+		nameQualifiers
+	}{
+		i.Sanction,
+		i.match,
+		i.highlights,
+		i.alias,
+		i.risk,
+		i.adjustment,
+		i.qualifiers,
+	})
+}
+
+func precomputeISNs(isns []*csl.ISN, pipe *pipeliner) []*ISN {
+	var out []*ISN
+	for _, isn := range isns {
+		if isn == nil {
+			continue
+		}
+		nn := isnName(isn)
+		if err := pipe.Do(nn); err != nil {
+			pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining ISN: %v", err))
+			continue
+		}
+
+		isn.AlternateNames = append(isn.AlternateNames, nn.aliases...)
+		var altNames []string
+		for i := range isn.AlternateNames {
+			altNN := &Name{Processed: isn.AlternateNames[i]}
+			if err := pipe.Do(altNN); err != nil {
+				pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining alt: %v", err))
+				continue
+			}
+			altNames = append(altNames, altNN.Processed)
+		}
+		isn.AlternateNames = altNames
+
+		out = append(out, &ISN{
+			Sanction:   isn,
+			risk:       programRisk{programRisks.tier(isn.Programs)},
+			name:       nn.Processed,
+			exact:      exactName(nn),
+			qualifiers: nn.qualifiers,
+		})
+	}
+	return out
+}
+
+// CanadianSanction is a ca.Entry wrapped with precomputed search metadata
+type CanadianSanction struct {
+	Entry      *ca.Entry
+	match      float64
+	highlights []tokenMatch
+	alias      matchedAlias
+	adjustment sourceAdjustment
+	qualifiers nameQualifiers
+	name       string
+	exact      string
+}
+
+func (c CanadianSanction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*ca.Entry
+		Match      float64      `json:"match"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		matchedAlias
+		sourceAdjustment
+		nameQualifiers
+	}{
+		c.Entry,
+		c.match,
+		c.highlights,
+		c.alias,
+		c.adjustment,
+		c.qualifiers,
+	})
+}
+
+func precomputeCanadianSanctions(entries []*ca.Entry, pipe *pipeliner) []*CanadianSanction {
+	var out []*CanadianSanction
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		nn := canadianSanctionName(entry)
+		if err := pipe.Do(nn); err != nil {
+			pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining CA entry: %v", err))
+			continue
+		}
+
+		entry.Aliases = append(entry.Aliases, nn.aliases...)
+		var aliases []string
+		for i := range entry.Aliases {
+			altNN := &Name{Processed: entry.Aliases[i]}
+			if err := pipe.Do(altNN); err != nil {
+				pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining alt: %v", err))
+				continue
+			}
+			aliases = append(aliases, altNN.Processed)
+		}
+		entry.Aliases = aliases
+
+		out = append(out, &CanadianSanction{
+			Entry:      entry,
+			name:       nn.Processed,
+			exact:      exactName(nn),
+			qualifiers: nn.qualifiers,
+		})
+	}
+	return out
+}
+
+// AustralianSanction is an au.Entry wrapped with precomputed search metadata
+type AustralianSanction struct {
+	Entry      *au.Entry
+	match      float64
+	highlights []tokenMatch
+	alias      matchedAlias
+	adjustment sourceAdjustment
+	qualifiers nameQualifiers
+	name       string
+	exact      string
+}
+
+func (a AustralianSanction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*au.Entry
+		Match      float64      `json:"match"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		matchedAlias
+		sourceAdjustment
+		nameQualifiers
+	}{
+		a.Entry,
+		a.match,
+		a.highlights,
+		a.alias,
+		a.adjustment,
+		a.qualifiers,
+	})
+}
+
+func precomputeAustralianSanctions(entries []*au.Entry, pipe *pipeliner) []*AustralianSanction {
+	var out []*AustralianSanction
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		nn := australianSanctionName(entry)
+		if err := pipe.Do(nn); err != nil {
+			pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining AU entry: %v", err))
+			continue
+		}
+
+		entry.Aliases = append(entry.Aliases, nn.aliases...)
+		var aliases []string
+		for i := range entry.Aliases {
+			altNN := &Name{Processed: entry.Aliases[i]}
+			if err := pipe.Do(altNN); err != nil {
+				pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining alt: %v", err))
+				continue
+			}
+			aliases = append(aliases, altNN.Processed)
+		}
+		entry.Aliases = aliases
+
+		out = append(out, &AustralianSanction{
+			Entry:      entry,
+			name:       nn.Processed,
+			exact:      exactName(nn),
+			qualifiers: nn.qualifiers,
+		})
+	}
+	return out
+}
+
+// UnitedNationsSanction is a un.Entry wrapped with precomputed search metadata
+type UnitedNationsSanction struct {
+	Entry      *un.Entry
+	match      float64
+	highlights []tokenMatch
+	alias      matchedAlias
+	adjustment sourceAdjustment
+	qualifiers nameQualifiers
+	name       string
+	exact      string
+}
+
+func (u UnitedNationsSanction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*un.Entry
+		Match      float64      `json:"match"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		matchedAlias
+		sourceAdjustment
+		nameQualifiers
+	}{
+		u.Entry,
+		u.match,
+		u.highlights,
+		u.alias,
+		u.adjustment,
+		u.qualifiers,
+	})
+}
+
+func precomputeUnitedNationsSanctions(entries []*un.Entry, pipe *pipeliner) []*UnitedNationsSanction {
+	var out []*UnitedNationsSanction
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		nn := unitedNationsSanctionName(entry)
+		if err := pipe.Do(nn); err != nil {
+			pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining UN entry: %v", err))
+			continue
+		}
+
+		entry.Aliases = append(entry.Aliases, nn.aliases...)
+		var aliases []string
+		for i := range entry.Aliases {
+			altNN := &Name{Processed: entry.Aliases[i]}
+			if err := pipe.Do(altNN); err != nil {
+				pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining alt: %v", err))
+				continue
+			}
+			aliases = append(aliases, altNN.Processed)
+		}
+		entry.Aliases = aliases
+
+		out = append(out, &UnitedNationsSanction{
+			Entry:      entry,
+			name:       nn.Processed,
+			exact:      exactName(nn),
+			qualifiers: nn.qualifiers,
+		})
+	}
+	return out
+}
+
+// CustomEntry is a custom.Entry wrapped with precomputed search metadata
+type CustomEntry struct {
+	Entry      *custom.Entry
+	match      float64
+	highlights []tokenMatch
+	alias      matchedAlias
+	adjustment sourceAdjustment
+	qualifiers nameQualifiers
+	name       string
+	exact      string
+}
+
+func (c CustomEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		*custom.Entry
+		Match      float64      `json:"match"`
+		Highlights []tokenMatch `json:"highlights,omitempty"`
+		matchedAlias
+		sourceAdjustment
+		nameQualifiers
+	}{
+		c.Entry,
+		c.match,
+		c.highlights,
+		c.alias,
+		c.adjustment,
+		c.qualifiers,
+	})
+}
+
+func precomputeCustomEntries(entries []*custom.Entry, pipe *pipeliner) []*CustomEntry {
+	var out []*CustomEntry
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		nn := customEntryName(entry)
+		if err := pipe.Do(nn); err != nil {
+			pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining custom entry: %v", err))
+			continue
+		}
+
+		entry.Aliases = append(entry.Aliases, nn.aliases...)
+		var aliases []string
+		for i := range entry.Aliases {
+			altNN := &Name{Processed: entry.Aliases[i]}
+			if err := pipe.Do(altNN); err != nil {
+				pipe.logger.Log("pipeline", fmt.Sprintf("problem pipelining alt: %v", err))
+				continue
+			}
+			aliases = append(aliases, altNN.Processed)
+		}
+		entry.Aliases = aliases
+
+		out = append(out, &CustomEntry{
+			Entry:      entry,
+			name:       nn.Processed,
+			exact:      exactName(nn),
+			qualifiers: nn.qualifiers,
+		})
+	}
+	return out
+}
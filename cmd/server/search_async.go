@@ -11,6 +11,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-kit/kit/log"
@@ -22,6 +23,7 @@ var (
 
 func init() {
 	watchResearchBatchSize = readWebhookBatchSize(os.Getenv("WEBHOOK_BATCH_SIZE"))
+	webhookMaxAttempts = readWebhookMaxAttempts(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
 }
 
 func readWebhookBatchSize(str string) int {
@@ -35,19 +37,39 @@ func readWebhookBatchSize(str string) int {
 	return watchResearchBatchSize
 }
 
+func readWebhookMaxAttempts(str string) int {
+	if str == "" {
+		return webhookMaxAttempts
+	}
+	d, _ := strconv.Atoi(str)
+	if d > 0 {
+		return d
+	}
+	return webhookMaxAttempts
+}
+
 // spawnResearching will block and select on updates for when to re-inspect all watches setup.
 // Since watches are used to post list data via webhooks they are used as catalysts in other systems.
 func (s *searcher) spawnResearching(logger log.Logger, companyRepo companyRepository, custRepo customerRepository, watchRepo watchRepository, webhookRepo webhookRepository, updates chan *downloadStats) {
-	for range updates {
+	for stats := range updates {
 		s.logger.Log("search", "async: starting re-search of watches")
+		if n, err := webhookRepo.pruneDeliveries(time.Now().Add(-webhookDeliveryRetention)); err != nil {
+			s.logger.Log("search", fmt.Sprintf("async: problem pruning webhook deliveries: %v", err))
+		} else if n > 0 {
+			s.logger.Log("search", fmt.Sprintf("async: pruned %d webhook deliveries", n))
+		}
 		cursor := watchRepo.getWatchesCursor(logger, watchResearchBatchSize)
+		var deliveries sync.WaitGroup
 		for {
 			watches, _ := cursor.Next()
 			if len(watches) == 0 {
 				break
 			}
 			for i := range watches {
-				body, err := s.renderBody(watches[i], companyRepo, custRepo)
+				body, err := s.renderBody(watches[i], stats.sdnChanges, companyRepo, custRepo)
+				if err == errWatchUnchanged {
+					continue
+				}
 				if err != nil {
 					s.logger.Log("search", fmt.Sprintf("async: watch %s: %v", watches[i].id, err))
 					continue
@@ -57,59 +79,69 @@ func (s *searcher) spawnResearching(logger log.Logger, companyRepo companyReposi
 					continue
 				}
 
-				// Send HTTP webhook
-				now := time.Now()
-				status, err := callWebhook(watches[i].id, body, watches[i].webhook, watches[i].authToken)
-				if err != nil {
-					s.logger.Log("search", fmt.Errorf("async: problem writing watch (%s) webhook status: %v", watches[i].id, err))
-				}
-				if err := webhookRepo.recordWebhook(watches[i].id, now, status); err != nil {
-					s.logger.Log("search", fmt.Errorf("async: problem writing watch (%s) webhook status: %v", watches[i].id, err))
-				}
+				// Send HTTP webhooks, which wait for a free worker once webhookWorkers are busy
+				queueWebhooks(s.logger, watches[i], body.Bytes(), webhookRepo, &deliveries)
 			}
 		}
+		deliveries.Wait()
 	}
 }
 
-func (s *searcher) renderBody(w watch, companyRepo companyRepository, custRepo customerRepository) (*bytes.Buffer, error) {
+// renderBody returns the webhook body of a watch, changes are the SDNs which changed in the refresh
+// or reindex the watch is re-searched after
+func (s *searcher) renderBody(w watch, changes map[string]*sdnChange, companyRepo companyRepository, custRepo customerRepository) (*bytes.Buffer, error) {
 	// Perform a query (ID watches) or search (name watches) and encode the model in JSON for calling the webhook.
 	switch {
 	case w.customerID != "":
 		s.logger.Log("search", fmt.Sprintf("async: watch %s for customer %s found", w.id, w.customerID))
-		return getCustomerBody(s, w.id, w.customerID, 1.0, custRepo)
+		if err := notifies(changes, w.customerID); err != nil {
+			return nil, err
+		}
+		return getCustomerBody(s, w.id, w.customerID, 1.0, changes[w.customerID], custRepo)
 
 	case w.customerName != "":
-		s.logger.Log("search", fmt.Sprintf("async: name watch '%s' for customer %s found", w.customerName, w.id))
-		sdns := s.TopSDNs(5, w.customerName)
+		s.logger.Log("search", fmt.Sprintf("async: name watch '%s' for customer %s found", redact(w.customerName), w.id))
+		sdns := s.TopSDNs(5, w.customerName, searchOptions{})
 		for j := range sdns {
 			if strings.EqualFold(sdns[j].SDNType, "individual") {
-				return getCustomerBody(s, w.id, sdns[j].EntityID, sdns[j].match, custRepo)
+				if err := notifies(changes, sdns[j].EntityID); err != nil {
+					return nil, err
+				}
+				return getCustomerBody(s, w.id, sdns[j].EntityID, sdns[j].match, changes[sdns[j].EntityID], custRepo)
 			}
 		}
 
 	case w.companyID != "":
 		s.logger.Log("search", fmt.Sprintf("async: watch %s for company %s found", w.id, w.companyID))
-		return getCompanyBody(s, w.id, w.companyID, 1.0, companyRepo)
+		if err := notifies(changes, w.companyID); err != nil {
+			return nil, err
+		}
+		return getCompanyBody(s, w.id, w.companyID, 1.0, changes[w.companyID], companyRepo)
 
 	case w.companyName != "":
-		s.logger.Log("search", fmt.Sprintf("async: name watch '%s' for company %s found", w.companyName, w.id))
-		sdns := s.TopSDNs(5, w.companyName)
+		s.logger.Log("search", fmt.Sprintf("async: name watch '%s' for company %s found", redact(w.companyName), w.id))
+		sdns := s.TopSDNs(5, w.companyName, searchOptions{})
 		for j := range sdns {
 			if !strings.EqualFold(sdns[j].SDNType, "individual") {
-				return getCompanyBody(s, w.id, sdns[j].EntityID, sdns[j].match, companyRepo)
+				if err := notifies(changes, sdns[j].EntityID); err != nil {
+					return nil, err
+				}
+				return getCompanyBody(s, w.id, sdns[j].EntityID, sdns[j].match, changes[sdns[j].EntityID], companyRepo)
 			}
 		}
 	}
 	return nil, nil
 }
 
-// getCustomerBody returns the JSON encoded form of a given customer by their EntityID
-func getCustomerBody(s *searcher, watchID string, customerID string, match float64, repo customerRepository) (*bytes.Buffer, error) {
+// getCustomerBody returns the JSON encoded form of a given customer by their EntityID, with how
+// their SDN changed in the last refresh
+func getCustomerBody(s *searcher, watchID string, customerID string, match float64, change *sdnChange, repo customerRepository) (*bytes.Buffer, error) {
 	customer, _ := getCustomerByID(customerID, s, repo)
 	if customer == nil {
 		return nil, fmt.Errorf("async: watch %s customer %v not found", watchID, customerID)
 	}
 	customer.Match = match
+	customer.Changes = change
 
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(customer); err != nil {
@@ -118,13 +150,15 @@ func getCustomerBody(s *searcher, watchID string, customerID string, match float
 	return &buf, nil
 }
 
-// getCompanyBody returns the JSON encoded form of a given customer by their EntityID
-func getCompanyBody(s *searcher, watchID string, companyID string, match float64, repo companyRepository) (*bytes.Buffer, error) {
+// getCompanyBody returns the JSON encoded form of a given company by their EntityID, with how
+// their SDN changed in the last refresh
+func getCompanyBody(s *searcher, watchID string, companyID string, match float64, change *sdnChange, repo companyRepository) (*bytes.Buffer, error) {
 	company, _ := getCompanyByID(companyID, s, repo)
 	if company == nil {
 		return nil, fmt.Errorf("async: watch %s company %v not found", watchID, companyID)
 	}
 	company.Match = match
+	company.Changes = change
 
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(company); err != nil {
@@ -33,7 +33,7 @@ func TestSearchAsync__renderBody(t *testing.T) {
 	customerRepo := createTestCustomerRepository(t)
 	defer customerRepo.close()
 
-	body, err := customerSearcher.renderBody(w, companyRepo, customerRepo)
+	body, err := customerSearcher.renderBody(w, nil, companyRepo, customerRepo)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -48,7 +48,7 @@ func TestSearchAsync__renderBody(t *testing.T) {
 		webhook:     "https://moov.io",
 		authToken:   "hidden",
 	}
-	body, err = companySearcher.renderBody(w, companyRepo, customerRepo)
+	body, err = companySearcher.renderBody(w, nil, companyRepo, customerRepo)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -70,7 +70,7 @@ func TestSearchAsync__renderBodyName(t *testing.T) {
 	customerRepo := createTestCustomerRepository(t)
 	defer customerRepo.close()
 
-	body, err := customerSearcher.renderBody(w, companyRepo, customerRepo)
+	body, err := customerSearcher.renderBody(w, nil, companyRepo, customerRepo)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -84,7 +84,7 @@ func TestSearchAsync__renderBodyName(t *testing.T) {
 		webhook:     "https://moov.io",
 		authToken:   "hidden",
 	}
-	body, err = companySearcher.renderBody(w, companyRepo, customerRepo)
+	body, err = companySearcher.renderBody(w, nil, companyRepo, customerRepo)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -97,7 +97,7 @@ func TestSearchAsync_getCompanyBody(t *testing.T) {
 	repo := createTestCompanyRepository(t)
 	defer repo.close()
 
-	body, err := getCompanyBody(companySearcher, "watchID", "21206", 1.0, repo)
+	body, err := getCompanyBody(companySearcher, "watchID", "21206", 1.0, nil, repo)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -117,7 +117,7 @@ func TestSearchAsync_getCompanyBody(t *testing.T) {
 	}
 
 	// Company not found
-	body, err = getCompanyBody(companySearcher, "watchID", "", 0.0, repo)
+	body, err = getCompanyBody(companySearcher, "watchID", "", 0.0, nil, repo)
 	if err == nil || body != nil {
 		t.Fatal("expected error and no body")
 	}
@@ -127,7 +127,7 @@ func TestSearchAsync_getCustomerBody(t *testing.T) {
 	repo := createTestCustomerRepository(t)
 	defer repo.close()
 
-	body, err := getCustomerBody(customerSearcher, "watchID", "306", 0.91, repo)
+	body, err := getCustomerBody(customerSearcher, "watchID", "306", 0.91, nil, repo)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -147,7 +147,7 @@ func TestSearchAsync_getCustomerBody(t *testing.T) {
 	}
 
 	// Customer not found
-	body, err = getCustomerBody(customerSearcher, "watchID", "", 0.0, repo)
+	body, err = getCustomerBody(customerSearcher, "watchID", "", 0.0, nil, repo)
 	if err == nil || body != nil {
 		t.Fatal("expected error and no body")
 	}
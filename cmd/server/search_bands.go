@@ -0,0 +1,165 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	groupBands = "bands"
+)
+
+// bandThresholds are the lowest match of the high and medium confidence bands, anything
+// below medium is in the low band.
+type bandThresholds struct {
+	high   float64
+	medium float64
+}
+
+var (
+	defaultBandThresholds = bandThresholds{
+		high:   0.95,
+		medium: 0.85,
+	}
+
+	// searchBandThresholds are used by ?group=bands, main sets them from SEARCH_BAND_THRESHOLDS
+	searchBandThresholds = defaultBandThresholds
+)
+
+// getBandThresholds reads thresholds formatted as "high=0.95,medium=0.85". A band not listed
+// keeps its default threshold.
+//
+// env is the value from an environmental variable
+func getBandThresholds(logger log.Logger, env string) bandThresholds {
+	if env == "" {
+		return defaultBandThresholds
+	}
+	thresholds, err := readBandThresholds(env)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid SEARCH_BAND_THRESHOLDS=%q, using defaults: %v", env, err))
+		return defaultBandThresholds
+	}
+	logger.Log("main", fmt.Sprintf("Setting search band thresholds to %q", env))
+	return thresholds
+}
+
+func readBandThresholds(str string) (bandThresholds, error) {
+	thresholds := defaultBandThresholds
+	err := readThresholds(str, "band", map[string]*float64{
+		"high":   &thresholds.high,
+		"medium": &thresholds.medium,
+	})
+	if err != nil {
+		return thresholds, err
+	}
+	if thresholds.medium > thresholds.high {
+		return thresholds, errors.New("medium threshold is above the high threshold")
+	}
+	return thresholds, nil
+}
+
+// readThresholds parses thresholds formatted as "name=0.95,other=0.85" into the values of names,
+// keyed in lowercase. Each threshold must be between 0 and 1 and kind is what errors call
+// the names (e.g. band).
+func readThresholds(str, kind string, names map[string]*float64) error {
+	for _, part := range strings.Split(str, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("expected %s=threshold, got %q", kind, part)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil || math.IsNaN(v) || v < 0 || v > 1 {
+			return fmt.Errorf("invalid threshold %q for %s, must be between 0 and 1", kv[1], kv[0])
+		}
+		threshold, exists := names[strings.ToLower(strings.TrimSpace(kv[0]))]
+		if !exists {
+			return fmt.Errorf("unknown %s %q", kind, kv[0])
+		}
+		*threshold = v
+	}
+	return nil
+}
+
+// bandResult is a search result from any list placed into a confidence band
+type bandResult struct {
+	// List is the key of the list in a flat response (e.g. SDNs or altNames)
+	List   string      `json:"list"`
+	Match  float64     `json:"match"`
+	Result interface{} `json:"result"`
+}
+
+// resultBands are the results of a search with ?group=bands, each band is sorted by match
+type resultBands struct {
+	High   []bandResult `json:"high"`
+	Medium []bandResult `json:"medium"`
+	Low    []bandResult `json:"low"`
+}
+
+func (b *resultBands) add(thresholds bandThresholds, list string, match float64, result interface{}) {
+	r := bandResult{List: list, Match: match, Result: result}
+	switch {
+	case match >= thresholds.high:
+		b.High = append(b.High, r)
+	case match >= thresholds.medium:
+		b.Medium = append(b.Medium, r)
+	default:
+		b.Low = append(b.Low, r)
+	}
+}
+
+// groupIntoBands moves every result of the response from their lists into bands
+func (resp *searchResponse) groupIntoBands(thresholds bandThresholds) {
+	bands := &resultBands{}
+	for _, r := range resp.SDNs {
+		bands.add(thresholds, "SDNs", r.match, r)
+	}
+	for _, r := range resp.AltNames {
+		bands.add(thresholds, "altNames", r.match, r)
+	}
+	for _, r := range resp.Addresses {
+		bands.add(thresholds, "addresses", r.match, r)
+	}
+	for _, r := range resp.SectoralSanctions {
+		bands.add(thresholds, "sectoralSanctions", r.match, r)
+	}
+	for _, r := range resp.DeniedPersons {
+		bands.add(thresholds, "deniedPersons", r.match, r)
+	}
+	for _, r := range resp.BISEntities {
+		bands.add(thresholds, "bisEntities", r.match, r)
+	}
+	for _, r := range resp.NonproliferationSanctions {
+		bands.add(thresholds, "nonproliferationSanctions", r.match, r)
+	}
+	for _, r := range resp.CanadianSanctions {
+		bands.add(thresholds, "canadianSanctions", r.match, r)
+	}
+	for _, r := range resp.AustralianSanctions {
+		bands.add(thresholds, "australianSanctions", r.match, r)
+	}
+	for _, r := range resp.UnitedNationsSanctions {
+		bands.add(thresholds, "unitedNationsSanctions", r.match, r)
+	}
+	for _, r := range resp.CustomEntries {
+		bands.add(thresholds, "customEntries", r.match, r)
+	}
+	for _, band := range [][]bandResult{bands.High, bands.Medium, bands.Low} {
+		sort.SliceStable(band, func(i, j int) bool { return band[i].Match > band[j].Match })
+	}
+
+	resp.SDNs, resp.AltNames, resp.Addresses, resp.SectoralSanctions = nil, nil, nil, nil
+	resp.DeniedPersons, resp.BISEntities = nil, nil
+	resp.NonproliferationSanctions, resp.CanadianSanctions, resp.AustralianSanctions = nil, nil, nil
+	resp.UnitedNationsSanctions, resp.CustomEntries = nil, nil
+	resp.Bands = bands
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestBands__thresholds(t *testing.T) {
+	logger := log.NewNopLogger()
+	if v := getBandThresholds(logger, ""); v != defaultBandThresholds {
+		t.Errorf("got %#v", v)
+	}
+	if v := getBandThresholds(logger, "high=0.9"); v.high != 0.9 || v.medium != 0.85 {
+		t.Errorf("got %#v", v)
+	}
+	if v := getBandThresholds(logger, "high=0.9, medium=0.7"); v.high != 0.9 || v.medium != 0.7 {
+		t.Errorf("got %#v", v)
+	}
+	for _, env := range []string{"high", "high=1.5", "high=NaN", "low=0.5", "high=0.8,medium=0.9"} {
+		if _, err := readBandThresholds(env); err == nil {
+			t.Errorf("%s: expected error", env)
+		}
+		if v := getBandThresholds(logger, env); v != defaultBandThresholds {
+			t.Errorf("%s: got %#v", env, v)
+		}
+	}
+}
+
+func TestBands__group(t *testing.T) {
+	resp := &searchResponse{
+		SDNs: []SDN{
+			{SDN: &ofac.SDN{EntityID: "1"}, match: 0.87},
+			{SDN: &ofac.SDN{EntityID: "2"}, match: 0.99},
+		},
+		AltNames: []Alt{
+			{AlternateIdentity: &ofac.AlternateIdentity{AlternateID: "3"}, match: 0.95},
+		},
+		SectoralSanctions: []SSI{
+			{SectoralSanction: &csl.SSI{EntityID: "4"}, match: 0.5},
+			{SectoralSanction: &csl.SSI{EntityID: "5"}, match: 0.9},
+		},
+	}
+	resp.trim(searchOptions{group: groupBands, bands: bandThresholds{high: 0.95, medium: 0.85}})
+
+	if resp.SDNs != nil || resp.AltNames != nil || resp.SectoralSanctions != nil || resp.Bands == nil {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+	check := func(band string, results []bandResult, expected ...float64) {
+		t.Helper()
+		if len(results) != len(expected) {
+			t.Fatalf("%s: %#v", band, results)
+		}
+		for i := range expected {
+			if results[i].Match != expected[i] {
+				t.Errorf("%s[%d]: got %v expected %v", band, i, results[i].Match, expected[i])
+			}
+		}
+	}
+	check("high", resp.Bands.High, 0.99, 0.95) // the threshold is inclusive
+	check("medium", resp.Bands.Medium, 0.9, 0.87)
+	check("low", resp.Bands.Low, 0.5)
+
+	if r := resp.Bands.High[1]; r.List != "altNames" {
+		t.Errorf("unexpected list: %#v", r)
+	}
+	if r := resp.Bands.Medium[0]; r.List != "sectoralSanctions" {
+		t.Errorf("unexpected list: %#v", r)
+	}
+
+	// stricter thresholds move results down
+	resp = &searchResponse{
+		SDNs: []SDN{{SDN: &ofac.SDN{EntityID: "2"}, match: 0.99}},
+	}
+	resp.trim(searchOptions{group: groupBands, bands: bandThresholds{high: 1.0, medium: 0.99}})
+	check("high", resp.Bands.High)
+	check("medium", resp.Bands.Medium, 0.99)
+}
+
+func TestBands__handler(t *testing.T) {
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro&group=bands", nil))
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	var resp struct {
+		SDNs  []json.RawMessage `json:"SDNs"`
+		Bands struct {
+			High   []bandResult `json:"high"`
+			Medium []bandResult `json:"medium"`
+			Low    []bandResult `json:"low"`
+		} `json:"bands"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.SDNs) != 0 {
+		t.Errorf("SDNs: %#v", resp.SDNs)
+	}
+	if n := len(resp.Bands.High) + len(resp.Bands.Medium) + len(resp.Bands.Low); n != 1 {
+		t.Fatalf("got %d results: %#v", n, resp.Bands)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro&group=lists", nil))
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
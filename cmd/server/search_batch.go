@@ -0,0 +1,186 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	defaultBatchWorkers = 4
+
+	// searchBatchWorkers is how many searches of a batch run at once, it's read from SEARCH_BATCH_WORKERS
+	searchBatchWorkers = defaultBatchWorkers
+)
+
+// getBatchWorkers reads a positive number of searches
+//
+// env is the value from an environmental variable
+func getBatchWorkers(logger log.Logger, env string) int {
+	if env == "" {
+		return defaultBatchWorkers
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil || n <= 0 {
+		logger.Log("main", fmt.Sprintf("invalid SEARCH_BATCH_WORKERS=%q, using default of %d", env, defaultBatchWorkers))
+		return defaultBatchWorkers
+	}
+	logger.Log("main", fmt.Sprintf("Running %d searches of a batch at once", n))
+	return n
+}
+
+// runOrderedBatch performs each search returned by next with up to workers of them at once and
+// calls emit with each result in the order of the batch, regardless of which finished first.
+// next returns io.EOF after the last search, any other error stops the batch and is returned.
+//
+// At most workers searches are read ahead of the result being emitted, so a batch read from a
+// stream isn't held in memory. The number of emitted results is returned.
+func runOrderedBatch(ctx context.Context, workers int, next func() (json.RawMessage, error), run func(json.RawMessage) batchSearchResult, emit func(int, batchSearchResult) error) (int, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	type pending struct {
+		index  int
+		result chan batchSearchResult
+	}
+	queue := make(chan pending, workers)
+	running := make(chan struct{}, workers)
+	stop := make(chan struct{})
+
+	var readErr error
+	go func() {
+		defer close(queue)
+		for i := 0; ; i++ {
+			search, err := next()
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+				}
+				return
+			}
+			select {
+			case running <- struct{}{}:
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+			p := pending{index: i, result: make(chan batchSearchResult, 1)}
+			go func() {
+				defer func() { <-running }()
+				p.result <- run(search)
+			}()
+			select {
+			case queue <- p:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var emitted int
+	var emitErr error
+	for p := range queue {
+		if emitErr != nil {
+			continue // drain until the reader stops
+		}
+		if err := emit(p.index, <-p.result); err != nil {
+			emitErr = err
+			close(stop)
+			continue
+		}
+		emitted++
+	}
+	switch {
+	case emitErr != nil:
+		return emitted, emitErr
+	case readErr != nil:
+		return emitted, readErr
+	}
+	return emitted, ctx.Err()
+}
+
+// batchDecoder reads the searches of a batch body ({"searches": [...]}) one at a time
+type batchDecoder struct {
+	dec     *json.Decoder
+	started bool
+	reading bool // inside the searches array
+	found   bool
+}
+
+func newBatchDecoder(r io.Reader) *batchDecoder {
+	return &batchDecoder{dec: json.NewDecoder(r)}
+}
+
+// next returns the next search of the batch and io.EOF after the last one. Searches aren't
+// validated, so an invalid search only fails that search.
+func (d *batchDecoder) next() (json.RawMessage, error) {
+	if !d.started {
+		d.started = true
+		if err := d.expect(json.Delim('{')); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		if d.reading {
+			if d.dec.More() {
+				var search json.RawMessage
+				if err := d.dec.Decode(&search); err != nil {
+					return nil, unexpectedEOF(err)
+				}
+				return search, nil
+			}
+			if err := d.expect(json.Delim(']')); err != nil {
+				return nil, err
+			}
+			d.reading = false
+		}
+
+		tok, err := d.dec.Token()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if tok == json.Delim('}') {
+			return nil, io.EOF
+		}
+		if key, ok := tok.(string); ok && key == "searches" && !d.found {
+			d.found = true
+			if err := d.expect(json.Delim('[')); err != nil {
+				return nil, fmt.Errorf("searches: %v", err)
+			}
+			d.reading = true
+			continue
+		}
+		var skipped json.RawMessage
+		if err := d.dec.Decode(&skipped); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+}
+
+func (d *batchDecoder) expect(delim json.Delim) error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %v but found %v", delim, tok)
+	}
+	return nil
+}
+
+// unexpectedEOF replaces io.EOF, which would end the batch as if it were complete
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
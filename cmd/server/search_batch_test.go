@@ -0,0 +1,279 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSearch__getBatchWorkers(t *testing.T) {
+	if n := getBatchWorkers(log.NewNopLogger(), ""); n != defaultBatchWorkers {
+		t.Errorf("got %d", n)
+	}
+	if n := getBatchWorkers(log.NewNopLogger(), "0"); n != defaultBatchWorkers {
+		t.Errorf("got %d", n)
+	}
+	if n := getBatchWorkers(log.NewNopLogger(), "16"); n != 16 {
+		t.Errorf("got %d", n)
+	}
+}
+
+func TestSearch__runOrderedBatch(t *testing.T) {
+	var searches []json.RawMessage
+	for i := 0; i < 12; i++ {
+		searches = append(searches, json.RawMessage(fmt.Sprintf("%d", i)))
+	}
+	var read int
+	next := func() (json.RawMessage, error) {
+		if read == len(searches) {
+			return nil, io.EOF
+		}
+		read++
+		return searches[read-1], nil
+	}
+
+	var inFlight, maxInFlight int64
+	run := func(search json.RawMessage) batchSearchResult {
+		n := atomic.AddInt64(&inFlight, 1)
+		defer atomic.AddInt64(&inFlight, -1)
+		for {
+			max := atomic.LoadInt64(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt64(&maxInFlight, max, n) {
+				break
+			}
+		}
+
+		var i int
+		json.Unmarshal(search, &i)
+		time.Sleep(time.Duration(12-i) * time.Millisecond) // later searches finish first
+		if i%4 == 3 {
+			return batchSearchResult{Status: http.StatusBadRequest, Error: fmt.Sprintf("search %d", i)}
+		}
+		return batchSearchResult{Status: http.StatusOK, Result: search}
+	}
+
+	var emitted []int
+	emit := func(i int, result batchSearchResult) error {
+		if i%4 == 3 {
+			if result.Status != http.StatusBadRequest || result.Error != fmt.Sprintf("search %d", i) {
+				t.Errorf("#%d: %#v", i, result)
+			}
+		} else if string(result.Result) != fmt.Sprintf("%d", i) {
+			t.Errorf("#%d: %#v", i, result)
+		}
+		emitted = append(emitted, i)
+		return nil
+	}
+	n, err := runOrderedBatch(context.Background(), 3, next, run, emit)
+	if err != nil || n != len(searches) {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	for i := range emitted {
+		if emitted[i] != i {
+			t.Fatalf("out of order: %v", emitted)
+		}
+	}
+	if max := atomic.LoadInt64(&maxInFlight); max < 2 || max > 3 {
+		t.Errorf("%d searches ran at once", max)
+	}
+
+	// errors reading or emitting stop the batch
+	read = 0
+	failing := func() (json.RawMessage, error) {
+		if read == 2 {
+			return nil, errors.New("bad read")
+		}
+		return next()
+	}
+	n, err = runOrderedBatch(context.Background(), 3, failing, run, func(int, batchSearchResult) error { return nil })
+	if n != 2 || err == nil || err.Error() != "bad read" {
+		t.Errorf("n=%d err=%v", n, err)
+	}
+	read = 0
+	n, err = runOrderedBatch(context.Background(), 3, next, run, func(i int, _ batchSearchResult) error {
+		if i == 1 {
+			return errors.New("bad write")
+		}
+		return nil
+	})
+	if n != 1 || err == nil || err.Error() != "bad write" {
+		t.Errorf("n=%d err=%v", n, err)
+	}
+}
+
+func TestSearch__batchDecoder(t *testing.T) {
+	read := func(body string) ([]string, error) {
+		dec := newBatchDecoder(strings.NewReader(body))
+		var out []string
+		for {
+			search, err := dec.next()
+			if err == io.EOF {
+				return out, nil
+			}
+			if err != nil {
+				return out, err
+			}
+			out = append(out, string(search))
+		}
+	}
+
+	searches, err := read(`{"other": {"searches": []}, "searches": [{"name": "a"}, "b", [1]], "after": 1}`)
+	if err != nil || len(searches) != 3 || searches[0] != `{"name": "a"}` || searches[1] != `"b"` {
+		t.Errorf("searches=%q err=%v", searches, err)
+	}
+	if searches, err := read(`{"searches": []}`); err != nil || len(searches) != 0 {
+		t.Errorf("searches=%q err=%v", searches, err)
+	}
+	for _, body := range []string{``, `[]`, `{"searches": {}}`, `{"searches": [{"name": "a"}, {"name"`, `{"searches": [{"name": "a"}]`} {
+		if _, err := read(body); err == nil || err == io.EOF {
+			t.Errorf("%q: expected error: %v", body, err)
+		}
+	}
+}
+
+func TestSearch__batchOrder(t *testing.T) {
+	defer func(workers int) { searchBatchWorkers = workers }(searchBatchWorkers)
+	searchBatchWorkers = 3
+
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"},
+			{EntityID: "2681", SDNName: "HAWATMA, Nayif", SDNType: "individual"},
+			{EntityID: "306", SDNName: "BANCO NACIONAL DE CUBA"},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	// successful and failing searches, expecting an SDN or an error in their place
+	searches := []struct {
+		search, expected string
+		status           int
+	}{
+		{`{"name": "nicolas maduro", "limit": 1}`, `"entityID":"22790"`, http.StatusOK},
+		{`{"name": "nicolas maduro", "sources": "other"}`, "unknown source", http.StatusBadRequest},
+		{`{"name": "nayif hawatma", "limit": 1}`, `"entityID":"2681"`, http.StatusOK},
+		{`"banco nacional de cuba"`, "invalid search", http.StatusBadRequest},
+		{`{"name": "banco nacional de cuba", "limit": 1}`, `"entityID":"306"`, http.StatusOK},
+		{`{"sources": [1, 2]}`, "unsupported value", http.StatusBadRequest},
+		{`{"name": "nicolas maduro", "minMatch": 2}`, "invalid minMatch", http.StatusBadRequest},
+		{`{"name": "hawatma nayif", "limit": 1}`, `"entityID":"2681"`, http.StatusOK},
+	}
+	var bodies []string
+	for i := range searches {
+		bodies = append(bodies, searches[i].search)
+	}
+	body := `{"searches": [` + strings.Join(bodies, ",") + `]}`
+
+	check := func(t *testing.T, i int, result batchSearchResult) {
+		t.Helper()
+		expected := searches[i]
+		if result.Status != expected.status {
+			t.Errorf("#%d: status=%d", i, result.Status)
+		}
+		if expected.status == http.StatusOK {
+			var resp searchResponse
+			if err := json.Unmarshal(result.Result, &resp); err != nil || len(resp.SDNs) != 1 || !strings.Contains(string(result.Result), expected.expected) {
+				t.Errorf("#%d: %s", i, result.Result)
+			}
+		} else if !strings.Contains(result.Error, expected.expected) || result.Result != nil {
+			t.Errorf("#%d: %#v", i, result)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/search/batch", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	var resp batchSearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != len(searches) {
+		t.Fatalf("got %d results", len(resp.Results))
+	}
+	for i := range resp.Results {
+		check(t, i, resp.Results[i])
+	}
+
+	// streamed results are in the same order, an invalid body ends the stream
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	stream := func(t *testing.T, body string) []string {
+		t.Helper()
+		resp, err := http.Post(server.URL+"/search/batch/stream", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("bogus status code: %d", resp.StatusCode)
+		}
+		bs, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var events []string
+		for _, event := range strings.Split(strings.TrimSpace(string(bs)), "\n\n") {
+			lines := strings.SplitN(event, "\n", 2)
+			if len(lines) != 2 {
+				t.Fatalf("malformed event: %q", event)
+			}
+			name, data := strings.TrimPrefix(lines[0], "event: "), strings.TrimPrefix(lines[1], "data: ")
+			if name == "result" {
+				var result streamedSearchResult
+				if err := json.Unmarshal([]byte(data), &result); err != nil {
+					t.Fatal(err)
+				}
+				if result.Index != len(events) {
+					t.Errorf("got #%d as result %d", result.Index, len(events))
+				}
+				check(t, result.Index, result.batchSearchResult)
+			}
+			events = append(events, name+" "+data)
+		}
+		return events
+	}
+	events := stream(t, body)
+	if len(events) != len(searches)+1 || events[len(searches)] != `done {"results":8}` {
+		t.Errorf("unexpected events: %q", events)
+	}
+
+	events = stream(t, `{"searches": [`+strings.Join(bodies[:3], ",")+`, {"name": }]}`)
+	if len(events) != 4 || !strings.HasPrefix(events[3], `error {"error":"invalid batch: `) {
+		t.Errorf("unexpected events: %q", events)
+	}
+
+	// invalid batches are still rejected before streaming
+	for _, body := range []string{`not json`, `{"searches": []}`, `{}`} {
+		resp, err := http.Post(server.URL+"/search/batch/stream", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: bogus status code: %d", body, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	// decisionClear is a search or screening whose top match is below the review threshold
+	decisionClear = "clear"
+
+	// decisionReview is a top match which should be looked at by someone before proceeding
+	decisionReview = "review"
+
+	// decisionBlock is a top match strong enough to stop the transaction or onboarding
+	decisionBlock = "block"
+)
+
+// decisionThresholds are the lowest top match which is decided as block or review, anything
+// below review is clear.
+type decisionThresholds struct {
+	block  float64
+	review float64
+}
+
+var (
+	defaultDecisionThresholds = decisionThresholds{
+		block:  0.95,
+		review: 0.85,
+	}
+
+	// searchDecisionThresholds decide searches and screenings, main sets them from
+	// SEARCH_DECISION_THRESHOLDS
+	searchDecisionThresholds = defaultDecisionThresholds
+)
+
+// getDecisionThresholds reads thresholds formatted as "block=0.95,review=0.85". A decision not
+// listed keeps its default threshold.
+//
+// env is the value from an environmental variable
+func getDecisionThresholds(logger log.Logger, env string) decisionThresholds {
+	if env == "" {
+		return defaultDecisionThresholds
+	}
+	thresholds, err := readDecisionThresholds(env)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid SEARCH_DECISION_THRESHOLDS=%q, using defaults: %v", env, err))
+		return defaultDecisionThresholds
+	}
+	logger.Log("main", fmt.Sprintf("Setting search decision thresholds to %q", env))
+	return thresholds
+}
+
+func readDecisionThresholds(str string) (decisionThresholds, error) {
+	thresholds := defaultDecisionThresholds
+	err := readThresholds(str, "decision", map[string]*float64{
+		decisionBlock:  &thresholds.block,
+		decisionReview: &thresholds.review,
+	})
+	if err != nil {
+		return thresholds, err
+	}
+	if thresholds.review > thresholds.block {
+		return thresholds, errors.New("review threshold is above the block threshold")
+	}
+	return thresholds, nil
+}
+
+// decide returns the decision of a search or screening whose best result has match
+func (t decisionThresholds) decide(match float64) string {
+	switch {
+	case match >= t.block:
+		return decisionBlock
+	case match >= t.review:
+		return decisionReview
+	default:
+		return decisionClear
+	}
+}
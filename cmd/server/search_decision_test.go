@@ -0,0 +1,161 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestDecision__thresholds(t *testing.T) {
+	logger := log.NewNopLogger()
+	if v := getDecisionThresholds(logger, ""); v != defaultDecisionThresholds {
+		t.Errorf("got %#v", v)
+	}
+	if v := getDecisionThresholds(logger, "block=0.9"); v.block != 0.9 || v.review != 0.85 {
+		t.Errorf("got %#v", v)
+	}
+	if v := getDecisionThresholds(logger, "Block=0.9, review=0.7"); v.block != 0.9 || v.review != 0.7 {
+		t.Errorf("got %#v", v)
+	}
+	for _, env := range []string{"block", "block=1.5", "block=NaN", "review=-1", "clear=0.5", "block=0.8,review=0.9"} {
+		if _, err := readDecisionThresholds(env); err == nil {
+			t.Errorf("%s: expected error", env)
+		}
+		if v := getDecisionThresholds(logger, env); v != defaultDecisionThresholds {
+			t.Errorf("%s: got %#v", env, v)
+		}
+	}
+}
+
+func TestDecision__decide(t *testing.T) {
+	thresholds := decisionThresholds{block: 0.95, review: 0.85}
+	cases := map[float64]string{
+		1.0:   decisionBlock,
+		0.95:  decisionBlock, // thresholds are inclusive
+		0.949: decisionReview,
+		0.9:   decisionReview,
+		0.85:  decisionReview,
+		0.849: decisionClear,
+		0.5:   decisionClear,
+		0.0:   decisionClear,
+	}
+	for match, expected := range cases {
+		if v := thresholds.decide(match); v != expected {
+			t.Errorf("%.3f: got %s, expected %s", match, v, expected)
+		}
+	}
+
+	// equal thresholds leave no review band
+	thresholds = decisionThresholds{block: 0.9, review: 0.9}
+	if v := thresholds.decide(0.9); v != decisionBlock {
+		t.Errorf("got %s", v)
+	}
+	if v := thresholds.decide(0.89); v != decisionClear {
+		t.Errorf("got %s", v)
+	}
+}
+
+func TestDecision__trim(t *testing.T) {
+	newResponse := func(sdn, ssi float64) *searchResponse {
+		return &searchResponse{
+			SDNs:              []SDN{{SDN: &ofac.SDN{EntityID: "1"}, match: sdn}},
+			SectoralSanctions: []SSI{{SectoralSanction: &csl.SSI{EntityID: "2"}, match: ssi}},
+		}
+	}
+	cases := []struct {
+		sdn, ssi float64
+		expected string
+	}{
+		{0.99, 0.5, decisionBlock},
+		{0.5, 0.96, decisionBlock}, // the best result of any list decides
+		{0.9, 0.86, decisionReview},
+		{0.8, 0.84, decisionClear},
+	}
+	for _, tc := range cases {
+		resp := newResponse(tc.sdn, tc.ssi)
+		resp.trim(searchOptions{})
+		if resp.Decision != tc.expected {
+			t.Errorf("%.2f/%.2f: got %s, expected %s", tc.sdn, tc.ssi, resp.Decision, tc.expected)
+		}
+
+		// grouping results doesn't change the decision
+		resp = newResponse(tc.sdn, tc.ssi)
+		resp.trim(searchOptions{group: groupBands, bands: defaultBandThresholds, dedupeEntities: true})
+		if resp.Decision != tc.expected {
+			t.Errorf("%.2f/%.2f bands: got %s, expected %s", tc.sdn, tc.ssi, resp.Decision, tc.expected)
+		}
+	}
+	resp := &searchResponse{}
+	if resp.trim(searchOptions{}); resp.Decision != decisionClear {
+		t.Errorf("no results: got %s", resp.Decision)
+	}
+}
+
+func TestDecision__search(t *testing.T) {
+	defer func() { searchDecisionThresholds = defaultDecisionThresholds }()
+
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+	search := func(query string) string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: bogus status code: %d", query, w.Code)
+		}
+		var resp struct {
+			Decision string `json:"decision"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Decision
+	}
+	if v := search("name=nicolas+maduro+moros"); v != decisionBlock {
+		t.Errorf("got %s", v)
+	}
+	if v := search("id=5892464"); v != decisionBlock {
+		t.Errorf("got %s", v)
+	}
+	if v := search("name=zzzzzz+qqqqq"); v != decisionClear {
+		t.Errorf("got %s", v)
+	}
+
+	searchDecisionThresholds = decisionThresholds{block: 1.0, review: 0.0}
+	if v := search("name=zzzzzz+qqqqq"); v != decisionReview {
+		t.Errorf("got %s", v)
+	}
+}
+
+func TestDecision__screen(t *testing.T) {
+	defer func() { searchDecisionThresholds = defaultDecisionThresholds }()
+
+	s := screenTestSearcher()
+	_, resp := screenTest(t, s, `{"name": "John Smith", "dateOfBirth": "1980-05-05"}`)
+	if resp.Match == nil || resp.Decision != decisionBlock {
+		t.Errorf("got %s: %#v", resp.Decision, resp.Match)
+	}
+	_, resp = screenTest(t, s, `{"name": "Qwerty Zxcvb"}`)
+	if resp.Decision != decisionClear {
+		t.Errorf("got %s: %#v", resp.Decision, resp.Match)
+	}
+
+	// the blended match is decided, not the name's score
+	searchDecisionThresholds = decisionThresholds{block: 1.0, review: 0.5}
+	_, resp = screenTest(t, s, `{"name": "John Smith", "dateOfBirth": "1990-01-01"}`)
+	if resp.Match == nil || resp.Match.Match >= 1.0 || resp.Decision != decisionReview {
+		t.Errorf("got %s: %#v", resp.Decision, resp.Match)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+// topMatch returns the best match of every result in the response
+func (resp *searchResponse) topMatch() float64 {
+	var top float64
+	max := func(m float64) {
+		if m > top {
+			top = m
+		}
+	}
+	for i := range resp.SDNs {
+		max(resp.SDNs[i].match)
+	}
+	for i := range resp.AltNames {
+		max(resp.AltNames[i].match)
+	}
+	for i := range resp.Addresses {
+		max(resp.Addresses[i].match)
+	}
+	for i := range resp.SectoralSanctions {
+		max(resp.SectoralSanctions[i].match)
+	}
+	for i := range resp.DeniedPersons {
+		max(resp.DeniedPersons[i].match)
+	}
+	for i := range resp.BISEntities {
+		max(resp.BISEntities[i].match)
+	}
+	for i := range resp.NonproliferationSanctions {
+		max(resp.NonproliferationSanctions[i].match)
+	}
+	for i := range resp.CanadianSanctions {
+		max(resp.CanadianSanctions[i].match)
+	}
+	for i := range resp.AustralianSanctions {
+		max(resp.AustralianSanctions[i].match)
+	}
+	for i := range resp.UnitedNationsSanctions {
+		max(resp.UnitedNationsSanctions[i].match)
+	}
+	for i := range resp.CustomEntries {
+		max(resp.CustomEntries[i].match)
+	}
+	return top
+}
+
+// trim removes results (and fields not asked for) after ranking according to opts
+func (resp *searchResponse) trim(opts searchOptions) {
+	resp.TotalMatches = opts.totalMatches()
+	resp.suppressSelfMatches(opts.selfMatches)
+	if !opts.includeRemarks {
+		resp.hideRemarks()
+	}
+	if opts.strict {
+		// only exact name matches, addresses have their own toggle
+		addresses := resp.Addresses
+		resp.keepAtLeast(1.0)
+		resp.Addresses = addresses
+	}
+	if opts.strictAddresses {
+		resp.keepExactAddresses()
+	}
+	resp.keepWithinDelta(opts.topDelta)
+	resp.Decision = searchDecisionThresholds.decide(resp.topMatch())
+	if opts.includeAddresses {
+		resp.includeSDNAddresses()
+	}
+	if opts.includeDetails {
+		resp.includeSDNDetails()
+	}
+	if opts.dedupeEntities {
+		resp.dedupeEntities()
+	}
+	if opts.sort == sortRecent {
+		resp.sortByRecency(opts.bands)
+	}
+	if opts.group == groupBands {
+		resp.groupIntoBands(opts.bands)
+		if opts.sort == sortRecent {
+			resp.Bands.sortByRecency(opts.bands)
+		}
+	}
+}
+
+// hideRemarks leaves the raw remarks out of SDN results, they're always on GET /ofac/sdn/{sdnId}
+func (resp *searchResponse) hideRemarks() {
+	for i := range resp.SDNs {
+		resp.SDNs[i].hideRemarks = true
+	}
+}
+
+// keepWithinDelta removes results whose match is more than delta below the best match
+// across the entire response. A strong hit suppresses weak ones while near ties are kept.
+func (resp *searchResponse) keepWithinDelta(delta float64) {
+	if delta <= 0 {
+		return
+	}
+	resp.keepAtLeast(resp.topMatch() - delta)
+}
+
+// keepExactAddresses removes address results which aren't exact matches
+func (resp *searchResponse) keepExactAddresses() {
+	addresses := make([]Address, 0, len(resp.Addresses))
+	for i := range resp.Addresses {
+		if resp.Addresses[i].match >= 1.0 {
+			addresses = append(addresses, resp.Addresses[i])
+		}
+	}
+	resp.Addresses = addresses
+}
+
+// keepAtLeast removes results whose match is below floor, lists which end up empty are
+// still encoded as [] rather than null
+func (resp *searchResponse) keepAtLeast(floor float64) {
+	sdns := make([]SDN, 0, len(resp.SDNs))
+	for i := range resp.SDNs {
+		if resp.SDNs[i].match >= floor {
+			sdns = append(sdns, resp.SDNs[i])
+		}
+	}
+	resp.SDNs = sdns
+
+	alts := make([]Alt, 0, len(resp.AltNames))
+	for i := range resp.AltNames {
+		if resp.AltNames[i].match >= floor {
+			alts = append(alts, resp.AltNames[i])
+		}
+	}
+	resp.AltNames = alts
+
+	addresses := make([]Address, 0, len(resp.Addresses))
+	for i := range resp.Addresses {
+		if resp.Addresses[i].match >= floor {
+			addresses = append(addresses, resp.Addresses[i])
+		}
+	}
+	resp.Addresses = addresses
+
+	ssis := make([]SSI, 0, len(resp.SectoralSanctions))
+	for i := range resp.SectoralSanctions {
+		if resp.SectoralSanctions[i].match >= floor {
+			ssis = append(ssis, resp.SectoralSanctions[i])
+		}
+	}
+	resp.SectoralSanctions = ssis
+
+	dps := make([]DP, 0, len(resp.DeniedPersons))
+	for i := range resp.DeniedPersons {
+		if resp.DeniedPersons[i].match >= floor {
+			dps = append(dps, resp.DeniedPersons[i])
+		}
+	}
+	resp.DeniedPersons = dps
+
+	els := make([]BISEntity, 0, len(resp.BISEntities))
+	for i := range resp.BISEntities {
+		if resp.BISEntities[i].match >= floor {
+			els = append(els, resp.BISEntities[i])
+		}
+	}
+	resp.BISEntities = els
+
+	isns := make([]ISN, 0, len(resp.NonproliferationSanctions))
+	for i := range resp.NonproliferationSanctions {
+		if resp.NonproliferationSanctions[i].match >= floor {
+			isns = append(isns, resp.NonproliferationSanctions[i])
+		}
+	}
+	resp.NonproliferationSanctions = isns
+
+	cas := make([]CanadianSanction, 0, len(resp.CanadianSanctions))
+	for i := range resp.CanadianSanctions {
+		if resp.CanadianSanctions[i].match >= floor {
+			cas = append(cas, resp.CanadianSanctions[i])
+		}
+	}
+	resp.CanadianSanctions = cas
+
+	aus := make([]AustralianSanction, 0, len(resp.AustralianSanctions))
+	for i := range resp.AustralianSanctions {
+		if resp.AustralianSanctions[i].match >= floor {
+			aus = append(aus, resp.AustralianSanctions[i])
+		}
+	}
+	resp.AustralianSanctions = aus
+
+	uns := make([]UnitedNationsSanction, 0, len(resp.UnitedNationsSanctions))
+	for i := range resp.UnitedNationsSanctions {
+		if resp.UnitedNationsSanctions[i].match >= floor {
+			uns = append(uns, resp.UnitedNationsSanctions[i])
+		}
+	}
+	resp.UnitedNationsSanctions = uns
+
+	customs := make([]CustomEntry, 0, len(resp.CustomEntries))
+	for i := range resp.CustomEntries {
+		if resp.CustomEntries[i].match >= floor {
+			customs = append(customs, resp.CustomEntries[i])
+		}
+	}
+	resp.CustomEntries = customs
+}
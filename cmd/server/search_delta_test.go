@@ -0,0 +1,107 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSearch__keepWithinDelta(t *testing.T) {
+	resp := &searchResponse{
+		SDNs:          []SDN{{match: 0.99}, {match: 0.70}},
+		AltNames:      []Alt{{match: 0.60}},
+		DeniedPersons: []DP{{match: 0.95}},
+	}
+	resp.keepWithinDelta(0.05)
+
+	// the strong hit suppresses weaker results across every list
+	if len(resp.SDNs) != 1 || resp.SDNs[0].match != 0.99 {
+		t.Errorf("SDNs: %#v", resp.SDNs)
+	}
+	if len(resp.AltNames) != 0 {
+		t.Errorf("AltNames: %#v", resp.AltNames)
+	}
+	if len(resp.DeniedPersons) != 1 {
+		t.Errorf("DeniedPersons: %#v", resp.DeniedPersons)
+	}
+
+	// several near-ties are all kept
+	resp = &searchResponse{
+		SDNs: []SDN{{match: 0.82}, {match: 0.81}, {match: 0.80}, {match: 0.50}},
+	}
+	resp.keepWithinDelta(0.02)
+	if len(resp.SDNs) != 3 {
+		t.Errorf("SDNs: %#v", resp.SDNs)
+	}
+
+	// zero keeps everything
+	resp.keepWithinDelta(0.0)
+	if len(resp.SDNs) != 3 {
+		t.Errorf("SDNs: %#v", resp.SDNs)
+	}
+
+	// lists without any results left are empty rather than null
+	resp = &searchResponse{
+		SDNs:     []SDN{{match: 0.99}},
+		AltNames: []Alt{{match: 0.60}},
+	}
+	resp.keepWithinDelta(0.05)
+	bs, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lists map[string]json.RawMessage
+	if err := json.Unmarshal(bs, &lists); err != nil {
+		t.Fatal(err)
+	}
+	for _, list := range []string{"altNames", "addresses", "deniedPersons", "customEntries"} {
+		if v := string(lists[list]); v != "[]" {
+			t.Errorf("%s=%s", list, v)
+		}
+	}
+}
+
+func TestSearch__topDeltaParam(t *testing.T) {
+	u, _ := url.Parse("/search?q=foo&topDelta=0.1")
+	opts, err := readSearchOptions(u)
+	if err != nil || opts.topDelta != 0.1 {
+		t.Errorf("opts=%#v err=%v", opts, err)
+	}
+	for _, v := range []string{"abc", "-0.1", "1.5", "NaN"} {
+		u, _ = url.Parse("/search?q=foo&topDelta=" + v)
+		if _, err := readSearchOptions(u); err == nil {
+			t.Errorf("expected error for %s", v)
+		}
+	}
+
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, sdnSearcher)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=Ayman+ZAWAHIRI&limit=10&topDelta=0.01", nil))
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	var wrapper struct {
+		SDNs []struct {
+			Match float64 `json:"match"`
+		} `json:"SDNs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&wrapper); err != nil {
+		t.Fatal(err)
+	}
+	if len(wrapper.SDNs) != 1 {
+		t.Errorf("expected only the strong hit: %#v", wrapper.SDNs)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	// emptyFieldsIgnore searches as if empty fields weren't given, a search with only empty
+	// fields is rejected as missing search parameters
+	emptyFieldsIgnore = "ignore"
+
+	// emptyFieldsReject rejects searches with any empty field
+	emptyFieldsReject = "reject"
+)
+
+var (
+	// searchEmptyFields is how searches with empty fields are handled, main sets it from
+	// SEARCH_EMPTY_FIELDS
+	searchEmptyFields = emptyFieldsIgnore
+
+	// queryFields are the search parameters which are compared against records, they're
+	// empty when they have no letters or digits
+	queryFields = []string{
+		"q", "id", "name", "altName", "givenName", "middleName", "familyName",
+		"address", "city", "state", "providence", "zip", "country",
+	}
+)
+
+// getSearchEmptyFields reads either "ignore" or "reject"
+//
+// env is the value from an environmental variable
+func getSearchEmptyFields(logger log.Logger, env string) string {
+	switch policy := strings.ToLower(strings.TrimSpace(env)); policy {
+	case "", emptyFieldsIgnore:
+		return emptyFieldsIgnore
+	case emptyFieldsReject:
+		logger.Log("main", "Rejecting searches with empty fields")
+		return policy
+	default:
+		logger.Log("main", fmt.Sprintf("invalid SEARCH_EMPTY_FIELDS=%q, ignoring empty fields", env))
+		return emptyFieldsIgnore
+	}
+}
+
+// emptyQuery returns true when v has nothing to compare once normalized, like whitespace or
+// only punctuation ("--", "!!!"), which would otherwise weakly match every record
+func emptyQuery(v string) bool {
+	return strings.IndexFunc(v, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) < 0
+}
+
+// checkEmptyFields removes the empty values of queryFields from u, or returns an error naming
+// the first one when searchEmptyFields is emptyFieldsReject
+func checkEmptyFields(u *url.URL) error {
+	q := u.Query()
+	changed := false
+	for _, field := range queryFields {
+		values, exists := q[field]
+		if !exists {
+			continue
+		}
+		var kept []string
+		for _, v := range values {
+			if !emptyQuery(v) {
+				kept = append(kept, v)
+				continue
+			}
+			if searchEmptyFields == emptyFieldsReject {
+				return fmt.Errorf("%s is empty once normalized, it needs a letter or digit to search for", field)
+			}
+		}
+		if len(kept) != len(values) {
+			changed = true
+			if len(kept) == 0 {
+				q.Del(field)
+			} else {
+				q[field] = kept
+			}
+		}
+	}
+	if changed {
+		u.RawQuery = q.Encode()
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSearchEmptyFields__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	for env, expected := range map[string]string{"": emptyFieldsIgnore, "ignore": emptyFieldsIgnore, " Reject ": emptyFieldsReject, "other": emptyFieldsIgnore} {
+		if v := getSearchEmptyFields(logger, env); v != expected {
+			t.Errorf("%q: got %q", env, v)
+		}
+	}
+}
+
+func TestSearchEmptyFields__emptyQuery(t *testing.T) {
+	for _, v := range []string{"", " ", "\t\n", "--", "!!!", " - . ", "#,?"} {
+		if !emptyQuery(v) {
+			t.Errorf("%q isn't empty", v)
+		}
+	}
+	for _, v := range []string{"a", " 1 ", "al-", "Москва", "金正恩"} {
+		if emptyQuery(v) {
+			t.Errorf("%q is empty", v)
+		}
+	}
+}
+
+func TestSearchEmptyFields__check(t *testing.T) {
+	defer func() { searchEmptyFields = emptyFieldsIgnore }()
+
+	u, _ := url.Parse("/search?name=%20&name=Nicolas+Maduro&address=--&country=venezuela&limit=1")
+	if err := checkEmptyFields(u); err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if names := q["name"]; len(names) != 1 || names[0] != "Nicolas Maduro" {
+		t.Errorf("name=%v", names)
+	}
+	if _, exists := q["address"]; exists || q.Get("country") != "venezuela" || q.Get("limit") != "1" {
+		t.Errorf("query=%v", q)
+	}
+
+	searchEmptyFields = emptyFieldsReject
+	u, _ = url.Parse("/search?name=Nicolas+Maduro&city=!!!")
+	if err := checkEmptyFields(u); err == nil || !strings.Contains(err.Error(), "city") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSearchEmptyFields__search(t *testing.T) {
+	defer func() { searchEmptyFields = emptyFieldsIgnore }()
+
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+	search := func(method, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		if method == "POST" {
+			router.ServeHTTP(w, httptest.NewRequest("POST", "/search", strings.NewReader(query)))
+		} else {
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		}
+		w.Flush()
+		return w
+	}
+
+	// searches with only empty fields are rejected instead of weakly matching every record
+	empty := []string{"name=", "name=%20%20", "name=!!!", "q=%20", "q=--", "altName=...", "id=%20", "address=%2C&city=%20", "givenName=-&familyName=%20"}
+	for _, query := range empty {
+		w := search("GET", query)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), errNoSearchParams.Error()) {
+			t.Errorf("%s: got %d: %s", query, w.Code, w.Body.String())
+		}
+	}
+	for _, body := range []string{`{"name": " "}`, `{"name": "?!"}`, `{"q": "- -"}`} {
+		if w := search("POST", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d: %s", body, w.Code, w.Body.String())
+		}
+	}
+
+	// by default the other fields of a search are still searched
+	w := search("GET", "name=!!!&id=5892464")
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		SDNs []struct {
+			EntityID string `json:"entityID"`
+		} `json:"SDNs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.SDNs) != 1 || resp.SDNs[0].EntityID != "22790" {
+		t.Errorf("got %#v", resp.SDNs)
+	}
+
+	// which are rejected when any field is empty
+	searchEmptyFields = emptyFieldsReject
+	for _, query := range []string{"name=!!!&id=5892464", "name=nicolas+maduro&city=%20", "q=%20-%20"} {
+		w := search("GET", query)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "empty once normalized") {
+			t.Errorf("%s: got %d: %s", query, w.Code, w.Body.String())
+		}
+	}
+	if w := search("GET", "name=nicolas+maduro"); w.Code != http.StatusOK {
+		t.Errorf("got %d: %s", w.Code, w.Body.String())
+	}
+}
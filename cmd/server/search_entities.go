@@ -0,0 +1,350 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// entityGroup is one party found on several lists. With ?dedupeEntities=true its entries are
+// removed from their list in the response and returned together instead.
+type entityGroup struct {
+	// Name and Match are from the best scoring entry
+	Name    string        `json:"name"`
+	Match   float64       `json:"match"`
+	Entries []entityEntry `json:"entries"`
+}
+
+// entityEntry refers to a search result which was grouped into an entityGroup
+type entityEntry struct {
+	Source   string  `json:"source"`
+	EntityID string  `json:"entityID"`
+	Name     string  `json:"name"`
+	Match    float64 `json:"match"`
+}
+
+// party holds what a result says about who it refers to. Only lists which publish dates of
+// birth or identification numbers can be grouped, as a name alone isn't enough.
+type party struct {
+	entry entityEntry
+	names []string // normalized with their tokens sorted
+	dobs  []string // YYYY-MM-DD or YYYY
+	ids   []string // uppercased alphanumerics
+
+	remove func() // drops the result from its list in the response
+	result interface{}
+}
+
+// agrees returns true when both parties have the same name and at least one of the same date
+// of birth or identification number.
+func (p *party) agrees(other *party) bool {
+	if p.entry.Source == other.entry.Source && p.entry.EntityID == other.entry.EntityID {
+		return false
+	}
+	return anyEqual(p.names, other.names) && (anyEqual(p.dobs, other.dobs) || anyEqual(p.ids, other.ids))
+}
+
+func anyEqual(a, b []string) bool {
+	for i := range a {
+		for j := range b {
+			if a[i] != "" && a[i] == b[j] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dedupeEntities groups results from different lists which refer to the same party
+func (resp *searchResponse) dedupeEntities() {
+	parties := resp.parties()
+
+	// union parties that agree, so A~B and B~C are one group even if A and C differ
+	parent := make([]int, len(parties))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range parties {
+		for j := i + 1; j < len(parties); j++ {
+			if parties[i].agrees(parties[j]) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := make(map[int][]*party)
+	var roots []int
+	for i := range parties {
+		root := find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], parties[i])
+	}
+
+	for _, root := range roots {
+		group := members[root]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return group[i].entry.Match > group[j].entry.Match })
+
+		eg := entityGroup{
+			Name:  group[0].entry.Name,
+			Match: group[0].entry.Match,
+		}
+		for _, p := range group {
+			eg.Entries = append(eg.Entries, p.entry)
+			p.remove()
+		}
+		resp.Entities = append(resp.Entities, eg)
+	}
+	sort.SliceStable(resp.Entities, func(i, j int) bool { return resp.Entities[i].Match > resp.Entities[j].Match })
+
+	resp.compact()
+}
+
+// parties collects the results which could be grouped. Results are marked for removal by
+// setting their match below zero, compact then drops them.
+func (resp *searchResponse) parties() []*party {
+	var out []*party
+	for i := range resp.SDNs {
+		sdn := &resp.SDNs[i]
+		if sdn.SDN == nil {
+			continue
+		}
+		out = append(out, &party{
+			entry:  entityEntry{sourceSDN, sdn.EntityID, sdn.SDNName, sdn.match},
+			names:  []string{sortTokens(sdn.name)},
+			dobs:   remarkDOBs(sdn.Remarks),
+			ids:    []string{normalizeID(sdn.id)},
+			remove: func() { sdn.match = -1 },
+			result: *sdn,
+		})
+	}
+	for i := range resp.SectoralSanctions {
+		ssi := &resp.SectoralSanctions[i]
+		if ssi.SectoralSanction == nil {
+			continue
+		}
+		p := &party{
+			entry:  entityEntry{sourceSSI, ssi.SectoralSanction.EntityID, ssi.SectoralSanction.Name, ssi.match},
+			names:  []string{sortTokens(ssi.name)},
+			remove: func() { ssi.match = -1 },
+			result: *ssi,
+		}
+		for _, alt := range ssi.SectoralSanction.AlternateNames {
+			p.names = append(p.names, sortTokens(alt))
+		}
+		for _, id := range ssi.SectoralSanction.IDsOnRecord {
+			// IDs are formatted as "type, number, country"
+			if parts := strings.Split(id, ","); len(parts) > 1 {
+				p.ids = append(p.ids, normalizeID(parts[1]))
+			}
+		}
+		out = append(out, p)
+	}
+	for i := range resp.CanadianSanctions {
+		cs := &resp.CanadianSanctions[i]
+		if cs.Entry == nil {
+			continue
+		}
+		p := &party{
+			entry:  entityEntry{sourceCA, cs.Entry.ID(), cs.Entry.Name(), cs.match},
+			names:  []string{sortTokens(cs.name)},
+			ids:    []string{normalizeID(cs.Entry.ShipIMONumber)},
+			remove: func() { cs.match = -1 },
+			result: *cs,
+		}
+		for _, alt := range cs.Entry.Aliases {
+			p.names = append(p.names, sortTokens(alt))
+		}
+		if dob := normalizeDOB(cs.Entry.DateOfBirthOrShipBuildDate); dob != "" {
+			p.dobs = append(p.dobs, dob)
+		}
+		out = append(out, p)
+	}
+	for i := range resp.AustralianSanctions {
+		as := &resp.AustralianSanctions[i]
+		if as.Entry == nil {
+			continue
+		}
+		p := &party{
+			entry:  entityEntry{sourceAU, as.Entry.Reference, as.Entry.Name, as.match},
+			names:  []string{sortTokens(as.name)},
+			remove: func() { as.match = -1 },
+			result: *as,
+		}
+		for _, alt := range as.Entry.Aliases {
+			p.names = append(p.names, sortTokens(alt))
+		}
+		// several dates of birth are separated by semicolons
+		for _, raw := range strings.Split(as.Entry.DateOfBirth, ";") {
+			if dob := normalizeDOB(raw); dob != "" {
+				p.dobs = append(p.dobs, dob)
+			}
+		}
+		out = append(out, p)
+	}
+	for i := range resp.UnitedNationsSanctions {
+		us := &resp.UnitedNationsSanctions[i]
+		if us.Entry == nil {
+			continue
+		}
+		p := &party{
+			entry:  entityEntry{sourceUN, us.Entry.ReferenceNumber, us.Entry.Name(), us.match},
+			names:  []string{sortTokens(us.name)},
+			remove: func() { us.match = -1 },
+			result: *us,
+		}
+		for _, alt := range us.Entry.Aliases {
+			p.names = append(p.names, sortTokens(alt))
+		}
+		for _, doc := range us.Entry.Documents {
+			if id := normalizeID(doc.Number); id != "" {
+				p.ids = append(p.ids, id)
+			}
+		}
+		for _, raw := range us.Entry.DatesOfBirth {
+			if dob := normalizeDOB(raw); dob != "" {
+				p.dobs = append(p.dobs, dob)
+			}
+		}
+		out = append(out, p)
+	}
+	for i := range resp.CustomEntries {
+		ce := &resp.CustomEntries[i]
+		if ce.Entry == nil {
+			continue
+		}
+		p := &party{
+			entry:  entityEntry{sourceCustom, ce.Entry.ID, ce.Entry.Name, ce.match},
+			names:  []string{sortTokens(ce.name)},
+			remove: func() { ce.match = -1 },
+			result: *ce,
+		}
+		for _, alt := range ce.Entry.Aliases {
+			p.names = append(p.names, sortTokens(alt))
+		}
+		for _, number := range ce.Entry.IDNumbers {
+			if id := normalizeID(number); id != "" {
+				p.ids = append(p.ids, id)
+			}
+		}
+		for _, raw := range ce.Entry.DatesOfBirth {
+			if dob := normalizeDOB(raw); dob != "" {
+				p.dobs = append(p.dobs, dob)
+			}
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// compact drops results which were moved into an entityGroup
+func (resp *searchResponse) compact() {
+	var sdns []SDN
+	for i := range resp.SDNs {
+		if resp.SDNs[i].match >= 0 {
+			sdns = append(sdns, resp.SDNs[i])
+		}
+	}
+	resp.SDNs = sdns
+
+	var ssis []SSI
+	for i := range resp.SectoralSanctions {
+		if resp.SectoralSanctions[i].match >= 0 {
+			ssis = append(ssis, resp.SectoralSanctions[i])
+		}
+	}
+	resp.SectoralSanctions = ssis
+
+	var cas []CanadianSanction
+	for i := range resp.CanadianSanctions {
+		if resp.CanadianSanctions[i].match >= 0 {
+			cas = append(cas, resp.CanadianSanctions[i])
+		}
+	}
+	resp.CanadianSanctions = cas
+
+	var aus []AustralianSanction
+	for i := range resp.AustralianSanctions {
+		if resp.AustralianSanctions[i].match >= 0 {
+			aus = append(aus, resp.AustralianSanctions[i])
+		}
+	}
+	resp.AustralianSanctions = aus
+
+	var uns []UnitedNationsSanction
+	for i := range resp.UnitedNationsSanctions {
+		if resp.UnitedNationsSanctions[i].match >= 0 {
+			uns = append(uns, resp.UnitedNationsSanctions[i])
+		}
+	}
+	resp.UnitedNationsSanctions = uns
+
+	var customs []CustomEntry
+	for i := range resp.CustomEntries {
+		if resp.CustomEntries[i].match >= 0 {
+			customs = append(customs, resp.CustomEntries[i])
+		}
+	}
+	resp.CustomEntries = customs
+}
+
+// sortTokens normalizes a name and sorts its tokens, so "MADURO MOROS, Nicolas" and
+// "Nicolas Maduro Moros" are equal.
+func sortTokens(name string) string {
+	tokens := strings.Fields(precompute(name))
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+func normalizeID(id string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, id)
+}
+
+var remarkDOBRegex = regexp.MustCompile(`DOB (?:circa )?([0-9]{1,2} [A-Za-z]{3} [0-9]{4}|[0-9]{4})`)
+
+// remarkDOBs finds each date of birth (e.g. "DOB 23 Nov 1962" or "DOB 1962") in SDN remarks
+func remarkDOBs(remarks string) []string {
+	var out []string
+	for _, m := range remarkDOBRegex.FindAllStringSubmatch(remarks, -1) {
+		if dob := normalizeDOB(m[1]); dob != "" {
+			out = append(out, dob)
+		}
+	}
+	return out
+}
+
+// normalizeDOB formats a date as YYYY-MM-DD, or YYYY when only the year is known
+func normalizeDOB(raw string) string {
+	raw = strings.TrimSpace(raw)
+	for _, layout := range []string{"2006-01-02", "02 Jan 2006", "2 Jan 2006", "02/01/2006", "2/1/2006"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	if t, err := time.Parse("2006", raw); err == nil {
+		return t.Format("2006")
+	}
+	return ""
+}
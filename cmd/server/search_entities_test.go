@@ -0,0 +1,210 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/au"
+	"github.com/moov-io/watchman/pkg/ca"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+var (
+	// entitySearcher has Nicolas Maduro on OFAC and Canada's list, along with a namesake
+	// with a different date of birth who isn't the same person
+	entitySearcher = &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{
+				EntityID: "22790",
+				SDNName:  "MADURO MOROS, Nicolas",
+				SDNType:  "individual",
+				Programs: []string{"VENEZUELA"},
+				Remarks:  "DOB 23 Nov 1962; POB Caracas, Venezuela; Cedula No. 5892464 (Venezuela).",
+			},
+		}, nil, noLogPipeliner),
+		CanadianSanctions: precomputeCanadianSanctions([]*ca.Entry{
+			{
+				Type:                       ca.TypeIndividual,
+				Country:                    "Venezuela",
+				LastName:                   "Maduro Moros",
+				GivenName:                  "Nicolas",
+				DateOfBirthOrShipBuildDate: "1962-11-23",
+				Schedule:                   "1",
+				Item:                       "41",
+			},
+			{
+				Type:                       ca.TypeIndividual,
+				Country:                    "Venezuela",
+				LastName:                   "Maduro Moros",
+				GivenName:                  "Nicolas",
+				DateOfBirthOrShipBuildDate: "1990-01-01",
+				Schedule:                   "1",
+				Item:                       "42",
+			},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+)
+
+func TestSearch__dedupeEntities(t *testing.T) {
+	opts := searchOptions{dedupeEntities: true}
+	resp := &searchResponse{
+		SDNs:              entitySearcher.TopSDNs(10, precompute("nicolas maduro"), opts),
+		CanadianSanctions: entitySearcher.TopCanadianSanctions(10, precompute("nicolas maduro"), opts),
+	}
+	resp.trim(opts)
+
+	if len(resp.Entities) != 1 {
+		t.Fatalf("entities: %#v", resp.Entities)
+	}
+	group := resp.Entities[0]
+	if len(group.Entries) != 2 {
+		t.Fatalf("entries: %#v", group.Entries)
+	}
+	sources := map[string]string{}
+	for _, e := range group.Entries {
+		sources[e.Source] = e.EntityID
+	}
+	if sources[sourceSDN] != "22790" || sources[sourceCA] != "Venezuela/1/41" {
+		t.Errorf("unexpected entries: %#v", group.Entries)
+	}
+
+	// grouped results are removed from their lists, the namesake is kept
+	if len(resp.SDNs) != 0 {
+		t.Errorf("SDNs: %#v", resp.SDNs)
+	}
+	if len(resp.CanadianSanctions) != 1 || resp.CanadianSanctions[0].Entry.Item != "42" {
+		t.Errorf("CanadianSanctions: %#v", resp.CanadianSanctions)
+	}
+
+	// without the option nothing is grouped
+	resp = &searchResponse{
+		SDNs:              entitySearcher.TopSDNs(10, precompute("nicolas maduro"), searchOptions{}),
+		CanadianSanctions: entitySearcher.TopCanadianSanctions(10, precompute("nicolas maduro"), searchOptions{}),
+	}
+	resp.trim(searchOptions{})
+	if len(resp.Entities) != 0 || len(resp.SDNs) != 1 || len(resp.CanadianSanctions) != 2 {
+		t.Errorf("unexpected response: %#v", resp)
+	}
+}
+
+func TestSearch__dedupeEntitiesCA(t *testing.T) {
+	// items are numbered within each country's schedule, so another country's item 41 is
+	// a different record
+	s := &searcher{
+		SDNs: entitySearcher.SDNs,
+		CanadianSanctions: precomputeCanadianSanctions([]*ca.Entry{
+			{Type: ca.TypeIndividual, Country: "Venezuela", LastName: "Maduro Moros", GivenName: "Nicolas", DateOfBirthOrShipBuildDate: "1962-11-23", Schedule: "1", Item: "41"},
+			{Type: ca.TypeIndividual, Country: "Russia", LastName: "Maduro Moros", GivenName: "Nicolas", DateOfBirthOrShipBuildDate: "1962-11-23", Schedule: "1", Item: "41"},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	opts := searchOptions{dedupeEntities: true}
+	resp := &searchResponse{
+		SDNs:              s.TopSDNs(10, precompute("nicolas maduro"), opts),
+		CanadianSanctions: s.TopCanadianSanctions(10, precompute("nicolas maduro"), opts),
+	}
+	resp.trim(opts)
+
+	if len(resp.Entities) != 1 || len(resp.Entities[0].Entries) != 3 {
+		t.Fatalf("entities: %#v", resp.Entities)
+	}
+	ids := make(map[string]bool)
+	for _, e := range resp.Entities[0].Entries {
+		ids[e.EntityID] = true
+	}
+	if !ids["Venezuela/1/41"] || !ids["Russia/1/41"] {
+		t.Errorf("entries: %#v", resp.Entities[0].Entries)
+	}
+}
+
+func TestSearch__dedupeEntitiesAU(t *testing.T) {
+	s := &searcher{
+		SDNs: entitySearcher.SDNs,
+		AustralianSanctions: precomputeAustralianSanctions([]*au.Entry{
+			{
+				Reference:   "2",
+				Type:        au.TypeIndividual,
+				Name:        "Nicolas MADURO MOROS",
+				DateOfBirth: "1960; 23/11/1962",
+			},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	opts := searchOptions{dedupeEntities: true}
+	resp := &searchResponse{
+		SDNs:                s.TopSDNs(10, precompute("nicolas maduro"), opts),
+		AustralianSanctions: s.TopAustralianSanctions(10, precompute("nicolas maduro"), opts),
+	}
+	resp.trim(opts)
+
+	if len(resp.Entities) != 1 || len(resp.Entities[0].Entries) != 2 {
+		t.Fatalf("entities: %#v", resp.Entities)
+	}
+	if len(resp.SDNs) != 0 || len(resp.AustralianSanctions) != 0 {
+		t.Errorf("unexpected response: %#v", resp)
+	}
+}
+
+func TestSearch__dedupeEntitiesHandler(t *testing.T) {
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, entitySearcher)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro&dedupeEntities=true", nil))
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	var resp struct {
+		SDNs     []json.RawMessage `json:"SDNs"`
+		Entities []entityGroup     `json:"entities"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.SDNs) != 0 || len(resp.Entities) != 1 {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+	if e := resp.Entities[0]; e.Name == "" || e.Match <= 0 || len(e.Entries) != 2 {
+		t.Errorf("unexpected entity: %#v", e)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro&dedupeEntities=sometimes", nil))
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
+
+func TestSearch__normalizeDOB(t *testing.T) {
+	eql := func(raw, expected string) {
+		t.Helper()
+		if v := normalizeDOB(raw); v != expected {
+			t.Errorf("%q: got %q", raw, v)
+		}
+	}
+	eql("1962-11-23", "1962-11-23")
+	eql("23 Nov 1962", "1962-11-23")
+	eql("3 Jul 1956", "1956-07-03")
+	eql("23/11/1962", "1962-11-23")
+	eql("2008", "2008")
+	eql("circa 1960", "")
+
+	dobs := remarkDOBs("DOB 10 Dec 1948; alt. DOB 1950; POB Egypt")
+	if len(dobs) != 2 || dobs[0] != "1948-12-10" || dobs[1] != "1950" {
+		t.Errorf("dobs: %#v", dobs)
+	}
+}
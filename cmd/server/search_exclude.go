@@ -0,0 +1,34 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// readExcludeIDs parses ?excludeIds=, a comma separated list of SDN entity IDs (e.g. hits which
+// were already cleared) to leave out of results. The parameter can also be repeated.
+func readExcludeIDs(u *url.URL) map[string]bool {
+	var out map[string]bool
+	for _, raw := range u.Query()["excludeIds"] {
+		for _, id := range strings.Split(raw, ",") {
+			if id = strings.TrimSpace(id); id == "" {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]bool)
+			}
+			out[id] = true
+		}
+	}
+	return out
+}
+
+// excluded returns true when results of the SDN entityID (including its alt names and
+// addresses) were excluded with ?excludeIds=
+func (opts searchOptions) excluded(entityID string) bool {
+	return opts.excludeIDs[entityID]
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestExcludeIDs__read(t *testing.T) {
+	u, _ := url.Parse("/search?excludeIds=1,+2,,&excludeIds=3")
+	ids := readExcludeIDs(u)
+	if len(ids) != 3 || !ids["1"] || !ids["2"] || !ids["3"] {
+		t.Errorf("%#v", ids)
+	}
+	u, _ = url.Parse("/search?excludeIds=+,")
+	if ids := readExcludeIDs(u); ids != nil {
+		t.Errorf("%#v", ids)
+	}
+}
+
+func TestExcludeIDs__search(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "SMITH, John", SDNType: "individual"},
+			{EntityID: "2", SDNName: "SMITH, Jon", SDNType: "individual"},
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual", Remarks: "Cedula No. 5892464 (Venezuela);"},
+		}, nil, noLogPipeliner),
+		Alts: precomputeAlts([]*ofac.AlternateIdentity{
+			{EntityID: "1", AlternateID: "10", AlternateType: "aka", AlternateName: "John SMITH"},
+			{EntityID: "2", AlternateID: "20", AlternateType: "aka", AlternateName: "Johnny SMITH"},
+		}),
+		Addresses: precomputeAddresses([]*ofac.Address{
+			{EntityID: "1", AddressID: "100", Address: "1 John Smith Way", Country: "United Kingdom"},
+		}),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	var resp struct {
+		SDNs []struct {
+			EntityID string `json:"entityID"`
+		} `json:"SDNs"`
+		AltNames []struct {
+			EntityID string `json:"entityID"`
+		} `json:"altNames"`
+		Addresses []struct {
+			EntityID string `json:"entityID"`
+		} `json:"addresses"`
+	}
+	search := func(t *testing.T, query string) {
+		t.Helper()
+		resp.SDNs, resp.AltNames, resp.Addresses = nil, nil, nil
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the exact match is the top result without exclusions
+	search(t, "name=john+smith&limit=1")
+	if len(resp.SDNs) != 1 || resp.SDNs[0].EntityID != "1" || len(resp.AltNames) != 1 || resp.AltNames[0].EntityID != "1" {
+		t.Fatalf("unexpected results: %#v", resp)
+	}
+
+	// excluded IDs are dropped before the limit, so the next result takes their place
+	search(t, "name=john+smith&limit=1&excludeIds=1")
+	if len(resp.SDNs) != 1 || resp.SDNs[0].EntityID != "2" || len(resp.AltNames) != 1 || resp.AltNames[0].EntityID != "2" {
+		t.Errorf("unexpected results: %#v", resp)
+	}
+
+	// searches of several names too
+	search(t, "name=john+smith&name=johnny+smith&limit=1")
+	if len(resp.SDNs) != 1 || resp.SDNs[0].EntityID != "1" {
+		t.Fatalf("unexpected results: %#v", resp)
+	}
+	search(t, "name=john+smith&name=johnny+smith&limit=1&excludeIds=1")
+	if len(resp.SDNs) != 1 || resp.SDNs[0].EntityID != "2" {
+		t.Errorf("unexpected results: %#v", resp)
+	}
+
+	// ?q= also searches addresses
+	search(t, "q=1+john+smith+way&excludeIds=1,22790")
+	for _, sdn := range resp.SDNs {
+		if sdn.EntityID == "1" || sdn.EntityID == "22790" {
+			t.Errorf("excluded SDN: %#v", resp.SDNs)
+		}
+	}
+	for _, addr := range resp.Addresses {
+		if addr.EntityID == "1" {
+			t.Errorf("excluded address: %#v", resp.Addresses)
+		}
+	}
+
+	// and ID searches
+	search(t, "id=5892464")
+	if len(resp.SDNs) != 1 {
+		t.Fatalf("SDNs=%#v", resp.SDNs)
+	}
+	search(t, "id=5892464&excludeIds=22790")
+	if len(resp.SDNs) != 0 {
+		t.Errorf("excluded SDN: %#v", resp.SDNs)
+	}
+}
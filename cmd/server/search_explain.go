@@ -0,0 +1,286 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/xrash/smetrics"
+)
+
+// searchExplain adds GET /search/explain, which traces how a query scores against one SDN for
+// tuning searches. It's meant for development so main only enables it with SEARCH_EXPLAIN=true.
+var searchExplain = false
+
+// searchExplanation is every step of scoring a query against an SDN's name
+type searchExplanation struct {
+	SDNID   string               `json:"sdnId"`
+	Query   explainedQuery       `json:"query"`
+	Indexed explainedIndexedName `json:"indexed"`
+
+	// Method is how names were compared: strict, wildcard, initials, tokens, full, concatenated,
+	// nickname, components or the name scorer of FIELD_SCORERS (tokenSet or exact)
+	Method       string `json:"method"`
+	ExactNumbers bool   `json:"exactNumbers"`
+
+	// NicknameQuery is the query with nicknames swapped which scored best (see nicknameScore),
+	// NameScore is its score lowered by nicknameWeight
+	NicknameQuery string `json:"nicknameQuery,omitempty"`
+
+	// Tokens are the scores of each indexed token against its best query token, only the
+	// counted ones are averaged into NameScore
+	Tokens    []explainedToken `json:"tokens,omitempty"`
+	NameScore float64          `json:"nameScore"`
+
+	// CalibratedScore is NameScore on the shared scale of SCORE_CALIBRATION, it's only set when
+	// the method has a calibration (the NameScore of familyFirst is already calibrated)
+	CalibratedScore float64 `json:"calibratedScore,omitempty"`
+
+	Penalties []explainedPenalty `json:"penalties,omitempty"`
+
+	// Match is the score the SDN has in GET /search results
+	Match float64 `json:"match"`
+}
+
+type explainedQuery struct {
+	Raw        string `json:"raw"`
+	Normalized string `json:"normalized"`
+
+	// Scored is the query compared against the name, which is without titles for individuals and without leading articles for entities
+	Scored string `json:"scored"`
+}
+
+type explainedIndexedName struct {
+	Raw        string `json:"raw"`
+	Normalized string `json:"normalized"`
+	Individual bool   `json:"individual"`
+
+	// FamilyFirst is the native order of a CJK name, which is also compared with the query
+	FamilyFirst string `json:"familyFirst,omitempty"`
+}
+
+type explainedToken struct {
+	Indexed string  `json:"indexed"`
+	Query   string  `json:"query"`
+	Score   float64 `json:"score"`
+	Counted bool    `json:"counted"`
+}
+
+// explainedPenalty multiplies the name score, a multiplier of zero means the SDN isn't a result
+type explainedPenalty struct {
+	Name       string  `json:"name"`
+	Multiplier float64 `json:"multiplier"`
+	Reason     string  `json:"reason"`
+}
+
+func searchExplainHandler(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		name := strings.TrimSpace(r.URL.Query().Get("name"))
+		if emptyQuery(name) {
+			moovhttp.Problem(w, errNoNameParam)
+			return
+		}
+		sdnID := strings.TrimSpace(r.URL.Query().Get("sdnId"))
+		if sdnID == "" {
+			moovhttp.Problem(w, errNoSDNId)
+			return
+		}
+		opts, err := readSearchOptions(r.URL)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+
+		sdn := searcher.debugSDN(sdnID)
+		if sdn == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		requestID, userID := moovhttp.GetRequestID(r), moovhttp.GetUserID(r)
+		logger.Log("search", fmt.Sprintf("explaining %s against SDN=%s", redact(name), sdnID), "requestID", requestID, "userID", userID)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(explainSDN(sdn, name, opts))
+	}
+}
+
+// explainSDN traces how TopSDNs scores name against sdn
+func explainSDN(sdn *SDN, name string, opts searchOptions) *searchExplanation {
+	individual := strings.EqualFold(sdn.SDNType, "individual")
+	out := &searchExplanation{
+		SDNID: sdn.EntityID,
+		Query: explainedQuery{
+			Raw:        name,
+			Normalized: precompute(name),
+		},
+		Indexed: explainedIndexedName{
+			Raw:         sdn.SDNName,
+			Normalized:  sdn.name,
+			Individual:  individual,
+			FamilyFirst: sdn.familyFirst,
+		},
+	}
+	query := nameArticles.strip(out.Query.Normalized)
+	if individual {
+		query = nameHonorifics.strip(out.Query.Normalized)
+	}
+	out.Query.Scored = query
+
+	switch {
+	case opts.strict:
+		out.Method = "strict"
+		out.NameScore = opts.scoreName(individual, sdn.name, sdn.exact, query)
+	case opts.matchMode == matchModeWildcard:
+		out.Method = matchModeWildcard
+		out.NameScore = wildcardMatch(sdn.name, query)
+	case opts.matchMode == matchModeInitials:
+		out.Method = matchModeInitials
+		out.Tokens, out.NameScore = explainTokens(sdn.name, query, initialTokenMatch)
+	case scorerName(searchScorers.name) != scorerJaroWinkler:
+		out.Method = scorerName(searchScorers.name)
+		out.NameScore = searchScorers.name.score(sdn.name, query)
+	case opts.nameScoring == nameScoringFull:
+		out.Method = nameScoringFull
+		out.NameScore = fullJaroWinkler(sdn.name, query)
+		out.explainConcatenated(sdn.name, query, opts.scoreFloor, tokenUpperBound(sdn.name, query))
+	default:
+		out.Method = nameScoringTokens
+		out.ExactNumbers = exactNumbers(individual)
+		if out.ExactNumbers {
+			out.Tokens, out.NameScore = explainTokens(sdn.name, query, numericTokenMatch)
+		} else {
+			out.Tokens, out.NameScore = explainTokens(sdn.name, query, func(a, b string) float64 {
+				return smetrics.JaroWinkler(a, b, 0.7, 4)
+			})
+		}
+		out.explainConcatenated(sdn.name, query, opts.scoreFloor, jaroWinklerUpperBound(sdn.name, query))
+	}
+	if opts.nicknames && !opts.strict && opts.matchMode != matchModeWildcard {
+		if nickname, q := opts.nicknameScore(individual, sdn.name, query); nickname > out.NameScore {
+			out.Method, out.NicknameQuery = "nickname", q
+			out.Tokens, out.NameScore = nil, nickname
+		}
+	}
+	if curve, ok := opts.calibration(); ok {
+		out.CalibratedScore = curve.apply(out.NameScore)
+	}
+	components, byComponents := opts.scoreComponents(sdn)
+	if byComponents {
+		out.Method = "components"
+		out.Tokens, out.NameScore, out.CalibratedScore, out.NicknameQuery = nil, components, 0, ""
+	}
+
+	if opts.nameFrequency && !opts.strict && opts.matchMode != matchModeWildcard && !byComponents {
+		if weight := nameFrequencyWeight(query); weight < 1.0 {
+			out.penalize("nameFrequency", weight, "the query is made of common names")
+		}
+	}
+	if individual && opts.entityBias && !opts.strict && opts.matchMode != matchModeWildcard && !byComponents && nameSuffixes.suffixed(query) {
+		out.penalize("entityBias", businessQueryIndividualWeight, "the query has a business suffix but the SDN is an individual")
+	}
+	enough := opts.enoughNameTokens(individual, sdn.name, query)
+	if !enough {
+		out.penalize("minNameTokens", 0, fmt.Sprintf("fewer than %d query tokens match the name", opts.minNameTokens))
+	}
+
+	// the match comes from the same scoring as searches, the trace above explains it
+	var match float64
+	switch {
+	case enough && byComponents:
+		match = components
+	case enough:
+		match = opts.scoreRecord(individual, sdn.name, query)
+		if other, ok := opts.scoreCJKOrder(sdn, individual, "", sdn.name, query); ok && other > match {
+			// the name's score before the penalties listed above
+			unweighted := opts
+			unweighted.nameFrequency, unweighted.entityBias = false, false
+			out.Method = "familyFirst"
+			out.Tokens, out.NameScore, out.CalibratedScore, out.NicknameQuery = nil, unweighted.scoreRecord(individual, sdn.familyFirst, query), 0, ""
+			match = other
+		}
+	}
+	match, adjustment := adjustSourceScore(sourceSDN, match)
+	if adjustment.SourceMultiplier > 0 {
+		out.penalize("sourceMultiplier", adjustment.SourceMultiplier, fmt.Sprintf("%s results are multiplied by SOURCE_SCORE_MULTIPLIERS", sourceSDN))
+	}
+	out.Match = match
+	return out
+}
+
+func (e *searchExplanation) penalize(name string, multiplier float64, reason string) {
+	e.Penalties = append(e.Penalties, explainedPenalty{Name: name, Multiplier: multiplier, Reason: reason})
+}
+
+// explainScoreFloor adds a penalty when the name's upper bound skipped scoring it
+func (e *searchExplanation) explainScoreFloor(floor, upperBound float64) {
+	if floor > 0 && upperBound+1e-9 < floor {
+		e.penalize("scoreFloor", 0, fmt.Sprintf("the upper bound %.3f is below the score floor %.3f", upperBound, floor))
+	}
+}
+
+// explainConcatenated explains the name score of fuzzy scoring, which is concatenatedNameScore
+// instead when it's higher (or the upper bound skipped scoring the name)
+func (e *searchExplanation) explainConcatenated(indexed, query string, floor, upperBound float64) {
+	score := e.NameScore
+	if floor > 0 && upperBound+1e-9 < floor {
+		score = 0.0
+	}
+	if concatenated := concatenatedNameScore(indexed, query, floor); concatenated > score {
+		e.Method, e.Tokens, e.NameScore = "concatenated", nil, concatenated
+		return
+	}
+	e.explainScoreFloor(floor, upperBound)
+}
+
+// explainTokens scores each token of indexed against query like averageTokenScores, and returns
+// the tokens along with their average
+func explainTokens(indexed, query string, score func(a, b string) float64) ([]explainedToken, float64) {
+	indexedParts, queryParts := strings.Fields(indexed), strings.Fields(query)
+	if len(indexedParts) == 0 || len(queryParts) == 0 {
+		return nil, 0.0
+	}
+
+	out := make([]explainedToken, len(indexedParts))
+	for i := range indexedParts {
+		out[i] = explainedToken{Indexed: indexedParts[i], Query: queryParts[0], Score: score(indexedParts[i], queryParts[0]), Counted: true}
+		for j := 1; j < len(queryParts); j++ {
+			if s := score(indexedParts[i], queryParts[j]); s > out[i].Score {
+				out[i].Query, out[i].Score = queryParts[j], s
+			}
+		}
+	}
+
+	// the lowest scores are dropped when the name has more tokens than the query
+	order := make([]int, len(out))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return out[order[i]].Score < out[order[j]].Score })
+	if len(indexedParts) > len(queryParts) {
+		for _, i := range order[:len(queryParts)-1] {
+			out[i].Counted = false
+		}
+	}
+
+	var sum, n float64
+	for _, i := range order {
+		if out[i].Counted {
+			sum += out[i].Score
+			n++
+		}
+	}
+	return out, sum / n
+}
@@ -0,0 +1,139 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+	"github.com/xrash/smetrics"
+)
+
+func TestSearchExplain__maduro(t *testing.T) {
+	sdn := idSearcher.SDNs[0] // 22790 "MADURO MOROS, Nicolas"
+	explained := explainSDN(sdn, "Dr. Nicolas Maduro", searchOptions{nameScoring: nameScoringTokens})
+
+	if explained.Query.Normalized != "dr nicolas maduro" || explained.Query.Scored != "nicolas maduro" {
+		t.Errorf("query: %#v", explained.Query)
+	}
+	if explained.Indexed.Normalized != "nicolas maduro moros" || !explained.Indexed.Individual {
+		t.Errorf("indexed: %#v", explained.Indexed)
+	}
+	if explained.Method != nameScoringTokens || explained.ExactNumbers {
+		t.Errorf("method=%s exactNumbers=%v", explained.Method, explained.ExactNumbers)
+	}
+
+	// moros is the lowest scoring token of the three, so it's dropped from the average
+	expected := []explainedToken{
+		{Indexed: "nicolas", Query: "nicolas", Score: 1.0, Counted: true},
+		{Indexed: "maduro", Query: "maduro", Score: 1.0, Counted: true},
+		{Indexed: "moros", Query: "maduro", Score: smetrics.JaroWinkler("moros", "maduro", 0.7, 4), Counted: false},
+	}
+	if len(explained.Tokens) != len(expected) {
+		t.Fatalf("tokens: %#v", explained.Tokens)
+	}
+	for i := range expected {
+		if explained.Tokens[i] != expected[i] {
+			t.Errorf("tokens[%d]=%#v expected %#v", i, explained.Tokens[i], expected[i])
+		}
+	}
+	eql(t, "nameScore", explained.NameScore, 1.0)
+	eql(t, "match", explained.Match, 1.0)
+	if len(explained.Penalties) != 0 {
+		t.Errorf("penalties: %#v", explained.Penalties)
+	}
+}
+
+func TestSearchExplain__penalties(t *testing.T) {
+	defer func(v map[string]float64) { sourceScoreMultipliers = v }(sourceScoreMultipliers)
+	sourceScoreMultipliers = map[string]float64{sourceSDN: 0.9}
+
+	sdn := idSearcher.SDNs[0]
+	explained := explainSDN(sdn, "maduro", searchOptions{nameFrequency: true, minNameTokens: 2})
+	if len(explained.Penalties) != 2 {
+		t.Fatalf("penalties: %#v", explained.Penalties)
+	}
+	if p := explained.Penalties[0]; p.Name != "minNameTokens" || p.Multiplier != 0 {
+		t.Errorf("penalties[0]=%#v", p)
+	}
+	if p := explained.Penalties[1]; p.Name != "sourceMultiplier" || p.Multiplier != 0.9 {
+		t.Errorf("penalties[1]=%#v", p)
+	}
+	eql(t, "match", explained.Match, 0.0)
+
+	// the trace matches how TopSDNs scores the SDN
+	opts := searchOptions{nameFrequency: true}
+	explained = explainSDN(sdn, "nicolas maduro", opts)
+	sdns := idSearcher.TopSDNs(1, "nicolas maduro", opts)
+	if len(sdns) != 1 {
+		t.Fatalf("%#v", sdns)
+	}
+	eql(t, "match", explained.Match, sdns[0].match)
+	eql(t, "nameScore", explained.NameScore*0.9, sdns[0].match)
+}
+
+func TestSearchExplain__numbers(t *testing.T) {
+	sdns := precomputeSDNs([]*ofac.SDN{{EntityID: "1", SDNName: "BANK 123"}}, nil, noLogPipeliner)
+	explained := explainSDN(sdns[0], "bank 124", searchOptions{})
+	if !explained.ExactNumbers || len(explained.Tokens) != 2 {
+		t.Fatalf("%#v", explained)
+	}
+	eql(t, "bank", explained.Tokens[0].Score, 1.0)
+	eql(t, "123", explained.Tokens[1].Score, 0.0)
+	eql(t, "nameScore", explained.NameScore, 0.5)
+	eql(t, "match", explained.Match, 0.5)
+}
+
+func TestSearchExplain__handler(t *testing.T) {
+	defer func(v bool) { searchExplain = v }(searchExplain)
+
+	// the route is only added when enabled
+	searchExplain = false
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search/explain?name=nicolas+maduro&sdnId=22790", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+
+	searchExplain = true
+	router = mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search/explain?name=nicolas+maduro&sdnId=22790&nameScoring=full", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	var explained searchExplanation
+	if err := json.NewDecoder(w.Body).Decode(&explained); err != nil {
+		t.Fatal(err)
+	}
+	if explained.SDNID != "22790" || explained.Method != nameScoringFull || len(explained.Tokens) != 0 {
+		t.Errorf("%#v", explained)
+	}
+	eql(t, "match", explained.Match, fullJaroWinkler("nicolas maduro moros", "nicolas maduro"))
+
+	for _, path := range []string{"/search/explain?sdnId=22790", "/search/explain?name=maduro", "/search/explain?name=maduro&sdnId=22790&matchMode=other"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: bogus status code: %d", path, w.Code)
+		}
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search/explain?name=maduro&sdnId=99999", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
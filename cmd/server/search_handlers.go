@@ -10,6 +10,7 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -31,7 +32,16 @@ var (
 )
 
 func addSearchRoutes(logger log.Logger, r *mux.Router, searcher *searcher) {
-	r.Methods("GET").Path("/search").HandlerFunc(search(logger, searcher))
+	maxAge := newDataAgeGuard(searcher, searchMaxDataAge)
+	r.Methods("GET").Path("/search").HandlerFunc(searchConcurrency.wrap(maxAge.wrap(search(logger, searcher))))
+	r.Methods("POST").Path("/search").HandlerFunc(searchConcurrency.wrap(maxAge.wrap(searchViaPost(logger, searcher))))
+	r.Methods("POST").Path("/search/batch").HandlerFunc(searchConcurrency.wrap(maxAge.wrap(searchBatch(logger, searcher))))
+	r.Methods("POST").Path("/search/batch/stream").HandlerFunc(searchConcurrency.wrap(maxAge.wrap(searchBatchStream(logger, searcher))))
+	r.Methods("POST").Path("/search/replay").HandlerFunc(searchConcurrency.wrap(maxAge.wrap(searchReplay(logger, searcher))))
+	r.Methods("GET").Path("/search/schema").HandlerFunc(searchSchemaHandler(logger))
+	if searchExplain {
+		r.Methods("GET").Path("/search/explain").HandlerFunc(searchConcurrency.wrap(maxAge.wrap(searchExplainHandler(logger, searcher))))
+	}
 }
 
 type addressSearchRequest struct {
@@ -59,48 +69,94 @@ func readAddressSearchRequest(u *url.URL) addressSearchRequest {
 	}
 }
 
+// dataVersionHeader identifies the data a search ran against. Its value is the timestamp
+// (RFC 3339 with nanoseconds, UTC) of the download, which is also listed in GET /downloads.
+const dataVersionHeader = "X-Watchman-Data-Version"
+
+// dataVersion returns the value of dataVersionHeader, or an empty string before data is loaded
+func (s *searcher) dataVersion() string {
+	s.RLock()
+	defer s.RUnlock()
+
+	if s.lastRefreshedAt.IsZero() {
+		return ""
+	}
+	return s.lastRefreshedAt.UTC().Format(time.RFC3339Nano)
+}
+
 func search(logger log.Logger, searcher *searcher) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w = wrapResponseWriter(logger, w, r)
 		requestID, userID := moovhttp.GetRequestID(r), moovhttp.GetUserID(r)
 
+		if version := searcher.dataVersion(); version != "" {
+			w.Header().Set(dataVersionHeader, version)
+		}
+		if stale := searcher.staleSourceNames(); len(stale) > 0 {
+			w.Header().Set(staleSourcesHeader, strings.Join(stale, ","))
+		}
+
+		if _, err := readSources(r.URL); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		if err := checkEmptyFields(r.URL); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		opts, err := readSearchOptions(r.URL)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		if err := checkRequiredFields(r.URL); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		r, cancel := withSearchTimeout(r, opts)
+		defer cancel()
+
 		// Search over all fields
 		if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
-			logger.Log("search", fmt.Sprintf("searching all names and address for %s", q), "requestID", requestID, "userID", userID)
+			logger.Log("search", fmt.Sprintf("searching all names and address for %s", redact(q)), "requestID", requestID, "userID", userID)
 			searchViaQ(logger, searcher, q)(w, r)
 			return
 		}
 
 		// Search by ID (found in an SDN's Remarks property)
 		if id := strings.TrimSpace(r.URL.Query().Get("id")); id != "" {
-			logger.Log("search", fmt.Sprintf("searching SDNs by remarks ID for %s", id))
+			logger.Log("search", fmt.Sprintf("searching SDNs by remarks ID for %s", redact(id)))
 			searchByRemarksID(logger, searcher, id)(w, r)
 			return
 		}
 
-		// Search by Name
-		if name := strings.TrimSpace(r.URL.Query().Get("name")); name != "" {
+		// Search by Name, or its components
+		name := strings.TrimSpace(r.URL.Query().Get("name"))
+		if name == "" && opts.nameComponents != nil {
+			name = opts.nameComponents.String()
+		}
+		if name != "" {
 			if req := readAddressSearchRequest(r.URL); !req.empty() {
-				logger.Log("search", fmt.Sprintf("searching SDN names='%s' and addresses", name), "requestID", requestID, "userID", userID)
+				logger.Log("search", fmt.Sprintf("searching SDN names='%s' and addresses", redact(name)), "requestID", requestID, "userID", userID)
 				searchViaAddressAndName(logger, searcher, name, req)(w, r)
 				return
 			}
 
-			logger.Log("search", fmt.Sprintf("searching SDN names for %s", name), "requestID", requestID, "userID", userID)
+			logger.Log("search", fmt.Sprintf("searching SDN names for %s", redact(name)), "requestID", requestID, "userID", userID)
 			searchByName(logger, searcher, name)(w, r)
 			return
 		}
 
 		// Search by Alt Name
 		if alt := strings.TrimSpace(r.URL.Query().Get("altName")); alt != "" {
-			logger.Log("search", fmt.Sprintf("searching SDN alt names for %s", alt), "requestID", requestID, "userID", userID)
+			logger.Log("search", fmt.Sprintf("searching SDN alt names for %s", redact(alt)), "requestID", requestID, "userID", userID)
 			searchByAltName(logger, searcher, alt)(w, r)
 			return
 		}
 
 		// Search Addresses
 		if req := readAddressSearchRequest(r.URL); !req.empty() {
-			logger.Log("search", fmt.Sprintf("searching address for %#v", req), "requestID", requestID, "userID", userID)
+			logger.Log("search", fmt.Sprintf("searching address for %#v", redact(req)), "requestID", requestID, "userID", userID)
 			searchByAddress(logger, searcher, req)(w, r)
 			return
 		}
@@ -119,31 +175,70 @@ type searchResponse struct {
 	// BIS
 	DeniedPersons []DP        `json:"deniedPersons"`
 	BISEntities   []BISEntity `json:"bisEntities"`
+	// State Department
+	NonproliferationSanctions []ISN `json:"nonproliferationSanctions"`
+	// Global Affairs Canada
+	CanadianSanctions []CanadianSanction `json:"canadianSanctions"`
+	// Australian Department of Foreign Affairs and Trade
+	AustralianSanctions []AustralianSanction `json:"australianSanctions"`
+	// United Nations Security Council
+	UnitedNationsSanctions []UnitedNationsSanction `json:"unitedNationsSanctions"`
+	// Custom list (CUSTOM_LIST_FILE)
+	CustomEntries []CustomEntry `json:"customEntries"`
+	// Parties found on several lists, only with ?dedupeEntities=true
+	Entities []entityGroup `json:"entities,omitempty"`
+	// Every result grouped by confidence, only with ?group=bands
+	Bands *resultBands `json:"bands,omitempty"`
+	// Self-matches of the internal entity searched for (INTERNAL_ENTITIES_FILE)
+	Suppressed []suppressedResult `json:"suppressed,omitempty"`
+	// Results above minMatch before the limit was applied, only with ?totalMatches=true
+	TotalMatches *int `json:"totalMatches,omitempty"`
+	// Decision of the best result (clear, review or block), see SEARCH_DECISION_THRESHOLDS
+	Decision string `json:"decision"`
 	// Metadata
 	RefreshedAt time.Time `json:"refreshedAt"`
 }
 
-func buildAddressCompares(req addressSearchRequest) []func(*Address) *item {
-	var compares []func(*Address) *item
-	if req.Address != "" {
-		compares = append(compares, topAddressesAddress(req.Address))
+// highlightRequested returns true when the caller asked for token alignments via ?highlight=true
+func highlightRequested(u *url.URL) bool {
+	v, _ := strconv.ParseBool(u.Query().Get("highlight"))
+	return v
+}
+
+// highlight computes the token alignment between query and each name based result.
+// This is kept out of search responses unless requested as it's only needed for display.
+func (resp *searchResponse) highlight(query string) {
+	query = precompute(query)
+	for i := range resp.SDNs {
+		resp.SDNs[i].highlights = alignTokens(resp.SDNs[i].name, query)
+	}
+	for i := range resp.AltNames {
+		resp.AltNames[i].highlights = alignTokens(resp.AltNames[i].name, query)
+	}
+	for i := range resp.SectoralSanctions {
+		resp.SectoralSanctions[i].highlights = alignTokens(resp.SectoralSanctions[i].name, query)
+	}
+	for i := range resp.DeniedPersons {
+		resp.DeniedPersons[i].highlights = alignTokens(resp.DeniedPersons[i].name, query)
 	}
-	if req.City != "" {
-		compares = append(compares, topAddressesCityState(req.City))
+	for i := range resp.BISEntities {
+		resp.BISEntities[i].highlights = alignTokens(resp.BISEntities[i].name, query)
 	}
-	if req.State != "" {
-		compares = append(compares, topAddressesCityState(req.State))
+	for i := range resp.NonproliferationSanctions {
+		resp.NonproliferationSanctions[i].highlights = alignTokens(resp.NonproliferationSanctions[i].name, query)
 	}
-	if req.Providence != "" {
-		compares = append(compares, topAddressesCityState(req.Providence))
+	for i := range resp.CanadianSanctions {
+		resp.CanadianSanctions[i].highlights = alignTokens(resp.CanadianSanctions[i].name, query)
 	}
-	if req.Zip != "" {
-		compares = append(compares, topAddressesCityState(req.Zip))
+	for i := range resp.AustralianSanctions {
+		resp.AustralianSanctions[i].highlights = alignTokens(resp.AustralianSanctions[i].name, query)
 	}
-	if req.Country != "" {
-		compares = append(compares, topAddressesCountry(req.Country))
+	for i := range resp.UnitedNationsSanctions {
+		resp.UnitedNationsSanctions[i].highlights = alignTokens(resp.UnitedNationsSanctions[i].name, query)
+	}
+	for i := range resp.CustomEntries {
+		resp.CustomEntries[i].highlights = alignTokens(resp.CustomEntries[i].name, query)
 	}
-	return compares
 }
 
 func searchByAddress(logger log.Logger, searcher *searcher, req addressSearchRequest) http.HandlerFunc {
@@ -157,13 +252,17 @@ func searchByAddress(logger log.Logger, searcher *searcher, req addressSearchReq
 			RefreshedAt: searcher.lastRefreshedAt,
 		}
 		limit := extractSearchLimit(r)
+		opts := requestSearchOptions(r).forAddress(req)
 
 		// Perform our ranking across all accumulated compare functions
 		//
 		// TODO(adam): Is there something in the (SDN?) files which signal to block an entire country? (i.e. Needing to block Iran all together)
 		// https://www.treasury.gov/resource-center/sanctions/CivPen/Documents/20190327_decker_settlement.pdf
-		compares := buildAddressCompares(req)
-		resp.Addresses = searcher.TopAddressesFn(limit, multiAddressCompare(compares...))
+		resp.Addresses = searcher.TopAddressesFn(limit, opts, weightedAddressCompare(req, addressScoreWeights, opts))
+		if searchTimedOut(w, r) {
+			return
+		}
+		resp.trim(opts)
 
 		// record Prometheus metrics
 		if len(resp.Addresses) > 0 {
@@ -186,9 +285,17 @@ func searchViaQ(logger log.Logger, searcher *searcher, name string) http.Handler
 			return
 		}
 		limit := extractSearchLimit(r)
+		opts := requestSearchOptions(r)
 
 		// Perform multiple searches over the set of SDNs
-		resp := buildFullSearchResponse(searcher, buildFilterRequest(r.URL), limit, name)
+		resp := buildFullSearchResponse(searcher, buildFilterRequest(r.URL), opts, limit, name)
+		if searchTimedOut(w, r) {
+			return
+		}
+		resp.trim(opts)
+		if highlightRequested(r.URL) {
+			resp.highlight(name)
+		}
 
 		// record Prometheus metrics
 		if len(resp.SDNs) > 0 {
@@ -205,42 +312,87 @@ func searchViaQ(logger log.Logger, searcher *searcher, name string) http.Handler
 }
 
 // searchGather performs an inmem search with *searcher and mutates *searchResponse by setting a specific field
-type searchGather func(searcher *searcher, filters filterRequest, limit int, name string, resp *searchResponse)
+type searchGather func(searcher *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse)
 
 var (
 	gatherings = []searchGather{
 		// OFAC SDN Search
-		func(s *searcher, filters filterRequest, limit int, name string, resp *searchResponse) {
-			sdns := s.FindSDNsByRemarksID(limit, name)
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if !filters.sources.includes(sourceSDN) {
+				return
+			}
+			sdns := s.findSDNsByRemarksID(limit, name, opts)
 			if len(sdns) == 0 {
-				sdns = s.TopSDNs(limit, name)
+				sdns = s.TopSDNs(limit, name, opts)
+			} else {
+				opts.countMatches(len(sdns))
 			}
 			resp.SDNs = filterSDNs(sdns, filters)
 		},
 		// OFAC SDN Alt Names
-		func(s *searcher, _ filterRequest, limit int, name string, resp *searchResponse) {
-			resp.AltNames = s.TopAltNames(limit, name)
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceSDN) {
+				resp.AltNames = s.TopAltNames(limit, name, opts)
+			}
 		},
 		// OFAC Addresses
-		func(s *searcher, _ filterRequest, limit int, name string, resp *searchResponse) {
-			resp.Addresses = s.TopAddresses(limit, name)
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceSDN) {
+				resp.Addresses = s.TopAddresses(limit, name, opts)
+			}
 		},
 		// OFAC Sectoral Sanctions Identifications
-		func(s *searcher, _ filterRequest, limit int, name string, resp *searchResponse) {
-			resp.SectoralSanctions = s.TopSSIs(limit, name)
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceSSI) {
+				resp.SectoralSanctions = s.TopSSIs(limit, name, opts)
+			}
 		},
 		// BIS Denied Persons
-		func(s *searcher, _ filterRequest, limit int, name string, resp *searchResponse) {
-			resp.DeniedPersons = s.TopDPs(limit, name)
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceDPL) {
+				resp.DeniedPersons = s.TopDPs(limit, name, opts)
+			}
 		},
 		// BIS Entity List
-		func(s *searcher, _ filterRequest, limit int, name string, resp *searchResponse) {
-			resp.BISEntities = s.TopBISEntities(limit, name)
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceEL) {
+				resp.BISEntities = s.TopBISEntities(limit, name, opts)
+			}
+		},
+		// State Department Nonproliferation Sanctions
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceISN) {
+				resp.NonproliferationSanctions = s.TopISNs(limit, name, opts)
+			}
+		},
+		// Global Affairs Canada Consolidated Autonomous Sanctions
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceCA) {
+				resp.CanadianSanctions = s.TopCanadianSanctions(limit, name, opts)
+			}
+		},
+		// Australian DFAT Consolidated List
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceAU) {
+				resp.AustralianSanctions = s.TopAustralianSanctions(limit, name, opts)
+			}
+		},
+		// UN Security Council Consolidated List
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceUN) {
+				resp.UnitedNationsSanctions = s.TopUnitedNationsSanctions(limit, name, opts)
+			}
+		},
+		// Custom list
+		func(s *searcher, filters filterRequest, opts searchOptions, limit int, name string, resp *searchResponse) {
+			if filters.sources.includes(sourceCustom) {
+				resp.CustomEntries = s.TopCustomEntries(limit, name, opts)
+			}
 		},
 	}
 )
 
-func buildFullSearchResponse(searcher *searcher, filters filterRequest, limit int, name string) *searchResponse {
+func buildFullSearchResponse(searcher *searcher, filters filterRequest, opts searchOptions, limit int, name string) *searchResponse {
 	resp := searchResponse{
 		RefreshedAt: searcher.lastRefreshedAt,
 	}
@@ -248,7 +400,7 @@ func buildFullSearchResponse(searcher *searcher, filters filterRequest, limit in
 	wg.Add(len(gatherings))
 	for i := range gatherings {
 		go func(i int) {
-			gatherings[i](searcher, filters, limit, name, &resp)
+			gatherings[i](searcher, filters, opts, limit, name, &resp)
 			wg.Done()
 		}(i)
 	}
@@ -265,12 +417,15 @@ func searchViaAddressAndName(logger log.Logger, searcher *searcher, name string,
 		}
 
 		limit := extractSearchLimit(r)
+		opts := requestSearchOptions(r).forAddress(req)
 
 		// Grab the top SDNs by name and top addresses
-		sdns := filterSDNs(searcher.TopSDNs(limit, name), buildFilterRequest(r.URL))
+		sdns := filterSDNs(searcher.TopSDNs(limit, name, opts), buildFilterRequest(r.URL))
 
-		compares := buildAddressCompares(req)
-		addresses := searcher.TopAddressesFn(limit, multiAddressCompare(compares...))
+		addresses := searcher.TopAddressesFn(limit, opts, weightedAddressCompare(req, addressScoreWeights, opts))
+		if searchTimedOut(w, r) {
+			return
+		}
 
 		resp := &searchResponse{
 			RefreshedAt: searcher.lastRefreshedAt,
@@ -283,6 +438,12 @@ func searchViaAddressAndName(logger log.Logger, searcher *searcher, name string,
 				}
 			}
 		}
+		resp.combineAddressMatches(opts.combination)
+		resp.trim(opts)
+
+		if highlightRequested(r.URL) {
+			resp.highlight(name)
+		}
 
 		// record Prometheus metrics
 		if len(resp.SDNs) > 0 && len(resp.Addresses) > 0 {
@@ -305,7 +466,8 @@ func searchByRemarksID(logger log.Logger, searcher *searcher, id string) http.Ha
 		}
 
 		limit := extractSearchLimit(r)
-		sdns := searcher.FindSDNsByRemarksID(limit, id)
+		opts, _ := readSearchOptions(r.URL)
+		sdns := searcher.findSDNsByRemarksID(limit, id, opts)
 		sdns = filterSDNs(sdns, buildFilterRequest(r.URL))
 
 		// record Prometheus metrics
@@ -315,12 +477,26 @@ func searchByRemarksID(logger log.Logger, searcher *searcher, id string) http.Ha
 			matchHist.With("type", "remarksID").Observe(0.0)
 		}
 
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(&searchResponse{
+		resp := &searchResponse{
 			SDNs:        sdns,
 			RefreshedAt: searcher.lastRefreshedAt,
-		})
+		}
+		if !opts.includeRemarks {
+			resp.hideRemarks()
+		}
+		if opts.includeAddresses {
+			resp.includeSDNAddresses()
+		}
+		if opts.includeDetails {
+			resp.includeSDNDetails()
+		}
+		opts.countMatches(len(sdns))
+		resp.TotalMatches = opts.totalMatches()
+		resp.Decision = searchDecisionThresholds.decide(resp.topMatch())
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
 	}
 }
 
@@ -333,31 +509,70 @@ func searchByName(logger log.Logger, searcher *searcher, nameSlug string) http.H
 		}
 
 		limit := extractSearchLimit(r)
+		filters := buildFilterRequest(r.URL)
+		opts := requestSearchOptions(r)
 
-		// Grab the SDN's and then filter any out based on query params
-		sdns := searcher.TopSDNs(limit, nameSlug)
-		sdns = filterSDNs(sdns, buildFilterRequest(r.URL))
+		resp := &searchResponse{
+			RefreshedAt: searcher.lastRefreshedAt,
+		}
+		// OFAC
+		if filters.sources.includes(sourceSDN) {
+			// Grab the SDN's and then filter any out based on query params
+			if names := readNameVariants(r.URL); len(names) > 1 {
+				resp.SDNs = filterSDNs(searcher.TopSDNsByNames(limit, names, opts), filters)
+			} else {
+				resp.SDNs = filterSDNs(searcher.TopSDNs(limit, nameSlug, opts), filters)
+			}
+			resp.AltNames = searcher.TopAltNames(limit, nameSlug, opts)
+		}
+		if filters.sources.includes(sourceSSI) {
+			resp.SectoralSanctions = searcher.TopSSIs(limit, nameSlug, opts)
+		}
+		// BIS
+		if filters.sources.includes(sourceDPL) {
+			resp.DeniedPersons = searcher.TopDPs(limit, nameSlug, opts)
+		}
+		if filters.sources.includes(sourceEL) {
+			resp.BISEntities = searcher.TopBISEntities(limit, nameSlug, opts)
+		}
+		// State Department
+		if filters.sources.includes(sourceISN) {
+			resp.NonproliferationSanctions = searcher.TopISNs(limit, nameSlug, opts)
+		}
+		// Global Affairs Canada
+		if filters.sources.includes(sourceCA) {
+			resp.CanadianSanctions = searcher.TopCanadianSanctions(limit, nameSlug, opts)
+		}
+		// Australian DFAT
+		if filters.sources.includes(sourceAU) {
+			resp.AustralianSanctions = searcher.TopAustralianSanctions(limit, nameSlug, opts)
+		}
+		// UN Security Council
+		if filters.sources.includes(sourceUN) {
+			resp.UnitedNationsSanctions = searcher.TopUnitedNationsSanctions(limit, nameSlug, opts)
+		}
+		// Custom list
+		if filters.sources.includes(sourceCustom) {
+			resp.CustomEntries = searcher.TopCustomEntries(limit, nameSlug, opts)
+		}
+		if searchTimedOut(w, r) {
+			return
+		}
+		resp.trim(opts)
 
 		// record Prometheus metrics
-		if len(sdns) > 0 {
-			matchHist.With("type", "name").Observe(sdns[0].match)
+		if len(resp.SDNs) > 0 {
+			matchHist.With("type", "name").Observe(resp.SDNs[0].match)
 		} else {
 			matchHist.With("type", "name").Observe(0.0)
 		}
+		if highlightRequested(r.URL) {
+			resp.highlight(nameSlug)
+		}
 
 		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(&searchResponse{
-			// OFAC
-			SDNs:              sdns,
-			AltNames:          searcher.TopAltNames(limit, nameSlug),
-			SectoralSanctions: searcher.TopSSIs(limit, nameSlug),
-			// BIS
-			DeniedPersons: searcher.TopDPs(limit, nameSlug),
-			BISEntities:   searcher.TopBISEntities(limit, nameSlug),
-			// Metadata
-			RefreshedAt: searcher.lastRefreshedAt,
-		})
+		json.NewEncoder(w).Encode(resp)
 	}
 }
 
@@ -369,7 +584,11 @@ func searchByAltName(logger log.Logger, searcher *searcher, altSlug string) http
 			return
 		}
 
-		alts := searcher.TopAltNames(extractSearchLimit(r), altSlug)
+		opts := requestSearchOptions(r)
+		alts := searcher.TopAltNames(extractSearchLimit(r), altSlug, opts)
+		if searchTimedOut(w, r) {
+			return
+		}
 
 		// record Prometheus metrics
 		if len(alts) > 0 {
@@ -378,11 +597,17 @@ func searchByAltName(logger log.Logger, searcher *searcher, altSlug string) http
 			matchHist.With("type", "altName").Observe(0.0)
 		}
 
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(&searchResponse{
+		resp := &searchResponse{
 			AltNames:    alts,
 			RefreshedAt: searcher.lastRefreshedAt,
-		})
+		}
+		resp.trim(opts)
+		if highlightRequested(r.URL) {
+			resp.highlight(altSlug)
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
 	}
 }
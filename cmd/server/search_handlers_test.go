@@ -10,10 +10,16 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/moov-io/watchman/internal/database"
+	"github.com/moov-io/watchman/pkg/au"
+	"github.com/moov-io/watchman/pkg/ca"
 	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/custom"
 	"github.com/moov-io/watchman/pkg/dpl"
 	"github.com/moov-io/watchman/pkg/ofac"
+	"github.com/moov-io/watchman/pkg/un"
 
 	"github.com/go-kit/kit/log"
 	"github.com/gorilla/mux"
@@ -88,7 +94,7 @@ func TestSearch__AddressMulti(t *testing.T) {
 		t.Errorf("bogus status code: %d", w.Code)
 	}
 
-	if v := w.Body.String(); !strings.Contains(v, `"match":0.925`) {
+	if v := w.Body.String(); !strings.Contains(v, `"match":0.95`) {
 		t.Errorf("%#v", v)
 	}
 }
@@ -106,7 +112,7 @@ func TestSearch__AddressProvidence(t *testing.T) {
 		t.Errorf("bogus status code: %d", w.Code)
 	}
 
-	if v := w.Body.String(); !strings.Contains(v, `"match":0.950`) {
+	if v := w.Body.String(); !strings.Contains(v, `"match":0.9625`) {
 		t.Errorf("%#v", v)
 	}
 }
@@ -124,7 +130,7 @@ func TestSearch__AddressCity(t *testing.T) {
 		t.Errorf("bogus status code: %d", w.Code)
 	}
 
-	if v := w.Body.String(); !strings.Contains(v, `"match":0.950`) {
+	if v := w.Body.String(); !strings.Contains(v, `"match":0.97`) {
 		t.Errorf("%#v", v)
 	}
 }
@@ -142,7 +148,7 @@ func TestSearch__AddressState(t *testing.T) {
 		t.Errorf("bogus status code: %d", w.Code)
 	}
 
-	if v := w.Body.String(); !strings.Contains(v, `"match":0.950`) {
+	if v := w.Body.String(); !strings.Contains(v, `"match":0.9625`) {
 		t.Errorf("%#v", v)
 	}
 }
@@ -399,3 +405,260 @@ func TestSearch__ID(t *testing.T) {
 		t.Errorf("%#v", wrapper.SDNs[0])
 	}
 }
+
+func TestSearch__NameHighlight(t *testing.T) {
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+
+	// highlights are excluded by default
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/search?name=maduro+nicolas&limit=1", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+	if v := w.Body.String(); strings.Contains(v, `"highlights"`) {
+		t.Error(v)
+	}
+
+	// request the token alignment
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/search?name=maduro+nicolas&limit=1&highlight=true", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+
+	var wrapper struct {
+		SDNs []struct {
+			EntityID   string       `json:"entityID"`
+			Highlights []tokenMatch `json:"highlights"`
+		} `json:"SDNs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&wrapper); err != nil {
+		t.Fatal(err)
+	}
+	if len(wrapper.SDNs) != 1 || wrapper.SDNs[0].EntityID != "22790" {
+		t.Fatalf("%#v", wrapper.SDNs)
+	}
+	highlights := wrapper.SDNs[0].Highlights
+	if len(highlights) != 2 {
+		t.Fatalf("%#v", highlights)
+	}
+	if highlights[0].Query != "maduro" || highlights[0].Candidate != "maduro" || highlights[0].Score != 1.0 {
+		t.Errorf("highlights[0]=%#v", highlights[0])
+	}
+	if highlights[1].Query != "nicolas" || highlights[1].Candidate != "nicolas" || highlights[1].Score != 1.0 {
+		t.Errorf("highlights[1]=%#v", highlights[1])
+	}
+}
+
+func TestSearch__Sources(t *testing.T) {
+	router := mux.NewRouter()
+	combinedSearcher := &searcher{
+		SDNs: sdnSearcher.SDNs,
+		Alts: altSearcher.Alts,
+		SSIs: ssiSearcher.SSIs,
+		ISNs: isnSearcher.ISNs,
+		pipe: noLogPipeliner,
+	}
+	addSearchRoutes(log.NewNopLogger(), router, combinedSearcher)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/search?q=abu+hamed&limit=1&sources=isn", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+
+	var wrapper struct {
+		SDNs []*ofac.SDN `json:"SDNs"`
+		SSIs []*csl.SSI  `json:"sectoralSanctions"`
+		ISNs []*csl.ISN  `json:"nonproliferationSanctions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&wrapper); err != nil {
+		t.Fatal(err)
+	}
+	if len(wrapper.SDNs) != 0 || len(wrapper.SSIs) != 0 {
+		t.Errorf("SDNs=%d SSIs=%d", len(wrapper.SDNs), len(wrapper.SSIs))
+	}
+	if len(wrapper.ISNs) != 1 || wrapper.ISNs[0].Name != "Mohammed Hamed" {
+		t.Errorf("%#v", wrapper.ISNs)
+	}
+
+	// Canadian sanctions
+	combinedSearcher.CanadianSanctions = caSearcher.CanadianSanctions
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/search?q=bank+of+russia&limit=1&sources=ca", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+	var caWrapper struct {
+		SDNs []*ofac.SDN `json:"SDNs"`
+		CAs  []*ca.Entry `json:"canadianSanctions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&caWrapper); err != nil {
+		t.Fatal(err)
+	}
+	if len(caWrapper.SDNs) != 0 {
+		t.Errorf("SDNs=%d", len(caWrapper.SDNs))
+	}
+	if len(caWrapper.CAs) != 1 || caWrapper.CAs[0].EntityOrShip != "Tsentralnyy Bank Rossiyskoy Federatsii" {
+		t.Errorf("%#v", caWrapper.CAs)
+	}
+
+	// Australian sanctions
+	combinedSearcher.AustralianSanctions = auSearcher.AustralianSanctions
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/search?q=korea+mining+development&limit=1&sources=au", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+	var auWrapper struct {
+		CAs []*ca.Entry `json:"canadianSanctions"`
+		AUs []*au.Entry `json:"australianSanctions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&auWrapper); err != nil {
+		t.Fatal(err)
+	}
+	if len(auWrapper.CAs) != 0 {
+		t.Errorf("CAs=%d", len(auWrapper.CAs))
+	}
+	if len(auWrapper.AUs) != 1 || auWrapper.AUs[0].Reference != "3" {
+		t.Errorf("%#v", auWrapper.AUs)
+	}
+
+	// UN sanctions
+	combinedSearcher.UnitedNationsSanctions = unSearcher.UnitedNationsSanctions
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/search?q=korea+mining+development&limit=1&sources=un", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+	var unWrapper struct {
+		AUs []*au.Entry `json:"australianSanctions"`
+		UNs []*un.Entry `json:"unitedNationsSanctions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&unWrapper); err != nil {
+		t.Fatal(err)
+	}
+	if len(unWrapper.AUs) != 0 {
+		t.Errorf("AUs=%d", len(unWrapper.AUs))
+	}
+	if len(unWrapper.UNs) != 1 || unWrapper.UNs[0].ReferenceNumber != "KPe.001" || unWrapper.UNs[0].Type != un.TypeEntity {
+		t.Errorf("%#v", unWrapper.UNs)
+	}
+
+	// custom list
+	combinedSearcher.CustomEntries = customSearcher.CustomEntries
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/search?q=blue+harbor+shipping&limit=1&sources=custom", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+	var customWrapper struct {
+		UNs     []*un.Entry     `json:"unitedNationsSanctions"`
+		Customs []*custom.Entry `json:"customEntries"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&customWrapper); err != nil {
+		t.Fatal(err)
+	}
+	if len(customWrapper.UNs) != 0 {
+		t.Errorf("UNs=%d", len(customWrapper.UNs))
+	}
+	if len(customWrapper.Customs) != 1 || customWrapper.Customs[0].ID != "BL-002" || customWrapper.Customs[0].Type != custom.TypeEntity {
+		t.Errorf("%#v", customWrapper.Customs)
+	}
+
+	// unknown sources are rejected
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/search?name=abu+hamed&sources=other", nil)
+	router.ServeHTTP(w, req)
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
+
+func TestSearch__dataVersionHeader(t *testing.T) {
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	repo := &sqliteDownloadRepository{db.DB, log.NewNopLogger()}
+
+	refreshedAt := time.Date(2020, time.October, 1, 12, 30, 0, 123456789, time.UTC)
+	if err := repo.recordStats(&downloadStats{SDNs: 1, RefreshedAt: refreshedAt}); err != nil {
+		t.Fatal(err)
+	}
+	s := &searcher{
+		SDNs:            idSearcher.SDNs,
+		lastRefreshedAt: refreshedAt,
+		pipe:            noLogPipeliner,
+	}
+
+	router := mux.NewRouter()
+	addDownloadRoutes(log.NewNopLogger(), router, repo)
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro", nil))
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	version, err := time.Parse(time.RFC3339Nano, w.Header().Get(dataVersionHeader))
+	if err != nil {
+		t.Fatalf("header %q: %v", w.Header().Get(dataVersionHeader), err)
+	}
+
+	// the header matches the latest download
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/downloads", nil))
+	w.Flush()
+
+	var downloads []Download
+	if err := json.NewDecoder(w.Body).Decode(&downloads); err != nil {
+		t.Fatal(err)
+	}
+	if len(downloads) != 1 {
+		t.Fatalf("got %d downloads", len(downloads))
+	}
+	if !version.Equal(downloads[0].Timestamp) {
+		t.Errorf("header %v doesn't match download %v", version, downloads[0].Timestamp)
+	}
+
+	// no header before data is loaded
+	router = mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, &searcher{pipe: noLogPipeliner})
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro", nil))
+	w.Flush()
+
+	if v := w.Header().Get(dataVersionHeader); v != "" {
+		t.Errorf("unexpected header: %q", v)
+	}
+}
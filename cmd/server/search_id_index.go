@@ -0,0 +1,126 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// indexSDNIDs keeps an index of the IDs parsed from SDN remarks so ?id= searches don't scan
+// every SDN, main sets it from SDN_ID_INDEX
+var indexSDNIDs = true
+
+// sdnIDIndex finds SDNs by the ID parsed from their remarks (see extractIDFromRemark). It's
+// built with the SDNs it indexes and swapped in along with them, so it never refers to
+// records which aren't searched.
+type sdnIDIndex struct {
+	sdns []*SDN
+
+	// ids are the positions of SDNs by their ID and by its normalized form (see normalizeID)
+	ids map[string][]int
+
+	// parts are the positions of SDNs whose ID has several parts by each numeric part, every
+	// numeric part has to be in the query (see remarksIDMatches)
+	parts map[string][]int
+
+	// unkeyed are SDNs whose ID has several parts and none are numeric, they're compared
+	// with every query
+	unkeyed []int
+}
+
+func newSDNIDIndex(sdns []*SDN) *sdnIDIndex {
+	idx := &sdnIDIndex{
+		sdns:  sdns,
+		ids:   make(map[string][]int),
+		parts: make(map[string][]int),
+	}
+	for i := range sdns {
+		id := sdns[i].id
+		if id == "" {
+			continue
+		}
+		if !strings.Contains(id, " ") {
+			idx.ids[id] = append(idx.ids[id], i)
+			if normalized := normalizeID(id); normalized != "" && normalized != id {
+				idx.ids[normalized] = append(idx.ids[normalized], i)
+			}
+			continue
+		}
+		keyed := false
+		for _, part := range numericIDParts(id) {
+			idx.parts[part] = append(idx.parts[part], i)
+			keyed = true
+		}
+		if !keyed {
+			idx.unkeyed = append(idx.unkeyed, i)
+		}
+	}
+	return idx
+}
+
+// lookup returns the positions of SDNs whose ID matches id, in the order they're indexed
+func (idx *sdnIDIndex) lookup(id string) []int {
+	seen := make(map[int]bool)
+	var out []int
+	add := func(positions []int) {
+		for _, i := range positions {
+			if !seen[i] && remarksIDMatches(idx.sdns[i].id, id) {
+				seen[i] = true
+				out = append(out, i)
+			}
+		}
+	}
+	add(idx.ids[id])
+	add(idx.ids[normalizeID(id)])
+	for _, part := range strings.Fields(id) {
+		add(idx.parts[part])
+	}
+	add(idx.unkeyed)
+	sort.Ints(out)
+	return out
+}
+
+// remarksIDMatches returns true when the query id matches an SDN's ID. IDs of several parts
+// (e.g. "2456 7890") match when every numeric part is one of the query's parts, other IDs
+// match when they're the same as the query or only differ by case and punctuation.
+func remarksIDMatches(sdnID, id string) bool {
+	if sdnID == "" || id == "" {
+		return false
+	}
+	if strings.Contains(sdnID, " ") {
+		queryParts := strings.Fields(id)
+		for _, part := range numericIDParts(sdnID) {
+			found := false
+			for k := range queryParts {
+				if part == queryParts[k] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	if sdnID == id {
+		return true
+	}
+	normalized := normalizeID(id)
+	return normalized != "" && normalizeID(sdnID) == normalized
+}
+
+// numericIDParts returns the parts of an ID which are positive numbers
+func numericIDParts(id string) []string {
+	var out []string
+	for _, part := range strings.Fields(id) {
+		if n, _ := strconv.ParseInt(part, 10, 64); n > 0 {
+			out = append(out, part)
+		}
+	}
+	return out
+}
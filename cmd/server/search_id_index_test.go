@@ -0,0 +1,117 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestSDNIDIndex__lookup(t *testing.T) {
+	s := testdataSDNs(t)
+	scanned := s.FindSDNsByRemarksID(10, "16593470")
+
+	s.sdnIDs = newSDNIDIndex(s.SDNs)
+	for _, id := range []string{"16593470", "16.593.470", "16-593-470"} {
+		sdns := s.FindSDNsByRemarksID(10, id)
+		if len(sdns) != 1 || sdns[0].EntityID != "4149" || sdns[0].match != 1.0 {
+			t.Errorf("%s: got %#v", id, sdns)
+		}
+	}
+	if len(scanned) != 1 || scanned[0].EntityID != "4149" {
+		t.Errorf("scanned: %#v", scanned)
+	}
+
+	// indexed IDs find the same SDNs as comparing them with every SDN
+	scan := &searcher{SDNs: s.SDNs}
+	for i := 0; i < len(s.SDNs); i += 10 {
+		id := s.SDNs[i].id
+		if id == "" {
+			continue
+		}
+		if indexed, scanned := s.FindSDNsByRemarksID(100, id), scan.FindSDNsByRemarksID(100, id); !reflect.DeepEqual(indexed, scanned) {
+			t.Errorf("%q: indexed=%d scanned=%d", id, len(indexed), len(scanned))
+		}
+	}
+	if sdns := s.FindSDNsByRemarksID(10, "99999999999"); len(sdns) != 0 {
+		t.Errorf("got %#v", sdns)
+	}
+}
+
+func TestSDNIDIndex__parts(t *testing.T) {
+	sdns := []*SDN{
+		{SDN: &ofac.SDN{EntityID: "1"}, id: "2456 7890"},
+		{SDN: &ofac.SDN{EntityID: "2"}, id: "C-5892464"},
+		{SDN: &ofac.SDN{EntityID: "3"}, id: "ABC DEF"},
+	}
+	idx := newSDNIDIndex(sdns)
+
+	cases := map[string][]int{
+		"2456 7890":  {0, 2},
+		"7890 2456":  {0, 2},
+		"2456":       {2},
+		"c5892464":   {1, 2},
+		"C-5892464":  {1, 2},
+		"5892464":    {2},
+		"12456 7890": {2},
+	}
+	for id, expected := range cases {
+		if got := idx.lookup(id); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%q: got %v, expected %v", id, got, expected)
+		}
+	}
+}
+
+func TestSDNIDIndex__reindex(t *testing.T) {
+	dir := filepath.Join("..", "..", "test", "testdata")
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if s.sdnIDs == nil || len(s.sdnIDs.sdns) != len(s.SDNs) || &s.sdnIDs.sdns[0] != &s.SDNs[0] {
+		t.Fatal("SDN IDs weren't indexed with the SDNs")
+	}
+	if sdns := s.FindSDNsByRemarksID(1, "16593470"); len(sdns) != 1 || sdns[0].EntityID != "4149" {
+		t.Errorf("got %#v", sdns)
+	}
+
+	defer func() { indexSDNIDs = true }()
+	indexSDNIDs = false
+	if _, err := s.reindexSource(dir, sourceSDN); err != nil {
+		t.Fatal(err)
+	}
+	if s.sdnIDs != nil {
+		t.Error("SDN IDs were indexed on reindex")
+	}
+	if sdns := s.FindSDNsByRemarksID(1, "16593470"); len(sdns) != 1 || sdns[0].EntityID != "4149" {
+		t.Errorf("got %#v", sdns)
+	}
+}
+
+func BenchmarkSDNIDIndex(b *testing.B) {
+	s := testdataSDNs(b)
+	ids := []string{"16593470", "16.593.470", "6068015", "99999999999"}
+
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s.FindSDNsByRemarksID(10, ids[i%len(ids)])
+		}
+	})
+
+	indexed := &searcher{SDNs: s.SDNs, sdnIDs: newSDNIDIndex(s.SDNs)}
+	b.Run("index", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			indexed.FindSDNsByRemarksID(10, ids[i%len(ids)])
+		}
+	})
+}
@@ -0,0 +1,179 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/moov-io/watchman/pkg/custom"
+)
+
+// internalEntities are our own companies (e.g. subsidiaries), main reads them from
+// INTERNAL_ENTITIES_FILE. Searches for one of them don't return results which are the same
+// internal entity (see suppressSelfMatches).
+var internalEntities []*internalEntity
+
+// internalEntity is an allowlisted party, its names are normalized with sorted tokens
+type internalEntity struct {
+	id    string
+	name  string
+	names []string
+}
+
+// readInternalEntities reads a file in the format of CUSTOM_LIST_FILE, each record's name and
+// aliases are names of the internal entity
+func readInternalEntities(path string) ([]*internalEntity, error) {
+	entries, err := custom.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*internalEntity, 0, len(entries))
+	for _, entry := range entries {
+		ie := &internalEntity{id: entry.ID, name: entry.Name}
+		for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+			if name = sortTokens(name); name != "" {
+				ie.names = append(ie.names, name)
+			}
+		}
+		out = append(out, ie)
+	}
+	return out, nil
+}
+
+// findInternalEntity returns the internal entity with a name normalized like query, or nil
+func findInternalEntity(query string) *internalEntity {
+	if len(internalEntities) == 0 {
+		return nil
+	}
+	names := []string{sortTokens(query)}
+	for _, ie := range internalEntities {
+		if anyEqual(ie.names, names) {
+			return ie
+		}
+	}
+	return nil
+}
+
+// readInternalEntity finds the internal entity searched for by ?name=, ?q= or ?altName=, unless
+// ?suppressSelfMatches=false keeps its results
+func readInternalEntity(u *url.URL) (*internalEntity, error) {
+	if v := strings.TrimSpace(u.Query().Get("suppressSelfMatches")); v != "" {
+		suppress, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid suppressSelfMatches %q", v)
+		}
+		if !suppress {
+			return nil, nil
+		}
+	}
+	for _, param := range []string{"name", "q", "altName"} {
+		if query := strings.TrimSpace(u.Query().Get(param)); query != "" {
+			return findInternalEntity(query), nil
+		}
+	}
+	return nil, nil
+}
+
+// suppressedResult is a result which was left out of the response, with why
+type suppressedResult struct {
+	entityEntry
+	InternalEntityID string `json:"internalEntityID"`
+	Reason           string `json:"reason"`
+}
+
+// selfMatches collects the results of a search which are the internal entity it searched for,
+// like a subsidiary added to a custom list. Like ?excludeIds= they're dropped before results are
+// limited, but they're found by name on every list which dedupeEntities groups.
+type selfMatches struct {
+	entity *internalEntity
+
+	mu      sync.Mutex // lists are searched concurrently
+	results []suppressedResult
+}
+
+// suppress returns true when the result it of source is the internal entity, and records it
+func (sm *selfMatches) suppress(source string, it *item) bool {
+	p := itemParty(source, it)
+	if p == nil || !anyEqual(p.names, sm.entity.names) {
+		return false
+	}
+	sm.add(p.entry)
+	return true
+}
+
+func (sm *selfMatches) add(entry entityEntry) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for i := range sm.results {
+		if sm.results[i].Source == entry.Source && sm.results[i].EntityID == entry.EntityID {
+			return
+		}
+	}
+	sm.results = append(sm.results, suppressedResult{
+		entityEntry:      entry,
+		InternalEntityID: sm.entity.id,
+		Reason:           fmt.Sprintf("self-match of internal entity %s (%s)", sm.entity.id, sm.entity.name),
+	})
+}
+
+// itemParty returns the party of a result being ranked, or nil when its list can't be grouped
+func itemParty(source string, it *item) *party {
+	match, _ := adjustSourceScore(source, it.weight)
+
+	var resp searchResponse
+	switch v := it.value.(type) {
+	case *SDN:
+		resp.SDNs = []SDN{*v}
+		resp.SDNs[0].match = match
+	case *SSI:
+		resp.SectoralSanctions = []SSI{*v}
+		resp.SectoralSanctions[0].match = match
+	case *CanadianSanction:
+		resp.CanadianSanctions = []CanadianSanction{*v}
+		resp.CanadianSanctions[0].match = match
+	case *AustralianSanction:
+		resp.AustralianSanctions = []AustralianSanction{*v}
+		resp.AustralianSanctions[0].match = match
+	case *UnitedNationsSanction:
+		resp.UnitedNationsSanctions = []UnitedNationsSanction{*v}
+		resp.UnitedNationsSanctions[0].match = match
+	case *CustomEntry:
+		resp.CustomEntries = []CustomEntry{*v}
+		resp.CustomEntries[0].match = match
+	}
+	if parties := resp.parties(); len(parties) == 1 {
+		return parties[0]
+	}
+	return nil
+}
+
+// suppressSelfMatches lists the results suppressed while searching under Suppressed. Results
+// which weren't ranked (like SDNs found by ID) are removed from the response here.
+func (resp *searchResponse) suppressSelfMatches(sm *selfMatches) {
+	if sm == nil {
+		return
+	}
+	var suppressed bool
+	for _, p := range resp.parties() {
+		if !anyEqual(p.names, sm.entity.names) {
+			continue
+		}
+		sm.add(p.entry)
+		p.remove()
+		suppressed = true
+	}
+	if suppressed {
+		resp.compact()
+	}
+
+	sm.mu.Lock()
+	resp.Suppressed = append([]suppressedResult(nil), sm.results...)
+	sm.mu.Unlock()
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/custom"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestInternalEntities__read(t *testing.T) {
+	dir, err := ioutil.TempDir("", "internal-entities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "internal.json")
+	body := `[{"id": "SUB-1", "name": "Northwind Trading Ltd", "aliases": ["Northwind Shipping"]}]`
+	if err := ioutil.WriteFile(path, []byte(body), 0600); err != nil {
+		t.Fatal(err)
+	}
+	entities, err := readInternalEntities(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 || entities[0].id != "SUB-1" || len(entities[0].names) != 2 {
+		t.Fatalf("%#v", entities)
+	}
+
+	defer func(v []*internalEntity) { internalEntities = v }(internalEntities)
+	internalEntities = entities
+
+	for _, query := range []string{"northwind trading ltd", "TRADING NORTHWIND LIMITED", "northwind shipping"} {
+		if ie := findInternalEntity(query); ie == nil || ie.id != "SUB-1" {
+			t.Errorf("%s: %#v", query, ie)
+		}
+	}
+	if ie := findInternalEntity("northwind trading"); ie != nil {
+		t.Errorf("%#v", ie)
+	}
+
+	u, _ := url.Parse("/search?name=northwind+trading+ltd&suppressSelfMatches=false")
+	if ie, err := readInternalEntity(u); ie != nil || err != nil {
+		t.Errorf("ie=%#v err=%v", ie, err)
+	}
+	u, _ = url.Parse("/search?name=northwind+trading+ltd&suppressSelfMatches=maybe")
+	if _, err := readInternalEntity(u); err == nil {
+		t.Error("expected error")
+	}
+
+	if _, err := readInternalEntities(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestInternalEntities__suppress(t *testing.T) {
+	defer func(v []*internalEntity) { internalEntities = v }(internalEntities)
+	internalEntities = []*internalEntity{
+		{id: "SUB-1", name: "Northwind Trading Ltd", names: []string{sortTokens("Northwind Trading Ltd")}},
+	}
+
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "NORTHWIND TRADING GROUP"},
+		}, nil, noLogPipeliner),
+		CustomEntries: precomputeCustomEntries([]*custom.Entry{
+			{ID: "BL-10", Name: "Northwind Trading Limited", Type: custom.TypeEntity},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	var resp struct {
+		SDNs []struct {
+			EntityID string `json:"entityID"`
+		} `json:"SDNs"`
+		CustomEntries []struct {
+			ID string `json:"id"`
+		} `json:"customEntries"`
+		Suppressed []suppressedResult `json:"suppressed"`
+	}
+	search := func(t *testing.T, query string) {
+		t.Helper()
+		resp.SDNs, resp.CustomEntries, resp.Suppressed = nil, nil, nil
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the internal entity on the custom list is suppressed while the external match passes
+	search(t, "name=northwind+trading+ltd")
+	if len(resp.SDNs) != 1 || resp.SDNs[0].EntityID != "1" || len(resp.CustomEntries) != 0 {
+		t.Fatalf("unexpected results: %#v", resp)
+	}
+	if len(resp.Suppressed) != 1 {
+		t.Fatalf("%#v", resp.Suppressed)
+	}
+	sup := resp.Suppressed[0]
+	if sup.Source != sourceCustom || sup.EntityID != "BL-10" || sup.InternalEntityID != "SUB-1" || sup.Reason == "" {
+		t.Errorf("%#v", sup)
+	}
+
+	// the self-match is kept when asked for
+	search(t, "name=northwind+trading+ltd&suppressSelfMatches=false")
+	if len(resp.CustomEntries) != 1 || len(resp.Suppressed) != 0 {
+		t.Errorf("unexpected results: %#v", resp)
+	}
+
+	// searches for other parties aren't changed
+	search(t, "name=northwind+trading+group")
+	if len(resp.SDNs) != 1 || len(resp.CustomEntries) != 1 || len(resp.Suppressed) != 0 {
+		t.Errorf("unexpected results: %#v", resp)
+	}
+
+	// self-matches don't take the place of other results within the limit
+	s.CustomEntries = precomputeCustomEntries([]*custom.Entry{
+		{ID: "BL-10", Name: "Northwind Trading Limited", Type: custom.TypeEntity},
+		{ID: "BL-11", Name: "Northwind Traders", Type: custom.TypeEntity},
+	}, noLogPipeliner)
+	search(t, "name=northwind+trading+ltd&limit=1")
+	if len(resp.CustomEntries) != 1 || resp.CustomEntries[0].ID != "BL-11" || len(resp.Suppressed) != 1 {
+		t.Errorf("unexpected results: %#v", resp)
+	}
+}
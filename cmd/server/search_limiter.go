@@ -0,0 +1,91 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	// searchConcurrency bounds how many searches run at once, by default it's unlimited.
+	// main sets it from SEARCH_MAX_CONCURRENCY and SEARCH_MAX_QUEUED.
+	searchConcurrency *searchLimiter
+
+	errSearchesSaturated = errors.New("too many in-flight searches, retry later")
+
+	// searchRetryAfter is the Retry-After header value (in seconds) sent with rejections
+	searchRetryAfter = "1"
+)
+
+// getSearchLimit reads a non-negative count of searches, zero (the default) is unlimited
+// for SEARCH_MAX_CONCURRENCY and doesn't queue any searches for SEARCH_MAX_QUEUED.
+//
+// env is the value from an environmental variable
+func getSearchLimit(logger log.Logger, name, env string) int {
+	if env == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil || n < 0 {
+		logger.Log("main", fmt.Sprintf("invalid %s=%q, using default of 0", name, env))
+		return 0
+	}
+	logger.Log("main", fmt.Sprintf("Setting %s to %d", name, n))
+	return n
+}
+
+// searchLimiter is a semaphore over search handlers. Up to maxInFlight searches run
+// concurrently and up to maxQueued more wait for a slot. Requests beyond that are
+// rejected with a 503 so excess load doesn't slow down every search.
+type searchLimiter struct {
+	admitted chan struct{} // running plus queued requests
+	running  chan struct{}
+}
+
+// newSearchLimiter returns nil (which doesn't limit) when maxInFlight is zero
+func newSearchLimiter(maxInFlight, maxQueued int) *searchLimiter {
+	if maxInFlight <= 0 {
+		return nil
+	}
+	return &searchLimiter{
+		admitted: make(chan struct{}, maxInFlight+maxQueued),
+		running:  make(chan struct{}, maxInFlight),
+	}
+}
+
+func (l *searchLimiter) wrap(next http.HandlerFunc) http.HandlerFunc {
+	if l == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case l.admitted <- struct{}{}:
+			defer func() { <-l.admitted }()
+		default:
+			w.Header().Set("Retry-After", searchRetryAfter)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": errSearchesSaturated.Error(),
+			})
+			return
+		}
+
+		select {
+		case l.running <- struct{}{}:
+			defer func() { <-l.running }()
+		case <-r.Context().Done():
+			return // client gave up while queued
+		}
+
+		next(w, r)
+	}
+}
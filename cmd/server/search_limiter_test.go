@@ -0,0 +1,94 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestSearchLimiter__read(t *testing.T) {
+	logger := log.NewNopLogger()
+	for _, env := range []string{"", "0", "-1", "ten", "2.5"} {
+		if n := getSearchLimit(logger, "SEARCH_MAX_CONCURRENCY", env); n != 0 {
+			t.Errorf("%q: got %d", env, n)
+		}
+	}
+	if n := getSearchLimit(logger, "SEARCH_MAX_CONCURRENCY", "25"); n != 25 {
+		t.Errorf("got %d", n)
+	}
+	if l := newSearchLimiter(0, 10); l != nil {
+		t.Errorf("expected unlimited: %#v", l)
+	}
+}
+
+func TestSearchLimiter__load(t *testing.T) {
+	const (
+		inFlight = 2
+		queued   = 3
+		total    = 20
+	)
+	limiter := newSearchLimiter(inFlight, queued)
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	handler := limiter.wrap(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Fire more requests than can be admitted
+	var wg sync.WaitGroup
+	codes := make(chan *httptest.ResponseRecorder, total)
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest("GET", "/search?q=foo", nil))
+			codes <- w
+		}()
+	}
+
+	// Every request beyond running+queued is rejected right away
+	for i := 0; i < total-inFlight-queued; i++ {
+		w := <-codes
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("bogus status code: %d", w.Code)
+		}
+		if v := w.Header().Get("Retry-After"); v == "" {
+			t.Error("missing Retry-After")
+		}
+	}
+
+	// Admitted requests all complete once unblocked
+	close(release)
+	wg.Wait()
+	close(codes)
+	for w := range codes {
+		if w.Code != http.StatusOK {
+			t.Errorf("bogus status code: %d", w.Code)
+		}
+	}
+	if maxRunning > inFlight {
+		t.Errorf("ran %d searches concurrently", maxRunning)
+	}
+}
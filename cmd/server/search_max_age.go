@@ -0,0 +1,128 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// searchMaxDataAge rejects searches once the oldest source is older than it, instead of serving
+// results from stale data. main sets it from SEARCH_MAX_DATA_AGE, zero (the default) disables it.
+var searchMaxDataAge time.Duration
+
+// getSearchMaxDataAge reads a positive duration, "off" or an empty value disable the limit
+//
+// env is the value from an environmental variable
+func getSearchMaxDataAge(logger log.Logger, env string) time.Duration {
+	if env == "" || strings.EqualFold(env, "off") {
+		return 0
+	}
+	dur, err := time.ParseDuration(env)
+	if err != nil || dur <= 0 {
+		logger.Log("main", fmt.Sprintf("invalid SEARCH_MAX_DATA_AGE=%q, searches aren't rejected for stale data", env))
+		return 0
+	}
+	logger.Log("main", fmt.Sprintf("Rejecting searches when data is older than %v", dur))
+	return dur
+}
+
+// freshSources returns the sources which weren't kept from an earlier refresh
+func freshSources(stale map[string]bool) []string {
+	var out []string
+	for _, source := range knownSources {
+		if !stale[source] {
+			out = append(out, source)
+		}
+	}
+	return out
+}
+
+// markRefreshed records when sources were last loaded. The searcher must be locked.
+func (s *searcher) markRefreshed(at time.Time, sources ...string) {
+	if s.sourceRefreshedAt == nil {
+		s.sourceRefreshedAt = make(map[string]time.Time)
+	}
+	for _, source := range sources {
+		s.sourceRefreshedAt[source] = at
+	}
+}
+
+// oldestSource returns the enabled source whose data was loaded the longest time ago. The custom
+// list is only enabled with CUSTOM_LIST_FILE. It's empty before any data is loaded.
+func (s *searcher) oldestSource() (string, time.Time) {
+	s.RLock()
+	defer s.RUnlock()
+
+	var source string
+	var oldest time.Time
+	for _, src := range knownSources {
+		if src == sourceCustom && customListFile == "" {
+			continue
+		}
+		at, exists := s.sourceRefreshedAt[src]
+		if !exists {
+			continue
+		}
+		if source == "" || at.Before(oldest) {
+			source, oldest = src, at
+		}
+	}
+	return source, oldest
+}
+
+// dataAgeGuard rejects searches with a 503 while the oldest source is older than maxAge
+type dataAgeGuard struct {
+	searcher *searcher
+	maxAge   time.Duration
+
+	now func() time.Time
+}
+
+// newDataAgeGuard returns nil (which doesn't reject searches) when maxAge is zero
+func newDataAgeGuard(s *searcher, maxAge time.Duration) *dataAgeGuard {
+	if maxAge <= 0 {
+		return nil
+	}
+	return &dataAgeGuard{
+		searcher: s,
+		maxAge:   maxAge,
+		now:      time.Now,
+	}
+}
+
+// check returns an error when the oldest source is older than maxAge or no data is loaded
+func (g *dataAgeGuard) check() error {
+	source, refreshedAt := g.searcher.oldestSource()
+	if source == "" {
+		return fmt.Errorf("no data loaded yet")
+	}
+	if age := g.now().Sub(refreshedAt); age > g.maxAge {
+		return fmt.Errorf("%s data is %v old, older than the maximum of %v", source, age.Truncate(time.Second), g.maxAge)
+	}
+	return nil
+}
+
+func (g *dataAgeGuard) wrap(next http.HandlerFunc) http.HandlerFunc {
+	if g == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := g.check(); err != nil {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": fmt.Sprintf("searches are unavailable: %v", err),
+			})
+			return
+		}
+		next(w, r)
+	}
+}
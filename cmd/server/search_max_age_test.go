@@ -0,0 +1,156 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSearchMaxDataAge__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	cases := map[string]time.Duration{
+		"":     0,
+		"off":  0,
+		"OFF":  0,
+		"0s":   0,
+		"-1h":  0,
+		"asdf": 0,
+		"36h":  36 * time.Hour,
+		"90m":  90 * time.Minute,
+	}
+	for env, expected := range cases {
+		if got := getSearchMaxDataAge(logger, env); got != expected {
+			t.Errorf("%q: got %v", env, got)
+		}
+	}
+}
+
+func TestSearchMaxDataAge__boundary(t *testing.T) {
+	refreshedAt := time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+	s := &searcher{}
+	s.markRefreshed(refreshedAt, knownSources...)
+
+	guard := newDataAgeGuard(s, 24*time.Hour)
+	clock := refreshedAt
+	guard.now = func() time.Time { return clock }
+
+	for _, tc := range []struct {
+		now      time.Time
+		rejected bool
+	}{
+		{refreshedAt, false},
+		{refreshedAt.Add(24*time.Hour - time.Nanosecond), false},
+		{refreshedAt.Add(24 * time.Hour), false},
+		{refreshedAt.Add(24*time.Hour + time.Nanosecond), true},
+		{refreshedAt.Add(48 * time.Hour), true},
+	} {
+		clock = tc.now
+		if err := guard.check(); (err != nil) != tc.rejected {
+			t.Errorf("age %v: rejected=%v err=%v", tc.now.Sub(refreshedAt), tc.rejected, err)
+		}
+	}
+
+	// searches are served again after a refresh
+	clock = refreshedAt.Add(48 * time.Hour)
+	s.markRefreshed(clock, freshSources(nil)...)
+	if err := guard.check(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSearchMaxDataAge__oldestSource(t *testing.T) {
+	defer func(path string) { customListFile = path }(customListFile)
+	customListFile = ""
+
+	s := &searcher{}
+	if source, _ := s.oldestSource(); source != "" {
+		t.Errorf("oldest source before data is loaded: %s", source)
+	}
+	if err := newDataAgeGuard(s, time.Hour).check(); err == nil {
+		t.Error("expected error before data is loaded")
+	}
+
+	// CA was kept after its download failed, so it's older than the other sources
+	first := time.Date(2020, time.June, 1, 12, 0, 0, 0, time.UTC)
+	s.markRefreshed(first, knownSources...)
+	s.markRefreshed(first.Add(6*time.Hour), freshSources(map[string]bool{sourceCA: true})...)
+	s.markRefreshed(first.Add(-time.Hour), sourceCustom) // not enabled
+	if source, at := s.oldestSource(); source != sourceCA || !at.Equal(first) {
+		t.Errorf("oldest source %s refreshed at %v", source, at)
+	}
+
+	guard := newDataAgeGuard(s, 12*time.Hour)
+	guard.now = func() time.Time { return first.Add(13 * time.Hour) }
+	if err := guard.check(); err == nil || !strings.Contains(err.Error(), "CA data is 13h0m0s old") {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// reindexing CA makes SDN (and the other sources) the oldest
+	s.markRefreshed(first.Add(12*time.Hour), sourceCA)
+	if source, _ := s.oldestSource(); source != sourceSDN {
+		t.Errorf("oldest source %s", source)
+	}
+
+	// the custom list is only enabled with CUSTOM_LIST_FILE
+	customListFile = "custom-list.csv"
+	if source, _ := s.oldestSource(); source != sourceCustom {
+		t.Errorf("oldest source %s", source)
+	}
+}
+
+func TestSearchMaxDataAge__routes(t *testing.T) {
+	defer func(v time.Duration) { searchMaxDataAge = v }(searchMaxDataAge)
+
+	// disabled by default
+	if newDataAgeGuard(idSearcher, searchMaxDataAge) != nil {
+		t.Fatal("expected searches to be served regardless of data age")
+	}
+
+	s := &searcher{
+		SDNs: idSearcher.SDNs,
+		pipe: noLogPipeliner,
+	}
+	s.markRefreshed(time.Now().Add(-2*time.Hour), knownSources...)
+
+	searchMaxDataAge = time.Hour
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/search?name=maduro", nil),
+		httptest.NewRequest("POST", "/search", strings.NewReader(`{"name": "maduro"}`)),
+		httptest.NewRequest("POST", "/search/batch", strings.NewReader(`[{"name": "maduro"}]`)),
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		w.Flush()
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s: bogus status code: %d", req.Method, req.URL, w.Code)
+		}
+		if v := w.Body.String(); !strings.Contains(v, "older than the maximum of 1h0m0s") {
+			t.Errorf("%s %s: %s", req.Method, req.URL, v)
+		}
+	}
+
+	// fresh data is searched
+	searchMaxDataAge = 3 * time.Hour
+	router = mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=maduro", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+}
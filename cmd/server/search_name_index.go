@@ -0,0 +1,107 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+)
+
+// indexAltNames scores every name of an SDN (its primary name and alt names) as its own record,
+// so SDN results also match on alt names. main sets it from INDEX_ALT_NAMES.
+var indexAltNames = false
+
+// sdnNameRecord is one searchable name of an SDN, alt is nil for its primary name
+type sdnNameRecord struct {
+	sdn *SDN
+	alt *Alt
+}
+
+// sdnNameIndex has a record for each name of every SDN. It's built with the SDNs and alt names
+// it indexes and swapped in along with them, so it never refers to records which aren't
+// searched. Searches of several names always score alt names through it, single names only
+// with indexAltNames.
+type sdnNameIndex struct {
+	// records of an SDN are next to each other with its primary name first, so scores collapse
+	// to one per SDN as they're compared
+	records []sdnNameRecord
+}
+
+func newSDNNameIndex(sdns []*SDN, alts []*Alt) *sdnNameIndex {
+	byEntity := make(map[string][]*Alt)
+	for i := range alts {
+		if alts[i] == nil || alts[i].AlternateIdentity == nil {
+			continue
+		}
+		entityID := alts[i].AlternateIdentity.EntityID
+		byEntity[entityID] = append(byEntity[entityID], alts[i])
+	}
+	idx := &sdnNameIndex{
+		records: make([]sdnNameRecord, 0, len(sdns)+len(alts)),
+	}
+	for i := range sdns {
+		if sdns[i] == nil {
+			continue
+		}
+		idx.records = append(idx.records, sdnNameRecord{sdn: sdns[i]})
+		for _, alt := range byEntity[sdns[i].EntityID] {
+			idx.records = append(idx.records, sdnNameRecord{sdn: sdns[i], alt: alt})
+		}
+	}
+	return idx
+}
+
+// top adds each SDN to xs once, weighted by the best score of its names. The alt name is the
+// item's alias when it scored higher than the primary name.
+func (idx *sdnNameIndex) top(xs *largest, q sdnQuery, opts searchOptions) {
+	var best *item
+	for i := range idx.records {
+		if opts.cancelled(i) {
+			break
+		}
+		rec := idx.records[i]
+		if best != nil && best.value != rec.sdn {
+			xs.add(best)
+			best = nil
+		}
+
+		var weight float64
+		var ok bool
+		if rec.alt == nil {
+			weight, ok = opts.scoreSDN(rec.sdn, q)
+		} else {
+			weight, ok = opts.scoreSDNAlt(rec.sdn, rec.alt, q)
+		}
+		if !ok {
+			continue
+		}
+		switch {
+		case best == nil:
+			best = &item{value: rec.sdn, weight: weight, boost: opts.inProgramBoost(rec.sdn.Programs)}
+		case weight <= best.weight:
+			continue
+		default:
+			best.weight = weight
+		}
+		if rec.alt != nil {
+			best.alias = rec.alt.AlternateIdentity.AlternateName
+			best.aliasType = strings.ToLower(rec.alt.AlternateIdentity.AlternateType)
+		}
+	}
+	if best != nil {
+		xs.add(best)
+	}
+}
+
+// scoreSDNAlt scores an alt name of sdn like its primary name, ok is false when it isn't a result
+func (opts searchOptions) scoreSDNAlt(sdn *SDN, alt *Alt, q sdnQuery) (float64, bool) {
+	if nameless(alt.name, nil) || opts.excluded(sdn.EntityID) {
+		return 0.0, false
+	}
+	query, individual := q.forSDN(sdn)
+	if !opts.enoughNameTokens(individual, alt.name, query) {
+		return 0.0, false
+	}
+	return opts.scoreRecord(individual, alt.name, query), true
+}
@@ -0,0 +1,160 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+var sdnNameQueries = []string{
+	"nicolas maduro", "aero caribbean", "banco nacional de cuba", "national bank of cuba",
+	"avia import", "al qaida", "mohammed hamed", "kim jong un", "zzzz",
+}
+
+// topSDNsAttached scores each SDN with its alt names attached to it, the way records of
+// other lists score their aliases
+func topSDNsAttached(s *searcher, limit int, name string, opts searchOptions) []SDN {
+	alts := make(map[string][]*Alt)
+	for _, alt := range s.Alts {
+		alts[alt.AlternateIdentity.EntityID] = append(alts[alt.AlternateIdentity.EntityID], alt)
+	}
+	q := newSDNQuery(precompute(name))
+	xs := opts.largest(limit, sourceSDN)
+	for _, sdn := range s.SDNs {
+		it := &item{value: sdn, weight: -1}
+		if weight, ok := opts.scoreSDN(sdn, q); ok {
+			it.weight = weight
+		}
+		for _, alt := range alts[sdn.EntityID] {
+			if weight, ok := opts.scoreSDNAlt(sdn, alt, q); ok && weight > it.weight {
+				it.weight, it.alias = weight, alt.AlternateIdentity.AlternateName
+			}
+		}
+		if it.weight >= 0 {
+			xs.add(it)
+		}
+	}
+	var out []SDN
+	for _, v := range xs.items {
+		if v != nil {
+			sdn := *v.value.(*SDN)
+			sdn.match, sdn.alias.MatchedName = v.weight, v.alias
+			out = append(out, sdn)
+		}
+	}
+	return out
+}
+
+func TestSDNNameIndex__parity(t *testing.T) {
+	defer func() { indexAltNames = false }()
+	indexAltNames = true
+
+	s := testdataSDNs(t)
+	indexed := &searcher{SDNs: s.SDNs, Alts: s.Alts, sdnNames: newSDNNameIndex(s.SDNs, s.Alts), pipe: noLogPipeliner}
+	if n := len(indexed.sdnNames.records); n != len(s.SDNs)+len(s.Alts) {
+		t.Fatalf("%d records of %d SDNs and %d alts", n, len(s.SDNs), len(s.Alts))
+	}
+
+	opts := searchOptions{nameScoring: nameScoringTokens, matchedName: true}
+	for _, name := range sdnNameQueries {
+		expected := topSDNsAttached(s, 10, name, opts)
+		got := indexed.TopSDNs(10, name, opts)
+		if len(got) != len(expected) {
+			t.Fatalf("%s: got %d SDNs, expected %d", name, len(got), len(expected))
+		}
+		seen := make(map[string]bool)
+		for i := range got {
+			if got[i].EntityID != expected[i].EntityID || got[i].match != expected[i].match || got[i].alias.MatchedName != expected[i].alias.MatchedName {
+				t.Errorf("%s #%d: got %s (%.4f, %q), expected %s (%.4f, %q)", name, i,
+					got[i].EntityID, got[i].match, got[i].alias.MatchedName,
+					expected[i].EntityID, expected[i].match, expected[i].alias.MatchedName)
+			}
+			if seen[got[i].EntityID] {
+				t.Errorf("%s: SDN %s is a result more than once", name, got[i].EntityID)
+			}
+			seen[got[i].EntityID] = true
+		}
+
+		// the primary name scores as it does without the index
+		for _, sdn := range s.TopSDNs(10, name, opts) {
+			for i := range got {
+				if got[i].EntityID == sdn.EntityID && got[i].match < sdn.match {
+					t.Errorf("%s: SDN %s scored %.4f, %.4f without the index", name, sdn.EntityID, got[i].match, sdn.match)
+				}
+			}
+		}
+	}
+}
+
+func TestSDNNameIndex__alias(t *testing.T) {
+	defer func() { indexAltNames = false }()
+	indexAltNames = true
+
+	sdns := precomputeSDNs([]*ofac.SDN{
+		{EntityID: "306", SDNName: "BANCO NACIONAL DE CUBA"},
+		{EntityID: "173", SDNName: "ANGLO-CARIBBEAN CO., LTD."},
+	}, nil, noLogPipeliner)
+	alts := precomputeAlts([]*ofac.AlternateIdentity{
+		{EntityID: "306", AlternateID: "220", AlternateType: "aka", AlternateName: "NATIONAL BANK OF CUBA"},
+		{EntityID: "173", AlternateID: "57", AlternateType: "fka", AlternateName: "AVIA IMPORT"},
+		{EntityID: "999", AlternateID: "1", AlternateType: "aka", AlternateName: "UNLISTED"},
+	})
+	s := &searcher{SDNs: sdns, Alts: alts, sdnNames: newSDNNameIndex(sdns, alts), pipe: noLogPipeliner}
+	if n := len(s.sdnNames.records); n != 4 {
+		t.Errorf("%d records", n)
+	}
+
+	results := s.TopSDNs(2, "avia import", searchOptions{matchedName: true})
+	if len(results) != 2 || results[0].EntityID != "173" || results[0].match != 1.0 {
+		t.Fatalf("got %#v", results)
+	}
+	if alias := results[0].alias; alias.MatchedName != "AVIA IMPORT" || alias.MatchedNameType != "fka" {
+		t.Errorf("alias=%#v", alias)
+	}
+
+	// the primary name has no alias, and the alias is only included with matchedName
+	if results := s.TopSDNs(1, "banco nacional de cuba", searchOptions{matchedName: true}); results[0].EntityID != "306" || results[0].alias.MatchedName != "" {
+		t.Errorf("got %#v", results[0])
+	}
+	if results := s.TopSDNs(1, "national bank of cuba", searchOptions{}); results[0].EntityID != "306" || results[0].match != 1.0 || results[0].alias.MatchedName != "" {
+		t.Errorf("got %#v", results[0])
+	}
+
+	// excluded SDNs aren't matched by their alt names
+	if results := s.TopSDNs(2, "avia import", searchOptions{excludeIDs: map[string]bool{"173": true}}); len(results) != 1 || results[0].EntityID != "306" {
+		t.Errorf("got %#v", results)
+	}
+}
+
+// BenchmarkSDNNameIndex compares searching SDNs and alt names separately (the default), each alt
+// name attached to its SDN and every name as its own record (INDEX_ALT_NAMES)
+func BenchmarkSDNNameIndex(b *testing.B) {
+	defer func() { indexAltNames = false }()
+	indexAltNames = true
+
+	s := testdataSDNs(b)
+	indexed := &searcher{SDNs: s.SDNs, Alts: s.Alts, sdnNames: newSDNNameIndex(s.SDNs, s.Alts), pipe: noLogPipeliner}
+	opts := searchOptions{nameScoring: nameScoringTokens}
+
+	b.Run("separate", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			name := sdnNameQueries[i%len(sdnNameQueries)]
+			s.TopSDNs(10, name, opts)
+			s.TopAltNames(10, name, opts)
+		}
+	})
+	b.Run("attached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			topSDNsAttached(s, 10, sdnNameQueries[i%len(sdnNameQueries)], opts)
+		}
+	})
+	b.Run("records", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			indexed.TopSDNs(10, sdnNameQueries[i%len(sdnNameQueries)], opts)
+		}
+	})
+}
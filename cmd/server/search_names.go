@@ -0,0 +1,122 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"math"
+	"net/url"
+	"strings"
+)
+
+const (
+	// multiNameMatchScore is the lowest score of a query name against an SDN for it to count
+	// as one more name matching the same SDN
+	multiNameMatchScore = 0.85
+
+	// multiNameBonus is added to an SDN's match for each additional query name which matched it
+	multiNameBonus = 0.05
+)
+
+// readNameVariants returns each ?name parameter, so a counterparty's primary name and
+// known aliases can be searched together (?name=a&name=b).
+func readNameVariants(u *url.URL) []string {
+	var out []string
+	for _, name := range u.Query()["name"] {
+		if name = strings.TrimSpace(name); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// TopSDNsByNames scores each SDN by the best pair of a query name and the SDN's name or one of
+// its alternate names, each scored like TopSDNs scores them. SDNs matched by more than one query
+// name get multiNameBonus for each additional name, which lifts borderline matches confirmed by
+// an alias. Names which are the same once normalized are only scored once.
+func (s *searcher) TopSDNsByNames(limit int, names []string, opts searchOptions) []SDN {
+	var queries []sdnQuery
+	seen := make(map[string]bool)
+	for i := range names {
+		if name := precompute(names[i]); name != "" && !seen[name] {
+			seen[name] = true
+			queries = append(queries, newSDNQuery(name))
+		}
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	if len(s.SDNs) == 0 || len(queries) == 0 {
+		return nil
+	}
+	xs := opts.largest(limit, sourceSDN)
+
+	// the records of an SDN are next to each other, searchers built without an index only
+	// have primary names
+	n, record := len(s.SDNs), func(i int) sdnNameRecord { return sdnNameRecord{sdn: s.SDNs[i]} }
+	if s.sdnNames != nil {
+		records := s.sdnNames.records
+		n, record = len(records), func(i int) sdnNameRecord { return records[i] }
+	}
+
+	var best *item
+	bests := make([]float64, len(queries)) // best score of each query name against the SDN
+	add := func() {
+		if best == nil {
+			return
+		}
+		var matched int
+		for i := range bests {
+			if bests[i] >= multiNameMatchScore {
+				matched++
+			}
+			bests[i] = 0.0
+		}
+		if matched > 1 && !opts.strict {
+			best.weight = math.Min(1.0, best.weight+multiNameBonus*float64(matched-1))
+		}
+		xs.add(best)
+		best = nil
+	}
+	for i := 0; i < n; i++ {
+		if opts.cancelled(i) {
+			break
+		}
+		rec := record(i)
+		if best != nil && best.value != rec.sdn {
+			add()
+		}
+		for j, q := range queries {
+			var weight float64
+			var ok bool
+			if rec.alt == nil {
+				weight, ok = opts.scoreSDN(rec.sdn, q)
+			} else {
+				weight, ok = opts.scoreSDNAlt(rec.sdn, rec.alt, q)
+			}
+			if !ok {
+				continue
+			}
+			if weight > bests[j] {
+				bests[j] = weight
+			}
+			switch {
+			case best == nil:
+				best = &item{value: rec.sdn, weight: weight, boost: opts.inProgramBoost(rec.sdn.Programs)}
+			case weight <= best.weight:
+				continue
+			default:
+				best.weight = weight
+				best.alias, best.aliasType = "", ""
+			}
+			if rec.alt != nil {
+				best.alias = rec.alt.AlternateIdentity.AlternateName
+				best.aliasType = strings.ToLower(rec.alt.AlternateIdentity.AlternateType)
+			}
+		}
+	}
+	add()
+	return sdnResults(xs, opts)
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+var (
+	namesSearcher = func() *searcher {
+		sdns := precomputeSDNs([]*ofac.SDN{
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"},
+			{EntityID: "1", SDNName: "OBRERO SHIPPING", SDNType: ""},
+		}, nil, noLogPipeliner)
+		alts := precomputeAlts([]*ofac.AlternateIdentity{
+			{EntityID: "22790", AlternateID: "1", AlternateType: "aka", AlternateName: "EL PRESIDENTE OBRERO"},
+		})
+		return &searcher{SDNs: sdns, Alts: alts, sdnNames: newSDNNameIndex(sdns, alts), pipe: noLogPipeliner}
+	}()
+)
+
+func TestSearch__readNameVariants(t *testing.T) {
+	u, _ := url.Parse("/search?name=nicolas+maduro&name=+&name=el+presidente")
+	if names := readNameVariants(u); len(names) != 2 || names[1] != "el presidente" {
+		t.Errorf("names: %#v", names)
+	}
+}
+
+func TestSearcher__TopSDNsByNames(t *testing.T) {
+	const threshold = 0.95
+
+	// the primary name alone is a borderline match
+	sdns := namesSearcher.TopSDNsByNames(1, []string{"n maduro"}, searchOptions{})
+	if len(sdns) != 1 || sdns[0].EntityID != "22790" || sdns[0].match >= threshold {
+		t.Fatalf("SDNs: %#v", sdns)
+	}
+	primary := sdns[0].match
+
+	// and so is the counterparty's alias, against the SDN's alternate name
+	sdns = namesSearcher.TopSDNsByNames(1, []string{"pres obrero"}, searchOptions{})
+	if len(sdns) != 1 || sdns[0].EntityID != "22790" || sdns[0].match >= threshold {
+		t.Fatalf("SDNs: %#v", sdns)
+	}
+	alias := sdns[0].match
+
+	// both together match the same SDN twice and are boosted above the threshold
+	sdns = namesSearcher.TopSDNsByNames(2, []string{"n maduro", "pres obrero"}, searchOptions{})
+	if len(sdns) != 2 || sdns[0].EntityID != "22790" {
+		t.Fatalf("SDNs: %#v", sdns)
+	}
+	if sdns[0].match < threshold || sdns[0].match <= primary || sdns[0].match <= alias {
+		t.Errorf("match=%.4f primary=%.4f alias=%.4f", sdns[0].match, primary, alias)
+	}
+	eql(t, "bonus", sdns[0].match, alias+multiNameBonus)
+
+	// the same name given twice isn't another name matching the SDN
+	sdns = namesSearcher.TopSDNsByNames(1, []string{"n maduro", "N. MADURO"}, searchOptions{})
+	if len(sdns) != 1 || sdns[0].match != primary {
+		t.Errorf("SDNs: %#v primary=%.4f", sdns, primary)
+	}
+
+	// strict searches aren't boosted
+	sdns = namesSearcher.TopSDNsByNames(1, []string{"nicolas maduro moros", "el presidente obrero"}, searchOptions{strict: true})
+	if len(sdns) != 1 || sdns[0].match != 1.0 {
+		t.Errorf("SDNs: %#v", sdns)
+	}
+
+	// the alt name which matched is included like it is for single names
+	sdns = namesSearcher.TopSDNsByNames(1, []string{"n maduro", "el presidente obrero"}, searchOptions{matchedName: true})
+	if len(sdns) != 1 || sdns[0].alias != (matchedAlias{"EL PRESIDENTE OBRERO", "aka"}) {
+		t.Errorf("SDNs: %#v", sdns)
+	}
+
+	// and the multiplier of SDN results is applied
+	sourceScoreMultipliers = map[string]float64{sourceSDN: 0.5}
+	defer func() { sourceScoreMultipliers = nil }()
+	sdns = namesSearcher.TopSDNsByNames(1, []string{"nicolas maduro moros", "el presidente obrero"}, searchOptions{strict: true})
+	if len(sdns) != 1 || sdns[0].match != 0.5 || sdns[0].adjustment.RawMatch != 1.0 {
+		t.Errorf("SDNs: %#v", sdns)
+	}
+}
+
+func TestSearcher__TopSDNsByNamesParity(t *testing.T) {
+	defer func() { indexAltNames = false }()
+	indexAltNames = true
+
+	s := testdataSDNs(t)
+	s = &searcher{SDNs: s.SDNs, Alts: s.Alts, sdnNames: newSDNNameIndex(s.SDNs, s.Alts), pipe: noLogPipeliner}
+
+	// a single name scores each SDN like TopSDNs does with alt names indexed
+	opts := searchOptions{nameScoring: nameScoringTokens, matchedName: true}
+	for _, name := range append(sdnNameQueries, "金正恩", "Ким Чен Ын") {
+		expected := s.TopSDNs(10, name, opts)
+		got := s.TopSDNsByNames(10, []string{name}, opts)
+		if len(got) != len(expected) {
+			t.Fatalf("%s: got %d SDNs, expected %d", name, len(got), len(expected))
+		}
+		for i := range got {
+			if got[i].EntityID != expected[i].EntityID || got[i].match != expected[i].match || got[i].alias != expected[i].alias {
+				t.Errorf("%s #%d: got %s (%.4f, %q), expected %s (%.4f, %q)", name, i,
+					got[i].EntityID, got[i].match, got[i].alias.MatchedName,
+					expected[i].EntityID, expected[i].match, expected[i].alias.MatchedName)
+			}
+		}
+	}
+}
+
+func TestSearch__multipleNamesHandler(t *testing.T) {
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, namesSearcher)
+
+	search := func(query string) float64 {
+		t.Helper()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?limit=1&"+query, nil))
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d", w.Code)
+		}
+		var resp struct {
+			SDNs []struct {
+				EntityID string  `json:"entityID"`
+				Match    float64 `json:"match"`
+			} `json:"SDNs"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.SDNs) != 1 || resp.SDNs[0].EntityID != "22790" {
+			t.Fatalf("SDNs: %#v", resp.SDNs)
+		}
+		return resp.SDNs[0].Match
+	}
+	single := search("name=n+maduro")
+	multiple := search("name=n+maduro&name=pres+obrero")
+	if multiple <= single {
+		t.Errorf("single=%.4f multiple=%.4f", single, multiple)
+	}
+
+	// POST bodies can list names
+	values, err := searchValues(map[string]interface{}{
+		"name": []interface{}{"MADURO MOROS, Nicolas", "pres obrero"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := values["name"]; len(names) != 2 || !strings.Contains(names[0], ",") {
+		t.Errorf("names: %#v", names)
+	}
+}
@@ -0,0 +1,543 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	matchModeWildcard = "wildcard"
+	matchModeInitials = "initials"
+
+	// nameScoringTokens scores each query token against its best token of a name, so the
+	// order of tokens doesn't matter. nameScoringFull scores the whole strings at once.
+	nameScoringTokens = "tokens"
+	nameScoringFull   = "full"
+
+	// businessQueryIndividualWeight lowers the scores of individuals for queries with a business
+	// suffix (see entityBias), a company is being searched for so same-named entities rank first
+	businessQueryIndividualWeight = 0.9
+
+	// matchingTokenScore is the lowest score of a query token against a name token for it to
+	// count towards minNameTokens
+	matchingTokenScore = 0.9
+)
+
+var (
+	defaultMinMatchingNameTokens = 1
+
+	// minMatchingNameTokens is how many query tokens must match an individual's name before
+	// it's a result, main sets it from MIN_MATCHING_NAME_TOKENS
+	minMatchingNameTokens = defaultMinMatchingNameTokens
+
+	defaultMinAddressTokens = 1
+
+	// minAddressTokens is how many tokens an address search needs before it's scored fuzzily,
+	// main sets it from MIN_ADDRESS_TOKENS
+	minAddressTokens = defaultMinAddressTokens
+
+	// defaultNameScoring is how fuzzy searches score names unless they set ?nameScoring=,
+	// main sets it from NAME_SCORING
+	defaultNameScoring = nameScoringTokens
+
+	// searchScoreFloor skips scoring names which can't reach it, main sets it from SEARCH_SCORE_FLOOR
+	searchScoreFloor float64
+
+	// fuzzyNameMatching and fuzzyAddressMatching are the defaults of ?fuzzyName= and ?fuzzyAddress=,
+	// main sets them from FUZZY_NAME_MATCHING and FUZZY_ADDRESS_MATCHING
+	fuzzyNameMatching, fuzzyAddressMatching = true, true
+)
+
+// getFuzzyMatching reads a boolean which defaults to true
+//
+// name is the environmental variable and env is its value
+func getFuzzyMatching(logger log.Logger, name, env string) bool {
+	if env == "" {
+		return true
+	}
+	fuzzy, err := strconv.ParseBool(env)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid %s=%q, using fuzzy matching", name, env))
+		return true
+	}
+	if !fuzzy {
+		logger.Log("main", fmt.Sprintf("%s=false, only exact matches are returned by default", name))
+	}
+	return fuzzy
+}
+
+// getMinMatchingNameTokens reads a positive number of tokens
+//
+// env is the value from an environmental variable
+func getMinMatchingNameTokens(logger log.Logger, env string) int {
+	if env == "" {
+		return defaultMinMatchingNameTokens
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil || n < 1 {
+		logger.Log("main", fmt.Sprintf("invalid MIN_MATCHING_NAME_TOKENS=%q, using default of %d", env, defaultMinMatchingNameTokens))
+		return defaultMinMatchingNameTokens
+	}
+	logger.Log("main", fmt.Sprintf("Requiring %d matching name tokens for individuals", n))
+	return n
+}
+
+// getMinAddressTokens reads a positive number of tokens
+//
+// env is the value from an environmental variable
+func getMinAddressTokens(logger log.Logger, env string) int {
+	if env == "" {
+		return defaultMinAddressTokens
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil || n < 1 {
+		logger.Log("main", fmt.Sprintf("invalid MIN_ADDRESS_TOKENS=%q, using default of %d", env, defaultMinAddressTokens))
+		return defaultMinAddressTokens
+	}
+	logger.Log("main", fmt.Sprintf("Requiring %d address tokens for fuzzy address matching", n))
+	return n
+}
+
+// getScoreFloor reads a score between 0 and 1, zero scores every name
+//
+// env is the value from an environmental variable
+func getScoreFloor(logger log.Logger, env string) float64 {
+	if env == "" {
+		return 0.0
+	}
+	n, err := strconv.ParseFloat(env, 64)
+	if err != nil || n < 0 || n > 1 {
+		logger.Log("main", fmt.Sprintf("invalid SEARCH_SCORE_FLOOR=%q, scoring every name", env))
+		return 0.0
+	}
+	logger.Log("main", fmt.Sprintf("Skipping names which can't score %.2f", n))
+	return n
+}
+
+// getNameScoring reads either "tokens" or "full"
+//
+// env is the value from an environmental variable
+func getNameScoring(logger log.Logger, env string) string {
+	switch mode := strings.ToLower(strings.TrimSpace(env)); mode {
+	case "", nameScoringTokens:
+		return nameScoringTokens
+	case nameScoringFull:
+		logger.Log("main", "Scoring full names instead of their tokens")
+		return mode
+	default:
+		logger.Log("main", fmt.Sprintf("invalid NAME_SCORING=%q, scoring name tokens", env))
+		return nameScoringTokens
+	}
+}
+
+// searchOptions alter how names are scored for a single search request.
+// The zero value scores with jaroWinkler.
+type searchOptions struct {
+	// matchMode is empty for fuzzy matching, "wildcard" to match '*' and '?' patterns or
+	// "initials" to match abbreviated tokens (see initialsMatch)
+	matchMode string
+
+	// nameScoring is how fuzzy matches are scored, "tokens" or "full" (see nameScoringTokens)
+	nameScoring string
+
+	// topDelta drops results scoring more than this below the best result, zero keeps all results
+	topDelta float64
+
+	// strict only scores normalized exact name matches (as 1.0) and drops every other result
+	strict bool
+
+	// strictAddresses is strict for address results, only exact matches of every given field are kept
+	strictAddresses bool
+
+	// matchedName includes the alias (and its type) which produced each result's match
+	matchedName bool
+
+	// dedupeEntities groups results from different lists which refer to the same party
+	dedupeEntities bool
+
+	// sort is sortScore or sortRecent to order results of a band by their listing date
+	sort string
+
+	// group is empty for results in each list or "bands" to group them by confidence
+	group string
+	bands bandThresholds
+
+	// includeRemarks keeps the raw remarks on SDN results, which are left out by default
+	includeRemarks bool
+
+	// includeAddresses adds every address of an SDN to its result
+	includeAddresses bool
+
+	// includeDetails adds the dates of birth, nationalities and ID parsed from remarks to SDN results
+	includeDetails bool
+
+	// minNameTokens is how many query tokens must match a token of an individual's name
+	minNameTokens int
+
+	// minAddressTokens is how many tokens an address search needs before it's scored fuzzily,
+	// shorter ones only match exactly (see forAddress)
+	minAddressTokens int
+
+	// nameComponents are the given, middle and family names of the search, individual SDNs
+	// with components are scored by them instead of their whole name
+	nameComponents *nameComponents
+
+	// scoreFloor skips full scoring of names whose upper bound is below it, they score zero instead
+	scoreFloor float64
+
+	// nameFrequency lowers fuzzy scores of queries made of common names (see nameBearers)
+	nameFrequency bool
+
+	// nicknames also scores names against the query with nicknames swapped for formal names
+	// and the reverse (see nicknameTable)
+	nicknames bool
+
+	// entityBias lowers scores of individuals for queries with a business suffix, like "acme llc"
+	entityBias bool
+
+	// cjkNameOrder also compares CJK names with the family name first (see scoreCJKOrder)
+	cjkNameOrder bool
+
+	// programs rank SDN, SSI and ISN results in any of them above results which aren't, they're
+	// upper case (see readPrograms)
+	programs map[string]bool
+
+	// excludeIDs are SDN entity IDs whose SDN, alt name and address results are dropped before
+	// results are limited
+	excludeIDs map[string]bool
+
+	// selfMatches has the allowlisted entity the request searched for, its self-matches are
+	// suppressed before results are limited (see INTERNAL_ENTITIES_FILE)
+	selfMatches *selfMatches
+
+	// minMatch drops results scoring below it, zero keeps every result
+	minMatch float64
+
+	// combination scores SDNs of searches with a name and address (see readScoreCombination)
+	combination scoreCombination
+
+	// matches counts the results of each list above minMatch before they're limited, it's only
+	// set with ?totalMatches=true
+	matches *int64
+
+	// timeout is how long the client allows the search to run (?timeout=), searchTimeout caps it
+	timeout time.Duration
+
+	// ctx stops scoring once it's done, it's nil outside of HTTP requests
+	ctx context.Context
+}
+
+// readSearchOptions parses the query parameters which control scoring
+func readSearchOptions(u *url.URL) (searchOptions, error) {
+	opts := searchOptions{
+		nameScoring:      defaultNameScoring,
+		strict:           !fuzzyNameMatching,
+		strictAddresses:  !fuzzyAddressMatching,
+		minNameTokens:    minMatchingNameTokens,
+		minAddressTokens: minAddressTokens,
+		scoreFloor:       searchScoreFloor,
+		excludeIDs:       readExcludeIDs(u),
+		programs:         readPrograms(u),
+		nameComponents:   readNameComponents(u),
+		entityBias:       true,
+		cjkNameOrder:     true,
+	}
+
+	mode, err := readEnumParam(u, "matchMode")
+	if err != nil {
+		return opts, err
+	}
+	if mode != "fuzzy" {
+		opts.matchMode = mode
+	}
+
+	scoring, err := readEnumParam(u, "nameScoring")
+	if err != nil {
+		return opts, err
+	}
+	if scoring != "" {
+		opts.nameScoring = scoring
+	}
+
+	if v := strings.TrimSpace(u.Query().Get("topDelta")); v != "" {
+		delta, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(delta) || delta < 0 || delta > 1 {
+			return opts, fmt.Errorf("invalid topDelta %q, must be between 0 and 1", v)
+		}
+		opts.topDelta = delta
+	}
+
+	if v := strings.TrimSpace(u.Query().Get("minMatch")); v != "" {
+		min, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(min) || min < 0 || min > 1 {
+			return opts, fmt.Errorf("invalid minMatch %q, must be between 0 and 1", v)
+		}
+		opts.minMatch = min
+	}
+	if v := strings.TrimSpace(u.Query().Get("totalMatches")); v != "" {
+		total, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid totalMatches %q", v)
+		}
+		if total {
+			opts.matches = new(int64)
+		}
+	}
+
+	// strict is shorthand for fuzzyName=false&fuzzyAddress=false
+	if v := strings.TrimSpace(u.Query().Get("strict")); v != "" {
+		strict, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid strict %q", v)
+		}
+		opts.strict, opts.strictAddresses = strict, strict
+	}
+	for param, exact := range map[string]*bool{"fuzzyName": &opts.strict, "fuzzyAddress": &opts.strictAddresses} {
+		if v := strings.TrimSpace(u.Query().Get(param)); v != "" {
+			fuzzy, err := strconv.ParseBool(v)
+			if err != nil {
+				return opts, fmt.Errorf("invalid %s %q", param, v)
+			}
+			if fuzzy && u.Query().Get("strict") != "" && *exact {
+				return opts, fmt.Errorf("strict=true can't be combined with %s=true", param)
+			}
+			*exact = !fuzzy
+		}
+	}
+	if v := strings.TrimSpace(u.Query().Get("matchedName")); v != "" {
+		matchedName, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid matchedName %q", v)
+		}
+		opts.matchedName = matchedName
+	}
+
+	if v := strings.TrimSpace(u.Query().Get("dedupeEntities")); v != "" {
+		dedupe, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid dedupeEntities %q", v)
+		}
+		opts.dedupeEntities = dedupe
+	}
+
+	if v := strings.TrimSpace(u.Query().Get("nameFrequency")); v != "" {
+		weighted, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid nameFrequency %q", v)
+		}
+		opts.nameFrequency = weighted
+	}
+
+	if v := strings.TrimSpace(u.Query().Get("nicknames")); v != "" {
+		expand, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid nicknames %q", v)
+		}
+		opts.nicknames = expand
+	}
+
+	if v := strings.TrimSpace(u.Query().Get("entityBias")); v != "" {
+		bias, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid entityBias %q", v)
+		}
+		opts.entityBias = bias
+	}
+
+	if v := strings.TrimSpace(u.Query().Get("cjkNameOrder")); v != "" {
+		order, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid cjkNameOrder %q", v)
+		}
+		opts.cjkNameOrder = order
+	}
+
+	if v := strings.TrimSpace(u.Query().Get("includeRemarks")); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid includeRemarks %q", v)
+		}
+		opts.includeRemarks = include
+	}
+	if v := strings.TrimSpace(u.Query().Get("includeAddresses")); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid includeAddresses %q", v)
+		}
+		opts.includeAddresses = include
+	}
+	if v := strings.TrimSpace(u.Query().Get("includeDetails")); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("invalid includeDetails %q", v)
+		}
+		opts.includeDetails = include
+	}
+
+	if v := strings.TrimSpace(u.Query().Get("timeout")); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			return opts, fmt.Errorf("invalid timeout %q, must be a positive duration (e.g. 5s)", v)
+		}
+		opts.timeout = timeout
+	}
+
+	combination, err := readScoreCombination(u)
+	if err != nil {
+		return opts, err
+	}
+	opts.combination = combination
+
+	ie, err := readInternalEntity(u)
+	if err != nil {
+		return opts, err
+	}
+	if ie != nil {
+		opts.selfMatches = &selfMatches{entity: ie}
+	}
+
+	order, err := readEnumParam(u, "sort")
+	if err != nil {
+		return opts, err
+	}
+	opts.sort = sortScore
+	if order == sortRecent {
+		opts.sort = order
+		opts.bands = searchBandThresholds
+	}
+
+	group, err := readEnumParam(u, "group")
+	if err != nil {
+		return opts, err
+	}
+	if group == groupBands {
+		opts.group = group
+		opts.bands = searchBandThresholds
+	}
+
+	if opts.strict && opts.matchMode != "" {
+		return opts, fmt.Errorf("strict (or fuzzyName=false) searches can't use matchMode=%s", opts.matchMode)
+	}
+
+	return opts, nil
+}
+
+// enoughNameTokens returns false when an individual's indexed name has fewer tokens matching
+// the query than minNameTokens. Entities always have enough.
+func (opts searchOptions) enoughNameTokens(individual bool, indexed, query string) bool {
+	if !individual || opts.minNameTokens <= 1 {
+		return true
+	}
+	var n int
+	for _, m := range alignTokens(indexed, query) {
+		if m.Score >= matchingTokenScore {
+			n++
+		}
+	}
+	return n >= opts.minNameTokens
+}
+
+// score compares an indexed name against the (precomputed) query, the record is scored as an
+// entity when it's not known to be an individual
+func (opts searchOptions) score(indexed, query string) float64 {
+	return opts.scoreRecord(false, indexed, query)
+}
+
+// scoreName is scoreRecord for the primary name of a record. Strict searches also match exact,
+// the listed name before stopwords and other pipeline steps removed words of it.
+func (opts searchOptions) scoreName(individual bool, indexed, exact, query string) float64 {
+	if opts.strict && exact != "" && exact == query {
+		return 1.0
+	}
+	return opts.scoreRecord(individual, indexed, query)
+}
+
+// scoreRecord is score for the name of an individual or entity, which decides how numeric
+// tokens are compared (see numericNameTokens)
+func (opts searchOptions) scoreRecord(individual bool, indexed, query string) float64 {
+	if opts.strict {
+		return exactMatch(indexed, query)
+	}
+	curve, calibrated := opts.calibration()
+	if calibrated && opts.scoreFloor > 0 {
+		// the floor skips names by their raw score
+		opts.scoreFloor = curve.raw(opts.scoreFloor)
+	}
+	if opts.matchMode == matchModeWildcard {
+		score := wildcardMatch(indexed, query)
+		if calibrated {
+			score = curve.apply(score)
+		}
+		return score
+	}
+	score := opts.nameScore(individual, indexed, query)
+	if opts.nicknames {
+		if nickname, _ := opts.nicknameScore(individual, indexed, query); nickname > score {
+			score = nickname
+		}
+	}
+	if calibrated {
+		score = curve.apply(score)
+	}
+	if opts.nameFrequency {
+		score *= nameFrequencyWeight(query)
+	}
+	if individual && opts.entityBias && nameSuffixes.suffixed(query) {
+		score *= businessQueryIndividualWeight
+	}
+	return score
+}
+
+// nameScore is the score of an indexed name against the query with initials or fuzzy matching,
+// before it's calibrated or penalized
+func (opts searchOptions) nameScore(individual bool, indexed, query string) float64 {
+	if opts.matchMode == matchModeInitials {
+		return initialsMatch(indexed, query)
+	}
+	if _, fuzzy := searchScorers.name.(jaroWinklerScorer); !fuzzy {
+		return searchScorers.name.score(indexed, query)
+	}
+	score := opts.fuzzyScore(indexed, query, exactNumbers(individual))
+	if concatenated := concatenatedNameScore(indexed, query, opts.scoreFloor); concatenated > score {
+		score = concatenated
+	}
+	return score
+}
+
+// fuzzyScore is the Jaro-Winkler score of a name according to nameScoring and scoreFloor.
+// Scoring by tokens compares numeric tokens exactly with numbers.
+func (opts searchOptions) fuzzyScore(indexed, query string, numbers bool) float64 {
+	if opts.nameScoring == nameScoringFull {
+		if opts.scoreFloor > 0 && tokenUpperBound(indexed, query)+1e-9 < opts.scoreFloor {
+			return 0.0
+		}
+		return fullJaroWinkler(indexed, query)
+	}
+	// the bound is padded so floating point rounding can't skip a name scoring exactly the floor
+	if opts.scoreFloor > 0 && jaroWinklerUpperBound(indexed, query)+1e-9 < opts.scoreFloor {
+		return 0.0
+	}
+	if numbers {
+		return numericJaroWinkler(indexed, query)
+	}
+	return jaroWinkler(indexed, query)
+}
+
+// exactMatch returns 1.0 when indexed is the same as query, both already normalized, otherwise 0.0
+func exactMatch(indexed, query string) float64 {
+	if indexed == query {
+		return 1.0
+	}
+	return 0.0
+}
@@ -0,0 +1,562 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ca"
+	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSearchOptions__read(t *testing.T) {
+	u, _ := url.Parse("/search?q=foo")
+	opts, err := readSearchOptions(u)
+	if err != nil || opts.matchMode != "" {
+		t.Errorf("opts=%#v err=%v", opts, err)
+	}
+
+	u, _ = url.Parse("/search?q=foo&matchMode=Wildcard")
+	opts, err = readSearchOptions(u)
+	if err != nil || opts.matchMode != matchModeWildcard {
+		t.Errorf("opts=%#v err=%v", opts, err)
+	}
+
+	u, _ = url.Parse("/search?q=foo&matchMode=other")
+	if _, err := readSearchOptions(u); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestSearch__wildcardHandler(t *testing.T) {
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nic*+mad?ro&matchMode=wildcard", nil))
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas&matchMode=regex", nil))
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
+
+func TestSearchOptions__strict(t *testing.T) {
+	u, _ := url.Parse("/search?q=foo&strict=true")
+	opts, err := readSearchOptions(u)
+	if err != nil || !opts.strict {
+		t.Errorf("opts=%#v err=%v", opts, err)
+	}
+	for _, q := range []string{"strict=yes", "strict=true&matchMode=wildcard"} {
+		u, _ = url.Parse("/search?q=foo&" + q)
+		if _, err := readSearchOptions(u); err == nil {
+			t.Errorf("%s: expected error", q)
+		}
+	}
+
+	eql(t, "exact", exactMatch("nicolas maduro moros", "nicolas maduro moros"), 1.0)
+	eql(t, "normalized", exactMatch(precompute("MADURO-MOROS"), "maduro moros"), 1.0)
+	eql(t, "fuzzy", exactMatch("nicolas maduro moros", "nicolas maduro"), 0.0)
+}
+
+func TestSearchOptions__strictListedName(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "306", SDNName: "BANCO NACIONAL DE CUBA", Programs: []string{"CUBA"}},
+		}, []*ofac.Address{
+			{EntityID: "306", AddressID: "201", Address: "Calle 13 No. 551", Country: "Cuba"},
+		}, noLogPipeliner),
+		SSIs: precomputeSSIs([]*csl.SSI{
+			{EntityID: "18782", Name: "BANK OF MOSCOW", Type: "Entity"},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	// stopwords were removed from the indexed names
+	if s.SDNs[0].name != "banco nacional cuba" || s.SSIs[0].name != "bank moscow" {
+		t.Fatalf("SDN=%q SSI=%q", s.SDNs[0].name, s.SSIs[0].name)
+	}
+
+	strict := searchOptions{strict: true}
+	if sdns := s.TopSDNs(1, "BANCO NACIONAL DE CUBA", strict); len(sdns) != 1 || sdns[0].match != 1.0 {
+		t.Errorf("SDNs=%#v", sdns)
+	}
+	if sdns := s.TopSDNs(1, "banco nacional cuba", strict); len(sdns) != 1 || sdns[0].match != 1.0 {
+		t.Errorf("SDNs=%#v", sdns)
+	}
+	if ssis := s.TopSSIs(1, "Bank of Moscow", strict); len(ssis) != 1 || ssis[0].match != 1.0 {
+		t.Errorf("SSIs=%#v", ssis)
+	}
+	// other names still don't match
+	if sdns := s.TopSDNs(1, "BANCO NACIONAL", strict); len(sdns) != 1 || sdns[0].match != 0.0 {
+		t.Errorf("SDNs=%#v", sdns)
+	}
+}
+
+func TestSearch__strictHandler(t *testing.T) {
+	type response struct {
+		SDNs      []json.RawMessage `json:"SDNs"`
+		Addresses []json.RawMessage `json:"addresses"`
+	}
+	search := func(s *searcher, query string) response {
+		t.Helper()
+
+		router := mux.NewRouter()
+		addSearchRoutes(log.NewNopLogger(), router, s)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: bogus status code: %d", query, w.Code)
+		}
+		var resp response
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// fuzzy hits are returned normally, but not in strict mode
+	if resp := search(idSearcher, "name=nicolas+maduro"); len(resp.SDNs) != 1 {
+		t.Errorf("expected fuzzy hit: %#v", resp.SDNs)
+	}
+	if resp := search(idSearcher, "name=nicolas+maduro&strict=true"); len(resp.SDNs) != 0 {
+		t.Errorf("unexpected strict hit: %#v", resp.SDNs)
+	}
+	if resp := search(idSearcher, "q=nicolas+maduro&strict=true"); len(resp.SDNs) != 0 {
+		t.Errorf("unexpected strict hit: %#v", resp.SDNs)
+	}
+
+	// exact names and IDs still match
+	if resp := search(idSearcher, "name=Nicolas+Maduro+Moros&strict=true"); len(resp.SDNs) != 1 {
+		t.Errorf("expected exact hit: %#v", resp.SDNs)
+	}
+	if resp := search(idSearcher, "id=5892464&strict=true"); len(resp.SDNs) != 1 {
+		t.Errorf("expected ID hit: %#v", resp.SDNs)
+	}
+
+	// addresses need every component to match
+	if resp := search(addressSearcher, "address=ibex+house&country=united+kingdom&strict=true"); len(resp.Addresses) != 0 {
+		t.Errorf("unexpected strict hit: %#v", resp.Addresses)
+	}
+	if resp := search(addressSearcher, "address=ibex+house+the+minories&city=london&country=united+kingdom&strict=true"); len(resp.Addresses) != 1 {
+		t.Errorf("expected exact hit: %#v", resp.Addresses)
+	}
+}
+
+func TestSearchOptions__fuzzy(t *testing.T) {
+	logger := log.NewNopLogger()
+	for env, expected := range map[string]bool{"": true, "true": true, "false": false, "0": false, "other": true} {
+		if v := getFuzzyMatching(logger, "FUZZY_NAME_MATCHING", env); v != expected {
+			t.Errorf("%q: got %v", env, v)
+		}
+	}
+
+	read := func(query string) searchOptions {
+		t.Helper()
+		u, _ := url.Parse("/search?q=foo&" + query)
+		opts, err := readSearchOptions(u)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return opts
+	}
+	cases := map[string][2]bool{ // query: strict, strictAddresses
+		"":                                   {false, false},
+		"strict=true":                        {true, true},
+		"fuzzyName=false":                    {true, false},
+		"fuzzyAddress=false":                 {false, true},
+		"fuzzyName=false&fuzzyAddress=false": {true, true},
+		"strict=true&fuzzyName=false":        {true, true},
+		"strict=false&fuzzyAddress=true":     {false, false},
+	}
+	for query, expected := range cases {
+		if opts := read(query); opts.strict != expected[0] || opts.strictAddresses != expected[1] {
+			t.Errorf("%s: strict=%v strictAddresses=%v", query, opts.strict, opts.strictAddresses)
+		}
+	}
+	for _, query := range []string{"fuzzyName=maybe", "strict=true&fuzzyAddress=true", "fuzzyName=false&matchMode=wildcard"} {
+		u, _ := url.Parse("/search?q=foo&" + query)
+		if _, err := readSearchOptions(u); err == nil {
+			t.Errorf("%s: expected error", query)
+		}
+	}
+
+	// the defaults are overridden per request
+	defer func(names, addresses bool) {
+		fuzzyNameMatching, fuzzyAddressMatching = names, addresses
+	}(fuzzyNameMatching, fuzzyAddressMatching)
+	fuzzyNameMatching, fuzzyAddressMatching = false, false
+	if opts := read(""); !opts.strict || !opts.strictAddresses {
+		t.Errorf("expected exact defaults: %#v", opts)
+	}
+	if opts := read("fuzzyName=true"); opts.strict || !opts.strictAddresses {
+		t.Errorf("expected fuzzy names: %#v", opts)
+	}
+	if opts := read("fuzzyAddress=true"); !opts.strict || opts.strictAddresses {
+		t.Errorf("expected fuzzy addresses: %#v", opts)
+	}
+}
+
+func TestSearch__fuzzyNameAndAddressHandler(t *testing.T) {
+	s := &searcher{
+		SDNs: idSearcher.SDNs,
+		Addresses: precomputeAddresses([]*ofac.Address{
+			{
+				EntityID:                    "22790",
+				AddressID:                   "1",
+				Address:                     "Miraflores Palace",
+				CityStateProvincePostalCode: "Caracas",
+				Country:                     "Venezuela",
+			},
+		}),
+		pipe: noLogPipeliner,
+	}
+	search := func(query string) (int, int) {
+		t.Helper()
+
+		router := mux.NewRouter()
+		addSearchRoutes(log.NewNopLogger(), router, s)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: bogus status code: %d", query, w.Code)
+		}
+		var resp struct {
+			SDNs      []json.RawMessage `json:"SDNs"`
+			Addresses []json.RawMessage `json:"addresses"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return len(resp.SDNs), len(resp.Addresses)
+	}
+
+	names := map[string]bool{"nicolas+maduro": false, "nicolas+maduro+moros": true}
+	addresses := map[string]bool{"miraflores": false, "miraflores+palace": true}
+	for _, fuzzyName := range []bool{true, false} {
+		for _, fuzzyAddress := range []bool{true, false} {
+			for name, exactName := range names {
+				for address, exactAddress := range addresses {
+					query := fmt.Sprintf("name=%s&address=%s&country=venezuela&fuzzyName=%v&fuzzyAddress=%v", name, address, fuzzyName, fuzzyAddress)
+					sdns, adds := search(query)
+					if expected := fuzzyName || exactName; (sdns == 1) != expected {
+						t.Errorf("%s: got %d SDNs", query, sdns)
+					}
+					if expected := fuzzyAddress || exactAddress; (adds == 1) != expected {
+						t.Errorf("%s: got %d addresses", query, adds)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSearch__matchedNameHandler(t *testing.T) {
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, isnSearcher)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=abu+hamed&limit=1&matchedName=true", nil))
+	w.Flush()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	var resp struct {
+		ISNs []struct {
+			Name            string `json:"name"`
+			MatchedName     string `json:"matchedName"`
+			MatchedNameType string `json:"matchedNameType"`
+		} `json:"nonproliferationSanctions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.ISNs) != 1 {
+		t.Fatalf("ISNs: %#v", resp.ISNs)
+	}
+	if isn := resp.ISNs[0]; isn.Name != "Mohammed Hamed" || isn.MatchedName != "abu hamed" || isn.MatchedNameType != "aka" {
+		t.Errorf("unexpected ISN: %#v", isn)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=abu+hamed&matchedName=maybe", nil))
+	w.Flush()
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
+
+func TestSearchOptions__minNameTokens(t *testing.T) {
+	logger := log.NewNopLogger()
+	for env, expected := range map[string]int{"": 1, "2": 2, "0": 1, "two": 1} {
+		if n := getMinMatchingNameTokens(logger, env); n != expected {
+			t.Errorf("%q: got %d", env, n)
+		}
+	}
+
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"},
+			{EntityID: "1", SDNName: "MADURO SHIPPING", SDNType: ""},
+		}, nil, noLogPipeliner),
+		Alts: precomputeAlts([]*ofac.AlternateIdentity{
+			{EntityID: "22790", AlternateID: "1", AlternateType: "aka", AlternateName: "MADURO, Nicolas"},
+			{EntityID: "1", AlternateID: "2", AlternateType: "aka", AlternateName: "MADURO LINES"},
+		}),
+		CanadianSanctions: precomputeCanadianSanctions([]*ca.Entry{
+			{Type: ca.TypeIndividual, LastName: "Maduro Moros", GivenName: "Nicolas"},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	s.individuals = individualEntityIDs(s.SDNs)
+	ids := func(sdns []SDN) []string {
+		var out []string
+		for i := range sdns {
+			out = append(out, sdns[i].EntityID)
+		}
+		return out
+	}
+
+	// a single token matches the individual until two tokens are required
+	sdns := s.TopSDNs(10, "maduro", searchOptions{minNameTokens: 1})
+	if len(sdns) != 2 {
+		t.Errorf("SDNs: %v", ids(sdns))
+	}
+	sdns = s.TopSDNs(10, "maduro", searchOptions{minNameTokens: 2})
+	if len(sdns) != 1 || sdns[0].EntityID != "1" {
+		t.Errorf("SDNs: %v", ids(sdns)) // the entity isn't filtered
+	}
+	if cas := s.TopCanadianSanctions(10, "maduro", searchOptions{minNameTokens: 2}); len(cas) != 0 {
+		t.Errorf("CanadianSanctions: %#v", cas)
+	}
+	if alts := s.TopAltNames(10, "maduro", searchOptions{minNameTokens: 1}); len(alts) != 2 {
+		t.Errorf("AltNames: %#v", alts)
+	}
+	if alts := s.TopAltNames(10, "maduro", searchOptions{minNameTokens: 2}); len(alts) != 1 || alts[0].AlternateIdentity.EntityID != "1" {
+		t.Errorf("AltNames: %#v", alts) // only the individual's alt name is filtered
+	}
+
+	// two tokens pass, even when misspelled slightly
+	for _, q := range []string{"nicolas maduro", "nicolas madurro"} {
+		sdns = s.TopSDNs(10, q, searchOptions{minNameTokens: 2})
+		if len(sdns) != 2 || sdns[0].EntityID != "22790" {
+			t.Errorf("%s: SDNs: %v", q, ids(sdns))
+		}
+		if cas := s.TopCanadianSanctions(10, q, searchOptions{minNameTokens: 2}); len(cas) != 1 {
+			t.Errorf("%s: CanadianSanctions: %#v", q, cas)
+		}
+		if alts := s.TopAltNames(10, q, searchOptions{minNameTokens: 2}); len(alts) != 2 {
+			t.Errorf("%s: AltNames: %#v", q, alts)
+		}
+	}
+
+	// ID searches aren't affected
+	minMatchingNameTokens = 2
+	defer func() { minMatchingNameTokens = defaultMinMatchingNameTokens }()
+
+	u, _ := url.Parse("/search?name=maduro")
+	if opts, _ := readSearchOptions(u); opts.minNameTokens != 2 {
+		t.Errorf("opts=%#v", opts)
+	}
+	if sdns := idSearcher.FindSDNsByRemarksID(1, "5892464"); len(sdns) != 1 {
+		t.Errorf("SDNs: %v", ids(sdns))
+	}
+}
+
+// testdataSDNs is every SDN (and alt name) from test/testdata, used to compare scoring at scale
+func testdataSDNs(tb testing.TB) *searcher {
+	tb.Helper()
+	res, err := ofac.Read(filepath.Join("..", "..", "test", "testdata", "sdn.csv"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	alts, err := ofac.Read(filepath.Join("..", "..", "test", "testdata", "alt.csv"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return &searcher{
+		SDNs: precomputeSDNs(res.SDNs, nil, noLogPipeliner),
+		Alts: precomputeAlts(alts.AlternateIdentities),
+		pipe: noLogPipeliner,
+	}
+}
+
+var scoreFloorQueries = []string{
+	"nicolas maduro", "banco nacional de cuba", "mohammed hamed", "al qaida", "vladimir putin",
+	"ali", "jose gonzalez", "aeroflot", "kim jong un", "zzzz",
+}
+
+func TestSearchOptions__scoreFloor(t *testing.T) {
+	logger := log.NewNopLogger()
+	for env, expected := range map[string]float64{"": 0, "0.8": 0.8, "1": 1, "1.5": 0, "-0.1": 0, "high": 0} {
+		if v := getScoreFloor(logger, env); v != expected {
+			t.Errorf("%q: got %v", env, v)
+		}
+	}
+
+	s := testdataSDNs(t)
+	for _, floor := range []float64{0.5, 0.8, 0.9, 0.95} {
+		for _, q := range scoreFloorQueries {
+			query := precompute(q)
+			full := s.TopSDNs(hardResultsLimit, query, searchOptions{})
+			skipped := s.TopSDNs(hardResultsLimit, query, searchOptions{scoreFloor: floor})
+
+			// results above the floor are identical and in the same order
+			var expected, got []SDN
+			for i := range full {
+				if full[i].match >= floor {
+					expected = append(expected, full[i])
+				}
+			}
+			for i := range skipped {
+				if skipped[i].match >= floor {
+					got = append(got, skipped[i])
+				}
+			}
+			if len(expected) != len(got) {
+				t.Fatalf("floor=%v %s: got %d results above the floor, expected %d", floor, q, len(got), len(expected))
+			}
+			for i := range expected {
+				if expected[i].EntityID != got[i].EntityID || expected[i].match != got[i].match {
+					t.Errorf("floor=%v %s #%d: got %s (%v) expected %s (%v)", floor, q, i,
+						got[i].EntityID, got[i].match, expected[i].EntityID, expected[i].match)
+				}
+			}
+
+			alts := s.TopAltNames(hardResultsLimit, query, searchOptions{})
+			altsSkipped := s.TopAltNames(hardResultsLimit, query, searchOptions{scoreFloor: floor})
+			for i := range alts {
+				if alts[i].match < floor {
+					break
+				}
+				if alts[i].AlternateIdentity.AlternateID != altsSkipped[i].AlternateIdentity.AlternateID || alts[i].match != altsSkipped[i].match {
+					t.Errorf("floor=%v %s alt #%d: got %#v expected %#v", floor, q, i, altsSkipped[i], alts[i])
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkSearchOptions__scoreFloor(b *testing.B) {
+	s := testdataSDNs(b)
+	for _, floor := range []float64{0, 0.8, 0.9} {
+		opts := searchOptions{scoreFloor: floor}
+		b.Run(fmt.Sprintf("floor=%v", floor), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s.TopSDNs(10, precompute(scoreFloorQueries[i%len(scoreFloorQueries)]), opts)
+			}
+		})
+	}
+}
+
+func TestSearchOptions__nameScoring(t *testing.T) {
+	logger := log.NewNopLogger()
+	for env, expected := range map[string]string{"": nameScoringTokens, "tokens": nameScoringTokens, "Full": nameScoringFull, "other": nameScoringTokens} {
+		if v := getNameScoring(logger, env); v != expected {
+			t.Errorf("%q: got %q", env, v)
+		}
+	}
+
+	u, _ := url.Parse("/search?q=foo&nameScoring=other")
+	if _, err := readSearchOptions(u); err == nil {
+		t.Error("expected error")
+	}
+
+	// each default is overridden per request
+	defer func(scoring string) { defaultNameScoring = scoring }(defaultNameScoring)
+	for _, scoring := range []string{nameScoringTokens, nameScoringFull} {
+		defaultNameScoring = scoring
+		for query, expected := range map[string]string{"": scoring, "&nameScoring=tokens": nameScoringTokens, "&nameScoring=full": nameScoringFull} {
+			u, _ := url.Parse("/search?q=foo" + query)
+			opts, err := readSearchOptions(u)
+			if err != nil || opts.nameScoring != expected {
+				t.Errorf("default=%s %q: opts=%#v err=%v", scoring, query, opts, err)
+			}
+		}
+	}
+}
+
+func TestSearchOptions__nameScoringReordered(t *testing.T) {
+	s := testdataSDNs(t)
+	tokens, full := searchOptions{nameScoring: nameScoringTokens}, searchOptions{nameScoring: nameScoringFull}
+
+	// in order names score the same either way
+	in, reordered := precompute("Nicolas Maduro Moros"), precompute("Maduro Moros Nicolas")
+	top := func(query string, opts searchOptions) SDN {
+		t.Helper()
+		sdns := s.TopSDNs(1, query, opts)
+		if len(sdns) == 0 {
+			t.Fatalf("no results for %q", query)
+		}
+		return sdns[0]
+	}
+	for _, opts := range []searchOptions{tokens, full} {
+		if sdn := top(in, opts); sdn.EntityID != "22790" || sdn.match != 1.0 {
+			t.Errorf("%s: got %s (%v)", opts.nameScoring, sdn.EntityID, sdn.match)
+		}
+	}
+
+	// reordered names only score highly with token scoring
+	if sdn := top(reordered, tokens); sdn.EntityID != "22790" || sdn.match != 1.0 {
+		t.Errorf("tokens: got %s (%v)", sdn.EntityID, sdn.match)
+	}
+	if score := full.score(in, reordered); score > 0.8 {
+		t.Errorf("full: reordered name scored %v", score)
+	}
+	if sdn := top(reordered, full); sdn.match >= top(reordered, tokens).match {
+		t.Errorf("full: got %s (%v)", sdn.EntityID, sdn.match)
+	}
+
+	// the score floor still applies to full scoring
+	for _, q := range scoreFloorQueries {
+		query := precompute(q)
+		skipped := s.TopSDNs(hardResultsLimit, query, searchOptions{nameScoring: nameScoringFull, scoreFloor: 0.8})
+		for i, sdn := range s.TopSDNs(hardResultsLimit, query, full) {
+			if sdn.match < 0.8 {
+				break
+			}
+			if sdn.EntityID != skipped[i].EntityID || sdn.match != skipped[i].match {
+				t.Errorf("%s #%d: got %s (%v) expected %s (%v)", q, i, skipped[i].EntityID, skipped[i].match, sdn.EntityID, sdn.match)
+			}
+		}
+	}
+}
+
+func BenchmarkSearchOptions__nameScoring(b *testing.B) {
+	s := testdataSDNs(b)
+	for _, scoring := range []string{nameScoringTokens, nameScoringFull} {
+		opts := searchOptions{nameScoring: scoring}
+		b.Run(scoring, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s.TopSDNs(10, precompute(scoreFloorQueries[i%len(scoreFloorQueries)]), opts)
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	// defaultMaxBodyBytes fits batches of several thousand searches
+	defaultMaxBodyBytes int64 = 1 << 20 // 1MiB
+
+	// searchMaxBodyBytes limits request bodies of POST /search and POST /search/batch, it's read
+	// from SEARCH_MAX_BODY_BYTES
+	searchMaxBodyBytes = defaultMaxBodyBytes
+
+	errNoBatchSearches = errors.New("no searches in batch")
+)
+
+// getSearchMaxBodyBytes reads a positive number of bytes
+//
+// env is the value from an environmental variable
+func getSearchMaxBodyBytes(logger log.Logger, env string) int64 {
+	if env == "" {
+		return defaultMaxBodyBytes
+	}
+	n, err := strconv.ParseInt(env, 10, 64)
+	if err != nil || n <= 0 {
+		logger.Log("main", fmt.Sprintf("invalid SEARCH_MAX_BODY_BYTES=%q, using default of %d", env, defaultMaxBodyBytes))
+		return defaultMaxBodyBytes
+	}
+	logger.Log("main", fmt.Sprintf("Limiting search request bodies to %d bytes", n))
+	return n
+}
+
+// readBody reads the request body up to searchMaxBodyBytes. When the body is larger a 413
+// response is written and false is returned.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	bs, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, searchMaxBodyBytes))
+	if err != nil {
+		if !bodyTooLarge(w, err) {
+			moovhttp.Problem(w, err)
+		}
+		return nil, false
+	}
+	return bs, true
+}
+
+// bodyTooLarge writes a 413 response and returns true when err is from reading beyond searchMaxBodyBytes
+func bodyTooLarge(w http.ResponseWriter, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": fmt.Sprintf("request body is larger than %d bytes", tooLarge.Limit),
+	})
+	return true
+}
+
+// searchValues converts a JSON object of search parameters into the query parameters
+// accepted by GET /search. Lists (like sources) are comma separated, except name which is
+// repeated.
+func searchValues(params map[string]interface{}) (url.Values, error) {
+	values := make(url.Values)
+	for k, v := range params {
+		switch vv := v.(type) {
+		case nil:
+		case string:
+			values.Set(k, vv)
+		case float64:
+			values.Set(k, strconv.FormatFloat(vv, 'f', -1, 64))
+		case bool:
+			values.Set(k, strconv.FormatBool(vv))
+		case []interface{}:
+			var parts []string
+			for i := range vv {
+				s, ok := vv[i].(string)
+				if !ok {
+					return nil, fmt.Errorf("unsupported value in %s: %v", k, vv[i])
+				}
+				parts = append(parts, s)
+			}
+			if k == "name" {
+				values[k] = parts // names can contain commas, so they're repeated instead
+			} else {
+				values.Set(k, strings.Join(parts, ","))
+			}
+		default:
+			return nil, fmt.Errorf("unsupported value for %s: %v", k, v)
+		}
+	}
+	return values, nil
+}
+
+// bufferedResponse captures a search response so it can be embedded in a batch response
+type bufferedResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	if b.code == 0 {
+		b.code = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.code == 0 {
+		b.code = code
+	}
+}
+
+// runSearch performs the GET /search handler with params as its query parameters
+func runSearch(logger log.Logger, searcher *searcher, r *http.Request, params url.Values) *bufferedResponse {
+	req := r.Clone(r.Context())
+	req.Method = "GET"
+	req.URL.Path = "/search"
+	req.URL.RawQuery = params.Encode()
+	req.Body = http.NoBody
+	req.ContentLength = 0
+
+	resp := &bufferedResponse{
+		header: make(http.Header),
+	}
+	search(logger, searcher)(resp, req)
+	if resp.code == 0 {
+		resp.code = http.StatusNoContent
+	}
+	return resp
+}
+
+// searchViaPost accepts the query parameters of GET /search as a JSON object
+func searchViaPost(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		var params map[string]interface{}
+		if err := json.Unmarshal(body, &params); err != nil {
+			moovhttp.Problem(w, fmt.Errorf("invalid search: %v", err))
+			return
+		}
+		values, err := searchValues(params)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+
+		resp := runSearch(logger, searcher, r, values)
+		for k, v := range resp.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.code)
+		w.Write(resp.body.Bytes())
+	}
+}
+
+type batchSearchRequest struct {
+	Searches []json.RawMessage `json:"searches"`
+}
+
+type batchSearchResult struct {
+	Status int             `json:"status"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+type batchSearchResponse struct {
+	Results []batchSearchResult `json:"results"`
+}
+
+// searchBatch performs several searches in one request, up to searchBatchWorkers at once. Each
+// search has its own status so an invalid search doesn't fail the others.
+func searchBatch(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		req, ok := readBatch(w, r)
+		if !ok {
+			return
+		}
+
+		out := batchSearchResponse{
+			Results: make([]batchSearchResult, 0, len(req.Searches)),
+		}
+		var read int
+		next := func() (json.RawMessage, error) {
+			if read == len(req.Searches) {
+				return nil, io.EOF
+			}
+			read++
+			return req.Searches[read-1], nil
+		}
+		run := func(search json.RawMessage) batchSearchResult {
+			return runBatchSearch(logger, searcher, r, search)
+		}
+		emit := func(_ int, result batchSearchResult) error {
+			out.Results = append(out.Results, result)
+			return nil
+		}
+		if _, err := runOrderedBatch(r.Context(), searchBatchWorkers, next, run, emit); err != nil {
+			return // the client went away
+		}
+
+		if version := searcher.dataVersion(); version != "" {
+			w.Header().Set(dataVersionHeader, version)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(out)
+	}
+}
+
+// readBatch decodes the body of a batch request, writing an error response and returning false
+// when it's invalid or empty
+func readBatch(w http.ResponseWriter, r *http.Request) (batchSearchRequest, bool) {
+	var req batchSearchRequest
+	body, ok := readBody(w, r)
+	if !ok {
+		return req, false
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		moovhttp.Problem(w, fmt.Errorf("invalid batch: %v", err))
+		return req, false
+	}
+	if len(req.Searches) == 0 {
+		moovhttp.Problem(w, errNoBatchSearches)
+		return req, false
+	}
+	return req, true
+}
+
+// runBatchSearch performs one search of a batch
+func runBatchSearch(logger log.Logger, searcher *searcher, r *http.Request, search json.RawMessage) batchSearchResult {
+	var params map[string]interface{}
+	if err := json.Unmarshal(search, &params); err != nil {
+		return batchSearchResult{
+			Status: http.StatusBadRequest,
+			Error:  fmt.Sprintf("invalid search: %v", err),
+		}
+	}
+	values, err := searchValues(params)
+	if err != nil {
+		return batchSearchResult{
+			Status: http.StatusBadRequest,
+			Error:  err.Error(),
+		}
+	}
+	return batchResult(runSearch(logger, searcher, r, values))
+}
+
+// streamedSearchResult is a batchSearchResult along with the position of its search in the batch
+type streamedSearchResult struct {
+	Index int `json:"index"`
+	batchSearchResult
+}
+
+// searchBatchStream performs the searches of a batch like searchBatch, but sends each result
+// as a Server-Sent Event as soon as it and every search before it are done. A final "done" event
+// has the number of results. The body is decoded as the searches are performed rather than read
+// up front, a body which turns out to be invalid ends the stream with an "error" event.
+func searchBatchStream(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the wrapped writer doesn't expose Flush, so keep the original
+		flusher, _ := w.(http.Flusher)
+		controller := http.NewResponseController(w)
+		w = wrapResponseWriter(logger, w, r)
+
+		// HTTP/1 request bodies can't be read once the response has started unless the server
+		// allows full duplex, otherwise the whole body is read up front
+		var body io.Reader
+		if err := controller.EnableFullDuplex(); err == nil {
+			body = http.MaxBytesReader(w, r.Body, searchMaxBodyBytes)
+		} else {
+			bs, ok := readBody(w, r)
+			if !ok {
+				return
+			}
+			body = bytes.NewReader(bs)
+		}
+
+		// the first search is read before responding so an invalid or empty batch is rejected
+		searches := newBatchDecoder(body)
+		first, err := searches.next()
+		if err != nil {
+			switch {
+			case err == io.EOF:
+				moovhttp.Problem(w, errNoBatchSearches)
+			case !bodyTooLarge(w, err):
+				moovhttp.Problem(w, fmt.Errorf("invalid batch: %v", err))
+			}
+			return
+		}
+
+		if version := searcher.dataVersion(); version != "" {
+			w.Header().Set(dataVersionHeader, version)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		setPrivacyHeaders(w)
+		extendWriteDeadline(controller)
+		w.WriteHeader(http.StatusOK)
+
+		send := func(event string, v interface{}) error {
+			bs, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			extendWriteDeadline(controller)
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, bs); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		}
+		next := func() (json.RawMessage, error) {
+			if first != nil {
+				search := first
+				first = nil
+				return search, nil
+			}
+			return searches.next()
+		}
+		run := func(search json.RawMessage) batchSearchResult {
+			return runBatchSearch(logger, searcher, r, search)
+		}
+		emit := func(i int, result batchSearchResult) error {
+			return send("result", streamedSearchResult{i, result})
+		}
+		n, err := runOrderedBatch(r.Context(), searchBatchWorkers, next, run, emit)
+		if err != nil {
+			if r.Context().Err() != nil {
+				return // the client went away
+			}
+			logger.Log("search", fmt.Sprintf("batch stream: %v", err), "requestID", moovhttp.GetRequestID(r))
+			send("error", map[string]string{"error": fmt.Sprintf("invalid batch: %v", err)})
+			return
+		}
+		send("done", map[string]int{"results": n})
+	}
+}
+
+func batchResult(resp *bufferedResponse) batchSearchResult {
+	result := batchSearchResult{
+		Status: resp.code,
+	}
+	if resp.code == http.StatusOK {
+		result.Result = json.RawMessage(bytes.TrimSpace(resp.body.Bytes()))
+		return result
+	}
+	var problem struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(resp.body.Bytes(), &problem); err == nil && problem.Error != "" {
+		result.Error = problem.Error
+	} else {
+		result.Error = http.StatusText(resp.code)
+	}
+	return result
+}
@@ -0,0 +1,237 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func postSearch(t *testing.T, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", path, strings.NewReader(body)))
+	w.Flush()
+	return w
+}
+
+func TestSearch__post(t *testing.T) {
+	w := postSearch(t, "/search", `{"name": "nicolas maduro", "limit": 1, "sources": ["SDN"], "highlight": true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		SDNs []*ofac.SDN `json:"SDNs"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.SDNs) != 1 || resp.SDNs[0].EntityID != "22790" {
+		t.Errorf("%#v", resp.SDNs)
+	}
+
+	// invalid parameters are rejected like GET /search
+	if w := postSearch(t, "/search", `{"name": "nicolas maduro", "sources": "other"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+	if w := postSearch(t, "/search", `{"name": {"first": "nicolas"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+	if w := postSearch(t, "/search", `not json`); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
+
+func TestSearch__batch(t *testing.T) {
+	w := postSearch(t, "/search/batch", `{"searches": [
+		{"name": "nicolas maduro", "limit": 1},
+		{"name": "nicolas maduro", "sources": "other"},
+		{"id": "5892464"},
+		{"sources": [1, 2]}
+	]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp batchSearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 4 {
+		t.Fatalf("got %d results", len(resp.Results))
+	}
+	for i, status := range []int{http.StatusOK, http.StatusBadRequest, http.StatusOK, http.StatusBadRequest} {
+		if resp.Results[i].Status != status {
+			t.Errorf("#%d: status=%d", i, resp.Results[i].Status)
+		}
+	}
+	if !strings.Contains(string(resp.Results[0].Result), `"entityID":"22790"`) {
+		t.Errorf("#0: %s", resp.Results[0].Result)
+	}
+	if !strings.Contains(resp.Results[1].Error, "unknown source") {
+		t.Errorf("#1: %q", resp.Results[1].Error)
+	}
+	if resp.Results[3].Error == "" || resp.Results[3].Result != nil {
+		t.Errorf("#3: %#v", resp.Results[3])
+	}
+
+	if w := postSearch(t, "/search/batch", `{"searches": []}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
+
+func TestSearch__maxBodyBytes(t *testing.T) {
+	if n := getSearchMaxBodyBytes(log.NewNopLogger(), ""); n != defaultMaxBodyBytes {
+		t.Errorf("got %d", n)
+	}
+	if n := getSearchMaxBodyBytes(log.NewNopLogger(), "-1"); n != defaultMaxBodyBytes {
+		t.Errorf("got %d", n)
+	}
+	if n := getSearchMaxBodyBytes(log.NewNopLogger(), "2048"); n != 2048 {
+		t.Errorf("got %d", n)
+	}
+
+	bodies := map[string]string{
+		"/search":       `{"name": "nicolas maduro"}`,
+		"/search/batch": `{"searches": [{"name": "nicolas maduro"}]}`,
+	}
+	for path, body := range bodies {
+		prev := searchMaxBodyBytes
+		searchMaxBodyBytes = int64(len(body))
+
+		// at the limit
+		if w := postSearch(t, path, body); w.Code != http.StatusOK {
+			t.Errorf("%s: bogus status code: %d", path, w.Code)
+		}
+
+		// above the limit
+		w := postSearch(t, path, body+" ")
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: bogus status code: %d", path, w.Code)
+		}
+		var problem struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&problem); err != nil || !strings.Contains(problem.Error, "larger than") {
+			t.Errorf("%s: error=%q err=%v", path, problem.Error, err)
+		}
+
+		searchMaxBodyBytes = prev
+	}
+}
+
+func TestSearch__batchStream(t *testing.T) {
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	var searches []string
+	for i := 0; i < 20; i++ {
+		if i%5 == 4 {
+			searches = append(searches, `{"name": "nicolas maduro", "sources": "other"}`)
+		} else {
+			searches = append(searches, fmt.Sprintf(`{"name": "nicolas maduro", "limit": %d}`, i%3+1))
+		}
+	}
+	body := `{"searches": [` + strings.Join(searches, ",") + `]}`
+	resp, err := http.Post(server.URL+"/search/batch/stream", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("bogus status code: %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type: %s", ct)
+	}
+
+	// read each event as it arrives
+	seen := make(map[int]int)
+	var event string
+	var done bool
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data := []byte(strings.TrimPrefix(line, "data: "))
+			switch event {
+			case "result":
+				var result struct {
+					Index  int             `json:"index"`
+					Status int             `json:"status"`
+					Result json.RawMessage `json:"result"`
+					Error  string          `json:"error"`
+				}
+				if err := json.Unmarshal(data, &result); err != nil {
+					t.Fatal(err)
+				}
+				if done {
+					t.Errorf("result %d after done", result.Index)
+				}
+				seen[result.Index]++
+				if result.Index%5 == 4 {
+					if result.Status != http.StatusBadRequest || result.Error == "" {
+						t.Errorf("#%d: %#v", result.Index, result)
+					}
+				} else if result.Status != http.StatusOK || !strings.Contains(string(result.Result), `"entityID":"22790"`) {
+					t.Errorf("#%d: %#v", result.Index, result)
+				}
+			case "done":
+				var summary struct {
+					Results int `json:"results"`
+				}
+				if err := json.Unmarshal(data, &summary); err != nil {
+					t.Fatal(err)
+				}
+				if summary.Results != len(searches) {
+					t.Errorf("done with %d results", summary.Results)
+				}
+				done = true
+			default:
+				t.Errorf("unexpected event %q", event)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if !done {
+		t.Error("missing done event")
+	}
+	for i := range searches {
+		if seen[i] != 1 {
+			t.Errorf("#%d seen %d times", i, seen[i])
+		}
+	}
+	if len(seen) != len(searches) {
+		t.Errorf("got %d results", len(seen))
+	}
+
+	// invalid batches are rejected before streaming
+	if w := postSearch(t, "/search/batch/stream", `{"searches": []}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+)
+
+const searchPreviewPath = "/admin/search/preview"
+
+var (
+	errNoPreviewCandidate = errors.New("no candidate search parameters")
+	errNoPreviewQueries   = errors.New("no queries to preview")
+)
+
+// searchPreviewRequest has a candidate scoring config, made of GET /search parameters like
+// nameScoring or nameFrequency, and the queries to compare it on
+type searchPreviewRequest struct {
+	Candidate map[string]interface{}   `json:"candidate"`
+	Queries   []map[string]interface{} `json:"queries"`
+}
+
+// searchPreviewResult is a query's results with the current config and with the candidate's
+// parameters set over the query's
+type searchPreviewResult struct {
+	Query     map[string]interface{} `json:"query"`
+	Current   batchSearchResult      `json:"current"`
+	Candidate batchSearchResult      `json:"candidate"`
+}
+
+type searchPreviewResponse struct {
+	Results []searchPreviewResult `json:"results"`
+}
+
+// searchPreviewHandler searches each query of the request twice on the admin server, so the
+// effect of a scoring config can be seen before it's rolled out. The live config isn't changed,
+// the candidate only applies to the searches of the request.
+func searchPreviewHandler(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		var req searchPreviewRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			moovhttp.Problem(w, fmt.Errorf("invalid preview: %v", err))
+			return
+		}
+		if len(req.Candidate) == 0 {
+			moovhttp.Problem(w, errNoPreviewCandidate)
+			return
+		}
+		if len(req.Queries) == 0 {
+			moovhttp.Problem(w, errNoPreviewQueries)
+			return
+		}
+		candidate, err := searchValues(req.Candidate)
+		if err != nil {
+			moovhttp.Problem(w, fmt.Errorf("invalid candidate: %v", err))
+			return
+		}
+		if _, err := readSearchOptions(&url.URL{RawQuery: candidate.Encode()}); err != nil {
+			moovhttp.Problem(w, fmt.Errorf("invalid candidate: %v", err))
+			return
+		}
+
+		if requestID := moovhttp.GetRequestID(r); requestID != "" {
+			logger.Log("admin", fmt.Sprintf("previewing %d queries", len(req.Queries)), "requestID", requestID)
+		}
+
+		out := searchPreviewResponse{
+			Results: make([]searchPreviewResult, 0, len(req.Queries)),
+		}
+		for _, query := range req.Queries {
+			out.Results = append(out.Results, previewSearch(logger, searcher, r, query, candidate))
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(out)
+	}
+}
+
+// previewSearch performs query as it is and with the candidate parameters set over it
+func previewSearch(logger log.Logger, searcher *searcher, r *http.Request, query map[string]interface{}, candidate url.Values) searchPreviewResult {
+	result := searchPreviewResult{Query: query}
+
+	current, err := searchValues(query)
+	if err != nil {
+		result.Current = batchSearchResult{Status: http.StatusBadRequest, Error: err.Error()}
+		result.Candidate = result.Current
+		return result
+	}
+	candidates := make(url.Values)
+	for k, v := range current {
+		candidates[k] = v
+	}
+	for k, v := range candidate {
+		candidates[k] = v
+	}
+
+	result.Current = batchResult(runSearch(logger, searcher, r, current))
+	result.Candidate = batchResult(runSearch(logger, searcher, r, candidates))
+	return result
+}
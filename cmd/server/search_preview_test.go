@@ -0,0 +1,101 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestSearchPreview(t *testing.T) {
+	body := `{"candidate": {"nameScoring": "full"}, "queries": [{"name": "moros nicolas maduro", "limit": 1}, {"name": "moros nicolas maduro", "minMatch": 2}]}`
+	w := httptest.NewRecorder()
+	searchPreviewHandler(log.NewNopLogger(), idSearcher)(w, httptest.NewRequest("POST", searchPreviewPath, strings.NewReader(body)))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	var resp searchPreviewResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("%#v", resp)
+	}
+
+	// each result matches a search with that config
+	topMatch := func(result batchSearchResult) float64 {
+		t.Helper()
+		if result.Status != http.StatusOK {
+			t.Fatalf("status=%d error=%s", result.Status, result.Error)
+		}
+		var wrapper struct {
+			SDNs []struct {
+				EntityID string  `json:"entityID"`
+				Match    float64 `json:"match"`
+			} `json:"SDNs"`
+		}
+		if err := json.Unmarshal(result.Result, &wrapper); err != nil {
+			t.Fatal(err)
+		}
+		if len(wrapper.SDNs) != 1 || wrapper.SDNs[0].EntityID != "22790" {
+			t.Fatalf("%s", result.Result)
+		}
+		return wrapper.SDNs[0].Match
+	}
+	expected := func(query string) float64 {
+		t.Helper()
+		opts, err := readSearchOptions(&url.URL{RawQuery: query})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return idSearcher.TopSDNs(1, "moros nicolas maduro", opts)[0].match
+	}
+	current, candidate := topMatch(resp.Results[0].Current), topMatch(resp.Results[0].Candidate)
+	eql(t, "current", current, expected(""))
+	eql(t, "candidate", candidate, expected("nameScoring=full"))
+	if current == candidate {
+		t.Errorf("configs scored the same: %v", current)
+	}
+
+	// invalid queries fail under both configs
+	if res := resp.Results[1]; res.Current.Status != http.StatusBadRequest || res.Candidate.Status != http.StatusBadRequest {
+		t.Errorf("%#v", res)
+	}
+
+	// the live config isn't changed
+	if defaultNameScoring != nameScoringTokens {
+		t.Errorf("defaultNameScoring=%q", defaultNameScoring)
+	}
+}
+
+func TestSearchPreview__errors(t *testing.T) {
+	handler := searchPreviewHandler(log.NewNopLogger(), idSearcher)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", searchPreviewPath, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+
+	for _, body := range []string{
+		`{`,
+		`{"queries": [{"name": "maduro"}]}`,
+		`{"candidate": {"nameScoring": "full"}}`,
+		`{"candidate": {"nameScoring": "other"}, "queries": [{"name": "maduro"}]}`,
+	} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("POST", searchPreviewPath, strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: bogus status code: %d", body, w.Code)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	defaultProgramBoost = 0.05
+)
+
+var (
+	// programBoost is how much higher results in a program of ?program= rank than results which
+	// aren't, main sets it from PROGRAM_BOOST
+	programBoost = defaultProgramBoost
+)
+
+// getProgramBoost reads a boost between 0 and 1
+//
+// env is the value from an environmental variable
+func getProgramBoost(logger log.Logger, env string) float64 {
+	if env == "" {
+		return defaultProgramBoost
+	}
+	n, err := strconv.ParseFloat(env, 64)
+	if err != nil || n < 0 || n > 1 {
+		logger.Log("main", fmt.Sprintf("invalid PROGRAM_BOOST=%q, using %.2f", env, defaultProgramBoost))
+		return defaultProgramBoost
+	}
+	logger.Log("main", fmt.Sprintf("Ranking results in a searched program %.2f higher", n))
+	return n
+}
+
+// readPrograms reads the comma separated (or repeated) ?program= values, which are compared
+// case-insensitively
+func readPrograms(u *url.URL) map[string]bool {
+	var out map[string]bool
+	for _, raw := range u.Query()["program"] {
+		for _, program := range strings.Split(raw, ",") {
+			if program = strings.TrimSpace(program); program == "" {
+				continue
+			}
+			if out == nil {
+				out = make(map[string]bool)
+			}
+			out[strings.ToUpper(program)] = true
+		}
+	}
+	return out
+}
+
+// inProgramBoost returns how much higher a result in programs ranks. Like PROGRAM_RISK_TIERS codes
+// with a suffix (VENEZUELA-EO13850) are in their prefix's program (VENEZUELA). Unlike
+// ?ofacProgram= results outside of the searched programs are kept, and their match isn't changed.
+func (opts searchOptions) inProgramBoost(programs []string) float64 {
+	if len(opts.programs) == 0 {
+		return 0.0
+	}
+	for _, program := range programs {
+		code := strings.ToUpper(strings.TrimSpace(program))
+		if opts.programs[code] {
+			return programBoost
+		}
+		if idx := strings.Index(code, "-"); idx > 0 && opts.programs[code[:idx]] {
+			return programBoost
+		}
+	}
+	return 0.0
+}
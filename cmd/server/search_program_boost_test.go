@@ -0,0 +1,176 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestProgramBoost__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	for env, expected := range map[string]float64{"": defaultProgramBoost, "0.1": 0.1, "0": 0.0, "1.5": defaultProgramBoost, "-0.1": defaultProgramBoost, "x": defaultProgramBoost} {
+		if v := getProgramBoost(logger, env); v != expected {
+			t.Errorf("%q: got %v", env, v)
+		}
+	}
+}
+
+func TestProgramBoost__read(t *testing.T) {
+	u, _ := url.Parse("/search?program=sdgt,%20Iran&program=CUBA&program=")
+	programs := readPrograms(u)
+	if len(programs) != 3 || !programs["SDGT"] || !programs["IRAN"] || !programs["CUBA"] {
+		t.Errorf("got %v", programs)
+	}
+	u, _ = url.Parse("/search?name=x")
+	if programs := readPrograms(u); programs != nil {
+		t.Errorf("got %v", programs)
+	}
+
+	opts := searchOptions{programs: map[string]bool{"SDGT": true, "VENEZUELA": true}}
+	cases := []struct {
+		programs []string
+		expected float64
+	}{
+		{[]string{"SDGT"}, programBoost},
+		{[]string{"CUBA", " sdgt "}, programBoost},
+		{[]string{"VENEZUELA-EO13850"}, programBoost}, // suffixes fall back to their prefix
+		{[]string{"CUBA"}, 0.0},
+		{[]string{"SDGTX"}, 0.0},
+		{nil, 0.0},
+	}
+	for _, tc := range cases {
+		if v := opts.inProgramBoost(tc.programs); v != tc.expected {
+			t.Errorf("%v: got %v", tc.programs, v)
+		}
+	}
+	if v := (searchOptions{}).inProgramBoost([]string{"SDGT"}); v != 0.0 {
+		t.Errorf("no programs: got %v", v)
+	}
+}
+
+func TestProgramBoost__largest(t *testing.T) {
+	xs := newLargest(2)
+	xs.add(&item{value: "a", weight: 0.93})
+	xs.add(&item{value: "b", weight: 0.90, boost: 0.05})
+	xs.add(&item{value: "c", weight: 0.92})
+	if xs.items[0].value != "b" || xs.items[1].value != "a" {
+		t.Errorf("got %v %v", xs.items[0].value, xs.items[1].value)
+	}
+	if xs.items[0].weight != 0.90 {
+		t.Errorf("weight=%v", xs.items[0].weight)
+	}
+}
+
+func programBoostSearcher() *searcher {
+	return &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "AHMED, Mohamed", SDNType: "individual", Programs: []string{"IRAN"}},
+			{EntityID: "2", SDNName: "AHMEDI, Mohamed", SDNType: "individual", Programs: []string{"SDGT"}},
+			{EntityID: "3", SDNName: "AHMED, Mohamed", SDNType: "individual", Programs: []string{"SDGT", "IRAN"}},
+			{EntityID: "4", SDNName: "SMITH, John", SDNType: "individual", Programs: []string{"SDGT"}},
+		}, nil, noLogPipeliner),
+		SSIs: precomputeSSIs([]*csl.SSI{
+			{EntityID: "5", Name: "MOHAMED AHMED", Programs: []string{"UKRAINE-EO13662"}},
+			{EntityID: "6", Name: "MOHAMED AHMED", Programs: []string{"SDGT"}},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+}
+
+func TestProgramBoost__order(t *testing.T) {
+	s := programBoostSearcher()
+	ids := func(sdns []SDN) (out []string) {
+		for i := range sdns {
+			out = append(out, sdns[i].EntityID)
+		}
+		return out
+	}
+	opts := searchOptions{nameScoring: nameScoringFull}
+
+	// without a program equal matches keep the list's order
+	sdns := s.TopSDNs(3, "mohamed ahmed", opts)
+	if v := ids(sdns); len(v) != 3 || v[0] != "1" || v[1] != "3" || v[2] != "2" {
+		t.Fatalf("got %v", v)
+	}
+	exact, close := sdns[0].match, sdns[2].match
+	if exact != 1.0 || close >= exact || exact-close > programBoost {
+		t.Fatalf("matches %.3f and %.3f aren't within the boost", exact, close)
+	}
+
+	// in-program matches rank above equally (and slightly higher) scored matches outside of it
+	opts.programs = map[string]bool{"SDGT": true}
+	sdns = s.TopSDNs(3, "mohamed ahmed", opts)
+	if v := ids(sdns); len(v) != 3 || v[0] != "3" || v[1] != "2" || v[2] != "1" {
+		t.Errorf("got %v", v)
+	}
+	if sdns[0].match != exact || sdns[1].match != close || sdns[2].match != exact {
+		t.Errorf("matches changed: %.3f %.3f %.3f", sdns[0].match, sdns[1].match, sdns[2].match)
+	}
+
+	// in-program matches are kept by the limit
+	if v := ids(s.TopSDNs(2, "mohamed ahmed", opts)); len(v) != 2 || v[0] != "3" || v[1] != "2" {
+		t.Errorf("got %v", v)
+	}
+
+	// a boost doesn't lift weak matches above strong ones
+	if v := ids(s.TopSDNs(4, "mohamed ahmed", opts)); v[3] != "4" {
+		t.Errorf("got %v", v)
+	}
+
+	// the same for other lists with programs, whose suffixes fall back to their prefix
+	opts.programs = map[string]bool{"UKRAINE": true}
+	if ssis := s.TopSSIs(2, "mohamed ahmed", opts); ssis[0].SectoralSanction.EntityID != "5" {
+		t.Errorf("got %#v", ssis[0])
+	}
+	opts.programs = map[string]bool{"SDGT": true}
+	if ssis := s.TopSSIs(2, "mohamed ahmed", opts); ssis[0].SectoralSanction.EntityID != "6" {
+		t.Errorf("got %#v", ssis[0])
+	}
+}
+
+func TestProgramBoost__search(t *testing.T) {
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, programBoostSearcher())
+	search := func(query string) []string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: bogus status code: %d", query, w.Code)
+		}
+		var resp struct {
+			SDNs []struct {
+				EntityID string `json:"entityID"`
+			} `json:"SDNs"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for i := range resp.SDNs {
+			out = append(out, resp.SDNs[i].EntityID)
+		}
+		return out
+	}
+
+	if v := search("name=mohamed+ahmed&nameScoring=full&limit=3&program=iran"); len(v) != 3 || v[0] != "1" || v[1] != "3" {
+		t.Errorf("program: got %v", v)
+	}
+	// the filter is still separate, it drops SDNs outside of the program
+	if v := search("name=mohamed+ahmed&nameScoring=full&limit=3&ofacProgram=iran"); len(v) != 2 || v[0] != "1" || v[1] != "3" {
+		t.Errorf("ofacProgram: got %v", v)
+	}
+}
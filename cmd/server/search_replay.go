@@ -0,0 +1,224 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+)
+
+// replayMatchTolerance is how far a result's match can move before it's reported as rescored,
+// which allows prior results stored with rounded scores
+const replayMatchTolerance = 0.001
+
+var errNoReplaySearch = errors.New("no search to replay")
+
+type replayRequest struct {
+	// Search holds the parameters of POST /search
+	Search map[string]interface{} `json:"search"`
+
+	// Fingerprint, when set, must equal the fingerprint of Search
+	Fingerprint string `json:"fingerprint"`
+
+	// Prior is the response of the search when it was first performed
+	Prior json.RawMessage `json:"prior"`
+}
+
+// replayedResult identifies a result by the list it's in (e.g. SDNs or altNames) and its ID
+type replayedResult struct {
+	List  string  `json:"list"`
+	ID    string  `json:"id"`
+	Match float64 `json:"match"`
+}
+
+type rescoredResult struct {
+	replayedResult
+	PriorMatch float64 `json:"priorMatch"`
+}
+
+type replayResponse struct {
+	Fingerprint string `json:"fingerprint"`
+	DataVersion string `json:"dataVersion"`
+
+	// Result is the search performed against the current data
+	Result json.RawMessage `json:"result"`
+
+	// Changed is true when any result was added, removed or rescored since the prior search
+	Changed  bool             `json:"changed"`
+	Added    []replayedResult `json:"added"`
+	Removed  []replayedResult `json:"removed"`
+	Rescored []rescoredResult `json:"rescored"`
+}
+
+// searchFingerprint identifies a search by its parameters, regardless of their order
+func searchFingerprint(params url.Values) string {
+	sum := sha256.Sum256([]byte(params.Encode())) // Encode sorts by key
+	return hex.EncodeToString(sum[:])
+}
+
+// replayResultIDs are the fields identifying a result in each list of a search response
+var replayResultIDs = []struct {
+	list   string
+	fields []string
+}{
+	{"SDNs", []string{"entityID"}},
+	{"altNames", []string{"entityID", "alternateID"}},
+	{"addresses", []string{"entityID", "addressID"}},
+	{"sectoralSanctions", []string{"entityID"}},
+	{"deniedPersons", []string{"name"}},
+	{"bisEntities", []string{"name"}},
+	{"nonproliferationSanctions", []string{"entityID"}},
+	{"canadianSanctions", []string{"item"}},
+	{"australianSanctions", []string{"reference"}},
+	{"unitedNationsSanctions", []string{"referenceNumber"}},
+	{"customEntries", []string{"id"}},
+}
+
+// replayedResults reads the results of a search response. Entity groups and confidence bands
+// repeat results of the lists, so they aren't read.
+func replayedResults(body []byte) ([]replayedResult, error) {
+	var lists map[string]json.RawMessage
+	if err := json.Unmarshal(body, &lists); err != nil {
+		return nil, err
+	}
+	var out []replayedResult
+	for _, ids := range replayResultIDs {
+		list, fields := ids.list, ids.fields
+		raw, exists := lists[list]
+		if !exists || string(raw) == "null" {
+			continue
+		}
+		var results []map[string]interface{}
+		if err := json.Unmarshal(raw, &results); err != nil {
+			return nil, fmt.Errorf("%s: %v", list, err)
+		}
+		for i := range results {
+			var id bytes.Buffer
+			for j, field := range fields {
+				if j > 0 {
+					id.WriteString("/")
+				}
+				fmt.Fprint(&id, results[i][field])
+			}
+			match, _ := results[i]["match"].(float64)
+			out = append(out, replayedResult{List: list, ID: id.String(), Match: match})
+		}
+	}
+	return out, nil
+}
+
+// compare fills in the results which differ between prior and the current response
+func (resp *replayResponse) compare(prior, current []replayedResult) {
+	key := func(r replayedResult) string { return r.List + "|" + r.ID }
+
+	priors := make(map[string]replayedResult)
+	for i := range prior {
+		priors[key(prior[i])] = prior[i]
+	}
+	for i := range current {
+		k := key(current[i])
+		before, exists := priors[k]
+		if !exists {
+			resp.Added = append(resp.Added, current[i])
+			continue
+		}
+		delete(priors, k)
+		if math.Abs(before.Match-current[i].Match) > replayMatchTolerance {
+			resp.Rescored = append(resp.Rescored, rescoredResult{current[i], before.Match})
+		}
+	}
+	for i := range prior {
+		if _, exists := priors[key(prior[i])]; exists {
+			resp.Removed = append(resp.Removed, prior[i])
+		}
+	}
+	resp.Changed = len(resp.Added) > 0 || len(resp.Removed) > 0 || len(resp.Rescored) > 0
+}
+
+// searchReplay re-runs a prior search against the current data and reports how its results
+// differ from the prior response, for audits of past decisions.
+func searchReplay(logger log.Logger, searcher *searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		var req replayRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			moovhttp.Problem(w, fmt.Errorf("invalid replay: %v", err))
+			return
+		}
+		if len(req.Search) == 0 {
+			moovhttp.Problem(w, errNoReplaySearch)
+			return
+		}
+		values, err := searchValues(req.Search)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		fingerprint := searchFingerprint(values)
+		if req.Fingerprint != "" && req.Fingerprint != fingerprint {
+			moovhttp.Problem(w, fmt.Errorf("fingerprint %s doesn't match search %s", req.Fingerprint, fingerprint))
+			return
+		}
+		var prior []replayedResult
+		if len(req.Prior) > 0 {
+			if prior, err = replayedResults(req.Prior); err != nil {
+				moovhttp.Problem(w, fmt.Errorf("invalid prior results: %v", err))
+				return
+			}
+		}
+
+		result := runSearch(logger, searcher, r, values)
+		if result.code != http.StatusOK {
+			// the search failed, so respond like POST /search
+			for k, v := range result.header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(result.code)
+			w.Write(result.body.Bytes())
+			return
+		}
+		current, err := replayedResults(result.body.Bytes())
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+
+		resp := replayResponse{
+			Fingerprint: fingerprint,
+			DataVersion: result.header.Get(dataVersionHeader),
+			Result:      json.RawMessage(bytes.TrimSpace(result.body.Bytes())),
+			Added:       []replayedResult{},
+			Removed:     []replayedResult{},
+			Rescored:    []rescoredResult{},
+		}
+		resp.compare(prior, current)
+
+		if requestID := moovhttp.GetRequestID(r); requestID != "" {
+			logger.Log("search", fmt.Sprintf("replayed search %s, changed=%v", fingerprint, resp.Changed), "requestID", requestID)
+		}
+		if resp.DataVersion != "" {
+			w.Header().Set(dataVersionHeader, resp.DataVersion)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSearch__replay(t *testing.T) {
+	maduro := &ofac.SDN{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"}
+	s := &searcher{
+		SDNs:            precomputeSDNs([]*ofac.SDN{maduro}, nil, noLogPipeliner),
+		lastRefreshedAt: time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC),
+		pipe:            noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	replay := func(body string) (*httptest.ResponseRecorder, replayResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/search/replay", strings.NewReader(body)))
+		w.Flush()
+
+		var resp replayResponse
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w, resp
+	}
+	search := `{"name": "nicolas maduro", "sources": ["SDN"], "limit": 2, "minMatch": 0.8}`
+
+	// without prior results everything is added
+	w, first := replay(fmt.Sprintf(`{"search": %s}`, search))
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	if !first.Changed || len(first.Added) != 1 || first.Added[0].ID != "22790" || first.Added[0].List != "SDNs" {
+		t.Errorf("unexpected replay: %#v", first)
+	}
+	if first.DataVersion != "2020-06-01T00:00:00Z" || len(first.Fingerprint) != 64 {
+		t.Errorf("dataVersion=%q fingerprint=%q", first.DataVersion, first.Fingerprint)
+	}
+
+	// the same data gives the same outcome
+	_, again := replay(fmt.Sprintf(`{"search": %s, "fingerprint": %q, "prior": %s}`, search, first.Fingerprint, first.Result))
+	if again.Changed || len(again.Added) != 0 || len(again.Removed) != 0 || len(again.Rescored) != 0 {
+		t.Errorf("unexpected changes: %#v", again)
+	}
+
+	// a list update adds a closer match, which rescores the prior result or drops it below minMatch
+	s.Lock()
+	s.SDNs = precomputeSDNs([]*ofac.SDN{
+		{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas Ernesto", SDNType: "individual"},
+		{EntityID: "36475", SDNName: "MADURO, Nicolas", SDNType: "individual"},
+	}, nil, noLogPipeliner)
+	s.lastRefreshedAt = time.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC)
+	s.Unlock()
+
+	_, updated := replay(fmt.Sprintf(`{"search": %s, "prior": %s}`, search, first.Result))
+	if !updated.Changed || updated.DataVersion != "2020-07-01T00:00:00Z" {
+		t.Fatalf("expected changes: %#v", updated)
+	}
+	if len(updated.Added) != 1 || updated.Added[0].ID != "36475" {
+		t.Errorf("added: %#v", updated.Added)
+	}
+	if len(updated.Removed)+len(updated.Rescored) != 1 {
+		t.Errorf("removed=%#v rescored=%#v", updated.Removed, updated.Rescored)
+	}
+	for _, r := range updated.Rescored {
+		if r.ID != "22790" || r.PriorMatch <= r.Match {
+			t.Errorf("rescored: %#v", r)
+		}
+	}
+
+	// invalid replays
+	for _, body := range []string{
+		`not json`,
+		`{"prior": {}}`,
+		fmt.Sprintf(`{"search": %s, "fingerprint": "abc"}`, search),
+		fmt.Sprintf(`{"search": %s, "prior": {"SDNs": "other"}}`, search),
+	} {
+		if w, _ := replay(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: bogus status code: %d", body, w.Code)
+		}
+	}
+	// failed searches respond like POST /search
+	if w, _ := replay(`{"search": {"name": "nicolas maduro", "sources": "other"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
+
+func TestSearch__replayedResults(t *testing.T) {
+	results, err := replayedResults([]byte(`{
+		"SDNs": [{"entityID": "22790", "match": 0.9}],
+		"altNames": [{"entityID": "22790", "alternateID": "1", "match": 0.8}],
+		"addresses": null,
+		"bisEntities": [{"name": "Mohammad Jan Khan Mangal", "match": 0.7}],
+		"entities": [{"name": "MADURO", "entries": []}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []replayedResult{
+		{"SDNs", "22790", 0.9},
+		{"altNames", "22790/1", 0.8},
+		{"bisEntities", "Mohammad Jan Khan Mangal", 0.7},
+	}
+	if fmt.Sprintf("%v", results) != fmt.Sprintf("%v", expected) {
+		t.Errorf("got %v", results)
+	}
+
+	var resp replayResponse
+	resp.compare(expected, []replayedResult{{"SDNs", "22790", 0.9004}, {"altNames", "22790/1", 0.75}})
+	if !resp.Changed || len(resp.Added) != 0 || len(resp.Rescored) != 1 || len(resp.Removed) != 1 {
+		t.Errorf("unexpected comparison: %#v", resp)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	// requireName is satisfied by name, altName or q
+	requireName = "name"
+
+	// requireAddress is satisfied by any address field (address, city, state, providence, zip or country)
+	requireAddress = "address"
+)
+
+var (
+	// searchRequiredFields are what every search must include to be performed, by default a
+	// search can have either. main sets it from SEARCH_REQUIRED_FIELDS.
+	searchRequiredFields []string
+)
+
+// getSearchRequiredFields reads a comma separated list of "name" and "address". Nil is returned
+// when env is empty or invalid.
+//
+// env is the value from an environmental variable
+func getSearchRequiredFields(logger log.Logger, env string) []string {
+	if strings.TrimSpace(env) == "" {
+		return nil
+	}
+	var out []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(env, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		switch field {
+		case "":
+			continue
+		case requireName, requireAddress:
+			if !seen[field] {
+				seen[field] = true
+				out = append(out, field)
+			}
+		default:
+			logger.Log("main", fmt.Sprintf("invalid SEARCH_REQUIRED_FIELDS=%q, searches can have either a name or address", env))
+			return nil
+		}
+	}
+	if len(out) > 0 {
+		logger.Log("main", fmt.Sprintf("Searches must include: %s", strings.Join(out, " and ")))
+	}
+	return out
+}
+
+// checkRequiredFields returns an error when the search parameters of u don't include every
+// field of searchRequiredFields
+func checkRequiredFields(u *url.URL) error {
+	for _, field := range searchRequiredFields {
+		switch field {
+		case requireName:
+			q := u.Query()
+			if strings.TrimSpace(q.Get("name")) == "" && strings.TrimSpace(q.Get("altName")) == "" && strings.TrimSpace(q.Get("q")) == "" && readNameComponents(u) == nil {
+				return fmt.Errorf("searches must include a name (name, givenName, middleName, familyName, altName or q)")
+			}
+		case requireAddress:
+			if readAddressSearchRequest(u).empty() {
+				return fmt.Errorf("searches must include an address (address, city, state, providence, zip or country)")
+			}
+		}
+	}
+	return nil
+}
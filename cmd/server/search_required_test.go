@@ -0,0 +1,86 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSearchRequiredFields__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	cases := map[string]string{
+		"":                  "",
+		"name":              "name",
+		" Address ":         "address",
+		"name,address":      "name,address",
+		"address,name,name": "address,name",
+		"name,dob":          "",
+	}
+	for env, expected := range cases {
+		if fields := strings.Join(getSearchRequiredFields(logger, env), ","); fields != expected {
+			t.Errorf("%q: got %q expected %q", env, fields, expected)
+		}
+	}
+}
+
+func TestSearchRequiredFields__search(t *testing.T) {
+	defer func() { searchRequiredFields = nil }()
+
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+	search := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+		return w
+	}
+
+	nameOnly, addressOnly, both := "name=nicolas+maduro", "address=ibex+house", "name=nicolas+maduro&country=venezuela"
+	cases := []struct {
+		required []string
+		allowed  []string
+		rejected []string
+	}{
+		{nil, []string{nameOnly, addressOnly, both, "q=maduro", "id=5892464"}, nil},
+		{[]string{requireName}, []string{nameOnly, both, "altName=maduro", "q=maduro"}, []string{addressOnly, "id=5892464", "name=+"}},
+		{[]string{requireAddress}, []string{addressOnly, both, "zip=1000"}, []string{nameOnly, "q=maduro"}},
+		{[]string{requireName, requireAddress}, []string{both, "name=nicolas+maduro&address=caracas"}, []string{nameOnly, addressOnly, "q=maduro", "city=caracas"}},
+	}
+	for _, tc := range cases {
+		searchRequiredFields = tc.required
+		for _, query := range tc.allowed {
+			if w := search(query); w.Code != http.StatusOK {
+				t.Errorf("%v %s: bogus status code: %d: %s", tc.required, query, w.Code, w.Body.String())
+			}
+		}
+		for _, query := range tc.rejected {
+			w := search(query)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%v %s: bogus status code: %d", tc.required, query, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), "searches must include") {
+				t.Errorf("%v %s: %s", tc.required, query, w.Body.String())
+			}
+		}
+	}
+
+	// each search of a batch is checked
+	searchRequiredFields = []string{requireName, requireAddress}
+	w := httptest.NewRecorder()
+	body := `{"searches": [{"name": "nicolas maduro"}, {"name": "nicolas maduro", "country": "venezuela"}]}`
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/search/batch", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `{"status":400,"error":"searches must include an address`) || !strings.Contains(w.Body.String(), `{"status":200,`) {
+		t.Errorf("unexpected batch: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+// searchParamValues are the values accepted by each enumerated search parameter. It's what
+// readEnumParam (and readSources) validate against and what GET /search/schema and the served
+// OpenAPI document list, so add values here when a handler accepts them.
+var searchParamValues = map[string][]string{
+	"matchMode":   {"fuzzy", matchModeWildcard, matchModeInitials},
+	"nameScoring": {nameScoringTokens, nameScoringFull},
+	"sort":        {sortScore, sortRecent},
+	"group":       {groupBands},
+	"combine":     {combineMax, combineAvg, combineWeighted},
+	"sources":     knownSources,
+}
+
+// listParams are parameters accepting a comma separated list of their values
+var listParams = map[string]bool{
+	"sources": true,
+	"program": true,
+}
+
+// dataValuesParams are filters whose values come from the indexed data, they're listed by
+// GET /ui/values/{key} instead
+var dataValuesParams = map[string]string{
+	"sdnType":     "/ui/values/sdnType",
+	"ofacProgram": "/ui/values/ofacProgram",
+	"program":     "/ui/values/ofacProgram",
+}
+
+// readEnumParam reads the case-insensitive value of param, which must be one of
+// searchParamValues. It's empty when param isn't in u.
+func readEnumParam(u *url.URL, param string) (string, error) {
+	v := strings.ToLower(strings.TrimSpace(u.Query().Get(param)))
+	if v == "" {
+		return "", nil
+	}
+	for _, value := range searchParamValues[param] {
+		if v == value {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("unknown %s %q", param, v)
+}
+
+type searchSchema struct {
+	Parameters []searchSchemaParam `json:"parameters"`
+}
+
+type searchSchemaParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+
+	// Enum lists the accepted values, List is true when several can be comma separated
+	Enum []string `json:"enum,omitempty"`
+	List bool     `json:"list,omitempty"`
+
+	// ValuesPath is where the values found in the data are listed
+	ValuesPath string `json:"valuesPath,omitempty"`
+}
+
+// buildSearchSchema describes every parameter of searchParams
+func buildSearchSchema() searchSchema {
+	out := searchSchema{Parameters: make([]searchSchemaParam, 0, len(searchParams))}
+	for _, p := range searchParams {
+		out.Parameters = append(out.Parameters, searchSchemaParam{
+			Name:        p.Name,
+			Type:        p.Type,
+			Description: p.Description,
+			Enum:        searchParamValues[p.Name],
+			List:        listParams[p.Name],
+			ValuesPath:  dataValuesParams[p.Name],
+		})
+	}
+	return out
+}
+
+// searchSchemaHandler serves the parameters of GET /search and their accepted values, for
+// clients and UIs to build searches from
+func searchSchemaHandler(logger log.Logger) http.HandlerFunc {
+	schema := buildSearchSchema()
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(schema)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+// validateSearchParam runs the validator of a search parameter
+func validateSearchParam(param, value string) error {
+	u := &url.URL{RawQuery: url.Values{param: []string{value}}.Encode()}
+	if listParams[param] {
+		_, err := readSources(u)
+		return err
+	}
+	_, err := readSearchOptions(u)
+	return err
+}
+
+func TestSearchSchema__validator(t *testing.T) {
+	schema := buildSearchSchema()
+	if len(schema.Parameters) != len(searchParams) {
+		t.Fatalf("%d parameters, expected %d", len(schema.Parameters), len(searchParams))
+	}
+
+	enumerated := 0
+	for _, p := range schema.Parameters {
+		if len(p.Enum) == 0 {
+			continue
+		}
+		enumerated++
+
+		// every listed value is accepted, in any case
+		for _, value := range p.Enum {
+			for _, v := range []string{value, strings.ToUpper(value), strings.ToLower(value)} {
+				if err := validateSearchParam(p.Name, v); err != nil {
+					t.Errorf("%s=%s was rejected: %v", p.Name, v, err)
+				}
+			}
+		}
+		if p.List {
+			if err := validateSearchParam(p.Name, strings.Join(p.Enum, ",")); err != nil {
+				t.Errorf("%s=%s was rejected: %v", p.Name, strings.Join(p.Enum, ","), err)
+			}
+		}
+
+		// and others aren't
+		if err := validateSearchParam(p.Name, "other"); err == nil || !strings.Contains(err.Error(), "unknown") {
+			t.Errorf("%s=other wasn't rejected: %v", p.Name, err)
+		}
+	}
+	if enumerated != len(searchParamValues) {
+		t.Errorf("%d of %d enumerated parameters are search parameters", enumerated, len(searchParamValues))
+	}
+}
+
+func TestSearchSchema__handler(t *testing.T) {
+	router := mux.NewRouter()
+	addOpenAPIRoute(log.NewNopLogger(), router)
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search/schema", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d", w.Code)
+	}
+
+	var schema searchSchema
+	if err := json.NewDecoder(w.Body).Decode(&schema); err != nil {
+		t.Fatal(err)
+	}
+	params := make(map[string]searchSchemaParam)
+	for _, p := range schema.Parameters {
+		params[p.Name] = p
+	}
+	if p := params["matchMode"]; p.Type != "string" || !reflect.DeepEqual(p.Enum, []string{"fuzzy", "wildcard", "initials"}) || p.List {
+		t.Errorf("matchMode: %#v", p)
+	}
+	if p := params["sources"]; !reflect.DeepEqual(p.Enum, knownSources) || !p.List {
+		t.Errorf("sources: %#v", p)
+	}
+	if p := params["sdnType"]; len(p.Enum) != 0 || p.ValuesPath != "/ui/values/sdnType" {
+		t.Errorf("sdnType: %#v", p)
+	}
+	if p := params["limit"]; p.Type != "integer" || len(p.Enum) != 0 {
+		t.Errorf("limit: %#v", p)
+	}
+
+	// the OpenAPI document lists the same values
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	w.Flush()
+	var doc struct {
+		Paths map[string]struct {
+			Get struct {
+				Parameters []struct {
+					Name   string `json:"name"`
+					Schema struct {
+						Enum []string `json:"enum"`
+					} `json:"schema"`
+				} `json:"parameters"`
+			} `json:"get"`
+		} `json:"paths"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := doc.Paths["/search/schema"]; !exists {
+		t.Error("/search/schema isn't documented")
+	}
+	for _, p := range doc.Paths["/search"].Get.Parameters {
+		if expected := params[p.Name]; !expected.List && !reflect.DeepEqual(p.Schema.Enum, expected.Enum) {
+			t.Errorf("%s: enum=%v, expected %v", p.Name, p.Schema.Enum, expected.Enum)
+		}
+	}
+}
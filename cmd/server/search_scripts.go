@@ -0,0 +1,110 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// nameScripts are the writing systems whose name forms are indexed from SDN remarks, names in
+// Latin script are matched as they always were
+var nameScripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Arabic", unicode.Arabic},
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+	{"Hebrew", unicode.Hebrew},
+	{"Han", unicode.Han},
+	{"Hangul", unicode.Hangul},
+	{"Hiragana", unicode.Hiragana},
+	{"Katakana", unicode.Katakana},
+	{"Thai", unicode.Thai},
+	{"Devanagari", unicode.Devanagari},
+	{"Georgian", unicode.Georgian},
+	{"Armenian", unicode.Armenian},
+}
+
+// scriptName is the form of a name in a non-Latin script, like "صدام حسين" (Arabic)
+type scriptName struct {
+	script string
+	name   string // precomputed
+}
+
+// nameScript returns which of nameScripts every letter of s is written in. It's empty for Latin
+// names, strings without letters and strings mixing scripts.
+func nameScript(s string) string {
+	var script string
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		found := ""
+		for _, ns := range nameScripts {
+			if unicode.Is(ns.table, r) {
+				found = ns.name
+				break
+			}
+		}
+		if found == "" || (script != "" && script != found) {
+			return ""
+		}
+		script = found
+	}
+	return script
+}
+
+// remarkScriptNames finds runs of words in a single non-Latin script within SDN remarks
+// (e.g. "Arabic name: صدام حسين;") which are native forms of the SDN's name
+func remarkScriptNames(remarks string) []scriptName {
+	var out []scriptName
+	var run []string
+	var runScript string
+	flush := func() {
+		if len(run) > 0 {
+			if name := precompute(strings.Join(run, " ")); name != "" {
+				out = append(out, scriptName{script: runScript, name: name})
+			}
+		}
+		run, runScript = nil, ""
+	}
+	for _, field := range strings.Fields(remarks) {
+		// punctuation separates the name from what follows, like "حسين;" or "(حسين)"
+		word := strings.TrimFunc(field, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsMark(r) })
+		script := nameScript(word)
+		if script == "" || (runScript != "" && script != runScript) {
+			flush()
+		}
+		if script != "" {
+			run, runScript = append(run, word), script
+		}
+		if word != field && strings.ContainsAny(field, ";,.:()[]") {
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+// scriptIndexedName returns the form of the SDN's name which a query in script is compared
+// against. That's the best scoring native form in the same script, or the Latin name when the
+// SDN has none or the query is in Latin script.
+func (sdn *SDN) scriptIndexedName(script, query string, opts searchOptions) string {
+	if script == "" {
+		return sdn.name
+	}
+	indexed, best := sdn.name, -1.0
+	for _, sn := range sdn.scriptNames {
+		if sn.script != script {
+			continue
+		}
+		if score := opts.score(sn.name, query); score > best {
+			indexed, best = sn.name, score
+		}
+	}
+	return indexed
+}
@@ -0,0 +1,74 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+func TestNameScript(t *testing.T) {
+	cases := map[string]string{
+		"صدام حسين":         "Arabic",
+		"Путин Владимир":    "Cyrillic",
+		"김정은":               "Hangul",
+		"saddam hussein":    "",
+		"صدام hussein":      "", // mixed
+		"12345":             "",
+		"":                  "",
+		"(صدام حسين) 1937.": "Arabic",
+	}
+	for input, expected := range cases {
+		if ans := nameScript(input); ans != expected {
+			t.Errorf("%q: expected %q got %q", input, expected, ans)
+		}
+	}
+}
+
+func TestRemarkScriptNames(t *testing.T) {
+	names := remarkScriptNames("DOB 28 Apr 1937; Arabic name: صدّام حسين; Russian name: Саддам Хусейн; Gender Male.")
+	if len(names) != 2 {
+		t.Fatalf("%#v", names)
+	}
+	if names[0].script != "Arabic" || names[0].name != "صدام حسين" { // the shadda is removed
+		t.Errorf("%#v", names[0])
+	}
+	if names[1].script != "Cyrillic" || names[1].name != "саддам хусеин" { // folded like queries are
+		t.Errorf("%#v", names[1])
+	}
+	if names := remarkScriptNames("DOB 1951; POB Giza, Egypt"); len(names) != 0 {
+		t.Errorf("%#v", names)
+	}
+}
+
+func TestSearcher__scriptNames(t *testing.T) {
+	sdns := []*ofac.SDN{
+		{EntityID: "7488", SDNName: "HUSSEIN, Saddam", SDNType: "individual", Remarks: "DOB 28 Apr 1937; Arabic name: صدّام حسين;"},
+		{EntityID: "7489", SDNName: "HUSSEIN, Qusay", SDNType: "individual"},
+	}
+	s := &searcher{SDNs: precomputeSDNs(sdns, nil, noLogPipeliner)}
+	opts := searchOptions{nameScoring: nameScoringTokens}
+
+	// an Arabic query matches the Arabic form of the name
+	out := s.TopSDNs(2, "صدام حسين", opts)
+	if len(out) != 2 || out[0].EntityID != "7488" {
+		t.Fatalf("%#v", out)
+	}
+	eql(t, "arabic", out[0].match, 1.0)
+	if out[1].match >= 0.5 {
+		t.Errorf("SDN without an Arabic form scored %v", out[1].match)
+	}
+
+	// Latin queries score the same as without native forms
+	plain := &searcher{SDNs: precomputeSDNs([]*ofac.SDN{{EntityID: "7488", SDNName: "HUSSEIN, Saddam", SDNType: "individual"}}, nil, noLogPipeliner)}
+	for _, query := range []string{"saddam hussein", "sadam husein"} {
+		latin, expected := s.TopSDNs(1, query, opts), plain.TopSDNs(1, query, opts)
+		if len(latin) != 1 || latin[0].EntityID != "7488" {
+			t.Fatalf("%#v", latin)
+		}
+		eql(t, query, latin[0].match, expected[0].match)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/moov-io/watchman/pkg/ofac"
+)
+
+// sdnAddress is an address of an SDN result, included with ?includeAddresses=true
+type sdnAddress struct {
+	*ofac.Address
+
+	// Matched is set on the SDN's best scoring address result when the search included an address
+	Matched bool `json:"matched,omitempty"`
+}
+
+// includeSDNAddresses adds every address of each SDN result. The best scoring address result
+// of an SDN (when the search queried addresses) is marked as matched, so it's clear which
+// address (if any) was involved in the match.
+func (resp *searchResponse) includeSDNAddresses() {
+	best := make(map[string]*Address)
+	for i := range resp.Addresses {
+		addr := &resp.Addresses[i]
+		if addr.Address == nil || addr.match <= 0 {
+			continue
+		}
+		if current, exists := best[addr.Address.EntityID]; !exists || addr.match > current.match {
+			best[addr.Address.EntityID] = addr
+		}
+	}
+	for i := range resp.SDNs {
+		sdn := &resp.SDNs[i]
+		sdn.showAddresses = true
+		if matched, exists := best[sdn.EntityID]; exists {
+			sdn.matchedAddressID = matched.Address.AddressID
+		}
+	}
+}
+
+// sdnAddresses returns the addresses of s to include in its result
+func (s SDN) sdnAddresses() []sdnAddress {
+	if !s.showAddresses {
+		return nil
+	}
+	out := make([]sdnAddress, 0, len(s.addresses))
+	for i := range s.addresses {
+		out = append(out, sdnAddress{
+			Address: s.addresses[i],
+			Matched: s.matchedAddressID != "" && s.addresses[i].AddressID == s.matchedAddressID,
+		})
+	}
+	return out
+}
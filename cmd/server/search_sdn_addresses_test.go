@@ -0,0 +1,87 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSDNAddresses__search(t *testing.T) {
+	addrs := []*ofac.Address{
+		{EntityID: "22790", AddressID: "1", Address: "Palacio de Miraflores", CityStateProvincePostalCode: "Caracas", Country: "Venezuela"},
+		{EntityID: "22790", AddressID: "2", Address: "Calle 50", CityStateProvincePostalCode: "Panama City", Country: "Panama"},
+		{EntityID: "173", AddressID: "129", Address: "Ibex House, The Minories", CityStateProvincePostalCode: "London EC3N 1DY", Country: "United Kingdom"},
+	}
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"},
+			{EntityID: "173", SDNName: "ANGLO-CARIBBEAN CO., LTD."},
+		}, addrs, noLogPipeliner),
+		Addresses: precomputeAddresses(addrs),
+		pipe:      noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	type result struct {
+		EntityID  string `json:"entityID"`
+		Addresses []struct {
+			AddressID string `json:"addressID"`
+			Matched   bool   `json:"matched"`
+		} `json:"addresses"`
+	}
+	search := func(t *testing.T, query string) []result {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			SDNs []result `json:"SDNs"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.SDNs
+	}
+
+	// name searches include every address without any marked
+	sdns := search(t, "name=nicolas+maduro&limit=1&includeAddresses=true")
+	if len(sdns) != 1 || sdns[0].EntityID != "22790" || len(sdns[0].Addresses) != 2 {
+		t.Fatalf("unexpected results: %#v", sdns)
+	}
+	for _, addr := range sdns[0].Addresses {
+		if addr.Matched {
+			t.Errorf("address %s matched", addr.AddressID)
+		}
+	}
+
+	// the address which matched a combined search is marked
+	sdns = search(t, "name=nicolas+maduro&address=calle+50&city=panama+city&country=panama&limit=1&includeAddresses=true")
+	if len(sdns) != 1 || sdns[0].EntityID != "22790" || len(sdns[0].Addresses) != 2 {
+		t.Fatalf("unexpected results: %#v", sdns)
+	}
+	for _, addr := range sdns[0].Addresses {
+		if addr.Matched != (addr.AddressID == "2") {
+			t.Errorf("address %s matched=%v", addr.AddressID, addr.Matched)
+		}
+	}
+
+	// addresses are only included when asked for
+	sdns = search(t, "name=nicolas+maduro&address=calle+50&limit=1")
+	if len(sdns) != 1 || len(sdns[0].Addresses) != 0 {
+		t.Errorf("unexpected results: %#v", sdns)
+	}
+}
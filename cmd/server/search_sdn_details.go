@@ -0,0 +1,41 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+// sdnDetails are the fields parsed from an SDN's remarks, included with ?includeDetails=true so
+// clients don't need to parse remarks or call GET /ofac/sdn/{sdnId} for them. Titles are always
+// on results.
+type sdnDetails struct {
+	// DatesOfBirth are formatted as YYYY-MM-DD, or YYYY when only the year is known
+	DatesOfBirth  []string `json:"datesOfBirth,omitempty"`
+	Nationalities []string `json:"nationalities,omitempty"`
+
+	// ID is the identification number parsed from remarks, which GET /search?id= searches
+	ID string `json:"id,omitempty"`
+}
+
+// includeSDNDetails adds the parsed remarks of each SDN result
+func (resp *searchResponse) includeSDNDetails() {
+	for i := range resp.SDNs {
+		resp.SDNs[i].showDetails = true
+	}
+}
+
+// sdnDetails returns the parsed remarks of s to include in its result, nil when they weren't
+// asked for or nothing was parsed
+func (s SDN) sdnDetails() *sdnDetails {
+	if !s.showDetails || s.SDN == nil {
+		return nil
+	}
+	out := &sdnDetails{
+		DatesOfBirth:  remarkDOBs(s.SDN.Remarks),
+		Nationalities: remarkNationalities(s.SDN.Remarks),
+		ID:            s.id,
+	}
+	if len(out.DatesOfBirth) == 0 && len(out.Nationalities) == 0 && out.ID == "" {
+		return nil
+	}
+	return out
+}
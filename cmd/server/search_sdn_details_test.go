@@ -0,0 +1,90 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSDNDetails__search(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{
+				EntityID: "22790",
+				SDNName:  "MADURO MOROS, Nicolas",
+				SDNType:  "individual",
+				Remarks:  "DOB 23 Nov 1962; POB Caracas, Venezuela; nationality Venezuela; Gender Male; Cedula No. 5892464 (Venezuela); President of the Bolivarian Republic of Venezuela.",
+			},
+			{EntityID: "173", SDNName: "ANGLO-CARIBBEAN CO., LTD.", Remarks: "Website www.anglo-caribbean.example."},
+		}, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	type result struct {
+		EntityID string      `json:"entityID"`
+		Details  *sdnDetails `json:"details"`
+	}
+	search := func(t *testing.T, path string) []result {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			SDNs []result `json:"SDNs"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.SDNs
+	}
+
+	// details are left out by default
+	sdns := search(t, "/search?name=nicolas+maduro&limit=1")
+	if len(sdns) != 1 || sdns[0].EntityID != "22790" || sdns[0].Details != nil {
+		t.Fatalf("unexpected results: %#v", sdns)
+	}
+
+	for _, path := range []string{"/search?name=nicolas+maduro&limit=1&includeDetails=true", "/search?id=5892464&includeDetails=true"} {
+		sdns = search(t, path)
+		if len(sdns) != 1 || sdns[0].EntityID != "22790" || sdns[0].Details == nil {
+			t.Fatalf("%s: unexpected results: %#v", path, sdns)
+		}
+		details := sdns[0].Details
+		if len(details.DatesOfBirth) != 1 || details.DatesOfBirth[0] != "1962-11-23" {
+			t.Errorf("%s: datesOfBirth=%v", path, details.DatesOfBirth)
+		}
+		if len(details.Nationalities) != 1 || details.Nationalities[0] != "Venezuela" {
+			t.Errorf("%s: nationalities=%v", path, details.Nationalities)
+		}
+		if details.ID != "5892464" {
+			t.Errorf("%s: id=%q", path, details.ID)
+		}
+	}
+
+	// SDNs without any parsed fields have no details
+	sdns = search(t, "/search?name=anglo+caribbean&limit=1&includeDetails=true")
+	if len(sdns) != 1 || sdns[0].EntityID != "173" || sdns[0].Details != nil {
+		t.Fatalf("unexpected results: %#v", sdns)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro&includeDetails=maybe", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// sortScore orders each list by match, sortRecent orders results of the same confidence
+	// band by their listing date, the most recently listed first.
+	sortScore  = "score"
+	sortRecent = "recent"
+)
+
+// listingDateRegex finds a date in free text, like "Instrument 2022 (listed 25 February 2022)"
+var listingDateRegex = regexp.MustCompile(`[0-9]{4}-[0-9]{2}-[0-9]{2}|[0-9]{1,2}/[0-9]{1,2}/[0-9]{4}|[0-9]{1,2} [A-Za-z]+ [0-9]{4}`)
+
+// parseListingDate reads when a record was listed. Slashed dates are read as MM/DD/YYYY when
+// monthFirst is set (US lists) and DD/MM/YYYY otherwise. Unknown dates are the zero time.
+func parseListingDate(raw string, monthFirst bool) time.Time {
+	slashed := "02/01/2006"
+	if monthFirst {
+		slashed = "01/02/2006"
+	}
+	for _, text := range listingDateRegex.FindAllString(raw, -1) {
+		for _, layout := range []string{"2006-01-02", slashed, "2 Jan 2006", "2 January 2006"} {
+			if t, err := time.Parse(layout, padSlashedDate(text)); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// padSlashedDate pads the day and month of a date like 1/2/2006 for time.Parse
+func padSlashedDate(text string) string {
+	parts := strings.Split(text, "/")
+	if len(parts) != 3 {
+		return text
+	}
+	for i := 0; i < 2; i++ {
+		if len(parts[i]) == 1 {
+			parts[i] = "0" + parts[i]
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// listed returns when the result's record was listed, or the zero time when it can't be parsed.
+// OFAC and custom lists don't publish listing dates.
+func (dp DP) listed() time.Time {
+	if dp.DeniedPerson == nil {
+		return time.Time{}
+	}
+	return parseListingDate(dp.DeniedPerson.EffectiveDate, true)
+}
+
+func (e BISEntity) listed() time.Time {
+	if e.Entity == nil {
+		return time.Time{}
+	}
+	return parseListingDate(e.Entity.StartDate, true)
+}
+
+func (isn ISN) listed() time.Time {
+	if isn.Sanction == nil {
+		return time.Time{}
+	}
+	return parseListingDate(isn.Sanction.StartDate, true)
+}
+
+func (cs CanadianSanction) listed() time.Time {
+	if cs.Entry == nil {
+		return time.Time{}
+	}
+	return parseListingDate(cs.Entry.DateOfListing, false)
+}
+
+func (as AustralianSanction) listed() time.Time {
+	if as.Entry == nil {
+		return time.Time{}
+	}
+	return parseListingDate(as.Entry.ListingInformation, false)
+}
+
+func (us UnitedNationsSanction) listed() time.Time {
+	if us.Entry == nil {
+		return time.Time{}
+	}
+	return parseListingDate(us.Entry.ListedOn, false)
+}
+
+// bandRank is 2 for the high confidence band, 1 for medium and 0 for low
+func (t bandThresholds) bandRank(match float64) int {
+	switch {
+	case match >= t.high:
+		return 2
+	case match >= t.medium:
+		return 1
+	}
+	return 0
+}
+
+// recentFirst orders results of a higher band first, then the most recently listed
+func (t bandThresholds) recentFirst(mi, mj float64, li, lj time.Time) bool {
+	if bi, bj := t.bandRank(mi), t.bandRank(mj); bi != bj {
+		return bi > bj
+	}
+	return li.After(lj)
+}
+
+// sortByRecency orders each list with dates by recentFirst, results of the same band and date
+// keep their order by match
+func (resp *searchResponse) sortByRecency(t bandThresholds) {
+	sort.SliceStable(resp.DeniedPersons, func(i, j int) bool {
+		a, b := resp.DeniedPersons[i], resp.DeniedPersons[j]
+		return t.recentFirst(a.match, b.match, a.listed(), b.listed())
+	})
+	sort.SliceStable(resp.BISEntities, func(i, j int) bool {
+		a, b := resp.BISEntities[i], resp.BISEntities[j]
+		return t.recentFirst(a.match, b.match, a.listed(), b.listed())
+	})
+	sort.SliceStable(resp.NonproliferationSanctions, func(i, j int) bool {
+		a, b := resp.NonproliferationSanctions[i], resp.NonproliferationSanctions[j]
+		return t.recentFirst(a.match, b.match, a.listed(), b.listed())
+	})
+	sort.SliceStable(resp.CanadianSanctions, func(i, j int) bool {
+		a, b := resp.CanadianSanctions[i], resp.CanadianSanctions[j]
+		return t.recentFirst(a.match, b.match, a.listed(), b.listed())
+	})
+	sort.SliceStable(resp.AustralianSanctions, func(i, j int) bool {
+		a, b := resp.AustralianSanctions[i], resp.AustralianSanctions[j]
+		return t.recentFirst(a.match, b.match, a.listed(), b.listed())
+	})
+	sort.SliceStable(resp.UnitedNationsSanctions, func(i, j int) bool {
+		a, b := resp.UnitedNationsSanctions[i], resp.UnitedNationsSanctions[j]
+		return t.recentFirst(a.match, b.match, a.listed(), b.listed())
+	})
+}
+
+// listedResult is a search result whose list publishes when records were listed
+type listedResult interface {
+	listed() time.Time
+}
+
+// sortByRecency orders the results of each band by recentFirst, across every list
+func (b *resultBands) sortByRecency(t bandThresholds) {
+	listed := func(r bandResult) time.Time {
+		if lr, ok := r.Result.(listedResult); ok {
+			return lr.listed()
+		}
+		return time.Time{}
+	}
+	for _, band := range [][]bandResult{b.High, b.Medium, b.Low} {
+		sort.SliceStable(band, func(i, j int) bool {
+			return t.recentFirst(band[i].Match, band[j].Match, listed(band[i]), listed(band[j]))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/un"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestParseListingDate(t *testing.T) {
+	cases := []struct {
+		raw        string
+		monthFirst bool
+		expected   string
+	}{
+		{"2018-06-25", false, "2018-06-25"},
+		{"10/16/2017", true, "2017-10-16"},
+		{"1/2/2017", true, "2017-01-02"},
+		{"16/10/2017", false, "2017-10-16"},
+		{"Autonomous Sanctions (listed 25 February 2022)", false, "2022-02-25"},
+		{"Instrument 2022 (1 Mar 2022)", false, "2022-03-01"},
+		{"Instrument 2022", false, ""},
+		{"", false, ""},
+	}
+	for i := range cases {
+		var ans string
+		if t := parseListingDate(cases[i].raw, cases[i].monthFirst); !t.IsZero() {
+			ans = t.Format("2006-01-02")
+		}
+		if ans != cases[i].expected {
+			t.Errorf("#%d %q: expected %q got %q", i, cases[i].raw, cases[i].expected, ans)
+		}
+	}
+}
+
+func TestSearch__sortRecent(t *testing.T) {
+	s := &searcher{
+		UnitedNationsSanctions: precomputeUnitedNationsSanctions([]*un.Entry{
+			{ReferenceNumber: "KPe.001", Type: un.TypeEntity, FirstName: "OCEAN MARITIME TRADING", ListedOn: "2009-07-16"},
+			{ReferenceNumber: "KPe.002", Type: un.TypeEntity, FirstName: "OCEAN MARITIME TRADING", ListedOn: "2016-11-30"},
+			{ReferenceNumber: "KPe.003", Type: un.TypeEntity, FirstName: "OCEAN MARITIME TRADING", ListedOn: "not a date"},
+			{ReferenceNumber: "KPe.004", Type: un.TypeEntity, FirstName: "OCEANIC MARINE SERVICES", ListedOn: "2023-01-01"},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	search := func(t *testing.T, query string) []string {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			UnitedNationsSanctions []struct {
+				ReferenceNumber string  `json:"referenceNumber"`
+				Match           float64 `json:"match"`
+			} `json:"unitedNationsSanctions"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		var refs []string
+		for _, r := range resp.UnitedNationsSanctions {
+			refs = append(refs, r.ReferenceNumber)
+		}
+		return refs
+	}
+
+	// equal scores are ordered by their listing date, the most recent first and unknown dates
+	// last, while the weaker match stays below them
+	recent := search(t, "name=ocean+maritime+trading&sort=recent")
+	if len(recent) != 4 || recent[0] != "KPe.002" || recent[1] != "KPe.001" || recent[2] != "KPe.003" || recent[3] != "KPe.004" {
+		t.Errorf("sort=recent: %v", recent)
+	}
+
+	// sorting by score doesn't look at listing dates
+	byScore := search(t, "name=ocean+maritime+trading&sort=score")
+	if len(byScore) != 4 || byScore[0] == "KPe.002" || byScore[3] != "KPe.004" {
+		t.Errorf("sort=score: %v", byScore)
+	}
+
+	// bands are sorted the same way
+	var resp searchResponse
+	resp.UnitedNationsSanctions = s.TopUnitedNationsSanctions(4, "ocean maritime trading", searchOptions{})
+	opts, err := readSearchOptions(&url.URL{RawQuery: "sort=recent&group=bands"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.trim(opts)
+	if len(resp.Bands.High) != 3 || resp.Bands.High[0].Result.(UnitedNationsSanction).Entry.ReferenceNumber != "KPe.002" {
+		t.Errorf("%#v", resp.Bands.High)
+	}
+
+	if _, err := readSearchOptions(&url.URL{RawQuery: "sort=oldest"}); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -9,14 +9,20 @@ import (
 	"math"
 	"net/http/httptest"
 	"net/url"
+	"sort"
 	"strings"
 	"testing"
 
+	"github.com/moov-io/watchman/pkg/au"
+	"github.com/moov-io/watchman/pkg/ca"
 	"github.com/moov-io/watchman/pkg/csl"
+	"github.com/moov-io/watchman/pkg/custom"
 	"github.com/moov-io/watchman/pkg/dpl"
 	"github.com/moov-io/watchman/pkg/ofac"
+	"github.com/moov-io/watchman/pkg/un"
 
 	"github.com/go-kit/kit/log"
+	"github.com/xrash/smetrics"
 )
 
 var (
@@ -179,6 +185,104 @@ var (
 		}, noLogPipeliner),
 		pipe: noLogPipeliner,
 	}
+	caSearcher = &searcher{
+		CanadianSanctions: precomputeCanadianSanctions([]*ca.Entry{
+			{
+				Type:         ca.TypeEntity,
+				Country:      "Russia",
+				EntityOrShip: "Tsentralnyy Bank Rossiyskoy Federatsii",
+				Aliases:      []string{"Central Bank of the Russian Federation", "Bank of Russia"},
+			},
+			{
+				Type:      ca.TypeIndividual,
+				Country:   "Burma",
+				LastName:  "Hlaing",
+				GivenName: "Min Aung",
+			},
+			nil,
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	auSearcher = &searcher{
+		AustralianSanctions: precomputeAustralianSanctions([]*au.Entry{
+			{
+				Reference: "3",
+				Type:      au.TypeEntity,
+				Name:      "KOREA MINING DEVELOPMENT TRADING CORPORATION",
+				Aliases:   []string{"KOMID"},
+			},
+			{
+				Reference:   "1",
+				Type:        au.TypeIndividual,
+				Name:        "Abdul Baqi BASIR AWAL SHAH",
+				DateOfBirth: "c. 1960",
+			},
+			nil,
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	unSearcher = &searcher{
+		UnitedNationsSanctions: precomputeUnitedNationsSanctions([]*un.Entry{
+			{
+				DataID:          "2872157",
+				Type:            un.TypeEntity,
+				ReferenceNumber: "KPe.001",
+				FirstName:       "KOREA MINING DEVELOPMENT TRADING CORPORATION",
+				Aliases:         []string{"KOMID"},
+			},
+			{
+				DataID:          "6908047",
+				Type:            un.TypeIndividual,
+				ReferenceNumber: "QDi.006",
+				FirstName:       "AIMAN",
+				SecondName:      "MUHAMMED",
+				ThirdName:       "RABI",
+				FourthName:      "AL-ZAWAHIRI",
+				Aliases:         []string{"Ayman Al-Zawahari"},
+				DatesOfBirth:    []string{"1951-06-19"},
+			},
+			nil,
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	customSearcher = &searcher{
+		CustomEntries: precomputeCustomEntries([]*custom.Entry{
+			{
+				ID:        "BL-002",
+				Name:      "Blue Harbor Shipping Ltd",
+				Type:      custom.TypeEntity,
+				Aliases:   []string{"Blue Harbour Shipping"},
+				Addresses: []string{"Port Louis, Mauritius"},
+			},
+			{
+				ID:           "BL-001",
+				Name:         "PETROV, Ivan",
+				Type:         custom.TypeIndividual,
+				DatesOfBirth: []string{"1971-03-14"},
+				IDNumbers:    []string{"P1234567"},
+			},
+			nil,
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	isnSearcher = &searcher{
+		ISNs: precomputeISNs([]*csl.ISN{
+			{
+				// ISN records are often only a name, program and notice
+				Programs:              []string{"INKSNA"},
+				Name:                  "183rd Guard Air Defense Missile Regiment",
+				FederalRegisterNotice: "Vol. 83, No. 91, 05/10/2018",
+				StartDate:             "2018-04-30",
+			},
+			{
+				Programs:       []string{"CBW"},
+				Name:           "Mohammed Hamed",
+				AlternateNames: []string{"Abu Hamed"},
+			},
+			nil,
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
 )
 
 func TestJaroWinkler(t *testing.T) {
@@ -224,6 +328,34 @@ func TestJaroWinkler(t *testing.T) {
 	}
 }
 
+func TestJaroWinklerUpperBound(t *testing.T) {
+	tokens := []string{
+		"a", "al", "ali", "alia", "maduro", "madur", "moros", "nicolas", "nicholas", "mohammed", "muhammad",
+		"hamed", "ahmed", "aeroflot", "cuba", "bauc", "abcdefghij", "jihgfedcba", "zzzz", "banco",
+	}
+	for _, a := range tokens {
+		for _, b := range tokens {
+			if bound, v := tokenUpperBound(a, b), smetrics.JaroWinkler(a, b, 0.7, 4); bound < v {
+				t.Errorf("%s vs %s: bound %v is below %v", a, b, bound, v)
+			}
+		}
+	}
+	cases := [][2]string{
+		{"maduro moros nicolas", "nicolas maduro"},
+		{"banco nacional de cuba", "cuba"},
+		{"ali", "mohammed ali hamed"},
+		{"aeroflot", "zzzz"},
+	}
+	for _, c := range cases {
+		if bound, v := jaroWinklerUpperBound(c[0], c[1]), jaroWinkler(c[0], c[1]); bound < v {
+			t.Errorf("%s vs %s: bound %v is below %v", c[0], c[1], bound, v)
+		}
+	}
+
+	// tokens without common characters are never scored
+	eql(t, "no common characters", jaroWinklerUpperBound("zzzz", "abc"), 0.0)
+}
+
 func TestJaroWinklerErr(t *testing.T) {
 	v := jaroWinkler("", "hello")
 	eql(t, "NaN #1", v, 0.0)
@@ -271,7 +403,7 @@ func TestSearch_liveData(t *testing.T) {
 		{"nicolas maduro", 1.0},
 	}
 	for i := range cases {
-		sdns := searcher.TopSDNs(1, cases[i].name)
+		sdns := searcher.TopSDNs(1, cases[i].name, searchOptions{})
 		if len(sdns) == 0 {
 			t.Errorf("name=%q got no results", cases[i].name)
 		}
@@ -381,7 +513,7 @@ func TestSearch__FindAddresses(t *testing.T) {
 }
 
 func TestSearch__TopAddresses(t *testing.T) {
-	addresses := addressSearcher.TopAddresses(1, "Piarco Air")
+	addresses := addressSearcher.TopAddresses(1, "Piarco Air", searchOptions{})
 	if len(addresses) == 0 {
 		t.Fatal("empty Addresses")
 	}
@@ -391,7 +523,7 @@ func TestSearch__TopAddresses(t *testing.T) {
 }
 
 func TestSearch__TopAddressFn(t *testing.T) {
-	addresses := addressSearcher.TopAddressesFn(1, topAddressesCountry("United Kingdom"))
+	addresses := addressSearcher.TopAddressesFn(1, searchOptions{}, topAddressesCountry("United Kingdom"))
 	if len(addresses) == 0 {
 		t.Fatal("empty Addresses")
 	}
@@ -400,6 +532,42 @@ func TestSearch__TopAddressFn(t *testing.T) {
 	}
 }
 
+func TestSearch__TopAddressesDedupe(t *testing.T) {
+	adds := []*ofac.Address{
+		{EntityID: "173", AddressID: "1", Address: "Ibex House, The Minories", CityStateProvincePostalCode: "London EC3N 1DY", Country: "United Kingdom"},
+		{EntityID: "173", AddressID: "2", Address: "IBEX HOUSE THE MINORIES", CityStateProvincePostalCode: "London EC3N 1DY", Country: "United Kingdom"},
+		{EntityID: "173", AddressID: "3", Address: "Ibex House, The Minories, London", CityStateProvincePostalCode: "EC3N 1DY", Country: "United Kingdom"},
+		{EntityID: "173", AddressID: "4", Address: "Ibex House, The Minories", CityStateProvincePostalCode: "London EC3N 1DZ", Country: "United Kingdom"},
+		// another SDN at the same place is kept
+		{EntityID: "174", AddressID: "5", Address: "Ibex House, The Minories", CityStateProvincePostalCode: "London EC3N 1DY", Country: "United Kingdom"},
+	}
+	ids := func(addresses []Address) []string {
+		var out []string
+		for i := range addresses {
+			out = append(out, addresses[i].Address.AddressID)
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	s := &searcher{Addresses: precomputeAddresses(adds), pipe: noLogPipeliner}
+	addresses := s.TopAddresses(10, "ibex house the minories", searchOptions{})
+	if got := strings.Join(ids(addresses), ","); got != "1,4,5" {
+		t.Errorf("got addresses %s", got)
+	}
+	// every address of the SDN is still listed
+	if n := len(s.FindAddresses(10, "173")); n != 4 {
+		t.Errorf("found %d addresses", n)
+	}
+
+	defer func() { dedupeSDNAddresses = true }()
+	dedupeSDNAddresses = false
+	s = &searcher{Addresses: precomputeAddresses(adds), pipe: noLogPipeliner}
+	if got := strings.Join(ids(s.TopAddresses(10, "ibex house the minories", searchOptions{})), ","); got != "1,2,3,4,5" {
+		t.Errorf("got addresses %s without dedupe", got)
+	}
+}
+
 func TestSearch__FindAlts(t *testing.T) {
 	alts := altSearcher.FindAlts(1, "559")
 	if v := len(alts); v != 1 {
@@ -411,7 +579,7 @@ func TestSearch__FindAlts(t *testing.T) {
 }
 
 func TestSearch__TopAlts(t *testing.T) {
-	alts := altSearcher.TopAltNames(1, "SOGO KENKYUSHO")
+	alts := altSearcher.TopAltNames(1, "SOGO KENKYUSHO", searchOptions{})
 	if len(alts) == 0 {
 		t.Fatal("empty AltNames")
 	}
@@ -431,7 +599,7 @@ func TestSearch__FindSDN(t *testing.T) {
 }
 
 func TestSearch__TopSDNs(t *testing.T) {
-	sdns := sdnSearcher.TopSDNs(1, "Ayman ZAWAHIRI")
+	sdns := sdnSearcher.TopSDNs(1, "Ayman ZAWAHIRI", searchOptions{})
 	if len(sdns) == 0 {
 		t.Fatal("empty SDNs")
 	}
@@ -441,7 +609,7 @@ func TestSearch__TopSDNs(t *testing.T) {
 }
 
 func TestSearch__TopDPs(t *testing.T) {
-	dps := dplSearcher.TopDPs(1, "NASER AIRLINES")
+	dps := dplSearcher.TopDPs(1, "NASER AIRLINES", searchOptions{})
 	if len(dps) == 0 {
 		t.Fatal("empty DPs")
 	}
@@ -452,7 +620,7 @@ func TestSearch__TopDPs(t *testing.T) {
 }
 
 func TestSearcher_TopSSIs(t *testing.T) {
-	ssis := ssiSearcher.TopSSIs(1, "ROSOBORONEKSPORT")
+	ssis := ssiSearcher.TopSSIs(1, "ROSOBORONEKSPORT", searchOptions{})
 	if len(ssis) == 0 {
 		t.Fatal("empty SSIs")
 	}
@@ -462,7 +630,7 @@ func TestSearcher_TopSSIs(t *testing.T) {
 }
 
 func TestSearcher_TopSSIs_limit(t *testing.T) {
-	ssis := ssiSearcher.TopSSIs(2, "SPECIALIZED DEPOSITORY")
+	ssis := ssiSearcher.TopSSIs(2, "SPECIALIZED DEPOSITORY", searchOptions{})
 	if len(ssis) != 2 {
 		t.Fatalf("Expected 2 results, found %d", len(ssis))
 	}
@@ -472,7 +640,7 @@ func TestSearcher_TopSSIs_limit(t *testing.T) {
 }
 
 func TestSearcher_TopSSIs_reportAltNameWeight(t *testing.T) {
-	ssis := ssiSearcher.TopSSIs(1, "KENKYUSHO")
+	ssis := ssiSearcher.TopSSIs(1, "KENKYUSHO", searchOptions{})
 	if len(ssis) == 0 {
 		t.Fatal("empty SSIs")
 	}
@@ -485,7 +653,7 @@ func TestSearcher_TopSSIs_reportAltNameWeight(t *testing.T) {
 }
 
 func TestSearcher_TopBISEntities(t *testing.T) {
-	els := bisEntitySearcher.TopBISEntities(1, "Khan")
+	els := bisEntitySearcher.TopBISEntities(1, "Khan", searchOptions{})
 	if len(els) == 0 {
 		t.Fatal("empty ELs")
 	}
@@ -495,7 +663,7 @@ func TestSearcher_TopBISEntities(t *testing.T) {
 }
 
 func TestSearcher_TopBISEntities_AltName(t *testing.T) {
-	els := bisEntitySearcher.TopBISEntities(1, "Luqman Sehreci.")
+	els := bisEntitySearcher.TopBISEntities(1, "Luqman Sehreci.", searchOptions{})
 	if len(els) == 0 {
 		t.Fatal("empty ELs")
 	}
@@ -507,6 +675,135 @@ func TestSearcher_TopBISEntities_AltName(t *testing.T) {
 	}
 }
 
+func TestSearcher_TopCanadianSanctions(t *testing.T) {
+	if n := len(caSearcher.CanadianSanctions); n != 2 {
+		t.Fatalf("got %d CA entries", n)
+	}
+
+	entries := caSearcher.TopCanadianSanctions(1, "Min Aung Hlaing", searchOptions{})
+	if len(entries) == 0 {
+		t.Fatal("empty CA entries")
+	}
+	if entries[0].Entry.Type != ca.TypeIndividual || math.Abs(1.0-entries[0].match) > 0.001 {
+		t.Errorf("match=%.3f %#v", entries[0].match, entries[0].Entry)
+	}
+
+	entries = caSearcher.TopCanadianSanctions(1, "Bank of Russia", searchOptions{})
+	if len(entries) == 0 {
+		t.Fatal("empty CA entries")
+	}
+	if math.Abs(1.0-entries[0].match) > 0.001 {
+		t.Errorf("Expected match=1.0 for aliases: %f - %#v", entries[0].match, entries[0].Entry)
+	}
+}
+
+func TestSearcher_TopAustralianSanctions(t *testing.T) {
+	if n := len(auSearcher.AustralianSanctions); n != 2 {
+		t.Fatalf("got %d AU entries", n)
+	}
+
+	entries := auSearcher.TopAustralianSanctions(1, "Abdul Baqi Basir Awal Shah", searchOptions{})
+	if len(entries) == 0 {
+		t.Fatal("empty AU entries")
+	}
+	if entries[0].Entry.Type != au.TypeIndividual || math.Abs(1.0-entries[0].match) > 0.001 {
+		t.Errorf("match=%.3f %#v", entries[0].match, entries[0].Entry)
+	}
+
+	entries = auSearcher.TopAustralianSanctions(1, "komid", searchOptions{matchedName: true})
+	if len(entries) == 0 {
+		t.Fatal("empty AU entries")
+	}
+	if math.Abs(1.0-entries[0].match) > 0.001 || entries[0].alias.MatchedName != "komid" {
+		t.Errorf("Expected match=1.0 for aliases: %f - %#v", entries[0].match, entries[0])
+	}
+}
+
+func TestSearcher_TopUnitedNationsSanctions(t *testing.T) {
+	if n := len(unSearcher.UnitedNationsSanctions); n != 2 {
+		t.Fatalf("got %d UN entries", n)
+	}
+
+	// every name component is searched
+	entries := unSearcher.TopUnitedNationsSanctions(1, "Aiman Muhammed Rabi al-Zawahiri", searchOptions{})
+	if len(entries) == 0 {
+		t.Fatal("empty UN entries")
+	}
+	if entries[0].Entry.Type != un.TypeIndividual || math.Abs(1.0-entries[0].match) > 0.001 {
+		t.Errorf("match=%.3f %#v", entries[0].match, entries[0].Entry)
+	}
+
+	entries = unSearcher.TopUnitedNationsSanctions(1, "komid", searchOptions{matchedName: true})
+	if len(entries) == 0 {
+		t.Fatal("empty UN entries")
+	}
+	if math.Abs(1.0-entries[0].match) > 0.001 || entries[0].alias.MatchedName != "komid" {
+		t.Errorf("Expected match=1.0 for aliases: %f - %#v", entries[0].match, entries[0])
+	}
+}
+
+func TestSearcher_TopCustomEntries(t *testing.T) {
+	if n := len(customSearcher.CustomEntries); n != 2 {
+		t.Fatalf("got %d custom entries", n)
+	}
+
+	// individuals are reordered like SDNs
+	entries := customSearcher.TopCustomEntries(1, "Ivan Petrov", searchOptions{})
+	if len(entries) == 0 {
+		t.Fatal("empty custom entries")
+	}
+	if entries[0].Entry.ID != "BL-001" || entries[0].name != "ivan petrov" || math.Abs(1.0-entries[0].match) > 0.001 {
+		t.Errorf("match=%.3f %#v", entries[0].match, entries[0].Entry)
+	}
+
+	entries = customSearcher.TopCustomEntries(1, "blue harbour shipping ltd", searchOptions{matchedName: true})
+	if len(entries) == 0 {
+		t.Fatal("empty custom entries")
+	}
+	if math.Abs(1.0-entries[0].match) > 0.001 || entries[0].alias.MatchedName != "blue harbour shipping" {
+		t.Errorf("Expected match=1.0 for aliases: %f - %#v", entries[0].match, entries[0])
+	}
+}
+
+func TestSearcher_TopISNs(t *testing.T) {
+	if n := len(isnSearcher.ISNs); n != 2 {
+		t.Fatalf("got %d ISNs", n)
+	}
+
+	isns := isnSearcher.TopISNs(1, "183rd guard air defense missile regiment", searchOptions{})
+	if len(isns) == 0 {
+		t.Fatal("empty ISNs")
+	}
+	if isns[0].Sanction.Name != "183rd Guard Air Defense Missile Regiment" {
+		t.Errorf("%#v", isns[0].Sanction)
+	}
+
+	isns = isnSearcher.TopISNs(1, "Abu Hamed", searchOptions{})
+	if len(isns) == 0 {
+		t.Fatal("empty ISNs")
+	}
+	if math.Abs(1.0-isns[0].match) > 0.001 {
+		t.Errorf("Expected match=1.0 for alt names: %f - %#v", isns[0].match, isns[0].Sanction)
+	}
+	if isns[0].alias.MatchedName != "" {
+		t.Errorf("unexpected matchedName without option: %#v", isns[0].alias)
+	}
+
+	isns = isnSearcher.TopISNs(1, "Abu Hamed", searchOptions{matchedName: true})
+	if len(isns) == 0 {
+		t.Fatal("empty ISNs")
+	}
+	if isns[0].alias.MatchedName != "abu hamed" || isns[0].alias.MatchedNameType != aliasTypeAKA {
+		t.Errorf("unexpected alias: %#v", isns[0].alias)
+	}
+
+	// the primary name doesn't report an alias
+	isns = isnSearcher.TopISNs(1, "Mohammed Hamed", searchOptions{matchedName: true})
+	if len(isns) == 0 || isns[0].alias.MatchedName != "" {
+		t.Errorf("unexpected ISNs: %#v", isns)
+	}
+}
+
 func TestSearch__extractIDFromRemark(t *testing.T) {
 	cases := []struct {
 		input, expected string
@@ -530,6 +827,9 @@ func TestSearch__extractIDFromRemark(t *testing.T) {
 		{"Tax ID No. AABA 670850 Y.", "AABA 670850"},
 		{"Phone No. 263-4-486946; Fax No. 263-4-487261.", "263-4-486946"},
 		{"D-U-N-S Number 56-558-7594; V.A.T. Number MT15388917 (Malta); Trade License No. C 24129 (Malta); Company Number 4220856; Linked To: DEBONO, Darren.", "C 24129"}, // SDN 23410
+		// malformed remarks found by FuzzExtractIDFromRemark
+		{"Passport No.", ""},
+		{"No.", ""},
 	}
 	for i := range cases {
 		result := extractIDFromRemark(cases[i].input)
@@ -537,6 +837,12 @@ func TestSearch__extractIDFromRemark(t *testing.T) {
 			t.Errorf("input=%s expected=%s result=%s", cases[i].input, cases[i].expected, result)
 		}
 	}
+
+	// remarks with many "No." tokens used to be rescanned from each of them
+	remarks := strings.Repeat("Passport No. 1234 ", 20000)
+	if id := extractIDFromRemark(remarks); !strings.HasPrefix(id, "1234") {
+		t.Errorf("unexpected ID: %.20s", id)
+	}
 }
 
 func TestSearch__FindSDNsByRemarksID(t *testing.T) {
@@ -604,3 +910,88 @@ func TestSearch__FindSDNsByRemarksID(t *testing.T) {
 		t.Fatalf("sdns=%#v", sdns)
 	}
 }
+
+func TestSearch__alignTokens(t *testing.T) {
+	// SDN names are reordered (and precomputed) when indexed, so query tokens may be out of order
+	indexed := idSearcher.SDNs[0].name // nicolas maduro moros
+	alignment := alignTokens(indexed, precompute("Maduro Nicolas"))
+	if len(alignment) != 2 {
+		t.Fatalf("unexpected alignment: %#v", alignment)
+	}
+	if alignment[0].Query != "maduro" || alignment[0].Candidate != "maduro" {
+		t.Errorf("alignment[0]=%#v", alignment[0])
+	}
+	eql(t, "maduro", alignment[0].Score, 1.0)
+	if alignment[1].Query != "nicolas" || alignment[1].Candidate != "nicolas" {
+		t.Errorf("alignment[1]=%#v", alignment[1])
+	}
+	eql(t, "nicolas", alignment[1].Score, 1.0)
+
+	// misspelled tokens still align to their closest candidate
+	alignment = alignTokens(indexed, precompute("nicolas morros"))
+	if len(alignment) != 2 || alignment[1].Candidate != "moros" {
+		t.Fatalf("unexpected alignment: %#v", alignment)
+	}
+	if alignment[1].Score >= 1.0 {
+		t.Errorf("expected partial match: %#v", alignment[1])
+	}
+
+	if out := alignTokens("", "nicolas"); out != nil {
+		t.Errorf("unexpected alignment: %#v", out)
+	}
+}
+
+func TestSearch__namelessRecords(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "1", SDNName: "-", SDNType: "vessel", Remarks: "Registration No. 8898831 (Korea, North)."},
+			{EntityID: "2", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"},
+		}, nil, noLogPipeliner),
+		Addresses: precomputeAddresses([]*ofac.Address{
+			{EntityID: "1", AddressID: "1", Address: "Port of Nampo", Country: "Korea, North"},
+		}),
+		CanadianSanctions: precomputeCanadianSanctions([]*ca.Entry{
+			{Type: ca.TypeEntity, EntityOrShip: "-", Country: "Syria"},
+			{Type: ca.TypeEntity, Aliases: []string{"Syrian Arab Airlines"}, Country: "Syria"},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	if s.SDNs[0].name != "" {
+		t.Fatalf("name=%q", s.SDNs[0].name)
+	}
+
+	// name searches never return records without a name
+	for _, opts := range []searchOptions{{}, {matchMode: matchModeWildcard}} {
+		for _, sdn := range s.TopSDNs(10, "*", opts) {
+			if sdn.EntityID == "1" {
+				t.Errorf("%#v: found nameless SDN", opts)
+			}
+		}
+		for _, sdn := range s.TopSDNsByNames(10, []string{"*", "nicolas"}, opts) {
+			if sdn.EntityID == "1" {
+				t.Errorf("%#v: found nameless SDN", opts)
+			}
+		}
+	}
+	// records with an alias are still compared by it
+	if cas := s.TopCanadianSanctions(10, "syrian arab airlines", searchOptions{}); len(cas) != 1 || cas[0].match != 1.0 {
+		t.Errorf("got %#v", cas)
+	}
+
+	// but they're found by ID and address
+	if sdns := s.FindSDNsByRemarksID(10, "8898831"); len(sdns) != 1 || sdns[0].EntityID != "1" {
+		t.Errorf("got %#v", sdns)
+	}
+	if addresses := s.TopAddresses(1, "port of nampo", searchOptions{}); len(addresses) != 1 || addresses[0].Address.EntityID != "1" {
+		t.Errorf("got %#v", addresses)
+	}
+
+	defer func() { searchNamelessRecords = false }()
+	searchNamelessRecords = true
+	if sdns := s.TopSDNs(10, "nicolas maduro", searchOptions{}); len(sdns) != 2 {
+		t.Errorf("got %d SDNs", len(sdns))
+	}
+	if cas := s.TopCanadianSanctions(10, "syrian arab airlines", searchOptions{}); len(cas) != 2 {
+		t.Errorf("got %d CA entries", len(cas))
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	defaultSearchTimeout = 30 * time.Second
+
+	// cancelCheckInterval is how many records are scored between checks of a search's context
+	cancelCheckInterval = 256
+)
+
+// searchTimeout is the longest a search runs before it's cancelled with a 504, clients can lower
+// it with ?timeout=. Zero doesn't limit searches. main sets it from SEARCH_TIMEOUT.
+var searchTimeout = defaultSearchTimeout
+
+// getSearchTimeout reads a non-negative duration (e.g. 10s)
+//
+// env is the value from an environmental variable
+func getSearchTimeout(logger log.Logger, env string) time.Duration {
+	if env == "" {
+		return defaultSearchTimeout
+	}
+	dur, err := time.ParseDuration(env)
+	if err != nil || dur < 0 {
+		logger.Log("main", fmt.Sprintf("invalid SEARCH_TIMEOUT=%q, using default of %v", env, defaultSearchTimeout))
+		return defaultSearchTimeout
+	}
+	if dur == 0 {
+		logger.Log("main", "SEARCH_TIMEOUT=0, searches aren't limited")
+	} else {
+		logger.Log("main", fmt.Sprintf("Cancelling searches after %v", dur))
+	}
+	return dur
+}
+
+// searchDeadline returns how long a search may run, which is the lower of requested (from
+// ?timeout=) and searchTimeout. Zero doesn't limit the search.
+func searchDeadline(requested time.Duration) time.Duration {
+	if requested > 0 && (searchTimeout <= 0 || requested < searchTimeout) {
+		return requested
+	}
+	return searchTimeout
+}
+
+// withSearchTimeout returns r with a context cancelled once the search's deadline passes
+func withSearchTimeout(r *http.Request, opts searchOptions) (*http.Request, context.CancelFunc) {
+	timeout := searchDeadline(opts.timeout)
+	if timeout <= 0 {
+		return r, func() {}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	return r.WithContext(ctx), cancel
+}
+
+// requestSearchOptions reads the search options of r, which are validated in search(). Searches
+// using them stop scoring records once the context of r is done.
+func requestSearchOptions(r *http.Request) searchOptions {
+	opts, _ := readSearchOptions(r.URL)
+	opts.ctx = r.Context()
+	return opts
+}
+
+// cancelled returns true when the search was cancelled, it's checked while scoring the i-th
+// record so only every cancelCheckInterval records look at the context.
+func (opts searchOptions) cancelled(i int) bool {
+	return opts.ctx != nil && i%cancelCheckInterval == 0 && opts.ctx.Err() != nil
+}
+
+// searchTimedOut writes a 504 and returns true when the search of r was cancelled, any partial
+// results are dropped.
+func searchTimedOut(w http.ResponseWriter, r *http.Request) bool {
+	err := r.Context().Err()
+	if err == nil {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusGatewayTimeout)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": fmt.Sprintf("search cancelled: %v", err),
+	})
+	return true
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSearchTimeout__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	for _, env := range []string{"", "-1s", "soon"} {
+		if dur := getSearchTimeout(logger, env); dur != defaultSearchTimeout {
+			t.Errorf("%q: got %v", env, dur)
+		}
+	}
+	if dur := getSearchTimeout(logger, "0"); dur != 0 {
+		t.Errorf("got %v", dur)
+	}
+	if dur := getSearchTimeout(logger, "2m"); dur != 2*time.Minute {
+		t.Errorf("got %v", dur)
+	}
+}
+
+func TestSearchTimeout__deadline(t *testing.T) {
+	defer func(d time.Duration) { searchTimeout = d }(searchTimeout)
+
+	searchTimeout = 10 * time.Second
+	cases := []struct {
+		requested, expected time.Duration
+	}{
+		{0, 10 * time.Second},
+		{time.Second, time.Second},
+		{time.Minute, 10 * time.Second}, // clients can only lower the timeout
+	}
+	for i := range cases {
+		if dur := searchDeadline(cases[i].requested); dur != cases[i].expected {
+			t.Errorf("#%d: got %v", i, dur)
+		}
+	}
+
+	searchTimeout = 0
+	if dur := searchDeadline(0); dur != 0 {
+		t.Errorf("got %v", dur)
+	}
+	if dur := searchDeadline(time.Second); dur != time.Second {
+		t.Errorf("got %v", dur)
+	}
+}
+
+func TestSearchTimeout__options(t *testing.T) {
+	u, _ := url.Parse("/search?name=nicolas&timeout=250ms")
+	opts, err := readSearchOptions(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.timeout != 250*time.Millisecond || opts.ctx != nil {
+		t.Errorf("unexpected options: %#v", opts)
+	}
+	for _, v := range []string{"0s", "-1s", "5"} {
+		u, _ := url.Parse("/search?name=nicolas&timeout=" + v)
+		if _, err := readSearchOptions(u); err == nil {
+			t.Errorf("%s: expected error", v)
+		}
+	}
+}
+
+func TestSearchTimeout__cancelled(t *testing.T) {
+	// enough records that scoring every one of them takes a while
+	s := &searcher{pipe: noLogPipeliner}
+	for i := 0; i < 200000; i++ {
+		s.SDNs = append(s.SDNs, &SDN{
+			SDN:  &ofac.SDN{EntityID: fmt.Sprintf("%d", i), SDNName: "MADURO MOROS, Nicolas"},
+			name: fmt.Sprintf("nicolas maduro moros %d", i),
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	opts := searchOptions{ctx: ctx}
+
+	start := time.Now()
+	if sdns := s.TopSDNs(10, "nicolas maduro", opts); len(sdns) != 0 {
+		t.Errorf("got %d SDNs", len(sdns))
+	}
+	if sdns := s.TopSDNsByNames(10, []string{"nicolas maduro", "maduro moros"}, opts); len(sdns) != 0 {
+		t.Errorf("got %d SDNs", len(sdns))
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("cancelled searches took %v", elapsed)
+	}
+
+	// searches without a context score every record
+	if sdns := s.TopSDNs(10, "nicolas maduro", searchOptions{}); len(sdns) != 10 {
+		t.Errorf("got %d SDNs", len(sdns))
+	}
+}
+
+func TestSearchTimeout__handler(t *testing.T) {
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, idSearcher)
+
+	for _, query := range []string{"name=nicolas+maduro", "q=nicolas+maduro", "altName=nicolas", "address=ibex+house"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?timeout=1ns&"+query, nil))
+		w.Flush()
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("%s: bogus status code: %d: %s", query, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?timeout=10s&name=nicolas+maduro", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Errorf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+}
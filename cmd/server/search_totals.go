@@ -0,0 +1,44 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync/atomic"
+)
+
+// largest returns a newLargest for results from source which drops results below ?minMatch=
+// and counts the others for ?totalMatches=true. The source's multiplier (see
+// sourceScoreMultipliers) is applied after, so minMatch is compared to the adjusted match.
+func (opts searchOptions) largest(limit int, source string) *largest {
+	xs := newLargest(limit)
+	xs.counted = opts.matches
+	if opts.selfMatches != nil {
+		xs.skip = func(it *item) bool { return opts.selfMatches.suppress(source, it) }
+	}
+	if opts.minMatch > 0 {
+		xs.minWeight = opts.minMatch
+		if multiplier, exists := sourceScoreMultipliers[source]; exists && multiplier > 0 {
+			xs.minWeight = opts.minMatch / multiplier
+		}
+	}
+	return xs
+}
+
+// countMatches adds n results which were found without scoring (e.g. by ID) to the total
+func (opts searchOptions) countMatches(n int) {
+	if opts.matches != nil {
+		atomic.AddInt64(opts.matches, int64(n))
+	}
+}
+
+// totalMatches returns how many results of every list searched scored above zero and at least
+// minMatch before limit was applied, or nil when ?totalMatches=true wasn't requested
+func (opts searchOptions) totalMatches() *int {
+	if opts.matches == nil {
+		return nil
+	}
+	n := int(atomic.LoadInt64(opts.matches))
+	return &n
+}
@@ -0,0 +1,96 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSearch__totalMatches(t *testing.T) {
+	var sdns []*ofac.SDN
+	for i := 0; i < 8; i++ {
+		sdns = append(sdns, &ofac.SDN{EntityID: fmt.Sprintf("%d", i+1), SDNName: "MADURO, Nicolas", SDNType: "individual"})
+	}
+	sdns = append(sdns, &ofac.SDN{EntityID: "99", SDNName: "PETROS, Elena", SDNType: "individual", Remarks: "Passport No. 5892464 (Greece);"})
+	s := &searcher{
+		SDNs: precomputeSDNs(sdns, nil, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	search := func(query string) (*httptest.ResponseRecorder, *searchResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?"+query, nil))
+		w.Flush()
+		var resp searchResponse
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return w, &resp
+	}
+
+	// more matches than the limit
+	w, resp := search("name=nicolas+maduro&sources=SDN&minMatch=0.9&totalMatches=true&limit=3")
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	if len(resp.SDNs) != 3 || resp.TotalMatches == nil || *resp.TotalMatches != 8 {
+		t.Errorf("SDNs=%d totalMatches=%v", len(resp.SDNs), resp.TotalMatches)
+	}
+
+	// without minMatch the weaker match is counted
+	_, resp = search("name=nicolas+maduro&sources=SDN&totalMatches=true&limit=3")
+	if resp.TotalMatches == nil || *resp.TotalMatches != 9 {
+		t.Errorf("totalMatches=%v", resp.TotalMatches)
+	}
+
+	// minMatch drops results from the page too
+	_, resp = search("name=nicolas+maduro&sources=SDN&minMatch=0.9&limit=20")
+	if len(resp.SDNs) != 8 || resp.TotalMatches != nil {
+		t.Errorf("SDNs=%d totalMatches=%v", len(resp.SDNs), resp.TotalMatches)
+	}
+
+	// ID searches count their hits
+	_, resp = search("id=5892464&totalMatches=true")
+	if len(resp.SDNs) != 1 || resp.TotalMatches == nil || *resp.TotalMatches != 1 {
+		t.Errorf("SDNs=%d totalMatches=%v", len(resp.SDNs), resp.TotalMatches)
+	}
+
+	for _, query := range []string{"name=maduro&minMatch=1.5", "name=maduro&minMatch=NaN", "name=maduro&minMatch=x", "name=maduro&totalMatches=maybe"} {
+		if w, _ := search(query); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: bogus status code: %d", query, w.Code)
+		}
+	}
+}
+
+func TestSearch__totalMatchesSourceMultiplier(t *testing.T) {
+	defer func() { sourceScoreMultipliers = nil }()
+	sourceScoreMultipliers = map[string]float64{sourceSDN: 0.5}
+
+	opts := searchOptions{minMatch: 0.4, matches: new(int64)}
+	xs := opts.largest(1, sourceSDN)
+	xs.add(&item{weight: 0.9})
+	xs.add(&item{weight: 0.85})
+	xs.add(&item{weight: 0.7})
+	if n := opts.totalMatches(); n == nil || *n != 2 {
+		t.Errorf("totalMatches=%v", n)
+	}
+	if len(xs.items) != 1 || xs.items[0].weight != 0.9 {
+		t.Errorf("items=%#v", xs.items)
+	}
+}
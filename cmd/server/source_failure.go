@@ -0,0 +1,114 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// sourceFailureKeepLastGood keeps the current records of a source which fails to download or
+	// parse and marks it stale, other sources are still refreshed.
+	sourceFailureKeepLastGood = "keep-last-good"
+
+	// sourceFailureFailHard fails the whole refresh when any source fails, every source keeps
+	// its current records until a refresh succeeds.
+	sourceFailureFailHard = "fail-hard"
+
+	// staleSourcesHeader lists the sources of a search response which were kept from an earlier
+	// refresh because their latest download failed
+	staleSourcesHeader = "X-Watchman-Stale-Sources"
+)
+
+var (
+	// sourceFailurePolicy is used by refreshData, main sets it from SOURCE_FAILURE_POLICY
+	sourceFailurePolicy = sourceFailureKeepLastGood
+
+	dataSourceStale = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "data_source_stale",
+		Help: "1 if a source kept its records from an earlier refresh because its latest download failed",
+	}, []string{"source"})
+)
+
+func init() {
+	prometheus.MustRegister(dataSourceStale)
+}
+
+// getSourceFailurePolicy reads keep-last-good or fail-hard
+//
+// env is the value from an environmental variable
+func getSourceFailurePolicy(logger log.Logger, env string) string {
+	switch policy := strings.ToLower(strings.TrimSpace(env)); policy {
+	case "":
+		return sourceFailureKeepLastGood
+	case sourceFailureKeepLastGood, sourceFailureFailHard:
+		logger.Log("main", fmt.Sprintf("Setting source failure policy to %s", policy))
+		return policy
+	}
+	logger.Log("main", fmt.Sprintf("invalid SOURCE_FAILURE_POLICY=%q, using %s", env, sourceFailureKeepLastGood))
+	return sourceFailureKeepLastGood
+}
+
+// sourceFailed returns err when a refresh should fail because sources couldn't be downloaded or
+// parsed. Otherwise the sources are added to stale and the refresh continues with their current
+// records, which may be empty. OFAC and DPL are required, so they only continue when kept is
+// true (the sources had records to keep).
+func (s *searcher) sourceFailed(err error, kept, required bool, stale map[string]bool, sources ...string) error {
+	if sourceFailurePolicy == sourceFailureFailHard || (required && !kept) {
+		return err
+	}
+	if s.logger != nil {
+		if kept {
+			s.logger.Log("download", fmt.Sprintf("WARN: keeping last-good %s records", strings.Join(sources, ", ")), "description", err)
+		} else {
+			s.logger.Log("download", fmt.Sprintf("WARN: skipping %s download", strings.Join(sources, ", ")), "description", err)
+		}
+	}
+	for _, source := range sources {
+		stale[source] = true
+	}
+	return nil
+}
+
+// markStale records which sources were kept by the last refresh, sources which were already
+// stale keep the time they first failed. The searcher must be locked.
+func (s *searcher) markStale(stale map[string]bool) {
+	since := make(map[string]time.Time)
+	for source := range stale {
+		if t, exists := s.staleSources[source]; exists {
+			since[source] = t
+		} else {
+			since[source] = time.Now()
+		}
+	}
+	s.staleSources = since
+
+	for _, source := range knownSources {
+		if stale[source] {
+			dataSourceStale.WithLabelValues(source).Set(1)
+		} else {
+			dataSourceStale.WithLabelValues(source).Set(0)
+		}
+	}
+}
+
+// staleSourceNames returns the sources whose records were kept after a failed download
+func (s *searcher) staleSourceNames() []string {
+	s.RLock()
+	defer s.RUnlock()
+
+	var out []string
+	for _, source := range knownSources {
+		if _, exists := s.staleSources[source]; exists {
+			out = append(out, source)
+		}
+	}
+	return out
+}
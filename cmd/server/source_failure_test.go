@@ -0,0 +1,141 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSourceFailure__getPolicy(t *testing.T) {
+	logger := log.NewNopLogger()
+	cases := map[string]string{
+		"":                 sourceFailureKeepLastGood,
+		"keep-last-good":   sourceFailureKeepLastGood,
+		" FAIL-HARD ":      sourceFailureFailHard,
+		"fail-hard":        sourceFailureFailHard,
+		"drop":             sourceFailureKeepLastGood,
+		"keep-last-good,x": sourceFailureKeepLastGood,
+	}
+	for env, expected := range cases {
+		if policy := getSourceFailurePolicy(logger, env); policy != expected {
+			t.Errorf("%q: got %q expected %q", env, policy, expected)
+		}
+	}
+}
+
+func TestSourceFailure__keepLastGood(t *testing.T) {
+	defer func(policy string) { sourceFailurePolicy = policy }(sourceFailurePolicy)
+	sourceFailurePolicy = sourceFailureKeepLastGood
+
+	dir := copyTestData(t)
+	defer os.RemoveAll(dir)
+
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.CanadianSanctions) != 4 || len(s.staleSourceNames()) != 0 {
+		t.Fatalf("CanadianSanctions=%d stale=%v", len(s.CanadianSanctions), s.staleSourceNames())
+	}
+	original, err := ioutil.ReadFile(filepath.Join(dir, "sema-lmes.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the Canadian list is broken on the next refresh
+	if err := ioutil.WriteFile(filepath.Join(dir, "sema-lmes.xml"), []byte("<data-set><record>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := s.refreshData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.CanadianSanctions) != 4 || stats.CanadianSanctions != 4 {
+		t.Errorf("CanadianSanctions=%d stats.CanadianSanctions=%d", len(s.CanadianSanctions), stats.CanadianSanctions)
+	}
+	if stale := s.staleSourceNames(); len(stale) != 1 || stale[0] != sourceCA {
+		t.Errorf("stale=%v", stale)
+	}
+	failedAt := s.staleSources[sourceCA]
+
+	// the last-good records are still searchable, flagged as stale
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro&sources=CA&limit=1", nil))
+	w.Flush()
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	if v := w.Header().Get(staleSourcesHeader); v != "CA" {
+		t.Errorf("%s: %q", staleSourcesHeader, v)
+	}
+	if !strings.Contains(w.Body.String(), `"item":"41"`) {
+		t.Errorf("missing kept record: %s", w.Body.String())
+	}
+
+	// still failing keeps when the source first failed
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !s.staleSources[sourceCA].Equal(failedAt) {
+		t.Errorf("staleSources[CA]=%v expected %v", s.staleSources[sourceCA], failedAt)
+	}
+
+	// a successful refresh clears the flag
+	if err := ioutil.WriteFile(filepath.Join(dir, "sema-lmes.xml"), original, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if stale := s.staleSourceNames(); len(stale) != 0 {
+		t.Errorf("stale=%v", stale)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro&sources=CA&limit=1", nil))
+	if v := w.Header().Get(staleSourcesHeader); v != "" {
+		t.Errorf("%s: %q", staleSourcesHeader, v)
+	}
+}
+
+func TestSourceFailure__failHard(t *testing.T) {
+	defer func(policy string) { sourceFailurePolicy = policy }(sourceFailurePolicy)
+
+	dir := copyTestData(t)
+	defer os.RemoveAll(dir)
+
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	sdns := s.SDNs
+
+	sourceFailurePolicy = sourceFailureFailHard
+	if err := ioutil.WriteFile(filepath.Join(dir, "sema-lmes.xml"), []byte("<data-set><record>"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.refreshData(dir); err == nil || !strings.Contains(err.Error(), "CA records") {
+		t.Fatalf("expected error: %v", err)
+	}
+	if len(s.CanadianSanctions) != 4 || &s.SDNs[0] != &sdns[0] || len(s.staleSourceNames()) != 0 {
+		t.Error("records were replaced after a failed refresh")
+	}
+}
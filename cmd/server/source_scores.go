@@ -0,0 +1,81 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+)
+
+// sourceScoreMultipliers discount the match of name and address results from lower quality
+// lists before results are ranked across lists. Sources which aren't listed keep their scores
+// (a multiplier of 1.0). main sets it from SOURCE_SCORE_MULTIPLIERS.
+var sourceScoreMultipliers map[string]float64
+
+// sourceAdjustment is included on results whose match was multiplied by their source's entry
+// of sourceScoreMultipliers, along with the score before the adjustment
+type sourceAdjustment struct {
+	RawMatch         float64 `json:"rawMatch,omitempty"`
+	SourceMultiplier float64 `json:"sourceMultiplier,omitempty"`
+}
+
+// getSourceScoreMultipliers reads a comma separated list of SOURCE=multiplier pairs
+// (e.g. CA=0.95,AU=0.9). Nil is returned when env is empty or invalid.
+//
+// env is the value from an environmental variable
+func getSourceScoreMultipliers(logger log.Logger, env string) map[string]float64 {
+	if strings.TrimSpace(env) == "" {
+		return nil
+	}
+	multipliers, err := parseSourceScoreMultipliers(env)
+	if err != nil {
+		logger.Log("main", fmt.Sprintf("invalid SOURCE_SCORE_MULTIPLIERS=%q, scores won't be adjusted: %v", env, err))
+		return nil
+	}
+	logger.Log("main", fmt.Sprintf("Adjusting scores of %d sources", len(multipliers)))
+	return multipliers
+}
+
+func parseSourceScoreMultipliers(raw string) (map[string]float64, error) {
+	out := make(map[string]float64)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q isn't SOURCE=multiplier", pair)
+		}
+		source := strings.ToUpper(strings.TrimSpace(parts[0]))
+		if !isKnownSource(source) {
+			return nil, fmt.Errorf("unknown source %q, expected one of %s", parts[0], strings.Join(knownSources, ", "))
+		}
+		if _, exists := out[source]; exists {
+			return nil, fmt.Errorf("%s is listed more than once", source)
+		}
+		multiplier, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || multiplier <= 0 || multiplier > 1 {
+			return nil, fmt.Errorf("invalid multiplier %q for %s, must be above 0 and at most 1", parts[1], source)
+		}
+		out[source] = multiplier
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no sources")
+	}
+	return out, nil
+}
+
+// adjustSourceScore returns the match of a result from source which scored raw, and its
+// adjustment when the source has a multiplier
+func adjustSourceScore(source string, raw float64) (float64, sourceAdjustment) {
+	multiplier, exists := sourceScoreMultipliers[source]
+	if !exists || multiplier == 1.0 {
+		return raw, sourceAdjustment{}
+	}
+	return raw * multiplier, sourceAdjustment{RawMatch: raw, SourceMultiplier: multiplier}
+}
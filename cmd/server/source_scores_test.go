@@ -0,0 +1,118 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ca"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestSourceScores__get(t *testing.T) {
+	logger := log.NewNopLogger()
+	for _, env := range []string{"", " ", "CA", "CA=", "OTHER=0.9", "CA=0", "CA=1.5", "CA=high", "CA=0.9,ca=0.8", ","} {
+		if multipliers := getSourceScoreMultipliers(logger, env); multipliers != nil {
+			t.Errorf("%q: got %#v", env, multipliers)
+		}
+	}
+
+	multipliers := getSourceScoreMultipliers(logger, "ca=0.9, AU = 0.75,SDN=1,")
+	if len(multipliers) != 3 || multipliers[sourceCA] != 0.9 || multipliers[sourceAU] != 0.75 || multipliers[sourceSDN] != 1.0 {
+		t.Errorf("unexpected multipliers: %#v", multipliers)
+	}
+}
+
+func TestSourceScores__adjust(t *testing.T) {
+	defer func(m map[string]float64) { sourceScoreMultipliers = m }(sourceScoreMultipliers)
+
+	sourceScoreMultipliers = nil
+	if match, adj := adjustSourceScore(sourceCA, 0.9); match != 0.9 || adj != (sourceAdjustment{}) {
+		t.Errorf("match=%v adjustment=%#v", match, adj)
+	}
+
+	sourceScoreMultipliers = map[string]float64{sourceCA: 0.9, sourceSDN: 1.0}
+	if match, adj := adjustSourceScore(sourceSDN, 0.9); match != 0.9 || adj != (sourceAdjustment{}) {
+		t.Errorf("match=%v adjustment=%#v", match, adj)
+	}
+	match, adj := adjustSourceScore(sourceCA, 0.8)
+	eql(t, "discounted match", match, 0.72)
+	if adj.RawMatch != 0.8 || adj.SourceMultiplier != 0.9 {
+		t.Errorf("adjustment=%#v", adj)
+	}
+}
+
+func TestSourceScores__ranking(t *testing.T) {
+	defer func(m map[string]float64) { sourceScoreMultipliers = m }(sourceScoreMultipliers)
+
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"},
+		}, nil, noLogPipeliner),
+		CanadianSanctions: precomputeCanadianSanctions([]*ca.Entry{
+			{Item: "41", Type: ca.TypeIndividual, LastName: "Maduro Moros", GivenName: "Nicolas"},
+		}, noLogPipeliner),
+		pipe: noLogPipeliner,
+	}
+	router := mux.NewRouter()
+	addSearchRoutes(log.NewNopLogger(), router, s)
+
+	search := func() []bandResult {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/search?name=nicolas+maduro+moros&sources=SDN,CA&group=bands&limit=1", nil))
+		w.Flush()
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			Bands struct {
+				High []bandResult `json:"high"`
+			} `json:"bands"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Bands.High
+	}
+
+	// both lists score the same without multipliers
+	sourceScoreMultipliers = nil
+	results := search()
+	if len(results) != 2 || results[0].Match != results[1].Match {
+		t.Fatalf("expected equal scores: %#v", results)
+	}
+
+	// reversing the lists' order rules out a stable sort keeping the SDN first
+	sourceScoreMultipliers = map[string]float64{sourceSDN: 0.97}
+	results = search()
+	if len(results) != 2 || results[0].List != "canadianSanctions" || results[1].List != "SDNs" {
+		t.Fatalf("unexpected order: %#v", results)
+	}
+
+	sourceScoreMultipliers = map[string]float64{sourceCA: 0.97}
+	results = search()
+	if len(results) != 2 || results[0].List != "SDNs" || results[1].List != "canadianSanctions" {
+		t.Fatalf("unexpected order: %#v", results)
+	}
+	eql(t, "discounted match", results[1].Match, results[0].Match*0.97)
+
+	// the adjustment is included on the discounted result only
+	discounted, _ := json.Marshal(results[1].Result)
+	if !strings.Contains(string(discounted), `"rawMatch":1`) || !strings.Contains(string(discounted), `"sourceMultiplier":0.97`) {
+		t.Errorf("missing adjustment: %s", discounted)
+	}
+	full, _ := json.Marshal(results[0].Result)
+	if strings.Contains(string(full), "sourceMultiplier") {
+		t.Errorf("unexpected adjustment: %s", full)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Keys for each list Watchman indexes, used with ?sources=SDN,SSI to limit searches.
+const (
+	sourceSDN    = "SDN"    // OFAC Specially Designated Nationals (includes alt names and addresses)
+	sourceSSI    = "SSI"    // OFAC Sectoral Sanctions Identifications
+	sourceDPL    = "DPL"    // BIS Denied Persons List
+	sourceEL     = "EL"     // BIS Entity List
+	sourceISN    = "ISN"    // State Department Nonproliferation Sanctions
+	sourceCA     = "CA"     // Global Affairs Canada Consolidated Autonomous Sanctions List
+	sourceAU     = "AU"     // Australian DFAT Consolidated List
+	sourceUN     = "UN"     // UN Security Council Consolidated List
+	sourceCustom = "CUSTOM" // Custom (private) list from CUSTOM_LIST_FILE
+)
+
+var knownSources = []string{sourceSDN, sourceSSI, sourceDPL, sourceEL, sourceISN, sourceCA, sourceAU, sourceUN, sourceCustom}
+
+// sourceSet is the set of lists a search should cover. A nil sourceSet includes every list.
+type sourceSet map[string]bool
+
+func (s sourceSet) includes(source string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	return s[source]
+}
+
+// readSources parses the comma separated ?sources query parameter. Values are case-insensitive
+// and an error is returned for any unknown list.
+func readSources(u *url.URL) (sourceSet, error) {
+	raw := strings.TrimSpace(u.Query().Get("sources"))
+	if raw == "" {
+		return nil, nil
+	}
+	out := make(sourceSet)
+	for _, v := range strings.Split(raw, ",") {
+		v = strings.ToUpper(strings.TrimSpace(v))
+		if v == "" {
+			continue
+		}
+		if !isKnownSource(v) {
+			return nil, fmt.Errorf("unknown source %q, expected one of %s", v, strings.Join(knownSources, ", "))
+		}
+		out[v] = true
+	}
+	return out, nil
+}
+
+func isKnownSource(source string) bool {
+	for i := range knownSources {
+		if knownSources[i] == source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestSources__read(t *testing.T) {
+	u, _ := url.Parse("/search?q=foo")
+	sources, err := readSources(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sources.includes(sourceSDN) || !sources.includes(sourceISN) {
+		t.Errorf("expected all sources: %#v", sources)
+	}
+
+	u, _ = url.Parse("/search?q=foo&sources=sdn,%20ISN")
+	sources, err = readSources(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sources.includes(sourceSDN) || !sources.includes(sourceISN) {
+		t.Errorf("expected SDN and ISN: %#v", sources)
+	}
+	if sources.includes(sourceSSI) || sources.includes(sourceDPL) {
+		t.Errorf("unexpected sources: %#v", sources)
+	}
+
+	u, _ = url.Parse("/search?q=foo&sources=SDN,other")
+	if _, err := readSources(u); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	defaultStalenessGracePeriod = 1 * time.Hour
+)
+
+// getStalenessGracePeriod returns how long after the refresh interval has passed data is still
+// considered fresh. A single late refresh shouldn't cause /ready to fail.
+//
+// env is the value from an environmental variable
+func getStalenessGracePeriod(logger log.Logger, env string) time.Duration {
+	if env != "" {
+		if strings.EqualFold(env, "off") {
+			return 0 * time.Second
+		}
+		if dur, err := time.ParseDuration(env); err == nil && dur >= 0 {
+			logger.Log("main", fmt.Sprintf("Setting data staleness grace period to %v", dur))
+			return dur
+		}
+		logger.Log("main", fmt.Sprintf("invalid DATA_STALENESS_GRACE_PERIOD=%q, using default", env))
+	}
+	logger.Log("main", fmt.Sprintf("Setting data staleness grace period to %v (default)", defaultStalenessGracePeriod))
+	return defaultStalenessGracePeriod
+}
+
+// stalenessChecker reports when a searcher's data is older than the refresh interval
+// plus a grace period. Data which is overdue within the grace period is still fresh.
+type stalenessChecker struct {
+	searcher *searcher
+
+	interval time.Duration // zero when periodic refreshes are disabled
+	grace    time.Duration
+
+	now func() time.Time
+}
+
+func newStalenessChecker(s *searcher, interval, grace time.Duration) *stalenessChecker {
+	return &stalenessChecker{
+		searcher: s,
+		interval: interval,
+		grace:    grace,
+		now:      time.Now,
+	}
+}
+
+// stale returns the age of data and if it's exceeded interval + grace
+func (c *stalenessChecker) stale() (time.Duration, bool) {
+	c.searcher.RLock()
+	succeededAt := c.searcher.refreshSucceededAt
+	c.searcher.RUnlock()
+
+	if c.interval <= 0 || succeededAt.IsZero() {
+		return 0, false // refreshes are off or we haven't loaded data yet
+	}
+	age := c.now().Sub(succeededAt)
+	return age, age > c.interval+c.grace
+}
+
+// check is used as a readiness check on the admin server
+func (c *stalenessChecker) check() error {
+	if age, stale := c.stale(); stale {
+		return fmt.Errorf("data is stale: last refreshed %v ago (interval %v, grace period %v)", age, c.interval, c.grace)
+	}
+	return nil
+}
+
+// gauge returns a Prometheus metric reading 1 when data is stale and 0 otherwise
+func (c *stalenessChecker) gauge() prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "data_stale",
+		Help: "1 if data hasn't been refreshed within the refresh interval plus grace period",
+	}, func() float64 {
+		if _, stale := c.stale(); stale {
+			return 1
+		}
+		return 0
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStaleness__gracePeriod(t *testing.T) {
+	now := time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+	s := &searcher{refreshSucceededAt: now}
+
+	checker := newStalenessChecker(s, 12*time.Hour, 30*time.Minute)
+	checker.now = func() time.Time { return now }
+
+	cases := []struct {
+		elapsed time.Duration
+		stale   bool
+	}{
+		{0, false},
+		{12 * time.Hour, false},                // overdue, but within grace
+		{12*time.Hour + 30*time.Minute, false}, // exactly at the boundary
+		{12*time.Hour + 30*time.Minute + time.Nanosecond, true},
+		{24 * time.Hour, true},
+	}
+	for i := range cases {
+		checker.now = func() time.Time { return now.Add(cases[i].elapsed) }
+		if _, stale := checker.stale(); stale != cases[i].stale {
+			t.Errorf("elapsed=%v expected stale=%v", cases[i].elapsed, cases[i].stale)
+		}
+		if err := checker.check(); (err != nil) != cases[i].stale {
+			t.Errorf("elapsed=%v unexpected check: %v", cases[i].elapsed, err)
+		}
+
+		if v := testutil.ToFloat64(checker.gauge()); (v == 1) != cases[i].stale {
+			t.Errorf("elapsed=%v unexpected gauge value %v", cases[i].elapsed, v)
+		}
+	}
+}
+
+func TestStaleness__disabled(t *testing.T) {
+	now := time.Now()
+
+	// periodic refreshing is off
+	checker := newStalenessChecker(&searcher{refreshSucceededAt: now}, 0, time.Minute)
+	checker.now = func() time.Time { return now.Add(1000 * time.Hour) }
+	if _, stale := checker.stale(); stale {
+		t.Error("expected fresh data with refreshing disabled")
+	}
+
+	// data hasn't been loaded yet
+	checker = newStalenessChecker(&searcher{}, time.Hour, 0)
+	if err := checker.check(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStaleness__getGracePeriod(t *testing.T) {
+	logger := log.NewNopLogger()
+	if v := getStalenessGracePeriod(logger, ""); v != defaultStalenessGracePeriod {
+		t.Errorf("got %v", v)
+	}
+	if v := getStalenessGracePeriod(logger, "off"); v != 0 {
+		t.Errorf("got %v", v)
+	}
+	if v := getStalenessGracePeriod(logger, "15m"); v != 15*time.Minute {
+		t.Errorf("got %v", v)
+	}
+	if v := getStalenessGracePeriod(logger, "bogus"); v != defaultStalenessGracePeriod {
+		t.Errorf("got %v", v)
+	}
+}
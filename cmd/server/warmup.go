@@ -0,0 +1,107 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var errWarmupNotRun = errors.New("warm-up queries haven't run")
+
+// warmupQuery is a name search whose top SDN must be SDNID for the index to be ready
+type warmupQuery struct {
+	Name  string `json:"name"`
+	SDNID string `json:"sdnId"`
+}
+
+// readWarmupQueries reads a JSON array of warm-up queries from path (WARMUP_QUERIES_FILE)
+func readWarmupQueries(path string) ([]warmupQuery, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var queries []warmupQuery
+	if err := json.Unmarshal(bs, &queries); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("%s has no queries", path)
+	}
+	for i := range queries {
+		queries[i].Name, queries[i].SDNID = strings.TrimSpace(queries[i].Name), strings.TrimSpace(queries[i].SDNID)
+		if queries[i].Name == "" || queries[i].SDNID == "" {
+			return nil, fmt.Errorf("%s: query %d needs a name and sdnId", path, i)
+		}
+	}
+	return queries, nil
+}
+
+// warmupChecker runs the warm-up queries after each refresh and reindex, the admin server's
+// readiness check fails until they've all passed against the latest index.
+type warmupChecker struct {
+	searcher *searcher
+	queries  []warmupQuery
+
+	mu  sync.RWMutex
+	err error // from the last run
+}
+
+func newWarmupChecker(s *searcher, queries []warmupQuery) *warmupChecker {
+	return &warmupChecker{
+		searcher: s,
+		queries:  queries,
+		err:      errWarmupNotRun,
+	}
+}
+
+// run searches each query with the default search options and records which ones failed
+func (c *warmupChecker) run() error {
+	opts, _ := readSearchOptions(&url.URL{})
+
+	var failures []string
+	for _, q := range c.queries {
+		sdns := c.searcher.TopSDNs(1, q.Name, opts)
+		switch {
+		case len(sdns) == 0:
+			failures = append(failures, fmt.Sprintf("%q found no SDNs (expected %s)", q.Name, q.SDNID))
+		case sdns[0].EntityID != q.SDNID:
+			failures = append(failures, fmt.Sprintf("%q found SDN %s (expected %s)", q.Name, sdns[0].EntityID, q.SDNID))
+		}
+	}
+
+	var err error
+	if len(failures) > 0 {
+		err = fmt.Errorf("%d of %d warm-up queries failed: %s", len(failures), len(c.queries), strings.Join(failures, ", "))
+	}
+	if err != nil && c.searcher.logger != nil {
+		c.searcher.logger.Log("download", fmt.Sprintf("ERROR: %v", err))
+	}
+
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+	return err
+}
+
+// check is used as a readiness check on the admin server
+func (c *warmupChecker) check() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.err
+}
+
+// setWarmup runs c against the current index and after every later refresh and reindex
+func (s *searcher) setWarmup(c *warmupChecker) error {
+	s.Lock()
+	s.warmup = c
+	s.Unlock()
+	return c.run()
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestWarmup__readQueries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "warmup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(t *testing.T, contents string) string {
+		t.Helper()
+		path := filepath.Join(dir, "warmup.json")
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	queries, err := readWarmupQueries(write(t, `[{"name": " nicolas maduro ", "sdnId": "22790"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 || queries[0].Name != "nicolas maduro" || queries[0].SDNID != "22790" {
+		t.Errorf("%#v", queries)
+	}
+
+	for _, contents := range []string{`[]`, `{"name": "maduro"}`, `[{"name": "maduro"}]`, `[{"sdnId": "22790"}]`} {
+		if _, err := readWarmupQueries(write(t, contents)); err == nil {
+			t.Errorf("%s: expected error", contents)
+		}
+	}
+	if _, err := readWarmupQueries(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestWarmup__brokenIndex(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "22790", SDNName: "MADURO MOROS, Nicolas", SDNType: "individual"},
+			{EntityID: "173", SDNName: "ANGLO-CARIBBEAN CO., LTD."},
+		}, nil, noLogPipeliner),
+		logger: log.NewNopLogger(),
+	}
+	c := newWarmupChecker(s, []warmupQuery{
+		{Name: "nicolas maduro", SDNID: "22790"},
+		{Name: "anglo caribbean", SDNID: "173"},
+	})
+	if err := c.check(); err != errWarmupNotRun {
+		t.Errorf("expected not ready before running: %v", err)
+	}
+	if err := s.setWarmup(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.check(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// an index whose names weren't normalized can't find Maduro
+	s.SDNs[0].name = s.SDNs[0].SDNName
+	if err := c.run(); err == nil {
+		t.Fatal("expected error")
+	}
+	err := c.check()
+	if err == nil || !strings.Contains(err.Error(), `1 of 2 warm-up queries failed: "nicolas maduro" found SDN 173 (expected 22790)`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	s.SDNs = nil
+	c.run()
+	if err := c.check(); err == nil || !strings.Contains(err.Error(), "found no SDNs") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWarmup__refresh(t *testing.T) {
+	dir := copyTestData(t)
+	defer os.RemoveAll(dir)
+
+	s := &searcher{
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	c := newWarmupChecker(s, []warmupQuery{{Name: "anglo caribbean", SDNID: "173"}})
+	if err := s.setWarmup(c); err == nil {
+		t.Fatal("expected error against an empty index")
+	}
+
+	// the queries are run after refreshing
+	if _, err := s.refreshData(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.check(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// and after reindexing a list
+	c.queries = append(c.queries, warmupQuery{Name: "anglo caribbean", SDNID: "36"})
+	if _, err := s.reindexSource(dir, sourceSDN); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.check(); err == nil {
+		t.Error("expected error")
+	}
+}
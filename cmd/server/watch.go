@@ -8,6 +8,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/moov-io/base"
@@ -33,6 +34,9 @@ func getWatchID(w http.ResponseWriter, r *http.Request) string {
 type watchRequest struct {
 	AuthToken string `json:"authToken"`
 	Webhook   string `json:"webhook"`
+
+	// Webhooks are called along with Webhook, each with its own authToken
+	Webhooks []watchWebhook `json:"webhooks"`
 }
 
 // watchRepository holds information about each company and/or customer that another service wants notifications
@@ -52,6 +56,16 @@ type watchRepository interface {
 	addCustomerNameWatch(name string, webhook string, authToken string) (string, error)
 	removeCustomerWatch(customerID string, watchID string) error
 	removeCustomerNameWatch(watchID string) error
+
+	// listWatches returns a page of watches of one type (or every type when empty) in the
+	// order they were created
+	listWatches(watchType string, limit, offset int) ([]watchListing, error)
+
+	// removeWatch deletes a watch of any type, false is returned when watchID wasn't found
+	removeWatch(watchID string) (bool, error)
+
+	// addWatchWebhooks stores additional webhooks of a watch
+	addWatchWebhooks(watchID string, hooks []watchWebhook) error
 }
 
 type sqliteWatchRepository struct {
@@ -211,12 +225,102 @@ func (r *sqliteWatchRepository) removeCustomerNameWatch(watchID string) error {
 	return err
 }
 
+const (
+	watchTypeCompany      = "company"
+	watchTypeCompanyName  = "companyName"
+	watchTypeCustomer     = "customer"
+	watchTypeCustomerName = "customerName"
+)
+
+// watchTables are the table (and column holding the watched ID or name) of each type of watch
+var watchTables = []struct {
+	watchType, table, column string
+}{
+	{watchTypeCompany, "company_watches", "company_id"},
+	{watchTypeCompanyName, "company_name_watches", "name"},
+	{watchTypeCustomer, "customer_watches", "customer_id"},
+	{watchTypeCustomerName, "customer_name_watches", "name"},
+}
+
+// watchListing is a watch of any type, the authToken is never listed
+type watchListing struct {
+	WatchID    string    `json:"watchID"`
+	Type       string    `json:"type"`
+	CompanyID  string    `json:"companyID,omitempty"`
+	CustomerID string    `json:"customerID,omitempty"`
+	Name       string    `json:"name,omitempty"`
+	Webhook    string    `json:"webhook"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func (r *sqliteWatchRepository) listWatches(watchType string, limit, offset int) ([]watchListing, error) {
+	// every table is merged by created_at, so the page is read from all of them at once
+	var selects []string
+	for _, t := range watchTables {
+		if watchType != "" && watchType != t.watchType {
+			continue
+		}
+		selects = append(selects, `select id, '`+t.watchType+`' as watch_type, `+t.column+` as watched, webhook, created_at from `+t.table+` where deleted_at is null`)
+	}
+	query := `select id, watch_type, watched, webhook, created_at from (` + strings.Join(selects, ` union all `) + `) as watches order by created_at asc, id asc limit ? offset ?`
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []watchListing
+	for rows.Next() {
+		var value string
+		var w watchListing
+		if err := rows.Scan(&w.WatchID, &w.Type, &value, &w.Webhook, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		switch w.Type {
+		case watchTypeCompany:
+			w.CompanyID = value
+		case watchTypeCustomer:
+			w.CustomerID = value
+		default:
+			w.Name = value
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteWatchRepository) removeWatch(watchID string) (bool, error) {
+	if watchID == "" {
+		return false, errNoWatchID
+	}
+	for _, t := range watchTables {
+		query := `update ` + t.table + ` set deleted_at = ? where id = ? and deleted_at is null`
+		res, err := r.db.Exec(query, time.Now(), watchID)
+		if err != nil {
+			return false, err
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 type watch struct {
 	id                       string
 	customerID, customerName string
 	companyID, companyName   string
 	webhook                  string
 	authToken                string
+
+	// webhooks are additional webhooks of the watch (see targets)
+	webhooks []watchWebhook
 }
 
 type watchCursor struct {
@@ -267,6 +371,9 @@ func (cur *watchCursor) Next() ([]watch, error) {
 	}
 	watches = append(watches, customerNameWatches...)
 
+	if err := cur.addWebhooks(watches); err != nil {
+		cur.logger.Log("watchCursor", "problem reading watch webhooks", "error", err)
+	}
 	return watches, nil
 }
 
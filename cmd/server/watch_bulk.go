@@ -0,0 +1,230 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+var (
+	// maxBulkWatches is the most watches which can be created or removed in one request
+	maxBulkWatches = 1000
+
+	// defaultWatchesLimit and maxWatchesLimit are the page sizes of GET /ofac/watches
+	defaultWatchesLimit, maxWatchesLimit = 100, 1000
+)
+
+func addWatchRoutes(logger log.Logger, r *mux.Router, watchRepo watchRepository) {
+	r.Methods("GET").Path("/ofac/watches").HandlerFunc(listWatches(logger, watchRepo))
+	r.Methods("POST").Path("/ofac/watches").HandlerFunc(addWatches(logger, watchRepo))
+	r.Methods("DELETE").Path("/ofac/watches").HandlerFunc(removeWatches(logger, watchRepo))
+}
+
+// bulkWatchRequest is one watch to create, ID is a company or customer ID and Name is
+// used by name watches
+type bulkWatchRequest struct {
+	Type      string `json:"type"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Webhook   string `json:"webhook"`
+	AuthToken string `json:"authToken"`
+
+	Webhooks []watchWebhook `json:"webhooks"`
+}
+
+// bulkWatchResult is the outcome of one bulkWatchRequest, which has either a WatchID or Error
+type bulkWatchResult struct {
+	Index   int    `json:"index"`
+	WatchID string `json:"watchID,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// addWatch validates and stores a watch the same way the single watch endpoints do
+func addWatch(repo watchRepository, req bulkWatchRequest) (string, error) {
+	if req.AuthToken == "" {
+		return "", errNoAuthToken
+	}
+	webhook, err := validateWebhook(req.Webhook)
+	if err != nil {
+		return "", err
+	}
+	hooks, err := validateWatchWebhooks(req.Webhooks)
+	if err != nil {
+		return "", err
+	}
+	return addWatchAndWebhooks(repo, hooks, func() (string, error) {
+		return createWatch(repo, req, webhook)
+	})
+}
+
+func createWatch(repo watchRepository, req bulkWatchRequest, webhook string) (string, error) {
+	switch req.Type {
+	case watchTypeCompany:
+		if req.ID == "" {
+			return "", errNoCompanyID
+		}
+		return repo.addCompanyWatch(req.ID, watchRequest{AuthToken: req.AuthToken, Webhook: webhook})
+	case watchTypeCustomer:
+		if req.ID == "" {
+			return "", errNoCustomerID
+		}
+		return repo.addCustomerWatch(req.ID, watchRequest{AuthToken: req.AuthToken, Webhook: webhook})
+	case watchTypeCompanyName, watchTypeCustomerName:
+		if req.Name == "" {
+			return "", errNoNameParam
+		}
+		if req.Type == watchTypeCompanyName {
+			return repo.addCompanyNameWatch(req.Name, webhook, req.AuthToken)
+		}
+		return repo.addCustomerNameWatch(req.Name, webhook, req.AuthToken)
+	}
+	return "", fmt.Errorf("unknown watch type %q", req.Type)
+}
+
+func addWatches(logger log.Logger, repo watchRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		var reqs []bulkWatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		if len(reqs) > maxBulkWatches {
+			moovhttp.Problem(w, fmt.Errorf("%d watches is more than the limit of %d", len(reqs), maxBulkWatches))
+			return
+		}
+
+		// each watch is created on its own, so one invalid watch doesn't fail the others
+		results := make([]bulkWatchResult, len(reqs))
+		var failed int
+		for i := range reqs {
+			results[i].Index = i
+			watchID, err := addWatch(repo, reqs[i])
+			if err != nil {
+				results[i].Error = err.Error()
+				failed++
+				continue
+			}
+			results[i].WatchID = watchID
+		}
+
+		requestID, userID := moovhttp.GetRequestID(r), moovhttp.GetUserID(r)
+		logger.Log("watches", fmt.Sprintf("added %d watches, %d failed", len(reqs)-failed, failed), "requestID", requestID, "userID", userID)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(struct {
+			Watches []bulkWatchResult `json:"watches"`
+		}{results})
+	}
+}
+
+func listWatches(logger log.Logger, repo watchRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		q := r.URL.Query()
+		watchType := q.Get("type")
+		if watchType != "" {
+			known := false
+			for _, t := range watchTables {
+				known = known || t.watchType == watchType
+			}
+			if !known {
+				moovhttp.Problem(w, fmt.Errorf("unknown watch type %q", watchType))
+				return
+			}
+		}
+		limit, offset := defaultWatchesLimit, 0
+		if v := q.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				moovhttp.Problem(w, fmt.Errorf("invalid limit %q", v))
+				return
+			}
+			limit = n
+		}
+		if limit > maxWatchesLimit {
+			limit = maxWatchesLimit
+		}
+		if v := q.Get("offset"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				moovhttp.Problem(w, fmt.Errorf("invalid offset %q", v))
+				return
+			}
+			offset = n
+		}
+
+		watches, err := repo.listWatches(watchType, limit, offset)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		if watches == nil {
+			watches = []watchListing{}
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(struct {
+			Watches []watchListing `json:"watches"`
+			Limit   int            `json:"limit"`
+			Offset  int            `json:"offset"`
+		}{watches, limit, offset})
+	}
+}
+
+func removeWatches(logger log.Logger, repo watchRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		var watchIDs []string
+		if err := json.NewDecoder(r.Body).Decode(&watchIDs); err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		if len(watchIDs) > maxBulkWatches {
+			moovhttp.Problem(w, fmt.Errorf("%d watches is more than the limit of %d", len(watchIDs), maxBulkWatches))
+			return
+		}
+
+		resp := struct {
+			Removed  []string `json:"removed"`
+			NotFound []string `json:"notFound"`
+		}{
+			Removed:  []string{},
+			NotFound: []string{},
+		}
+		for _, watchID := range watchIDs {
+			found, err := repo.removeWatch(watchID)
+			if err != nil && err != errNoWatchID {
+				moovhttp.Problem(w, err)
+				return
+			}
+			if found {
+				resp.Removed = append(resp.Removed, watchID)
+			} else {
+				resp.NotFound = append(resp.NotFound, watchID)
+			}
+		}
+
+		requestID, userID := moovhttp.GetRequestID(r), moovhttp.GetUserID(r)
+		logger.Log("watches", fmt.Sprintf("removed %d watches", len(resp.Removed)), "requestID", requestID, "userID", userID)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/watchman/internal/database"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+type bulkWatchesResponse struct {
+	Watches []bulkWatchResult `json:"watches"`
+}
+
+type listWatchesResponse struct {
+	Watches []watchListing `json:"watches"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+}
+
+func serveWatches(t *testing.T, repo watchRepository, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("x-user-id", "test")
+	req.Header.Set("x-request-id", base.ID())
+
+	router := mux.NewRouter()
+	addWatchRoutes(log.NewNopLogger(), router, repo)
+	router.ServeHTTP(w, req)
+	w.Flush()
+	return w
+}
+
+func TestWatches__bulkCreate(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *sqliteWatchRepository) {
+		body := `[
+  {"type": "company", "id": "c1", "webhook": "https://moov.io", "authToken": "foo"},
+  {"type": "customerName", "name": "jane doe", "webhook": "https://moov.io", "authToken": "foo"},
+  {"type": "customer", "id": "c2", "webhook": "https://moov.io"},
+  {"type": "companyName", "webhook": "https://moov.io", "authToken": "foo"},
+  {"type": "other", "id": "c3", "webhook": "https://moov.io", "authToken": "foo"},
+  {"type": "company", "webhook": "https://moov.io", "authToken": "foo"},
+  {"type": "customer", "id": "", "webhook": "https://moov.io", "authToken": "foo"}
+]`
+		w := serveWatches(t, repo, "POST", "/ofac/watches", body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp bulkWatchesResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if n := len(resp.Watches); n != 7 {
+			t.Fatalf("got %d results: %#v", n, resp.Watches)
+		}
+		for i, res := range resp.Watches {
+			if res.Index != i {
+				t.Errorf("#%d has index %d", i, res.Index)
+			}
+			if failed := i >= 2; failed != (res.Error != "") || failed == (res.WatchID != "") {
+				t.Errorf("#%d unexpected result: %#v", i, res)
+			}
+		}
+		if err := resp.Watches[2].Error; err != errNoAuthToken.Error() {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := resp.Watches[3].Error; err != errNoNameParam.Error() {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := resp.Watches[5].Error; err != errNoCompanyID.Error() {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if err := resp.Watches[6].Error; err != errNoCustomerID.Error() {
+			t.Errorf("unexpected error: %s", err)
+		}
+
+		// only the valid watches were stored
+		watches, err := repo.listWatches("", 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(watches) != 2 {
+			t.Fatalf("got %d watches: %#v", len(watches), watches)
+		}
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, &sqliteWatchRepository{sqliteDB.DB, log.NewNopLogger()})
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, &sqliteWatchRepository{mysqlDB.DB, log.NewNopLogger()})
+}
+
+func TestWatches__bulkCreateErr(t *testing.T) {
+	repo := createTestWatchRepository(t)
+	defer repo.close()
+
+	if w := serveWatches(t, repo, "POST", "/ofac/watches", `{"type": "company"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("bogus status code: %d", w.Code)
+	}
+}
+
+func TestWatches__list(t *testing.T) {
+	t.Parallel()
+
+	check := func(t *testing.T, repo *sqliteWatchRepository) {
+		for i := 0; i < 3; i++ {
+			if _, err := repo.addCompanyWatch(base.ID(), watchRequest{AuthToken: "foo", Webhook: "https://moov.io"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i := 0; i < 2; i++ {
+			if _, err := repo.addCustomerNameWatch("jane doe", "https://moov.io", "foo"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		list := func(query string) listWatchesResponse {
+			t.Helper()
+			w := serveWatches(t, repo, "GET", "/ofac/watches"+query, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: bogus status code: %d: %s", query, w.Code, w.Body.String())
+			}
+			var resp listWatchesResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			return resp
+		}
+
+		// page through every watch
+		seen := make(map[string]bool)
+		for offset, size := range map[string]int{"0": 2, "2": 2, "4": 1, "6": 0} {
+			resp := list("?limit=2&offset=" + offset)
+			if n := len(resp.Watches); n != size {
+				t.Errorf("offset=%s got %d watches", offset, n)
+			}
+			for _, watch := range resp.Watches {
+				if seen[watch.WatchID] {
+					t.Errorf("watch %s listed twice", watch.WatchID)
+				}
+				seen[watch.WatchID] = true
+			}
+		}
+		if len(seen) != 5 {
+			t.Errorf("listed %d watches", len(seen))
+		}
+
+		// filter by type
+		resp := list("?type=customerName")
+		if len(resp.Watches) != 2 {
+			t.Fatalf("got %d watches", len(resp.Watches))
+		}
+		for _, watch := range resp.Watches {
+			if watch.Type != watchTypeCustomerName || watch.Name != "jane doe" || watch.Webhook != "https://moov.io" {
+				t.Errorf("unexpected watch: %#v", watch)
+			}
+		}
+		if resp := list("?type=company&limit=5000"); len(resp.Watches) != 3 || resp.Limit != maxWatchesLimit {
+			t.Errorf("got %d watches with limit=%d", len(resp.Watches), resp.Limit)
+		}
+	}
+
+	// SQLite tests
+	sqliteDB := database.CreateTestSqliteDB(t)
+	defer sqliteDB.Close()
+	check(t, &sqliteWatchRepository{sqliteDB.DB, log.NewNopLogger()})
+
+	// MySQL tests
+	mysqlDB := database.CreateTestMySQLDB(t)
+	defer mysqlDB.Close()
+	check(t, &sqliteWatchRepository{mysqlDB.DB, log.NewNopLogger()})
+}
+
+func TestWatches__listErr(t *testing.T) {
+	repo := createTestWatchRepository(t)
+	defer repo.close()
+
+	for _, query := range []string{"?type=other", "?limit=0", "?limit=foo", "?offset=-1"} {
+		if w := serveWatches(t, repo, "GET", "/ofac/watches"+query, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: bogus status code: %d", query, w.Code)
+		}
+	}
+
+	// rows which can't be read fail the listing rather than being skipped
+	if _, err := repo.db.Exec(`insert into company_watches (id, company_id, created_at) values ('broken', 'c1', ?);`, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if watches, err := repo.listWatches("", 10, 0); err == nil {
+		t.Errorf("expected error, got %#v", watches)
+	}
+}
+
+func TestWatches__bulkDelete(t *testing.T) {
+	repo := createTestWatchRepository(t)
+	defer repo.close()
+
+	companyWatchID, err := repo.addCompanyWatch(base.ID(), watchRequest{AuthToken: "foo", Webhook: "https://moov.io"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nameWatchID, err := repo.addCustomerNameWatch("jane doe", "https://moov.io", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `["` + companyWatchID + `", "` + nameWatchID + `", "missing"]`
+	w := serveWatches(t, repo, "DELETE", "/ofac/watches", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("bogus status code: %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Removed  []string `json:"removed"`
+		NotFound []string `json:"notFound"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Removed) != 2 || len(resp.NotFound) != 1 || resp.NotFound[0] != "missing" {
+		t.Errorf("unexpected response: %#v", resp)
+	}
+
+	watches, err := repo.listWatches("", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(watches) != 0 {
+		t.Errorf("got %d watches after removal", len(watches))
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxWatchWebhooks is the most additional webhooks a watch can have
+var maxWatchWebhooks = 10
+
+// watchWebhook is an additional webhook of a watch (e.g. case management and a SIEM), which is
+// called with its own authToken and retried on its own
+type watchWebhook struct {
+	Webhook   string `json:"webhook"`
+	AuthToken string `json:"authToken"`
+}
+
+// validateWatchWebhooks checks each additional webhook like the watch's webhook is checked and
+// returns them with normalized URLs
+func validateWatchWebhooks(hooks []watchWebhook) ([]watchWebhook, error) {
+	if len(hooks) > maxWatchWebhooks {
+		return nil, fmt.Errorf("%d webhooks is more than the limit of %d", len(hooks), maxWatchWebhooks)
+	}
+	out := make([]watchWebhook, 0, len(hooks))
+	for i := range hooks {
+		if hooks[i].AuthToken == "" {
+			return nil, fmt.Errorf("webhook %d: %v", i, errNoAuthToken)
+		}
+		webhook, err := validateWebhook(hooks[i].Webhook)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %d: %v", i, err)
+		}
+		out = append(out, watchWebhook{Webhook: webhook, AuthToken: hooks[i].AuthToken})
+	}
+	return out, nil
+}
+
+// targets returns every webhook of the watch, its own webhook first
+func (w watch) targets() []watchWebhook {
+	out := []watchWebhook{{Webhook: w.webhook, AuthToken: w.authToken}}
+	return append(out, w.webhooks...)
+}
+
+func (r *sqliteWatchRepository) addWatchWebhooks(watchID string, hooks []watchWebhook) error {
+	if watchID == "" {
+		return errNoWatchID
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	// every webhook is stored or none are
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("addWatchWebhooks: begin: %v", err)
+	}
+	query := `insert into watch_webhooks (watch_id, webhook, auth_token, created_at) values (?, ?, ?, ?);`
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return fmt.Errorf("addWatchWebhooks: prepare error=%v rollback=%v", err, tx.Rollback())
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for i := range hooks {
+		if _, err := stmt.Exec(watchID, hooks[i].Webhook, hooks[i].AuthToken, now); err != nil {
+			return fmt.Errorf("addWatchWebhooks: exec error=%v rollback=%v", err, tx.Rollback())
+		}
+	}
+	return tx.Commit()
+}
+
+// addWatchAndWebhooks stores the webhooks of the watch created by add. The watch is removed
+// again when its webhooks can't be stored, so a failed request doesn't leave it behind.
+func addWatchAndWebhooks(repo watchRepository, hooks []watchWebhook, add func() (string, error)) (string, error) {
+	watchID, err := add()
+	if err != nil {
+		return "", err
+	}
+	if err := repo.addWatchWebhooks(watchID, hooks); err != nil {
+		if _, rmErr := repo.removeWatch(watchID); rmErr != nil {
+			return "", fmt.Errorf("%v: removing watch %s: %v", err, watchID, rmErr)
+		}
+		return "", err
+	}
+	return watchID, nil
+}
+
+// addWebhooks reads the additional webhooks of each watch in a batch
+func (cur *watchCursor) addWebhooks(watches []watch) error {
+	if len(watches) == 0 {
+		return nil
+	}
+	query := `select webhook, auth_token from watch_webhooks where watch_id = ? and deleted_at is null order by created_at asc`
+	stmt, err := cur.db.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for i := range watches {
+		rows, err := stmt.Query(watches[i].id)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var hook watchWebhook
+			if err := rows.Scan(&hook.Webhook, &hook.AuthToken); err == nil {
+				watches[i].webhooks = append(watches[i].webhooks, hook)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/watchman/internal/database"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestWatchWebhooks__validate(t *testing.T) {
+	hooks, err := validateWatchWebhooks([]watchWebhook{
+		{Webhook: "https://cases.example.com/hook", AuthToken: "a"},
+		{Webhook: "https://siem.example.com/hook", AuthToken: "b"},
+	})
+	if err != nil || len(hooks) != 2 {
+		t.Fatalf("hooks=%#v err=%v", hooks, err)
+	}
+	if hooks, err := validateWatchWebhooks(nil); err != nil || len(hooks) != 0 {
+		t.Errorf("hooks=%#v err=%v", hooks, err)
+	}
+
+	if _, err := validateWatchWebhooks([]watchWebhook{{Webhook: "http://siem.example.com/hook", AuthToken: "b"}}); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := validateWatchWebhooks([]watchWebhook{{Webhook: "https://siem.example.com/hook"}}); err == nil {
+		t.Error("expected error")
+	}
+	if _, err := validateWatchWebhooks(make([]watchWebhook, maxWatchWebhooks+1)); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestWatchWebhooks__cursor(t *testing.T) {
+	repo := createTestWatchRepository(t)
+	defer repo.close()
+
+	watchID, err := repo.addCompanyWatch(base.ID(), watchRequest{Webhook: "https://cases.example.com/hook", AuthToken: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.addWatchWebhooks(watchID, []watchWebhook{{Webhook: "https://siem.example.com/hook", AuthToken: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	watches, err := repo.getWatchesCursor(log.NewNopLogger(), 4).Next()
+	if err != nil || len(watches) != 1 {
+		t.Fatalf("watches=%#v err=%v", watches, err)
+	}
+	targets := watches[0].targets()
+	if len(targets) != 2 || targets[0].Webhook != "https://cases.example.com/hook" || targets[1].Webhook != "https://siem.example.com/hook" || targets[1].AuthToken != "b" {
+		t.Errorf("%#v", targets)
+	}
+}
+
+func TestWatchWebhooks__rollback(t *testing.T) {
+	repo := createTestWatchRepository(t)
+	defer repo.close()
+
+	// storing the webhooks fails
+	if _, err := repo.db.Exec(`drop table watch_webhooks;`); err != nil {
+		t.Fatal(err)
+	}
+	req := bulkWatchRequest{
+		Type:      watchTypeCompany,
+		ID:        base.ID(),
+		Webhook:   "https://cases.example.com/hook",
+		AuthToken: "a",
+		Webhooks:  []watchWebhook{{Webhook: "https://siem.example.com/hook", AuthToken: "b"}},
+	}
+	if watchID, err := addWatch(repo, req); err == nil {
+		t.Fatalf("expected error, created watch %s", watchID)
+	}
+
+	// so the watch isn't left behind
+	watches, err := repo.listWatches("", 10, 0)
+	if err != nil || len(watches) != 0 {
+		t.Errorf("watches=%#v err=%v", watches, err)
+	}
+}
+
+// hostTransport fails requests to failingHost and counts the requests to each host
+type hostTransport struct {
+	failingHost string
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (ht *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ht.mu.Lock()
+	ht.calls[req.URL.Host]++
+	ht.mu.Unlock()
+
+	status := http.StatusOK
+	if req.URL.Host == ht.failingHost {
+		status = http.StatusServiceUnavailable
+	}
+	return &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+func TestWatchWebhooks__deliver(t *testing.T) {
+	transport := &hostTransport{failingHost: "siem.example.com", calls: make(map[string]int)}
+	prev := webhookHTTPClient.Transport
+	webhookHTTPClient.Transport = transport
+	defer func() { webhookHTTPClient.Transport = prev }()
+
+	defer func(attempts int) { webhookMaxAttempts = attempts }(webhookMaxAttempts)
+	defer func(delay time.Duration) { webhookRetryDelay = delay }(webhookRetryDelay)
+	webhookMaxAttempts, webhookRetryDelay = 3, 0
+
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	repo := &sqliteWebhookRepository{db.DB}
+
+	w := watch{
+		id:        base.ID(),
+		webhook:   "https://siem.example.com/hook",
+		authToken: "a",
+		webhooks:  []watchWebhook{{Webhook: "https://cases.example.com/hook", AuthToken: "b"}},
+	}
+	var wg sync.WaitGroup
+	queueWebhooks(log.NewNopLogger(), w, []byte(`{"id": "cust"}`), repo, &wg)
+	wg.Wait()
+
+	// the failing webhook is retried without blocking the other
+	if transport.calls["siem.example.com"] != 3 || transport.calls["cases.example.com"] != 1 {
+		t.Errorf("calls: %#v", transport.calls)
+	}
+
+	// each attempt is recorded for its webhook
+	count := func(query string, args ...interface{}) int {
+		t.Helper()
+		var n int
+		if err := db.DB.QueryRow(query, args...).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := count(`select count(*) from webhook_stats where watch_id = ? and webhook = ? and status = 503`, w.id, "https://siem.example.com/hook"); n != 3 {
+		t.Errorf("recorded %d failed attempts", n)
+	}
+	if n := count(`select count(*) from webhook_stats where watch_id = ? and webhook = ? and status = 200`, w.id, "https://cases.example.com/hook"); n != 1 {
+		t.Errorf("recorded %d successful attempts", n)
+	}
+
+	// only the failing webhook is a dead letter
+	var webhook, failure string
+	var attempts, status int
+	row := db.DB.QueryRow(`select webhook, attempts, status, error from webhook_dead_letters where watch_id = ?`, w.id)
+	if err := row.Scan(&webhook, &attempts, &status, &failure); err != nil {
+		t.Fatal(err)
+	}
+	if webhook != "https://siem.example.com/hook" || attempts != 3 || status != 503 || !strings.Contains(failure, "503") {
+		t.Errorf("webhook=%s attempts=%d status=%d error=%q", webhook, attempts, status, failure)
+	}
+	if n := count(`select count(*) from webhook_dead_letters where watch_id = ?`, w.id); n != 1 {
+		t.Errorf("%d dead letters", n)
+	}
+}
@@ -10,15 +10,21 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
-	"go4.org/syncutil"
+	"github.com/moov-io/watchman/pkg/webhook"
+
+	"github.com/go-kit/kit/log"
 )
 
 var (
-	// webhookGate is a goroutine-safe throttler designed to only allow N
-	// goroutines to run at any given time.
-	webhookGate = syncutil.NewGate(10)
+	// webhookMaxAttempts is how many times each webhook of a watch is called before it's
+	// recorded as a dead letter, it's read from WEBHOOK_MAX_ATTEMPTS
+	webhookMaxAttempts = 3
+
+	// webhookRetryDelay is how long the first retry waits, later retries wait longer
+	webhookRetryDelay = 5 * time.Second
 
 	webhookHTTPClient = &http.Client{
 		Timeout: 10 * time.Second,
@@ -37,26 +43,24 @@ var (
 )
 
 // callWebhook will take `body` as JSON and make a POST request to the provided webhook url.
+// Requests with an authToken are signed with it, see pkg/webhook for verifying signatures.
 // Returned is the HTTP status code.
-func callWebhook(watchID string, body *bytes.Buffer, webhook string, authToken string) (int, error) {
-	webhook, err := validateWebhook(webhook)
+func callWebhook(watchID string, body *bytes.Buffer, webhookURL string, authToken string) (int, error) {
+	webhookURL, err := validateWebhook(webhookURL)
 	if err != nil {
 		return 0, err
 	}
 
 	// Setup HTTP request
-	req, err := http.NewRequest("POST", webhook, body)
+	req, err := http.NewRequest("POST", webhookURL, body)
 	if err != nil {
 		return 0, fmt.Errorf("unknown error with watch %s: %v", watchID, err)
 	}
 	if authToken != "" {
 		req.Header.Set("Authorization", authToken)
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign(authToken, time.Now(), body.Bytes()))
 	}
 
-	// Guard HTTP calls in-flight
-	webhookGate.Start()
-	defer webhookGate.Done()
-
 	resp, err := webhookHTTPClient.Do(req)
 	if resp == nil || err != nil {
 		if resp == nil {
@@ -87,8 +91,84 @@ func validateWebhook(raw string) (string, error) {
 	return u.String(), nil
 }
 
+// queueWebhooks submits every webhook of the watch to webhookWorkers, so a failing webhook
+// doesn't hold up the others, and marks each done in wg. Each is retried on its own up to
+// webhookMaxAttempts times, every attempt is recorded and webhooks which never succeed are
+// recorded as dead letters.
+func queueWebhooks(logger log.Logger, w watch, body []byte, repo webhookRepository, wg *sync.WaitGroup) {
+	targets := w.targets()
+	wg.Add(len(targets))
+	for i := range targets {
+		target := targets[i]
+		webhookWorkers.submit(func() {
+			defer wg.Done()
+			deliverWebhook(logger, w.id, target, body, repo)
+		})
+	}
+}
+
+func deliverWebhook(logger log.Logger, watchID string, target watchWebhook, body []byte, repo webhookRepository) {
+	var status int
+	var err error
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt-1) * webhookRetryDelay)
+		}
+		now := time.Now()
+		status, err = callWebhook(watchID, bytes.NewBuffer(body), target.Webhook, target.AuthToken)
+		if rerr := repo.recordWebhook(watchID, target.Webhook, now, status); rerr != nil {
+			logger.Log("search", fmt.Errorf("async: problem writing watch (%s) webhook status: %v", watchID, rerr))
+		}
+		if err == nil {
+			recordDelivery(logger, watchID, repo, webhookDelivery{
+				Webhook:     target.Webhook,
+				AttemptedAt: now,
+				Status:      status,
+				Attempts:    attempt,
+				Outcome:     deliveryDelivered,
+			})
+			return
+		}
+		logger.Log("search", fmt.Errorf("async: watch (%s) webhook attempt %d of %d failed: %v", watchID, attempt, webhookMaxAttempts, err))
+	}
+	failedAt := time.Now()
+	if rerr := repo.recordDeadLetter(watchID, target.Webhook, failedAt, webhookMaxAttempts, status, err); rerr != nil {
+		logger.Log("search", fmt.Errorf("async: problem writing watch (%s) webhook dead letter: %v", watchID, rerr))
+	}
+	delivery := webhookDelivery{
+		Webhook:     target.Webhook,
+		AttemptedAt: failedAt,
+		Status:      status,
+		Attempts:    webhookMaxAttempts,
+		Outcome:     deliveryDeadLettered,
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	recordDelivery(logger, watchID, repo, delivery)
+}
+
+func recordDelivery(logger log.Logger, watchID string, repo webhookRepository, delivery webhookDelivery) {
+	if err := repo.recordDelivery(watchID, delivery); err != nil {
+		logger.Log("search", fmt.Errorf("async: problem writing watch (%s) webhook delivery: %v", watchID, err))
+	}
+}
+
 type webhookRepository interface {
-	recordWebhook(watchID string, attemptedAt time.Time, status int) error
+	// recordWebhook stores the status of an attempt to call one webhook of a watch
+	recordWebhook(watchID string, webhook string, attemptedAt time.Time, status int) error
+
+	// recordDeadLetter stores a webhook of a watch which failed on every attempt
+	recordDeadLetter(watchID string, webhook string, failedAt time.Time, attempts int, status int, err error) error
+
+	// recordDelivery stores the outcome of calling one webhook of a watch after every retry
+	recordDelivery(watchID string, delivery webhookDelivery) error
+
+	// getDeliveries returns the deliveries of a watch since a time, the latest first
+	getDeliveries(watchID string, since time.Time, limit int) ([]webhookDelivery, error)
+
+	// pruneDeliveries deletes the deliveries of every watch before a time
+	pruneDeliveries(before time.Time) (int64, error)
 }
 
 type sqliteWebhookRepository struct {
@@ -99,14 +179,30 @@ func (r *sqliteWebhookRepository) close() error {
 	return r.db.Close()
 }
 
-func (r *sqliteWebhookRepository) recordWebhook(watchID string, attemptedAt time.Time, status int) error {
-	query := `insert into webhook_stats (watch_id, attempted_at, status) values (?, ?, ?);`
+func (r *sqliteWebhookRepository) recordWebhook(watchID string, webhook string, attemptedAt time.Time, status int) error {
+	query := `insert into webhook_stats (watch_id, webhook, attempted_at, status) values (?, ?, ?, ?);`
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(watchID, webhook, attemptedAt, status)
+	return err
+}
+
+func (r *sqliteWebhookRepository) recordDeadLetter(watchID string, webhook string, failedAt time.Time, attempts int, status int, failure error) error {
+	query := `insert into webhook_dead_letters (watch_id, webhook, failed_at, attempts, status, error) values (?, ?, ?, ?, ?, ?);`
 	stmt, err := r.db.Prepare(query)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(watchID, attemptedAt, status)
+	var msg string
+	if failure != nil {
+		msg = failure.Error()
+	}
+	_, err = stmt.Exec(watchID, webhook, failedAt, attempts, status, msg)
 	return err
 }
@@ -0,0 +1,199 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+const (
+	// notifyEveryRefresh calls the webhooks of every watch after each refresh
+	notifyEveryRefresh = "every"
+
+	// notifyChanges only calls the webhooks of watches whose matched SDN changed in the refresh
+	notifyChanges = "changes"
+)
+
+var (
+	// webhookNotifications is when watches are notified, it's read from WEBHOOK_NOTIFICATIONS
+	webhookNotifications = notifyEveryRefresh
+
+	errWatchUnchanged = errors.New("matched SDN is unchanged")
+)
+
+// getWebhookNotifications reads when watches are notified, every or changes
+//
+// env is the value from an environmental variable
+func getWebhookNotifications(logger log.Logger, env string) string {
+	switch v := strings.ToLower(strings.TrimSpace(env)); v {
+	case "", notifyEveryRefresh:
+		return notifyEveryRefresh
+	case notifyChanges:
+		logger.Log("main", "Only notifying watches whose matched SDN changed in a refresh")
+		return v
+	}
+	logger.Log("main", fmt.Sprintf("invalid WEBHOOK_NOTIFICATIONS=%q, using default of %s", env, notifyEveryRefresh))
+	return notifyEveryRefresh
+}
+
+// sdnChange is how the details of an SDN changed in a refresh, it's sent to the webhooks of
+// watches matching the SDN
+type sdnChange struct {
+	EntityID string `json:"entityID"`
+
+	// Added is true when the SDN wasn't listed before the refresh
+	Added bool `json:"added,omitempty"`
+
+	Fields []sdnFieldChange `json:"fields,omitempty"`
+
+	AddedAltNames    []string `json:"addedAltNames,omitempty"`
+	RemovedAltNames  []string `json:"removedAltNames,omitempty"`
+	AddedAddresses   []string `json:"addedAddresses,omitempty"`
+	RemovedAddresses []string `json:"removedAddresses,omitempty"`
+}
+
+// sdnFieldChange is one field of an SDN which has a different value after the refresh
+type sdnFieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// sdnSnapshot is the parts of an SDN compared between refreshes
+type sdnSnapshot struct {
+	sdn       *ofac.SDN
+	altNames  []string
+	addresses []string
+}
+
+func snapshotSDNs(sdns []*SDN, adds []*Address, alts []*Alt, only map[string]bool) map[string]*sdnSnapshot {
+	out := make(map[string]*sdnSnapshot, len(sdns))
+	for i := range sdns {
+		if sdns[i] == nil || sdns[i].SDN == nil || (only != nil && !only[sdns[i].EntityID]) {
+			continue
+		}
+		out[sdns[i].EntityID] = &sdnSnapshot{sdn: sdns[i].SDN}
+	}
+	for i := range adds {
+		if adds[i] == nil || adds[i].Address == nil {
+			continue
+		}
+		if d, exists := out[adds[i].Address.EntityID]; exists {
+			d.addresses = append(d.addresses, formatAddress(adds[i].Address))
+		}
+	}
+	for i := range alts {
+		if alts[i] == nil || alts[i].AlternateIdentity == nil {
+			continue
+		}
+		if d, exists := out[alts[i].AlternateIdentity.EntityID]; exists {
+			d.altNames = append(d.altNames, alts[i].AlternateIdentity.AlternateName)
+		}
+	}
+	return out
+}
+
+func formatAddress(addr *ofac.Address) string {
+	var parts []string
+	for _, v := range []string{addr.Address, addr.CityStateProvincePostalCode, addr.Country} {
+		if v = strings.TrimSpace(v); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffSDNRecords returns the SDNs whose details differ between the records before and after a
+// refresh, by their EntityID. SDNs removed by the refresh aren't included. When only is non-nil
+// just those SDNs are compared, like the SDNs an OFAC delta changed.
+func diffSDNRecords(beforeSDNs []*SDN, beforeAdds []*Address, beforeAlts []*Alt, afterSDNs []*SDN, afterAdds []*Address, afterAlts []*Alt, only map[string]bool) map[string]*sdnChange {
+	if len(beforeSDNs) == 0 {
+		return nil // nothing was listed before the first refresh
+	}
+	before := snapshotSDNs(beforeSDNs, beforeAdds, beforeAlts, only)
+	after := snapshotSDNs(afterSDNs, afterAdds, afterAlts, only)
+
+	out := make(map[string]*sdnChange)
+	for entityID, next := range after {
+		prev, exists := before[entityID]
+		if !exists {
+			out[entityID] = &sdnChange{EntityID: entityID, Added: true}
+			continue
+		}
+		change := &sdnChange{
+			EntityID: entityID,
+			Fields:   diffSDNFields(prev.sdn, next.sdn),
+		}
+		change.AddedAltNames, change.RemovedAltNames = diffValues(prev.altNames, next.altNames)
+		change.AddedAddresses, change.RemovedAddresses = diffValues(prev.addresses, next.addresses)
+		if len(change.Fields)+len(change.AddedAltNames)+len(change.RemovedAltNames)+len(change.AddedAddresses)+len(change.RemovedAddresses) > 0 {
+			out[entityID] = change
+		}
+	}
+	return out
+}
+
+func diffSDNFields(prev, next *ofac.SDN) []sdnFieldChange {
+	fields := []struct {
+		name          string
+		before, after string
+	}{
+		{"sdnName", prev.SDNName, next.SDNName},
+		{"sdnType", prev.SDNType, next.SDNType},
+		{"program", strings.Join(prev.Programs, "; "), strings.Join(next.Programs, "; ")},
+		{"title", prev.Title, next.Title},
+		{"callSign", prev.CallSign, next.CallSign},
+		{"vesselType", prev.VesselType, next.VesselType},
+		{"tonnage", prev.Tonnage, next.Tonnage},
+		{"grossRegisteredTonnage", prev.GrossRegisteredTonnage, next.GrossRegisteredTonnage},
+		{"vesselFlag", prev.VesselFlag, next.VesselFlag},
+		{"vesselOwner", prev.VesselOwner, next.VesselOwner},
+		{"remarks", prev.Remarks, next.Remarks},
+	}
+	var out []sdnFieldChange
+	for _, f := range fields {
+		if f.before != f.after {
+			out = append(out, sdnFieldChange{Field: f.name, Before: f.before, After: f.after})
+		}
+	}
+	return out
+}
+
+// diffValues returns the values only in after and only in before, in their order
+func diffValues(before, after []string) (added []string, removed []string) {
+	count := make(map[string]int, len(before))
+	for _, v := range before {
+		count[v]++
+	}
+	for _, v := range after {
+		if count[v] > 0 {
+			count[v]--
+			continue
+		}
+		added = append(added, v)
+	}
+	for _, v := range before {
+		if count[v] > 0 {
+			count[v]--
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
+
+// notifies returns an error when webhooks of a watch matching the SDN aren't called, which is
+// when only changes are notified and the SDN isn't in changes
+func notifies(changes map[string]*sdnChange, entityID string) error {
+	if webhookNotifications == notifyChanges && changes[entityID] == nil {
+		return errWatchUnchanged
+	}
+	return nil
+}
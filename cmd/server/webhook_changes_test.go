@@ -0,0 +1,260 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/moov-io/watchman/internal/database"
+	"github.com/moov-io/watchman/pkg/ofac"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestWebhookChanges__read(t *testing.T) {
+	for str, expected := range map[string]string{"": notifyEveryRefresh, "every": notifyEveryRefresh, " Changes ": notifyChanges, "other": notifyEveryRefresh} {
+		if v := getWebhookNotifications(log.NewNopLogger(), str); v != expected {
+			t.Errorf("%q: got %q", str, v)
+		}
+	}
+}
+
+func TestWebhookChanges__diff(t *testing.T) {
+	sdns := precomputeSDNs([]*ofac.SDN{
+		{EntityID: "306", SDNName: "BANCO NACIONAL DE CUBA", Programs: []string{"CUBA"}},
+		{EntityID: "21206", SDNName: "AL-HISN", Programs: []string{"SYRIA"}},
+	}, nil, noLogPipeliner)
+	alts := precomputeAlts([]*ofac.AlternateIdentity{
+		{EntityID: "306", AlternateID: "220", AlternateType: "aka", AlternateName: "NATIONAL BANK OF CUBA"},
+	})
+
+	// nothing changed before the first refresh
+	if changes := diffSDNRecords(nil, nil, nil, sdns, nil, alts, nil); changes != nil {
+		t.Errorf("got %#v", changes)
+	}
+
+	nextSDNs := precomputeSDNs([]*ofac.SDN{
+		{EntityID: "306", SDNName: "BANCO NACIONAL DE CUBA", Programs: []string{"CUBA", "CUBA-EO13224"}},
+		{EntityID: "21206", SDNName: "AL-HISN", Programs: []string{"SYRIA"}},
+		{EntityID: "7140", SDNName: "BANCO INTERNACIONAL DE CUBA"},
+	}, nil, noLogPipeliner)
+	nextAlts := precomputeAlts([]*ofac.AlternateIdentity{
+		{EntityID: "306", AlternateID: "221", AlternateType: "fka", AlternateName: "BNC"},
+	})
+	changes := diffSDNRecords(sdns, nil, alts, nextSDNs, nil, nextAlts, nil)
+	if len(changes) != 2 || changes["21206"] != nil || changes["7140"] == nil || !changes["7140"].Added {
+		t.Fatalf("got %#v", changes)
+	}
+	change := changes["306"]
+	if len(change.Fields) != 1 || change.Fields[0] != (sdnFieldChange{Field: "program", Before: "CUBA", After: "CUBA; CUBA-EO13224"}) {
+		t.Errorf("fields=%#v", change.Fields)
+	}
+	if len(change.AddedAltNames) != 1 || change.AddedAltNames[0] != "BNC" || len(change.RemovedAltNames) != 1 || change.RemovedAltNames[0] != "NATIONAL BANK OF CUBA" {
+		t.Errorf("added=%v removed=%v", change.AddedAltNames, change.RemovedAltNames)
+	}
+
+	// only the SDNs of an OFAC delta are compared
+	changes = diffSDNRecords(sdns, nil, alts, nextSDNs, nil, nextAlts, map[string]bool{"21206": true})
+	if len(changes) != 0 {
+		t.Errorf("got %#v", changes)
+	}
+}
+
+func TestWebhookChanges__reindex(t *testing.T) {
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "2681", SDNName: "HAWATMA, Nayif", SDNType: "individual", Programs: []string{"SYRIA"}},
+		}, nil, noLogPipeliner),
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+	stats, err := s.reindexSource(filepath.Join("..", "..", "test", "testdata"), sourceSDN)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a reindex records its changes like a refresh
+	change := stats.sdnChanges["2681"]
+	if change == nil || len(change.Fields) == 0 || change.Fields[0] != (sdnFieldChange{Field: "program", Before: "SYRIA", After: "SDT"}) {
+		t.Fatalf("got %#v", change)
+	}
+	if change := stats.sdnChanges["306"]; change == nil || !change.Added {
+		t.Errorf("got %#v", change)
+	}
+}
+
+func TestWebhookChanges__refreshThenReindex(t *testing.T) {
+	defer func() { webhookNotifications = notifyEveryRefresh }()
+	webhookNotifications = notifyChanges
+
+	transport := &bodyTransport{bodies: make(map[string][]byte)}
+	prev := webhookHTTPClient.Transport
+	webhookHTTPClient.Transport = transport
+	defer func() { webhookHTTPClient.Transport = prev }()
+
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "2681", SDNName: "HAWATMA, Nayif", SDNType: "individual", Programs: []string{"SYRIA"}},
+		}, nil, noLogPipeliner),
+		logger: log.NewNopLogger(),
+		pipe:   noLogPipeliner,
+	}
+
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	watchRepo := &sqliteWatchRepository{db.DB, log.NewNopLogger()}
+	webhookRepo := &sqliteWebhookRepository{db.DB}
+	if _, err := watchRepo.addCustomerWatch("2681", watchRequest{Webhook: "https://changed.example.com", AuthToken: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	custRepo := createTestCustomerRepository(t)
+	defer custRepo.close()
+	companyRepo := createTestCompanyRepository(t)
+	defer companyRepo.close()
+
+	// the refresh changes the SDN and the reindex right after it doesn't, before watches are
+	// re-searched after either of them
+	dir := filepath.Join("..", "..", "test", "testdata")
+	refreshed, err := s.refreshData(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reindexed, err := s.reindexSource(dir, sourceSDN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reindexed.sdnChanges) != 0 {
+		t.Fatalf("reindex changed %#v", reindexed.sdnChanges)
+	}
+
+	updates := make(chan *downloadStats)
+	done := make(chan struct{})
+	go func() {
+		s.spawnResearching(log.NewNopLogger(), companyRepo, custRepo, watchRepo, webhookRepo, updates)
+		close(done)
+	}()
+	updates <- refreshed
+	updates <- reindexed
+	close(updates)
+	<-done
+
+	body, exists := transport.bodies["changed.example.com"]
+	if !exists {
+		t.Fatal("change of the refresh wasn't notified")
+	}
+	var customer Customer
+	if err := json.Unmarshal(body, &customer); err != nil {
+		t.Fatal(err)
+	}
+	if customer.Changes == nil || len(customer.Changes.Fields) == 0 || customer.Changes.Fields[0].Field != "program" {
+		t.Errorf("got %s", string(body))
+	}
+}
+
+// bodyTransport records the body of requests to each host
+type bodyTransport struct {
+	mu     sync.Mutex
+	bodies map[string][]byte
+}
+
+func (rt *bodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := ioutil.ReadAll(req.Body)
+	rt.mu.Lock()
+	rt.bodies[req.URL.Host] = body
+	rt.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+func TestWebhookChanges__address(t *testing.T) {
+	defer func() { webhookNotifications = notifyEveryRefresh }()
+	webhookNotifications = notifyChanges
+
+	transport := &bodyTransport{bodies: make(map[string][]byte)}
+	prev := webhookHTTPClient.Transport
+	webhookHTTPClient.Transport = transport
+	defer func() { webhookHTTPClient.Transport = prev }()
+
+	s := &searcher{
+		SDNs: precomputeSDNs([]*ofac.SDN{
+			{EntityID: "306", SDNName: "BANCO NACIONAL DE CUBA", SDNType: "individual", Programs: []string{"CUBA"}},
+			{EntityID: "2681", SDNName: "HAWATMA, Nayif", SDNType: "individual", Programs: []string{"SDT"}},
+		}, nil, noLogPipeliner),
+		Addresses: precomputeAddresses([]*ofac.Address{
+			{EntityID: "306", AddressID: "201", Address: "Dai-Ichi Bldg. 6th Floor", CityStateProvincePostalCode: "Tokyo 103", Country: "Japan"},
+			{EntityID: "2681", AddressID: "1570", Country: "Lebanon"},
+		}),
+		pipe:   noLogPipeliner,
+		logger: log.NewNopLogger(),
+	}
+
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	watchRepo := &sqliteWatchRepository{db.DB, log.NewNopLogger()}
+	webhookRepo := &sqliteWebhookRepository{db.DB}
+	if _, err := watchRepo.addCustomerWatch("306", watchRequest{Webhook: "https://changed.example.com", AuthToken: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watchRepo.addCustomerWatch("2681", watchRequest{Webhook: "https://unchanged.example.com", AuthToken: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	custRepo := createTestCustomerRepository(t)
+	defer custRepo.close()
+	companyRepo := createTestCompanyRepository(t)
+	defer companyRepo.close()
+
+	// the address of the matched SDN moves while the other SDN's records stay the same
+	adds := precomputeAddresses([]*ofac.Address{
+		{EntityID: "306", AddressID: "202", Address: "Calle 13 No. 551", CityStateProvincePostalCode: "Havana", Country: "Cuba"},
+		{EntityID: "2681", AddressID: "1570", Country: "Lebanon"},
+	})
+	s.Lock()
+	changes := diffSDNRecords(s.SDNs, s.Addresses, s.Alts, s.SDNs, adds, s.Alts, nil)
+	s.Addresses = adds
+	s.Unlock()
+
+	updates := make(chan *downloadStats)
+	done := make(chan struct{})
+	go func() {
+		s.spawnResearching(log.NewNopLogger(), companyRepo, custRepo, watchRepo, webhookRepo, updates)
+		close(done)
+	}()
+	updates <- &downloadStats{sdnChanges: changes}
+	close(updates)
+	<-done
+
+	if _, exists := transport.bodies["unchanged.example.com"]; exists {
+		t.Error("watch of an unchanged SDN was notified")
+	}
+	body, exists := transport.bodies["changed.example.com"]
+	if !exists {
+		t.Fatal("watch of the changed SDN wasn't notified")
+	}
+	var customer Customer
+	if err := json.Unmarshal(body, &customer); err != nil {
+		t.Fatal(err)
+	}
+	if customer.ID != "306" || customer.Changes == nil {
+		t.Fatalf("got %s", string(body))
+	}
+	if added := customer.Changes.AddedAddresses; len(added) != 1 || added[0] != "Calle 13 No. 551, Havana, Cuba" {
+		t.Errorf("added=%v", added)
+	}
+	if removed := customer.Changes.RemovedAddresses; len(removed) != 1 || removed[0] != "Dai-Ichi Bldg. 6th Floor, Tokyo 103, Japan" {
+		t.Errorf("removed=%v", removed)
+	}
+	if len(customer.Changes.Fields) != 0 || len(customer.Changes.AddedAltNames) != 0 {
+		t.Errorf("unexpected changes: %#v", customer.Changes)
+	}
+}
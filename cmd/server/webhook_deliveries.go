@@ -0,0 +1,160 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	moovhttp "github.com/moov-io/base/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// deliveryDelivered is the outcome of a webhook which responded with a 2xx status
+	deliveryDelivered = "delivered"
+
+	// deliveryDeadLettered is the outcome of a webhook which failed every attempt
+	deliveryDeadLettered = "deadLettered"
+)
+
+var (
+	// webhookDeliveryRetention is how long the delivery history of watches is kept, it's read
+	// from WEBHOOK_DELIVERY_RETENTION
+	webhookDeliveryRetention = 30 * 24 * time.Hour
+
+	// defaultDeliveriesLimit and maxDeliveriesLimit are the page sizes of GET /ofac/watches/{watchID}/deliveries
+	defaultDeliveriesLimit, maxDeliveriesLimit = 100, 1000
+)
+
+func init() {
+	webhookDeliveryRetention = readWebhookDeliveryRetention(os.Getenv("WEBHOOK_DELIVERY_RETENTION"))
+}
+
+func readWebhookDeliveryRetention(str string) time.Duration {
+	if str == "" {
+		return webhookDeliveryRetention
+	}
+	if dur, err := time.ParseDuration(str); err == nil && dur > 0 {
+		return dur
+	}
+	return webhookDeliveryRetention
+}
+
+// webhookDelivery is the outcome of calling one webhook of a watch after every retry, which
+// receivers can be pointed at when they say a notification never arrived
+type webhookDelivery struct {
+	Webhook string `json:"webhook"`
+
+	// AttemptedAt is when the last attempt was made
+	AttemptedAt time.Time `json:"attemptedAt"`
+
+	// Status is the HTTP status of the last attempt, zero when the webhook couldn't be called
+	Status int `json:"status"`
+
+	Attempts int    `json:"attempts"`
+	Outcome  string `json:"outcome"`
+	Error    string `json:"error,omitempty"`
+}
+
+func (r *sqliteWebhookRepository) recordDelivery(watchID string, delivery webhookDelivery) error {
+	query := `insert into webhook_deliveries (watch_id, webhook, attempted_at, status, attempts, outcome, error) values (?, ?, ?, ?, ?, ?, ?);`
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(watchID, delivery.Webhook, delivery.AttemptedAt, delivery.Status, delivery.Attempts, delivery.Outcome, delivery.Error)
+	return err
+}
+
+func (r *sqliteWebhookRepository) getDeliveries(watchID string, since time.Time, limit int) ([]webhookDelivery, error) {
+	query := `select webhook, attempted_at, status, attempts, outcome, error from webhook_deliveries where watch_id = ? and attempted_at >= ? order by attempted_at desc limit ?;`
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(watchID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []webhookDelivery
+	for rows.Next() {
+		var delivery webhookDelivery
+		if err := rows.Scan(&delivery.Webhook, &delivery.AttemptedAt, &delivery.Status, &delivery.Attempts, &delivery.Outcome, &delivery.Error); err != nil {
+			return nil, err
+		}
+		out = append(out, delivery)
+	}
+	return out, rows.Err()
+}
+
+func (r *sqliteWebhookRepository) pruneDeliveries(before time.Time) (int64, error) {
+	query := `delete from webhook_deliveries where attempted_at < ?;`
+	stmt, err := r.db.Prepare(query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.Exec(before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func addWebhookDeliveryRoutes(logger log.Logger, r *mux.Router, webhookRepo webhookRepository) {
+	r.Methods("GET").Path("/ofac/watches/{watchID}/deliveries").HandlerFunc(getWebhookDeliveries(logger, webhookRepo))
+}
+
+func getWebhookDeliveries(logger log.Logger, repo webhookRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w = wrapResponseWriter(logger, w, r)
+
+		watchID := getWatchID(w, r)
+		if watchID == "" {
+			return
+		}
+		limit := defaultDeliveriesLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				moovhttp.Problem(w, fmt.Errorf("invalid limit %q", v))
+				return
+			}
+			limit = n
+		}
+		if limit > maxDeliveriesLimit {
+			limit = maxDeliveriesLimit
+		}
+
+		deliveries, err := repo.getDeliveries(watchID, time.Now().Add(-webhookDeliveryRetention), limit)
+		if err != nil {
+			moovhttp.Problem(w, err)
+			return
+		}
+		if deliveries == nil {
+			deliveries = []webhookDelivery{}
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(struct {
+			Deliveries []webhookDelivery `json:"deliveries"`
+		}{deliveries})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/watchman/internal/database"
+
+	"github.com/go-kit/kit/log"
+	"github.com/gorilla/mux"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	transport := &hostTransport{failingHost: "siem.example.com", calls: make(map[string]int)}
+	prev := webhookHTTPClient.Transport
+	webhookHTTPClient.Transport = transport
+	defer func() { webhookHTTPClient.Transport = prev }()
+
+	defer func(attempts int) { webhookMaxAttempts = attempts }(webhookMaxAttempts)
+	defer func(delay time.Duration) { webhookRetryDelay = delay }(webhookRetryDelay)
+	webhookMaxAttempts, webhookRetryDelay = 3, 0
+
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	repo := &sqliteWebhookRepository{db.DB}
+
+	w := watch{
+		id:        base.ID(),
+		webhook:   "https://siem.example.com/hook",
+		authToken: "a",
+		webhooks:  []watchWebhook{{Webhook: "https://cases.example.com/hook", AuthToken: "b"}},
+	}
+	var wg sync.WaitGroup
+	queueWebhooks(log.NewNopLogger(), w, []byte(`{"id": "cust"}`), repo, &wg)
+
+	// another watch's deliveries aren't included
+	queueWebhooks(log.NewNopLogger(), watch{id: base.ID(), webhook: "https://cases.example.com/other", authToken: "c"}, []byte(`{}`), repo, &wg)
+	wg.Wait()
+
+	router := mux.NewRouter()
+	addWebhookDeliveryRoutes(log.NewNopLogger(), router, repo)
+	history := func(query string) []webhookDelivery {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/ofac/watches/"+w.id+"/deliveries"+query, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("bogus HTTP status: %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Deliveries []webhookDelivery `json:"deliveries"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp.Deliveries
+	}
+
+	deliveries := history("")
+	if len(deliveries) != 2 {
+		t.Fatalf("got %#v", deliveries)
+	}
+	byWebhook := make(map[string]webhookDelivery)
+	for _, d := range deliveries {
+		if d.AttemptedAt.IsZero() {
+			t.Errorf("no timestamp: %#v", d)
+		}
+		byWebhook[d.Webhook] = d
+	}
+	if d := byWebhook["https://cases.example.com/hook"]; d.Outcome != deliveryDelivered || d.Status != 200 || d.Attempts != 1 || d.Error != "" {
+		t.Errorf("delivered: %#v", d)
+	}
+	if d := byWebhook["https://siem.example.com/hook"]; d.Outcome != deliveryDeadLettered || d.Status != 503 || d.Attempts != 3 || !strings.Contains(d.Error, "503") {
+		t.Errorf("dead letter: %#v", d)
+	}
+	if deliveries := history("?limit=1"); len(deliveries) != 1 {
+		t.Errorf("got %#v", deliveries)
+	}
+
+	// deliveries are only kept for the retention window
+	defer func(retention time.Duration) { webhookDeliveryRetention = retention }(webhookDeliveryRetention)
+	old := webhookDelivery{Webhook: "https://cases.example.com/hook", AttemptedAt: time.Now().Add(-2 * time.Hour), Status: 200, Attempts: 1, Outcome: deliveryDelivered}
+	if err := repo.recordDelivery(w.id, old); err != nil {
+		t.Fatal(err)
+	}
+	if deliveries := history(""); len(deliveries) != 3 {
+		t.Errorf("got %#v", deliveries)
+	}
+	webhookDeliveryRetention = time.Hour
+	if deliveries := history(""); len(deliveries) != 2 {
+		t.Errorf("got %#v", deliveries)
+	}
+	if n, err := repo.pruneDeliveries(time.Now().Add(-webhookDeliveryRetention)); err != nil || n != 1 {
+		t.Errorf("pruned %d: %v", n, err)
+	}
+
+	// an unknown watch has no history
+	req := httptest.NewRequest("GET", "/ofac/watches/other/deliveries", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"deliveries":[]`) {
+		t.Errorf("bogus response: %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWebhookDeliveries__retention(t *testing.T) {
+	if d := readWebhookDeliveryRetention(""); d != 30*24*time.Hour {
+		t.Errorf("got %v", d)
+	}
+	if d := readWebhookDeliveryRetention("72h"); d != 72*time.Hour {
+		t.Errorf("got %v", d)
+	}
+	if d := readWebhookDeliveryRetention("-1h"); d != webhookDeliveryRetention {
+		t.Errorf("got %v", d)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	// webhookWorkers delivers the webhooks of every watch, WEBHOOK_MAX_CONCURRENCY is how many
+	// are delivered at once
+	webhookWorkers = newWebhookPool(defaultWebhookMaxConcurrency)
+)
+
+const defaultWebhookMaxConcurrency = 10
+
+// getWebhookMaxConcurrency reads a positive number of webhooks
+//
+// env is the value from an environmental variable
+func getWebhookMaxConcurrency(logger log.Logger, env string) int {
+	if env == "" {
+		return defaultWebhookMaxConcurrency
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil || n <= 0 {
+		logger.Log("main", fmt.Sprintf("invalid WEBHOOK_MAX_CONCURRENCY=%q, using default of %d", env, defaultWebhookMaxConcurrency))
+		return defaultWebhookMaxConcurrency
+	}
+	logger.Log("main", fmt.Sprintf("Delivering up to %d webhooks at once", n))
+	return n
+}
+
+// webhookPool runs webhook deliveries on a fixed number of workers, so a burst of matches after
+// a large list update doesn't call thousands of webhooks at once. Deliveries beyond the limit
+// wait for a free worker rather than each running in its own goroutine.
+type webhookPool struct {
+	size int
+	jobs chan func()
+	once sync.Once
+}
+
+func newWebhookPool(size int) *webhookPool {
+	return &webhookPool{
+		size: size,
+		jobs: make(chan func()),
+	}
+}
+
+// submit blocks until a worker is free to run fn, the workers are started on first use
+func (p *webhookPool) submit(fn func()) {
+	p.once.Do(func() {
+		for i := 0; i < p.size; i++ {
+			go p.work()
+		}
+	})
+	p.jobs <- fn
+}
+
+func (p *webhookPool) work() {
+	for fn := range p.jobs {
+		fn()
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moov-io/base"
+	"github.com/moov-io/watchman/internal/database"
+
+	"github.com/go-kit/kit/log"
+)
+
+// concurrencyTracker records the most functions which were running at once
+type concurrencyTracker struct {
+	running, most int64
+}
+
+func (c *concurrencyTracker) track(d time.Duration) {
+	n := atomic.AddInt64(&c.running, 1)
+	for {
+		most := atomic.LoadInt64(&c.most)
+		if n <= most || atomic.CompareAndSwapInt64(&c.most, most, n) {
+			break
+		}
+	}
+	time.Sleep(d)
+	atomic.AddInt64(&c.running, -1)
+}
+
+func TestWebhookPool(t *testing.T) {
+	pool := newWebhookPool(3)
+	tracker := &concurrencyTracker{}
+
+	var wg sync.WaitGroup
+	var ran int64
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		pool.submit(func() {
+			defer wg.Done()
+			tracker.track(2 * time.Millisecond)
+			atomic.AddInt64(&ran, 1)
+		})
+	}
+	wg.Wait()
+
+	if ran != 50 {
+		t.Errorf("ran %d deliveries", ran)
+	}
+	if tracker.most > 3 || tracker.most < 1 {
+		t.Errorf("%d deliveries ran at once", tracker.most)
+	}
+}
+
+// slowTransport responds with a 200 after a delay and tracks how many requests are in-flight
+type slowTransport struct {
+	concurrencyTracker
+}
+
+func (st *slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	st.track(5 * time.Millisecond)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+func TestWebhookPool__queueWebhooks(t *testing.T) {
+	transport := &slowTransport{}
+	prev := webhookHTTPClient.Transport
+	webhookHTTPClient.Transport = transport
+	defer func() { webhookHTTPClient.Transport = prev }()
+
+	defer func(pool *webhookPool) { webhookWorkers = pool }(webhookWorkers)
+	webhookWorkers = newWebhookPool(2)
+
+	db := database.CreateTestSqliteDB(t)
+	defer db.Close()
+	repo := &sqliteWebhookRepository{db.DB}
+
+	// a burst of watches, each with several webhooks
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		w := watch{
+			id:        base.ID(),
+			webhook:   fmt.Sprintf("https://cases.example.com/%d", i),
+			authToken: "a",
+			webhooks:  []watchWebhook{{Webhook: fmt.Sprintf("https://siem.example.com/%d", i), AuthToken: "b"}},
+		}
+		queueWebhooks(log.NewNopLogger(), w, []byte(`{}`), repo, &wg)
+	}
+	wg.Wait()
+
+	if transport.most > 2 {
+		t.Errorf("%d webhooks were called at once", transport.most)
+	}
+	var n int
+	if err := db.DB.QueryRow(`select count(*) from webhook_deliveries where outcome = ?`, deliveryDelivered).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 20 {
+		t.Errorf("%d webhooks delivered", n)
+	}
+}
+
+func TestWebhookPool__getWebhookMaxConcurrency(t *testing.T) {
+	if n := getWebhookMaxConcurrency(log.NewNopLogger(), ""); n != defaultWebhookMaxConcurrency {
+		t.Errorf("got %d", n)
+	}
+	if n := getWebhookMaxConcurrency(log.NewNopLogger(), "25"); n != 25 {
+		t.Errorf("got %d", n)
+	}
+	if n := getWebhookMaxConcurrency(log.NewNopLogger(), "0"); n != defaultWebhookMaxConcurrency {
+		t.Errorf("got %d", n)
+	}
+}
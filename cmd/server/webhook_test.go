@@ -15,6 +15,7 @@ import (
 
 	"github.com/moov-io/base"
 	"github.com/moov-io/watchman/internal/database"
+	"github.com/moov-io/watchman/pkg/webhook"
 )
 
 var (
@@ -127,7 +128,7 @@ func TestWebhook_call(t *testing.T) {
 	defer custRepo.close()
 
 	// execute webhook with arbitrary Customer
-	body, err := getCustomerBody(customerSearcher, "watchID", "306", 1.0, custRepo)
+	body, err := getCustomerBody(customerSearcher, "watchID", "306", 1.0, nil, custRepo)
 	if body == nil {
 		t.Fatalf("nil body: %v", err)
 	}
@@ -136,6 +137,39 @@ func TestWebhook_call(t *testing.T) {
 	}
 }
 
+type recordingTransport struct {
+	req  *http.Request
+	body []byte
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.req = req
+	rt.body, _ = ioutil.ReadAll(req.Body)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+func TestWebhook__signature(t *testing.T) {
+	transport := &recordingTransport{}
+	prev := webhookHTTPClient.Transport
+	webhookHTTPClient.Transport = transport
+	defer func() { webhookHTTPClient.Transport = prev }()
+
+	var body bytes.Buffer
+	body.WriteString(`{"id": "cust"}`)
+	if _, err := callWebhook("watchID", &body, "https://example.com/webhook", "authToken"); err != nil {
+		t.Fatal(err)
+	}
+
+	header := transport.req.Header.Get(webhook.SignatureHeader)
+	if !webhook.VerifySignature("authToken", transport.body, header) {
+		t.Errorf("invalid signature %q for %s", header, string(transport.body))
+	}
+}
+
 func TestWebhook__CallErr(t *testing.T) {
 	var body bytes.Buffer
 	body.WriteString(`{"foo": "bar"}`)
@@ -153,7 +187,7 @@ func TestWebhook_record(t *testing.T) {
 	t.Parallel()
 
 	check := func(t *testing.T, repo *sqliteWebhookRepository) {
-		if err := repo.recordWebhook(base.ID(), time.Now(), 200); err != nil {
+		if err := repo.recordWebhook(base.ID(), "https://example.com/webhook", time.Now(), 200); err != nil {
 			t.Fatal(err)
 		}
 	}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// wildcardMatch scores an indexed name against a query whose tokens may contain '*' (zero or
+// more characters) and '?' (exactly one character). Patterns are anchored to whole tokens, so
+// "mohamm*" matches "mohammed" but not "almohammed".
+//
+// The score is the average of how many query tokens matched and how many indexed tokens were
+// matched, so a query covering the entire name scores 1.0 and a query token without any match
+// scores the name 0.0
+func wildcardMatch(indexed, query string) float64 {
+	names, patterns := strings.Fields(indexed), strings.Fields(query)
+	if len(names) == 0 || len(patterns) == 0 {
+		return 0.0
+	}
+
+	// Match the most specific patterns first so a broad pattern (e.g. "m*") doesn't take
+	// the only token a narrower one (e.g. "moha*") could match.
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return literalLength(patterns[i]) > literalLength(patterns[j])
+	})
+
+	used := make([]bool, len(names))
+	for _, pattern := range patterns {
+		found := false
+		for i := range names {
+			if !used[i] && globMatch(pattern, names[i]) {
+				used[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			return 0.0
+		}
+	}
+
+	// every query token matched, so only the name's coverage can reduce the score
+	matched := float64(len(patterns))
+	return (1.0 + matched/float64(len(names))) / 2.0
+}
+
+// globMatch reports if the entire token matches pattern
+func globMatch(pattern, token string) bool {
+	p, t := []rune(pattern), []rune(token)
+
+	// Classic iterative matcher which backtracks to the last '*'
+	pi, ti := 0, 0
+	star, mark := -1, 0
+	for ti < len(t) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == t[ti]):
+			pi++
+			ti++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ti
+			pi++
+		case star >= 0:
+			pi = star + 1
+			mark++
+			ti = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+func literalLength(pattern string) int {
+	return len(strings.NewReplacer("*", "", "?", "").Replace(pattern))
+}
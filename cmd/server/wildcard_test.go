@@ -0,0 +1,64 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestWildcard__globMatch(t *testing.T) {
+	cases := []struct {
+		pattern, token string
+		match          bool
+	}{
+		// prefix
+		{"mohamm*", "mohammed", true},
+		{"mohamm*", "mohammad", true},
+		{"mohamm*", "mohamm", true},
+		{"mohamm*", "muhammad", false},
+		{"mohamm*", "almohammed", false}, // anchored to the token start
+		// suffix
+		{"*hammad", "muhammad", true},
+		{"*hammad", "mohammad", true},
+		{"*hammad", "mohammed", false},
+		// embedded
+		{"m?hamm?d", "muhammad", true},
+		{"m?hamm?d", "mohammed", true},
+		{"m?hamm?d", "mhammed", false},
+		{"m*d", "muhammad", true},
+		{"m*h*d", "mohammed", true},
+		{"m*h*d", "mohammad ali", false},
+		// no wildcards
+		{"ali", "ali", true},
+		{"ali", "alia", false},
+		{"*", "", true},
+		{"?", "", false},
+	}
+	for i := range cases {
+		if got := globMatch(cases[i].pattern, cases[i].token); got != cases[i].match {
+			t.Errorf("globMatch(%q, %q)=%v", cases[i].pattern, cases[i].token, got)
+		}
+	}
+}
+
+func TestWildcard__match(t *testing.T) {
+	eql(t, "full coverage", wildcardMatch("mohammed ali", "mohamm* ali"), 1.0)
+	eql(t, "partial name coverage", wildcardMatch("mohammed ali", "mohamm*"), 0.75)
+	eql(t, "missing query token", wildcardMatch("mohammed ali", "mohamm* omar"), 0.0)
+	eql(t, "suffix", wildcardMatch("muhammad", "*hammad"), 1.0)
+	eql(t, "embedded", wildcardMatch("muhammad ali", "m?hamm?d a*"), 1.0)
+	eql(t, "specific patterns first", wildcardMatch("mx mohammed", "m* moha*"), 1.0)
+	eql(t, "token reuse", wildcardMatch("ali", "ali ali"), 0.0)
+	eql(t, "empty", wildcardMatch("", "ali"), 0.0)
+}
+
+func TestSearch__wildcardOptions(t *testing.T) {
+	opts := searchOptions{matchMode: matchModeWildcard}
+	sdns := idSearcher.TopSDNs(1, "nic* mad?ro", opts)
+	if len(sdns) == 0 || sdns[0].EntityID != "22790" {
+		t.Fatalf("%#v", sdns)
+	}
+	eql(t, "wildcard SDN match", sdns[0].match, (1.0+2.0/3.0)/2.0)
+}
@@ -81,6 +81,46 @@ var (
 			"add__bis_entities__to_download_stats",
 			"alter table download_stats add column bis_entities integer not null default 0;",
 		),
+		execsql(
+			"add__nonproliferation_sanctions__to_download_stats",
+			"alter table download_stats add column nonproliferation_sanctions integer not null default 0;",
+		),
+		execsql(
+			"add__canadian_sanctions__to_download_stats",
+			"alter table download_stats add column canadian_sanctions integer not null default 0;",
+		),
+		execsql(
+			"add__australian_sanctions__to_download_stats",
+			"alter table download_stats add column australian_sanctions integer not null default 0;",
+		),
+		execsql(
+			"add__united_nations_sanctions__to_download_stats",
+			"alter table download_stats add column united_nations_sanctions integer not null default 0;",
+		),
+		execsql(
+			"add__custom_entries__to_download_stats",
+			"alter table download_stats add column custom_entries integer not null default 0;",
+		),
+		execsql(
+			"create_watch_webhooks",
+			`create table if not exists watch_webhooks(watch_id varchar(40), webhook varchar(512), auth_token varchar(128), created_at timestamp(3), deleted_at timestamp(3));`,
+		),
+		execsql(
+			"add__webhook__to_webhook_stats",
+			"alter table webhook_stats add column webhook varchar(512) not null default '';",
+		),
+		execsql(
+			"create_webhook_dead_letters",
+			`create table if not exists webhook_dead_letters(watch_id varchar(40), webhook varchar(512), failed_at timestamp(3), attempts integer, status varchar(10), error text);`,
+		),
+		execsql(
+			"create_download_source_stats",
+			`create table if not exists download_source_stats(downloaded_at timestamp(3), source varchar(10), size_bytes bigint, record_count integer);`,
+		),
+		execsql(
+			"create_webhook_deliveries",
+			`create table if not exists webhook_deliveries(watch_id varchar(40), webhook varchar(512), attempted_at timestamp(3), status integer, attempts integer, outcome varchar(20), error text);`,
+		),
 	)
 )
 
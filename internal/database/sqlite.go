@@ -79,6 +79,46 @@ var (
 			"add__bis_entities__to_download_stats",
 			"alter table download_stats add column bis_entities default 0;",
 		),
+		execsql(
+			"add__nonproliferation_sanctions__to_download_stats",
+			"alter table download_stats add column nonproliferation_sanctions default 0;",
+		),
+		execsql(
+			"add__canadian_sanctions__to_download_stats",
+			"alter table download_stats add column canadian_sanctions default 0;",
+		),
+		execsql(
+			"add__australian_sanctions__to_download_stats",
+			"alter table download_stats add column australian_sanctions default 0;",
+		),
+		execsql(
+			"add__united_nations_sanctions__to_download_stats",
+			"alter table download_stats add column united_nations_sanctions default 0;",
+		),
+		execsql(
+			"add__custom_entries__to_download_stats",
+			"alter table download_stats add column custom_entries default 0;",
+		),
+		execsql(
+			"create_watch_webhooks",
+			`create table if not exists watch_webhooks(watch_id, webhook, auth_token, created_at datetime, deleted_at datetime);`,
+		),
+		execsql(
+			"add__webhook__to_webhook_stats",
+			"alter table webhook_stats add column webhook default '';",
+		),
+		execsql(
+			"create_webhook_dead_letters",
+			`create table if not exists webhook_dead_letters(watch_id, webhook, failed_at datetime, attempts, status, error);`,
+		),
+		execsql(
+			"create_download_source_stats",
+			`create table if not exists download_source_stats(downloaded_at datetime, source, size_bytes, record_count);`,
+		),
+		execsql(
+			"create_webhook_deliveries",
+			`create table if not exists webhook_deliveries(watch_id, webhook, attempted_at datetime, status, attempts, outcome, error);`,
+		),
 	)
 )
 
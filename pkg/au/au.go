@@ -0,0 +1,40 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package au
+
+const (
+	TypeIndividual = "individual"
+	TypeEntity     = "entity"
+)
+
+// Entry is a record on Australia's Consolidated List, maintained by the Department of Foreign
+// Affairs and Trade (DFAT) for Australian sanction laws and the UN Security Council sanctions
+// which Australia implements.
+type Entry struct {
+	// Reference is the record's reference number on the list
+	Reference string `json:"reference"`
+	// Type is individual or entity
+	Type string `json:"type"`
+	// Name is the primary "Name of Individual or Entity"
+	Name string `json:"name"`
+	// Aliases are other known names, including names in their original script
+	Aliases []string `json:"aliases"`
+	// DateOfBirth is an individual's date(s) of birth as published
+	DateOfBirth string `json:"dateOfBirth"`
+	// PlaceOfBirth is where an individual was born
+	PlaceOfBirth string `json:"placeOfBirth"`
+	// Citizenship is an individual's citizenship(s)
+	Citizenship string `json:"citizenship"`
+	// Address is the known address(es) of the individual or entity
+	Address string `json:"address"`
+	// AdditionalInformation is free text published with the record
+	AdditionalInformation string `json:"additionalInformation"`
+	// ListingInformation is the instrument (and date) which listed the record
+	ListingInformation string `json:"listingInformation"`
+	// Committees are the sanctions regimes the record is listed under
+	Committees string `json:"committees"`
+	// ControlDate is when the record was last changed
+	ControlDate string `json:"controlDate"`
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package au
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/moov-io/watchman/pkg/download"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	auDownloadTemplate = func() string {
+		if w := os.Getenv("AU_DOWNLOAD_TEMPLATE"); w != "" {
+			return w
+		}
+		return "https://www.dfat.gov.au/sites/default/files/%s"
+	}()
+)
+
+// Download returns the filepath of the downloaded DFAT Consolidated List
+func Download(logger log.Logger, initialDir string) (string, error) {
+	dl := download.New(logger, download.HTTPClient)
+
+	addrs := make(map[string]string)
+	addrs["regulation8_consolidated.xlsx"] = fmt.Sprintf(auDownloadTemplate, "regulation8_consolidated.xlsx")
+
+	files, err := dl.GetFiles(initialDir, addrs)
+	if len(files) == 0 || err != nil {
+		return "", fmt.Errorf("au download: %v", err)
+	}
+	return files[0], nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package au
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	colReference   = "reference"
+	colName        = "name of individual or entity"
+	colType        = "type"
+	colNameType    = "name type"
+	colDateOfBirth = "date of birth"
+	colPlaceBirth  = "place of birth"
+	colCitizenship = "citizenship"
+	colAddress     = "address"
+	colAdditional  = "additional information"
+	colListing     = "listing information"
+	colCommittees  = "committees"
+	colControlDate = "control date"
+)
+
+// Read parses DFAT's Consolidated List from either its XLSX spreadsheet or a CSV export with
+// the same columns. Aliases are listed as their own rows, which share the reference number of
+// the primary name followed by a letter (e.g. 12 and 12a), and are folded into that Entry.
+func Read(path string) ([]*Entry, error) {
+	var rows [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xlsx":
+		rows, err = readXLSX(path)
+	case ".csv":
+		rows, err = readCSV(path)
+	default:
+		return nil, fmt.Errorf("unknown file type: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseRows(rows)
+}
+
+func readCSV(path string) ([][]string, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	r := csv.NewReader(fd)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return r.ReadAll()
+}
+
+func parseRows(rows [][]string) ([]*Entry, error) {
+	// find the header row, the spreadsheet can have a title above it
+	columns := make(map[string]int)
+	for len(rows) > 0 && len(columns) == 0 {
+		header := make(map[string]int)
+		for i, v := range rows[0] {
+			header[strings.ToLower(strings.TrimSpace(v))] = i
+		}
+		if _, exists := header[colName]; exists {
+			columns = header
+		}
+		rows = rows[1:]
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no %q column found", colName)
+	}
+
+	get := func(row []string, col string) string {
+		if i, exists := columns[col]; exists && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var out []*Entry
+	entries := make(map[string]*Entry)
+	for _, row := range rows {
+		name := get(row, colName)
+		if name == "" {
+			continue
+		}
+		ref := baseReference(get(row, colReference))
+		if entry, exists := entries[ref]; exists && ref != "" && !isPrimaryName(get(row, colNameType)) {
+			entry.Aliases = append(entry.Aliases, name)
+			continue
+		}
+
+		entry := &Entry{
+			Reference:             ref,
+			Type:                  entryType(get(row, colType)),
+			Name:                  name,
+			DateOfBirth:           excelDate(get(row, colDateOfBirth)),
+			PlaceOfBirth:          get(row, colPlaceBirth),
+			Citizenship:           get(row, colCitizenship),
+			Address:               get(row, colAddress),
+			AdditionalInformation: get(row, colAdditional),
+			ListingInformation:    get(row, colListing),
+			Committees:            get(row, colCommittees),
+			ControlDate:           excelDate(get(row, colControlDate)),
+		}
+		if ref != "" {
+			entries[ref] = entry
+		}
+		out = append(out, entry)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no records found")
+	}
+	return out, nil
+}
+
+// baseReference drops the letter suffix of an alias's reference number (12a is 12)
+func baseReference(ref string) string {
+	return strings.TrimRightFunc(ref, unicode.IsLetter)
+}
+
+func isPrimaryName(nameType string) bool {
+	return nameType == "" || strings.EqualFold(nameType, "primary name")
+}
+
+func entryType(raw string) string {
+	if strings.EqualFold(raw, "individual") {
+		return TypeIndividual
+	}
+	return TypeEntity
+}
+
+// excelDate formats dates which are stored as an Excel serial number (days since 1899-12-30)
+// as YYYY-MM-DD. Any other value is returned unchanged, including small numbers (before 1927)
+// which are more likely a year of birth.
+func excelDate(raw string) string {
+	days, err := strconv.ParseFloat(raw, 64)
+	if err != nil || days < 10000 || days > 100000 {
+		return raw
+	}
+	return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(days)).Format("2006-01-02")
+}
@@ -0,0 +1,124 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package au
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAU__read(t *testing.T) {
+	entries, err := Read(filepath.Join("..", "..", "test", "testdata", "regulation8_consolidated.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("found %d AU records", len(entries))
+	}
+
+	// individual, the alias is an inline string
+	e := entries[0]
+	if e.Type != TypeIndividual || e.Reference != "1" || e.Name != "Abdul Baqi BASIR AWAL SHAH" {
+		t.Errorf("individual: %#v", e)
+	}
+	if e.DateOfBirth != "c. 1960" || e.PlaceOfBirth != "Jalalabad, Afghanistan" || e.Citizenship != "Afghan" {
+		t.Errorf("individual: %#v", e)
+	}
+	if e.Committees != "1988 (Taliban)" || e.ControlDate != "2011-01-01" {
+		t.Errorf("individual: %#v", e)
+	}
+	if len(e.Aliases) != 1 || e.Aliases[0] != "Abdul Baqi" {
+		t.Errorf("aliases: %#v", e.Aliases)
+	}
+
+	// the primary name is rich text split into runs
+	if e := entries[1]; e.Name != "Nicolas MADURO MOROS" || e.DateOfBirth != "23/11/1962" || e.ControlDate != "2024-01-01" {
+		t.Errorf("individual: %#v", e)
+	}
+
+	// entity with an alias and its name in the original script
+	e = entries[2]
+	if e.Type != TypeEntity || e.Name != "KOREA MINING DEVELOPMENT TRADING CORPORATION" {
+		t.Errorf("entity: %#v", e)
+	}
+	if e.Address != "Central District, Pyongyang, Democratic People's Republic of Korea" {
+		t.Errorf("address: %q", e.Address)
+	}
+	if len(e.Aliases) != 2 || e.Aliases[0] != "KOMID" || e.Aliases[1] != "조선광업개발무역회사" {
+		t.Errorf("aliases: %#v", e.Aliases)
+	}
+
+	for _, name := range []string{"sdn.csv", "sema-lmes.xml"} {
+		if _, err := Read(filepath.Join("..", "..", "test", "testdata", name)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestAU__readCSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "au-csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "consolidated.csv")
+	body := `Consolidated List,,,
+Reference,Name of Individual or Entity,Type,Name Type,Date of Birth,Address
+12,"SMITH, John",Individual,Primary Name,01/02/1970,"1 Main St, Sydney"
+12a,Johnny Smith,Individual,Alias,,
+13,ACME TRADING,Entity,,,
+`
+	if err := ioutil.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("found %d AU records", len(entries))
+	}
+	if e := entries[0]; e.Name != "SMITH, John" || e.DateOfBirth != "01/02/1970" || e.Address != "1 Main St, Sydney" {
+		t.Errorf("individual: %#v", e)
+	}
+	if e := entries[0]; len(e.Aliases) != 1 || e.Aliases[0] != "Johnny Smith" {
+		t.Errorf("aliases: %#v", e.Aliases)
+	}
+	if e := entries[1]; e.Type != TypeEntity || e.Reference != "13" || len(e.Aliases) != 0 {
+		t.Errorf("entity: %#v", e)
+	}
+
+	// files without the name column
+	if err := ioutil.WriteFile(path, []byte("a,b,c\n1,2,3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestAU__helpers(t *testing.T) {
+	if v := baseReference("12ab"); v != "12" {
+		t.Errorf("got %q", v)
+	}
+	if v := columnIndex("C7"); v != 2 {
+		t.Errorf("got %d", v)
+	}
+	if v := columnIndex("AA1"); v != 26 {
+		t.Errorf("got %d", v)
+	}
+	if v := excelDate("23/11/1962"); v != "23/11/1962" {
+		t.Errorf("got %q", v)
+	}
+	if v := excelDate("1962"); v != "1962" {
+		t.Errorf("got %q", v)
+	}
+	if v := excelDate("22973"); v != "1962-11-23" {
+		t.Errorf("got %q", v)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package au
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// An XLSX file is a zip of XML documents. Only the pieces needed to read cell values from the
+// first worksheet are decoded, formatting and formulas are ignored.
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var buf strings.Builder
+	for i := range t.Runs {
+		buf.WriteString(t.Runs[i].T)
+	}
+	return buf.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX returns the cell values of the first worksheet in path, row by row
+func readXLSX(path string) ([][]string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	files := make(map[string]*zip.File)
+	var sheets []string
+	for _, f := range zr.File {
+		files[f.Name] = f
+		if strings.HasPrefix(f.Name, "xl/worksheets/") && strings.HasSuffix(f.Name, ".xml") {
+			sheets = append(sheets, f.Name)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, errors.New("xlsx: no worksheets found")
+	}
+	sheet := "xl/worksheets/sheet1.xml"
+	if _, exists := files[sheet]; !exists {
+		sort.Strings(sheets)
+		sheet = sheets[0]
+	}
+
+	var shared xlsxSharedStrings
+	if f, exists := files["xl/sharedStrings.xml"]; exists {
+		if err := decodeXLSXFile(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+	var ws xlsxWorksheet
+	if err := decodeXLSXFile(files[sheet], &ws); err != nil {
+		return nil, err
+	}
+
+	var out [][]string
+	for _, row := range ws.Rows {
+		var values []string
+		for i, c := range row.Cells {
+			col := columnIndex(c.Ref)
+			if col < 0 {
+				col = i // the reference is optional
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+			switch c.Type {
+			case "s":
+				n, err := strconv.Atoi(c.Value)
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("xlsx: %s has invalid shared string %q", c.Ref, c.Value)
+				}
+				values[col] = shared.Items[n].String()
+			case "inlineStr":
+				values[col] = c.Inline.String()
+			default:
+				values[col] = c.Value
+			}
+		}
+		out = append(out, values)
+	}
+	return out, nil
+}
+
+func decodeXLSXFile(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("xlsx: %s: %v", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex returns the zero based column of a cell reference, e.g. 2 for C7
+func columnIndex(ref string) int {
+	var n int
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1
+}
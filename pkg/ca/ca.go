@@ -0,0 +1,61 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ca
+
+const (
+	TypeIndividual = "individual"
+	TypeEntity     = "entity"
+	TypeVessel     = "vessel"
+)
+
+// Entry is a record on Canada's Consolidated Autonomous Sanctions List, maintained by Global
+// Affairs Canada for the Special Economic Measures Act (SEMA) and the Justice for Victims of
+// Corrupt Foreign Officials Act (JVCFOA).
+type Entry struct {
+	// Type is individual, entity or vessel
+	Type string `json:"type"`
+	// Country is the regime or country the sanctions relate to
+	Country string `json:"country"`
+	// LastName is the surname of a listed individual
+	LastName string `json:"lastName"`
+	// GivenName is the given name(s) of a listed individual
+	GivenName string `json:"givenName"`
+	// EntityOrShip is the name of a listed entity or vessel
+	EntityOrShip string `json:"entityOrShip"`
+	// ShipIMONumber is the International Maritime Organization number of a listed vessel
+	ShipIMONumber string `json:"shipIMONumber"`
+	// DateOfBirthOrShipBuildDate is an individual's date of birth or a vessel's build year
+	DateOfBirthOrShipBuildDate string `json:"dateOfBirthOrShipBuildDate"`
+	// Schedule is the schedule (and part) of the regulations which lists the record
+	Schedule string `json:"schedule"`
+	// Item is the record's item number within its schedule
+	Item string `json:"item"`
+	// DateOfListing is when the record was added to the list
+	DateOfListing string `json:"dateOfListing"`
+	// Aliases are other known names of the individual, entity or vessel
+	Aliases []string `json:"aliases"`
+	// TitleOrShip is an individual's title or position, or a vessel's details
+	TitleOrShip string `json:"titleOrShip"`
+}
+
+// ID identifies an Entry. Items are only numbered within the schedule of a country's
+// regulations, so each of them is part of the ID (e.g. "Venezuela/1, Part 1/41").
+func (e *Entry) ID() string {
+	return e.Country + "/" + e.Schedule + "/" + e.Item
+}
+
+// Name returns the primary name of an Entry. Individuals are "GivenName LastName".
+func (e *Entry) Name() string {
+	if e.Type == TypeIndividual {
+		if e.GivenName == "" {
+			return e.LastName
+		}
+		if e.LastName == "" {
+			return e.GivenName
+		}
+		return e.GivenName + " " + e.LastName
+	}
+	return e.EntityOrShip
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ca
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/moov-io/watchman/pkg/download"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	caDownloadTemplate = func() string {
+		if w := os.Getenv("CA_DOWNLOAD_TEMPLATE"); w != "" {
+			return w
+		}
+		return "https://www.international.gc.ca/world-monde/assets/office_docs/international_relations-relations_internationales/sanctions/%s"
+	}()
+)
+
+// Download returns the filepath of the downloaded Consolidated Autonomous Sanctions List
+func Download(logger log.Logger, initialDir string) (string, error) {
+	dl := download.New(logger, download.HTTPClient)
+
+	addrs := make(map[string]string)
+	addrs["sema-lmes.xml"] = fmt.Sprintf(caDownloadTemplate, "sema-lmes.xml")
+
+	files, err := dl.GetFiles(initialDir, addrs)
+	if len(files) == 0 || err != nil {
+		return "", fmt.Errorf("ca download: %v", err)
+	}
+	return files[0], nil
+}
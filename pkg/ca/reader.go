@@ -0,0 +1,89 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ca
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"strings"
+)
+
+type dataSet struct {
+	XMLName xml.Name `xml:"data-set"`
+	Records []record `xml:"record"`
+}
+
+type record struct {
+	Country                    string `xml:"Country"`
+	LastName                   string `xml:"LastName"`
+	GivenName                  string `xml:"GivenName"`
+	EntityOrShip               string `xml:"EntityOrShip"`
+	ShipIMONumber              string `xml:"ShipIMONumber"`
+	DateOfBirthOrShipBuildDate string `xml:"DateOfBirthOrShipBuildDate"`
+	Schedule                   string `xml:"Schedule"`
+	Item                       string `xml:"Item"`
+	DateOfListing              string `xml:"DateOfListing"`
+	Aliases                    string `xml:"Aliases"`
+	TitleOrShip                string `xml:"TitleOrShip"`
+}
+
+// Read parses the XML export of Canada's Consolidated Autonomous Sanctions List.
+// Records without any name are skipped.
+func Read(path string) ([]*Entry, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var doc dataSet
+	if err := xml.NewDecoder(fd).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Records) == 0 {
+		return nil, errors.New("no records found")
+	}
+
+	var out []*Entry
+	for _, r := range doc.Records {
+		entry := &Entry{
+			Country:                    strings.TrimSpace(r.Country),
+			LastName:                   strings.TrimSpace(r.LastName),
+			GivenName:                  strings.TrimSpace(r.GivenName),
+			EntityOrShip:               strings.TrimSpace(r.EntityOrShip),
+			ShipIMONumber:              strings.TrimSpace(r.ShipIMONumber),
+			DateOfBirthOrShipBuildDate: strings.TrimSpace(r.DateOfBirthOrShipBuildDate),
+			Schedule:                   strings.TrimSpace(r.Schedule),
+			Item:                       strings.TrimSpace(r.Item),
+			DateOfListing:              strings.TrimSpace(r.DateOfListing),
+			Aliases:                    splitAliases(r.Aliases),
+			TitleOrShip:                strings.TrimSpace(r.TitleOrShip),
+		}
+		switch {
+		case entry.LastName != "" || entry.GivenName != "":
+			entry.Type = TypeIndividual
+		case entry.ShipIMONumber != "":
+			entry.Type = TypeVessel
+		case entry.EntityOrShip != "":
+			entry.Type = TypeEntity
+		default:
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+// splitAliases breaks the Aliases field apart, which separates aliases by commas or semicolons
+func splitAliases(raw string) []string {
+	var out []string
+	for _, alias := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ';' }) {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			out = append(out, alias)
+		}
+	}
+	return out
+}
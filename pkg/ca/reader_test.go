@@ -0,0 +1,84 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ca
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCA__read(t *testing.T) {
+	entries, err := Read(filepath.Join("..", "..", "test", "testdata", "sema-lmes.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("found %d CA records", len(entries))
+	}
+
+	// individual
+	if e := entries[0]; e.Type != TypeIndividual || e.Name() != "Min Aung Hlaing" || e.Country != "Burma" {
+		t.Errorf("individual: %#v", e)
+	}
+	if e := entries[0]; e.DateOfBirthOrShipBuildDate != "1956-07-03" || e.TitleOrShip != "Commander-in-Chief of the Armed Forces" {
+		t.Errorf("individual: %#v", e)
+	}
+
+	// entity with aliases
+	e := entries[1]
+	if e.Type != TypeEntity || e.Name() != "Tsentralnyy Bank Rossiyskoy Federatsii" {
+		t.Errorf("entity: %#v", e)
+	}
+	if len(e.Aliases) != 2 || e.Aliases[0] != "Central Bank of the Russian Federation" || e.Aliases[1] != "Bank of Russia" {
+		t.Errorf("aliases: %#v", e.Aliases)
+	}
+	if e.Schedule != "1, Part 1" || e.Item != "1104" || e.DateOfListing != "2022-02-28" {
+		t.Errorf("entity: %#v", e)
+	}
+
+	// vessel
+	if e := entries[2]; e.Type != TypeVessel || e.Name() != "SABITI" || e.ShipIMONumber != "9357183" {
+		t.Errorf("vessel: %#v", e)
+	}
+
+	if _, err := Read(filepath.Join("..", "..", "test", "testdata", "sdn.csv")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestCA__splitAliases(t *testing.T) {
+	aliases := splitAliases(" Bank of Russia, Bank Rossii ; ;")
+	if len(aliases) != 2 || aliases[0] != "Bank of Russia" || aliases[1] != "Bank Rossii" {
+		t.Errorf("%#v", aliases)
+	}
+	if aliases := splitAliases(""); len(aliases) != 0 {
+		t.Errorf("%#v", aliases)
+	}
+}
+
+func TestCA__id(t *testing.T) {
+	a := Entry{Country: "Venezuela", Schedule: "1, Part 1", Item: "41"}
+	b := Entry{Country: "Russia", Schedule: "1, Part 1", Item: "41"}
+	if a.ID() != "Venezuela/1, Part 1/41" || a.ID() == b.ID() {
+		t.Errorf("a=%q b=%q", a.ID(), b.ID())
+	}
+}
+
+func TestCA__name(t *testing.T) {
+	cases := []struct {
+		entry    Entry
+		expected string
+	}{
+		{Entry{Type: TypeIndividual, GivenName: "Nicolas", LastName: "Maduro Moros"}, "Nicolas Maduro Moros"},
+		{Entry{Type: TypeIndividual, LastName: "Hlaing"}, "Hlaing"},
+		{Entry{Type: TypeIndividual, GivenName: "Min"}, "Min"},
+		{Entry{Type: TypeEntity, EntityOrShip: "Bank of Russia"}, "Bank of Russia"},
+	}
+	for i := range cases {
+		if name := cases[i].entry.Name(); name != cases[i].expected {
+			t.Errorf("#%d: got %q", i, name)
+		}
+	}
+}
@@ -8,12 +8,15 @@ package csl
 type CSL struct {
 	SSIs []*SSI // Sectoral Sanctions Identifications List (SSI) - Treasury Department
 	ELs  []*EL  // Entity List – Bureau of Industry and Security
+	ISNs []*ISN // Nonproliferation Sanctions (ISN) - State Department
 	// []*UL (Unverified List – Bureau of Industry and Security)
 	// []*PSE (Foreign Sanctions Evaders (FSE) - Treasury Department)
-	// []*ISN (Nonproliferation Sanctions (ISN) - State Department)
 	// []*PLC (Palestinian Legislative Council List (PLC) - Treasury Department)
 	// []*CAPTA (CAPTA (formerly Foreign Financial Institutions Subject to Part 561 - Treasury Department))
 	// []*ADL (AECA Debarred List - State Department)
+	//
+	// The State Department's Terrorist Exclusion List (TEL) isn't part of the CSL, it's only
+	// published as a web page of names.
 }
 
 // This is the order of the columns in the CSL
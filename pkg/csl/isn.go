@@ -0,0 +1,30 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package csl
+
+// ISN is the Nonproliferation Sanctions List - State Department
+//
+// These records are often sparse and typically only contain a name, programs and the
+// Federal Register notice which added them.
+type ISN struct {
+	// EntityID is the unique record identifier, which is often empty for ISN records
+	EntityID string `json:"entityID"`
+	// Programs is the list of sanctions program for which the entity is flagged
+	Programs []string `json:"programs"`
+	// Name is the entity's name
+	Name string `json:"name"`
+	// FederalRegisterNotice is the Federal Register notice of the sanction
+	FederalRegisterNotice string `json:"federalRegisterNotice"`
+	// StartDate is the effective date
+	StartDate string `json:"startDate"`
+	// Remarks is used to provide additional details for the entity
+	Remarks []string `json:"remarks"`
+	// AlternateNames is a list of aliases associated with the entity
+	AlternateNames []string `json:"alternateNames"`
+	// SourceListURL is a link to the official ISN list
+	SourceListURL string `json:"sourceListURL"`
+	// SourceInfoURL is a link to information about the list
+	SourceInfoURL string `json:"sourceInfoURL"`
+}
@@ -18,6 +18,7 @@ func Read(path string) (*CSL, error) {
 
 	var ssis []*SSI
 	var els []*EL
+	var isns []*ISN
 	for {
 		record, err := reader.Read()
 		if err == io.EOF {
@@ -30,12 +31,18 @@ func Read(path string) (*CSL, error) {
 		// CSL datafiles have added a unique identifier as the first column. Thus
 		// we need to check either column 0 or 1 contains the identifier.
 		for i := 0; i <= 1; i++ {
+			if len(record[i:]) <= IDsIdx {
+				continue // skip rows missing columns rather than panic
+			}
 			switch record[i] {
 			case "Sectoral Sanctions Identifications List (SSI) - Treasury Department":
-				ssis = append(ssis, unmarshalSSI(record))
+				ssis = append(ssis, unmarshalSSI(record[i:]))
 
 			case "Entity List (EL) - Bureau of Industry and Security":
-				els = append(els, unmarshalEL(record))
+				els = append(els, unmarshalEL(record[i:]))
+
+			case "Nonproliferation Sanctions (ISN) - State Department":
+				isns = append(isns, unmarshalISN(record[i:]))
 			}
 		}
 	}
@@ -43,6 +50,7 @@ func Read(path string) (*CSL, error) {
 	return &CSL{
 		SSIs: ssis,
 		ELs:  els,
+		ISNs: isns,
 	}, nil
 }
 
@@ -75,6 +83,20 @@ func unmarshalEL(row []string) *EL {
 	}
 }
 
+func unmarshalISN(row []string) *ISN {
+	return &ISN{
+		EntityID:              row[EntityNumberIdx],
+		Programs:              expandProgramsList(row[ProgramsIdx]),
+		Name:                  strings.TrimSpace(row[NameIdx]),
+		FederalRegisterNotice: row[FRNoticeIdx],
+		StartDate:             row[StartDateIdx],
+		Remarks:               expandOptionalField(row[RemarksIdx]),
+		AlternateNames:        expandOptionalField(row[AltNamesIdx]),
+		SourceListURL:         row[SourceListURLIdx],
+		SourceInfoURL:         row[SourceInformationURLIdx],
+	}
+}
+
 // Some columns in a CSL row are actually lists delimited by ';'.
 // These helper methods split these fields out and clean up the results.
 
@@ -86,6 +108,15 @@ func expandField(addrs string) []string {
 	return result
 }
 
+// expandOptionalField is expandField, but returns nil for empty columns instead of
+// a slice containing one empty string.
+func expandOptionalField(in string) []string {
+	if strings.TrimSpace(in) == "" {
+		return nil
+	}
+	return expandField(in)
+}
+
 var prgmReplacer = strings.NewReplacer("]", "", "[", "")
 
 func expandProgramsList(prgms string) []string {
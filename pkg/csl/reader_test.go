@@ -20,6 +20,9 @@ func TestRead(t *testing.T) {
 	if len(csl.ELs) != 22 {
 		t.Errorf("len(ELs)=%d", len(csl.ELs))
 	}
+	if len(csl.ISNs) != 3 {
+		t.Errorf("len(ISNs)=%d", len(csl.ISNs))
+	}
 }
 
 func TestRead_invalidRow(t *testing.T) {
@@ -88,6 +91,26 @@ func Test_unmarshalEL(t *testing.T) {
 	}
 }
 
+func Test_unmarshalISN(t *testing.T) {
+	// ISN rows are sparse, often only a name, program and FR notice
+	record := []string{"Nonproliferation Sanctions (ISN) - State Department", "", "", "INKSNA", "183rd Guard Air Defense Missile Regiment", "", "", "Vol. 83, No. 91, 05/10/2018", "2018-04-30",
+		"", "", "", "", "", "", "", "", "", "", "", "http://bit.ly/1NuVFxV", "", "", "", "", "", "http://bit.ly/1NuVFxV", ""}
+	expectedISN := &ISN{
+		Programs:              []string{"INKSNA"},
+		Name:                  "183rd Guard Air Defense Missile Regiment",
+		FederalRegisterNotice: "Vol. 83, No. 91, 05/10/2018",
+		StartDate:             "2018-04-30",
+		SourceListURL:         "http://bit.ly/1NuVFxV",
+		SourceInfoURL:         "http://bit.ly/1NuVFxV",
+	}
+
+	actualISN := unmarshalISN(record)
+
+	if !reflect.DeepEqual(expectedISN, actualISN) {
+		t.Errorf("Expected: %#v\nFound: %#v\n", expectedISN, actualISN)
+	}
+}
+
 func Test_expandField(t *testing.T) {
 	tests := []struct {
 		input string
@@ -139,4 +162,12 @@ func TestCSL__UniqueIDs(t *testing.T) {
 	if n := len(records.ELs); n != 1332 {
 		t.Errorf("got %d EL records", n)
 	}
+	if n := len(records.ISNs); n != 151 {
+		t.Errorf("got %d ISN records", n)
+	}
+
+	// columns after the unique identifier are read from their usual index
+	if isn := records.ISNs[0]; isn.Name != "Abascience Tech Col, Ltd." || isn.StartDate != "2019-05-14" || len(isn.Programs) != 1 || isn.Programs[0] != "INKSNA" {
+		t.Errorf("ISN=%#v", isn)
+	}
 }
@@ -0,0 +1,31 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package custom
+
+const (
+	TypeIndividual = "individual"
+	TypeEntity     = "entity"
+)
+
+// Entry is a record on a custom (private) watchlist, such as an internal blocklist of
+// high-risk parties which is screened alongside the government lists.
+type Entry struct {
+	// ID is the record's unique identifier on the list
+	ID string `json:"id"`
+	// Name is the primary name of the individual or entity
+	Name string `json:"name"`
+	// Type is individual or entity, records without a type are entities
+	Type string `json:"type"`
+	// Aliases are other names of the record
+	Aliases []string `json:"aliases"`
+	// Addresses are free text addresses of the record
+	Addresses []string `json:"addresses"`
+	// DatesOfBirth of an individual, in any format
+	DatesOfBirth []string `json:"datesOfBirth"`
+	// IDNumbers are passport, tax or other identification numbers
+	IDNumbers []string `json:"idNumbers"`
+	// Remarks is free text kept with the record, like why it's listed
+	Remarks string `json:"remarks"`
+}
@@ -0,0 +1,158 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package custom
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Read parses a custom list from a CSV or JSON file, chosen by the file's extension.
+// Records without a name are skipped and every record must have an ID.
+//
+// JSON files are an array of Entry objects. CSV files start with a header row naming the
+// columns: id and name are required, and type, aliases, addresses, datesOfBirth, idNumbers
+// and remarks are optional. Columns with several values (aliases, addresses, datesOfBirth
+// and idNumbers) separate them with a semicolon.
+func Read(path string) ([]*Entry, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var entries []*Entry
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		entries, err = readJSON(fd)
+	case ".csv":
+		entries, err = readCSV(fd)
+	default:
+		return nil, fmt.Errorf("unknown custom list format %q, expected .csv or .json", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*Entry
+	for i := range entries {
+		if entries[i] == nil {
+			continue
+		}
+		entry := clean(entries[i])
+		if entry.Name == "" {
+			continue
+		}
+		if entry.ID == "" {
+			return nil, fmt.Errorf("record %d (%s) has no id", i+1, entry.Name)
+		}
+		out = append(out, entry)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no records found")
+	}
+	return out, nil
+}
+
+func readJSON(r io.Reader) ([]*Entry, error) {
+	var entries []*Entry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func readCSV(r io.Reader) ([]*Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("header: %v", err)
+	}
+	columns := make(map[string]int)
+	for i := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))] = i
+	}
+	for _, required := range []string{"id", "name"} {
+		if _, exists := columns[required]; !exists {
+			return nil, fmt.Errorf("missing %s column", required)
+		}
+	}
+
+	var out []*Entry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		field := func(name string) string {
+			if i, exists := columns[strings.ToLower(name)]; exists && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		out = append(out, &Entry{
+			ID:           field("id"),
+			Name:         field("name"),
+			Type:         field("type"),
+			Aliases:      splitValues(field("aliases")),
+			Addresses:    splitValues(field("addresses")),
+			DatesOfBirth: splitValues(field("datesOfBirth")),
+			IDNumbers:    splitValues(field("idNumbers")),
+			Remarks:      field("remarks"),
+		})
+	}
+	return out, nil
+}
+
+// splitValues returns the semicolon separated values of a CSV column
+func splitValues(raw string) []string {
+	var out []string
+	for _, v := range strings.Split(raw, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func clean(in *Entry) *Entry {
+	out := &Entry{
+		ID:      strings.TrimSpace(in.ID),
+		Name:    strings.TrimSpace(in.Name),
+		Type:    TypeEntity,
+		Remarks: strings.TrimSpace(in.Remarks),
+	}
+	if strings.EqualFold(strings.TrimSpace(in.Type), TypeIndividual) {
+		out.Type = TypeIndividual
+	}
+	for _, vs := range []struct {
+		in  []string
+		out *[]string
+	}{
+		{in.Aliases, &out.Aliases},
+		{in.Addresses, &out.Addresses},
+		{in.DatesOfBirth, &out.DatesOfBirth},
+		{in.IDNumbers, &out.IDNumbers},
+	} {
+		for _, v := range vs.in {
+			if v = strings.TrimSpace(v); v != "" {
+				*vs.out = append(*vs.out, v)
+			}
+		}
+	}
+	return out
+}
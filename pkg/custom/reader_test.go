@@ -0,0 +1,91 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package custom
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCustom__readCSV(t *testing.T) {
+	entries, err := Read(filepath.Join("..", "..", "test", "testdata", "custom-list.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("found %d custom records", len(entries))
+	}
+
+	e := entries[0]
+	if e.ID != "BL-001" || e.Name != "PETROV, Ivan" || e.Type != TypeIndividual || e.Remarks != "Chargeback fraud ring" {
+		t.Errorf("individual: %#v", e)
+	}
+	if len(e.Aliases) != 2 || e.Aliases[0] != "Ivan Petrov" || e.Aliases[1] != "I. Petrov" {
+		t.Errorf("aliases: %#v", e.Aliases)
+	}
+	if len(e.Addresses) != 1 || e.Addresses[0] != "12 Harbour Road, Limassol, Cyprus" {
+		t.Errorf("addresses: %#v", e.Addresses)
+	}
+	if len(e.DatesOfBirth) != 1 || len(e.IDNumbers) != 1 || e.IDNumbers[0] != "P1234567" {
+		t.Errorf("individual: %#v", e)
+	}
+
+	if e := entries[1]; e.ID != "BL-002" || e.Type != TypeEntity || len(e.DatesOfBirth) != 0 {
+		t.Errorf("entity: %#v", e)
+	}
+	// records without a type are entities, the nameless record is skipped
+	if e := entries[2]; e.ID != "BL-004" || e.Name != "Northwind Trading 7" || e.Type != TypeEntity {
+		t.Errorf("untyped: %#v", e)
+	}
+}
+
+func TestCustom__readJSON(t *testing.T) {
+	entries, err := Read(filepath.Join("..", "..", "test", "testdata", "custom-list.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("found %d custom records", len(entries))
+	}
+	if e := entries[0]; e.ID != "BL-101" || e.Type != TypeIndividual || len(e.Aliases) != 1 || e.IDNumbers[0] != "X9988776" {
+		t.Errorf("individual: %#v", e)
+	}
+	if e := entries[1]; e.Name != "Apex Crypto Exchange" || e.Type != TypeEntity || len(e.Addresses) != 1 {
+		t.Errorf("entity: %#v", e)
+	}
+}
+
+func TestCustom__readErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := map[string]string{
+		"list.txt":      "id,name\n1,Acme\n",
+		"noid.csv":      "id,name\n,Acme\n",
+		"columns.csv":   "name\nAcme\n",
+		"empty.csv":     "id,name\n",
+		"broken.json":   `[{"id": "1"`,
+		"nameless.json": `[{"id": "1"}]`,
+	}
+	for name, contents := range cases {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if entries, err := Read(path); err == nil {
+			t.Errorf("%s: expected error: %#v", name, entries)
+		}
+	}
+
+	if _, err := Read(filepath.Join(dir, "missing.csv")); err == nil || !strings.Contains(err.Error(), "missing.csv") {
+		t.Errorf("expected error: %v", err)
+	}
+}
@@ -25,12 +25,23 @@ var (
 	HTTPClient = &http.Client{
 		Timeout: 15 * time.Second,
 	}
+
+	// MaxAttempts is how many HTTP requests New's Downloaders make for each file before giving up
+	MaxAttempts = 3
+
+	// RetryBackoff is how long New's Downloaders wait after a file's first failed attempt, the
+	// wait doubles after each further one (up to maxRetryBackoff)
+	RetryBackoff = 100 * time.Millisecond
 )
 
+const maxRetryBackoff = time.Minute
+
 func New(logger log.Logger, httpClient *http.Client) *Downloader {
 	return &Downloader{
-		HTTP:   httpClient,
-		Logger: logger,
+		HTTP:         httpClient,
+		Logger:       logger,
+		MaxAttempts:  MaxAttempts,
+		RetryBackoff: RetryBackoff,
 	}
 }
 
@@ -42,6 +53,12 @@ func New(logger log.Logger, httpClient *http.Client) *Downloader {
 type Downloader struct {
 	HTTP   *http.Client
 	Logger log.Logger
+
+	// MaxAttempts is how many HTTP requests are made for each file, the package's MaxAttempts
+	// when it's zero. RetryBackoff is the wait after the first failed attempt, which doubles
+	// after each further one.
+	MaxAttempts  int
+	RetryBackoff time.Duration
 }
 
 // GetFiles will download all provided files, return their filepaths, and store them in a
@@ -60,6 +77,9 @@ func (dl *Downloader) GetFiles(initialDir string, namesAndSources map[string]str
 	if dl.Logger == nil {
 		dl.Logger = log.NewNopLogger()
 	}
+	if dl.MaxAttempts == 0 {
+		dl.MaxAttempts = MaxAttempts
+	}
 
 	// Create a temporary directory for downloads
 	dir, err := ioutil.TempDir("", "downloader")
@@ -109,34 +129,9 @@ func (dl *Downloader) GetFiles(initialDir string, namesAndSources map[string]str
 				}
 			}
 
-			// Allow a couple retries for various sources (some are flakey)
-			for i := 0; i < 3; i++ {
-				req, err := http.NewRequest("GET", downloadURL, nil)
-				if err != nil {
-					dl.Logger.Log("download", fmt.Sprintf("error building HTTP request: %v", err))
-					return
-				}
-				req.Header.Set("User-Agent", fmt.Sprintf("moov-io/watchman:%v", watchman.Version))
-
-				resp, err := dl.HTTP.Do(req)
-				if err != nil {
-					time.Sleep(100 * time.Millisecond)
-					continue // retry
-				}
-
-				// Copy resp.Body into a file in our temp dir
-				fd, err := os.Create(filepath.Join(dir, filename))
-				if err != nil {
-					resp.Body.Close()
-					return
-				}
-
-				io.Copy(fd, resp.Body) // copy file contents
-
-				// close the open files
-				fd.Close()
-				resp.Body.Close()
-				return // quit after successful download
+			// Retry failed requests, see MaxAttempts (some sources are flakey)
+			if err := dl.download(downloadURL, filepath.Join(dir, filename)); err != nil {
+				dl.Logger.Log("download", fmt.Sprintf("problem downloading %s: %v", filename, err))
 			}
 		}(&wg, name, source)
 	}
@@ -156,6 +151,152 @@ func (dl *Downloader) GetFiles(initialDir string, namesAndSources map[string]str
 	return out, nil
 }
 
+// retryBackoff returns how long to wait before the attempt'th (from zero) request of a file
+func (dl *Downloader) retryBackoff(attempt int) time.Duration {
+	wait := dl.RetryBackoff
+	for i := 1; i < attempt && wait < maxRetryBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return wait
+}
+
+// download writes the contents of downloadURL to path. The response is first written to a
+// partial file which is only renamed into path once its size matches what the server sent.
+//
+// Interrupted transfers are resumed with a Range request when the server advertised
+// "Accept-Ranges: bytes" and a Content-Length, otherwise the file is downloaded again.
+func (dl *Downloader) download(downloadURL, path string) error {
+	part := path + ".part"
+	fd, err := os.Create(part)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(part) // no-op after a successful rename
+	defer fd.Close()
+
+	var (
+		written   int64
+		total     int64 = -1 // unknown
+		resumable bool
+		validator string // ETag or Last-Modified sent as If-Range
+
+		lastErr error
+	)
+	restart := func() error {
+		written = 0
+		if err := fd.Truncate(0); err != nil {
+			return err
+		}
+		_, err := fd.Seek(0, io.SeekStart)
+		return err
+	}
+
+	attempts := dl.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			wait := dl.retryBackoff(i)
+			dl.Logger.Log("download", fmt.Sprintf("attempt %d of %d downloading %s failed, retrying in %v: %v", i, attempts, filepath.Base(path), wait, lastErr))
+			time.Sleep(wait)
+		}
+
+		req, err := http.NewRequest("GET", downloadURL, nil)
+		if err != nil {
+			return fmt.Errorf("error building HTTP request: %v", err)
+		}
+		req.Header.Set("User-Agent", fmt.Sprintf("moov-io/watchman:%v", watchman.Version))
+		if resumable && written > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", written))
+			if validator != "" {
+				req.Header.Set("If-Range", validator)
+			}
+		}
+
+		resp, err := dl.HTTP.Do(req)
+		if err != nil {
+			lastErr = err
+			continue // retry
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusPartialContent && written > 0:
+			start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+			if err != nil || start != written || size != total {
+				// we can't trust this range, so start over
+				resp.Body.Close()
+				resumable = false
+				lastErr = fmt.Errorf("unexpected Content-Range %q (resuming at %d of %d)", resp.Header.Get("Content-Range"), written, total)
+				if err := restart(); err != nil {
+					return err
+				}
+				continue
+			}
+
+		case resp.StatusCode == http.StatusOK:
+			// a full response, either our first request or the server ignored our Range
+			if err := restart(); err != nil {
+				resp.Body.Close()
+				return err
+			}
+			total = resp.ContentLength
+			resumable = total > 0 && strings.EqualFold(resp.Header.Get("Accept-Ranges"), "bytes")
+			validator = resp.Header.Get("ETag")
+			if validator == "" {
+				validator = resp.Header.Get("Last-Modified")
+			}
+
+		default:
+			resp.Body.Close()
+			lastErr = fmt.Errorf("unexpected HTTP status: %s", resp.Status)
+			continue
+		}
+
+		n, err := io.Copy(fd, resp.Body)
+		resp.Body.Close()
+		written += n
+		if err != nil {
+			lastErr = fmt.Errorf("interrupted after %d bytes: %v", written, err)
+			if !resumable {
+				if err := restart(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if total >= 0 && written != total {
+			lastErr = fmt.Errorf("downloaded %d of %d bytes", written, total)
+			if err := restart(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fd.Close(); err != nil {
+			return err
+		}
+		return os.Rename(part, path)
+	}
+	return lastErr
+}
+
+// parseContentRange reads a Content-Range header like "bytes 100-199/200" and returns the
+// first byte's offset and the total size.
+func parseContentRange(header string) (int64, int64, error) {
+	var start, end, size int64
+	if _, err := fmt.Sscanf(header, "bytes %d-%d/%d", &start, &end, &size); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q: %v", header, err)
+	}
+	if start > end || end >= size {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	return start, size, nil
+}
+
 func compareNames(found []os.FileInfo, expected map[string]string) (string, string) {
 	var matched []string
 	var missing []string
@@ -0,0 +1,255 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package download
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	fileContents = bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	modTime      = time.Date(2020, time.October, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// flakyServer drops the connection mid-transfer for the first `interruptions` requests
+type flakyServer struct {
+	mu            sync.Mutex
+	interruptions int
+	ranges        bool
+	rangeHeaders  []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.rangeHeaders = append(s.rangeHeaders, r.Header.Get("Range"))
+	interrupt := s.interruptions > 0
+	s.interruptions--
+	s.mu.Unlock()
+
+	if !s.ranges {
+		r.Header.Del("Range")
+	}
+	if interrupt {
+		if s.ranges {
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(fileContents)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(fileContents[:len(fileContents)/3])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler) // close the connection
+	}
+	if s.ranges {
+		http.ServeContent(w, r, "file.txt", modTime, bytes.NewReader(fileContents))
+	} else {
+		w.Header().Set("Content-Length", strconv.Itoa(len(fileContents)))
+		w.Write(fileContents)
+	}
+}
+
+func getFile(t *testing.T, server *httptest.Server) (string, error) {
+	t.Helper()
+
+	dl := New(log.NewNopLogger(), server.Client())
+	files, err := dl.GetFiles("", map[string]string{
+		"file.txt": server.URL + "/file.txt",
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(files) != 1 {
+		t.Fatalf("unexpected files: %v", files)
+	}
+	return files[0], nil
+}
+
+func checkFile(t *testing.T, path string) {
+	t.Helper()
+	defer os.RemoveAll(filepath.Dir(path))
+
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bs, fileContents) {
+		t.Errorf("got %d bytes, expected %d", len(bs), len(fileContents))
+	}
+}
+
+func TestDownloader__resume(t *testing.T) {
+	handler := &flakyServer{interruptions: 1, ranges: true}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	path, err := getFile(t, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, path)
+
+	if len(handler.rangeHeaders) != 2 {
+		t.Fatalf("unexpected requests: %q", handler.rangeHeaders)
+	}
+	if h := handler.rangeHeaders[1]; h != "bytes="+strconv.Itoa(len(fileContents)/3)+"-" {
+		t.Errorf("unexpected Range: %q", h)
+	}
+}
+
+func TestDownloader__noRanges(t *testing.T) {
+	handler := &flakyServer{interruptions: 1, ranges: false}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	path, err := getFile(t, server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, path)
+
+	// the retry downloads the entire file again
+	if len(handler.rangeHeaders) != 2 || handler.rangeHeaders[1] != "" {
+		t.Errorf("unexpected Range headers: %q", handler.rangeHeaders)
+	}
+}
+
+func TestDownloader__interrupted(t *testing.T) {
+	handler := &flakyServer{interruptions: MaxAttempts, ranges: false}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// a partial file is never committed
+	if path, err := getFile(t, server); err == nil {
+		t.Errorf("expected error, got %s", path)
+	}
+}
+
+func TestDownloader__parseContentRange(t *testing.T) {
+	start, size, err := parseContentRange("bytes 100-199/200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if start != 100 || size != 200 {
+		t.Errorf("start=%d size=%d", start, size)
+	}
+
+	for _, h := range []string{"", "bytes */200", "bytes 100-99/200", "bytes 0-200/200"} {
+		if _, _, err := parseContentRange(h); err == nil {
+			t.Errorf("expected error for %q", h)
+		}
+	}
+}
+
+// failingServer responds with a 503 to the first `failures` requests
+type failingServer struct {
+	mu       sync.Mutex
+	failures int
+	requests []time.Time
+}
+
+func (s *failingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, time.Now())
+	fail := s.failures > 0
+	s.failures--
+	s.mu.Unlock()
+
+	if fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.Write(fileContents)
+}
+
+type logLines struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *logLines) Log(keyvals ...interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprint(keyvals...))
+	return nil
+}
+
+func TestDownloader__retries(t *testing.T) {
+	handler := &failingServer{failures: 2}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	logger := &logLines{}
+	dl := New(logger, server.Client())
+	dl.MaxAttempts, dl.RetryBackoff = 3, 20*time.Millisecond
+
+	files, err := dl.GetFiles("", map[string]string{
+		"file.txt": server.URL + "/file.txt",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, files[0])
+
+	// each failed attempt is logged and followed by a longer wait
+	if len(handler.requests) != 3 {
+		t.Fatalf("%d requests", len(handler.requests))
+	}
+	if wait := handler.requests[1].Sub(handler.requests[0]); wait < 20*time.Millisecond {
+		t.Errorf("first retry after %v", wait)
+	}
+	if wait := handler.requests[2].Sub(handler.requests[1]); wait < 40*time.Millisecond {
+		t.Errorf("second retry after %v", wait)
+	}
+	if len(logger.lines) != 2 {
+		t.Fatalf("logged: %q", logger.lines)
+	}
+	for i, line := range logger.lines {
+		if expected := fmt.Sprintf("attempt %d of 3 downloading file.txt failed", i+1); !strings.Contains(line, expected) || !strings.Contains(line, "503") {
+			t.Errorf("logged %q", line)
+		}
+	}
+
+	// fewer attempts give up
+	handler = &failingServer{failures: 2}
+	server = httptest.NewServer(handler)
+	defer server.Close()
+
+	dl = New(log.NewNopLogger(), server.Client())
+	dl.MaxAttempts, dl.RetryBackoff = 2, time.Millisecond
+	if _, err := dl.GetFiles("", map[string]string{"file.txt": server.URL + "/file.txt"}); err == nil {
+		t.Error("expected error")
+	}
+	if len(handler.requests) != 2 {
+		t.Errorf("%d requests", len(handler.requests))
+	}
+}
+
+func TestDownloader__retryBackoff(t *testing.T) {
+	dl := &Downloader{RetryBackoff: time.Second}
+	for attempt, expected := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		7:  maxRetryBackoff,
+		50: maxRetryBackoff,
+	} {
+		if wait := dl.retryBackoff(attempt); wait != expected {
+			t.Errorf("attempt %d: %v", attempt, wait)
+		}
+	}
+}
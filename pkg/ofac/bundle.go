@@ -0,0 +1,115 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ofac
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const ofacBundleFilename = "ofac.zip"
+
+var (
+	// ofacBundleURL is the HTTP address of a zip archive which contains every OFAC file. When it's
+	// set the archive is downloaded (or found in the initial directory) instead of each file.
+	ofacBundleURL = os.Getenv("OFAC_DOWNLOAD_BUNDLE")
+)
+
+// unzipBundle extracts the OFAC files of the zip archive at path into dir and returns their
+// filepaths. Files are matched by name (case-insensitively, in any directory of the archive)
+// to ofacFilenames so Read can parse them. An error is returned if the archive contains any
+// other file, or is missing one.
+func unzipBundle(path, dir string) ([]string, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Base(path), err)
+	}
+	defer r.Close()
+
+	found := make(map[string]bool)
+	var out []string
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name, err := bundleFilename(f.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filepath.Base(path), err)
+		}
+		if found[name] {
+			return nil, fmt.Errorf("%s: found %s more than once", filepath.Base(path), name)
+		}
+		found[name] = true
+
+		where := filepath.Join(dir, name)
+		if err := extractFile(f, where); err != nil {
+			return nil, fmt.Errorf("%s: extracting %s: %v", filepath.Base(path), f.Name, err)
+		}
+		out = append(out, where)
+	}
+
+	var missing []string
+	for _, name := range ofacFilenames {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s: missing %s", filepath.Base(path), strings.Join(missing, ", "))
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// bundleFilename returns which of ofacFilenames an archive entry is
+func bundleFilename(entry string) (string, error) {
+	base := strings.ToLower(path.Base(entry))
+	for _, name := range ofacFilenames {
+		if base == name {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unexpected file %s, expected only %s", entry, strings.Join(ofacFilenames, ", "))
+}
+
+func extractFile(f *zip.File, where string) error {
+	in, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(where)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// WriteBundle writes res to w as a zip archive of ofacFilenames, which can be served as
+// OFAC_DOWNLOAD_BUNDLE to load the same records into another instance.
+func WriteBundle(w io.Writer, res *Results) error {
+	zw := zip.NewWriter(w)
+	for _, name := range ofacFilenames {
+		f, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if err := fileWriters[name](f, res); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ofac
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestBundle__download(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join("..", "..", "test", "testdata", "ofac.zip"))
+	}))
+	defer server.Close()
+
+	defer func(url string) { ofacBundleURL = url }(ofacBundleURL)
+	ofacBundleURL = server.URL + "/sdn_csv.zip"
+
+	files, err := Download(log.NewNopLogger(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(filepath.Dir(files[0]))
+
+	if len(files) != 4 {
+		t.Fatalf("found %d files: %v", len(files), files)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(files[0]), ofacBundleFilename)); !os.IsNotExist(err) {
+		t.Errorf("bundle wasn't removed: %v", err)
+	}
+
+	// each file is routed to its parser
+	results := &Results{}
+	for i := range files {
+		res, err := Read(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		results.Addresses = append(results.Addresses, res.Addresses...)
+		results.AlternateIdentities = append(results.AlternateIdentities, res.AlternateIdentities...)
+		results.SDNs = append(results.SDNs, res.SDNs...)
+		results.SDNComments = append(results.SDNComments, res.SDNComments...)
+	}
+	if len(results.SDNs) != 25 || len(results.Addresses) != 25 || len(results.AlternateIdentities) != 25 || len(results.SDNComments) == 0 {
+		t.Errorf("SDNs=%d Addresses=%d AlternateIdentities=%d SDNComments=%d",
+			len(results.SDNs), len(results.Addresses), len(results.AlternateIdentities), len(results.SDNComments))
+	}
+	if sdn := results.SDNs[2]; sdn.EntityID != "306" || sdn.SDNName != "BANCO NACIONAL DE CUBA" {
+		t.Errorf("unexpected SDN: %#v", sdn)
+	}
+}
+
+func TestBundle__invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ofac-bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mk := func(t *testing.T, names ...string) string {
+		t.Helper()
+		path := filepath.Join(dir, ofacBundleFilename)
+		fd, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fd.Close()
+		w := zip.NewWriter(fd)
+		for _, name := range names {
+			f, err := w.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.Write([]byte("\n"))
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	_, err = unzipBundle(mk(t, "add.csv", "alt.csv", "sdn.csv", "sdn_comments.csv", "README.txt"), dir)
+	if err == nil || !strings.Contains(err.Error(), "unexpected file README.txt") {
+		t.Errorf("expected error: %v", err)
+	}
+	_, err = unzipBundle(mk(t, "SDN.CSV", "sdn/sdn.csv"), dir)
+	if err == nil || !strings.Contains(err.Error(), "found sdn.csv more than once") {
+		t.Errorf("expected error: %v", err)
+	}
+	_, err = unzipBundle(mk(t, "sdn.csv", "alt.csv"), dir)
+	if err == nil || !strings.Contains(err.Error(), "missing add.csv, sdn_comments.csv") {
+		t.Errorf("expected error: %v", err)
+	}
+
+	path := filepath.Join(dir, "sdn.csv")
+	if err := ioutil.WriteFile(path, []byte("not a zip"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unzipBundle(path, dir); err == nil {
+		t.Error("expected error")
+	}
+}
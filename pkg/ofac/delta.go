@@ -0,0 +1,117 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ofac
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/moov-io/watchman/pkg/download"
+
+	"github.com/go-kit/kit/log"
+)
+
+const ofacDeltaFilename = "sdn_delta.json"
+
+// Delta is a change file of the OFAC lists. Deltas are numbered by Sequence and each one
+// changes the records of PreviousSequence.
+type Delta struct {
+	Sequence         int64 `json:"sequence"`
+	PreviousSequence int64 `json:"previousSequence"`
+
+	// SDNs were added or updated, their Addresses and AlternateIdentities replace every
+	// address and alternate identity of the SDN
+	SDNs                []*SDN               `json:"sdns"`
+	Addresses           []*Address           `json:"addresses"`
+	AlternateIdentities []*AlternateIdentity `json:"alternateIdentities"`
+
+	// Removed are the entity IDs of SDNs which were delisted
+	Removed []string `json:"removed"`
+}
+
+// DownloadDelta returns the filepath of the delta file at deltaURL, see download.Downloader
+// for how initialDir is used. Deltas are published alongside full files which already include
+// their changes.
+func DownloadDelta(logger log.Logger, initialDir, deltaURL string) (string, error) {
+	dl := download.New(logger, download.HTTPClient)
+	files, err := dl.GetFiles(initialDir, map[string]string{
+		ofacDeltaFilename: deltaURL,
+	})
+	if err != nil {
+		return "", err
+	}
+	return files[0], nil
+}
+
+// ReadDelta parses the delta file at path and checks every change belongs to an SDN of it
+func ReadDelta(path string) (*Delta, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var delta Delta
+	if err := json.NewDecoder(fd).Decode(&delta); err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Base(path), err)
+	}
+	if err := delta.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", filepath.Base(path), err)
+	}
+	return &delta, nil
+}
+
+func (d *Delta) validate() error {
+	if d.Sequence <= 0 {
+		return errors.New("missing sequence")
+	}
+	if d.PreviousSequence >= d.Sequence {
+		return fmt.Errorf("previousSequence %d isn't before sequence %d", d.PreviousSequence, d.Sequence)
+	}
+	changed := make(map[string]bool)
+	for i := range d.SDNs {
+		if d.SDNs[i] == nil || d.SDNs[i].EntityID == "" {
+			return fmt.Errorf("SDN %d has no entityID", i)
+		}
+		changed[d.SDNs[i].EntityID] = true
+	}
+	for i := range d.Addresses {
+		switch addr := d.Addresses[i]; {
+		case addr == nil:
+			return fmt.Errorf("address %d is empty", i)
+		case !changed[addr.EntityID]:
+			return fmt.Errorf("address %s of SDN %s which isn't in the delta", addr.AddressID, addr.EntityID)
+		}
+	}
+	for i := range d.AlternateIdentities {
+		switch alt := d.AlternateIdentities[i]; {
+		case alt == nil:
+			return fmt.Errorf("alternate identity %d is empty", i)
+		case !changed[alt.EntityID]:
+			return fmt.Errorf("alternate identity %s of SDN %s which isn't in the delta", alt.AlternateID, alt.EntityID)
+		}
+	}
+	for _, id := range d.Removed {
+		if changed[id] {
+			return fmt.Errorf("SDN %s is both changed and removed", id)
+		}
+	}
+	return nil
+}
+
+// Changed returns the entity IDs of every SDN the delta adds, updates or removes
+func (d *Delta) Changed() map[string]bool {
+	out := make(map[string]bool, len(d.SDNs)+len(d.Removed))
+	for i := range d.SDNs {
+		out[d.SDNs[i].EntityID] = true
+	}
+	for _, id := range d.Removed {
+		out[id] = true
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ofac
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestDelta__read(t *testing.T) {
+	delta, err := ReadDelta(filepath.Join("..", "..", "test", "testdata", "sdn_delta.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delta.Sequence != 2 || delta.PreviousSequence != 1 {
+		t.Errorf("sequence=%d previous=%d", delta.Sequence, delta.PreviousSequence)
+	}
+	if len(delta.SDNs) != 2 || len(delta.Addresses) != 3 || len(delta.AlternateIdentities) != 1 || len(delta.Removed) != 1 {
+		t.Errorf("%#v", delta)
+	}
+	if sdn := delta.SDNs[1]; sdn.EntityID != "99001" || sdn.SDNName != "NORTHERN STAR SHIPPING LLC" || len(sdn.Programs) != 1 {
+		t.Errorf("%#v", sdn)
+	}
+
+	changed := delta.Changed()
+	if len(changed) != 3 || !changed["173"] || !changed["99001"] || !changed["36"] {
+		t.Errorf("changed: %v", changed)
+	}
+}
+
+func TestDelta__invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ofac-delta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := map[string]string{
+		"not json":         `sequence=2`,
+		"no sequence":      `{"previousSequence": 1}`,
+		"out of order":     `{"sequence": 2, "previousSequence": 2}`,
+		"no entity ID":     `{"sequence": 2, "previousSequence": 1, "sdns": [{"sdnName": "ACME"}]}`,
+		"orphan address":   `{"sequence": 2, "previousSequence": 1, "addresses": [{"entityID": "1", "addressID": "2"}]}`,
+		"orphan alt":       `{"sequence": 2, "previousSequence": 1, "alternateIdentities": [{"entityID": "1", "alternateID": "2"}]}`,
+		"empty address":    `{"sequence": 2, "previousSequence": 1, "addresses": [null]}`,
+		"changed, removed": `{"sequence": 2, "previousSequence": 1, "sdns": [{"entityID": "1"}], "removed": ["1"]}`,
+	}
+	for desc, contents := range cases {
+		path := filepath.Join(dir, ofacDeltaFilename)
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadDelta(path); err == nil {
+			t.Errorf("%s: expected error", desc)
+		}
+	}
+	if _, err := ReadDelta(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestDelta__download(t *testing.T) {
+	// the delta is copied from initialDir
+	path, err := DownloadDelta(log.NewNopLogger(), filepath.Join("..", "..", "test", "testdata"), "http://localhost:0/sdn_delta.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(filepath.Dir(path))
+	if filepath.Base(path) != ofacDeltaFilename {
+		t.Errorf("unexpected file: %s", path)
+	}
+	if _, err := ReadDelta(path); err != nil {
+		t.Error(err)
+	}
+}
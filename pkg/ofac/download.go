@@ -7,6 +7,7 @@ package ofac
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/moov-io/watchman/pkg/download"
 
@@ -29,9 +30,22 @@ var (
 	}()
 )
 
+// Download returns the filepaths of each OFAC file, see download.Downloader for how initialDir is used.
+// With OFAC_DOWNLOAD_BUNDLE set a single zip archive is downloaded and unpacked instead.
 func Download(logger log.Logger, initialDir string) ([]string, error) {
 	dl := download.New(logger, download.HTTPClient)
 
+	if ofacBundleURL != "" {
+		files, err := dl.GetFiles(initialDir, map[string]string{
+			ofacBundleFilename: ofacBundleURL,
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(files[0])
+		return unzipBundle(files[0], filepath.Dir(files[0]))
+	}
+
 	addrs := make(map[string]string)
 	for i := range ofacFilenames {
 		addrs[ofacFilenames[i]] = fmt.Sprintf(ofacURLTemplate, ofacFilenames[i])
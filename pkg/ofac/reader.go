@@ -6,6 +6,7 @@ package ofac
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -61,6 +62,31 @@ type Results struct {
 
 	// SDNComments returns an array of OFAC Specially Designated National Comments
 	SDNComments []*SDNComments `json:"sdnComments"`
+
+	// Warnings describes each malformed record which was skipped while reading
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// readRecord returns the next CSV record and if it should be kept. Records without the expected
+// number of fields are skipped with a warning. io.EOF is returned after the last record.
+func readRecord(reader *csv.Reader, file string, fields int, warnings *[]string) ([]string, bool, error) {
+	record, err := reader.Read()
+	if err == io.EOF {
+		return nil, false, err
+	}
+	if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+		*warnings = append(*warnings, fmt.Sprintf("%s: %v", file, err))
+		return nil, false, nil
+	}
+	if len(record) != fields {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			file = fmt.Sprintf("%s line %d", file, perr.Line)
+		}
+		*warnings = append(*warnings, fmt.Sprintf("%s: expected %d fields, found %d", file, fields, len(record)))
+		return nil, false, nil
+	}
+	return record, true, nil
 }
 
 func csvAddressFile(path string) (*Results, error) {
@@ -72,18 +98,16 @@ func csvAddressFile(path string) (*Results, error) {
 	defer f.Close()
 
 	var out []*Address
+	var warnings []string
 
 	// Read File into a Variable
 	reader := csv.NewReader(f)
 	for {
-		record, err := reader.Read()
-		if err != nil && err == csv.ErrFieldCount {
-			continue
-		}
-		if err == io.EOF { // TODO(Adam): add max line count break here also
+		record, ok, err := readRecord(reader, "add.csv", 6, &warnings)
+		if err == io.EOF {
 			break
 		}
-		if len(record) != 6 {
+		if !ok {
 			continue
 		}
 
@@ -97,7 +121,7 @@ func csvAddressFile(path string) (*Results, error) {
 			AddressRemarks:              record[5],
 		})
 	}
-	return &Results{Addresses: out}, nil
+	return &Results{Addresses: out, Warnings: warnings}, nil
 }
 
 func csvAlternateIdentityFile(path string) (*Results, error) {
@@ -109,18 +133,16 @@ func csvAlternateIdentityFile(path string) (*Results, error) {
 	defer f.Close()
 
 	var out []*AlternateIdentity
+	var warnings []string
 
 	// Read File into a Variable
 	reader := csv.NewReader(f)
 	for {
-		record, err := reader.Read()
-		if err != nil && err == csv.ErrFieldCount {
-			continue
-		}
-		if err == io.EOF { // TODO(adam)
+		record, ok, err := readRecord(reader, "alt.csv", 5, &warnings)
+		if err == io.EOF {
 			break
 		}
-		if len(record) != 5 {
+		if !ok {
 			continue
 		}
 		record = replaceNull(record)
@@ -132,7 +154,7 @@ func csvAlternateIdentityFile(path string) (*Results, error) {
 			AlternateRemarks: record[4],
 		})
 	}
-	return &Results{AlternateIdentities: out}, nil
+	return &Results{AlternateIdentities: out, Warnings: warnings}, nil
 }
 
 func csvSDNFile(path string) (*Results, error) {
@@ -144,18 +166,16 @@ func csvSDNFile(path string) (*Results, error) {
 	defer f.Close()
 
 	var out []*SDN
+	var warnings []string
 
 	// Read File into a Variable
 	reader := csv.NewReader(f)
 	for {
-		record, err := reader.Read()
-		if err != nil && err == csv.ErrFieldCount {
-			continue
-		}
-		if err == io.EOF { // TODO(Adam): add max line count break here also
+		record, ok, err := readRecord(reader, "sdn.csv", 12, &warnings)
+		if err == io.EOF {
 			break
 		}
-		if len(record) != 12 {
+		if !ok {
 			continue
 		}
 		record = replaceNull(record)
@@ -174,7 +194,7 @@ func csvSDNFile(path string) (*Results, error) {
 			Remarks:                record[11],
 		})
 	}
-	return &Results{SDNs: out}, nil
+	return &Results{SDNs: out, Warnings: warnings}, nil
 }
 
 func csvSDNCommentsFile(path string) (*Results, error) {
@@ -205,11 +225,15 @@ func csvSDNCommentsFile(path string) (*Results, error) {
 	return &Results{SDNComments: out}, nil
 }
 
-// replaceNull replaces a CSV field that contain -0- with "".  Null values for all four formats consist of "-0-"
-// (ASCII characters 45, 48, 45).
+// replaceNull replaces a CSV field that is -0- with "".  Null values for all four formats consist of "-0-"
+// (ASCII characters 45, 48, 45). Other fields are only trimmed, so a "-0-" within remarks or
+// an ID is kept.
 func replaceNull(s []string) []string {
 	for i := 0; i < len(s); i++ {
-		s[i] = strings.TrimSpace(strings.Replace(s[i], "-0-", "", -1))
+		s[i] = strings.TrimSpace(s[i])
+		if s[i] == "-0-" {
+			s[i] = ""
+		}
 	}
 	return s
 }
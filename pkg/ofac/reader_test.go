@@ -6,8 +6,10 @@ package ofac
 
 import (
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -59,6 +61,27 @@ func TestReplaceNull(t *testing.T) {
 	if len(ans) != 2 || ans[0] != "foo" || ans[1] != "" {
 		t.Errorf("Got %v", ans)
 	}
+	ans = replaceNull([]string{"US FEIN CH-660-0-469-982-0 (United States)."})
+	if len(ans) != 1 || ans[0] != "US FEIN CH-660-0-469-982-0 (United States)." {
+		t.Errorf("Got %v", ans)
+	}
+}
+
+func TestOFAC__readRemarks(t *testing.T) {
+	res, err := Read(filepath.Join("..", "..", "test", "testdata", "sdn.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sdn := range res.SDNs {
+		if sdn.EntityID == "2831" {
+			// remarks are kept as published, even with a "-0-" in them
+			if expected := "US FEIN CH-660-0-469-982-0 (United States); Switzerland."; sdn.Remarks != expected {
+				t.Errorf("remarks=%q", sdn.Remarks)
+			}
+			return
+		}
+	}
+	t.Error("SDN 2831 not found")
 }
 
 func TestCleanPrgmsList(t *testing.T) {
@@ -113,3 +136,41 @@ func TestSDNComments(t *testing.T) {
 		}
 	}
 }
+
+func TestOFAC__readWarnings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ofac-warnings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "add.csv")
+	lines := strings.Join([]string{
+		`36,25,-0- ,"Havana","Cuba",-0- `,
+		`173,129,"Ibex House, The Minories","London EC3N 1DY"`,
+		`306,199,"Zweierstrasse 35","Zurich CH-8022","Switzerland",-0-`,
+	}, "\n")
+	if err := ioutil.WriteFile(path, []byte(lines), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Addresses) != 2 {
+		t.Errorf("found %d Addresses", len(res.Addresses))
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0] != "add.csv line 2: expected 6 fields, found 4" {
+		t.Errorf("warnings=%#v", res.Warnings)
+	}
+
+	// valid files don't have warnings
+	res, err = Read(filepath.Join("..", "..", "test", "testdata", "sdn.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Warnings) != 0 {
+		t.Errorf("warnings=%#v", res.Warnings)
+	}
+}
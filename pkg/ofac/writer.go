@@ -0,0 +1,157 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ofac
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Write creates sdn.csv, add.csv, alt.csv and sdn_comments.csv of res in dir, which Read
+// parses back into the same records. Files already in dir are replaced.
+func Write(dir string, res *Results) error {
+	for _, name := range ofacFilenames {
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if err := fileWriters[name](f, res); err != nil {
+			f.Close()
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// fileWriters write each of ofacFilenames
+var fileWriters = map[string]func(io.Writer, *Results) error{
+	"add.csv":          WriteAddresses,
+	"alt.csv":          WriteAlternateIdentities,
+	"sdn.csv":          WriteSDNs,
+	"sdn_comments.csv": WriteSDNComments,
+}
+
+// WriteSDNs writes the SDNs of res in the layout of OFAC's sdn.csv
+func WriteSDNs(w io.Writer, res *Results) error {
+	cw := newCSVWriter(w)
+	for _, sdn := range res.SDNs {
+		cw.row(
+			id(sdn.EntityID),
+			sdn.SDNName,
+			sdn.SDNType,
+			strings.Join(sdn.Programs, "; "),
+			sdn.Title,
+			sdn.CallSign,
+			sdn.VesselType,
+			sdn.Tonnage,
+			sdn.GrossRegisteredTonnage,
+			sdn.VesselFlag,
+			sdn.VesselOwner,
+			sdn.Remarks,
+		)
+	}
+	return cw.flush()
+}
+
+// WriteAddresses writes the addresses of res in the layout of OFAC's add.csv
+func WriteAddresses(w io.Writer, res *Results) error {
+	cw := newCSVWriter(w)
+	for _, addr := range res.Addresses {
+		cw.row(
+			id(addr.EntityID),
+			id(addr.AddressID),
+			addr.Address,
+			addr.CityStateProvincePostalCode,
+			addr.Country,
+			addr.AddressRemarks,
+		)
+	}
+	return cw.flush()
+}
+
+// WriteAlternateIdentities writes the alternate identities of res in the layout of OFAC's alt.csv
+func WriteAlternateIdentities(w io.Writer, res *Results) error {
+	cw := newCSVWriter(w)
+	for _, alt := range res.AlternateIdentities {
+		cw.row(
+			id(alt.EntityID),
+			id(alt.AlternateID),
+			alt.AlternateType,
+			alt.AlternateName,
+			alt.AlternateRemarks,
+		)
+	}
+	return cw.flush()
+}
+
+// WriteSDNComments writes the comments of res in the layout of OFAC's sdn_comments.csv
+func WriteSDNComments(w io.Writer, res *Results) error {
+	cw := newCSVWriter(w)
+	for _, comment := range res.SDNComments {
+		cw.row(id(comment.EntityID), comment.RemarksExtended)
+	}
+	return cw.flush()
+}
+
+// id marks a field written without quotes, OFAC only quotes text fields
+type id string
+
+// csvWriter writes rows the way OFAC publishes them: text is always quoted, identifiers
+// never are and empty fields are "-0- " (which replaceNull reads back as empty).
+type csvWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: bufio.NewWriter(w)}
+}
+
+func (cw *csvWriter) row(fields ...interface{}) {
+	if cw.err != nil {
+		return
+	}
+	var line strings.Builder
+	for i, field := range fields {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		var v string
+		quoted := true
+		switch f := field.(type) {
+		case id:
+			v, quoted = string(f), false
+		case string:
+			v = f
+		}
+		v = strings.TrimSpace(v)
+		switch {
+		case v == "":
+			line.WriteString("-0- ")
+		case quoted || strings.ContainsAny(v, ",\"\r\n"):
+			line.WriteByte('"')
+			line.WriteString(strings.Replace(v, `"`, `""`, -1))
+			line.WriteByte('"')
+		default:
+			line.WriteString(v)
+		}
+	}
+	line.WriteByte('\n')
+	_, cw.err = cw.w.WriteString(line.String())
+}
+
+func (cw *csvWriter) flush() error {
+	if cw.err != nil {
+		return cw.err
+	}
+	return cw.w.Flush()
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package ofac
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readTestdata(t *testing.T, dir string) *Results {
+	t.Helper()
+	out := &Results{}
+	for _, name := range ofacFilenames {
+		res, err := Read(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out.Addresses = append(out.Addresses, res.Addresses...)
+		out.AlternateIdentities = append(out.AlternateIdentities, res.AlternateIdentities...)
+		out.SDNs = append(out.SDNs, res.SDNs...)
+		out.SDNComments = append(out.SDNComments, res.SDNComments...)
+		out.Warnings = append(out.Warnings, res.Warnings...)
+	}
+	return out
+}
+
+func TestWriter__roundTrip(t *testing.T) {
+	expected := readTestdata(t, filepath.Join("..", "..", "test", "testdata"))
+	if len(expected.SDNs) == 0 || len(expected.Addresses) == 0 || len(expected.AlternateIdentities) == 0 || len(expected.SDNComments) == 0 {
+		t.Fatalf("missing testdata: %d SDNs, %d addresses, %d alts, %d comments",
+			len(expected.SDNs), len(expected.Addresses), len(expected.AlternateIdentities), len(expected.SDNComments))
+	}
+	expected.Warnings = nil
+
+	dir, err := ioutil.TempDir("", "ofac-writer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := Write(dir, expected); err != nil {
+		t.Fatal(err)
+	}
+	if got := readTestdata(t, dir); !reflect.DeepEqual(got, expected) {
+		t.Errorf("written files read back differently")
+	}
+
+	// the bundle extracts into the same files
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, expected); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, ofacBundleFilename)
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	bundleDir := filepath.Join(dir, "bundle")
+	if err := os.Mkdir(bundleDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	files, err := unzipBundle(path, bundleDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(ofacFilenames) {
+		t.Fatalf("files=%v", files)
+	}
+	if got := readTestdata(t, bundleDir); !reflect.DeepEqual(got, expected) {
+		t.Errorf("bundled files read back differently")
+	}
+}
+
+func TestWriter__fields(t *testing.T) {
+	res := &Results{
+		SDNs: []*SDN{
+			{EntityID: "306", SDNName: `BANCO "NACIONAL" DE CUBA`, Programs: []string{"CUBA", "SDGT"}, Remarks: "a.k.a. 'BNC'.\nLine two"},
+		},
+		Addresses: []*Address{
+			{EntityID: "306", AddressID: "199", Address: "Zweierstrasse 35", CityStateProvincePostalCode: "Zurich CH-8022", Country: "Switzerland"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteSDNs(&buf, res); err != nil {
+		t.Fatal(err)
+	}
+	expected := "306,\"BANCO \"\"NACIONAL\"\" DE CUBA\",-0- ,\"CUBA; SDGT\",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,\"a.k.a. 'BNC'.\nLine two\"\n"
+	if v := buf.String(); v != expected {
+		t.Errorf("got %q", v)
+	}
+
+	buf.Reset()
+	if err := WriteAddresses(&buf, res); err != nil {
+		t.Fatal(err)
+	}
+	if v := buf.String(); v != `306,199,"Zweierstrasse 35","Zurich CH-8022","Switzerland",-0- `+"\n" {
+		t.Errorf("got %q", v)
+	}
+
+	// the layout matches OFAC's own files
+	data, err := ioutil.ReadFile(filepath.Join("..", "..", "test", "testdata", "add.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), buf.String()) {
+		t.Errorf("%q isn't in add.csv", buf.String())
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package un
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/moov-io/watchman/pkg/download"
+
+	"github.com/go-kit/kit/log"
+)
+
+var (
+	unDownloadTemplate = func() string {
+		if w := os.Getenv("UN_DOWNLOAD_TEMPLATE"); w != "" {
+			return w
+		}
+		return "https://scsanctions.un.org/resources/xml/en/%s"
+	}()
+)
+
+// Download returns the filepath of the downloaded UN Security Council Consolidated List
+func Download(logger log.Logger, initialDir string) (string, error) {
+	dl := download.New(logger, download.HTTPClient)
+
+	addrs := make(map[string]string)
+	addrs["consolidated.xml"] = fmt.Sprintf(unDownloadTemplate, "consolidated.xml")
+
+	files, err := dl.GetFiles(initialDir, addrs)
+	if len(files) == 0 || err != nil {
+		return "", fmt.Errorf("un download: %v", err)
+	}
+	return files[0], nil
+}
@@ -0,0 +1,171 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package un
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"strings"
+)
+
+type consolidatedList struct {
+	XMLName     xml.Name `xml:"CONSOLIDATED_LIST"`
+	Individuals []record `xml:"INDIVIDUALS>INDIVIDUAL"`
+	Entities    []record `xml:"ENTITIES>ENTITY"`
+}
+
+// record is an INDIVIDUAL or ENTITY, entities don't publish the individual only fields
+type record struct {
+	DataID             string          `xml:"DATAID"`
+	FirstName          string          `xml:"FIRST_NAME"`
+	SecondName         string          `xml:"SECOND_NAME"`
+	ThirdName          string          `xml:"THIRD_NAME"`
+	FourthName         string          `xml:"FOURTH_NAME"`
+	ListType           string          `xml:"UN_LIST_TYPE"`
+	ReferenceNumber    string          `xml:"REFERENCE_NUMBER"`
+	ListedOn           string          `xml:"LISTED_ON"`
+	NameOriginalScript string          `xml:"NAME_ORIGINAL_SCRIPT"`
+	Comments           string          `xml:"COMMENTS1"`
+	Designations       []string        `xml:"DESIGNATION>VALUE"`
+	Nationalities      []string        `xml:"NATIONALITY>VALUE"`
+	IndividualAliases  []alias         `xml:"INDIVIDUAL_ALIAS"`
+	EntityAliases      []alias         `xml:"ENTITY_ALIAS"`
+	DatesOfBirth       []dateOfBirth   `xml:"INDIVIDUAL_DATE_OF_BIRTH"`
+	PlacesOfBirth      []placeOfBirth  `xml:"INDIVIDUAL_PLACE_OF_BIRTH"`
+	Documents          []documentEntry `xml:"INDIVIDUAL_DOCUMENT"`
+}
+
+type alias struct {
+	Quality string `xml:"QUALITY"`
+	Name    string `xml:"ALIAS_NAME"`
+}
+
+type dateOfBirth struct {
+	TypeOfDate string `xml:"TYPE_OF_DATE"`
+	Date       string `xml:"DATE"`
+	Year       string `xml:"YEAR"`
+	FromYear   string `xml:"FROM_YEAR"`
+	ToYear     string `xml:"TO_YEAR"`
+}
+
+type placeOfBirth struct {
+	City          string `xml:"CITY"`
+	StateProvince string `xml:"STATE_PROVINCE"`
+	Country       string `xml:"COUNTRY"`
+}
+
+type documentEntry struct {
+	Type           string `xml:"TYPE_OF_DOCUMENT"`
+	Number         string `xml:"NUMBER"`
+	IssuingCountry string `xml:"ISSUING_COUNTRY"`
+	CountryOfIssue string `xml:"COUNTRY_OF_ISSUE"`
+	Note           string `xml:"NOTE"`
+}
+
+// Read parses the XML export of the UN Security Council Consolidated List. Records without
+// any name are skipped.
+func Read(path string) ([]*Entry, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	var doc consolidatedList
+	if err := xml.NewDecoder(fd).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Individuals) == 0 && len(doc.Entities) == 0 {
+		return nil, errors.New("no records found")
+	}
+
+	var out []*Entry
+	for i := range doc.Individuals {
+		if entry := doc.Individuals[i].entry(TypeIndividual); entry.Name() != "" {
+			out = append(out, entry)
+		}
+	}
+	for i := range doc.Entities {
+		if entry := doc.Entities[i].entry(TypeEntity); entry.Name() != "" {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+func (r record) entry(kind string) *Entry {
+	entry := &Entry{
+		DataID:             strings.TrimSpace(r.DataID),
+		Type:               kind,
+		ReferenceNumber:    strings.TrimSpace(r.ReferenceNumber),
+		FirstName:          strings.TrimSpace(r.FirstName),
+		SecondName:         strings.TrimSpace(r.SecondName),
+		ThirdName:          strings.TrimSpace(r.ThirdName),
+		FourthName:         strings.TrimSpace(r.FourthName),
+		NameOriginalScript: strings.TrimSpace(r.NameOriginalScript),
+		ListType:           strings.TrimSpace(r.ListType),
+		ListedOn:           strings.TrimSpace(r.ListedOn),
+		Comments:           strings.TrimSpace(r.Comments),
+		Designations:       values(r.Designations),
+		Nationalities:      values(r.Nationalities),
+	}
+	for _, a := range append(r.IndividualAliases, r.EntityAliases...) {
+		if name := strings.TrimSpace(a.Name); name != "" {
+			entry.Aliases = append(entry.Aliases, name)
+		}
+	}
+	for _, dob := range r.DatesOfBirth {
+		if date := dob.String(); date != "" {
+			entry.DatesOfBirth = append(entry.DatesOfBirth, date)
+		}
+	}
+	for _, pob := range r.PlacesOfBirth {
+		if place := join(", ", pob.City, pob.StateProvince, pob.Country); place != "" {
+			entry.PlacesOfBirth = append(entry.PlacesOfBirth, place)
+		}
+	}
+	for _, doc := range r.Documents {
+		d := Document{
+			Type:           strings.TrimSpace(doc.Type),
+			Number:         strings.TrimSpace(doc.Number),
+			IssuingCountry: strings.TrimSpace(doc.IssuingCountry),
+			Note:           strings.TrimSpace(doc.Note),
+		}
+		if d.IssuingCountry == "" {
+			d.IssuingCountry = strings.TrimSpace(doc.CountryOfIssue)
+		}
+		if d.Number != "" || d.Note != "" {
+			entry.Documents = append(entry.Documents, d)
+		}
+	}
+	return entry
+}
+
+// String returns an exact date (1964-07-17), a year (1964) or a range of years (1960-1965)
+func (d dateOfBirth) String() string {
+	if date := strings.TrimSpace(d.Date); date != "" {
+		return date
+	}
+	if year := strings.TrimSpace(d.Year); year != "" {
+		return year
+	}
+	return join("-", d.FromYear, d.ToYear)
+}
+
+// values returns the non-empty values of a list of VALUE elements
+func values(in []string) []string {
+	var out []string
+	for i := range in {
+		if v := strings.TrimSpace(in[i]); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func join(sep string, parts ...string) string {
+	return strings.Join(values(parts), sep)
+}
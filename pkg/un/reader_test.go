@@ -0,0 +1,99 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package un
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUN__read(t *testing.T) {
+	entries, err := Read(filepath.Join("..", "..", "test", "testdata", "consolidated.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("found %d UN records", len(entries))
+	}
+
+	// individual with every name component
+	e := entries[0]
+	if e.Type != TypeIndividual || e.Name() != "AIMAN MUHAMMED RABI AL-ZAWAHIRI" || e.DataID != "6908047" {
+		t.Errorf("individual: %#v", e)
+	}
+	if e.ReferenceNumber != "QDi.006" || e.ListType != "Al-Qaida" || e.ListedOn != "2001-01-25" {
+		t.Errorf("individual: %#v", e)
+	}
+	if len(e.Aliases) != 2 || e.Aliases[0] != "Ayman Al-Zawahari" || e.Aliases[1] != "Ahmed Fuad Salim" {
+		t.Errorf("aliases: %#v", e.Aliases)
+	}
+	if len(e.DatesOfBirth) != 1 || e.DatesOfBirth[0] != "1951-06-19" {
+		t.Errorf("dates of birth: %#v", e.DatesOfBirth)
+	}
+	if len(e.PlacesOfBirth) != 1 || e.PlacesOfBirth[0] != "Giza, Egypt" {
+		t.Errorf("places of birth: %#v", e.PlacesOfBirth)
+	}
+	if len(e.Nationalities) != 1 || e.Nationalities[0] != "Egypt" {
+		t.Errorf("nationalities: %#v", e.Nationalities)
+	}
+	if len(e.Documents) != 2 || e.Documents[0] != (Document{Type: "Passport", Number: "1084010", IssuingCountry: "Egypt"}) {
+		t.Errorf("documents: %#v", e.Documents)
+	}
+	if e.Documents[1].IssuingCountry != "Egypt" {
+		t.Errorf("country of issue: %#v", e.Documents[1])
+	}
+
+	// two name components, original script and no aliases
+	e = entries[1]
+	if e.Name() != "RI WON HO" || e.NameOriginalScript != "리원호" || len(e.Aliases) != 0 {
+		t.Errorf("individual: %#v", e)
+	}
+	if len(e.Designations) != 1 || e.Designations[0] != "DPRK Ministry of State Security Official" {
+		t.Errorf("designations: %#v", e.Designations)
+	}
+
+	// ranges and years of birth
+	e = entries[2]
+	if len(e.DatesOfBirth) != 2 || e.DatesOfBirth[0] != "1955-1958" || e.DatesOfBirth[1] != "1950" {
+		t.Errorf("dates of birth: %#v", e.DatesOfBirth)
+	}
+	if len(e.PlacesOfBirth) != 1 || e.PlacesOfBirth[0] != "Kandahar, Kandahar Province, Afghanistan" || len(e.Documents) != 0 {
+		t.Errorf("individual: %#v", e)
+	}
+
+	// entities follow individuals
+	e = entries[3]
+	if e.Type != TypeEntity || e.Name() != "AL-QAIDA" || e.ReferenceNumber != "QDe.004" {
+		t.Errorf("entity: %#v", e)
+	}
+	if len(e.Aliases) != 2 || e.Aliases[0] != "The Base" || e.Aliases[1] != "Al Qaeda" {
+		t.Errorf("aliases: %#v", e.Aliases)
+	}
+	if e := entries[4]; e.Type != TypeEntity || len(e.Aliases) != 1 || e.Aliases[0] != "KOMID" {
+		t.Errorf("entity: %#v", e)
+	}
+
+	if _, err := Read(filepath.Join("..", "..", "test", "testdata", "sema-lmes.xml")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestUN__name(t *testing.T) {
+	cases := []struct {
+		entry    Entry
+		expected string
+	}{
+		{Entry{FirstName: "AIMAN", SecondName: "MUHAMMED", ThirdName: "RABI", FourthName: "AL-ZAWAHIRI"}, "AIMAN MUHAMMED RABI AL-ZAWAHIRI"},
+		{Entry{FirstName: "RI", SecondName: "WON HO"}, "RI WON HO"},
+		{Entry{FirstName: "ABDUL", ThirdName: "BASIR"}, "ABDUL BASIR"},
+		{Entry{Type: TypeEntity, FirstName: "AL-QAIDA"}, "AL-QAIDA"},
+		{Entry{}, ""},
+	}
+	for i := range cases {
+		if name := cases[i].entry.Name(); name != cases[i].expected {
+			t.Errorf("#%d: got %q", i, name)
+		}
+	}
+}
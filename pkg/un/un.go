@@ -0,0 +1,70 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package un
+
+import (
+	"strings"
+)
+
+const (
+	TypeIndividual = "individual"
+	TypeEntity     = "entity"
+)
+
+// Entry is a record on the United Nations Security Council Consolidated List, which includes
+// every individual and entity subject to measures imposed by the Security Council.
+type Entry struct {
+	// DataID is the record's unique identifier on the list
+	DataID string `json:"dataID"`
+	// Type is individual or entity
+	Type string `json:"type"`
+	// ReferenceNumber is the permanent reference of the record (e.g. QDi.001)
+	ReferenceNumber string `json:"referenceNumber"`
+	// FirstName, SecondName, ThirdName and FourthName are the components of the primary name.
+	// Entities only have a FirstName.
+	FirstName  string `json:"firstName"`
+	SecondName string `json:"secondName"`
+	ThirdName  string `json:"thirdName"`
+	FourthName string `json:"fourthName"`
+	// NameOriginalScript is the primary name in its original script
+	NameOriginalScript string `json:"nameOriginalScript"`
+	// ListType is the sanctions regime (committee) of the record, such as Al-Qaida or DPRK
+	ListType string `json:"listType"`
+	// ListedOn is when the record was added to the list
+	ListedOn string `json:"listedOn"`
+	// Comments is free text published with the record
+	Comments string `json:"comments"`
+	// Designations are an individual's titles or positions
+	Designations []string `json:"designations"`
+	// Nationalities are an individual's nationalities
+	Nationalities []string `json:"nationalities"`
+	// Aliases are other known names of the individual or entity
+	Aliases []string `json:"aliases"`
+	// DatesOfBirth are an individual's known dates, years or ranges of years of birth
+	DatesOfBirth []string `json:"datesOfBirth"`
+	// PlacesOfBirth are where an individual was born
+	PlacesOfBirth []string `json:"placesOfBirth"`
+	// Documents are an individual's passports and identity documents
+	Documents []Document `json:"documents"`
+}
+
+// Document is a passport or identity document of a listed individual
+type Document struct {
+	Type           string `json:"type"`
+	Number         string `json:"number"`
+	IssuingCountry string `json:"issuingCountry"`
+	Note           string `json:"note"`
+}
+
+// Name returns the primary name of an Entry, which is each of its name components in order
+func (e *Entry) Name() string {
+	var parts []string
+	for _, part := range []string{e.FirstName, e.SecondName, e.ThirdName, e.FourthName} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,95 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+// Package webhook implements signing and verification of webhooks sent by Watchman.
+//
+// Each webhook request includes a SignatureHeader of the form:
+//
+//	X-Watchman-Signature: t=<unix timestamp>,v1=<signature>
+//
+// t is the unix timestamp when the webhook was signed and v1 is the lowercase hex encoded
+// HMAC-SHA256 of the canonical string using the watch's auth token as the key. The canonical
+// string is exactly the decimal timestamp, a period and the raw request body. A body of {}
+// signed with the auth token "secret" has the canonical string and header:
+//
+//	1601553600.{}
+//	X-Watchman-Signature: t=1601553600,v1=759d26bccbf6218c0c02b375013a26176f3527f5dc6098dba9bbabef802f7a55
+//
+// Receivers should compute the HMAC over the body bytes exactly as received (before any JSON
+// decoding) and reject old timestamps to prevent replays.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the HTTP header Watchman sets on webhook requests
+const SignatureHeader = "X-Watchman-Signature"
+
+// Sign returns the SignatureHeader value for body signed at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, hex.EncodeToString(computeMAC(secret, ts, body)))
+}
+
+// VerifySignature returns true when header is a valid SignatureHeader value of body signed with secret.
+// Callers should also check the signed timestamp with ParseTimestamp.
+func VerifySignature(secret string, body []byte, header string) bool {
+	ts, sig, err := parseHeader(header)
+	if err != nil {
+		return false
+	}
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, computeMAC(secret, ts, body))
+}
+
+// ParseTimestamp returns when the SignatureHeader value was signed
+func ParseTimestamp(header string) (time.Time, error) {
+	ts, _, err := parseHeader(header)
+	if err != nil {
+		return time.Time{}, err
+	}
+	n, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid signature timestamp: %v", err)
+	}
+	return time.Unix(n, 0), nil
+}
+
+func computeMAC(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func parseHeader(header string) (string, string, error) {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			ts = kv[1]
+		case "v1":
+			sig = kv[1]
+		}
+	}
+	if ts == "" || sig == "" {
+		return "", "", fmt.Errorf("malformed %s: %q", SignatureHeader, header)
+	}
+	return ts, sig, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Moov Authors
+// Use of this source code is governed by an Apache License
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignature(t *testing.T) {
+	when := time.Unix(1601553600, 0)
+	body := []byte(`{"id":"cust","match":0.91}`)
+	header := Sign("secret", when, body)
+
+	if !VerifySignature("secret", body, header) {
+		t.Errorf("expected valid signature: %s", header)
+	}
+	if ts, err := ParseTimestamp(header); err != nil || !ts.Equal(when) {
+		t.Errorf("ts=%v err=%v", ts, err)
+	}
+
+	// tampered body
+	if VerifySignature("secret", []byte(`{"id":"cust","match":0.10}`), header) {
+		t.Error("expected tampered body to fail")
+	}
+	// wrong secret
+	if VerifySignature("other", body, header) {
+		t.Error("expected wrong secret to fail")
+	}
+	// tampered timestamp
+	other := Sign("secret", when.Add(time.Second), body)
+	if VerifySignature("secret", body, "t=1601553600,"+other[len("t=1601553601,"):]) {
+		t.Error("expected tampered timestamp to fail")
+	}
+}
+
+func TestSignature__canonical(t *testing.T) {
+	// Receivers must compute: hex(hmac_sha256(secret, "<t>.<body>"))
+	header := Sign("secret", time.Unix(1601553600, 0), []byte(`{}`))
+	expected := "t=1601553600,v1=759d26bccbf6218c0c02b375013a26176f3527f5dc6098dba9bbabef802f7a55"
+	if header != expected {
+		t.Errorf("got %s", header)
+	}
+}
+
+func TestSignature__malformed(t *testing.T) {
+	body := []byte(`{}`)
+	for _, header := range []string{"", "t=1601553600", "v1=abcd", "t=1601553600,v1=zz", "bogus"} {
+		if VerifySignature("secret", body, header) {
+			t.Errorf("expected %q to fail", header)
+		}
+	}
+	if _, err := ParseTimestamp("t=abc,v1=00"); err == nil {
+		t.Error("expected error")
+	}
+}